@@ -39,7 +39,9 @@ import (
 	badgectl "github.com/horizoncd/horizon/core/controller/badge"
 	"github.com/horizoncd/horizon/core/controller/build"
 	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	clustersecretctl "github.com/horizoncd/horizon/core/controller/clustersecret"
 	codectl "github.com/horizoncd/horizon/core/controller/code"
+	doractl "github.com/horizoncd/horizon/core/controller/dora"
 	environmentctl "github.com/horizoncd/horizon/core/controller/environment"
 	environmentregionctl "github.com/horizoncd/horizon/core/controller/environmentregion"
 	envtemplatectl "github.com/horizoncd/horizon/core/controller/envtemplate"
@@ -47,15 +49,18 @@ import (
 	groupctl "github.com/horizoncd/horizon/core/controller/group"
 	idpctl "github.com/horizoncd/horizon/core/controller/idp"
 	memberctl "github.com/horizoncd/horizon/core/controller/member"
+	mfactl "github.com/horizoncd/horizon/core/controller/mfa"
 	oauthservicectl "github.com/horizoncd/horizon/core/controller/oauth"
 	oauthappctl "github.com/horizoncd/horizon/core/controller/oauthapp"
 	oauthcheckctl "github.com/horizoncd/horizon/core/controller/oauthcheck"
 	prctl "github.com/horizoncd/horizon/core/controller/pipelinerun"
+	quotactl "github.com/horizoncd/horizon/core/controller/quota"
 	regionctl "github.com/horizoncd/horizon/core/controller/region"
 	registryctl "github.com/horizoncd/horizon/core/controller/registry"
 	roltctl "github.com/horizoncd/horizon/core/controller/role"
 	scopectl "github.com/horizoncd/horizon/core/controller/scope"
 	tagctl "github.com/horizoncd/horizon/core/controller/tag"
+	teamctl "github.com/horizoncd/horizon/core/controller/team"
 	templatectl "github.com/horizoncd/horizon/core/controller/template"
 	templateschematagctl "github.com/horizoncd/horizon/core/controller/templateschematag"
 	terminalctl "github.com/horizoncd/horizon/core/controller/terminal"
@@ -88,20 +93,25 @@ import (
 	applicationregionv2 "github.com/horizoncd/horizon/core/http/api/v2/applicationregion"
 	"github.com/horizoncd/horizon/core/http/api/v2/badge"
 	clusterv2 "github.com/horizoncd/horizon/core/http/api/v2/cluster"
+	clustersecretv2 "github.com/horizoncd/horizon/core/http/api/v2/clustersecret"
 	codev2 "github.com/horizoncd/horizon/core/http/api/v2/code"
+	dorav2 "github.com/horizoncd/horizon/core/http/api/v2/dora"
 	environmentv2 "github.com/horizoncd/horizon/core/http/api/v2/environment"
 	environmentregionv2 "github.com/horizoncd/horizon/core/http/api/v2/environmentregion"
 	eventv2 "github.com/horizoncd/horizon/core/http/api/v2/event"
 	groupv2 "github.com/horizoncd/horizon/core/http/api/v2/group"
 	idpv2 "github.com/horizoncd/horizon/core/http/api/v2/idp"
 	memberv2 "github.com/horizoncd/horizon/core/http/api/v2/member"
+	mfav2 "github.com/horizoncd/horizon/core/http/api/v2/mfa"
 	oauthappv2 "github.com/horizoncd/horizon/core/http/api/v2/oauthapp"
 	pipelinerunv2 "github.com/horizoncd/horizon/core/http/api/v2/pipelinerun"
+	quotav2 "github.com/horizoncd/horizon/core/http/api/v2/quota"
 	regionv2 "github.com/horizoncd/horizon/core/http/api/v2/region"
 	registryv2 "github.com/horizoncd/horizon/core/http/api/v2/registry"
 	rolev2 "github.com/horizoncd/horizon/core/http/api/v2/role"
 	scopev2 "github.com/horizoncd/horizon/core/http/api/v2/scope"
 	tagv2 "github.com/horizoncd/horizon/core/http/api/v2/tag"
+	teamv2 "github.com/horizoncd/horizon/core/http/api/v2/team"
 	templateschematagv2 "github.com/horizoncd/horizon/core/http/api/v2/templateschematag"
 	terminalv2 "github.com/horizoncd/horizon/core/http/api/v2/terminal"
 	userv2 "github.com/horizoncd/horizon/core/http/api/v2/user"
@@ -109,22 +119,31 @@ import (
 	"github.com/horizoncd/horizon/core/middleware"
 	"github.com/horizoncd/horizon/core/middleware/auth"
 	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/lib/email"
 	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
 	"github.com/horizoncd/horizon/pkg/admission"
+	admissionmodels "github.com/horizoncd/horizon/pkg/admission/models"
 	"github.com/horizoncd/horizon/pkg/cd"
 	clustermetrcis "github.com/horizoncd/horizon/pkg/cluster/metrics"
+	dorametrics "github.com/horizoncd/horizon/pkg/cluster/metrics/dora"
 	admissionconfig "github.com/horizoncd/horizon/pkg/config/admission"
 	"github.com/horizoncd/horizon/pkg/environment/service"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	"github.com/horizoncd/horizon/pkg/eventhandler/gitstatus"
+	"github.com/horizoncd/horizon/pkg/eventhandler/quotausage"
 	"github.com/horizoncd/horizon/pkg/grafana"
 	"github.com/horizoncd/horizon/pkg/jobs"
 	"github.com/horizoncd/horizon/pkg/jobs/autofree"
 	"github.com/horizoncd/horizon/pkg/jobs/clean"
+	jobdrift "github.com/horizoncd/horizon/pkg/jobs/drift"
 	"github.com/horizoncd/horizon/pkg/jobs/eventhandler"
 	"github.com/horizoncd/horizon/pkg/jobs/grafanasync"
 	"github.com/horizoncd/horizon/pkg/jobs/k8sevent"
+	"github.com/horizoncd/horizon/pkg/jobs/tokenexpiry"
 	jobwebhook "github.com/horizoncd/horizon/pkg/jobs/webhook"
 	prservice "github.com/horizoncd/horizon/pkg/pr/service"
+	"github.com/horizoncd/horizon/pkg/quota"
+	"github.com/horizoncd/horizon/pkg/quota/clusterusage"
 	"github.com/horizoncd/horizon/pkg/regioninformers"
 	"github.com/horizoncd/horizon/pkg/token/generator"
 	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
@@ -146,6 +165,7 @@ import (
 	ginlogmiddle "github.com/horizoncd/horizon/core/middleware/ginlog"
 	logmiddle "github.com/horizoncd/horizon/core/middleware/log"
 	metricsmiddle "github.com/horizoncd/horizon/core/middleware/metrics"
+	mfamiddle "github.com/horizoncd/horizon/core/middleware/mfa"
 	prehandlemiddle "github.com/horizoncd/horizon/core/middleware/prehandle"
 	scopemiddle "github.com/horizoncd/horizon/core/middleware/scope"
 	tagmiddle "github.com/horizoncd/horizon/core/middleware/tag"
@@ -164,6 +184,7 @@ import (
 	roleconfig "github.com/horizoncd/horizon/pkg/config/role"
 	groupservice "github.com/horizoncd/horizon/pkg/group/service"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
+	mfaservice "github.com/horizoncd/horizon/pkg/mfa/service"
 	oauthdao "github.com/horizoncd/horizon/pkg/oauth/dao"
 	oauthmanager "github.com/horizoncd/horizon/pkg/oauth/manager"
 	scopeservice "github.com/horizoncd/horizon/pkg/oauth/scope"
@@ -174,6 +195,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/templaterelease/output"
 	templateschemarepo "github.com/horizoncd/horizon/pkg/templaterelease/schema/repo"
 	"github.com/horizoncd/horizon/pkg/templaterepo"
+	terminalsessionservice "github.com/horizoncd/horizon/pkg/terminalsession/service"
 	userservice "github.com/horizoncd/horizon/pkg/user/service"
 	callbacks "github.com/horizoncd/horizon/pkg/util/ormcallbacks"
 
@@ -195,6 +217,9 @@ type Flags struct {
 	Dev                 bool
 	Environment         string
 	LogLevel            string
+	// Command is the subcommand following the flags, the server is started if it is empty
+	Command     string
+	CommandArgs []string
 }
 
 type RegisterRouter interface {
@@ -230,6 +255,10 @@ func ParseFlags() *Flags {
 		&flags.LogLevel, "loglevel", "info", "the loglevel(panic/fatal/error/warn/info/debug/trace))")
 
 	flag.Parse()
+	if flag.NArg() > 0 {
+		flags.Command = flag.Arg(0)
+		flags.CommandArgs = flag.Args()[1:]
+	}
 	return &flags
 }
 
@@ -292,11 +321,14 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	log.Printf("the roleConfig = %+v\n", roleConfig)
 
 	// init db
-	mysqlDB, err := orm.NewMySQLDB(coreConfig.DBConfig)
+	gormDB, err := orm.NewDB(coreConfig.DBConfig)
 	if err != nil {
 		panic(err)
 	}
-	callbacks.RegisterCustomCallbacks(mysqlDB)
+	callbacks.RegisterCustomCallbacks(gormDB)
+	if err := migrateOnStartup(ctx, gormDB, coreConfig.DBConfig); err != nil {
+		panic(err)
+	}
 
 	redisClient := redis.NewClient(&redis.Options{
 		Network:  coreConfig.RedisConfig.Protocol,
@@ -319,7 +351,7 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	gob.Register(&userauth.DefaultInfo{})
 
 	// init manager parameter
-	manager := managerparam.InitManager(mysqlDB)
+	manager := managerparam.InitManager(gormDB)
 
 	gitlabGitops, err := gitlablib.New(coreConfig.GitopsRepoConfig.Token, coreConfig.GitopsRepoConfig.URL)
 	if err != nil {
@@ -374,20 +406,23 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		panic(err)
 	}
 
-	oauthAppDAO := oauthdao.NewDAO(mysqlDB)
-	tokenStore := tokenstore.NewStore(mysqlDB)
+	oauthAppDAO := oauthdao.NewDAO(gormDB)
+	tokenStore := tokenstore.NewStore(gormDB)
 	oauthManager := oauthmanager.NewManager(oauthAppDAO, tokenStore,
 		generator.NewAuthorizeGenerator(),
 		coreConfig.Oauth.AuthorizeCodeExpireIn,
 		coreConfig.Oauth.AccessTokenExpireIn,
-		coreConfig.Oauth.RefreshTokenExpireIn)
+		coreConfig.Oauth.RefreshTokenExpireIn,
+		coreConfig.Oauth.DeviceCodeExpireIn)
 
-	roleService, err := role.NewFileRoleFrom2(context.TODO(), roleConfig)
+	roleService, err := role.NewCustomService(context.TODO(), roleConfig, manager.CustomRoleMgr)
 	if err != nil {
 		panic(err)
 	}
+	// custom roles may be changed by other replicas
+	go roleService.Sync(ctx, 30*time.Second)
 	mservice := memberservice.NewService(roleService, oauthManager, manager)
-	rbacAuthorizer := rbac.NewAuthorizer(roleService, mservice)
+	rbacAuthorizer := rbac.NewAuthorizer(roleService, mservice, manager)
 
 	// init scope service
 	scopeFile, err := os.OpenFile(flags.ScopeRoleFile, os.O_RDONLY, 0644)
@@ -440,6 +475,12 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	clusterSvc := clusterservice.NewService(applicationSvc, clusterGitRepo, manager)
 	userSvc := userservice.NewService(manager)
 	tokenSvc := tokenservice.NewService(manager, coreConfig.TokenConfig)
+	mfaSvc := mfaservice.NewService(manager)
+	terminalSessionSvc, err := terminalsessionservice.New(coreConfig.TerminalConfig.Recording,
+		manager.TerminalSessionMgr)
+	if err != nil {
+		panic(err)
+	}
 
 	// init kube client
 	_, client, err := kube.BuildClient(coreConfig.KubeConfig)
@@ -462,32 +503,47 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		EventSvc:             eventSvc,
 		UserSvc:              userSvc,
 		TokenSvc:             tokenSvc,
+		MFASvc:               mfaSvc,
+		TerminalSessionSvc:   terminalSessionSvc,
 		RoleService:          roleService,
+		CustomRoleSvc:        roleService,
 		ScopeService:         scopeService,
 		ApplicationGitRepo:   applicationGitRepo,
 		TemplateSchemaGetter: templateSchemaGetter,
-		CD: cd.NewCD(regionInformers, clusterGitRepo, coreConfig.ArgoCDMapper, coreConfig.RegionArgoCDMapper,
+		CD: cd.NewCD(regionInformers, clusterGitRepo, manager.RegionMgr, coreConfig.ArgoCDMapper,
+			coreConfig.RegionArgoCDMapper, coreConfig.FluxConfig, templateRepo, coreConfig.HelmConfig,
 			coreConfig.GitopsRepoConfig.DefaultBranch),
 		K8sUtil:        cd.NewK8sUtil(regionInformers, manager.EventMgr),
 		OutputGetter:   outputGetter,
 		TektonFty:      tektonFty,
 		ClusterGitRepo: clusterGitRepo,
+		TemplateRepo:   templateRepo,
 		PRService:      prservice.NewService(manager),
 		GitGetter:      gitGetter,
 		GrafanaService: grafanaService,
 		BuildSchema:    buildSchema,
 	}
 
+	if coreConfig.QuotaConfig.Enabled {
+		admission.Register(admissionmodels.KindValidating,
+			quota.NewWebhook(coreConfig.QuotaConfig, parameter, templateRepo))
+	}
+
 	var (
 		authnSkippers = []middleware.Skipper{
 			middleware.MethodAndPathSkipper("*",
 				regexp.MustCompile("(^/apis/front/.*)|(^/health)|(^/metrics)|(^/apis/login)|"+
-					"(^/apis/core/v[12]/roles)|(^/apis/internal/.*)|(^/login/oauth/authorize)|(^/login/oauth/access_token)")),
+					"(^/apis/internal/.*)|(^/login/oauth/authorize)|(^/login/oauth/access_token)|"+
+					"(^/login/oauth/device)")),
+			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/roles")),
 			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/idps/endpoints")),
 			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/login/callback")),
 			middleware.MethodAndPathSkipper(http.MethodPost, regexp.MustCompile("^/apis/core/v[12]/logout")),
 			middleware.MethodAndPathSkipper(http.MethodPost, regexp.MustCompile("^/apis/core/v[12]/users/login")),
+			middleware.MethodAndPathSkipper(http.MethodPost, regexp.MustCompile("^/apis/core/v2/idps/[0-9]+/login$")),
 			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/users/self")),
+			// git webhooks are verified by the webhook secret of git host
+			middleware.MethodAndPathSkipper(http.MethodPost, regexp.MustCompile("^/apis/core/v2/gitwebhooks/")),
 		}
 		authzSkippers = []middleware.Skipper{
 			middleware.MethodAndPathSkipper("*",
@@ -505,7 +561,7 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		prCtl                = prctl.NewController(coreConfig, parameter)
 		templateCtl          = templatectl.NewController(parameter, templateRepo)
 		roleCtl              = roltctl.NewController(parameter)
-		terminalCtl          = terminalctl.NewController(parameter)
+		terminalCtl          = terminalctl.NewController(coreConfig, parameter)
 		codeGitCtl           = codectl.NewController(gitGetter)
 		tagCtl               = tagctl.NewController(parameter)
 		templateSchemaTagCtl = templateschematagctl.NewController(parameter)
@@ -527,6 +583,11 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		webhookCtl           = webhookctl.NewController(parameter)
 		eventCtl             = eventctl.NewController(parameter)
 		badgeCtl             = badgectl.NewController(parameter)
+		mfaCtl               = mfactl.NewController(coreConfig, parameter)
+		teamCtl              = teamctl.NewController(parameter)
+		doraCtl              = doractl.NewController(parameter)
+		clusterSecretCtl     = clustersecretctl.NewController(parameter)
+		quotaCtl             = quotactl.NewController(parameter)
 	)
 
 	var (
@@ -552,7 +613,7 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		applicationRegionAPI = applicationregion.NewAPI(applicationRegionCtl)
 		oauthAppAPI          = oauthapp.NewAPI(oauthAppCtl)
 		oauthServerAPI       = oauthserver.NewAPI(oauthServerCtl, oauthAppCtl,
-			coreConfig.Oauth.OauthHTMLLocation, scopeService)
+			coreConfig.Oauth.OauthHTMLLocation, coreConfig.Oauth.DeviceVerificationURI, scopeService)
 		idpAPI         = idp.NewAPI(idpCtrl, store)
 		accessTokenAPI = accesstoken.NewAPI(accessTokenCtl, roleService, scopeService)
 		scopeAPI       = scope.NewAPI(scopeCtl)
@@ -587,6 +648,11 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		userAPIV2              = userv2.NewAPI(userCtl, store)
 		webhookAPIV2           = webhookv2.NewAPI(webhookCtl)
 		badgeAPIV2             = badge.NewAPI(badgeCtl)
+		mfaAPIV2               = mfav2.NewAPI(mfaCtl, store)
+		teamAPIV2              = teamv2.NewAPI(teamCtl)
+		doraAPIV2              = dorav2.NewAPI(doraCtl)
+		clusterSecretAPIV2     = clustersecretv2.NewAPI(clusterSecretCtl)
+		quotaAPIV2             = quotav2.NewAPI(quotaCtl)
 	)
 
 	// start jobs
@@ -597,16 +663,40 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		}
 		eventHandlerJob, eventHandlerSvc := eventhandler.New(ctx, coreConfig.EventHandlerConfig, manager)
 		webhookJob, _ := jobwebhook.New(ctx, eventHandlerSvc, coreConfig.WebhookConfig, manager)
+		if coreConfig.GitStatusConfig.Enabled {
+			if err := eventHandlerSvc.RegisterEventHandler("gitstatus",
+				gitstatus.NewReporter(coreConfig.GitStatusConfig, manager, gitGetter)); err != nil {
+				panic(err)
+			}
+		}
 		grafanaSyncJob := func(ctx context.Context) {
 			grafanasync.Run(ctx, coreConfig, manager, client)
 		}
-		k8seventJob := k8sevent.New(coreConfig.KubernetesEvent, regionInformers, manager, mysqlDB)
-		go jobs.Run(ctx, &coreConfig.JobConfig, eventHandlerJob, webhookJob,
-			k8seventJob.Run, cleaner.Run, autoFreeJob, grafanaSyncJob)
+		k8seventJob := k8sevent.New(coreConfig.KubernetesEvent, regionInformers, manager, gormDB)
+		emailSender := email.NewSender(coreConfig.EmailConfig)
+		tokenExpiryNotifier := tokenexpiry.New(coreConfig.TokenConfig.ExpiryNotification, emailSender, manager)
+		driftDetector := jobdrift.New(coreConfig.DriftConfig, emailSender, parameter)
+		jobList := []jobs.Job{eventHandlerJob, webhookJob,
+			k8seventJob.Run, cleaner.Run, autoFreeJob, grafanaSyncJob, terminalSessionSvc.Run,
+			tokenExpiryNotifier.Run, driftDetector.Run}
+		if coreConfig.QuotaConfig.Enabled {
+			refresher := quotausage.NewRefresher(clusterusage.NewCalculator(templateRepo, clusterGitRepo), manager)
+			if err := eventHandlerSvc.RegisterEventHandler("quotausage", refresher); err != nil {
+				panic(err)
+			}
+			jobList = append(jobList, refresher.Run)
+		}
+		go jobs.Run(ctx, &coreConfig.JobConfig, jobList...)
 	}
 
 	// init server
 	r := gin.New()
+	// gin trusts all proxies by default, keep it unless trusted proxies are configured
+	if len(coreConfig.ServerConfig.TrustedProxies) > 0 {
+		if err := r.SetTrustedProxies(coreConfig.ServerConfig.TrustedProxies); err != nil {
+			panic(err)
+		}
+	}
 	// use middleware
 	middlewares := []gin.HandlerFunc{
 		ginlogmiddle.Middleware(gin.DefaultWriter, "/health", "/metrics"),
@@ -626,11 +716,16 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/apis/front/v1/terminal")),
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/apis/front/v2/buildschema")),
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/login/oauth/access_token")),
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/login/oauth/device/code")),
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/apis/internal/v2/.*")),
 			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/idps/endpoints")),
-			middleware.MethodAndPathSkipper(http.MethodPost, regexp.MustCompile("^/apis/core/v[12]/users/login"))),
+			middleware.MethodAndPathSkipper(http.MethodPost, regexp.MustCompile("^/apis/core/v[12]/users/login")),
+			middleware.MethodAndPathSkipper(http.MethodPost, regexp.MustCompile("^/apis/core/v2/idps/[0-9]+/login$")),
+			middleware.MethodAndPathSkipper(http.MethodPost, regexp.MustCompile("^/apis/core/v2/gitwebhooks/"))),
 		prehandlemiddle.Middleware(r, manager),
 		auth.Middleware(rbacAuthorizer, authzSkippers...),
+		mfamiddle.Middleware(mfaSvc, store, coreConfig.MFAConfig, append(authzSkippers,
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/apis/core/v2/users/self/mfa")))...),
 		tagmiddle.Middleware(),
 		admissionmiddle.Middleware(authzSkippers...),
 	}
@@ -641,6 +736,7 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	// register routes
 	health.RegisterRoutes(r)
 	clustermetrcis.NewMetrics(manager)
+	dorametrics.NewMetrics(ctx, manager)
 	metrics.RegisterRoutes(r)
 
 	// v1
@@ -702,6 +798,11 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		userAPIV2,
 		webhookAPIV2,
 		badgeAPIV2,
+		mfaAPIV2,
+		teamAPIV2,
+		doraAPIV2,
+		clusterSecretAPIV2,
+		quotaAPIV2,
 	}
 
 	// start cloud event server
@@ -742,6 +843,27 @@ func Run(flags *Flags) {
 	// init log
 	InitLog(flags)
 
+	switch flags.Command {
+	case "":
+	case CommandMigrate:
+		if err := RunMigrate(ctx, flags.CommandArgs, configs); err != nil {
+			panic(err)
+		}
+		return
+	case CommandReencrypt:
+		if err := RunReencrypt(ctx, flags.CommandArgs, configs); err != nil {
+			panic(err)
+		}
+		return
+	default:
+		panic(fmt.Sprintf("unknown command %s", flags.Command))
+	}
+
+	// init encryption of credentials
+	if _, err := initEncryption(ctx, configs); err != nil {
+		panic(err)
+	}
+
 	// init api
 	Init(ctx, flags, configs)
 }
@@ -0,0 +1,147 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/horizoncd/horizon/core/config"
+	"github.com/horizoncd/horizon/lib/encrypt"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/config/argocd"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const CommandReencrypt = "reencrypt"
+
+// encryptedColumns are columns of encrypt.String fields
+var encryptedColumns = []struct {
+	table  string
+	column string
+}{
+	{table: "tb_registry", column: "token"},
+	{table: "tb_region", column: "certificate"},
+	{table: "tb_identity_provider", column: "client_secret"},
+	{table: "tb_webhook", column: "secret"},
+	{table: "tb_git_trigger", column: "secret"},
+	{table: "tb_preview_setting", column: "secret"},
+}
+
+// initEncryption sets the encrypter of encrypted columns and decrypts encrypted argoCD tokens in config
+func initEncryption(ctx context.Context, coreConfig *config.Config) (*encrypt.Encrypter, error) {
+	encrypter, err := encrypt.New(coreConfig.EncryptionConfig)
+	if err != nil {
+		return nil, err
+	}
+	encrypt.SetDefault(encrypter)
+
+	argoCDs := make([]*argocd.ArgoCD, 0, len(coreConfig.ArgoCDMapper)+len(coreConfig.RegionArgoCDMapper))
+	for _, argoCD := range coreConfig.ArgoCDMapper {
+		argoCDs = append(argoCDs, argoCD)
+	}
+	for _, argoCD := range coreConfig.RegionArgoCDMapper {
+		argoCDs = append(argoCDs, argoCD)
+	}
+	for _, argoCD := range argoCDs {
+		if argoCD == nil || !encrypt.IsEncrypted(argoCD.Token) {
+			continue
+		}
+		if encrypter == nil {
+			return nil, perror.Errorf("argoCD token of %s is encrypted but encryption is not configured", argoCD.URL)
+		}
+		if argoCD.Token, err = encrypter.Decrypt(ctx, argoCD.Token); err != nil {
+			return nil, perror.WithMessagef(err, "failed to decrypt argoCD token of %s", argoCD.URL)
+		}
+	}
+	return encrypter, nil
+}
+
+// RunReencrypt runs the reencrypt command, which encrypts plaintext values and values encrypted
+// with rotated keys by the primary key, e.g. app -config config.yaml reencrypt -dry-run
+func RunReencrypt(ctx context.Context, args []string, coreConfig *config.Config) error {
+	flags := flag.NewFlagSet(CommandReencrypt, flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "print values to re-encrypt without updating them")
+	all := flags.Bool("all", false,
+		"re-encrypt values encrypted with the primary key as well, e.g. after the key is rotated in vault")
+	value := flags.String("value", "", "print the encrypted value instead, e.g. for argoCD tokens in config")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	encrypter, err := initEncryption(ctx, coreConfig)
+	if err != nil {
+		return err
+	}
+	if encrypter == nil {
+		return perror.New("encryption is not configured")
+	}
+	if *value != "" {
+		encrypted, err := encrypter.Encrypt(ctx, *value)
+		if err != nil {
+			return err
+		}
+		fmt.Println(encrypted)
+		return nil
+	}
+
+	gormDB, err := orm.NewDB(coreConfig.DBConfig)
+	if err != nil {
+		return err
+	}
+	for _, c := range encryptedColumns {
+		var rows []struct {
+			ID    uint
+			Value string
+		}
+		result := gormDB.WithContext(ctx).Table(c.table).
+			Select(fmt.Sprintf("id, %s as value", c.column)).
+			Where(fmt.Sprintf("%s <> ''", c.column)).Find(&rows)
+		if result.Error != nil {
+			return perror.Wrapf(result.Error, "failed to list %s", c.table)
+		}
+		count := 0
+		for _, row := range rows {
+			if !*all && !encrypter.Outdated(row.Value) {
+				continue
+			}
+			count++
+			if *dryRun {
+				continue
+			}
+			plaintext, err := encrypter.Decrypt(ctx, row.Value)
+			if err != nil {
+				return perror.WithMessagef(err, "failed to decrypt %s of %s %d", c.column, c.table, row.ID)
+			}
+			encrypted, err := encrypter.Encrypt(ctx, plaintext)
+			if err != nil {
+				return err
+			}
+			result := gormDB.WithContext(ctx).Table(c.table).
+				Where("id = ?", row.ID).Update(c.column, encrypted)
+			if result.Error != nil {
+				return perror.Wrapf(result.Error, "failed to update %s of %s %d", c.column, c.table, row.ID)
+			}
+		}
+		if *dryRun {
+			log.Printf("[encryption] would re-encrypt %d of %d %s in %s", count, len(rows), c.column, c.table)
+		} else {
+			log.Printf("[encryption] re-encrypted %d of %d %s in %s", count, len(rows), c.column, c.table)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"flag"
+	"log"
+	"path/filepath"
+
+	"github.com/horizoncd/horizon/core/config"
+	"github.com/horizoncd/horizon/lib/migration"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/config/db"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	CommandMigrate = "migrate"
+
+	defaultSchemaDir         = "db"
+	defaultPostgresSchemaDir = "db/postgres"
+)
+
+// newMigrationRunner loads schema snapshots from the schema dir and migrations from its migrations subdir
+func newMigrationRunner(gormDB *gorm.DB, dbConfig db.Config) (*migration.Runner, error) {
+	config := dbConfig.Migration
+	dir := config.Dir
+	if dir == "" {
+		dir = defaultSchemaDir
+		if dbConfig.Type == db.TypePostgres {
+			dir = defaultPostgresSchemaDir
+		}
+	}
+	snapshot, err := migration.LoadSnapshot(dir)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := migration.Load(filepath.Join(dir, "migrations"))
+	if err != nil {
+		return nil, err
+	}
+	return migration.NewRunner(gormDB, snapshot, migrations, config.LockTimeout)
+}
+
+// migrateOnStartup applies pending migrations if auto apply is enabled,
+// otherwise returns error if the schema is behind so that the server never serves with an outdated schema.
+// Databases deployed before migrations were tracked have no history, so the version of their schema is unknown
+// until it is baselined. The server refuses to start for them unless auto apply is enabled, in which case
+// the operator has opted in to manage the schema on startup, and it only warns.
+func migrateOnStartup(ctx context.Context, gormDB *gorm.DB, config db.Config) error {
+	runner, err := newMigrationRunner(gormDB, config)
+	if err != nil {
+		return err
+	}
+	if !config.Migration.AutoApply {
+		err = runner.Check(ctx)
+		if perror.Cause(err) == migration.ErrNoHistory {
+			return perror.Wrapf(err, "run the %s command with -baseline to mark the migrations already applied",
+				CommandMigrate)
+		}
+		if err != nil {
+			return perror.Wrapf(err, "run the %s command to apply pending migrations", CommandMigrate)
+		}
+		return nil
+	}
+	applied, err := runner.Up(ctx, false)
+	for _, m := range applied {
+		log.Printf("[migration] applied %s", m.File)
+	}
+	if perror.Cause(err) == migration.ErrNoHistory {
+		warnNoHistory(err)
+		return nil
+	}
+	return err
+}
+
+func warnNoHistory(err error) {
+	log.Printf("[migration] warning: %v, run the %s command with -baseline to mark the migrations "+
+		"already applied, pending migrations are not checked until then", err, CommandMigrate)
+}
+
+// RunMigrate runs the migrate command, e.g. app -config config.yaml migrate -dry-run
+func RunMigrate(ctx context.Context, args []string, coreConfig *config.Config) error {
+	flags := flag.NewFlagSet(CommandMigrate, flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "print pending migrations without applying them")
+	status := flags.Bool("status", false, "print current version and pending migrations")
+	baseline := flags.Uint64("baseline", 0,
+		"mark migrations up to the version as applied without running them, for databases migrated by hand")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	gormDB, err := orm.NewDB(coreConfig.DBConfig)
+	if err != nil {
+		return err
+	}
+	runner, err := newMigrationRunner(gormDB, coreConfig.DBConfig)
+	if err != nil {
+		return err
+	}
+
+	if *status {
+		s, err := runner.Status(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("[migration] current version: %d", s.Current)
+		for _, m := range s.Pending {
+			log.Printf("[migration] pending: %s", m.File)
+		}
+		for _, version := range s.Unknown {
+			log.Printf("[migration] applied but not found locally: %d", version)
+		}
+		return nil
+	}
+
+	var applied []*migration.Migration
+	if *baseline > 0 {
+		applied, err = runner.Baseline(ctx, *baseline, *dryRun)
+	} else {
+		applied, err = runner.Up(ctx, *dryRun)
+	}
+	for _, m := range applied {
+		switch {
+		case *dryRun && *baseline > 0:
+			log.Printf("[migration] would mark %s as applied", m.File)
+		case *dryRun:
+			log.Printf("[migration] would apply %s:", m.File)
+			for _, stmt := range m.Statements {
+				log.Printf("%s;", stmt)
+			}
+		case *baseline > 0:
+			log.Printf("[migration] marked %s as applied", m.File)
+		default:
+			log.Printf("[migration] applied %s", m.File)
+		}
+	}
+	if err == nil && len(applied) == 0 {
+		log.Printf("[migration] schema is up to date")
+	}
+	return err
+}
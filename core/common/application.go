@@ -24,4 +24,7 @@ const (
 	ApplicationQueryID               = "id"
 
 	ApplicationQueryWithDeleted = "withDeleted"
+	// ApplicationQueryHiddenApplications is used to exclude private applications and applications
+	// under private groups which the current user is not a member of.
+	ApplicationQueryHiddenApplications = "hiddenApplications"
 )
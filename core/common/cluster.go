@@ -31,6 +31,11 @@ const (
 	ClusterQueryContainerName = "containerName"
 	ClusterQueryPodName       = "podName"
 	ClusterQueryTailLines     = "tailLines"
+	ClusterQuerySinceTime     = "sinceTime"
+	ClusterQueryFollow        = "follow"
+	ClusterQueryPrevious      = "previous"
+	ClusterQueryLogKeyword    = "keyword"
+	ClusterQueryLogRegex      = "regex"
 	ClusterQueryExtraOwner    = "extraOwner"
 	ClusterQueryHard          = "hard"
 
@@ -40,6 +45,9 @@ const (
 	ClusterQueryWithFavorite = "withFavorite"
 	ClusterQueryUpdatedAfter = "updatedAfter"
 	ClusterQueryOnlyDeleted  = "onlyDeleted"
+	// ClusterQueryHiddenApplications is used to exclude clusters of private applications and applications
+	// under private groups, unless the current user is a member of the cluster itself.
+	ClusterQueryHiddenApplications = "hiddenApplications"
 )
 
 const (
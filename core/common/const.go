@@ -110,6 +110,8 @@ const (
 	ResourceWebhookLog = "webhooklogs"
 
 	ResourceMember = "members"
+
+	ResourceTeam = "teams"
 )
 
 const (
@@ -126,6 +128,8 @@ const (
 	ParamResourceType  = "resourceType"
 	ParamResourceID    = "resourceID"
 	ParamAccessTokenID = "accessTokenID"
+	ParamGitTriggerID  = "gitTriggerID"
+	ParamGitKind       = "gitKind"
 )
 
 const (
@@ -138,8 +142,9 @@ const (
 )
 
 const (
-	CookieKeyAuth      = "horizon|session"
-	SessionKeyAuthUser = "user"
+	CookieKeyAuth           = "horizon|session"
+	SessionKeyAuthUser      = "user"
+	SessionKeyMFAVerifiedAt = "mfaVerifiedAt"
 )
 
 const (
@@ -23,6 +23,7 @@ const (
 	GitopsFileBase           = "system/horizon.yaml"
 	GitopsFileEnv            = "system/env.yaml"
 	GitopsFileRestart        = "system/restart.yaml"
+	GitopsFileSecret         = "system/secret.yaml"
 	GitopsFilePipeline       = "pipeline/pipeline.yaml"
 	GitopsAppPipeline        = "pipeline.yaml"
 	GitopsFilePipelineOutput = "pipeline/pipeline-output.yaml"
@@ -37,5 +38,6 @@ const (
 	GitopsGroupClusters          = "clusters"
 	GitopsGroupRecyclingClusters = "recycling-clusters"
 
-	GitopsKeyTags = "tags"
+	GitopsKeyTags    = "tags"
+	GitopsKeySecrets = "secrets"
 )
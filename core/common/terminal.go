@@ -0,0 +1,7 @@
+package common
+
+const (
+	TerminalSessionQueryByUser      = "userID"
+	TerminalSessionQueryByPod       = "pod"
+	TerminalSessionQueryByContainer = "container"
+)
@@ -24,21 +24,30 @@ import (
 	"github.com/horizoncd/horizon/pkg/config/autofree"
 	"github.com/horizoncd/horizon/pkg/config/clean"
 	"github.com/horizoncd/horizon/pkg/config/db"
+	"github.com/horizoncd/horizon/pkg/config/drift"
+	"github.com/horizoncd/horizon/pkg/config/email"
+	"github.com/horizoncd/horizon/pkg/config/encryption"
 	"github.com/horizoncd/horizon/pkg/config/eventhandler"
+	"github.com/horizoncd/horizon/pkg/config/flux"
 	"github.com/horizoncd/horizon/pkg/config/git"
 	"github.com/horizoncd/horizon/pkg/config/gitlab"
+	"github.com/horizoncd/horizon/pkg/config/gitstatus"
 	"github.com/horizoncd/horizon/pkg/config/grafana"
+	"github.com/horizoncd/horizon/pkg/config/helm"
 	"github.com/horizoncd/horizon/pkg/config/job"
 	"github.com/horizoncd/horizon/pkg/config/k8sevent"
 	"github.com/horizoncd/horizon/pkg/config/oauth"
 	"github.com/horizoncd/horizon/pkg/config/pipeline"
 	"github.com/horizoncd/horizon/pkg/config/pprof"
+	"github.com/horizoncd/horizon/pkg/config/preview"
+	"github.com/horizoncd/horizon/pkg/config/quota"
 	"github.com/horizoncd/horizon/pkg/config/redis"
 	"github.com/horizoncd/horizon/pkg/config/server"
 	"github.com/horizoncd/horizon/pkg/config/session"
 	"github.com/horizoncd/horizon/pkg/config/tekton"
 	"github.com/horizoncd/horizon/pkg/config/template"
 	"github.com/horizoncd/horizon/pkg/config/templaterepo"
+	"github.com/horizoncd/horizon/pkg/config/terminal"
 	"github.com/horizoncd/horizon/pkg/config/token"
 	"github.com/horizoncd/horizon/pkg/config/webhook"
 
@@ -55,10 +64,13 @@ type Config struct {
 	GitopsRepoConfig       gitlab.GitopsRepoConfig `yaml:"gitopsRepoConfig"`
 	ArgoCDMapper           argocd.Mapper           `yaml:"argoCDMapper"`
 	RegionArgoCDMapper     argocd.RegionMapper     `yaml:"regionArgoCDMapper"`
+	FluxConfig             flux.Config             `yaml:"fluxConfig"`
+	HelmConfig             helm.Config             `yaml:"helmConfig"`
 	RedisConfig            redis.Redis             `yaml:"redisConfig"`
 	TektonMapper           tekton.Mapper           `yaml:"tektonMapper"`
 	TemplateRepo           templaterepo.Repo       `yaml:"templateRepo"`
 	AccessSecretKeys       authenticate.KeysConfig `yaml:"accessSecretKeys"`
+	MFAConfig              authenticate.MFAConfig  `yaml:"mfa"`
 	GrafanaConfig          grafana.Config          `yaml:"grafanaConfig"`
 	Oauth                  oauth.Server            `yaml:"oauth"`
 	AutoFreeConfig         autofree.Config         `yaml:"autoFree"`
@@ -72,6 +84,13 @@ type Config struct {
 	Clean                  clean.Config            `yaml:"clean"`
 	Admission              admission.Admission     `yaml:"admission"`
 	PipelineConfig         pipeline.Config         `yaml:"pipelineConfig"`
+	PreviewConfig          preview.Config          `yaml:"preview"`
+	GitStatusConfig        gitstatus.Config        `yaml:"gitStatus"`
+	TerminalConfig         terminal.Config         `yaml:"terminal"`
+	EmailConfig            email.Config            `yaml:"email"`
+	EncryptionConfig       encryption.Config       `yaml:"encryption"`
+	DriftConfig            drift.Config            `yaml:"driftConfig"`
+	QuotaConfig            quota.Config            `yaml:"quotaConfig"`
 }
 
 func LoadConfig(configFilePath string) (*Config, error) {
@@ -19,6 +19,7 @@ import (
 	"net/http"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/core/middleware"
 	"github.com/horizoncd/horizon/core/middleware/prehandle"
 	hauth "github.com/horizoncd/horizon/pkg/auth"
@@ -102,7 +103,11 @@ func (c *controller) Review(ctx context.Context, apis []API) (map[string]map[str
 		}
 		decision, reason, err := c.authorizer.Authorize(ctx, authRecord)
 		if err != nil {
-			return nil, perror.WithMessagef(err, "failed to authorize, url: %s, method: %s", api.URL, api.Method)
+			// the resource to review does not exist, it's not accessible rather than failed
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+				return nil, perror.WithMessagef(err, "failed to authorize, url: %s, method: %s", api.URL, api.Method)
+			}
+			decision = hauth.DecisionDeny
 		}
 		reviewResult.Allowed = decision == hauth.DecisionAllow
 		reviewResult.Reason = reason
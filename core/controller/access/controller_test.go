@@ -81,7 +81,7 @@ func TestMain(m *testing.M) {
 		ID:   uint(110),
 	})
 
-	rbacAuthorizer := rbac.NewAuthorizer(roleService, memberService)
+	rbacAuthorizer := rbac.NewAuthorizer(roleService, memberService, manager)
 	skippers := middleware.MethodAndPathSkipper("*",
 		regexp.MustCompile("(^/apis/front/.*)|(^/health)|(^/metrics)|(^/apis/login)|"+
 			"(^/apis/core/v1/roles)|(^/apis/internal/.*)"))
@@ -90,7 +90,7 @@ func TestMain(m *testing.M) {
 	group, err = manager.GroupMgr.Create(ctx, &groupmodels.Group{
 		Name:            "group",
 		Path:            "/group",
-		VisibilityLevel: "private",
+		VisibilityLevel: "public",
 	})
 	if err != nil {
 		panic(err)
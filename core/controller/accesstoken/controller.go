@@ -98,7 +98,7 @@ func (c *controller) CreateResourceAccessToken(ctx context.Context, request Crea
 	userID = robot.ID
 
 	token, err := c.tokenSvc.CreateAccessToken(ctx, request.Name,
-		request.ExpiresAt, userID, request.Scopes)
+		request.ExpiresAt, userID, request.Scopes, request.AllowedCIDRs)
 	if err != nil {
 		return nil, err
 	}
@@ -112,9 +112,10 @@ func (c *controller) CreateResourceAccessToken(ctx context.Context, request Crea
 		ResourceAccessToken: ResourceAccessToken{
 			CreateResourceAccessTokenRequest: CreateResourceAccessTokenRequest{
 				CreatePersonalAccessTokenRequest: CreatePersonalAccessTokenRequest{
-					Name:      token.Name,
-					Scopes:    request.Scopes,
-					ExpiresAt: parseExpiredAt(token.CreatedAt, token.ExpiresIn),
+					Name:         token.Name,
+					Scopes:       request.Scopes,
+					ExpiresAt:    parseExpiredAt(token.CreatedAt, token.ExpiresIn),
+					AllowedCIDRs: tokenservice.SplitAllowedCIDRs(token.AllowedCIDRs),
 				},
 				Role: request.Role,
 			},
@@ -140,7 +141,7 @@ func (c *controller) CreatePersonalAccessToken(ctx context.Context,
 	}
 
 	token, err := c.tokenSvc.CreateAccessToken(ctx, request.Name, request.ExpiresAt,
-		currentUser.GetID(), request.Scopes)
+		currentUser.GetID(), request.Scopes, request.AllowedCIDRs)
 	if err != nil {
 		return nil, err
 	}
@@ -148,9 +149,10 @@ func (c *controller) CreatePersonalAccessToken(ctx context.Context,
 	resp := &CreatePersonalAccessTokenResponse{
 		PersonalAccessToken: PersonalAccessToken{
 			CreatePersonalAccessTokenRequest: CreatePersonalAccessTokenRequest{
-				Name:      token.Name,
-				Scopes:    request.Scopes,
-				ExpiresAt: parseExpiredAt(token.CreatedAt, token.ExpiresIn),
+				Name:         token.Name,
+				Scopes:       request.Scopes,
+				ExpiresAt:    parseExpiredAt(token.CreatedAt, token.ExpiresIn),
+				AllowedCIDRs: tokenservice.SplitAllowedCIDRs(token.AllowedCIDRs),
 			},
 			CreatedAt: token.CreatedAt,
 			CreatedBy: &usermodels.UserBasic{
@@ -184,9 +186,10 @@ func (c *controller) ListPersonalAccessTokens(ctx context.Context,
 		}
 		accessTokens = append(accessTokens, PersonalAccessToken{
 			CreatePersonalAccessTokenRequest: CreatePersonalAccessTokenRequest{
-				Name:      token.Name,
-				Scopes:    strings.Split(token.Scope, " "),
-				ExpiresAt: parseExpiredAt(token.CreatedAt, token.ExpiresIn),
+				Name:         token.Name,
+				Scopes:       strings.Split(token.Scope, " "),
+				ExpiresAt:    parseExpiredAt(token.CreatedAt, token.ExpiresIn),
+				AllowedCIDRs: tokenservice.SplitAllowedCIDRs(token.AllowedCIDRs),
 			},
 			CreatedAt: token.CreatedAt,
 			CreatedBy: &usermodels.UserBasic{
@@ -194,7 +197,9 @@ func (c *controller) ListPersonalAccessTokens(ctx context.Context,
 				Name:  creator.Name,
 				Email: creator.Email,
 			},
-			ID: token.ID,
+			LastUsedAt: token.LastUsedAt,
+			LastUsedIP: token.LastUsedIP,
+			ID:         token.ID,
 		})
 	}
 
@@ -219,9 +224,10 @@ func (c *controller) ListResourceAccessTokens(ctx context.Context, resourceType
 		accessTokens = append(accessTokens, ResourceAccessToken{
 			CreateResourceAccessTokenRequest: CreateResourceAccessTokenRequest{
 				CreatePersonalAccessTokenRequest: CreatePersonalAccessTokenRequest{
-					Name:      token.Name,
-					Scopes:    strings.Split(token.Scope, " "),
-					ExpiresAt: parseExpiredAt(token.CreatedAt, token.ExpiresIn),
+					Name:         token.Name,
+					Scopes:       strings.Split(token.Scope, " "),
+					ExpiresAt:    parseExpiredAt(token.CreatedAt, token.ExpiresIn),
+					AllowedCIDRs: tokenservice.SplitAllowedCIDRs(token.AllowedCIDRs),
 				},
 				Role: token.Role,
 			},
@@ -231,7 +237,9 @@ func (c *controller) ListResourceAccessTokens(ctx context.Context, resourceType
 				Name:  creator.Name,
 				Email: creator.Email,
 			},
-			ID: token.ID,
+			LastUsedAt: token.LastUsedAt,
+			LastUsedIP: token.LastUsedIP,
+			ID:         token.ID,
 		})
 	}
 
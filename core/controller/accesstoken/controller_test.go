@@ -95,11 +95,12 @@ func TestMain(m *testing.M) {
 	authorizeCodeExpireIn := time.Second * 3
 	accessTokenExpireIn := time.Hour * 24
 	refreshTokenExpireIn := time.Hour * 24 * 30
+	deviceCodeExpireIn := time.Minute * 10
 
 	tokenStore := tokenstore.NewStore(db)
 	oauthAppDAO := oauthdao.NewDAO(db)
 	oauthMgr := oauthmanager.NewManager(oauthAppDAO, tokenStore, generator.NewAuthorizeGenerator(),
-		authorizeCodeExpireIn, accessTokenExpireIn, refreshTokenExpireIn)
+		authorizeCodeExpireIn, accessTokenExpireIn, refreshTokenExpireIn, deviceCodeExpireIn)
 
 	parameter := &param.Param{
 		Manager:       manager,
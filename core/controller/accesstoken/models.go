@@ -30,6 +30,8 @@ type CreatePersonalAccessTokenRequest struct {
 	Name      string   `json:"name"`
 	Scopes    []string `json:"scopes"`
 	ExpiresAt string   `json:"expiresAt"`
+	// AllowedCIDRs restricts the networks the token can be used from, e.g. 10.0.0.0/8
+	AllowedCIDRs []string `json:"allowedCIDRs,omitempty"`
 }
 
 type CreateResourceAccessTokenRequest struct {
@@ -39,16 +41,20 @@ type CreateResourceAccessTokenRequest struct {
 
 type PersonalAccessToken struct {
 	CreatePersonalAccessTokenRequest
-	ID        uint                  `json:"id"`
-	CreatedAt time.Time             `json:"createdAt"`
-	CreatedBy *usermodels.UserBasic `json:"createdBy"`
+	ID         uint                  `json:"id"`
+	CreatedAt  time.Time             `json:"createdAt"`
+	CreatedBy  *usermodels.UserBasic `json:"createdBy"`
+	LastUsedAt *time.Time            `json:"lastUsedAt,omitempty"`
+	LastUsedIP string                `json:"lastUsedIP,omitempty"`
 }
 
 type ResourceAccessToken struct {
 	CreateResourceAccessTokenRequest
-	ID        uint                  `json:"id"`
-	CreatedAt time.Time             `json:"createdAt"`
-	CreatedBy *usermodels.UserBasic `json:"createdBy"`
+	ID         uint                  `json:"id"`
+	CreatedAt  time.Time             `json:"createdAt"`
+	CreatedBy  *usermodels.UserBasic `json:"createdBy"`
+	LastUsedAt *time.Time            `json:"lastUsedAt,omitempty"`
+	LastUsedIP string                `json:"lastUsedIP,omitempty"`
 }
 
 type CreatePersonalAccessTokenResponse struct {
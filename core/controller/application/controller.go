@@ -37,6 +37,7 @@ import (
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	groupsvc "github.com/horizoncd/horizon/pkg/group/service"
 	membermanager "github.com/horizoncd/horizon/pkg/member/manager"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
@@ -54,6 +55,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/util/permission"
 	"github.com/horizoncd/horizon/pkg/util/validate"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"github.com/horizoncd/horizon/pkg/visibility"
 )
 
 type Controller interface {
@@ -104,6 +106,7 @@ type controller struct {
 	pipelinemanager       pipelinemanager.Manager
 	buildSchema           *build.Schema
 	templateUpgradeMapper template.UpgradeMapper
+	visibilityChecker     visibility.Checker
 }
 
 var _ Controller = (*controller)(nil)
@@ -126,6 +129,7 @@ func NewController(config *config.Config, param *param.Param) Controller {
 		pipelinemanager:       param.PipelineMgr,
 		buildSchema:           param.BuildSchema,
 		templateUpgradeMapper: config.TemplateUpgradeMapper,
+		visibilityChecker:     visibility.NewChecker(param.Manager),
 	}
 }
 
@@ -198,10 +202,11 @@ func (c *controller) GetApplicationV2(ctx context.Context, id uint) (_ *GetAppli
 	}
 
 	resp := &GetApplicationResponseV2{
-		ID:          id,
-		Name:        app.Name,
-		Description: app.Description,
-		Priority:    string(app.Priority),
+		ID:              id,
+		Name:            app.Name,
+		Description:     app.Description,
+		Priority:        string(app.Priority),
+		VisibilityLevel: app.VisibilityLevel,
 		Git: func() *codemodels.Git {
 			if app.GitURL == "" {
 				return nil
@@ -353,6 +358,11 @@ func (c *controller) CreateApplicationV2(ctx context.Context, groupID uint,
 			return nil, err
 		}
 	}
+	if request.VisibilityLevel != nil {
+		if err := validateVisibilityLevel(*request.VisibilityLevel); err != nil {
+			return nil, err
+		}
+	}
 	if request.Git != nil {
 		if err := validate.CheckGitURL(request.Git.URL); err != nil {
 			return nil, err
@@ -533,6 +543,11 @@ func (c *controller) UpdateApplicationV2(ctx context.Context, id uint,
 			return err
 		}
 	}
+	if request.VisibilityLevel != nil {
+		if err := validateVisibilityLevel(*request.VisibilityLevel); err != nil {
+			return err
+		}
+	}
 	if request.Git != nil {
 		if err := validate.CheckGitURL(request.Git.URL); err != nil {
 			return err
@@ -708,6 +723,16 @@ func validatePriority(priority string) error {
 	return nil
 }
 
+// validateVisibilityLevel validate visibility level, empty means public unless the group is private
+func validateVisibilityLevel(visibilityLevel string) error {
+	switch visibilityLevel {
+	case "", groupmodels.VisibilityPrivate, groupmodels.VisibilityPublic:
+	default:
+		return perror.Wrap(herrors.ErrParamInvalid, "invalid visibility level")
+	}
+	return nil
+}
+
 // validateApplicationName validate application name
 // 1. name length must be less than 40
 // 2. name must match pattern ^(([a-z][-a-z0-9]*)?[a-z0-9])?$
@@ -780,6 +805,18 @@ func (c *controller) List(ctx context.Context, query *q.Query) (
 		}
 	}
 
+	// hide private applications and applications under private groups from non-members
+	hidden, err := c.visibilityChecker.HiddenResources(ctx)
+	if err != nil {
+		return nil, 0, perror.WithMessage(err, "failed to list hidden applications of current user")
+	}
+	if len(hidden.ApplicationIDs) > 0 {
+		if query.Keywords == nil {
+			query.Keywords = q.KeyWords{}
+		}
+		query.Keywords[common.ApplicationQueryHiddenApplications] = hidden.ApplicationIDs
+	}
+
 	listApplicationResp = []*ListApplicationResponse{}
 	// 1. get application in db
 	count, applications, err := c.applicationMgr.List(ctx, subGroupIDs, query)
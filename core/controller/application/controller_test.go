@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/config"
 	"github.com/horizoncd/horizon/lib/orm"
 	"github.com/horizoncd/horizon/lib/q"
 	appgitrepomock "github.com/horizoncd/horizon/mock/pkg/application/gitrepo"
@@ -41,6 +42,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	teammodels "github.com/horizoncd/horizon/pkg/team/models"
 	tmodels "github.com/horizoncd/horizon/pkg/template/models"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	trschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
@@ -288,7 +290,7 @@ func TestMain(m *testing.M) {
 	if err := db.AutoMigrate(&trmodels.TemplateRelease{}); err != nil {
 		panic(err)
 	}
-	if err := db.AutoMigrate(&membermodels.Member{}); err != nil {
+	if err := db.AutoMigrate(&membermodels.Member{}, &teammodels.TeamMember{}); err != nil {
 		panic(err)
 	}
 	if err := db.AutoMigrate(&tagmodels.Tag{}); err != nil {
@@ -681,7 +683,7 @@ func TestListUserApplication(t *testing.T) {
 		applications = append(applications, application)
 	}
 
-	c = NewController(nil, &param.Param{
+	c = NewController(&config.Config{}, &param.Param{
 		Manager:  manager,
 		GroupSvc: groupservice.NewService(manager),
 	})
@@ -749,4 +751,40 @@ func TestListUserApplication(t *testing.T) {
 	for _, resp := range resps {
 		t.Logf("%v", resp)
 	}
+
+	// private applications are hidden from non-members
+	_, err = manager.ApplicationMgr.Create(ctx, &models.Application{
+		GroupID:         groups[3].ID,
+		Name:            "appFuzzilyPrivate",
+		Priority:        "P3",
+		GitURL:          "ssh://git.com",
+		GitSubfolder:    "/test",
+		GitRef:          "master",
+		Template:        "javaapp",
+		TemplateRelease: "v1.0.0",
+		VisibilityLevel: groupmodels.VisibilityPrivate,
+		CreatedBy:       uint(2),
+	}, nil)
+	assert.Nil(t, err)
+	query := &q.Query{
+		Keywords: q.KeyWords{
+			common.ApplicationQueryName: "appFuzzily",
+		},
+		PageNumber: 1,
+		PageSize:   common.DefaultPageSize,
+	}
+	_, count, err = c.List(ctx, query)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, count)
+	// nolint
+	otherCtx := context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "Tom",
+		ID:   uint(3),
+	})
+	resps, count, err = c.List(otherCtx, query)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, count)
+	for _, resp := range resps {
+		assert.NotEqual(t, "appFuzzilyPrivate", resp.Name)
+	}
 }
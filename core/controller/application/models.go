@@ -98,6 +98,7 @@ func (m *UpdateApplicationRequest) toApplicationModel(appExistsInDB *models.Appl
 	application := &models.Application{
 		Description:     appExistsInDB.Description,
 		Priority:        appExistsInDB.Priority,
+		VisibilityLevel: appExistsInDB.VisibilityLevel,
 		GitURL:          appExistsInDB.GitURL,
 		GitSubfolder:    appExistsInDB.GitSubfolder,
 		GitRef:          appExistsInDB.GitRef,
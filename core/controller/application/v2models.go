@@ -36,8 +36,9 @@ type GetApplicationResponseV2 struct {
 	TemplateConfig map[string]interface{}   `json:"templateConfig"`
 	Manifest       map[string]interface{}   `json:"manifest"`
 
-	FullPath string `json:"fullPath"`
-	GroupID  uint   `json:"groupID"`
+	FullPath        string `json:"fullPath"`
+	GroupID         uint   `json:"groupID"`
+	VisibilityLevel string `json:"visibilityLevel"`
 
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
@@ -55,6 +56,9 @@ type CreateOrUpdateApplicationRequestV2 struct {
 	TemplateInfo   *codemodels.TemplateInfo `json:"templateInfo"`
 	TemplateConfig map[string]interface{}   `json:"templateConfig"`
 
+	// VisibilityLevel is private or public, private applications are hidden from non-members
+	VisibilityLevel *string `json:"visibilityLevel"`
+
 	// TODO(remove it): only for internal usage
 	ExtraMembers map[string]string `json:"extraMembers"`
 }
@@ -70,6 +74,12 @@ func (req *CreateOrUpdateApplicationRequestV2) CreateToApplicationModel(groupID
 			}
 			return ""
 		}(),
+		VisibilityLevel: func() string {
+			if req.VisibilityLevel != nil {
+				return *req.VisibilityLevel
+			}
+			return ""
+		}(),
 		GitURL: func() string {
 			if req.Git != nil {
 				return req.Git.URL
@@ -120,6 +130,7 @@ func (req *CreateOrUpdateApplicationRequestV2) UpdateToApplicationModel(
 	application := &models.Application{
 		Description:     appExistsInDB.Description,
 		Priority:        appExistsInDB.Priority,
+		VisibilityLevel: appExistsInDB.VisibilityLevel,
 		GitURL:          appExistsInDB.GitURL,
 		GitSubfolder:    appExistsInDB.GitSubfolder,
 		GitRef:          appExistsInDB.GitRef,
@@ -132,6 +143,9 @@ func (req *CreateOrUpdateApplicationRequestV2) UpdateToApplicationModel(
 	if req.Priority != nil {
 		application.Priority = models.Priority(*req.Priority)
 	}
+	if req.VisibilityLevel != nil {
+		application.VisibilityLevel = *req.VisibilityLevel
+	}
 	if req.Git != nil {
 		application.GitURL = req.Git.URL
 		application.GitRefType = req.Git.RefType()
@@ -37,6 +37,8 @@ import (
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
@@ -87,6 +89,9 @@ func TestMain(m *testing.M) {
 	if err := db.AutoMigrate(&trmodels.TemplateRelease{}); err != nil {
 		panic(err)
 	}
+	if err := db.AutoMigrate(&eventmodels.Event{}); err != nil {
+		panic(err)
+	}
 	ctx = context.TODO()
 	ctx = context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
 		Name: "Tony",
@@ -282,6 +287,7 @@ func Test(t *testing.T) {
 	p := &param.Param{Manager: manager}
 	p.ClusterGitRepo = clusterGitRepo
 	p.TemplateReleaseMgr = templateReleaseMgr
+	p.EventSvc = eventservice.New(manager)
 	c := NewController(tektonFty, p)
 	err = c.CloudEvent(ctx, &WrappedPipelineRun{
 		PipelineRun: pipelineRun,
@@ -16,6 +16,7 @@ package cluster
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/horizoncd/horizon/pkg/config/pipeline"
 	membermanager "github.com/horizoncd/horizon/pkg/member/manager"
@@ -37,14 +38,18 @@ import (
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
 	registryfty "github.com/horizoncd/horizon/pkg/cluster/registry/factory"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
+	clusterdriftmanager "github.com/horizoncd/horizon/pkg/clusterdrift/manager"
+	clustersecretmanager "github.com/horizoncd/horizon/pkg/clustersecret/manager"
 	collectionmanager "github.com/horizoncd/horizon/pkg/collection/manager"
 	"github.com/horizoncd/horizon/pkg/config/grafana"
+	"github.com/horizoncd/horizon/pkg/config/preview"
 	"github.com/horizoncd/horizon/pkg/config/template"
 	"github.com/horizoncd/horizon/pkg/config/token"
 	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	"github.com/horizoncd/horizon/pkg/environment/service"
 	environmentregionmapper "github.com/horizoncd/horizon/pkg/environmentregion/manager"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	gittriggermanager "github.com/horizoncd/horizon/pkg/gittrigger/manager"
 	grafanaservice "github.com/horizoncd/horizon/pkg/grafana"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	groupsvc "github.com/horizoncd/horizon/pkg/group/service"
@@ -53,6 +58,8 @@ import (
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
 	pipelinemanager "github.com/horizoncd/horizon/pkg/pr/pipeline/manager"
 	prservice "github.com/horizoncd/horizon/pkg/pr/service"
+	previewmanager "github.com/horizoncd/horizon/pkg/preview/manager"
+	"github.com/horizoncd/horizon/pkg/quota/clusterusage"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
@@ -62,6 +69,7 @@ import (
 	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	usersvc "github.com/horizoncd/horizon/pkg/user/service"
+	"github.com/horizoncd/horizon/pkg/visibility"
 )
 
 type Controller interface {
@@ -106,6 +114,8 @@ type Controller interface {
 	GetDiff(ctx context.Context, clusterID uint, refType, ref string) (*GetDiffResponse, error)
 	GetContainerLog(ctx context.Context, clusterID uint, podName, containerName string, tailLines int64) (
 		<-chan string, error)
+	// StreamContainerLog streams logs of a container or all pods of cluster, which supports following and filtering
+	StreamContainerLog(ctx context.Context, clusterID uint, r *ContainerLogRequest) (<-chan string, error)
 
 	DeleteClusterPods(ctx context.Context, clusterID uint, podName []string) (BatchResponse, error)
 	GetClusterPod(ctx context.Context, clusterID uint, podName string) (
@@ -130,6 +140,21 @@ type Controller interface {
 	Upgrade(ctx context.Context, clusterID uint) error
 	ToggleLikeStatus(ctx context.Context, clusterID uint, like *WhetherLike) (err error)
 	CreatePipelineRun(ctx context.Context, clusterID uint, r *CreatePipelineRunRequest) (*prmodels.PipelineBasic, error)
+
+	ListGitTriggers(ctx context.Context, clusterID uint) ([]*GitTrigger, error)
+	CreateGitTrigger(ctx context.Context, clusterID uint, r *CreateGitTriggerRequest) (*GitTrigger, error)
+	UpdateGitTrigger(ctx context.Context, clusterID, triggerID uint, r *UpdateGitTriggerRequest) (*GitTrigger, error)
+	DeleteGitTrigger(ctx context.Context, clusterID, triggerID uint) error
+
+	GetPreviewSetting(ctx context.Context, clusterID uint) (*PreviewSetting, error)
+	// UpdatePreviewSetting creates or updates the preview setting of the base cluster
+	UpdatePreviewSetting(ctx context.Context, clusterID uint, r *UpdatePreviewSettingRequest) (*PreviewSetting, error)
+	DeletePreviewSetting(ctx context.Context, clusterID uint) error
+	ListPreviewClusters(ctx context.Context, clusterID uint) ([]*PreviewCluster, error)
+
+	// HandleGitWebhook starts BuildDeploy of the clusters whose git triggers match the push event,
+	// or creates, updates and deletes the preview clusters of merge requests
+	HandleGitWebhook(ctx context.Context, kind string, header http.Header, body []byte) (*GitWebhookResponse, error)
 }
 
 type controller struct {
@@ -172,12 +197,20 @@ type controller struct {
 	collectionManager     collectionmanager.Manager
 	clusterSvc            clusterservice.Service
 	pipelineConfig        pipeline.Config
+	gitTriggerMgr         gittriggermanager.Manager
+	previewMgr            previewmanager.Manager
+	previewConfig         preview.Config
+	clusterSecretMgr      clustersecretmanager.Manager
+	clusterDriftMgr       clusterdriftmanager.Manager
+	visibilityChecker     visibility.Checker
+	quotaRecorder         *clusterusage.Recorder
+	quotaEnforcer         *clusterusage.Enforcer
 }
 
 var _ Controller = (*controller)(nil)
 
 func NewController(config *config.Config, param *param.Param) Controller {
-	return &controller{
+	c := &controller{
 		clusterMgr:            param.ClusterMgr,
 		clusterGitRepo:        param.ClusterGitRepo,
 		applicationGitRepo:    param.ApplicationGitRepo,
@@ -217,5 +250,17 @@ func NewController(config *config.Config, param *param.Param) Controller {
 		collectionManager:     param.CollectionMgr,
 		clusterSvc:            param.ClusterSvc,
 		pipelineConfig:        config.PipelineConfig,
+		gitTriggerMgr:         param.GitTriggerMgr,
+		previewMgr:            param.PreviewMgr,
+		previewConfig:         config.PreviewConfig,
+		clusterSecretMgr:      param.ClusterSecretMgr,
+		clusterDriftMgr:       param.ClusterDriftMgr,
+		visibilityChecker:     visibility.NewChecker(param.Manager),
+	}
+	if config.QuotaConfig.Enabled {
+		calculator := clusterusage.NewCalculator(param.TemplateRepo, param.ClusterGitRepo)
+		c.quotaRecorder = clusterusage.NewRecorder(calculator, param.Manager)
+		c.quotaEnforcer = clusterusage.NewEnforcer(calculator, param.Manager)
 	}
+	return c
 }
@@ -38,9 +38,11 @@ import (
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/quota/clusterusage"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	"github.com/horizoncd/horizon/pkg/util/jsonschema"
 	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/mergemap"
@@ -112,6 +114,21 @@ func (c *controller) List(ctx context.Context, query *q.Query) ([]*ListClusterWi
 		}
 	}
 
+	// hide clusters of private applications and applications under private groups from non-members
+	if !currentUser.IsAdmin() {
+		hidden, err := c.visibilityChecker.HiddenResources(ctx)
+		if err != nil {
+			return nil, 0,
+				perror.WithMessage(err, "failed to list hidden applications of current user")
+		}
+		if len(hidden.ApplicationIDs) > 0 {
+			if query.Keywords == nil {
+				query.Keywords = q.KeyWords{}
+			}
+			query.Keywords[common.ClusterQueryHiddenApplications] = hidden.ApplicationIDs
+		}
+	}
+
 	count, clusters, err := c.clusterMgr.List(ctx, query, applicationIDs...)
 	if err != nil {
 		return nil, 0,
@@ -487,7 +504,13 @@ func (c *controller) CreateCluster(ctx context.Context, applicationID uint, envi
 		return nil, err
 	}
 
-	// 6. create cluster, after created, params.Cluster is the newest cluster
+	// 6. check quotas of the cluster
+	if err := c.admitQuotaOfCreation(ctx, application, tr, environment, region, r.Namespace, r.Name,
+		r.TemplateInput.Application); err != nil {
+		return nil, err
+	}
+
+	// 7. create cluster, after created, params.Cluster is the newest cluster
 	cluster, tags := r.toClusterModel(application, er, expireSeconds)
 	cluster.Status = common.ClusterStatusCreating
 
@@ -495,14 +518,14 @@ func (c *controller) CreateCluster(ctx context.Context, applicationID uint, envi
 		return nil, err
 	}
 
-	// 7. create cluster in db
+	// 8. create cluster in db
 	cluster, err = c.clusterMgr.Create(ctx, cluster, tags, r.ExtraMembers)
 	if err != nil {
 		return nil, err
 	}
 
 	// TODO: refactor by asynchronous task, and notify api callers to adapt
-	// 8. create cluster in git repo
+	// 9. create cluster in git repo
 	err = c.clusterGitRepo.CreateCluster(ctx, &gitrepo.CreateClusterParams{
 		BaseParams: &gitrepo.BaseParams{
 			ClusterID:           cluster.ID,
@@ -535,15 +558,16 @@ func (c *controller) CreateCluster(ctx context.Context, applicationID uint, envi
 	if err != nil {
 		return nil, err
 	}
+	c.recordQuotaUsage(ctx, cluster)
 
-	// 9. get full path
+	// 10. get full path
 	group, err := c.groupSvc.GetChildByID(ctx, application.GroupID)
 	if err != nil {
 		return nil, err
 	}
 	fullPath := fmt.Sprintf("%v/%v/%v", group.FullPath, application.Name, cluster.Name)
 
-	// 10. get namespace
+	// 11. get namespace
 	envValue, err := c.clusterGitRepo.GetEnvValue(ctx, application.Name, cluster.Name, tr.ChartName)
 	if err != nil {
 		return nil, err
@@ -552,7 +576,7 @@ func (c *controller) CreateCluster(ctx context.Context, applicationID uint, envi
 	ret := ofClusterModel(application, cluster, fullPath, envValue.Namespace,
 		r.TemplateInput.Pipeline, r.TemplateInput.Application)
 
-	// 11. record event
+	// 12. record event
 	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, ret.ID,
 		eventmodels.ClusterCreated, nil)
 	c.eventSvc.RecordMemberCreatedEvent(ctx, common.ResourceCluster, ret.ID)
@@ -634,6 +658,10 @@ func (c *controller) UpdateCluster(ctx context.Context, clusterID uint,
 	}
 
 	clusterModel, tags := r.toClusterModel(cluster, templateRelease, er)
+	quotaChanges := &clusterusage.ClusterValues{}
+	if templateRelease != cluster.TemplateRelease {
+		quotaChanges.TemplateRelease = tr
+	}
 
 	// 4. if templateInput is not empty, validate templateInput and update templateInput in git repo
 	if r.TemplateInput != nil {
@@ -669,6 +697,11 @@ func (c *controller) UpdateCluster(ctx context.Context, clusterID uint,
 			return nil, perror.Wrapf(herrors.ErrParamInvalid,
 				"request body validate err: %v", err)
 		}
+		// check quotas of the cluster
+		quotaChanges.TemplateConfig = r.TemplateInput.Application
+		if err := c.admitQuotaOfUpdate(ctx, application, cluster, er.EnvironmentName, quotaChanges); err != nil {
+			return nil, err
+		}
 		// update cluster in git repo
 		if err := c.clusterGitRepo.UpdateCluster(ctx, &gitrepo.UpdateClusterParams{
 			BaseParams: &gitrepo.BaseParams{
@@ -686,6 +719,9 @@ func (c *controller) UpdateCluster(ctx context.Context, clusterID uint,
 			return nil, err
 		}
 	} else {
+		if err := c.admitQuotaOfUpdate(ctx, application, cluster, er.EnvironmentName, quotaChanges); err != nil {
+			return nil, err
+		}
 		files, err := c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, tr.ChartName)
 		if err != nil {
 			return nil, err
@@ -702,6 +738,7 @@ func (c *controller) UpdateCluster(ctx context.Context, clusterID uint,
 	if err != nil {
 		return nil, err
 	}
+	c.recordQuotaUsage(ctx, cluster)
 
 	// 7. update cluster tags
 	tagsInDB, err := c.tagMgr.ListByResourceTypeID(ctx, common.ResourceCluster, clusterID)
@@ -854,7 +891,7 @@ func (c *controller) DeleteCluster(ctx context.Context, clusterID uint, hard boo
 		// 2. delete image
 		rg, err := c.registryFty.GetRegistryByConfig(newctx, &registry.Config{
 			Server:             regionEntity.Registry.Server,
-			Token:              regionEntity.Registry.Token,
+			Token:              string(regionEntity.Registry.Token),
 			InsecureSkipVerify: regionEntity.Registry.InsecureSkipTLSVerify,
 			Kind:               regionEntity.Registry.Kind,
 			Path:               regionEntity.Registry.Path,
@@ -885,6 +922,14 @@ func (c *controller) DeleteCluster(ctx context.Context, clusterID uint, hard boo
 			if err := c.tagMgr.UpsertByResourceTypeID(ctx, common.ResourceCluster, clusterID, nil); err != nil {
 				log.Errorf(newctx, "failed to delete tags of cluster: %v, err: %v", cluster.Name, err)
 			}
+			// delete secrets
+			if err := c.clusterSecretMgr.DeleteByClusterID(ctx, clusterID); err != nil {
+				log.Errorf(newctx, "failed to delete secrets of cluster: %v, err: %v", cluster.Name, err)
+			}
+			// delete drift
+			if err := c.clusterDriftMgr.DeleteByClusterID(ctx, clusterID); err != nil {
+				log.Errorf(newctx, "failed to delete drift of cluster: %v, err: %v", cluster.Name, err)
+			}
 			// delete gitrepo
 			err = c.clusterGitRepo.HardDeleteCluster(newctx, application.Name, cluster.Name)
 			if err != nil {
@@ -1188,3 +1233,43 @@ func (c *controller) addIsFavoriteForClusters(ctx context.Context,
 	}
 	return nil
 }
+
+// admitQuotaOfCreation checks the cluster to be created against quotas of its group and environment
+// and reserves its resources under them, callers include the apis, previews and git triggers.
+// Nothing is checked if quotas are disabled.
+func (c *controller) admitQuotaOfCreation(ctx context.Context, application *models.Application,
+	release *trmodels.TemplateRelease, environment, region, namespace, cluster string,
+	templateConfig map[string]interface{}) error {
+	if c.quotaEnforcer == nil {
+		return nil
+	}
+	return c.quotaEnforcer.Admit(ctx, c.quotaEnforcer.CreationTarget(ctx, application, release,
+		environment, region, namespace, cluster, templateConfig))
+}
+
+// admitQuotaOfUpdate checks the cluster to be moved to the environment or changed
+// against quotas of its group and the environment, and reserves the increased resources under them.
+// Nothing is checked if quotas are disabled.
+func (c *controller) admitQuotaOfUpdate(ctx context.Context, application *models.Application,
+	cluster *cmodels.Cluster, environment string, changes *clusterusage.ClusterValues) error {
+	if c.quotaEnforcer == nil {
+		return nil
+	}
+	target, err := c.quotaEnforcer.UpdateTarget(ctx, application, cluster, environment, changes)
+	if err != nil || target == nil {
+		return err
+	}
+	return c.quotaEnforcer.Admit(ctx, target)
+}
+
+// recordQuotaUsage records resources requested by the cluster right after it's written, so that requests checked
+// afterwards count them, and releases the resources reserved by the quota webhook for the request.
+// Nothing is recorded if quotas are disabled.
+func (c *controller) recordQuotaUsage(ctx context.Context, cluster *cmodels.Cluster) {
+	if c.quotaRecorder == nil {
+		return
+	}
+	if err := c.quotaRecorder.RecordAndRelease(ctx, cluster); err != nil {
+		log.Warningf(ctx, "failed to record quota usage of cluster %d, err: %v", cluster.ID, err)
+	}
+}
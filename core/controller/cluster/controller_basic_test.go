@@ -28,6 +28,7 @@ import (
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/q"
 	mockcd "github.com/horizoncd/horizon/mock/pkg/cd"
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
@@ -35,13 +36,18 @@ import (
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	badgemodels "github.com/horizoncd/horizon/pkg/badge/models"
 	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	environmentmodels "github.com/horizoncd/horizon/pkg/environment/models"
 	envmodels "github.com/horizoncd/horizon/pkg/environmentregion/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	groupservice "github.com/horizoncd/horizon/pkg/group/service"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/quota/clusterusage"
+	quotamodels "github.com/horizoncd/horizon/pkg/quota/models"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	registrydao "github.com/horizoncd/horizon/pkg/registry/dao"
 	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+	"github.com/horizoncd/horizon/pkg/visibility"
 )
 
 func testListClusterByNameFuzzily(t *testing.T) {
@@ -99,13 +105,14 @@ func testListClusterByNameFuzzily(t *testing.T) {
 	}
 
 	c = &controller{
-		clusterMgr:     manager.ClusterMgr,
-		applicationMgr: manager.ApplicationMgr,
-		applicationSvc: applicationservice.NewService(groupservice.NewService(manager), manager),
-		groupManager:   manager.GroupMgr,
-		memberManager:  manager.MemberMgr,
-		eventSvc:       eventservice.New(manager),
-		commitGetter:   commitGetter,
+		clusterMgr:        manager.ClusterMgr,
+		applicationMgr:    manager.ApplicationMgr,
+		applicationSvc:    applicationservice.NewService(groupservice.NewService(manager), manager),
+		groupManager:      manager.GroupMgr,
+		memberManager:     manager.MemberMgr,
+		visibilityChecker: visibility.NewChecker(manager),
+		eventSvc:          eventservice.New(manager),
+		commitGetter:      commitGetter,
 	}
 
 	resps, count, err := c.List(ctx, &q.Query{Keywords: q.KeyWords{common.ClusterQueryName: "fuzzilyCluster"}})
@@ -216,13 +223,14 @@ func testListUserClustersByNameFuzzily(t *testing.T) {
 	assert.Nil(t, err)
 
 	c = &controller{
-		clusterMgr:     manager.ClusterMgr,
-		applicationMgr: manager.ApplicationMgr,
-		applicationSvc: applicationservice.NewService(groupservice.NewService(manager), manager),
-		groupManager:   manager.GroupMgr,
-		memberManager:  manager.MemberMgr,
-		eventSvc:       eventservice.New(manager),
-		commitGetter:   commitGetter,
+		clusterMgr:        manager.ClusterMgr,
+		applicationMgr:    manager.ApplicationMgr,
+		applicationSvc:    applicationservice.NewService(groupservice.NewService(manager), manager),
+		groupManager:      manager.GroupMgr,
+		memberManager:     manager.MemberMgr,
+		visibilityChecker: visibility.NewChecker(manager),
+		eventSvc:          eventservice.New(manager),
+		commitGetter:      commitGetter,
 	}
 
 	resps, count, err := c.List(ctx,
@@ -407,3 +415,47 @@ func testControllerFreeOrDeleteClusterFailed(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 0, len(badges))
 }
+
+func testQuota(t *testing.T) {
+	c = &controller{
+		quotaEnforcer: clusterusage.NewEnforcer(nil, manager),
+	}
+
+	group, err := manager.GroupMgr.Create(ctx, &groupmodels.Group{Name: "quota", Path: "quota"})
+	assert.Nil(t, err)
+	application, err := manager.ApplicationMgr.Create(ctx, &appmodels.Application{
+		GroupID: group.ID,
+		Name:    "quota",
+	}, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, db.Create(&environmentmodels.Environment{Name: "quota-online"}).Error)
+	assert.Nil(t, db.Create(&environmentmodels.Environment{Name: "quota-test"}).Error)
+	_, err = manager.QuotaMgr.Create(ctx, &quotamodels.Quota{
+		ResourceType: quotamodels.ResourceTypeGroup,
+		ResourceID:   group.ID,
+		Environment:  "quota-online",
+		MaxClusters:  1,
+	})
+	assert.Nil(t, err)
+
+	// the cluster is reserved once even if it's checked again, e.g. by the quota webhook and the controller
+	err = c.admitQuotaOfCreation(ctx, application, nil, "quota-online", "hz", "", "quota-cluster1", nil)
+	assert.Nil(t, err)
+	err = c.admitQuotaOfCreation(ctx, application, nil, "quota-online", "hz", "", "quota-cluster1", nil)
+	assert.Nil(t, err)
+	err = c.admitQuotaOfCreation(ctx, application, nil, "quota-online", "hz", "", "quota-cluster2", nil)
+	assert.Equal(t, herrors.ErrQuotaExceeded, perror.Cause(err))
+
+	// clusters moved to the environment are counted as well
+	cluster, err := manager.ClusterMgr.Create(ctx, &clustermodels.Cluster{
+		ApplicationID:   application.ID,
+		Name:            "quota-cluster3",
+		EnvironmentName: "quota-test",
+		RegionName:      "hz",
+	}, nil, nil)
+	assert.Nil(t, err)
+	err = c.admitQuotaOfUpdate(ctx, application, cluster, "quota-online", &clusterusage.ClusterValues{})
+	assert.Equal(t, herrors.ErrQuotaExceeded, perror.Cause(err))
+	err = c.admitQuotaOfUpdate(ctx, application, cluster, "quota-test", &clusterusage.ClusterValues{})
+	assert.Nil(t, err)
+}
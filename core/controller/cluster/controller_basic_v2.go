@@ -16,6 +16,7 @@ package cluster
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -29,6 +30,7 @@ import (
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	"github.com/horizoncd/horizon/pkg/git"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/quota/clusterusage"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 	"github.com/horizoncd/horizon/pkg/templaterelease/models"
 	templateschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
@@ -94,6 +96,24 @@ func (c *controller) GetClusterStatusV2(ctx context.Context, clusterID uint) (*S
 		}
 	}
 
+	drift, err := c.clusterDriftMgr.GetByClusterID(ctx, clusterID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return nil, err
+		}
+		return resp, nil
+	}
+	resp.Drift = &DriftStatus{
+		Drifted:    drift.Drifted,
+		DetectedAt: drift.DetectedAt,
+		CheckedAt:  drift.CheckedAt,
+	}
+	if drift.Drifted && drift.Resources != "" {
+		if err := json.Unmarshal([]byte(drift.Resources), &resp.Drift.Resources); err != nil {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+	}
+
 	return resp, nil
 }
 
@@ -180,17 +200,23 @@ func (c *controller) CreateClusterV2(ctx context.Context,
 		return nil, err
 	}
 
-	// 8. customize db infos
+	// 8. check quotas of the cluster, which is created by the apis, previews and git triggers
+	if err := c.admitQuotaOfCreation(ctx, application, tr, params.Environment, params.Region, "",
+		params.Name, buildTemplateInfo.TemplateConfig); err != nil {
+		return nil, err
+	}
+
+	// 9. customize db infos
 	cluster, tags := params.toClusterModel(application,
 		envEntity, buildTemplateInfo, template, expireSeconds)
 
-	// 9. update db and tags
+	// 10. update db and tags
 	clusterResp, err := c.clusterMgr.Create(ctx, cluster, tags, params.ExtraMembers)
 	if err != nil {
 		return nil, err
 	}
 
-	// 10. create git repo
+	// 11. create git repo
 	err = c.clusterGitRepo.CreateCluster(ctx, &gitrepo.CreateClusterParams{
 		BaseParams: &gitrepo.BaseParams{
 			ClusterID:           clusterResp.ID,
@@ -222,8 +248,9 @@ func (c *controller) CreateClusterV2(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	c.recordQuotaUsage(ctx, updateClusterResp)
 
-	// 11. get full path
+	// 12. get full path
 	group, err := c.groupSvc.GetChildByID(ctx, application.GroupID)
 	if err != nil {
 		return nil, err
@@ -248,11 +275,11 @@ func (c *controller) CreateClusterV2(ctx context.Context,
 		UpdatedAt:     updateClusterResp.UpdatedAt,
 	}
 
-	// 12. record event
+	// 13. record event
 	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, ret.ID,
 		eventmodels.ClusterCreated, nil)
 	c.eventSvc.RecordMemberCreatedEvent(ctx, common.ResourceCluster, ret.ID)
-	// 13. customize response
+	// 14. customize response
 	return ret, nil
 }
 
@@ -502,7 +529,16 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 		return err
 	}
 
-	// 6. update in git repo
+	// 6. check quotas of the cluster
+	quotaChanges := &clusterusage.ClusterValues{TemplateConfig: templateConfig}
+	if templateInfo.Name != cluster.Template || templateInfo.Release != cluster.TemplateRelease {
+		quotaChanges.TemplateRelease = templateRelease
+	}
+	if err := c.admitQuotaOfUpdate(ctx, application, cluster, environmentName, quotaChanges); err != nil {
+		return err
+	}
+
+	// 7. update in git repo
 	if err = c.clusterGitRepo.UpdateCluster(ctx, &gitrepo.UpdateClusterParams{
 		BaseParams: &gitrepo.BaseParams{
 			ClusterID:           cluster.ID,
@@ -518,19 +554,20 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 		return err
 	}
 
-	// 7. record event
+	// 8. record event
 	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, cluster.ID,
 		eventmodels.ClusterUpdated, nil)
 
-	// 8. update cluster in db
+	// 9. update cluster in db
 	clusterModel, tags := r.toClusterModel(cluster, expireSeconds, environmentName,
 		regionName, templateInfo.Name, templateInfo.Release)
 	_, err = c.clusterMgr.UpdateByID(ctx, clusterID, clusterModel)
 	if err != nil {
 		return err
 	}
+	c.recordQuotaUsage(ctx, cluster)
 
-	// 9. update cluster tags
+	// 10. update cluster tags
 	tagsInDB, err := c.tagMgr.ListByResourceTypeID(ctx, common.ResourceCluster, clusterID)
 	if err != nil {
 		return err
@@ -75,11 +75,16 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 		}
 	}
 
-	commit, err := c.commitGetter.GetCommit(ctx, cluster.GitURL, gitRefType, gitRef)
+	var commitRef, commitRefType, pusher = gitRef, gitRefType, ""
+	if r.Trigger != nil {
+		// build the pushed commit even if the branch has been moved on
+		commitRef, commitRefType, pusher = r.Trigger.Commit, codemodels.GitRefTypeCommit, r.Trigger.Pusher
+	}
+	commit, err := c.commitGetter.GetCommit(ctx, cluster.GitURL, commitRefType, commitRef)
 	if err != nil {
 		commit = &git.Commit{
 			Message: "commit not found",
-			ID:      gitRef,
+			ID:      commitRef,
 		}
 	}
 
@@ -107,6 +112,7 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 		GitRefType:       gitRefType,
 		GitRef:           gitRef,
 		GitCommit:        commit.ID,
+		Pusher:           pusher,
 		ImageURL:         imageURL,
 		LastConfigCommit: configCommit.Master,
 		ConfigCommit:     configCommit.Gitops,
@@ -0,0 +1,305 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/encrypt"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/git"
+	"github.com/horizoncd/horizon/pkg/gittrigger/models"
+	previewmodels "github.com/horizoncd/horizon/pkg/preview/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) ListGitTriggers(ctx context.Context, clusterID uint) ([]*GitTrigger, error) {
+	if _, err := c.clusterMgr.GetByID(ctx, clusterID); err != nil {
+		return nil, err
+	}
+	triggers, err := c.gitTriggerMgr.ListByClusterID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*GitTrigger, 0, len(triggers))
+	for _, trigger := range triggers {
+		result = append(result, ofGitTrigger(trigger))
+	}
+	return result, nil
+}
+
+func (c *controller) CreateGitTrigger(ctx context.Context, clusterID uint,
+	r *CreateGitTriggerRequest) (*GitTrigger, error) {
+	const op = "cluster controller: create git trigger"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if cluster.GitURL == "" {
+		return nil, herrors.ErrBuildDeployNotSupported
+	}
+
+	trigger := &models.GitTrigger{
+		ClusterID:     clusterID,
+		BranchPattern: r.BranchPattern,
+		TagRegex:      r.TagRegex,
+		PathFilter:    r.PathFilter,
+		Enabled:       true,
+	}
+	if r.Enabled != nil {
+		trigger.Enabled = *r.Enabled
+	}
+	if err := validateGitTrigger(trigger); err != nil {
+		return nil, err
+	}
+	secret, err := genWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	trigger.Secret = encrypt.String(secret)
+	trigger, err = c.gitTriggerMgr.Create(ctx, trigger)
+	if err != nil {
+		return nil, err
+	}
+	return ofGitTriggerWithSecret(trigger), nil
+}
+
+func (c *controller) UpdateGitTrigger(ctx context.Context, clusterID, triggerID uint,
+	r *UpdateGitTriggerRequest) (*GitTrigger, error) {
+	const op = "cluster controller: update git trigger"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	trigger, err := c.getGitTrigger(ctx, clusterID, triggerID)
+	if err != nil {
+		return nil, err
+	}
+	if r.BranchPattern != nil {
+		trigger.BranchPattern = *r.BranchPattern
+	}
+	if r.TagRegex != nil {
+		trigger.TagRegex = *r.TagRegex
+	}
+	if r.PathFilter != nil {
+		trigger.PathFilter = *r.PathFilter
+	}
+	if r.Enabled != nil {
+		trigger.Enabled = *r.Enabled
+	}
+	if err := validateGitTrigger(trigger); err != nil {
+		return nil, err
+	}
+	if r.ResetSecret {
+		secret, err := genWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		trigger.Secret = encrypt.String(secret)
+	}
+	trigger, err = c.gitTriggerMgr.Update(ctx, trigger)
+	if err != nil {
+		return nil, err
+	}
+	if r.ResetSecret {
+		return ofGitTriggerWithSecret(trigger), nil
+	}
+	return ofGitTrigger(trigger), nil
+}
+
+func (c *controller) DeleteGitTrigger(ctx context.Context, clusterID, triggerID uint) error {
+	if _, err := c.getGitTrigger(ctx, clusterID, triggerID); err != nil {
+		return err
+	}
+	return c.gitTriggerMgr.Delete(ctx, triggerID)
+}
+
+func (c *controller) getGitTrigger(ctx context.Context, clusterID, triggerID uint) (*models.GitTrigger, error) {
+	trigger, err := c.gitTriggerMgr.GetByID(ctx, triggerID)
+	if err != nil {
+		return nil, err
+	}
+	if trigger.ClusterID != clusterID {
+		return nil, herrors.NewErrNotFound(herrors.GitTriggerInDB,
+			fmt.Sprintf("git trigger %d was not found in cluster %d", triggerID, clusterID))
+	}
+	return trigger, nil
+}
+
+func validateGitTrigger(trigger *models.GitTrigger) error {
+	if trigger.BranchPattern == "" && trigger.TagRegex == "" {
+		return perror.Wrap(herrors.ErrParamInvalid, "either branchPattern or tagRegex should be specified")
+	}
+	if trigger.BranchPattern != "" {
+		if _, err := path.Match(trigger.BranchPattern, ""); err != nil {
+			return perror.Wrapf(herrors.ErrParamInvalid, "invalid branchPattern %s: %v", trigger.BranchPattern, err)
+		}
+	}
+	if trigger.TagRegex != "" {
+		if _, err := regexp.Compile(trigger.TagRegex); err != nil {
+			return perror.Wrapf(herrors.ErrParamInvalid, "invalid tagRegex %s: %v", trigger.TagRegex, err)
+		}
+	}
+	return nil
+}
+
+func (c *controller) HandleGitWebhook(ctx context.Context, kind string,
+	header http.Header, body []byte) (*GitWebhookResponse, error) {
+	const op = "cluster controller: handle git webhook"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	parser, err := git.GetWebhookParser(kind)
+	if err != nil {
+		return nil, err
+	}
+	pushEvent, err := parser.ParsePushEvent(header, body)
+	if err != nil {
+		return nil, err
+	}
+	if pushEvent != nil {
+		triggers, err := c.gitTriggerMgr.ListEnabledByGitURLs(ctx, pushEvent.RepoURLs)
+		if err != nil {
+			return nil, err
+		}
+		verified := make([]*models.GitTrigger, 0, len(triggers))
+		for _, trigger := range triggers {
+			if parser.Verify(header, body, string(trigger.Secret)) == nil {
+				verified = append(verified, trigger)
+			}
+		}
+		if len(verified) == 0 {
+			return nil, perror.Wrap(herrors.ErrForbidden, "webhook is not verified by any git trigger")
+		}
+		return c.handlePushEvent(ctx, pushEvent, verified)
+	}
+	mrEvent, err := parser.ParseMergeRequestEvent(header, body)
+	if err != nil {
+		return nil, err
+	}
+	if mrEvent != nil {
+		settings, err := c.previewMgr.ListEnabledSettingsByGitURLs(ctx, mrEvent.RepoURLs)
+		if err != nil {
+			return nil, err
+		}
+		verified := make([]*previewmodels.PreviewSetting, 0, len(settings))
+		for _, setting := range settings {
+			if parser.Verify(header, body, string(setting.Secret)) == nil {
+				verified = append(verified, setting)
+			}
+		}
+		if len(verified) == 0 {
+			return nil, perror.Wrap(herrors.ErrForbidden, "webhook is not verified by any preview setting")
+		}
+		return c.handleMergeRequestEvent(ctx, mrEvent, verified)
+	}
+	return &GitWebhookResponse{
+		Pipelineruns: []*TriggeredPipelinerun{},
+		Previews:     []*TriggeredPreview{},
+	}, nil
+}
+
+// handlePushEvent starts BuildDeploy of the clusters whose triggers match the push event,
+// the triggers have verified the webhook with their secrets
+func (c *controller) handlePushEvent(ctx context.Context, event *git.PushEvent,
+	triggers []*models.GitTrigger) (*GitWebhookResponse, error) {
+	resp := &GitWebhookResponse{
+		Pipelineruns: []*TriggeredPipelinerun{},
+		Previews:     []*TriggeredPreview{},
+	}
+	triggered := make(map[uint]bool)
+	for _, trigger := range triggers {
+		if triggered[trigger.ClusterID] || !trigger.Match(event) {
+			continue
+		}
+		triggered[trigger.ClusterID] = true
+
+		result := &TriggeredPipelinerun{ClusterID: trigger.ClusterID}
+		pipelinerunID, err := c.buildDeployByGitTrigger(ctx, trigger, event)
+		if err != nil {
+			log.Errorf(ctx, "failed to build deploy cluster %d triggered by %s %s: %v",
+				trigger.ClusterID, event.RefType, event.Ref, err)
+			result.Error = _triggerFailed
+		} else {
+			result.PipelinerunID = pipelinerunID
+		}
+		resp.Pipelineruns = append(resp.Pipelineruns, result)
+	}
+	return resp, nil
+}
+
+// genWebhookSecret generates the secret of git trigger or preview setting,
+// which is configured in the webhook of repository to sign or carry the webhook requests
+func genWebhookSecret() (string, error) {
+	bytes := make([]byte, 20)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", perror.Wrap(herrors.ErrGenerateRandomID, err.Error())
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// withUserContext returns a context on behalf of the user, which is used by the actions triggered by webhooks
+func (c *controller) withUserContext(ctx context.Context, userID uint) (context.Context, error) {
+	user, err := c.userManager.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     user.Name,
+		FullName: user.FullName,
+		ID:       user.ID,
+		Email:    user.Email,
+		Admin:    user.Admin,
+	}), nil
+}
+
+// buildDeployByGitTrigger starts BuildDeploy on behalf of the creator of the trigger
+func (c *controller) buildDeployByGitTrigger(ctx context.Context,
+	trigger *models.GitTrigger, event *git.PushEvent) (uint, error) {
+	ctx, err := c.withUserContext(ctx, trigger.CreatedBy)
+	if err != nil {
+		return 0, err
+	}
+
+	title := strings.TrimSpace(strings.SplitN(event.Message, "\n", 2)[0])
+	if title == "" {
+		title = fmt.Sprintf("push %s %s", event.RefType, event.Ref)
+	}
+	request := &BuildDeployRequest{
+		Title:       title,
+		Description: fmt.Sprintf("triggered by %s %s pushed by %s", event.RefType, event.Ref, event.Pusher),
+		// build the pushed commit, the branch or tag may have been moved on by later pushes
+		Git: &BuildDeployRequestGit{Commit: event.Commit},
+		Trigger: &BuildDeployTrigger{
+			Commit: event.Commit,
+			Pusher: event.Pusher,
+		},
+	}
+	resp, err := c.BuildDeploy(ctx, trigger.ClusterID, request)
+	if err != nil {
+		return 0, err
+	}
+	return resp.PipelinerunID, nil
+}
@@ -0,0 +1,127 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/git/gitlab"
+)
+
+func testGitTrigger(t *testing.T) {
+	c = &controller{
+		clusterMgr:    manager.ClusterMgr,
+		gitTriggerMgr: manager.GitTriggerMgr,
+		previewMgr:    manager.PreviewMgr,
+	}
+
+	cluster, err := manager.ClusterMgr.Create(ctx, &clustermodels.Cluster{
+		ApplicationID:   uint(1),
+		Name:            "clusterWithGitTrigger",
+		EnvironmentName: "test",
+		RegionName:      "hz",
+		GitURL:          "ssh://git@cloudnative.com:22222/music-cloud-native/horizon/trigger.git",
+	}, nil, nil)
+	assert.Nil(t, err)
+
+	// invalid triggers
+	_, err = c.CreateGitTrigger(ctx, cluster.ID, &CreateGitTriggerRequest{})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.CreateGitTrigger(ctx, cluster.ID, &CreateGitTriggerRequest{TagRegex: "v(1"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.CreateGitTrigger(ctx, cluster.ID, &CreateGitTriggerRequest{BranchPattern: "release/["})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	trigger, err := c.CreateGitTrigger(ctx, cluster.ID, &CreateGitTriggerRequest{
+		BranchPattern: "release/*",
+		PathFilter:    "app",
+	})
+	assert.Nil(t, err)
+	assert.True(t, trigger.Enabled)
+	secret := trigger.Secret
+	assert.NotEmpty(t, secret)
+
+	tagRegex := `^v\d+`
+	trigger, err = c.UpdateGitTrigger(ctx, cluster.ID, trigger.ID, &UpdateGitTriggerRequest{TagRegex: &tagRegex})
+	assert.Nil(t, err)
+	assert.Equal(t, "release/*", trigger.BranchPattern)
+	assert.Equal(t, tagRegex, trigger.TagRegex)
+	assert.Empty(t, trigger.Secret)
+
+	triggers, err := c.ListGitTriggers(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(triggers))
+	assert.Empty(t, triggers[0].Secret)
+
+	_, ok := perror.Cause(c.DeleteGitTrigger(ctx, cluster.ID+1, trigger.ID)).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	// webhooks are verified by secrets of triggers
+	header := http.Header{}
+	header.Set("X-Gitlab-Token", secret)
+	body := []byte(`{
+		"object_kind": "push",
+		"ref": "refs/heads/master",
+		"after": "da1560886d4f094c3e6c9ef40349f7d38b5d27d7",
+		"user_username": "tony",
+		"project": {
+			"git_ssh_url": "ssh://git@cloudnative.com:22222/music-cloud-native/horizon/trigger.git",
+			"git_http_url": "https://cloudnative.com/music-cloud-native/horizon/trigger.git"
+		},
+		"commits": [{"id": "da1560886d4f094c3e6c9ef40349f7d38b5d27d7", "modified": ["app/main.go"]}]
+	}`)
+	resp, err := c.HandleGitWebhook(ctx, gitlab.Kind, header, body)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(resp.Pipelineruns))
+
+	// the secret of trigger doesn't verify merge requests for previews
+	_, err = c.HandleGitWebhook(ctx, gitlab.Kind, header, []byte(`{
+		"object_kind": "merge_request",
+		"project": {
+			"git_ssh_url": "ssh://git@cloudnative.com:22222/music-cloud-native/horizon/trigger.git"
+		},
+		"object_attributes": {"iid": 12, "action": "close"}
+	}`))
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	header.Set("X-Gitlab-Token", "wrong")
+	_, err = c.HandleGitWebhook(ctx, gitlab.Kind, header, body)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	trigger, err = c.UpdateGitTrigger(ctx, cluster.ID, trigger.ID, &UpdateGitTriggerRequest{ResetSecret: true})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, trigger.Secret)
+	assert.NotEqual(t, secret, trigger.Secret)
+	header.Set("X-Gitlab-Token", secret)
+	_, err = c.HandleGitWebhook(ctx, gitlab.Kind, header, body)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	header.Set("X-Gitlab-Token", trigger.Secret)
+	_, err = c.HandleGitWebhook(ctx, gitlab.Kind, header, body)
+	assert.Nil(t, err)
+
+	_, err = c.HandleGitWebhook(ctx, "unknown", header, body)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	assert.Nil(t, c.DeleteGitTrigger(ctx, cluster.ID, trigger.ID))
+	triggers, err = c.ListGitTriggers(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(triggers))
+}
@@ -118,6 +118,9 @@ func (c *controller) InternalDeploy(ctx context.Context, clusterID uint,
 	if err != nil {
 		return nil, err
 	}
+	if err := c.clusterGitRepo.EnsureSecretFile(ctx, application.Name, cluster.Name); err != nil {
+		return nil, err
+	}
 	repoInfo := c.clusterGitRepo.GetRepoInfo(ctx, application.Name, cluster.Name)
 	if err := c.cd.CreateCluster(ctx, &cd.CreateClusterParams{
 		Environment:  cluster.EnvironmentName,
@@ -141,6 +144,7 @@ func (c *controller) InternalDeploy(ctx context.Context, clusterID uint,
 
 	// 8. deploy cluster in cd system
 	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
+		Application: application.Name,
 		Environment: cluster.EnvironmentName,
 		Cluster:     cluster.Name,
 		Revision:    masterRevision,
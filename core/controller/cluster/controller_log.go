@@ -0,0 +1,164 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cd"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const _kindPod = "Pod"
+
+// StreamContainerLog streams logs of a container, or merges logs of all pods with pod name as prefix.
+// The returned channel is closed when all logs are sent, or ctx is done when following logs.
+func (c *controller) StreamContainerLog(ctx context.Context, clusterID uint,
+	r *ContainerLogRequest) (<-chan string, error) {
+	const op = "cluster controller: stream container log"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	match, err := newLogMatcher(r.Keyword, r.Regex)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, cluster.Template, cluster.TemplateRelease)
+	if err != nil {
+		return nil, err
+	}
+	envValue, err := c.clusterGitRepo.GetEnvValue(ctx, application.Name, cluster.Name, tr.ChartName)
+	if err != nil {
+		return nil, err
+	}
+	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return nil, err
+	}
+
+	pods := []string{r.PodName}
+	if r.PodName == "" {
+		resourceTree, err := c.cd.GetResourceTree(ctx, &cd.GetResourceTreeParams{
+			Environment:  cluster.EnvironmentName,
+			Cluster:      cluster.Name,
+			RegionEntity: regionEntity,
+		})
+		if err != nil {
+			return nil, err
+		}
+		pods = pods[:0]
+		for _, node := range resourceTree {
+			if node.Kind == _kindPod {
+				pods = append(pods, node.Name)
+			}
+		}
+		if len(pods) == 0 {
+			return nil, herrors.NewErrNotFound(herrors.PodsInK8S,
+				fmt.Sprintf("no pod of cluster %s is found", cluster.Name))
+		}
+	}
+
+	logs := make(map[string]<-chan string, len(pods))
+	for _, pod := range pods {
+		logC, err := c.k8sutil.GetContainerLog(ctx, &cd.GetContainerLogParams{
+			RegionEntity: regionEntity,
+			Namespace:    envValue.Namespace,
+			Environment:  cluster.EnvironmentName,
+			Cluster:      cluster.Name,
+			Pod:          pod,
+			Container:    r.ContainerName,
+			TailLines:    r.TailLines,
+			SinceTime:    r.SinceTime,
+			Follow:       r.Follow,
+			Previous:     r.Previous,
+		})
+		if err != nil {
+			// logs of other pods are still useful when merging, such as a pod is pending
+			if r.PodName == "" {
+				log.Warningf(ctx, "failed to get logs of pod %s: %v", pod, err)
+				continue
+			}
+			return nil, err
+		}
+		logs[pod] = logC
+	}
+	return mergeLogs(ctx, logs, r.PodName == "", match), nil
+}
+
+// newLogMatcher returns a func to filter log lines by substring and regular expression
+func newLogMatcher(keyword, regex string) (func(string) bool, error) {
+	var re *regexp.Regexp
+	if regex != "" {
+		var err error
+		if re, err = regexp.Compile(regex); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid regex %s: %v", regex, err)
+		}
+	}
+	return func(line string) bool {
+		if keyword != "" && !strings.Contains(line, keyword) {
+			return false
+		}
+		if re != nil && !re.MatchString(line) {
+			return false
+		}
+		return true
+	}, nil
+}
+
+// mergeLogs merges logs of pods into one channel, lines of a pod keep their order.
+// Logs are always drained after ctx is done, so that the producers won't be blocked.
+func mergeLogs(ctx context.Context, logs map[string]<-chan string, withPrefix bool,
+	match func(string) bool) <-chan string {
+	out := make(chan string)
+	wg := sync.WaitGroup{}
+	wg.Add(len(logs))
+	for pod, logC := range logs {
+		go func(pod string, logC <-chan string) {
+			defer wg.Done()
+			for line := range logC {
+				if !match(line) {
+					continue
+				}
+				if withPrefix {
+					line = fmt.Sprintf("[%s] %s", pod, line)
+				}
+				select {
+				case out <- line:
+				case <-ctx.Done():
+				}
+			}
+		}(pod, logC)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
@@ -0,0 +1,75 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLogMatcher(t *testing.T) {
+	match, err := newLogMatcher("", "")
+	assert.Nil(t, err)
+	assert.True(t, match("anything"))
+
+	match, err = newLogMatcher("ERROR", "")
+	assert.Nil(t, err)
+	assert.True(t, match("[2023-01-01T00:00:00Z] ERROR failed"))
+	assert.False(t, match("[2023-01-01T00:00:00Z] INFO ok"))
+
+	match, err = newLogMatcher("failed", "status=5\\d\\d")
+	assert.Nil(t, err)
+	assert.True(t, match("request failed, status=502"))
+	assert.False(t, match("request failed, status=404"))
+	assert.False(t, match("request ok, status=500"))
+
+	_, err = newLogMatcher("", "(")
+	assert.NotNil(t, err)
+}
+
+func TestMergeLogs(t *testing.T) {
+	newLogC := func(lines ...string) <-chan string {
+		ch := make(chan string, len(lines))
+		for _, line := range lines {
+			ch <- line
+		}
+		close(ch)
+		return ch
+	}
+	match, _ := newLogMatcher("error", "")
+
+	out := mergeLogs(context.Background(), map[string]<-chan string{
+		"pod-a": newLogC("error a1\n", "info a2\n"),
+		"pod-b": newLogC("error b1\n"),
+	}, true, match)
+	var lines []string
+	for line := range out {
+		lines = append(lines, line)
+	}
+	sort.Strings(lines)
+	assert.Equal(t, []string{"[pod-a] error a1\n", "[pod-b] error b1\n"}, lines)
+
+	// producers are drained after ctx is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	out = mergeLogs(ctx, map[string]<-chan string{
+		"pod-a": newLogC("error a1\n", "error a2\n"),
+	}, false, match)
+	for range out {
+	}
+}
@@ -93,6 +93,7 @@ func (c *controller) Restart(ctx context.Context, clusterID uint) (_ *Pipelineru
 
 	// 3. deploy cluster in cd system
 	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
+		Application: application.Name,
 		Environment: cluster.EnvironmentName,
 		Cluster:     cluster.Name,
 		Revision:    commit,
@@ -390,6 +391,9 @@ func (c *controller) Rollback(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	if err := c.clusterGitRepo.EnsureSecretFile(ctx, application.Name, cluster.Name); err != nil {
+		return nil, err
+	}
 	repoInfo := c.clusterGitRepo.GetRepoInfo(ctx, application.Name, cluster.Name)
 	if err := c.cd.CreateCluster(ctx, &cd.CreateClusterParams{
 		Environment:  cluster.EnvironmentName,
@@ -413,6 +417,7 @@ func (c *controller) Rollback(ctx context.Context,
 
 	// 9. deploy cluster in cd and update status
 	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
+		Application: application.Name,
 		Environment: cluster.EnvironmentName,
 		Cluster:     cluster.Name,
 		Revision:    masterRevision,
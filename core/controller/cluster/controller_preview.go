@@ -0,0 +1,355 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"text/template"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/encrypt"
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/git"
+	"github.com/horizoncd/horizon/pkg/preview/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// maxCommitStatusDescription is the limit of commit status description of github
+const maxCommitStatusDescription = 140
+
+func (c *controller) GetPreviewSetting(ctx context.Context, clusterID uint) (*PreviewSetting, error) {
+	setting, err := c.previewMgr.GetSettingByClusterID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return ofPreviewSetting(setting), nil
+}
+
+func (c *controller) UpdatePreviewSetting(ctx context.Context, clusterID uint,
+	r *UpdatePreviewSettingRequest) (*PreviewSetting, error) {
+	const op = "cluster controller: update preview setting"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if cluster.GitURL == "" {
+		return nil, herrors.ErrBuildDeployNotSupported
+	}
+	if r.TargetBranchPattern != "" {
+		if _, err := path.Match(r.TargetBranchPattern, ""); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"invalid targetBranchPattern %s: %v", r.TargetBranchPattern, err)
+		}
+	}
+	if r.ExpireTime != "" {
+		if _, err := time.ParseDuration(r.ExpireTime); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid expireTime %s: %v", r.ExpireTime, err)
+		}
+	}
+
+	setting, err := c.previewMgr.GetSettingByClusterID(ctx, clusterID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return nil, err
+		}
+		setting = &models.PreviewSetting{ClusterID: clusterID, Enabled: true}
+	}
+	setting.TargetBranchPattern = r.TargetBranchPattern
+	setting.ExpireTime = r.ExpireTime
+	if r.Enabled != nil {
+		setting.Enabled = *r.Enabled
+	}
+	resetSecret := setting.ID == 0 || r.ResetSecret
+	if resetSecret {
+		secret, err := genWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		setting.Secret = encrypt.String(secret)
+	}
+	if setting.ID == 0 {
+		setting, err = c.previewMgr.CreateSetting(ctx, setting)
+	} else {
+		setting, err = c.previewMgr.UpdateSetting(ctx, setting)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := ofPreviewSetting(setting)
+	if resetSecret {
+		result.Secret = string(setting.Secret)
+	}
+	return result, nil
+}
+
+func (c *controller) DeletePreviewSetting(ctx context.Context, clusterID uint) error {
+	if _, err := c.previewMgr.GetSettingByClusterID(ctx, clusterID); err != nil {
+		return err
+	}
+	return c.previewMgr.DeleteSetting(ctx, clusterID)
+}
+
+func (c *controller) ListPreviewClusters(ctx context.Context, clusterID uint) ([]*PreviewCluster, error) {
+	if _, err := c.clusterMgr.GetByID(ctx, clusterID); err != nil {
+		return nil, err
+	}
+	clusters, err := c.previewMgr.ListClustersByBaseClusterID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*PreviewCluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		result = append(result, ofPreviewCluster(cluster))
+	}
+	return result, nil
+}
+
+// handleMergeRequestEvent deploys or deletes the previews of the settings matching the merge request event,
+// the settings have verified the webhook with their secrets
+func (c *controller) handleMergeRequestEvent(ctx context.Context, event *git.MergeRequestEvent,
+	settings []*models.PreviewSetting) (*GitWebhookResponse, error) {
+	resp := &GitWebhookResponse{
+		Pipelineruns: []*TriggeredPipelinerun{},
+		Previews:     []*TriggeredPreview{},
+	}
+	for _, setting := range settings {
+		if !setting.Match(event) {
+			continue
+		}
+
+		result := &TriggeredPreview{BaseClusterID: setting.ClusterID, Action: event.Action}
+		var err error
+		switch event.Action {
+		case git.MergeRequestActionOpen, git.MergeRequestActionUpdate:
+			// the source branch of a fork is controlled by anyone who can fork the repository,
+			// it must not be built and deployed with the credentials of the base cluster
+			if event.Fork {
+				err = perror.Wrapf(herrors.ErrPreviewOfForkNotSupported, "merge request %d", event.ID)
+			} else {
+				result.ClusterID, result.PipelinerunID, err = c.deployPreview(ctx, setting, event)
+			}
+			c.reportPreviewStatus(ctx, setting, event, err)
+		case git.MergeRequestActionClose, git.MergeRequestActionMerge:
+			result.ClusterID, err = c.deletePreview(ctx, setting, event)
+		}
+		if err != nil {
+			log.Errorf(ctx, "failed to %s preview of cluster %d for merge request %d: %v",
+				event.Action, setting.ClusterID, event.ID, err)
+			result.Error = _triggerFailed
+		}
+		resp.Previews = append(resp.Previews, result)
+	}
+	return resp, nil
+}
+
+// deployPreview creates the preview cluster for the merge request if it does not exist,
+// and then starts BuildDeploy of the preview cluster with the head commit of source branch
+func (c *controller) deployPreview(ctx context.Context, setting *models.PreviewSetting,
+	event *git.MergeRequestEvent) (uint, uint, error) {
+	ctx, err := c.withUserContext(ctx, setting.CreatedBy)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	previewCluster, err := c.getPreviewCluster(ctx, setting.ClusterID, event.ID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if previewCluster == nil {
+		previewCluster, err = c.createPreviewCluster(ctx, setting, event)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	resp, err := c.BuildDeploy(ctx, previewCluster.ClusterID, &BuildDeployRequest{
+		Title:       event.Title,
+		Description: fmt.Sprintf("preview of merge request %d: %s", event.ID, event.URL),
+		// build the head commit of the event, the source branch may have been moved on by later pushes
+		Git: &BuildDeployRequestGit{
+			Commit: event.Commit,
+		},
+		Trigger: &BuildDeployTrigger{
+			Commit: event.Commit,
+			Pusher: event.Author,
+		},
+	})
+	if err != nil {
+		return previewCluster.ClusterID, 0, err
+	}
+	return previewCluster.ClusterID, resp.PipelinerunID, nil
+}
+
+// reportPreviewStatus publishes whether the preview is triggered to the head commit of merge request,
+// the build and deploy of the preview cluster are reported by its pipelinerun as usual
+func (c *controller) reportPreviewStatus(ctx context.Context, setting *models.PreviewSetting,
+	event *git.MergeRequestEvent, err error) {
+	base, getErr := c.clusterMgr.GetByID(ctx, setting.ClusterID)
+	if getErr != nil {
+		log.Warningf(ctx, "failed to get cluster %d: %v", setting.ClusterID, getErr)
+		return
+	}
+	status := &git.CommitStatus{
+		Commit:      event.Commit,
+		State:       git.CommitStateSuccess,
+		Context:     fmt.Sprintf("horizon/preview/%s", base.Name),
+		Description: "preview is triggered",
+	}
+	// the source branch of a fork does not exist in the target repository
+	if !event.Fork {
+		status.Ref = event.SourceBranch
+	}
+	if err != nil {
+		status.State = git.CommitStateFailed
+		status.Description = perror.Cause(err).Error()
+		if len(status.Description) > maxCommitStatusDescription {
+			status.Description = status.Description[:maxCommitStatusDescription]
+		}
+	}
+	if err := c.commitGetter.CreateCommitStatus(ctx, base.GitURL, status); err != nil {
+		log.Warningf(ctx, "failed to create commit status of merge request %d of %s: %v", event.ID, base.GitURL, err)
+	}
+}
+
+// getPreviewCluster returns nil if the preview cluster of merge request does not exist,
+// the record is cleaned up if the preview cluster has been deleted manually
+func (c *controller) getPreviewCluster(ctx context.Context,
+	baseClusterID uint, mergeRequestID int) (*models.PreviewCluster, error) {
+	previewCluster, err := c.previewMgr.GetClusterByMergeRequest(ctx, baseClusterID, mergeRequestID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if _, err := c.clusterMgr.GetByID(ctx, previewCluster.ClusterID); err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return nil, err
+		}
+		if err := c.previewMgr.DeleteCluster(ctx, previewCluster.ID); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	return previewCluster, nil
+}
+
+// createPreviewCluster creates a cluster from the base cluster's config with the source branch of merge request,
+// and comments the access url on the merge request
+func (c *controller) createPreviewCluster(ctx context.Context, setting *models.PreviewSetting,
+	event *git.MergeRequestEvent) (*models.PreviewCluster, error) {
+	base, err := c.GetClusterV2(ctx, setting.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+	if base.Git == nil || base.Git.URL == "" {
+		return nil, herrors.ErrBuildDeployNotSupported
+	}
+
+	created, err := c.CreateClusterV2(ctx, &CreateClusterParamsV2{
+		CreateClusterRequestV2: &CreateClusterRequestV2{
+			Name:           models.ClusterName(base.Name, event.ID),
+			Description:    fmt.Sprintf("preview of merge request %d: %s", event.ID, event.URL),
+			Priority:       base.Priority,
+			ExpireTime:     setting.ExpireTime,
+			Git:            codemodels.NewGit(base.Git.URL, base.Git.Subfolder, git.GitRefTypeBranch, event.SourceBranch),
+			Tags:           base.Tags,
+			BuildConfig:    base.BuildConfig,
+			TemplateInfo:   base.TemplateInfo,
+			TemplateConfig: base.TemplateConfig,
+		},
+		ApplicationID: base.ApplicationID,
+		Environment:   base.Scope.Environment,
+		Region:        base.Scope.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	previewCluster, err := c.previewMgr.CreateCluster(ctx, &models.PreviewCluster{
+		BaseClusterID:  setting.ClusterID,
+		ClusterID:      created.ID,
+		MergeRequestID: event.ID,
+		SourceBranch:   event.SourceBranch,
+		CreatedBy:      setting.CreatedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	comment := fmt.Sprintf("Preview environment `%s` is created for this merge request.", created.FullPath)
+	if accessURL := c.renderPreviewAccessURL(ctx, created); accessURL != "" {
+		comment += fmt.Sprintf("\n\nAccess URL: %s", accessURL)
+	}
+	if err := c.commitGetter.CreateMergeRequestComment(ctx, base.Git.URL, event.ID, comment); err != nil {
+		log.Warningf(ctx, "failed to comment on merge request %d of %s: %v", event.ID, base.Git.URL, err)
+	}
+	return previewCluster, nil
+}
+
+func (c *controller) renderPreviewAccessURL(ctx context.Context, cluster *CreateClusterResponseV2) string {
+	if c.previewConfig.AccessURLTemplate == "" {
+		return ""
+	}
+	tmpl, err := template.New("accessURL").Parse(c.previewConfig.AccessURLTemplate)
+	if err != nil {
+		log.Warningf(ctx, "failed to parse access url template of preview: %v", err)
+		return ""
+	}
+	params := &previewAccessURLParams{
+		Cluster:   cluster.Name,
+		ClusterID: cluster.ID,
+		FullPath:  cluster.FullPath,
+	}
+	if cluster.Application != nil {
+		params.Application = cluster.Application.Name
+	}
+	if cluster.Scope != nil {
+		params.Environment = cluster.Scope.Environment
+		params.Region = cluster.Scope.Region
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, params); err != nil {
+		log.Warningf(ctx, "failed to render access url of preview cluster %s: %v", cluster.Name, err)
+		return ""
+	}
+	return buf.String()
+}
+
+// deletePreview deletes the preview cluster when the merge request is merged or closed
+func (c *controller) deletePreview(ctx context.Context, setting *models.PreviewSetting,
+	event *git.MergeRequestEvent) (uint, error) {
+	ctx, err := c.withUserContext(ctx, setting.CreatedBy)
+	if err != nil {
+		return 0, err
+	}
+
+	previewCluster, err := c.getPreviewCluster(ctx, setting.ClusterID, event.ID)
+	if err != nil || previewCluster == nil {
+		return 0, err
+	}
+	if err := c.DeleteCluster(ctx, previewCluster.ClusterID, true); err != nil {
+		return previewCluster.ClusterID, err
+	}
+	return previewCluster.ClusterID, c.previewMgr.DeleteCluster(ctx, previewCluster.ID)
+}
@@ -0,0 +1,173 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	commitmock "github.com/horizoncd/horizon/mock/pkg/cluster/code"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/git"
+	"github.com/horizoncd/horizon/pkg/git/gitlab"
+	previewmodels "github.com/horizoncd/horizon/pkg/preview/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+func testPreview(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	gitGetter := commitmock.NewMockGitGetter(mockCtl)
+	c = &controller{
+		clusterMgr:   manager.ClusterMgr,
+		previewMgr:   manager.PreviewMgr,
+		userManager:  manager.UserMgr,
+		commitGetter: gitGetter,
+	}
+
+	gitURL := "ssh://git@cloudnative.com:22222/music-cloud-native/horizon/preview.git"
+	cluster, err := manager.ClusterMgr.Create(ctx, &clustermodels.Cluster{
+		ApplicationID:   uint(1),
+		Name:            "clusterWithPreview",
+		EnvironmentName: "test",
+		RegionName:      "hz",
+		GitURL:          gitURL,
+	}, nil, nil)
+	assert.Nil(t, err)
+
+	// settings
+	_, err = c.GetPreviewSetting(ctx, cluster.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	_, err = c.UpdatePreviewSetting(ctx, cluster.ID, &UpdatePreviewSettingRequest{TargetBranchPattern: "release/["})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.UpdatePreviewSetting(ctx, cluster.ID, &UpdatePreviewSettingRequest{ExpireTime: "3 days"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	setting, err := c.UpdatePreviewSetting(ctx, cluster.ID, &UpdatePreviewSettingRequest{
+		TargetBranchPattern: "master",
+		ExpireTime:          "72h",
+	})
+	assert.Nil(t, err)
+	assert.True(t, setting.Enabled)
+	// the secret is generated on creation, and only returned when it's generated
+	assert.NotEmpty(t, setting.Secret)
+	setting, err = c.GetPreviewSetting(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Empty(t, setting.Secret)
+
+	disabled := false
+	setting, err = c.UpdatePreviewSetting(ctx, cluster.ID, &UpdatePreviewSettingRequest{
+		TargetBranchPattern: "main",
+		Enabled:             &disabled,
+	})
+	assert.Nil(t, err)
+	assert.False(t, setting.Enabled)
+	assert.Equal(t, "main", setting.TargetBranchPattern)
+	assert.Equal(t, "", setting.ExpireTime)
+	assert.Empty(t, setting.Secret)
+
+	assert.Nil(t, c.DeletePreviewSetting(ctx, cluster.ID))
+	_, err = c.GetPreviewSetting(ctx, cluster.ID)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	// closing the merge request cleans up the preview cluster which has been deleted
+	user, err := manager.UserMgr.Create(ctx, &usermodels.User{Name: "previewer"})
+	assert.Nil(t, err)
+	_, err = manager.PreviewMgr.CreateSetting(ctx, &previewmodels.PreviewSetting{
+		ClusterID: cluster.ID,
+		Enabled:   true,
+		Secret:    "secret",
+		CreatedBy: user.ID,
+	})
+	assert.Nil(t, err)
+	_, err = manager.PreviewMgr.CreateCluster(ctx, &previewmodels.PreviewCluster{
+		BaseClusterID:  cluster.ID,
+		ClusterID:      cluster.ID + 1000,
+		MergeRequestID: 12,
+		SourceBranch:   "feature",
+	})
+	assert.Nil(t, err)
+	previewClusters, err := c.ListPreviewClusters(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(previewClusters))
+
+	header := http.Header{}
+	header.Set("X-Gitlab-Token", "secret")
+	resp, err := c.HandleGitWebhook(ctx, gitlab.Kind, header, []byte(`{
+		"object_kind": "merge_request",
+		"user": {"username": "tony"},
+		"project": {
+			"git_ssh_url": "ssh://git@cloudnative.com:22222/music-cloud-native/horizon/preview.git"
+		},
+		"object_attributes": {
+			"iid": 12,
+			"source_branch": "feature",
+			"target_branch": "master",
+			"action": "close"
+		}
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(resp.Previews))
+	assert.Equal(t, cluster.ID, resp.Previews[0].BaseClusterID)
+	assert.Equal(t, git.MergeRequestActionClose, resp.Previews[0].Action)
+	assert.Equal(t, "", resp.Previews[0].Error)
+	previewClusters, err = c.ListPreviewClusters(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(previewClusters))
+
+	// merge requests from forks are not deployed, and the commit is marked as failed
+	gitGetter.EXPECT().CreateCommitStatus(gomock.Any(), gitURL, &git.CommitStatus{
+		Commit:      "da1560886d4f094c3e6c9ef40349f7d38b5d27d7",
+		State:       git.CommitStateFailed,
+		Context:     "horizon/preview/clusterWithPreview",
+		Description: herrors.ErrPreviewOfForkNotSupported.Error(),
+	}).Return(nil).Times(1)
+	resp, err = c.HandleGitWebhook(ctx, gitlab.Kind, header, []byte(`{
+		"object_kind": "merge_request",
+		"user": {"username": "tony"},
+		"project": {
+			"git_ssh_url": "ssh://git@cloudnative.com:22222/music-cloud-native/horizon/preview.git"
+		},
+		"object_attributes": {
+			"iid": 13,
+			"source_project_id": 6,
+			"target_project_id": 5,
+			"source_branch": "feature",
+			"target_branch": "master",
+			"action": "open",
+			"last_commit": {"id": "da1560886d4f094c3e6c9ef40349f7d38b5d27d7"}
+		}
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(resp.Previews))
+	assert.Equal(t, uint(0), resp.Previews[0].ClusterID)
+	assert.Equal(t, _triggerFailed, resp.Previews[0].Error)
+	previewClusters, err = c.ListPreviewClusters(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(previewClusters))
+
+	header.Set("X-Gitlab-Token", "wrong")
+	_, err = c.HandleGitWebhook(ctx, gitlab.Kind, header, []byte(`{
+		"object_kind": "merge_request",
+		"object_attributes": {"iid": 12, "action": "close"}
+	}`))
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+}
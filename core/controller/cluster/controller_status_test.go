@@ -16,6 +16,7 @@ package cluster
 
 import (
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -29,6 +30,7 @@ import (
 	applicationmodel "github.com/horizoncd/horizon/pkg/application/models"
 	"github.com/horizoncd/horizon/pkg/cd"
 	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	clusterdriftmodels "github.com/horizoncd/horizon/pkg/clusterdrift/models"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
@@ -42,17 +44,18 @@ func testGetClusterStatusV2(t *testing.T) {
 	appManagerMock := applicationmanangermock.NewMockManager(mockCtl)
 	mockCD := cdmock.NewMockCD(mockCtl)
 	db, _ := orm.NewSqliteDB("")
-	_ = db.AutoMigrate(&regionmodels.Region{}, &registrymodels.Registry{})
+	_ = db.AutoMigrate(&regionmodels.Region{}, &registrymodels.Registry{}, &clusterdriftmodels.ClusterDrift{})
 	manager := managerparam.InitManager(db)
 
 	regionName := "test"
 	status := "expectedStatus"
 
 	c := controller{
-		clusterMgr:     clusterManagerMock,
-		applicationMgr: appManagerMock,
-		regionMgr:      manager.RegionMgr,
-		cd:             mockCD,
+		clusterMgr:      clusterManagerMock,
+		applicationMgr:  appManagerMock,
+		regionMgr:       manager.RegionMgr,
+		cd:              mockCD,
+		clusterDriftMgr: manager.ClusterDriftMgr,
 	}
 
 	_, err := manager.RegistryMgr.Create(ctx, &registrymodels.Registry{
@@ -88,10 +91,23 @@ func testGetClusterStatusV2(t *testing.T) {
 	resp, err := c.GetClusterStatusV2(ctx, 1)
 	assert.Nil(t, err)
 	assert.Equal(t, status, resp.Status)
+	assert.Nil(t, resp.Drift)
+
+	detectedAt := time.Now()
+	_, err = manager.ClusterDriftMgr.Upsert(ctx, &clusterdriftmodels.ClusterDrift{
+		ClusterID:  1,
+		Drifted:    true,
+		Resources:  `[{"kind":"Deployment","name":"app","fields":[{"path":"spec.replicas"}]}]`,
+		DetectedAt: &detectedAt,
+		CheckedAt:  detectedAt,
+	})
+	assert.Nil(t, err)
 
 	resp, err = c.GetClusterStatusV2(ctx, 1)
 	assert.Nil(t, err)
 	assert.Equal(t, common.ClusterStatusCreating, resp.Status)
+	assert.True(t, resp.Drift.Drifted)
+	assert.Equal(t, "spec.replicas", resp.Drift.Resources[0].Fields[0].Path)
 
 	resp, err = c.GetClusterStatusV2(ctx, 1)
 	assert.Nil(t, err)
@@ -69,12 +69,15 @@ import (
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	"github.com/horizoncd/horizon/pkg/git"
 	"github.com/horizoncd/horizon/pkg/git/gitlab"
+	gittriggermodels "github.com/horizoncd/horizon/pkg/gittrigger/models"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	groupservice "github.com/horizoncd/horizon/pkg/group/service"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	previewmodels "github.com/horizoncd/horizon/pkg/preview/models"
+	quotamodels "github.com/horizoncd/horizon/pkg/quota/models"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	registrydao "github.com/horizoncd/horizon/pkg/registry/dao"
 	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
@@ -89,6 +92,7 @@ import (
 	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	userservice "github.com/horizoncd/horizon/pkg/user/service"
+	"github.com/horizoncd/horizon/pkg/visibility"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -460,7 +464,10 @@ func TestMain(m *testing.M) {
 		&registrymodels.Registry{}, eventmodels.Event{}, &templatemodels.Template{},
 		&regionmodels.Region{}, &envregionmodels.EnvironmentRegion{}, &eventmodels.Event{},
 		&prmodels.Pipelinerun{}, &schematagmodel.ClusterTemplateSchemaTag{}, &tmodel.Tag{},
-		&envmodels.Environment{}, &tokenmodels.Token{}, &badgemodels.Badge{}); err != nil {
+		&envmodels.Environment{}, &tokenmodels.Token{}, &badgemodels.Badge{},
+		&gittriggermodels.GitTrigger{}, &previewmodels.PreviewSetting{},
+		&previewmodels.PreviewCluster{}, &quotamodels.Quota{}, &quotamodels.ClusterUsage{},
+		&quotamodels.QuotaReservation{}); err != nil {
 		panic(err)
 	}
 	ctx = context.TODO()
@@ -512,6 +519,9 @@ func TestAll(t *testing.T) {
 	t.Run("TestListUserClustersByNameFuzzily", testListUserClustersByNameFuzzily)
 	t.Run("TestListClusterWithExpiry", testListClusterWithExpiry)
 	t.Run("TestGetClusterStatusV2", testGetClusterStatusV2)
+	t.Run("TestGitTrigger", testGitTrigger)
+	t.Run("TestPreview", testPreview)
+	t.Run("TestQuota", testQuota)
 }
 
 // nolint
@@ -663,6 +673,7 @@ func test(t *testing.T) {
 		applicationGitRepo:   applicationGitRepo,
 		eventSvc:             eventservice.New(manager),
 		memberManager:        manager.MemberMgr,
+		visibilityChecker:    visibility.NewChecker(manager),
 		tokenSvc: tokenservice.NewService(manager, tokenconfig.Config{
 			JwtSigningKey:         "horizon",
 			CallbackTokenExpireIn: time.Hour * 2,
@@ -695,6 +706,7 @@ func test(t *testing.T) {
 	clusterGitRepo.EXPECT().GetEnvValue(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(&gitrepo.EnvValue{
 		Namespace: "test-1",
 	}, nil).AnyTimes()
+	clusterGitRepo.EXPECT().EnsureSecretFile(ctx, gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	clusterGitRepo.EXPECT().GetRepoInfo(ctx, gomock.Any(), gomock.Any()).Return(&gitrepo.RepoInfo{
 		GitRepoURL: "ssh://xxxx",
 		ValueFiles: []string{},
@@ -885,6 +897,7 @@ func test(t *testing.T) {
 		Return("", nil).AnyTimes()
 	clusterGitRepo.EXPECT().MergeBranch(ctx, gomock.Any(), gomock.Any(), gomock.Any(),
 		gomock.Any(), gomock.Any()).Return("newest-commit", nil).AnyTimes()
+	clusterGitRepo.EXPECT().EnsureSecretFile(ctx, gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	clusterGitRepo.EXPECT().GetRepoInfo(ctx, gomock.Any(), gomock.Any()).Return(&gitrepo.RepoInfo{
 		GitRepoURL: "ssh://xxxx.git",
 		ValueFiles: []string{"file1", "file2"},
@@ -922,6 +935,7 @@ func test(t *testing.T) {
 	clusterGitRepo.EXPECT().UpdatePipelineOutput(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return("image-commit", nil).AnyTimes()
 	clusterGitRepo.EXPECT().MergeBranch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
 		gomock.Any(), gomock.Any()).Return("newest-commit", nil).AnyTimes()
+	clusterGitRepo.EXPECT().EnsureSecretFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	clusterGitRepo.EXPECT().GetRepoInfo(gomock.Any(), gomock.Any(), gomock.Any()).Return(&gitrepo.RepoInfo{
 		GitRepoURL: "ssh://xxxx.git",
 		ValueFiles: []string{"file1", "file2"},
@@ -1432,6 +1446,7 @@ func testV2(t *testing.T) {
 		cd:                   mockCd,
 		eventSvc:             eventservice.New(manager),
 		memberManager:        manager.MemberMgr,
+		visibilityChecker:    visibility.NewChecker(manager),
 	}
 	applicationGitRepo.EXPECT().GetApplication(gomock.Any(), applicationName, gomock.Any()).
 		Return(&appgitrepo.GetResponse{
@@ -1658,6 +1673,7 @@ func testUpgrade(t *testing.T) {
 		eventSvc:              eventservice.New(manager),
 		templateUpgradeMapper: templateUpgradeMapper,
 		memberManager:         manager.MemberMgr,
+		visibilityChecker:     visibility.NewChecker(manager),
 	}
 
 	applicationGitRepo.EXPECT().GetApplication(ctx, gomock.Any(), gomock.Any()).
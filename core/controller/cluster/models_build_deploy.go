@@ -18,6 +18,14 @@ type BuildDeployRequest struct {
 	Title       string                 `json:"title"`
 	Description string                 `json:"description"`
 	Git         *BuildDeployRequestGit `json:"git"`
+	// Trigger is set when the build is triggered by git push automatically
+	Trigger *BuildDeployTrigger `json:"-"`
+}
+
+type BuildDeployTrigger struct {
+	// Commit is the pushed commit to build with
+	Commit string
+	Pusher string
 }
 
 type BuildDeployRequestGit struct {
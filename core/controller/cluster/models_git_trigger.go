@@ -0,0 +1,92 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/gittrigger/models"
+)
+
+type GitTrigger struct {
+	ID            uint      `json:"id"`
+	ClusterID     uint      `json:"clusterID"`
+	BranchPattern string    `json:"branchPattern"`
+	TagRegex      string    `json:"tagRegex"`
+	PathFilter    string    `json:"pathFilter"`
+	Enabled       bool      `json:"enabled"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	CreatedBy     uint      `json:"createdBy"`
+	UpdatedBy     uint      `json:"updatedBy"`
+	// Secret is only returned when the trigger is created or the secret is reset
+	Secret string `json:"secret,omitempty"`
+}
+
+type CreateGitTriggerRequest struct {
+	// BranchPattern is a glob pattern of branches, such as master or release/*
+	BranchPattern string `json:"branchPattern"`
+	// TagRegex is a regular expression of tags, such as ^v\d+\.\d+\.\d+$
+	TagRegex string `json:"tagRegex"`
+	// PathFilter only triggers branch pushes which change files under the path
+	PathFilter string `json:"pathFilter"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled"`
+}
+
+type UpdateGitTriggerRequest struct {
+	BranchPattern *string `json:"branchPattern"`
+	TagRegex      *string `json:"tagRegex"`
+	PathFilter    *string `json:"pathFilter"`
+	Enabled       *bool   `json:"enabled"`
+	// ResetSecret generates a new webhook secret, which is returned in the response
+	ResetSecret bool `json:"resetSecret"`
+}
+
+type GitWebhookResponse struct {
+	Pipelineruns []*TriggeredPipelinerun `json:"pipelineruns"`
+	Previews     []*TriggeredPreview     `json:"previews"`
+}
+
+// _triggerFailed is the error of triggered pipelineruns and previews returned to git hosts,
+// the cause is only logged since it may contain internal details
+const _triggerFailed = "failed to trigger, see logs of horizon for details"
+
+type TriggeredPipelinerun struct {
+	ClusterID     uint   `json:"clusterID"`
+	PipelinerunID uint   `json:"pipelinerunID,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+func ofGitTrigger(trigger *models.GitTrigger) *GitTrigger {
+	return &GitTrigger{
+		ID:            trigger.ID,
+		ClusterID:     trigger.ClusterID,
+		BranchPattern: trigger.BranchPattern,
+		TagRegex:      trigger.TagRegex,
+		PathFilter:    trigger.PathFilter,
+		Enabled:       trigger.Enabled,
+		CreatedAt:     trigger.CreatedAt,
+		UpdatedAt:     trigger.UpdatedAt,
+		CreatedBy:     trigger.CreatedBy,
+		UpdatedBy:     trigger.UpdatedBy,
+	}
+}
+
+func ofGitTriggerWithSecret(trigger *models.GitTrigger) *GitTrigger {
+	result := ofGitTrigger(trigger)
+	result.Secret = string(trigger.Secret)
+	return result
+}
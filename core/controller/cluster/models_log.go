@@ -0,0 +1,31 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import "time"
+
+type ContainerLogRequest struct {
+	// PodName is the pod to get logs, logs of all pods are merged if it's empty
+	PodName       string
+	ContainerName string
+	TailLines     int64
+	SinceTime     *time.Time
+	Follow        bool
+	Previous      bool
+	// Keyword only returns the lines containing the substring
+	Keyword string
+	// Regex only returns the lines matching the regular expression
+	Regex string
+}
@@ -0,0 +1,100 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/preview/models"
+)
+
+type PreviewSetting struct {
+	ID                  uint      `json:"id"`
+	ClusterID           uint      `json:"clusterID"`
+	TargetBranchPattern string    `json:"targetBranchPattern"`
+	ExpireTime          string    `json:"expireTime"`
+	Enabled             bool      `json:"enabled"`
+	CreatedAt           time.Time `json:"createdAt"`
+	UpdatedAt           time.Time `json:"updatedAt"`
+	CreatedBy           uint      `json:"createdBy"`
+	UpdatedBy           uint      `json:"updatedBy"`
+	// Secret is only returned when the setting is created or the secret is reset
+	Secret string `json:"secret,omitempty"`
+}
+
+type UpdatePreviewSettingRequest struct {
+	// TargetBranchPattern is a glob pattern of target branches, such as master or release/*
+	TargetBranchPattern string `json:"targetBranchPattern"`
+	// ExpireTime is the lifetime of preview clusters, such as 72h
+	ExpireTime string `json:"expireTime"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled"`
+	// ResetSecret generates a new webhook secret, which is returned in the response
+	ResetSecret bool `json:"resetSecret"`
+}
+
+type PreviewCluster struct {
+	ID             uint      `json:"id"`
+	BaseClusterID  uint      `json:"baseClusterID"`
+	ClusterID      uint      `json:"clusterID"`
+	MergeRequestID int       `json:"mergeRequestID"`
+	SourceBranch   string    `json:"sourceBranch"`
+	CreatedAt      time.Time `json:"createdAt"`
+	CreatedBy      uint      `json:"createdBy"`
+}
+
+type TriggeredPreview struct {
+	BaseClusterID uint   `json:"baseClusterID"`
+	ClusterID     uint   `json:"clusterID,omitempty"`
+	Action        string `json:"action"`
+	PipelinerunID uint   `json:"pipelinerunID,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// previewAccessURLParams are the fields available in the access url template of preview clusters
+type previewAccessURLParams struct {
+	Cluster     string
+	ClusterID   uint
+	Application string
+	Environment string
+	Region      string
+	FullPath    string
+}
+
+func ofPreviewSetting(setting *models.PreviewSetting) *PreviewSetting {
+	return &PreviewSetting{
+		ID:                  setting.ID,
+		ClusterID:           setting.ClusterID,
+		TargetBranchPattern: setting.TargetBranchPattern,
+		ExpireTime:          setting.ExpireTime,
+		Enabled:             setting.Enabled,
+		CreatedAt:           setting.CreatedAt,
+		UpdatedAt:           setting.UpdatedAt,
+		CreatedBy:           setting.CreatedBy,
+		UpdatedBy:           setting.UpdatedBy,
+	}
+}
+
+func ofPreviewCluster(cluster *models.PreviewCluster) *PreviewCluster {
+	return &PreviewCluster{
+		ID:             cluster.ID,
+		BaseClusterID:  cluster.BaseClusterID,
+		ClusterID:      cluster.ClusterID,
+		MergeRequestID: cluster.MergeRequestID,
+		SourceBranch:   cluster.SourceBranch,
+		CreatedAt:      cluster.CreatedAt,
+		CreatedBy:      cluster.CreatedBy,
+	}
+}
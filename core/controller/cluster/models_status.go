@@ -15,7 +15,10 @@
 package cluster
 
 import (
+	"time"
+
 	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/horizoncd/horizon/pkg/cd"
 	"github.com/horizoncd/horizon/pkg/grafana"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -32,6 +35,15 @@ type Revision struct {
 
 type StatusResponseV2 struct {
 	Status string `json:"status"`
+	// Drift is the latest configuration drift detected, nil if the cluster has never been detected
+	Drift *DriftStatus `json:"drift,omitempty"`
+}
+
+type DriftStatus struct {
+	Drifted    bool               `json:"drifted"`
+	DetectedAt *time.Time         `json:"detectedAt,omitempty"`
+	CheckedAt  time.Time          `json:"checkedAt"`
+	Resources  []cd.ResourceDrift `json:"resources,omitempty"`
 }
 
 type PipelinerunStatusResponse struct {
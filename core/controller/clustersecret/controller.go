@@ -0,0 +1,297 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustersecret
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/clustersecret"
+	clustersecretmanager "github.com/horizoncd/horizon/pkg/clustersecret/manager"
+	"github.com/horizoncd/horizon/pkg/clustersecret/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	"github.com/horizoncd/horizon/pkg/param"
+	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const _maxSecretNameLength = 253
+
+// secret names are keys of kubernetes secret
+var _secretNamePattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+type Controller interface {
+	// List lists secrets of a cluster without values
+	List(ctx context.Context, clusterID uint) ([]*Secret, error)
+	// Create seals the value with the sealed secrets certificate of the cluster's region
+	// and commits it to the cluster's git repo, which takes effect on next deploy
+	Create(ctx context.Context, clusterID uint, request *CreateSecretRequest) (*Secret, error)
+	// Rotate replaces the value of a secret and increases its version
+	Rotate(ctx context.Context, clusterID uint, name string, request *RotateSecretRequest) (*Secret, error)
+	Delete(ctx context.Context, clusterID uint, name string) error
+}
+
+type controller struct {
+	clusterMgr       clustermanager.Manager
+	applicationMgr   appmanager.Manager
+	regionMgr        regionmanager.Manager
+	clusterSecretMgr clustersecretmanager.Manager
+	clusterGitRepo   gitrepo.ClusterGitRepo
+	eventSvc         eventservice.Service
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		clusterMgr:       param.ClusterMgr,
+		applicationMgr:   param.ApplicationMgr,
+		regionMgr:        param.RegionMgr,
+		clusterSecretMgr: param.ClusterSecretMgr,
+		clusterGitRepo:   param.ClusterGitRepo,
+		eventSvc:         param.EventSvc,
+	}
+}
+
+// sealTarget is where sealed values of a cluster can be decrypted
+type sealTarget struct {
+	application string
+	cluster     *clustermodels.Cluster
+	certificate string
+	namespace   string
+	secretName  string
+}
+
+func (c *controller) List(ctx context.Context, clusterID uint) ([]*Secret, error) {
+	if _, err := c.clusterMgr.GetByID(ctx, clusterID); err != nil {
+		return nil, err
+	}
+	secrets, err := c.clusterSecretMgr.ListByClusterID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Secret, 0, len(secrets))
+	for _, secret := range secrets {
+		ret = append(ret, ofSecret(secret))
+	}
+	return ret, nil
+}
+
+func (c *controller) Create(ctx context.Context, clusterID uint, request *CreateSecretRequest) (*Secret, error) {
+	const op = "cluster secret controller: create"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := validateName(request.Name); err != nil {
+		return nil, err
+	}
+	if request.Value == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "value of secret cannot be empty")
+	}
+	user, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	target, err := c.getSealTarget(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	sealedValue, err := clustersecret.Seal(target.certificate, target.namespace,
+		target.secretName, []byte(request.Value))
+	if err != nil {
+		return nil, err
+	}
+	secrets, err := c.clusterSecretMgr.ListByClusterID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	for _, secret := range secrets {
+		if secret.Name == request.Name {
+			return nil, perror.Wrapf(herrors.ErrClusterSecretExists, "secret %s", request.Name)
+		}
+	}
+	secret := &models.ClusterSecret{
+		ClusterID:   clusterID,
+		Name:        request.Name,
+		SealedValue: sealedValue,
+		CreatedBy:   user.GetID(),
+		UpdatedBy:   user.GetID(),
+	}
+	if err := c.commitSecrets(ctx, target, append(secrets, secret)); err != nil {
+		return nil, err
+	}
+	if secret, err = c.clusterSecretMgr.Create(ctx, secret); err != nil {
+		return nil, err
+	}
+	c.recordEvent(ctx, clusterID, eventmodels.ClusterSecretCreated, secret)
+	return ofSecret(secret), nil
+}
+
+func (c *controller) Rotate(ctx context.Context, clusterID uint, name string,
+	request *RotateSecretRequest) (*Secret, error) {
+	const op = "cluster secret controller: rotate"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if request.Value == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "value of secret cannot be empty")
+	}
+	user, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := c.clusterSecretMgr.GetByName(ctx, clusterID, name)
+	if err != nil {
+		return nil, err
+	}
+	target, err := c.getSealTarget(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	sealedValue, err := clustersecret.Seal(target.certificate, target.namespace,
+		target.secretName, []byte(request.Value))
+	if err != nil {
+		return nil, err
+	}
+	secrets, err := c.clusterSecretMgr.ListByClusterID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	rotated := make([]*models.ClusterSecret, 0, len(secrets))
+	for _, s := range secrets {
+		if s.ID == secret.ID {
+			s = &models.ClusterSecret{Name: s.Name, SealedValue: sealedValue}
+		}
+		rotated = append(rotated, s)
+	}
+	if err := c.commitSecrets(ctx, target, rotated); err != nil {
+		return nil, err
+	}
+	if err := c.clusterSecretMgr.Rotate(ctx, secret.ID, sealedValue, user.GetID()); err != nil {
+		return nil, err
+	}
+	if secret, err = c.clusterSecretMgr.GetByName(ctx, clusterID, name); err != nil {
+		return nil, err
+	}
+	c.recordEvent(ctx, clusterID, eventmodels.ClusterSecretRotated, secret)
+	return ofSecret(secret), nil
+}
+
+func (c *controller) Delete(ctx context.Context, clusterID uint, name string) error {
+	const op = "cluster secret controller: delete"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	secret, err := c.clusterSecretMgr.GetByName(ctx, clusterID, name)
+	if err != nil {
+		return err
+	}
+	target, err := c.getSealTarget(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	secrets, err := c.clusterSecretMgr.ListByClusterID(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	remaining := make([]*models.ClusterSecret, 0, len(secrets))
+	for _, s := range secrets {
+		if s.ID != secret.ID {
+			remaining = append(remaining, s)
+		}
+	}
+	if err := c.commitSecrets(ctx, target, remaining); err != nil {
+		return err
+	}
+	if err := c.clusterSecretMgr.DeleteByID(ctx, secret.ID); err != nil {
+		return err
+	}
+	c.recordEvent(ctx, clusterID, eventmodels.ClusterSecretDeleted, secret)
+	return nil
+}
+
+func (c *controller) getSealTarget(ctx context.Context, clusterID uint) (*sealTarget, error) {
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	region, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return nil, err
+	}
+	if region.SealedSecretsCertificate == "" {
+		return nil, perror.Wrapf(herrors.ErrSealedSecretsNotConfigured, "region %s", region.Name)
+	}
+	envValue, err := c.clusterGitRepo.GetEnvValue(ctx, application.Name, cluster.Name, cluster.Template)
+	if err != nil {
+		return nil, err
+	}
+	return &sealTarget{
+		application: application.Name,
+		cluster:     cluster,
+		certificate: region.SealedSecretsCertificate,
+		namespace:   envValue.Namespace,
+		secretName:  fmt.Sprintf("%s-secrets", cluster.Name),
+	}, nil
+}
+
+// commitSecrets writes the sealed secrets of the cluster to its git repo. It is called before the database
+// is changed, so a failed commit changes nothing, and a failed database write is fixed by retrying,
+// which commits the same secrets again.
+func (c *controller) commitSecrets(ctx context.Context, target *sealTarget, secrets []*models.ClusterSecret) error {
+	encryptedData := make(map[string]string, len(secrets))
+	for _, secret := range secrets {
+		encryptedData[secret.Name] = secret.SealedValue
+	}
+	_, err := c.clusterGitRepo.UpdateSecrets(ctx, target.application, target.cluster.Name,
+		target.cluster.Template, &gitrepo.SecretValue{
+			Name:          target.secretName,
+			Namespace:     target.namespace,
+			EncryptedData: encryptedData,
+		})
+	return err
+}
+
+func (c *controller) recordEvent(ctx context.Context, clusterID uint, eventType string,
+	secret *models.ClusterSecret) {
+	var extraStr *string
+	if b, err := json.Marshal(map[string]interface{}{
+		"name":    secret.Name,
+		"version": secret.Version,
+	}); err == nil {
+		s := string(b)
+		extraStr = &s
+	}
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, clusterID, eventType, extraStr)
+}
+
+func validateName(name string) error {
+	if len(name) == 0 || len(name) > _maxSecretNameLength || !_secretNamePattern.MatchString(name) {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"invalid secret name %s, which should consist of alphanumeric characters, '-', '_' or '.'", name)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustersecret
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	gitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/clustersecret/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+)
+
+func newCertificate(t *testing.T) string {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secret"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	assert.Nil(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestCommitBeforeWrite(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&appmodels.Application{}, &clustermodels.Cluster{}, &regionmodels.Region{},
+		&registrymodels.Registry{}, &models.ClusterSecret{}, &eventmodels.Event{}))
+	manager := managerparam.InitManager(db)
+	ctx := context.WithValue(context.Background(), common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "tony",
+		ID:   1,
+	})
+
+	registry := &registrymodels.Registry{Name: "registry"}
+	assert.Nil(t, db.Create(registry).Error)
+	assert.Nil(t, db.Create(&regionmodels.Region{
+		Name:                     "hz",
+		RegistryID:               registry.ID,
+		SealedSecretsCertificate: newCertificate(t),
+	}).Error)
+	application := &appmodels.Application{Name: "app"}
+	assert.Nil(t, db.Create(application).Error)
+	cluster := &clustermodels.Cluster{ApplicationID: application.ID, Name: "cluster", RegionName: "hz"}
+	assert.Nil(t, db.Create(cluster).Error)
+
+	mockCtl := gomock.NewController(t)
+	clusterGitRepo := gitrepomock.NewMockClusterGitRepo(mockCtl)
+	clusterGitRepo.EXPECT().GetEnvValue(gomock.Any(), "app", "cluster", gomock.Any()).
+		Return(&gitrepo.EnvValue{Namespace: "ns"}, nil).AnyTimes()
+	var committed map[string]string
+	commitErr := errors.New("git is unavailable")
+	clusterGitRepo.EXPECT().UpdateSecrets(gomock.Any(), "app", "cluster", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _, _ string, value *gitrepo.SecretValue) (string, error) {
+			if commitErr != nil {
+				return "", commitErr
+			}
+			committed = value.EncryptedData
+			return "commit", nil
+		}).AnyTimes()
+
+	c := &controller{
+		clusterMgr:       manager.ClusterMgr,
+		applicationMgr:   manager.ApplicationMgr,
+		regionMgr:        manager.RegionMgr,
+		clusterSecretMgr: manager.ClusterSecretMgr,
+		clusterGitRepo:   clusterGitRepo,
+		eventSvc:         eventservice.New(manager),
+	}
+
+	// nothing is saved if the commit fails, so the request can be retried
+	_, err = c.Create(ctx, cluster.ID, &CreateSecretRequest{Name: "DB_PASSWORD", Value: "v1"})
+	assert.Equal(t, commitErr, perror.Cause(err))
+	secrets, err := c.List(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(secrets))
+
+	commitErr = nil
+	secret, err := c.Create(ctx, cluster.ID, &CreateSecretRequest{Name: "DB_PASSWORD", Value: "v1"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), secret.Version)
+	sealedValue := committed["DB_PASSWORD"]
+	assert.NotEmpty(t, sealedValue)
+	_, err = c.Create(ctx, cluster.ID, &CreateSecretRequest{Name: "DB_PASSWORD", Value: "v1"})
+	assert.Equal(t, herrors.ErrClusterSecretExists, perror.Cause(err))
+
+	commitErr = errors.New("git is unavailable")
+	_, err = c.Rotate(ctx, cluster.ID, "DB_PASSWORD", &RotateSecretRequest{Value: "v2"})
+	assert.Equal(t, commitErr, perror.Cause(err))
+	saved, err := manager.ClusterSecretMgr.GetByName(ctx, cluster.ID, "DB_PASSWORD")
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), saved.Version)
+	assert.Equal(t, sealedValue, saved.SealedValue)
+
+	assert.Equal(t, commitErr, perror.Cause(c.Delete(ctx, cluster.ID, "DB_PASSWORD")))
+	_, err = manager.ClusterSecretMgr.GetByName(ctx, cluster.ID, "DB_PASSWORD")
+	assert.Nil(t, err)
+
+	commitErr = nil
+	secret, err = c.Rotate(ctx, cluster.ID, "DB_PASSWORD", &RotateSecretRequest{Value: "v2"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint(2), secret.Version)
+	assert.NotEqual(t, sealedValue, committed["DB_PASSWORD"])
+
+	assert.Nil(t, c.Delete(ctx, cluster.ID, "DB_PASSWORD"))
+	assert.Equal(t, 0, len(committed))
+	_, err = manager.ClusterSecretMgr.GetByName(ctx, cluster.ID, "DB_PASSWORD")
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
@@ -0,0 +1,51 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustersecret
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/clustersecret/models"
+)
+
+// Secret is the metadata of cluster secret, values are write-only and never returned
+type Secret struct {
+	Name      string    `json:"name"`
+	Version   uint      `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	CreatedBy uint      `json:"createdBy"`
+	UpdatedBy uint      `json:"updatedBy"`
+}
+
+type CreateSecretRequest struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type RotateSecretRequest struct {
+	Value string `json:"value"`
+}
+
+func ofSecret(secret *models.ClusterSecret) *Secret {
+	return &Secret{
+		Name:      secret.Name,
+		Version:   secret.Version,
+		CreatedAt: secret.CreatedAt,
+		UpdatedAt: secret.UpdatedAt,
+		CreatedBy: secret.CreatedBy,
+		UpdatedBy: secret.UpdatedBy,
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dora
+
+import (
+	"context"
+	"sort"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	doramanager "github.com/horizoncd/horizon/pkg/dora/manager"
+	"github.com/horizoncd/horizon/pkg/dora/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param"
+)
+
+type Controller interface {
+	// GetMetrics computes DORA metrics of an application, or all applications under a group and its subgroups
+	GetMetrics(ctx context.Context, resourceType string, resourceID uint, query *Query) (*Metrics, error)
+}
+
+type controller struct {
+	doraMgr        doramanager.Manager
+	applicationMgr applicationmanager.Manager
+	groupMgr       groupmanager.Manager
+}
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		doraMgr:        param.DoraMgr,
+		applicationMgr: param.ApplicationMgr,
+		groupMgr:       param.GroupMgr,
+	}
+}
+
+func (c *controller) GetMetrics(ctx context.Context, resourceType string,
+	resourceID uint, query *Query) (*Metrics, error) {
+	if !query.Start.Before(query.End) {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"start %v should be before end %v", query.Start, query.End)
+	}
+
+	applicationIDs, err := c.listApplicationIDs(ctx, resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var deployments []*models.Deployment
+	// nil applicationIDs means all applications
+	if applicationIDs == nil || len(applicationIDs) > 0 {
+		deployments, err = c.doraMgr.ListDeployments(ctx, &models.Query{
+			ApplicationIDs: applicationIDs,
+			Environment:    query.Environment,
+			Start:          query.Start,
+			End:            query.End,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	byEnvironment := make(map[string][]*models.Deployment)
+	for _, d := range deployments {
+		byEnvironment[d.Environment] = append(byEnvironment[d.Environment], d)
+	}
+	environments := make([]*EnvironmentMetrics, 0, len(byEnvironment))
+	for env, ds := range byEnvironment {
+		environments = append(environments, &EnvironmentMetrics{
+			Environment: env,
+			Metrics:     models.Calculate(ds, query.Start, query.End),
+		})
+	}
+	sort.Slice(environments, func(i, j int) bool {
+		return environments[i].Environment < environments[j].Environment
+	})
+
+	return &Metrics{
+		Start:        query.Start,
+		End:          query.End,
+		Metrics:      models.Calculate(deployments, query.Start, query.End),
+		Environments: environments,
+	}, nil
+}
+
+// listApplicationIDs lists the application, or the applications under the group subtree.
+// It returns nil for the root group, which contains all applications.
+func (c *controller) listApplicationIDs(ctx context.Context, resourceType string,
+	resourceID uint) ([]uint, error) {
+	switch resourceType {
+	case common.ResourceApplication:
+		if _, err := c.applicationMgr.GetByID(ctx, resourceID); err != nil {
+			return nil, err
+		}
+		return []uint{resourceID}, nil
+	case common.ResourceGroup:
+		if c.groupMgr.IsRootGroup(resourceID) {
+			return nil, nil
+		}
+		if _, err := c.groupMgr.GetByID(ctx, resourceID); err != nil {
+			return nil, err
+		}
+		groups, err := c.groupMgr.GetSubGroupsByGroupIDs(ctx, []uint{resourceID})
+		if err != nil {
+			return nil, err
+		}
+		groupIDs := make([]uint, 0, len(groups))
+		for _, group := range groups {
+			groupIDs = append(groupIDs, group.ID)
+		}
+		applications, err := c.applicationMgr.GetByGroupIDs(ctx, groupIDs)
+		if err != nil {
+			return nil, err
+		}
+		applicationIDs := make([]uint, 0, len(applications))
+		for _, application := range applications {
+			applicationIDs = append(applicationIDs, application.ID)
+		}
+		return applicationIDs, nil
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid resource type: %s", resourceType)
+	}
+}
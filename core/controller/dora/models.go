@@ -0,0 +1,41 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dora
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/dora/models"
+)
+
+// Query is the time range and environment to compute metrics of
+type Query struct {
+	Environment string
+	Start       time.Time
+	End         time.Time
+}
+
+type Metrics struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	*models.Metrics
+	// Environments are the metrics of each environment
+	Environments []*EnvironmentMetrics `json:"environments"`
+}
+
+type EnvironmentMetrics struct {
+	Environment string `json:"environment"`
+	*models.Metrics
+}
@@ -40,6 +40,7 @@ import (
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	"github.com/horizoncd/horizon/pkg/util/errors"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"github.com/horizoncd/horizon/pkg/visibility"
 )
 
 const (
@@ -83,6 +84,7 @@ type controller struct {
 	memberSvc          memberservice.Service
 	templateMgr        tmanager.Manager
 	templateReleaseMgr trmanager.Manager
+	visibilityChecker  visibility.Checker
 }
 
 // NewController initializes a new group controller
@@ -94,6 +96,7 @@ func NewController(param *param.Param) Controller {
 		memberSvc:          param.MemberService,
 		templateMgr:        param.TemplateMgr,
 		templateReleaseMgr: param.TemplateReleaseMgr,
+		visibilityChecker:  visibility.NewChecker(param.Manager),
 	}
 }
 
@@ -115,8 +118,13 @@ func (c *controller) GetChildren(ctx context.Context, id uint, pageNumber, pageS
 		}
 	}
 
-	// query children
-	children, count, err := c.groupManager.GetChildren(ctx, id, pageNumber, pageSize)
+	// query children, hiding private subgroups and applications from non-members
+	filter, err := c.newVisibilityFilter(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	children, count, err := c.groupManager.GetChildren(ctx, id, filter.hiddenGroupIDs, filter.hiddenApplicationIDs,
+		pageNumber, pageSize)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -133,7 +141,7 @@ func (c *controller) GetChildren(ctx context.Context, id uint, pageNumber, pageS
 		return nil, 0, err
 	}
 	childrenCountMap := map[uint]int{}
-	for _, g := range groups {
+	for _, g := range filter.groups(groups) {
 		childrenCountMap[g.ParentID]++
 	}
 
@@ -177,7 +185,12 @@ func (c *controller) SearchGroups(ctx context.Context, params *SearchParams) ([]
 	if err != nil {
 		return nil, 0, err
 	}
-	if matchedGroups == nil {
+	filter, err := c.newVisibilityFilter(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	matchedGroups = filter.groups(matchedGroups)
+	if len(matchedGroups) == 0 {
 		return []*service.Child{}, 0, nil
 	}
 
@@ -229,7 +242,25 @@ func (c *controller) SearchChildren(ctx context.Context, params *SearchParams) (
 		return nil, 0, err
 	}
 
-	matchedGroups = append(matchedGroups, groups...)
+	// hide groups and applications under private groups from non-members
+	filter, err := c.newVisibilityFilter(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	matchedGroups = filter.groups(matchedGroups)
+	matchedApplications = filter.applications(matchedApplications)
+	visibleGroupIDs := make(map[uint]struct{}, len(matchedApplications))
+	for _, application := range matchedApplications {
+		visibleGroupIDs[application.GroupID] = struct{}{}
+	}
+	parents := make([]*models.Group, 0, len(groups))
+	for _, group := range groups {
+		if _, ok := visibleGroupIDs[group.ID]; ok {
+			parents = append(parents, group)
+		}
+	}
+
+	matchedGroups = append(matchedGroups, parents...)
 	// query groups in ids (split matchedGroups' traversalIDs by ',')
 	groups, err = c.formatGroupsInTraversalIDs(ctx, matchedGroups)
 	if err != nil {
@@ -264,8 +295,12 @@ func (c *controller) GetSubGroups(ctx context.Context, id uint, pageNumber, page
 		}
 	}
 
-	// query subGroups
-	subGroups, count, err := c.groupManager.GetSubGroups(ctx, id, pageNumber, pageSize)
+	// query subGroups, hiding private subgroups from non-members
+	filter, err := c.newVisibilityFilter(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	subGroups, count, err := c.groupManager.GetSubGroups(ctx, id, filter.hiddenGroupIDs, pageNumber, pageSize)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -280,7 +315,7 @@ func (c *controller) GetSubGroups(ctx context.Context, id uint, pageNumber, page
 		return nil, 0, err
 	}
 	childrenCountMap := map[uint]int{}
-	for _, g := range groups {
+	for _, g := range filter.groups(groups) {
 		childrenCountMap[g.ParentID]++
 	}
 
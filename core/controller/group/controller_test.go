@@ -38,12 +38,15 @@ import (
 	"github.com/horizoncd/horizon/pkg/group/models"
 	"github.com/horizoncd/horizon/pkg/group/service"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
 	"github.com/horizoncd/horizon/pkg/server/global"
+	teammodels "github.com/horizoncd/horizon/pkg/team/models"
 	tmodels "github.com/horizoncd/horizon/pkg/template/models"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	callbacks "github.com/horizoncd/horizon/pkg/util/ormcallbacks"
 )
 
@@ -52,7 +55,10 @@ var (
 	db, _    = orm.NewSqliteDB("")
 	ctx      = context.TODO()
 	manager  = managerparam.InitManager(db)
-	groupCtl = NewController(&param.Param{Manager: manager})
+	groupCtl = NewController(&param.Param{
+		Manager:       manager,
+		MemberService: memberservice.NewService(nil, nil, manager),
+	})
 )
 
 func GroupValueEqual(g1, g2 *models.Group) bool {
@@ -87,7 +93,18 @@ func init() {
 		fmt.Printf("%+v", err)
 		os.Exit(1)
 	}
-	err = db.AutoMigrate(&membermodels.Member{})
+	err = db.AutoMigrate(&membermodels.Member{}, &teammodels.TeamMember{})
+	if err != nil {
+		fmt.Printf("%+v", err)
+		os.Exit(1)
+	}
+	err = db.AutoMigrate(&usermodels.User{})
+	if err != nil {
+		fmt.Printf("%+v", err)
+		os.Exit(1)
+	}
+	// the current user is the owner of the groups it creates
+	err = db.Create(&usermodels.User{Model: global.Model{ID: 110}, Name: "tony"}).Error
 	if err != nil {
 		fmt.Printf("%+v", err)
 		os.Exit(1)
@@ -1299,6 +1316,120 @@ func TestControllerUpdateBasic(t *testing.T) {
 	}
 }
 
+func TestControllerPrivateGroups(t *testing.T) {
+	db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Group{})
+	publicID, err := groupCtl.CreateGroup(ctx, &NewGroup{
+		Name:            "public",
+		Path:            "public",
+		VisibilityLevel: models.VisibilityPublic,
+	})
+	assert.Nil(t, err)
+	privateID, err := groupCtl.CreateGroup(ctx, &NewGroup{
+		Name:            "private",
+		Path:            "private",
+		VisibilityLevel: models.VisibilityPrivate,
+	})
+	assert.Nil(t, err)
+	subID, err := groupCtl.CreateGroup(ctx, &NewGroup{
+		Name:            "inherited",
+		Path:            "inherited",
+		VisibilityLevel: models.VisibilityPublic,
+		ParentID:        privateID,
+	})
+	assert.Nil(t, err)
+	app, err := applicationdao.NewDAO(db).Create(ctx, &appmodels.Application{
+		Name:    "inherited-app",
+		GroupID: subID,
+	}, nil)
+	assert.Nil(t, err)
+	markedApp, err := applicationdao.NewDAO(db).Create(ctx, &appmodels.Application{
+		Name:            "marked-app",
+		GroupID:         publicID,
+		VisibilityLevel: models.VisibilityPrivate,
+	}, nil)
+	assert.Nil(t, err)
+
+	childIDs := func(children []*service.Child) []uint {
+		ids := make([]uint, 0, len(children))
+		for _, child := range children {
+			ids = append(ids, child.ID)
+			for _, grandchild := range child.Children {
+				ids = append(ids, grandchild.ID)
+			}
+		}
+		return ids
+	}
+
+	// the creator is the owner of the private group
+	children, count, err := groupCtl.GetSubGroups(ctx, 0, 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.ElementsMatch(t, []uint{publicID, privateID}, childIDs(children))
+
+	// private groups and everything under them are hidden from non-members
+	otherCtx := context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "jerry",
+		ID:   111,
+	})
+	children, count, err = groupCtl.GetSubGroups(otherCtx, 0, 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, []uint{publicID}, childIDs(children))
+
+	children, count, err = groupCtl.GetChildren(otherCtx, 0, 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, []uint{publicID}, childIDs(children))
+
+	children, _, err = groupCtl.SearchGroups(otherCtx, &SearchParams{Filter: "inherited"})
+	assert.Nil(t, err)
+	assert.Empty(t, children)
+
+	children, _, err = groupCtl.SearchChildren(otherCtx, &SearchParams{Filter: "inherited"})
+	assert.Nil(t, err)
+	assert.Empty(t, children)
+
+	// so are private applications, even under public groups
+	children, count, err = groupCtl.GetChildren(ctx, publicID, 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, []uint{markedApp.ID}, childIDs(children))
+
+	children, count, err = groupCtl.GetChildren(otherCtx, publicID, 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+	assert.Empty(t, children)
+
+	children, _, err = groupCtl.SearchChildren(otherCtx, &SearchParams{Filter: "marked"})
+	assert.Nil(t, err)
+	assert.Empty(t, children)
+
+	// members of the private group see it again
+	err = db.Create(&usermodels.User{Model: global.Model{ID: 111}, Name: "jerry"}).Error
+	assert.Nil(t, err)
+	_, err = manager.MemberMgr.Create(ctx, &membermodels.Member{
+		ResourceType: membermodels.TypeGroup,
+		ResourceID:   privateID,
+		Role:         role.Guest,
+		MemberType:   membermodels.MemberUser,
+		MemberNameID: 111,
+	})
+	assert.Nil(t, err)
+
+	children, count, err = groupCtl.GetSubGroups(otherCtx, 0, 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.ElementsMatch(t, []uint{publicID, privateID}, childIDs(children))
+
+	children, _, err = groupCtl.SearchChildren(otherCtx, &SearchParams{Filter: "inherited"})
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{privateID, subID}, childIDs(children))
+	assert.Equal(t, app.ID, children[0].Children[0].Children[0].ID)
+
+	db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Group{})
+	db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&appmodels.Application{})
+}
+
 func TestGenerateChildrenWithLevelStruct(t *testing.T) {
 	type args struct {
 		groupID      uint
@@ -0,0 +1,71 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package group
+
+import (
+	"context"
+
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/group/models"
+)
+
+// visibilityFilter filters out the private applications, the groups and applications under private groups
+// which the current user is not a member of
+type visibilityFilter struct {
+	hiddenGroupIDs       []uint
+	hiddenApplicationIDs []uint
+	hiddenGroups         map[uint]struct{}
+	hiddenApplications   map[uint]struct{}
+}
+
+func (c *controller) newVisibilityFilter(ctx context.Context) (*visibilityFilter, error) {
+	hidden, err := c.visibilityChecker.HiddenResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filter := &visibilityFilter{
+		hiddenGroupIDs:       hidden.GroupIDs,
+		hiddenApplicationIDs: hidden.ApplicationIDs,
+		hiddenGroups:         make(map[uint]struct{}, len(hidden.GroupIDs)),
+		hiddenApplications:   make(map[uint]struct{}, len(hidden.ApplicationIDs)),
+	}
+	for _, id := range hidden.GroupIDs {
+		filter.hiddenGroups[id] = struct{}{}
+	}
+	for _, id := range hidden.ApplicationIDs {
+		filter.hiddenApplications[id] = struct{}{}
+	}
+	return filter, nil
+}
+
+func (f *visibilityFilter) groups(groups []*models.Group) []*models.Group {
+	visibleGroups := make([]*models.Group, 0, len(groups))
+	for _, group := range groups {
+		if _, ok := f.hiddenGroups[group.ID]; !ok {
+			visibleGroups = append(visibleGroups, group)
+		}
+	}
+	return visibleGroups
+}
+
+func (f *visibilityFilter) applications(applications []*appmodels.Application) []*appmodels.Application {
+	visibleApplications := make([]*appmodels.Application, 0, len(applications))
+	for _, application := range applications {
+		if _, ok := f.hiddenApplications[application.ID]; !ok {
+			visibleApplications = append(visibleApplications, application)
+		}
+	}
+	return visibleApplications
+}
@@ -28,9 +28,17 @@ import (
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/q"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/idp/ldap"
 	"github.com/horizoncd/horizon/pkg/idp/manager"
+	"github.com/horizoncd/horizon/pkg/idp/models"
 	"github.com/horizoncd/horizon/pkg/idp/utils"
+	membermanager "github.com/horizoncd/horizon/pkg/member/manager"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	mfaservice "github.com/horizoncd/horizon/pkg/mfa/service"
 	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+	teammanager "github.com/horizoncd/horizon/pkg/team/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	usermodel "github.com/horizoncd/horizon/pkg/user/models"
 	linkmanager "github.com/horizoncd/horizon/pkg/userlink/manager"
@@ -41,11 +49,16 @@ var (
 	linkKey     = "link"
 )
 
+// syncGrantor is the grantor of bindings granted by ldap group sync, bindings updated manually get a real grantor
+const syncGrantor uint = 0
+
 type Controller interface {
 	ListAuthEndpoints(ctx context.Context, redirectURL string) ([]*AuthInfo, error)
 	List(ctx context.Context) ([]*IdentityProvider, error)
 	GetByID(ctx context.Context, id uint) (*IdentityProvider, error)
 	LoginOrLink(ctx context.Context, code string, state string, redirectURL string) (*usermodel.User, error)
+	// LoginWithLDAP authenticates user against ldap provider, and returns whether the mfa is verified
+	LoginWithLDAP(ctx context.Context, idpID uint, request *LDAPLoginRequest) (*usermodel.User, bool, error)
 	Create(c context.Context, createParam *CreateIDPRequest) (*IdentityProvider, error)
 	Delete(c context.Context, idpID uint) error
 	Update(c context.Context, id uint, updateParam *UpdateIDPRequest) (*IdentityProvider, error)
@@ -53,16 +66,28 @@ type Controller interface {
 }
 
 type controller struct {
-	idpManager  manager.Manager
-	userManager usermanager.Manager
-	linkManager linkmanager.Manager
+	idpManager    manager.Manager
+	userManager   usermanager.Manager
+	linkManager   linkmanager.Manager
+	groupManager  groupmanager.Manager
+	memberManager membermanager.Manager
+	roleSvc       role.Service
+	mfaSvc        mfaservice.Service
+	teamManager   teammanager.Manager
+	ldapDial      ldap.Dialer
 }
 
 func NewController(param *param.Param) Controller {
 	return &controller{
-		idpManager:  param.IdpMgr,
-		userManager: param.UserMgr,
-		linkManager: param.UserLinksMgr,
+		idpManager:    param.IdpMgr,
+		userManager:   param.UserMgr,
+		linkManager:   param.UserLinksMgr,
+		groupManager:  param.GroupMgr,
+		memberManager: param.MemberMgr,
+		roleSvc:       param.RoleService,
+		mfaSvc:        param.MFASvc,
+		teamManager:   param.TeamMgr,
+		ldapDial:      ldap.Dial,
 	}
 }
 
@@ -77,7 +102,12 @@ func (c *controller) ListAuthEndpoints(ctx context.Context, redirectURL string)
 		res  = make([]*AuthInfo, 0)
 	)
 	for _, idp := range idps {
-		info := &AuthInfo{ID: idp.ID, Name: idp.Name, DisplayName: idp.DisplayName}
+		info := &AuthInfo{ID: idp.ID, Name: idp.Name, DisplayName: idp.DisplayName, Kind: idp.Kind}
+		if idp.IsLDAP() {
+			// ldap provider signs in with username and password, there's no auth url
+			res = append(res, info)
+			continue
+		}
 		conf, err = utils.MakeOuath2Config(ctx, idp, oidc.ScopeOpenID)
 		if err != nil {
 			return nil, err
@@ -186,6 +216,12 @@ func (c *controller) LoginOrLink(ctx context.Context,
 			}
 		}
 	}
+	if user != nil {
+		// teams synced from the idp follow the groups of user in the idp
+		if err := c.teamManager.SyncExternalTeams(ctx, idp.ID, user.ID, claims.Groups); err != nil {
+			return nil, err
+		}
+	}
 	return user, nil
 }
 
@@ -200,6 +236,12 @@ func (c *controller) GetByID(ctx context.Context, id uint) (*IdentityProvider, e
 func (c *controller) Create(ctx context.Context,
 	createParam *CreateIDPRequest) (*IdentityProvider, error) {
 	idp := createParam.toModel()
+	if idp.Kind == "" {
+		idp.Kind = models.KindOIDC
+	}
+	if err := c.validateLDAPConfig(ctx, idp); err != nil {
+		return nil, err
+	}
 
 	_, err := c.idpManager.GetByCondition(ctx,
 		q.Query{Keywords: map[string]interface{}{idpconst.QueryName: idp.Name}})
@@ -227,6 +269,22 @@ func (c *controller) Delete(ctx context.Context, idpID uint) error {
 func (c *controller) Update(ctx context.Context,
 	id uint, updateParam *UpdateIDPRequest) (*IdentityProvider, error) {
 	updateIDP := updateParam.toModel()
+	if updateIDP.LDAPConfig != nil {
+		current, err := c.idpManager.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		// bind password is never returned, so keep it unchanged if it's not provided
+		if updateIDP.LDAPConfig.BindPassword == "" && current.LDAPConfig != nil {
+			updateIDP.LDAPConfig.BindPassword = current.LDAPConfig.BindPassword
+		}
+		if updateIDP.Kind == "" {
+			updateIDP.Kind = current.Kind
+		}
+		if err := c.validateLDAPConfig(ctx, updateIDP); err != nil {
+			return nil, err
+		}
+	}
 	idp, err := c.idpManager.Update(ctx, id, updateIDP)
 	if err != nil {
 		return nil, err
@@ -254,3 +312,155 @@ func (c *controller) GetDiscovery(ctx context.Context, s Discovery) (*DiscoveryC
 		Issuer:                issuer,
 	}, nil
 }
+
+func (c *controller) LoginWithLDAP(ctx context.Context,
+	idpID uint, request *LDAPLoginRequest) (*usermodel.User, bool, error) {
+	idp, err := c.idpManager.GetByID(ctx, idpID)
+	if err != nil {
+		return nil, false, err
+	}
+	if !idp.IsLDAP() {
+		return nil, false, perror.Wrapf(herrors.ErrParamInvalid,
+			"idp %s is not a ldap provider", idp.Name)
+	}
+
+	entry, err := ldap.Authenticate(ctx, c.ldapDial, idp.LDAPConfig, request.Username, request.Password)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var user *usermodel.User
+	if link, err := c.linkManager.GetByIDPAndSub(ctx, idp.ID, entry.DN); err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return nil, false, err
+		}
+		// for register
+		if entry.Email == "" {
+			return nil, false, perror.Wrapf(herrors.ErrForbidden,
+				"there's no email of %s in ldap", entry.DN)
+		}
+		user, err = c.userManager.Create(ctx, &usermodel.User{
+			Name:     entry.Name,
+			FullName: entry.FullName,
+			Email:    entry.Email,
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		_, err = c.linkManager.CreateLink(ctx, user.ID, idp.ID, &utils.Claims{
+			Sub:   entry.DN,
+			Name:  entry.FullName,
+			Email: entry.Email,
+		}, false)
+		if err != nil {
+			return nil, false, err
+		}
+	} else {
+		// for signing in
+		user, err = c.userManager.GetUserByID(ctx, link.UserID)
+		if err != nil {
+			return nil, false, err
+		}
+		if user.Banned {
+			return nil, false, perror.Wrapf(herrors.ErrForbidden, "user is banned")
+		}
+	}
+
+	if err := c.syncLDAPGroups(ctx, idp.LDAPConfig, entry, user); err != nil {
+		return nil, false, err
+	}
+	if err := c.teamManager.SyncExternalTeams(ctx, idp.ID, user.ID, entry.Groups); err != nil {
+		return nil, false, err
+	}
+
+	enabled, err := c.mfaSvc.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	if !enabled {
+		return user, false, nil
+	}
+	if request.OTP == "" {
+		return nil, false, perror.Wrapf(herrors.ErrMFARequired,
+			"otp is required for user %s", user.Name)
+	}
+	if err := c.mfaSvc.Verify(ctx, user.ID, request.OTP); err != nil {
+		return nil, false, err
+	}
+	return user, true, nil
+}
+
+// syncLDAPGroups grants the mapped roles of horizon groups to user according to the ldap groups of user,
+// and revokes the roles granted by former syncs once user leaves the ldap groups.
+// Bindings granted or updated manually are left untouched, so that they won't be downgraded or revoked.
+func (c *controller) syncLDAPGroups(ctx context.Context, config *models.LDAPConfig,
+	entry *ldap.Entry, user *usermodel.User) error {
+	granted := make(map[uint]bool, len(config.GroupMappings))
+	for _, mapping := range config.GroupMappings {
+		if entry.InGroup(mapping.LDAPGroup) {
+			granted[mapping.GroupID] = true
+		}
+	}
+	for _, mapping := range config.GroupMappings {
+		member, err := c.memberManager.Get(ctx, membermodels.TypeGroup, mapping.GroupID,
+			membermodels.MemberUser, user.ID)
+		if err != nil {
+			return err
+		}
+		if !granted[mapping.GroupID] {
+			if member != nil && member.GrantedBy == syncGrantor && member.Role == mapping.Role {
+				if err := c.memberManager.DeleteMember(ctx, member.ID); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if member != nil {
+			continue
+		}
+		_, err = c.memberManager.Create(ctx, &membermodels.Member{
+			ResourceType: membermodels.TypeGroup,
+			ResourceID:   mapping.GroupID,
+			Role:         mapping.Role,
+			MemberType:   membermodels.MemberUser,
+			MemberNameID: user.ID,
+			GrantedBy:    syncGrantor,
+			CreatedBy:    user.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *controller) validateLDAPConfig(ctx context.Context, idp *models.IdentityProvider) error {
+	if !idp.IsLDAP() {
+		if idp.LDAPConfig != nil {
+			return perror.Wrapf(herrors.ErrParamInvalid,
+				"ldapConfig is only allowed for kind %s", models.KindLDAP)
+		}
+		return nil
+	}
+	config := idp.LDAPConfig
+	if config == nil || config.URL == "" || config.UserBaseDN == "" {
+		return perror.Wrap(herrors.ErrParamInvalid, "url and userBaseDN of ldapConfig are required")
+	}
+	for _, mapping := range config.GroupMappings {
+		if mapping.LDAPGroup == "" {
+			return perror.Wrap(herrors.ErrParamInvalid, "ldapGroup of group mapping is required")
+		}
+		if _, err := c.groupManager.GetByID(ctx, mapping.GroupID); err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				return perror.Wrapf(herrors.ErrParamInvalid,
+					"group %d of group mapping is not found", mapping.GroupID)
+			}
+			return err
+		}
+		if _, err := c.roleSvc.GetRole(ctx, mapping.Role); err != nil {
+			return perror.Wrapf(herrors.ErrParamInvalid, "role %s of group mapping is invalid: %v",
+				mapping.Role, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/idp/ldap/ldaptest"
+	"github.com/horizoncd/horizon/pkg/idp/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/mfa"
+	mfamodels "github.com/horizoncd/horizon/pkg/mfa/models"
+	mfaservice "github.com/horizoncd/horizon/pkg/mfa/service"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+	teammodels "github.com/horizoncd/horizon/pkg/team/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	linkmodels "github.com/horizoncd/horizon/pkg/userlink/models"
+)
+
+const roles = `
+RolePriorityRankDesc: [owner,guest]
+DefaultRole: guest
+Roles:
+  - name: owner
+    rules:
+    - apiGroups: ["*"]
+      resources: ["*"]
+      verbs: ["*"]
+      scopes: ["*"]
+      nonResourceURLs: ["*"]
+  - name: guest
+    rules:
+    - apiGroups: ["*"]
+      resources: ["*"]
+      verbs: ["get"]
+      scopes: ["*"]
+      nonResourceURLs: ["*"]
+`
+
+func TestLDAP(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&usermodels.User{}, &linkmodels.UserLink{}, &models.IdentityProvider{},
+		&groupmodels.Group{}, &membermodels.Member{}, &mfamodels.UserMFA{},
+		&teammodels.Team{}, &teammodels.TeamMember{}); err != nil {
+		panic(err)
+	}
+	mgr := managerparam.InitManager(db)
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name:  "admin",
+		ID:    100,
+		Admin: true,
+	})
+	roleSvc, err := role.NewFileRole(ctx, strings.NewReader(roles))
+	assert.Nil(t, err)
+
+	group := &groupmodels.Group{Name: "ops", Path: "ops", TraversalIDs: "1"}
+	assert.Nil(t, db.Create(group).Error)
+	qaGroup := &groupmodels.Group{Name: "qa", Path: "qa", TraversalIDs: "2"}
+	assert.Nil(t, db.Create(qaGroup).Error)
+
+	directory := ldaptest.NewDirectory()
+	directory.AddEntry("cn=admin,dc=example,dc=com", "admin-secret", nil)
+	directory.AddEntry("uid=tom,ou=people,dc=example,dc=com", "tom-secret", map[string][]string{
+		"uid":  {"tom"},
+		"mail": {"tom@example.com"},
+		"cn":   {"Tom Cat"},
+	})
+	directory.AddEntry("cn=ops,ou=groups,dc=example,dc=com", "", map[string][]string{
+		"cn":     {"ops"},
+		"member": {"uid=tom,ou=people,dc=example,dc=com"},
+	})
+	qaAttributes := map[string][]string{
+		"cn":     {"qa"},
+		"member": {"uid=tom,ou=people,dc=example,dc=com"},
+	}
+	directory.AddEntry("cn=qa,ou=groups,dc=example,dc=com", "", qaAttributes)
+
+	c := NewController(&param.Param{
+		Manager:     mgr,
+		RoleService: roleSvc,
+		MFASvc:      mfaservice.NewService(mgr),
+	}).(*controller)
+	c.ldapDial = directory.Dial
+
+	ldapConfig := &models.LDAPConfig{
+		URL:          "ldap://ldap.example.com",
+		BindDN:       "cn=admin,dc=example,dc=com",
+		BindPassword: "admin-secret",
+		UserBaseDN:   "ou=people,dc=example,dc=com",
+		GroupBaseDN:  "ou=groups,dc=example,dc=com",
+		GroupMappings: []models.LDAPGroupMapping{
+			{LDAPGroup: "ops", GroupID: group.ID, Role: "guest"},
+			{LDAPGroup: "qa", GroupID: qaGroup.ID, Role: "guest"},
+		},
+	}
+
+	// invalid configs
+	_, err = c.Create(ctx, &CreateIDPRequest{UpdateIDPRequest{
+		Name: "ldap", Kind: models.KindLDAP,
+	}})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	invalidConfig := *ldapConfig
+	invalidConfig.GroupMappings = []models.LDAPGroupMapping{{LDAPGroup: "ops", GroupID: group.ID, Role: "nobody"}}
+	_, err = c.Create(ctx, &CreateIDPRequest{UpdateIDPRequest{
+		Name: "ldap", Kind: models.KindLDAP, LDAPConfig: &invalidConfig,
+	}})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	idp, err := c.Create(ctx, &CreateIDPRequest{UpdateIDPRequest{
+		Name: "ldap", DisplayName: "LDAP", Kind: models.KindLDAP, LDAPConfig: ldapConfig,
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, models.KindLDAP, idp.Kind)
+	assert.Equal(t, "", idp.LDAPConfig.BindPassword)
+
+	endpoints, err := c.ListAuthEndpoints(ctx, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(endpoints))
+	assert.Equal(t, models.KindLDAP, endpoints[0].Kind)
+	assert.Equal(t, "", endpoints[0].AuthURL)
+
+	// updating without bind password keeps the original one
+	updateConfig := *ldapConfig
+	updateConfig.BindPassword = ""
+	_, err = c.Update(ctx, idp.ID, &UpdateIDPRequest{LDAPConfig: &updateConfig})
+	assert.Nil(t, err)
+	stored, err := mgr.IdpMgr.GetByID(ctx, idp.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "admin-secret", stored.LDAPConfig.BindPassword)
+
+	_, _, err = c.LoginWithLDAP(ctx, idp.ID, &LDAPLoginRequest{Username: "tom", Password: "wrong"})
+	assert.Equal(t, herrors.ErrLDAPInvalidCredentials, perror.Cause(err))
+
+	// first login registers the user and grants roles by group mappings
+	user, verified, err := c.LoginWithLDAP(ctx, idp.ID, &LDAPLoginRequest{Username: "tom", Password: "tom-secret"})
+	assert.Nil(t, err)
+	assert.False(t, verified)
+	assert.Equal(t, "tom", user.Name)
+	assert.Equal(t, "Tom Cat", user.FullName)
+	assert.Equal(t, "tom@example.com", user.Email)
+
+	member, err := mgr.MemberMgr.Get(ctx, membermodels.TypeGroup, group.ID, membermodels.MemberUser, user.ID)
+	assert.Nil(t, err)
+	assert.NotNil(t, member)
+	assert.Equal(t, "guest", member.Role)
+
+	// teams synced from the idp follow the groups of user
+	opsTeam, err := mgr.TeamMgr.Create(ctx, &teammodels.Team{Name: "ops", IdpID: idp.ID, ExternalGroup: "ops"})
+	assert.Nil(t, err)
+	devTeam, err := mgr.TeamMgr.Create(ctx, &teammodels.Team{Name: "dev", IdpID: idp.ID, ExternalGroup: "dev"})
+	assert.Nil(t, err)
+	assert.Nil(t, mgr.TeamMgr.AddMembers(ctx, devTeam.ID, []uint{user.ID}, 0))
+
+	// roles granted manually are not downgraded
+	_, err = mgr.MemberMgr.UpdateByID(ctx, member.ID, "owner")
+	assert.Nil(t, err)
+	again, _, err := c.LoginWithLDAP(ctx, idp.ID, &LDAPLoginRequest{Username: "tom", Password: "tom-secret"})
+	assert.Nil(t, err)
+	assert.Equal(t, user.ID, again.ID)
+	member, err = mgr.MemberMgr.Get(ctx, membermodels.TypeGroup, group.ID, membermodels.MemberUser, user.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "owner", member.Role)
+	teamIDs, err := mgr.TeamMgr.ListTeamIDsByUserID(ctx, user.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{opsTeam.ID}, teamIDs)
+
+	// roles granted by sync are revoked once user leaves the ldap group, roles granted manually are kept
+	member, err = mgr.MemberMgr.Get(ctx, membermodels.TypeGroup, qaGroup.ID, membermodels.MemberUser, user.ID)
+	assert.Nil(t, err)
+	assert.NotNil(t, member)
+	qaAttributes["member"] = nil
+	_, err = mgr.MemberMgr.UpdateByID(ctx, member.ID, "guest")
+	assert.Nil(t, err)
+	_, _, err = c.LoginWithLDAP(ctx, idp.ID, &LDAPLoginRequest{Username: "tom", Password: "tom-secret"})
+	assert.Nil(t, err)
+	member, err = mgr.MemberMgr.Get(ctx, membermodels.TypeGroup, qaGroup.ID, membermodels.MemberUser, user.ID)
+	assert.Nil(t, err)
+	assert.NotNil(t, member)
+	assert.Nil(t, mgr.MemberMgr.DeleteMember(ctx, member.ID))
+
+	qaAttributes["member"] = []string{"uid=tom,ou=people,dc=example,dc=com"}
+	_, _, err = c.LoginWithLDAP(ctx, idp.ID, &LDAPLoginRequest{Username: "tom", Password: "tom-secret"})
+	assert.Nil(t, err)
+	member, err = mgr.MemberMgr.Get(ctx, membermodels.TypeGroup, qaGroup.ID, membermodels.MemberUser, user.ID)
+	assert.Nil(t, err)
+	assert.NotNil(t, member)
+	qaAttributes["member"] = nil
+	_, _, err = c.LoginWithLDAP(ctx, idp.ID, &LDAPLoginRequest{Username: "tom", Password: "tom-secret"})
+	assert.Nil(t, err)
+	member, err = mgr.MemberMgr.Get(ctx, membermodels.TypeGroup, qaGroup.ID, membermodels.MemberUser, user.ID)
+	assert.Nil(t, err)
+	assert.Nil(t, member)
+	member, err = mgr.MemberMgr.Get(ctx, membermodels.TypeGroup, group.ID, membermodels.MemberUser, user.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "owner", member.Role)
+
+	// mfa is required once enabled
+	secret, err := mfa.GenerateSecret()
+	assert.Nil(t, err)
+	_, err = mgr.UserMFAMgr.Create(ctx, &mfamodels.UserMFA{UserID: user.ID, Secret: secret, Enabled: true})
+	assert.Nil(t, err)
+	_, _, err = c.LoginWithLDAP(ctx, idp.ID, &LDAPLoginRequest{Username: "tom", Password: "tom-secret"})
+	assert.Equal(t, herrors.ErrMFARequired, perror.Cause(err))
+	code, err := mfa.GenerateCode(secret, time.Now())
+	assert.Nil(t, err)
+	_, verified, err = c.LoginWithLDAP(ctx, idp.ID,
+		&LDAPLoginRequest{Username: "tom", Password: "tom-secret", OTP: code})
+	assert.Nil(t, err)
+	assert.True(t, verified)
+
+	// banned user can't sign in
+	user.Banned = true
+	_, err = mgr.UserMgr.UpdateByID(ctx, user.ID, user)
+	assert.Nil(t, err)
+	_, _, err = c.LoginWithLDAP(ctx, idp.ID, &LDAPLoginRequest{Username: "tom", Password: "tom-secret"})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+}
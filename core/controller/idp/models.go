@@ -17,14 +17,16 @@ package idp
 import (
 	"time"
 
+	"github.com/horizoncd/horizon/lib/encrypt"
 	"github.com/horizoncd/horizon/pkg/idp/models"
 )
 
 type AuthInfo struct {
-	ID          uint   `json:"id"`
-	AuthURL     string `json:"authURL"`
-	Name        string `json:"name"`
-	DisplayName string `json:"displayName,omitempty"`
+	ID          uint        `json:"id"`
+	AuthURL     string      `json:"authURL"`
+	Name        string      `json:"name"`
+	DisplayName string      `json:"displayName,omitempty"`
+	Kind        models.Kind `json:"kind,omitempty"`
 }
 
 type IdentityProvider struct {
@@ -42,6 +44,8 @@ type IdentityProvider struct {
 	Jwks                    string                         `json:"jwks,omitempty"`
 	ClientID                string                         `json:"clientID,omitempty"`
 	ClientSecret            string                         `json:"clientSecret,omitempty"`
+	Kind                    models.Kind                    `json:"kind,omitempty"`
+	LDAPConfig              *models.LDAPConfig             `json:"ldapConfig,omitempty"`
 	CreatedAt               time.Time                      `json:"createdAt"`
 	UpdatedAt               time.Time                      `json:"updatedAt"`
 }
@@ -51,6 +55,17 @@ func ofIDPModel(idp *models.IdentityProvider) *IdentityProvider {
 	if idp.TokenEndpointAuthMethod != nil {
 		method = *idp.TokenEndpointAuthMethod
 	}
+	var ldapConfig *models.LDAPConfig
+	if idp.LDAPConfig != nil {
+		// never expose the password of service account
+		config := *idp.LDAPConfig
+		config.BindPassword = ""
+		ldapConfig = &config
+	}
+	kind := idp.Kind
+	if kind == "" {
+		kind = models.KindOIDC
+	}
 	return &IdentityProvider{
 		ID:                      idp.ID,
 		DisplayName:             idp.DisplayName,
@@ -65,7 +80,9 @@ func ofIDPModel(idp *models.IdentityProvider) *IdentityProvider {
 		TokenEndpointAuthMethod: method,
 		Jwks:                    idp.Jwks,
 		ClientID:                idp.ClientID,
-		ClientSecret:            idp.ClientSecret,
+		ClientSecret:            string(idp.ClientSecret),
+		Kind:                    kind,
+		LDAPConfig:              ldapConfig,
 		CreatedAt:               idp.CreatedAt,
 		UpdatedAt:               idp.UpdatedAt,
 	}
@@ -84,23 +101,7 @@ type CreateIDPRequest struct {
 }
 
 func (r *CreateIDPRequest) toModel() *models.IdentityProvider {
-	idp := &models.IdentityProvider{
-		DisplayName:             r.DisplayName,
-		Name:                    r.Name,
-		Avatar:                  r.Avatar,
-		AuthorizationEndpoint:   r.AuthorizationEndpoint,
-		TokenEndpoint:           r.TokenEndpoint,
-		UserinfoEndpoint:        r.UserinfoEndpoint,
-		RevocationEndpoint:      r.RevocationEndpoint,
-		Issuer:                  r.Issuer,
-		Scopes:                  r.Scopes,
-		SigningAlgs:             r.SigningAlgs,
-		TokenEndpointAuthMethod: &r.TokenEndpointAuthMethod,
-		Jwks:                    r.Jwks,
-		ClientID:                r.ClientID,
-		ClientSecret:            r.ClientSecret,
-	}
-	return idp
+	return r.UpdateIDPRequest.toModel()
 }
 
 type UpdateIDPRequest struct {
@@ -118,9 +119,15 @@ type UpdateIDPRequest struct {
 	Jwks                    string                         `json:"jwks,omitempty"`
 	ClientID                string                         `json:"clientID"`
 	ClientSecret            string                         `json:"clientSecret"`
+	Kind                    models.Kind                    `json:"kind,omitempty"`
+	LDAPConfig              *models.LDAPConfig             `json:"ldapConfig,omitempty"`
 }
 
 func (r *UpdateIDPRequest) toModel() *models.IdentityProvider {
+	var method *models.TokenEndpointAuthMethod
+	if r.TokenEndpointAuthMethod != 0 {
+		method = &r.TokenEndpointAuthMethod
+	}
 	idp := &models.IdentityProvider{
 		DisplayName:             r.DisplayName,
 		Name:                    r.Name,
@@ -132,14 +139,22 @@ func (r *UpdateIDPRequest) toModel() *models.IdentityProvider {
 		Issuer:                  r.Issuer,
 		Scopes:                  r.Scopes,
 		SigningAlgs:             r.SigningAlgs,
-		TokenEndpointAuthMethod: &r.TokenEndpointAuthMethod,
+		TokenEndpointAuthMethod: method,
 		Jwks:                    r.Jwks,
 		ClientID:                r.ClientID,
-		ClientSecret:            r.ClientSecret,
+		ClientSecret:            encrypt.String(r.ClientSecret),
+		Kind:                    r.Kind,
+		LDAPConfig:              r.LDAPConfig,
 	}
 	return idp
 }
 
+type LDAPLoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	OTP      string `json:"otp,omitempty"`
+}
+
 type Discovery struct {
 	FromURL string `json:"fromUrl"`
 }
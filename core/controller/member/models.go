@@ -27,6 +27,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/member/models"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/param"
+	teammanager "github.com/horizoncd/horizon/pkg/team/manager"
 	tmanager "github.com/horizoncd/horizon/pkg/template/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
@@ -45,10 +46,10 @@ type PostMember struct {
 	// ResourceID group id;application id ...
 	ResourceID uint `json:"resourceID"`
 
-	// MemberType user or group
+	// MemberType user or team
 	MemberType models.MemberType `json:"memberType"`
 
-	// MemberNameID group id / userid
+	// MemberNameID team id / userid
 	MemberNameID uint `json:"memberNameID"`
 
 	// Role owner/maintainer/develop/...
@@ -65,12 +66,12 @@ type Member struct {
 	ResourcePath string              `json:"resourcePath,omitempty"`
 	ResourceID   uint                `json:"resourceID"`
 
-	// MemberType user or group
+	// MemberType user or team
 	MemberType models.MemberType `json:"memberType"`
 
-	// MemberName username or groupName
+	// MemberName username or team name
 	MemberName string `json:"memberName"`
-	// MemberNameID userID or groupID
+	// MemberNameID userID or teamID
 	MemberNameID uint `json:"memberNameID"`
 
 	// Role the role name that bind
@@ -106,6 +107,7 @@ type converter struct {
 	clusterSvc     clusterservice.Service
 	templateMgr    tmanager.Manager
 	releaseMgr     trmanager.Manager
+	teamMgr        teammanager.Manager
 }
 
 func New(param *param.Param) ConvertMemberHelp {
@@ -117,6 +119,7 @@ func New(param *param.Param) ConvertMemberHelp {
 		clusterSvc:     param.ClusterSvc,
 		templateMgr:    param.TemplateMgr,
 		releaseMgr:     param.TemplateReleaseMgr,
+		teamMgr:        param.TeamMgr,
 	}
 }
 
@@ -135,8 +138,11 @@ func (c *converter) ConvertMember(ctx context.Context, member *models.Member) (_
 		}
 		memberInfo = user.Name
 	} else {
-		// TODO(tom) covert groupID to GroupName
-		return nil, errors.New("group member not support yet")
+		team, err := c.teamMgr.GetByID(ctx, member.MemberNameID)
+		if err != nil {
+			return nil, err
+		}
+		memberInfo = team.Name
 	}
 
 	return &Member{
@@ -152,11 +158,13 @@ func (c *converter) ConvertMember(ctx context.Context, member *models.Member) (_
 	}, nil
 }
 func (c *converter) ConvertMembers(ctx context.Context, members []models.Member) ([]Member, error) {
-	var userIDs []uint
+	var userIDs, teamIDs []uint
 
 	for _, member := range members {
-		if member.MemberType != models.MemberUser {
-			return nil, errors.New("Only Support User MemberType yet")
+		if member.MemberType == models.MemberGroup {
+			teamIDs = append(teamIDs, member.MemberNameID)
+			userIDs = append(userIDs, member.GrantedBy)
+			continue
 		}
 		userIDs = append(userIDs, member.MemberNameID, member.GrantedBy)
 	}
@@ -168,6 +176,16 @@ func (c *converter) ConvertMembers(ctx context.Context, members []models.Member)
 	for _, userItem := range users {
 		userIDToName[userItem.ID] = userItem.Name
 	}
+	teamIDToName := make(map[uint]string)
+	if len(teamIDs) > 0 {
+		teams, err := c.teamMgr.ListByIDs(ctx, teamIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, team := range teams {
+			teamIDToName[team.ID] = team.Name
+		}
+	}
 	var retMembers []Member
 	for _, member := range members {
 		var resourceName, resourcePath string
@@ -216,10 +234,14 @@ func (c *converter) ConvertMembers(ctx context.Context, members []models.Member)
 		default:
 			return nil, fmt.Errorf("%s is not support now", member.ResourceType)
 		}
+		memberName := userIDToName[member.MemberNameID]
+		if member.MemberType == models.MemberGroup {
+			memberName = teamIDToName[member.MemberNameID]
+		}
 		retMembers = append(retMembers, Member{
 			ID:           member.ID,
 			MemberType:   member.MemberType,
-			MemberName:   userIDToName[member.MemberNameID],
+			MemberName:   memberName,
 			MemberNameID: member.MemberNameID,
 			ResourceType: member.ResourceType,
 			ResourceID:   member.ResourceID,
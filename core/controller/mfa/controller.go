@@ -0,0 +1,259 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mfa
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/config"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/mfa"
+	mfamanager "github.com/horizoncd/horizon/pkg/mfa/manager"
+	"github.com/horizoncd/horizon/pkg/mfa/models"
+	mfaservice "github.com/horizoncd/horizon/pkg/mfa/service"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const _defaultIssuer = "Horizon"
+
+type Controller interface {
+	// GetStatus returns the mfa status of current user
+	GetStatus(ctx context.Context) (*Status, error)
+	// Enroll generates a new secret for current user, it takes effect after Activate
+	Enroll(ctx context.Context) (*Enrollment, error)
+	// Activate enables mfa with the first valid code, and returns the recovery codes
+	Activate(ctx context.Context, code string) (*RecoveryCodes, error)
+	// Disable disables mfa of current user, a valid code is required
+	Disable(ctx context.Context, code string) error
+	// RegenerateRecoveryCodes invalidates the old recovery codes and returns new ones
+	RegenerateRecoveryCodes(ctx context.Context, code string) (*RecoveryCodes, error)
+	// Verify checks the code of current user
+	Verify(ctx context.Context, code string) error
+	// Reset removes mfa of the user, used by admins when a user lost the device
+	Reset(ctx context.Context, userID uint) error
+}
+
+type controller struct {
+	issuer string
+	mfaMgr mfamanager.Manager
+	mfaSvc mfaservice.Service
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(config *config.Config, param *param.Param) Controller {
+	issuer := config.MFAConfig.Issuer
+	if issuer == "" {
+		issuer = _defaultIssuer
+	}
+	return &controller{
+		issuer: issuer,
+		mfaMgr: param.UserMFAMgr,
+		mfaSvc: param.MFASvc,
+	}
+}
+
+func (c *controller) getUserMFA(ctx context.Context, userID uint) (*models.UserMFA, error) {
+	userMFA, err := c.mfaMgr.GetByUserID(ctx, userID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return userMFA, nil
+}
+
+func (c *controller) GetStatus(ctx context.Context) (*Status, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userMFA, err := c.getUserMFA(ctx, currentUser.GetID())
+	if err != nil {
+		return nil, err
+	}
+	if userMFA == nil || !userMFA.Enabled {
+		return &Status{}, nil
+	}
+	return &Status{
+		Enabled:           true,
+		RecoveryCodesLeft: len(userMFA.RecoveryCodeHashes()),
+	}, nil
+}
+
+func (c *controller) Enroll(ctx context.Context) (*Enrollment, error) {
+	const op = "mfa controller: enroll"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userMFA, err := c.getUserMFA(ctx, currentUser.GetID())
+	if err != nil {
+		return nil, err
+	}
+	if userMFA != nil && userMFA.Enabled {
+		return nil, perror.Wrap(herrors.ErrMFAAlreadyExist, "disable it before enrolling again")
+	}
+
+	secret, err := mfa.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if userMFA == nil {
+		_, err = c.mfaMgr.Create(ctx, &models.UserMFA{
+			UserID:    currentUser.GetID(),
+			Secret:    secret,
+			CreatedBy: currentUser.GetID(),
+			UpdatedBy: currentUser.GetID(),
+		})
+	} else {
+		userMFA.Secret = secret
+		userMFA.UpdatedBy = currentUser.GetID()
+		_, err = c.mfaMgr.Update(ctx, userMFA)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	account := currentUser.GetEmail()
+	if account == "" {
+		account = currentUser.GetName()
+	}
+	return &Enrollment{
+		Secret: secret,
+		URI:    mfa.KeyURI(c.issuer, account, secret),
+	}, nil
+}
+
+func (c *controller) Activate(ctx context.Context, code string) (*RecoveryCodes, error) {
+	const op = "mfa controller: activate"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userMFA, err := c.getUserMFA(ctx, currentUser.GetID())
+	if err != nil {
+		return nil, err
+	}
+	if userMFA == nil {
+		return nil, perror.Wrap(herrors.ErrMFANotEnrolled, "enroll before activating")
+	}
+	if userMFA.Enabled {
+		return nil, perror.Wrap(herrors.ErrMFAAlreadyExist, "mfa has been activated")
+	}
+	step, ok := mfa.MatchStep(userMFA.Secret, code, time.Now())
+	if !ok {
+		return nil, perror.Wrap(herrors.ErrMFACodeInvalid, "totp code is invalid")
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	userMFA.Enabled = true
+	userMFA.RecoveryCodes = hashes
+	userMFA.UpdatedBy = currentUser.GetID()
+	if _, err := c.mfaMgr.Update(ctx, userMFA); err != nil {
+		return nil, err
+	}
+	// the code used for activation can't be used for verification again
+	if _, err := c.mfaMgr.UseStep(ctx, userMFA.ID, step); err != nil {
+		return nil, err
+	}
+	return &RecoveryCodes{Codes: codes}, nil
+}
+
+func (c *controller) Disable(ctx context.Context, code string) error {
+	const op = "mfa controller: disable"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := c.mfaSvc.Verify(ctx, currentUser.GetID(), code); err != nil {
+		return err
+	}
+	return c.mfaMgr.DeleteByUserID(ctx, currentUser.GetID())
+}
+
+func (c *controller) RegenerateRecoveryCodes(ctx context.Context, code string) (*RecoveryCodes, error) {
+	const op = "mfa controller: regenerate recovery codes"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.mfaSvc.Verify(ctx, currentUser.GetID(), code); err != nil {
+		return nil, err
+	}
+	userMFA, err := c.mfaMgr.GetByUserID(ctx, currentUser.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	userMFA.RecoveryCodes = hashes
+	userMFA.UpdatedBy = currentUser.GetID()
+	if _, err := c.mfaMgr.Update(ctx, userMFA); err != nil {
+		return nil, err
+	}
+	return &RecoveryCodes{Codes: codes}, nil
+}
+
+func (c *controller) Verify(ctx context.Context, code string) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	return c.mfaSvc.Verify(ctx, currentUser.GetID(), code)
+}
+
+func (c *controller) Reset(ctx context.Context, userID uint) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !currentUser.IsAdmin() {
+		return perror.Wrap(herrors.ErrForbidden, "only admin can reset mfa of other users")
+	}
+	return c.mfaMgr.DeleteByUserID(ctx, userID)
+}
+
+func generateRecoveryCodes() ([]string, string, error) {
+	codes, err := mfa.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, "", err
+	}
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, mfa.HashRecoveryCode(code))
+	}
+	return codes, strings.Join(hashes, ","), nil
+}
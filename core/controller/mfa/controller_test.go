@@ -0,0 +1,135 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mfa
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/config"
+	usercontroller "github.com/horizoncd/horizon/core/controller/user"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/mfa"
+	mfamodels "github.com/horizoncd/horizon/pkg/mfa/models"
+	mfaservice "github.com/horizoncd/horizon/pkg/mfa/service"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+// nolint
+func Test(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&usermodels.User{}, &mfamodels.UserMFA{}); err != nil {
+		panic(err)
+	}
+	mgr := managerparam.InitManager(db)
+	parameter := &param.Param{
+		Manager: mgr,
+		MFASvc:  mfaservice.NewService(mgr),
+	}
+	ctl := NewController(&config.Config{}, parameter)
+	userCtl := usercontroller.NewController(parameter)
+
+	user, err := mgr.UserMgr.Create(context.Background(), &usermodels.User{
+		Name:     "tom",
+		Email:    "tom@horizoncd.io",
+		Password: "passwd",
+	})
+	assert.Nil(t, err)
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name:  user.Name,
+		Email: user.Email,
+		ID:    user.ID,
+	})
+
+	status, err := ctl.GetStatus(ctx)
+	assert.Nil(t, err)
+	assert.False(t, status.Enabled)
+
+	// not activated, login without otp
+	enrollment, err := ctl.Enroll(ctx)
+	assert.Nil(t, err)
+	assert.Contains(t, enrollment.URI, enrollment.Secret)
+	loginUser, verified, err := userCtl.LoginWithPasswd(ctx, &usercontroller.LoginRequest{
+		Email: user.Email, Password: "passwd"})
+	assert.Nil(t, err)
+	assert.False(t, verified)
+	assert.Equal(t, user.ID, loginUser.ID)
+
+	_, err = ctl.Activate(ctx, "000000x")
+	assert.Equal(t, herrors.ErrMFACodeInvalid, perror.Cause(err))
+
+	code, err := mfa.GenerateCode(enrollment.Secret, time.Now())
+	assert.Nil(t, err)
+	recoveryCodes, err := ctl.Activate(ctx, code)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, len(recoveryCodes.Codes))
+
+	_, err = ctl.Enroll(ctx)
+	assert.Equal(t, herrors.ErrMFAAlreadyExist, perror.Cause(err))
+
+	// otp is required after activated
+	_, _, err = userCtl.LoginWithPasswd(ctx, &usercontroller.LoginRequest{
+		Email: user.Email, Password: "passwd"})
+	assert.Equal(t, herrors.ErrMFARequired, perror.Cause(err))
+	// the code used for activation can't be used again
+	_, _, err = userCtl.LoginWithPasswd(ctx, &usercontroller.LoginRequest{
+		Email: user.Email, Password: "passwd", OTP: code})
+	assert.Equal(t, herrors.ErrMFACodeInvalid, perror.Cause(err))
+	code, err = mfa.GenerateCode(enrollment.Secret, time.Now().Add(mfa.Period))
+	assert.Nil(t, err)
+	_, verified, err = userCtl.LoginWithPasswd(ctx, &usercontroller.LoginRequest{
+		Email: user.Email, Password: "passwd", OTP: code})
+	assert.Nil(t, err)
+	assert.True(t, verified)
+	assert.Equal(t, herrors.ErrMFACodeInvalid, perror.Cause(ctl.Verify(ctx, code)))
+
+	// recovery code can be used only once
+	assert.Nil(t, ctl.Verify(ctx, recoveryCodes.Codes[0]))
+	assert.Equal(t, herrors.ErrMFACodeInvalid, perror.Cause(ctl.Verify(ctx, recoveryCodes.Codes[0])))
+	status, err = ctl.GetStatus(ctx)
+	assert.Nil(t, err)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, 9, status.RecoveryCodesLeft)
+
+	// only admin can reset
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(ctl.Reset(ctx, user.ID)))
+
+	// verification is locked after too many failed attempts, even with a valid code,
+	// one failure has been recorded by reusing the recovery code above
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, herrors.ErrMFACodeInvalid, perror.Cause(ctl.Verify(ctx, "abcdef")))
+	}
+	assert.Nil(t, ctl.Verify(ctx, recoveryCodes.Codes[1]))
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, herrors.ErrMFACodeInvalid, perror.Cause(ctl.Verify(ctx, "abcdef")))
+	}
+	assert.Equal(t, herrors.ErrMFACodeInvalid, perror.Cause(ctl.Verify(ctx, recoveryCodes.Codes[2])))
+	assert.Nil(t, db.Model(&mfamodels.UserMFA{}).Where("user_id = ?", user.ID).
+		Update("locked_until", time.Now().Add(-time.Second)).Error)
+
+	assert.Nil(t, ctl.Disable(ctx, recoveryCodes.Codes[2]))
+	status, err = ctl.GetStatus(ctx)
+	assert.Nil(t, err)
+	assert.False(t, status.Enabled)
+}
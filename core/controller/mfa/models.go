@@ -0,0 +1,35 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mfa
+
+type Status struct {
+	Enabled           bool `json:"enabled"`
+	RecoveryCodesLeft int  `json:"recoveryCodesLeft"`
+}
+
+type Enrollment struct {
+	Secret string `json:"secret"`
+	// URI is the otpauth uri, which can be rendered as qrcode for authenticator apps
+	URI string `json:"uri"`
+}
+
+type CodeRequest struct {
+	Code string `json:"code"`
+}
+
+type RecoveryCodes struct {
+	// Codes are shown only once, each of them can replace a totp code one time
+	Codes []string `json:"codes"`
+}
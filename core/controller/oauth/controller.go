@@ -15,15 +15,21 @@
 package oauth
 
 import (
+	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/horizoncd/horizon/core/controller/accesstoken"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	"github.com/horizoncd/horizon/pkg/oauth/manager"
 	oauthmodel "github.com/horizoncd/horizon/pkg/oauth/models"
 	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
 	"github.com/horizoncd/horizon/pkg/token/generator"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 	"golang.org/x/net/context"
 )
@@ -35,6 +41,10 @@ type AuthorizeReq struct {
 	State        string
 	UserIdentity uint
 
+	// PKCE challenge, ref: rfc7636
+	CodeChallenge       string
+	CodeChallengeMethod string
+
 	Request *http.Request
 }
 
@@ -54,7 +64,13 @@ type BaseTokenReq struct {
 
 type AccessTokenReq struct {
 	BaseTokenReq
-	Code string
+	Code         string
+	CodeVerifier string
+}
+
+type ClientCredentialsReq struct {
+	BaseTokenReq
+	Scope string
 }
 
 type RefreshTokenReq struct {
@@ -64,7 +80,7 @@ type RefreshTokenReq struct {
 
 type AccessTokenResponse struct {
 	AccessToken  string        `json:"access_token"`
-	RefreshToken string        `json:"refresh_token"`
+	RefreshToken string        `json:"refresh_token,omitempty"`
 	ExpiresIn    time.Duration `json:"expires_in"`
 	Scope        string        `json:"scope"`
 	TokenType    string        `json:"token_type"`
@@ -76,16 +92,28 @@ type Controller interface {
 	// GenAccessToken Access Token GenOauthTokensRequest,ref:rfc6750
 	GenAccessToken(ctx context.Context, req *AccessTokenReq) (*AccessTokenResponse, error)
 	RefreshToken(ctx context.Context, req *RefreshTokenReq) (*AccessTokenResponse, error)
+	// ClientCredentials issues access token to horizon apps for server-to-server calls, ref: rfc6749 4.4
+	ClientCredentials(ctx context.Context, req *ClientCredentialsReq) (*AccessTokenResponse, error)
+
+	// device authorization grant, ref: rfc8628
+	GenDeviceCode(ctx context.Context, req *DeviceCodeReq) (*DeviceCodeResponse, error)
+	GetDeviceAuthorization(ctx context.Context, userCode string) (*DeviceAuthorization, error)
+	AuthorizeDevice(ctx context.Context, userCode string, approved bool) error
+	DeviceAccessToken(ctx context.Context, req *DeviceAccessTokenReq) (*AccessTokenResponse, error)
 }
 
 func NewController(param *param.Param) Controller {
-	return &controller{oauthManager: param.OauthManager}
+	return &controller{
+		oauthManager: param.OauthManager,
+		userMgr:      param.UserMgr,
+	}
 }
 
 var _ Controller = &controller{}
 
 type controller struct {
 	oauthManager manager.Manager
+	userMgr      usermanager.Manager
 }
 
 func (c *controller) GenAuthorizeCode(ctx context.Context, req *AuthorizeReq) (*AuthorizeCodeResponse, error) {
@@ -101,6 +129,9 @@ func (c *controller) GenAuthorizeCode(ctx context.Context, req *AuthorizeReq) (*
 		Scope:        req.Scope,
 		UserIdentify: req.UserIdentity,
 		Request:      req.Request,
+
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
 	})
 	if err != nil {
 		return nil, err
@@ -143,6 +174,7 @@ func (c *controller) GenAccessToken(ctx context.Context, req *AccessTokenReq) (*
 		ClientID:              req.ClientID,
 		ClientSecret:          req.ClientSecret,
 		Code:                  req.Code,
+		CodeVerifier:          req.CodeVerifier,
 		RedirectURL:           req.RedirectURL,
 		Request:               req.Request,
 		AccessTokenGenerator:  accessTokenGenerator,
@@ -188,3 +220,75 @@ func (c *controller) RefreshToken(ctx context.Context,
 		TokenType:    "bearer",
 	}, nil
 }
+
+func (c *controller) ClientCredentials(ctx context.Context,
+	req *ClientCredentialsReq) (*AccessTokenResponse, error) {
+	const op = "oauth controller: ClientCredentials"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	app, err := c.oauthManager.GetOAuthApp(ctx, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	if app.AppType != oauthmodel.HorizonOAuthAPP {
+		return nil, perror.Wrapf(herrors.ErrOAuthReqNotValid,
+			"client credentials grant is only supported by horizon apps, appType = %d", app.AppType)
+	}
+	robot, member, err := c.getAppRobot(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+
+	// the client secret is checked before the robot is created with the token
+	tokens, err := c.oauthManager.GenClientCredentialsTokens(ctx, &manager.OauthTokensRequest{
+		ClientID:             req.ClientID,
+		ClientSecret:         req.ClientSecret,
+		Scope:                req.Scope,
+		Robot:                robot,
+		RobotMember:          member,
+		Request:              req.Request,
+		AccessTokenGenerator: generator.NewHorizonAppServerToServerAccessGenerator(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &AccessTokenResponse{
+		AccessToken: tokens.AccessToken.Code,
+		ExpiresIn:   tokens.AccessToken.ExpiresIn,
+		Scope:       tokens.AccessToken.Scope,
+		TokenType:   "bearer",
+	}, nil
+}
+
+// getAppRobot gets the robot user acting as the horizon app in server-to-server calls.
+// At the first time, an unsaved robot is returned with its membership as a guest of the group owning the app,
+// which are created along with the token, owners of the group can grant it a higher role if needed.
+func (c *controller) getAppRobot(ctx context.Context,
+	app *oauthmodel.OauthApp) (*usermodels.User, *membermodels.Member, error) {
+	email := fmt.Sprintf("oauthapps_%s_robot%s", app.ClientID, accesstoken.RobotEmailSuffix)
+	users, err := c.userMgr.ListByEmail(ctx, []string{email})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(users) > 0 {
+		return users[0], nil, nil
+	}
+
+	robot := &usermodels.User{
+		Name:     app.Name,
+		FullName: fmt.Sprintf("oauthapps_%s_robot", app.ClientID),
+		Email:    email,
+		UserType: usermodels.UserTypeRobot,
+	}
+	if !app.IsGroupOwnerType() {
+		return robot, nil, nil
+	}
+	return robot, &membermodels.Member{
+		ResourceType: membermodels.TypeGroup,
+		ResourceID:   app.OwnerID,
+		Role:         role.Guest,
+		MemberType:   membermodels.MemberUser,
+		GrantedBy:    app.CreatedBy,
+		CreatedBy:    app.CreatedBy,
+	}, nil
+}
@@ -0,0 +1,131 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/pkg/oauth/manager"
+	"github.com/horizoncd/horizon/pkg/token/generator"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"golang.org/x/net/context"
+)
+
+type DeviceCodeReq struct {
+	ClientID string
+	Scope    string
+
+	Request *http.Request
+}
+
+type DeviceCodeResponse struct {
+	DeviceCode string
+	UserCode   string
+	ExpiresIn  time.Duration
+	Interval   time.Duration
+}
+
+type DeviceAuthorization struct {
+	ClientID   string    `json:"clientID"`
+	ClientName string    `json:"clientName"`
+	HomeURL    string    `json:"homeURL"`
+	Scope      string    `json:"scope"`
+	UserCode   string    `json:"userCode"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+type DeviceAccessTokenReq struct {
+	BaseTokenReq
+	DeviceCode string
+}
+
+func (c *controller) GenDeviceCode(ctx context.Context, req *DeviceCodeReq) (*DeviceCodeResponse, error) {
+	const op = "oauth controller: GenDeviceCode"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	token, err := c.oauthManager.GenDeviceCode(ctx, &manager.DeviceAuthorizationRequest{
+		ClientID: req.ClientID,
+		Scope:    req.Scope,
+		Request:  req.Request,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &DeviceCodeResponse{
+		DeviceCode: token.Code,
+		UserCode:   token.State,
+		ExpiresIn:  token.ExpiresIn,
+		Interval:   manager.DevicePollInterval,
+	}, nil
+}
+
+func (c *controller) GetDeviceAuthorization(ctx context.Context, userCode string) (*DeviceAuthorization, error) {
+	token, err := c.oauthManager.GetDeviceCodeByUserCode(ctx, userCode)
+	if err != nil {
+		return nil, err
+	}
+	app, err := c.oauthManager.GetOAuthApp(ctx, token.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	return &DeviceAuthorization{
+		ClientID:   app.ClientID,
+		ClientName: app.Name,
+		HomeURL:    app.HomeURL,
+		Scope:      token.Scope,
+		UserCode:   token.State,
+		ExpiresAt:  token.CreatedAt.Add(token.ExpiresIn),
+	}, nil
+}
+
+func (c *controller) AuthorizeDevice(ctx context.Context, userCode string, approved bool) error {
+	const op = "oauth controller: AuthorizeDevice"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	user, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	return c.oauthManager.AuthorizeDevice(ctx, userCode, user.GetID(), approved)
+}
+
+func (c *controller) DeviceAccessToken(ctx context.Context,
+	req *DeviceAccessTokenReq) (*AccessTokenResponse, error) {
+	accessTokenGenerator, err := c.getAccessTokenGenerator(ctx, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := c.oauthManager.GenDeviceTokens(ctx, &manager.OauthTokensRequest{
+		ClientID:              req.ClientID,
+		ClientSecret:          req.ClientSecret,
+		DeviceCode:            req.DeviceCode,
+		Request:               req.Request,
+		AccessTokenGenerator:  accessTokenGenerator,
+		RefreshTokenGenerator: generator.NewRefreshTokenGenerator(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &AccessTokenResponse{
+		AccessToken:  tokens.AccessToken.Code,
+		RefreshToken: tokens.RefreshToken.Code,
+		ExpiresIn:    tokens.AccessToken.ExpiresIn,
+		Scope:        tokens.AccessToken.Scope,
+		TokenType:    "bearer",
+	}, nil
+}
@@ -31,6 +31,8 @@ type CreateOauthAPPRequest struct {
 	Desc        string `json:"desc"`
 	HomeURL     string `json:"homeURL"`
 	RedirectURL string `json:"redirectURL"`
+	// Public clients, such as native apps, are allowed to omit the secret if they use PKCE
+	Public bool `json:"public"`
 }
 
 type APPBasicInfo struct {
@@ -42,6 +44,7 @@ type APPBasicInfo struct {
 	RedirectURL string    `json:"redirectURL"`
 	UpdatedBy   uint      `json:"updatedBy"`
 	UpdatedAt   time.Time `json:"updatedAt"`
+	Public      bool      `json:"public"`
 }
 
 type Controller interface {
@@ -145,6 +148,7 @@ func (c *controller) Create(ctx context.Context, groupID uint, request CreateOau
 		OwnerType:   models.GroupOwnerType,
 		OwnerID:     groupID,
 		APPType:     models.DirectOAuthAPP,
+		Public:      request.Public,
 	})
 	if err != nil {
 		return nil, err
@@ -158,6 +162,7 @@ func (c *controller) Create(ctx context.Context, groupID uint, request CreateOau
 		RedirectURL: oauthApp.RedirectURL,
 		UpdatedBy:   oauthApp.UpdatedBy,
 		UpdatedAt:   oauthApp.UpdatedAt,
+		Public:      oauthApp.Public,
 	}
 	return resp, err
 }
@@ -179,6 +184,7 @@ func (c *controller) Get(ctx context.Context, clientID string) (*APPBasicInfo, e
 		RedirectURL: oauthApp.RedirectURL,
 		UpdatedBy:   oauthApp.UpdatedBy,
 		UpdatedAt:   oauthApp.UpdatedAt,
+		Public:      oauthApp.Public,
 	}
 	return resp, err
 }
@@ -202,6 +208,7 @@ func (c *controller) List(ctx context.Context, groupID uint) ([]APPBasicInfo, er
 			RedirectURL: app.RedirectURL,
 			UpdatedBy:   app.UpdatedBy,
 			UpdatedAt:   app.UpdatedAt,
+			Public:      app.Public,
 		})
 	}
 	return appInfos, nil
@@ -229,6 +236,7 @@ func (c *controller) Update(ctx context.Context, info APPBasicInfo) (*APPBasicIn
 		RedirectURL: app.RedirectURL,
 		UpdatedBy:   app.UpdatedBy,
 		UpdatedAt:   app.UpdatedAt,
+		Public:      app.Public,
 	}, nil
 }
 
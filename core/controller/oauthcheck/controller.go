@@ -30,15 +30,25 @@ import (
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/rbac/types"
 	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	"github.com/horizoncd/horizon/pkg/util/log"
 )
 
 type Controller interface {
-	ValidateToken(ctx context.Context, token string) error
+	// ValidateToken loads the token and checks whether it is expired, the loaded token is returned for reuse
+	ValidateToken(ctx context.Context, token string) (*tokenmodels.Token, error)
 	LoadAccessTokenUser(ctx context.Context, token string) (user.User, error)
 	CheckScopePermission(ctx context.Context, token string, authInfo auth.RequestInfo) (bool, string, error)
+	// CheckTokenSource checks the source ip against the allowed networks of the token,
+	// and records ip as the last usage of the token
+	CheckTokenSource(ctx context.Context, token *tokenmodels.Token, ip string) error
 }
 
+// lastUsedUpdateInterval limits how often last usage of a token is written to db
+const lastUsedUpdateInterval = time.Minute
+
 type controller struct {
 	tokenManager tokenmanager.Manager
 	userManager  usermanager.Manager
@@ -55,10 +65,10 @@ func NewOauthChecker(param *param.Param) Controller {
 	}
 }
 
-func (c *controller) ValidateToken(ctx context.Context, accessToken string) error {
+func (c *controller) ValidateToken(ctx context.Context, accessToken string) (*tokenmodels.Token, error) {
 	token, err := c.tokenManager.LoadTokenByCode(ctx, accessToken)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	isExpired := func() bool {
@@ -69,13 +79,30 @@ func (c *controller) ValidateToken(ctx context.Context, accessToken string) erro
 	}
 
 	if neverExpires() {
-		return nil
+		return token, nil
 	}
 
 	if isExpired() {
-		return perror.Wrap(herrors.ErrOAuthAccessTokenExpired, "")
+		return nil, perror.Wrap(herrors.ErrOAuthAccessTokenExpired, "")
+	}
+
+	return token, nil
+}
+
+func (c *controller) CheckTokenSource(ctx context.Context, token *tokenmodels.Token, ip string) error {
+	if !tokenservice.SourceAllowed(token.AllowedCIDRs, ip) {
+		return perror.Wrapf(herrors.ErrTokenSourceNotAllowed, "token %d is used from %s", token.ID, ip)
 	}
 
+	now := time.Now()
+	if token.LastUsedAt != nil && token.LastUsedIP == ip &&
+		now.Sub(*token.LastUsedAt) < lastUsedUpdateInterval {
+		return nil
+	}
+	// failing to record the usage should not break the request
+	if err := c.tokenManager.UpdateLastUsed(ctx, token.ID, now, ip); err != nil {
+		log.Warningf(ctx, "failed to update last usage of token %d: %v", token.ID, err)
+	}
 	return nil
 }
 
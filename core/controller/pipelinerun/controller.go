@@ -556,6 +556,7 @@ func (c *controller) executeRestart(ctx context.Context, application *appmodels.
 	}
 	// 3. deploy cluster in cd system
 	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
+		Application: application.Name,
 		Environment: cluster.EnvironmentName,
 		Cluster:     cluster.Name,
 		Revision:    commit,
@@ -646,6 +647,9 @@ func (c *controller) executeRollback(ctx context.Context, application *appmodels
 	if err != nil {
 		return perror.Wrapf(err, "failed to get env value, cluster = %s", cluster.Name)
 	}
+	if err := c.clusterGitRepo.EnsureSecretFile(ctx, application.Name, cluster.Name); err != nil {
+		return perror.Wrapf(err, "failed to ensure secret file, cluster = %s", cluster.Name)
+	}
 	repoInfo := c.clusterGitRepo.GetRepoInfo(ctx, application.Name, cluster.Name)
 	if err := c.cd.CreateCluster(ctx, &cd.CreateClusterParams{
 		Environment:  cluster.EnvironmentName,
@@ -669,6 +673,7 @@ func (c *controller) executeRollback(ctx context.Context, application *appmodels
 
 	// 8. deploy cluster in cd and update status
 	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
+		Application: application.Name,
 		Environment: cluster.EnvironmentName,
 		Cluster:     cluster.Name,
 		Revision:    masterRevision,
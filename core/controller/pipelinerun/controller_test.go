@@ -532,6 +532,7 @@ func TestExecutePipelineRun(t *testing.T) {
 			BaseRegistry:  "registry.cn-hangzhou.aliyuncs.com",
 			IngressDomain: region.IngressDomain,
 		}, nil).AnyTimes()
+	mockClusterGitRepo.EXPECT().EnsureSecretFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockClusterGitRepo.EXPECT().GetRepoInfo(gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(&clustergitrepo.RepoInfo{}).AnyTimes()
 	mockClusterGitRepo.EXPECT().DefaultBranch().Return("master").AnyTimes()
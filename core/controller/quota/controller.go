@@ -0,0 +1,213 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param"
+	quotamanager "github.com/horizoncd/horizon/pkg/quota/manager"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+	quotaservice "github.com/horizoncd/horizon/pkg/quota/service"
+)
+
+type Controller interface {
+	// ListOfGroup lists quotas of the group and its ancestors with their usage
+	ListOfGroup(ctx context.Context, groupID uint) ([]*Quota, error)
+	// ListOfEnvironment lists quotas of the environment with their usage
+	ListOfEnvironment(ctx context.Context, environmentID uint) ([]*Quota, error)
+	// Create creates a quota of the group or environment
+	Create(ctx context.Context, resourceType string, resourceID uint, request *CreateQuotaRequest) (*Quota, error)
+	// Update updates limits of a quota of the group or environment
+	Update(ctx context.Context, resourceType string, resourceID, id uint, request *UpdateQuotaRequest) (*Quota, error)
+	Delete(ctx context.Context, resourceType string, resourceID, id uint) error
+}
+
+type controller struct {
+	quotaMgr quotamanager.Manager
+	groupMgr groupmanager.Manager
+	envMgr   envmanager.Manager
+	quotaSvc quotaservice.Service
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		quotaMgr: param.QuotaMgr,
+		groupMgr: param.GroupMgr,
+		envMgr:   param.EnvMgr,
+		quotaSvc: quotaservice.NewService(param.Manager),
+	}
+}
+
+func (c *controller) ListOfGroup(ctx context.Context, groupID uint) ([]*Quota, error) {
+	quotas, err := c.quotaSvc.ListOfGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return c.withUsage(ctx, quotas, func(quota *models.Quota) bool {
+		return quota.ResourceID != groupID
+	})
+}
+
+func (c *controller) ListOfEnvironment(ctx context.Context, environmentID uint) ([]*Quota, error) {
+	if _, err := c.envMgr.GetByID(ctx, environmentID); err != nil {
+		return nil, err
+	}
+	quotas, err := c.quotaMgr.ListByResources(ctx, models.ResourceTypeEnvironment, []uint{environmentID})
+	if err != nil {
+		return nil, err
+	}
+	return c.withUsage(ctx, quotas, func(*models.Quota) bool { return false })
+}
+
+func (c *controller) withUsage(ctx context.Context, quotas []*models.Quota,
+	inherited func(*models.Quota) bool) ([]*Quota, error) {
+	ret := make([]*Quota, 0, len(quotas))
+	for _, quota := range quotas {
+		usage, err := c.quotaSvc.GetUsage(ctx, quota, 0)
+		if err != nil {
+			return nil, err
+		}
+		q := ofQuota(quota)
+		q.Inherited = inherited(quota)
+		q.Usage = ofUsage(usage)
+		ret = append(ret, q)
+	}
+	return ret, nil
+}
+
+func (c *controller) Create(ctx context.Context, resourceType string, resourceID uint,
+	request *CreateQuotaRequest) (*Quota, error) {
+	user, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateLimits(&request.Limits); err != nil {
+		return nil, err
+	}
+	switch resourceType {
+	case models.ResourceTypeGroup:
+		if _, err := c.groupMgr.GetByID(ctx, resourceID); err != nil {
+			return nil, err
+		}
+		if request.Environment != "" {
+			if _, err := c.envMgr.GetByName(ctx, request.Environment); err != nil {
+				return nil, err
+			}
+		}
+	case models.ResourceTypeEnvironment:
+		if _, err := c.envMgr.GetByID(ctx, resourceID); err != nil {
+			return nil, err
+		}
+		if request.Environment != "" {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, "environment can only be set for quotas of groups")
+		}
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "quotas of %s are not supported", resourceType)
+	}
+
+	quota, err := c.quotaMgr.Create(ctx, &models.Quota{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Environment:  request.Environment,
+		MaxClusters:  request.MaxClusters,
+		MaxCPU:       request.MaxCPU,
+		MaxMemory:    request.MaxMemory,
+		MaxReplicas:  request.MaxReplicas,
+		CreatedBy:    user.GetID(),
+		UpdatedBy:    user.GetID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ofQuota(quota), nil
+}
+
+func (c *controller) Update(ctx context.Context, resourceType string, resourceID, id uint,
+	request *UpdateQuotaRequest) (*Quota, error) {
+	user, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateLimits(&request.Limits); err != nil {
+		return nil, err
+	}
+	quota, err := c.get(ctx, resourceType, resourceID, id)
+	if err != nil {
+		return nil, err
+	}
+	quota.MaxClusters = request.MaxClusters
+	quota.MaxCPU = request.MaxCPU
+	quota.MaxMemory = request.MaxMemory
+	quota.MaxReplicas = request.MaxReplicas
+	quota.UpdatedBy = user.GetID()
+	if err := c.quotaMgr.Update(ctx, quota); err != nil {
+		return nil, err
+	}
+	quota, err = c.quotaMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ofQuota(quota), nil
+}
+
+func (c *controller) Delete(ctx context.Context, resourceType string, resourceID, id uint) error {
+	if _, err := c.get(ctx, resourceType, resourceID, id); err != nil {
+		return err
+	}
+	return c.quotaMgr.DeleteByID(ctx, id)
+}
+
+// get returns HorizonErrNotFound if the quota doesn't belong to the resource
+func (c *controller) get(ctx context.Context, resourceType string, resourceID, id uint) (*models.Quota, error) {
+	quota, err := c.quotaMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if quota.ResourceType != resourceType || quota.ResourceID != resourceID {
+		return nil, herrors.NewErrNotFound(herrors.QuotaInDB,
+			fmt.Sprintf("quota %d of %s %d was not found", id, resourceType, resourceID))
+	}
+	return quota, nil
+}
+
+func validateLimits(limits *Limits) error {
+	if limits.MaxClusters < 0 || limits.MaxReplicas < 0 {
+		return perror.Wrap(herrors.ErrParamInvalid, "max clusters and replicas cannot be negative")
+	}
+	for _, value := range []string{limits.MaxCPU, limits.MaxMemory} {
+		if value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return perror.Wrapf(herrors.ErrParamInvalid, "invalid quantity %s: %v", value, err)
+		}
+		if quantity.Sign() < 0 {
+			return perror.Wrapf(herrors.ErrParamInvalid, "quantity %s cannot be negative", value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/horizoncd/horizon/pkg/quota/models"
+)
+
+// Limits of clusters, zero or empty limits are unlimited
+type Limits struct {
+	MaxClusters int `json:"maxClusters"`
+	// MaxCPU is the max total cpu requests, such as 16 and 500m
+	MaxCPU string `json:"maxCPU"`
+	// MaxMemory is the max total memory requests, such as 32Gi
+	MaxMemory   string `json:"maxMemory"`
+	MaxReplicas int    `json:"maxReplicas"`
+}
+
+type CreateQuotaRequest struct {
+	// Environment restricts a group quota to clusters in the environment, it's empty for all environments
+	Environment string `json:"environment"`
+	Limits
+}
+
+type UpdateQuotaRequest struct {
+	Limits
+}
+
+// Usage is the resources requested by clusters restricted by a quota
+type Usage struct {
+	Clusters int    `json:"clusters"`
+	CPU      string `json:"cpu"`
+	Memory   string `json:"memory"`
+	Replicas int    `json:"replicas"`
+}
+
+type Quota struct {
+	ID           uint   `json:"id"`
+	ResourceType string `json:"resourceType"`
+	ResourceID   uint   `json:"resourceID"`
+	Environment  string `json:"environment"`
+	Limits
+	// Inherited is true if the quota is of an ancestor of the group
+	Inherited bool      `json:"inherited"`
+	Usage     *Usage    `json:"usage,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	CreatedBy uint      `json:"createdBy"`
+	UpdatedBy uint      `json:"updatedBy"`
+}
+
+func ofQuota(quota *models.Quota) *Quota {
+	return &Quota{
+		ID:           quota.ID,
+		ResourceType: quota.ResourceType,
+		ResourceID:   quota.ResourceID,
+		Environment:  quota.Environment,
+		Limits: Limits{
+			MaxClusters: quota.MaxClusters,
+			MaxCPU:      quota.MaxCPU,
+			MaxMemory:   quota.MaxMemory,
+			MaxReplicas: quota.MaxReplicas,
+		},
+		CreatedAt: quota.CreatedAt,
+		UpdatedAt: quota.UpdatedAt,
+		CreatedBy: quota.CreatedBy,
+		UpdatedBy: quota.UpdatedBy,
+	}
+}
+
+func ofUsage(usage *models.Usage) *Usage {
+	return &Usage{
+		Clusters: usage.Clusters,
+		CPU:      resource.NewMilliQuantity(usage.CPU, resource.DecimalSI).String(),
+		Memory:   resource.NewQuantity(usage.Memory, resource.BinarySI).String(),
+		Replicas: usage.Replicas,
+	}
+}
@@ -17,6 +17,9 @@ package region
 import (
 	"context"
 
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/encrypt"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/param"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	"github.com/horizoncd/horizon/pkg/region/models"
@@ -53,14 +56,19 @@ func (c controller) DeleteByID(ctx context.Context, id uint) error {
 }
 
 func (c controller) UpdateByID(ctx context.Context, id uint, request *UpdateRegionRequest) error {
+	if err := validateCDEngine(request.CDEngine); err != nil {
+		return err
+	}
 	err := c.regionMgr.UpdateByID(ctx, id, &models.Region{
-		DisplayName:   request.DisplayName,
-		Server:        request.Server,
-		Certificate:   request.Certificate,
-		IngressDomain: request.IngressDomain,
-		PrometheusURL: request.PrometheusURL,
-		RegistryID:    request.RegistryID,
-		Disabled:      request.Disabled,
+		DisplayName:              request.DisplayName,
+		Server:                   request.Server,
+		Certificate:              encrypt.String(request.Certificate),
+		IngressDomain:            request.IngressDomain,
+		PrometheusURL:            request.PrometheusURL,
+		RegistryID:               request.RegistryID,
+		Disabled:                 request.Disabled,
+		SealedSecretsCertificate: request.SealedSecretsCertificate,
+		CDEngine:                 request.CDEngine,
 	})
 	if err != nil {
 		return err
@@ -70,14 +78,19 @@ func (c controller) UpdateByID(ctx context.Context, id uint, request *UpdateRegi
 }
 
 func (c controller) Create(ctx context.Context, request *CreateRegionRequest) (uint, error) {
+	if err := validateCDEngine(request.CDEngine); err != nil {
+		return 0, err
+	}
 	create, err := c.regionMgr.Create(ctx, &models.Region{
-		Name:          request.Name,
-		DisplayName:   request.DisplayName,
-		Server:        request.Server,
-		Certificate:   request.Certificate,
-		IngressDomain: request.IngressDomain,
-		PrometheusURL: request.PrometheusURL,
-		RegistryID:    request.RegistryID,
+		Name:                     request.Name,
+		DisplayName:              request.DisplayName,
+		Server:                   request.Server,
+		Certificate:              encrypt.String(request.Certificate),
+		IngressDomain:            request.IngressDomain,
+		PrometheusURL:            request.PrometheusURL,
+		RegistryID:               request.RegistryID,
+		SealedSecretsCertificate: request.SealedSecretsCertificate,
+		CDEngine:                 request.CDEngine,
 	})
 	if err != nil {
 		return 0, err
@@ -93,3 +106,11 @@ func (c controller) ListRegions(ctx context.Context) ([]*Region, error) {
 	}
 	return ofRegionEntities(entities), nil
 }
+
+func validateCDEngine(engine string) error {
+	switch engine {
+	case "", models.CDEngineArgoCD, models.CDEngineFlux, models.CDEngineHelm:
+		return nil
+	}
+	return perror.Wrapf(herrors.ErrParamInvalid, "unsupported cd engine %s", engine)
+}
@@ -23,40 +23,46 @@ import (
 )
 
 type Region struct {
-	ID            uint              `json:"id"`
-	Name          string            `json:"name"`
-	DisplayName   string            `json:"displayName"`
-	Server        string            `json:"server"`
-	Certificate   string            `json:"certificate"`
-	IngressDomain string            `json:"ingressDomain"`
-	PrometheusURL string            `json:"prometheusURL"`
-	Disabled      bool              `json:"disabled"`
-	RegistryID    uint              `json:"registryID"`
-	Registry      registry.Registry `json:"registry"`
-	Tags          []tag.Tag         `json:"tags"`
-	CreatedAt     time.Time         `json:"createdAt"`
-	UpdatedAt     time.Time         `json:"updatedAt"`
+	ID                       uint              `json:"id"`
+	Name                     string            `json:"name"`
+	DisplayName              string            `json:"displayName"`
+	Server                   string            `json:"server"`
+	Certificate              string            `json:"certificate"`
+	IngressDomain            string            `json:"ingressDomain"`
+	PrometheusURL            string            `json:"prometheusURL"`
+	Disabled                 bool              `json:"disabled"`
+	SealedSecretsCertificate string            `json:"sealedSecretsCertificate"`
+	CDEngine                 string            `json:"cdEngine"`
+	RegistryID               uint              `json:"registryID"`
+	Registry                 registry.Registry `json:"registry"`
+	Tags                     []tag.Tag         `json:"tags"`
+	CreatedAt                time.Time         `json:"createdAt"`
+	UpdatedAt                time.Time         `json:"updatedAt"`
 }
 
 type CreateRegionRequest struct {
-	Name          string `json:"name"`
-	DisplayName   string `json:"displayName"`
-	Server        string `json:"server"`
-	Certificate   string `json:"certificate"`
-	IngressDomain string `json:"ingressDomain"`
-	PrometheusURL string `json:"prometheusURL"`
-	RegistryID    uint   `json:"registryID"`
+	Name                     string `json:"name"`
+	DisplayName              string `json:"displayName"`
+	Server                   string `json:"server"`
+	Certificate              string `json:"certificate"`
+	IngressDomain            string `json:"ingressDomain"`
+	PrometheusURL            string `json:"prometheusURL"`
+	RegistryID               uint   `json:"registryID"`
+	SealedSecretsCertificate string `json:"sealedSecretsCertificate"`
+	CDEngine                 string `json:"cdEngine"`
 }
 
 type UpdateRegionRequest struct {
-	Name          string `json:"name"`
-	DisplayName   string `json:"displayName"`
-	Server        string `json:"server"`
-	Certificate   string `json:"certificate"`
-	IngressDomain string `json:"ingressDomain"`
-	PrometheusURL string `json:"prometheusURL"`
-	RegistryID    uint   `json:"registryID"`
-	Disabled      bool   `json:"disabled"`
+	Name                     string `json:"name"`
+	DisplayName              string `json:"displayName"`
+	Server                   string `json:"server"`
+	Certificate              string `json:"certificate"`
+	IngressDomain            string `json:"ingressDomain"`
+	PrometheusURL            string `json:"prometheusURL"`
+	RegistryID               uint   `json:"registryID"`
+	Disabled                 bool   `json:"disabled"`
+	SealedSecretsCertificate string `json:"sealedSecretsCertificate"`
+	CDEngine                 string `json:"cdEngine"`
 }
 
 func ofRegionEntity(entity *models.RegionEntity) *Region {
@@ -68,18 +74,20 @@ func ofRegionEntity(entity *models.RegionEntity) *Region {
 		})
 	}
 	r := &Region{
-		ID:            entity.ID,
-		Name:          entity.Name,
-		DisplayName:   entity.DisplayName,
-		Server:        entity.Server,
-		IngressDomain: entity.IngressDomain,
-		PrometheusURL: entity.PrometheusURL,
-		Certificate:   entity.Certificate,
-		Disabled:      entity.Disabled,
-		RegistryID:    entity.RegistryID,
-		Tags:          tags,
-		CreatedAt:     entity.CreatedAt,
-		UpdatedAt:     entity.UpdatedAt,
+		ID:                       entity.ID,
+		Name:                     entity.Name,
+		DisplayName:              entity.DisplayName,
+		Server:                   entity.Server,
+		IngressDomain:            entity.IngressDomain,
+		PrometheusURL:            entity.PrometheusURL,
+		Certificate:              string(entity.Certificate),
+		Disabled:                 entity.Disabled,
+		SealedSecretsCertificate: entity.SealedSecretsCertificate,
+		CDEngine:                 entity.CDEngine,
+		RegistryID:               entity.RegistryID,
+		Tags:                     tags,
+		CreatedAt:                entity.CreatedAt,
+		UpdatedAt:                entity.UpdatedAt,
 	}
 	if entity.Registry != nil {
 		r.Registry = registry.Registry{
@@ -18,6 +18,7 @@ import (
 	"context"
 	"sync"
 
+	"github.com/horizoncd/horizon/lib/encrypt"
 	"github.com/horizoncd/horizon/pkg/cluster/registry"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/registry/manager"
@@ -51,7 +52,7 @@ func (c controller) Create(ctx context.Context, request *CreateRegistryRequest)
 	id, err := c.registryManager.Create(ctx, &models.Registry{
 		Name:                  request.Name,
 		Server:                request.Server,
-		Token:                 request.Token,
+		Token:                 encrypt.String(request.Token),
 		InsecureSkipTLSVerify: request.InsecureSkipTLSVerify,
 		Path:                  request.Path,
 		Kind:                  request.Kind,
@@ -81,7 +82,7 @@ func (c controller) UpdateByID(ctx context.Context, id uint, request *UpdateRegi
 	registry := &models.Registry{
 		Name:   request.Name,
 		Server: request.Server,
-		Token:  request.Token,
+		Token:  encrypt.String(request.Token),
 		Kind:   request.Kind,
 		Path:   request.Path,
 	}
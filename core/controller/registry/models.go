@@ -48,7 +48,7 @@ func ofRegistryModel(entity *models.Registry) *Registry {
 		ID:                    entity.ID,
 		Name:                  entity.Name,
 		Server:                entity.Server,
-		Token:                 entity.Token,
+		Token:                 string(entity.Token),
 		InsecureSkipTLSVerify: entity.InsecureSkipTLSVerify,
 		Kind:                  entity.Kind,
 		Path:                  entity.Path,
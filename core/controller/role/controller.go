@@ -17,30 +17,199 @@ package role
 import (
 	"context"
 	"net/http"
+	"strings"
 
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	customrolemanager "github.com/horizoncd/horizon/pkg/customrole/manager"
+	"github.com/horizoncd/horizon/pkg/customrole/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
-	"github.com/horizoncd/horizon/pkg/rbac/types"
 	"github.com/horizoncd/horizon/pkg/util/errors"
 )
 
 type Controller interface {
-	ListRole(ctx context.Context) ([]types.Role, error)
+	ListRole(ctx context.Context) ([]*Role, error)
+	GetRole(ctx context.Context, name string) (*Role, error)
+	// CreateRole creates a custom role, which ranks just below its base role
+	// and is not allowed to do anything beyond its base role
+	CreateRole(ctx context.Context, request *CreateRoleRequest) (*Role, error)
+	// UpdateRole updates a custom role, built-in roles are read-only
+	UpdateRole(ctx context.Context, name string, request *UpdateRoleRequest) (*Role, error)
+	// DeleteRole deletes a custom role which is not granted to any member
+	DeleteRole(ctx context.Context, name string) error
 }
 
 func NewController(param *param.Param) Controller {
-	return &controller{roleService: param.RoleService}
+	return &controller{
+		roleService:   param.RoleService,
+		customRoleSvc: param.CustomRoleSvc,
+		customRoleMgr: param.CustomRoleMgr,
+	}
 }
 
 type controller struct {
-	roleService role.Service
+	roleService   role.Service
+	customRoleSvc role.CustomService
+	customRoleMgr customrolemanager.Manager
 }
 
-func (c controller) ListRole(ctx context.Context) ([]types.Role, error) {
+func (c controller) ListRole(ctx context.Context) ([]*Role, error) {
 	const op = "role *controller: list role"
 	roles, err := c.roleService.ListRole(ctx)
 	if err != nil {
 		return nil, errors.E(op, http.StatusInternalServerError, err.Error())
 	}
-	return roles, nil
+	customRoles, err := c.customRoleMgr.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	customRoleMap := make(map[string]*models.CustomRole, len(customRoles))
+	for _, customRole := range customRoles {
+		customRoleMap[customRole.Name] = customRole
+	}
+
+	res := make([]*Role, 0, len(roles))
+	for _, r := range roles {
+		if customRole, ok := customRoleMap[r.Name]; ok && !c.customRoleSvc.IsBuiltin(r.Name) {
+			res = append(res, ofCustomRole(customRole))
+			continue
+		}
+		res = append(res, &Role{Role: r, Builtin: c.customRoleSvc.IsBuiltin(r.Name)})
+	}
+	return res, nil
+}
+
+func (c controller) GetRole(ctx context.Context, name string) (*Role, error) {
+	if c.customRoleSvc.IsBuiltin(name) {
+		r, err := c.roleService.GetRole(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		return &Role{Role: *r, Builtin: true}, nil
+	}
+	customRole, err := c.customRoleMgr.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return ofCustomRole(customRole), nil
+}
+
+// validate checks the base role and prevents the custom role from privilege escalation
+func (c controller) validate(ctx context.Context, customRole *models.CustomRole) error {
+	if len(customRole.Rules) == 0 {
+		return perror.Wrap(herrors.ErrParamInvalid, "rules of role are required")
+	}
+	if !c.customRoleSvc.IsBuiltin(customRole.BaseRole) {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"base role %s is not a built-in role", customRole.BaseRole)
+	}
+	baseRole, err := c.roleService.GetRole(ctx, customRole.BaseRole)
+	if err != nil {
+		return err
+	}
+	r := customRole.ToRole()
+	if err := role.ValidateCustomRole(baseRole, &r); err != nil {
+		return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	return nil
+}
+
+func (c controller) CreateRole(ctx context.Context, request *CreateRoleRequest) (*Role, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userID := currentUser.GetID()
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "name of role is required")
+	}
+	if c.customRoleSvc.IsBuiltin(name) {
+		return nil, perror.Wrapf(herrors.ErrNameConflict, "role %s is a built-in role", name)
+	}
+	if _, err := c.customRoleMgr.GetByName(ctx, name); err == nil {
+		return nil, perror.Wrapf(herrors.ErrNameConflict, "role %s already exists", name)
+	} else if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+		return nil, err
+	}
+
+	customRole := &models.CustomRole{
+		Name:        name,
+		Description: request.Desc,
+		BaseRole:    request.BaseRole,
+		Rules:       request.Rules,
+		CreatedBy:   userID,
+		UpdatedBy:   userID,
+	}
+	if err := c.validate(ctx, customRole); err != nil {
+		return nil, err
+	}
+	customRole, err = c.customRoleMgr.Create(ctx, customRole)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.customRoleSvc.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return ofCustomRole(customRole), nil
+}
+
+func (c controller) UpdateRole(ctx context.Context, name string, request *UpdateRoleRequest) (*Role, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userID := currentUser.GetID()
+	if c.customRoleSvc.IsBuiltin(name) {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "built-in role %s is read-only", name)
+	}
+	customRole, err := c.customRoleMgr.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Desc != nil {
+		customRole.Description = *request.Desc
+	}
+	if request.BaseRole != nil {
+		customRole.BaseRole = *request.BaseRole
+	}
+	if request.Rules != nil {
+		customRole.Rules = request.Rules
+	}
+	customRole.UpdatedBy = userID
+	if err := c.validate(ctx, customRole); err != nil {
+		return nil, err
+	}
+	customRole, err = c.customRoleMgr.Update(ctx, customRole)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.customRoleSvc.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return ofCustomRole(customRole), nil
+}
+
+func (c controller) DeleteRole(ctx context.Context, name string) error {
+	if c.customRoleSvc.IsBuiltin(name) {
+		return perror.Wrapf(herrors.ErrParamInvalid, "built-in role %s is read-only", name)
+	}
+	if _, err := c.customRoleMgr.GetByName(ctx, name); err != nil {
+		return err
+	}
+	count, err := c.customRoleMgr.CountMembers(ctx, name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"role %s is granted to %d members, which should be changed before deleting", name, count)
+	}
+	if err := c.customRoleMgr.DeleteByName(ctx, name); err != nil {
+		return err
+	}
+	return c.customRoleSvc.Reload(ctx)
 }
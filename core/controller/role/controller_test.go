@@ -0,0 +1,189 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	roleconfig "github.com/horizoncd/horizon/pkg/config/role"
+	customrolemodels "github.com/horizoncd/horizon/pkg/customrole/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+const roles = `
+RolePriorityRankDesc: [owner,maintainer,guest]
+DefaultRole: guest
+Roles:
+  - name: owner
+    rules:
+    - apiGroups: ["*"]
+      resources: ["*"]
+      verbs: ["*"]
+      scopes: ["*"]
+      nonResourceURLs: ["*"]
+  - name: maintainer
+    rules:
+    - apiGroups: ["core"]
+      resources: ["applications", "clusters", "clusters/builddeploy"]
+      verbs: ["get", "create", "update"]
+      scopes: ["*"]
+  - name: guest
+    rules:
+    - apiGroups: ["core"]
+      resources: ["applications", "clusters"]
+      verbs: ["get"]
+      scopes: ["*"]
+`
+
+// nolint
+func Test(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&customrolemodels.CustomRole{}, &membermodels.Member{}, &groupmodels.Group{},
+		&usermodels.User{}); err != nil {
+		panic(err)
+	}
+	mgr := managerparam.InitManager(db)
+	adminCtx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name:  "admin",
+		ID:    100,
+		Admin: true,
+	})
+	userCtx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "tom",
+		ID:   200,
+	})
+
+	var config roleconfig.Config
+	assert.Nil(t, yaml.Unmarshal([]byte(roles), &config))
+	roleSvc, err := role.NewCustomService(adminCtx, config, mgr.CustomRoleMgr)
+	assert.Nil(t, err)
+	ctl := NewController(&param.Param{
+		Manager:       mgr,
+		RoleService:   roleSvc,
+		CustomRoleSvc: roleSvc,
+	})
+
+	rules := []types.PolicyRule{{
+		APIGroups: []string{"core"},
+		Resources: []string{"clusters/builddeploy"},
+		Verbs:     []string{"create"},
+		Scopes:    []string{"online/*"},
+	}}
+	request := &CreateRoleRequest{Name: "deployer", Desc: "deploy only", BaseRole: "maintainer", Rules: rules}
+
+	_, err = ctl.CreateRole(adminCtx, &CreateRoleRequest{Name: "owner", BaseRole: "maintainer", Rules: rules})
+	assert.Equal(t, herrors.ErrNameConflict, perror.Cause(err))
+	_, err = ctl.CreateRole(adminCtx, &CreateRoleRequest{Name: "deployer", BaseRole: "nobody", Rules: rules})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// privilege escalation
+	_, err = ctl.CreateRole(adminCtx, &CreateRoleRequest{Name: "deployer", BaseRole: "maintainer",
+		Rules: []types.PolicyRule{{
+			APIGroups: []string{"core"},
+			Resources: []string{"clusters"},
+			Verbs:     []string{"delete"},
+			Scopes:    []string{"*"},
+		}}})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	created, err := ctl.CreateRole(adminCtx, request)
+	assert.Nil(t, err)
+	assert.Equal(t, "deployer", created.Name)
+	assert.False(t, created.Builtin)
+	_, err = ctl.CreateRole(adminCtx, request)
+	assert.Equal(t, herrors.ErrNameConflict, perror.Cause(err))
+
+	// the role takes effect immediately, and ranks below guest which it doesn't cover
+	result, err := roleSvc.RoleCompare(adminCtx, "deployer", "maintainer")
+	assert.Nil(t, err)
+	assert.Equal(t, role.RoleSmaller, result)
+	result, err = roleSvc.RoleCompare(adminCtx, "deployer", "guest")
+	assert.Nil(t, err)
+	assert.Equal(t, role.RoleSmaller, result)
+
+	roleList, err := ctl.ListRole(userCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(roleList))
+	assert.True(t, roleList[0].Builtin)
+	assert.Equal(t, "deployer", roleList[3].Name)
+	assert.Equal(t, "maintainer", roleList[3].BaseRole)
+
+	// built-in roles are read-only
+	desc := "new desc"
+	_, err = ctl.UpdateRole(adminCtx, "owner", &UpdateRoleRequest{Desc: &desc})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(ctl.DeleteRole(adminCtx, "owner")))
+
+	updated, err := ctl.UpdateRole(adminCtx, "deployer", &UpdateRoleRequest{Desc: &desc})
+	assert.Nil(t, err)
+	assert.Equal(t, desc, updated.Desc)
+	assert.Equal(t, rules, updated.PolicyRules)
+	_, err = ctl.UpdateRole(adminCtx, "deployer", &UpdateRoleRequest{Rules: []types.PolicyRule{{
+		APIGroups: []string{"*"},
+		Resources: []string{"*"},
+		Verbs:     []string{"*"},
+		Scopes:    []string{"*"},
+	}}})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// role granted to members can't be deleted
+	group := &groupmodels.Group{Name: "group", Path: "group"}
+	assert.Nil(t, db.Create(group).Error)
+	assert.Nil(t, db.Model(group).Update("traversal_ids", strconv.Itoa(int(group.ID))).Error)
+	assert.Nil(t, db.Create(&usermodels.User{Model: global.Model{ID: 200}, Name: "tom"}).Error)
+	_, err = mgr.MemberMgr.Create(adminCtx, &membermodels.Member{
+		ResourceType: membermodels.TypeGroup,
+		ResourceID:   group.ID,
+		Role:         "deployer",
+		MemberType:   membermodels.MemberUser,
+		MemberNameID: 200,
+	})
+	assert.Nil(t, err)
+
+	// members of the role can't grant roles allowing what the role doesn't allow
+	memberSvc := memberservice.NewService(roleSvc, nil, mgr)
+	for _, roleName := range []string{"guest", "maintainer"} {
+		err = memberSvc.RequirePermissionEqualOrHigher(userCtx, roleName, common.ResourceGroup, group.ID)
+		assert.Equal(t, herrors.ErrNoPrivilege, perror.Cause(err))
+	}
+	assert.Nil(t, memberSvc.RequirePermissionEqualOrHigher(userCtx, "deployer", common.ResourceGroup, group.ID))
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(ctl.DeleteRole(adminCtx, "deployer")))
+	assert.Nil(t, db.Where("role = ?", "deployer").Delete(&membermodels.Member{}).Error)
+
+	assert.Nil(t, ctl.DeleteRole(adminCtx, "deployer"))
+	_, err = roleSvc.GetRole(adminCtx, "deployer")
+	assert.Equal(t, role.ErrorRoleNotFound, err)
+	_, err = ctl.GetRole(adminCtx, "deployer")
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
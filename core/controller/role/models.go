@@ -0,0 +1,58 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/customrole/models"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+)
+
+type Role struct {
+	types.Role
+	// Builtin is true for the roles from file, which are read-only
+	Builtin bool `json:"builtin"`
+	// BaseRole is the built-in role which the custom role ranks just below
+	BaseRole  string     `json:"baseRole,omitempty"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+	CreatedBy uint       `json:"createdBy,omitempty"`
+	UpdatedBy uint       `json:"updatedBy,omitempty"`
+}
+
+type CreateRoleRequest struct {
+	Name     string             `json:"name"`
+	Desc     string             `json:"desc"`
+	BaseRole string             `json:"baseRole"`
+	Rules    []types.PolicyRule `json:"rules"`
+}
+
+type UpdateRoleRequest struct {
+	Desc     *string            `json:"desc"`
+	BaseRole *string            `json:"baseRole"`
+	Rules    []types.PolicyRule `json:"rules"`
+}
+
+func ofCustomRole(customRole *models.CustomRole) *Role {
+	return &Role{
+		Role:      customRole.ToRole(),
+		BaseRole:  customRole.BaseRole,
+		CreatedAt: &customRole.CreatedAt,
+		UpdatedAt: &customRole.UpdatedAt,
+		CreatedBy: customRole.CreatedBy,
+		UpdatedBy: customRole.UpdatedBy,
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package team
+
+import (
+	"context"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	idpmanager "github.com/horizoncd/horizon/pkg/idp/manager"
+	"github.com/horizoncd/horizon/pkg/param"
+	teammanager "github.com/horizoncd/horizon/pkg/team/manager"
+	"github.com/horizoncd/horizon/pkg/team/models"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+)
+
+type Controller interface {
+	Create(ctx context.Context, request *CreateTeamRequest) (*Team, error)
+	GetByID(ctx context.Context, id uint) (*Team, error)
+	List(ctx context.Context, query *q.Query) ([]*Team, int64, error)
+	Update(ctx context.Context, id uint, request *UpdateTeamRequest) (*Team, error)
+	// Delete deletes the team, and the members bound by the team are removed as well
+	Delete(ctx context.Context, id uint) error
+
+	ListMembers(ctx context.Context, id uint) ([]*TeamMember, error)
+	// AddMembers adds users to the team, members of synced teams can't be edited manually
+	AddMembers(ctx context.Context, id uint, request *AddMembersRequest) error
+	RemoveMember(ctx context.Context, id uint, userID uint) error
+}
+
+type controller struct {
+	teamMgr teammanager.Manager
+	userMgr usermanager.Manager
+	idpMgr  idpmanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		teamMgr: param.TeamMgr,
+		userMgr: param.UserMgr,
+		idpMgr:  param.IdpMgr,
+	}
+}
+
+func (c *controller) checkName(ctx context.Context, name string, id uint) error {
+	if name == "" {
+		return perror.Wrap(herrors.ErrParamInvalid, "name of team is required")
+	}
+	team, err := c.teamMgr.GetByName(ctx, name)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil
+		}
+		return err
+	}
+	if team.ID != id {
+		return perror.Wrapf(herrors.ErrNameConflict, "team with name %s already exists", name)
+	}
+	return nil
+}
+
+func (c *controller) checkExternalGroup(ctx context.Context, idpID uint, externalGroup string) error {
+	if idpID == 0 {
+		if externalGroup != "" {
+			return perror.Wrap(herrors.ErrParamInvalid, "idpID is required when externalGroup is set")
+		}
+		return nil
+	}
+	if externalGroup == "" {
+		return perror.Wrap(herrors.ErrParamInvalid, "externalGroup is required when idpID is set")
+	}
+	if _, err := c.idpMgr.GetByID(ctx, idpID); err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return perror.Wrapf(herrors.ErrParamInvalid, "identity provider %d not found", idpID)
+		}
+		return err
+	}
+	return nil
+}
+
+func (c *controller) Create(ctx context.Context, request *CreateTeamRequest) (*Team, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userID := currentUser.GetID()
+	name := strings.TrimSpace(request.Name)
+	if err := c.checkName(ctx, name, 0); err != nil {
+		return nil, err
+	}
+	if err := c.checkExternalGroup(ctx, request.IdpID, request.ExternalGroup); err != nil {
+		return nil, err
+	}
+
+	team, err := c.teamMgr.Create(ctx, &models.Team{
+		Name:          name,
+		Description:   request.Description,
+		IdpID:         request.IdpID,
+		ExternalGroup: request.ExternalGroup,
+		CreatedBy:     userID,
+		UpdatedBy:     userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ofTeamModel(team), nil
+}
+
+func (c *controller) GetByID(ctx context.Context, id uint) (*Team, error) {
+	team, err := c.teamMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ofTeamModel(team), nil
+}
+
+func (c *controller) List(ctx context.Context, query *q.Query) ([]*Team, int64, error) {
+	total, teams, err := c.teamMgr.List(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	return ofTeamModels(teams), total, nil
+}
+
+func (c *controller) Update(ctx context.Context, id uint, request *UpdateTeamRequest) (*Team, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userID := currentUser.GetID()
+	team, err := c.teamMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Name != nil {
+		name := strings.TrimSpace(*request.Name)
+		if err := c.checkName(ctx, name, id); err != nil {
+			return nil, err
+		}
+		team.Name = name
+	}
+	if request.Description != nil {
+		team.Description = *request.Description
+	}
+	if request.IdpID != nil {
+		team.IdpID = *request.IdpID
+	}
+	if request.ExternalGroup != nil {
+		team.ExternalGroup = *request.ExternalGroup
+	}
+	if err := c.checkExternalGroup(ctx, team.IdpID, team.ExternalGroup); err != nil {
+		return nil, err
+	}
+	team.UpdatedBy = userID
+
+	team, err = c.teamMgr.Update(ctx, team)
+	if err != nil {
+		return nil, err
+	}
+	return ofTeamModel(team), nil
+}
+
+func (c *controller) Delete(ctx context.Context, id uint) error {
+	if _, err := c.teamMgr.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return c.teamMgr.Delete(ctx, id)
+}
+
+func (c *controller) ListMembers(ctx context.Context, id uint) ([]*TeamMember, error) {
+	if _, err := c.teamMgr.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	members, err := c.teamMgr.ListMembers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	userIDs := make([]uint, 0, len(members))
+	for _, member := range members {
+		userIDs = append(userIDs, member.UserID)
+	}
+	users, err := c.userMgr.GetUserByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	return ofTeamMemberModels(members, users), nil
+}
+
+func (c *controller) getEditableTeam(ctx context.Context, id uint) (*models.Team, error) {
+	team, err := c.teamMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if team.IsSynced() {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"members of team %s are synced from identity provider and can't be edited", team.Name)
+	}
+	return team, nil
+}
+
+func (c *controller) AddMembers(ctx context.Context, id uint, request *AddMembersRequest) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	userID := currentUser.GetID()
+	if _, err := c.getEditableTeam(ctx, id); err != nil {
+		return err
+	}
+	if len(request.UserIDs) == 0 {
+		return perror.Wrap(herrors.ErrParamInvalid, "userIDs is required")
+	}
+	users, err := c.userMgr.GetUserByIDs(ctx, request.UserIDs)
+	if err != nil {
+		return err
+	}
+	found := make(map[uint]struct{}, len(users))
+	for _, user := range users {
+		found[user.ID] = struct{}{}
+	}
+	for _, id := range request.UserIDs {
+		if _, ok := found[id]; !ok {
+			return perror.Wrapf(herrors.ErrParamInvalid, "user %d not found", id)
+		}
+	}
+	return c.teamMgr.AddMembers(ctx, id, request.UserIDs, userID)
+}
+
+func (c *controller) RemoveMember(ctx context.Context, id uint, userID uint) error {
+	if _, err := c.getEditableTeam(ctx, id); err != nil {
+		return err
+	}
+	return c.teamMgr.RemoveMember(ctx, id, userID)
+}
@@ -0,0 +1,166 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package team
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	idpmodels "github.com/horizoncd/horizon/pkg/idp/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+	teammodels "github.com/horizoncd/horizon/pkg/team/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+const roles = `
+RolePriorityRankDesc: [owner,guest]
+DefaultRole: guest
+Roles:
+  - name: owner
+    rules:
+    - apiGroups: ["*"]
+      resources: ["*"]
+      verbs: ["*"]
+      scopes: ["*"]
+      nonResourceURLs: ["*"]
+  - name: guest
+    rules:
+    - apiGroups: ["*"]
+      resources: ["*"]
+      verbs: ["get"]
+      scopes: ["*"]
+      nonResourceURLs: ["*"]
+`
+
+// nolint
+func Test(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&usermodels.User{}, &teammodels.Team{}, &teammodels.TeamMember{},
+		&membermodels.Member{}, &groupmodels.Group{}, &idpmodels.IdentityProvider{}); err != nil {
+		panic(err)
+	}
+	mgr := managerparam.InitManager(db)
+	adminCtx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name:  "admin",
+		ID:    100,
+		Admin: true,
+	})
+	roleSvc, err := role.NewFileRole(adminCtx, strings.NewReader(roles))
+	assert.Nil(t, err)
+	memberSvc := memberservice.NewService(roleSvc, nil, mgr)
+	ctl := NewController(&param.Param{Manager: mgr})
+
+	tom, err := mgr.UserMgr.Create(adminCtx, &usermodels.User{Name: "tom", Email: "tom@horizoncd.io"})
+	assert.Nil(t, err)
+	jerry, err := mgr.UserMgr.Create(adminCtx, &usermodels.User{Name: "jerry", Email: "jerry@horizoncd.io"})
+	assert.Nil(t, err)
+	tomCtx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: tom.Name,
+		ID:   tom.ID,
+	})
+
+	team, err := ctl.Create(adminCtx, &CreateTeamRequest{Name: "dev", Description: "developers"})
+	assert.Nil(t, err)
+	assert.Equal(t, "dev", team.Name)
+	assert.False(t, team.Synced)
+
+	_, err = ctl.Create(adminCtx, &CreateTeamRequest{Name: "dev"})
+	assert.Equal(t, herrors.ErrNameConflict, perror.Cause(err))
+	_, err = ctl.Create(adminCtx, &CreateTeamRequest{Name: "ops", IdpID: 1})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = ctl.Create(adminCtx, &CreateTeamRequest{Name: "ops", IdpID: 1, ExternalGroup: "ops"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	description := "backend developers"
+	team, err = ctl.Update(adminCtx, team.ID, &UpdateTeamRequest{Description: &description})
+	assert.Nil(t, err)
+	assert.Equal(t, "dev", team.Name)
+	assert.Equal(t, description, team.Description)
+
+	teams, total, err := ctl.List(tomCtx, q.New(q.KeyWords{common.TeamQueryName: "de"}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, team.ID, teams[0].ID)
+
+	// members
+	err = ctl.AddMembers(adminCtx, team.ID, &AddMembersRequest{UserIDs: []uint{tom.ID, 1000}})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	err = ctl.AddMembers(adminCtx, team.ID, &AddMembersRequest{UserIDs: []uint{tom.ID, jerry.ID}})
+	assert.Nil(t, err)
+	// adding existing members is ignored
+	err = ctl.AddMembers(adminCtx, team.ID, &AddMembersRequest{UserIDs: []uint{tom.ID}})
+	assert.Nil(t, err)
+	members, err := ctl.ListMembers(tomCtx, team.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(members))
+	assert.Nil(t, ctl.RemoveMember(adminCtx, team.ID, jerry.ID))
+	members, err = ctl.ListMembers(tomCtx, team.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(members))
+	assert.Equal(t, "tom", members[0].Name)
+
+	// user gets the higher role of direct and team bindings
+	group := &groupmodels.Group{Name: "horizon", Path: "horizon", TraversalIDs: "1"}
+	assert.Nil(t, db.Create(group).Error)
+	groupID := strconv.Itoa(int(group.ID))
+	_, err = mgr.MemberMgr.Create(adminCtx, &membermodels.Member{
+		ResourceType: membermodels.TypeGroup,
+		ResourceID:   group.ID,
+		Role:         "guest",
+		MemberType:   membermodels.MemberUser,
+		MemberNameID: tom.ID,
+	})
+	assert.Nil(t, err)
+	member, err := memberSvc.GetMemberOfResource(tomCtx, common.ResourceGroup, groupID)
+	assert.Nil(t, err)
+	assert.Equal(t, "guest", member.Role)
+
+	_, err = mgr.MemberMgr.Create(adminCtx, &membermodels.Member{
+		ResourceType: membermodels.TypeGroup,
+		ResourceID:   group.ID,
+		Role:         "owner",
+		MemberType:   membermodels.MemberGroup,
+		MemberNameID: team.ID,
+	})
+	assert.Nil(t, err)
+	member, err = memberSvc.GetMemberOfResource(tomCtx, common.ResourceGroup, groupID)
+	assert.Nil(t, err)
+	assert.Equal(t, "owner", member.Role)
+	assert.Equal(t, membermodels.MemberGroup, member.MemberType)
+
+	// deleting the team removes its bindings
+	assert.Nil(t, ctl.Delete(adminCtx, team.ID))
+	_, err = ctl.GetByID(adminCtx, team.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	member, err = memberSvc.GetMemberOfResource(tomCtx, common.ResourceGroup, groupID)
+	assert.Nil(t, err)
+	assert.Equal(t, "guest", member.Role)
+}
@@ -0,0 +1,107 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package team
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/team/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+type Team struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// IdpID and ExternalGroup are set when the team is synced from identity provider
+	IdpID         uint      `json:"idpID,omitempty"`
+	ExternalGroup string    `json:"externalGroup,omitempty"`
+	Synced        bool      `json:"synced"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	CreatedBy     uint      `json:"createdBy"`
+	UpdatedBy     uint      `json:"updatedBy"`
+}
+
+type CreateTeamRequest struct {
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	IdpID         uint   `json:"idpID"`
+	ExternalGroup string `json:"externalGroup"`
+}
+
+type UpdateTeamRequest struct {
+	Name          *string `json:"name"`
+	Description   *string `json:"description"`
+	IdpID         *uint   `json:"idpID"`
+	ExternalGroup *string `json:"externalGroup"`
+}
+
+type AddMembersRequest struct {
+	UserIDs []uint `json:"userIDs"`
+}
+
+type TeamMember struct {
+	UserID   uint      `json:"userID"`
+	Name     string    `json:"name"`
+	FullName string    `json:"fullName"`
+	Email    string    `json:"email"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+func ofTeamModel(team *models.Team) *Team {
+	return &Team{
+		ID:            team.ID,
+		Name:          team.Name,
+		Description:   team.Description,
+		IdpID:         team.IdpID,
+		ExternalGroup: team.ExternalGroup,
+		Synced:        team.IsSynced(),
+		CreatedAt:     team.CreatedAt,
+		UpdatedAt:     team.UpdatedAt,
+		CreatedBy:     team.CreatedBy,
+		UpdatedBy:     team.UpdatedBy,
+	}
+}
+
+func ofTeamModels(teams []*models.Team) []*Team {
+	res := make([]*Team, 0, len(teams))
+	for _, team := range teams {
+		res = append(res, ofTeamModel(team))
+	}
+	return res
+}
+
+func ofTeamMemberModels(members []*models.TeamMember, users []*usermodels.User) []*TeamMember {
+	userMap := make(map[uint]*usermodels.User, len(users))
+	for _, user := range users {
+		userMap[user.ID] = user
+	}
+	res := make([]*TeamMember, 0, len(members))
+	for _, member := range members {
+		user, ok := userMap[member.UserID]
+		if !ok {
+			continue
+		}
+		res = append(res, &TeamMember{
+			UserID:   user.ID,
+			Name:     user.Name,
+			FullName: user.FullName,
+			Email:    user.Email,
+			JoinedAt: member.CreatedAt,
+		})
+	}
+	return res
+}
@@ -0,0 +1,228 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	terminalconfig "github.com/horizoncd/horizon/pkg/config/terminal"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+	"github.com/horizoncd/horizon/pkg/terminalaccess/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const _defaultMaxGrantDuration = time.Hour
+
+func (c *controller) Disconnect(ctx context.Context, clusterID uint, sessionID string) error {
+	const op = "terminal controller: disconnect"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := c.memberSvc.RequirePermissionEqualOrHigher(ctx, role.Owner, common.ResourceCluster,
+		clusterID); err != nil {
+		return err
+	}
+	user, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	id, ok := terminalSessions.Find(clusterID, sessionID)
+	if !ok {
+		return herrors.NewErrNotFound(herrors.TerminalSessionInDB,
+			fmt.Sprintf("running terminal %s was not found in cluster %d", sessionID, clusterID))
+	}
+	terminalSessions.Close(id, 3, fmt.Sprintf("Disconnected by %s", user.GetName()))
+
+	c.recordEvent(ctx, clusterID, eventmodels.ClusterTerminalDisconnected, map[string]interface{}{
+		"sessionID": sessionID,
+	})
+	return nil
+}
+
+func (c *controller) RequestAccess(ctx context.Context, clusterID uint,
+	request *AccessRequest) (*AccessGrant, error) {
+	const op = "terminal controller: request access"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	policy := c.terminalConfig.PolicyOf(cluster.EnvironmentName)
+	if policy.Mode != terminalconfig.PolicyModeJIT {
+		return nil, perror.Wrapf(herrors.ErrTerminalAccessNotRequired, "environment: %s", cluster.EnvironmentName)
+	}
+	maxDuration := policy.MaxGrantDuration
+	if maxDuration <= 0 {
+		maxDuration = _defaultMaxGrantDuration
+	}
+	duration := time.Duration(request.Duration) * time.Second
+	if duration <= 0 || duration > maxDuration {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"duration should be between 1s and %v, got %v", maxDuration, duration)
+	}
+	if request.Reason == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "reason should not be empty")
+	}
+
+	user, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	grant, err := c.terminalAccessMgr.Create(ctx, &models.TerminalAccessGrant{
+		ClusterID: clusterID,
+		UserID:    user.GetID(),
+		Reason:    request.Reason,
+		Duration:  request.Duration,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.recordEvent(ctx, clusterID, eventmodels.ClusterTerminalAccessRequested, map[string]interface{}{
+		"grantID":  grant.ID,
+		"reason":   grant.Reason,
+		"duration": grant.Duration,
+	})
+	return ofAccessGrant(grant), nil
+}
+
+func (c *controller) ListAccessGrants(ctx context.Context, clusterID uint,
+	query *q.Query) (int64, []*AccessGrant, error) {
+	const op = "terminal controller: list access grants"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.clusterMgr.GetByID(ctx, clusterID); err != nil {
+		return 0, nil, err
+	}
+	total, grants, err := c.terminalAccessMgr.ListByClusterID(ctx, clusterID, query)
+	if err != nil {
+		return 0, nil, err
+	}
+	resp := make([]*AccessGrant, 0, len(grants))
+	for _, grant := range grants {
+		resp = append(resp, ofAccessGrant(grant))
+	}
+	return total, resp, nil
+}
+
+func (c *controller) ApproveAccess(ctx context.Context, clusterID, grantID uint, approved bool) error {
+	const op = "terminal controller: approve access"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	grant, user, err := c.getGrantForApprover(ctx, clusterID, grantID)
+	if err != nil {
+		return err
+	}
+	if grant.UserID == user.GetID() {
+		return perror.Wrap(herrors.ErrNoPrivilege, "terminal access cannot be approved by the requester")
+	}
+
+	if !approved {
+		if err := c.terminalAccessMgr.Reject(ctx, grantID, user.GetID()); err != nil {
+			return err
+		}
+		c.recordEvent(ctx, clusterID, eventmodels.ClusterTerminalAccessRejected, map[string]interface{}{
+			"grantID": grantID,
+		})
+		return nil
+	}
+
+	expiresAt := time.Now().Add(time.Duration(grant.Duration) * time.Second)
+	if err := c.terminalAccessMgr.Approve(ctx, grantID, user.GetID(), expiresAt); err != nil {
+		return err
+	}
+	c.recordEvent(ctx, clusterID, eventmodels.ClusterTerminalAccessApproved, map[string]interface{}{
+		"grantID":   grantID,
+		"expiresAt": expiresAt,
+	})
+	return nil
+}
+
+func (c *controller) RevokeAccess(ctx context.Context, clusterID, grantID uint) error {
+	const op = "terminal controller: revoke access"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	_, user, err := c.getGrantForApprover(ctx, clusterID, grantID)
+	if err != nil {
+		return err
+	}
+	if err := c.terminalAccessMgr.Revoke(ctx, grantID, user.GetID()); err != nil {
+		return err
+	}
+	// sessions opened with the grant are closed at once rather than at its expiration
+	closed := terminalSessions.CloseByGrant(grantID, 3, fmt.Sprintf("Terminal access revoked by %s", user.GetName()))
+	c.recordEvent(ctx, clusterID, eventmodels.ClusterTerminalAccessRevoked, map[string]interface{}{
+		"grantID":        grantID,
+		"closedSessions": closed,
+	})
+	return nil
+}
+
+// getGrantForApprover returns the grant of cluster and current user, who should be owner of the cluster
+func (c *controller) getGrantForApprover(ctx context.Context, clusterID,
+	grantID uint) (*models.TerminalAccessGrant, userauth.User, error) {
+	if err := c.memberSvc.RequirePermissionEqualOrHigher(ctx, role.Owner, common.ResourceCluster,
+		clusterID); err != nil {
+		return nil, nil, err
+	}
+	user, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	grant, err := c.terminalAccessMgr.GetByID(ctx, grantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if grant.ClusterID != clusterID {
+		return nil, nil, herrors.NewErrNotFound(herrors.TerminalAccessGrantInDB,
+			fmt.Sprintf("terminal access grant %d was not found in cluster %d", grantID, clusterID))
+	}
+	return grant, user, nil
+}
+
+// getValidGrant returns the valid grant of current user in cluster
+func (c *controller) getValidGrant(ctx context.Context, clusterID uint) (*models.TerminalAccessGrant, error) {
+	user, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	grant, err := c.terminalAccessMgr.GetValid(ctx, clusterID, user.GetID())
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil, perror.Wrap(herrors.ErrTerminalAccessNotGranted, err.Error())
+		}
+		return nil, err
+	}
+	return grant, nil
+}
+
+func (c *controller) recordEvent(ctx context.Context, clusterID uint, eventType string,
+	extra map[string]interface{}) {
+	var extraStr *string
+	if b, err := json.Marshal(extra); err == nil {
+		s := string(b)
+		extraStr = &s
+	}
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, clusterID, eventType, extraStr)
+}
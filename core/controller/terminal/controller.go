@@ -22,20 +22,31 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"k8s.io/client-go/tools/remotecommand"
 
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/config"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
 	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	"github.com/horizoncd/horizon/pkg/cluster/kubeclient"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	terminalconfig "github.com/horizoncd/horizon/pkg/config/terminal"
 	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	envregionmanager "github.com/horizoncd/horizon/pkg/environmentregion/manager"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/param"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
+	terminalaccessmanager "github.com/horizoncd/horizon/pkg/terminalaccess/manager"
+	terminalsessionmanager "github.com/horizoncd/horizon/pkg/terminalsession/manager"
+	terminalsessionmodels "github.com/horizoncd/horizon/pkg/terminalsession/models"
+	terminalsessionservice "github.com/horizoncd/horizon/pkg/terminalsession/service"
 	"github.com/horizoncd/horizon/pkg/util/errors"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 
@@ -48,6 +59,24 @@ type Controller interface {
 	// CreateShell returns sessionID and sockJSHandler according to clusterID,podName,containerName
 	CreateShell(ctx context.Context, clusterID uint, podName, containerName string) (sessionID string,
 		sockJSHandler http.Handler, err error)
+	// ListSessions lists recorded terminal sessions of a cluster
+	ListSessions(ctx context.Context, clusterID uint, query *q.Query) (int64, []*TerminalSession, error)
+	// GetSessionRecording returns the recording of a terminal session in asciicast format
+	GetSessionRecording(ctx context.Context, clusterID, sessionID uint) ([]byte, error)
+	// Disconnect closes a running terminal forcibly, sessionID is the one returned by CreateShell
+	Disconnect(ctx context.Context, clusterID uint, sessionID string) error
+
+	// RequestAccess requests a time-boxed terminal access of cluster in environment with jit policy
+	RequestAccess(ctx context.Context, clusterID uint, request *AccessRequest) (*AccessGrant, error)
+	ListAccessGrants(ctx context.Context, clusterID uint, query *q.Query) (int64, []*AccessGrant, error)
+	// ApproveAccess approves or rejects a pending request, which requires owner of cluster
+	ApproveAccess(ctx context.Context, clusterID, grantID uint, approved bool) error
+	// RevokeAccess revokes an approved access, which requires owner of cluster
+	RevokeAccess(ctx context.Context, clusterID, grantID uint) error
+
+	// CreateDebugContainer attaches an ephemeral debug container to pod for images without shell,
+	// then shell can be created in the debug container
+	CreateDebugContainer(ctx context.Context, clusterID uint, request *DebugContainerRequest) (*DebugContainer, error)
 }
 
 type controller struct {
@@ -59,11 +88,17 @@ type controller struct {
 	envRegionMgr       envregionmanager.Manager
 	regionMgr          regionmanager.Manager
 	clusterGitRepo     gitrepo.ClusterGitRepo
+	terminalSessionMgr terminalsessionmanager.Manager
+	terminalSessionSvc terminalsessionservice.Service
+	terminalAccessMgr  terminalaccessmanager.Manager
+	terminalConfig     terminalconfig.Config
+	memberSvc          memberservice.Service
+	eventSvc           eventservice.Service
 }
 
 var _ Controller = (*controller)(nil)
 
-func NewController(param *param.Param) Controller {
+func NewController(config *config.Config, param *param.Param) Controller {
 	return &controller{
 		kubeClientFty:      kubeclient.Fty,
 		clusterMgr:         param.ClusterMgr,
@@ -73,6 +108,12 @@ func NewController(param *param.Param) Controller {
 		envRegionMgr:       param.EnvRegionMgr,
 		regionMgr:          param.RegionMgr,
 		clusterGitRepo:     param.ClusterGitRepo,
+		terminalSessionMgr: param.TerminalSessionMgr,
+		terminalSessionSvc: param.TerminalSessionSvc,
+		terminalAccessMgr:  param.TerminalAccessMgr,
+		terminalConfig:     config.TerminalConfig,
+		memberSvc:          param.MemberService,
+		eventSvc:           param.EventSvc,
 	}
 }
 
@@ -114,7 +155,8 @@ func (c *controller) GetSockJSHandler(ctx context.Context, sessionID string) (ht
 		return nil, err
 	}
 
-	kubeConfig, kubeClient, err := c.kubeClientFty.GetByK8SServer(regionEntity.Server, regionEntity.Certificate)
+	kubeConfig, kubeClient, err := c.kubeClientFty.GetByK8SServer(regionEntity.Server,
+		string(regionEntity.Certificate))
 	if err != nil {
 		return nil, err
 	}
@@ -138,11 +180,11 @@ func (c *controller) GetSockJSHandler(ctx context.Context, sessionID string) (ht
 		RandomID:    randomID,
 	}
 
-	terminalSessions.Set(ref.String(), Session{
-		id:       ref.String(),
-		bound:    make(chan error),
-		sizeChan: make(chan remotecommand.TerminalSize),
-	})
+	session, err := c.newSession(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	terminalSessions.Set(ref.String(), session)
 
 	go WaitForTerminal(kubeClient.Basic, kubeConfig, ref)
 
@@ -170,7 +212,8 @@ func (c *controller) CreateShell(ctx context.Context, clusterID uint, podName,
 		return "", nil, err
 	}
 
-	kubeConfig, kubeClient, err := c.kubeClientFty.GetByK8SServer(regionEntity.Server, regionEntity.Certificate)
+	kubeConfig, kubeClient, err := c.kubeClientFty.GetByK8SServer(regionEntity.Server,
+		string(regionEntity.Certificate))
 	if err != nil {
 		return "", nil, err
 	}
@@ -200,11 +243,11 @@ func (c *controller) CreateShell(ctx context.Context, clusterID uint, podName,
 		RandomID:    randomID,
 	}
 
-	terminalSessions.Set(ref.String(), Session{
-		id:       ref.String(),
-		bound:    make(chan error),
-		sizeChan: make(chan remotecommand.TerminalSize),
-	})
+	session, err := c.newSession(ctx, ref)
+	if err != nil {
+		return "", nil, err
+	}
+	terminalSessions.Set(ref.String(), session)
 
 	handler := sockjs.NewHandler("/apis/core/v1", sockjs.DefaultOptions, handleShellSession(ctx, ref.String()))
 
@@ -212,6 +255,91 @@ func (c *controller) CreateShell(ctx context.Context, clusterID uint, podName,
 	return randomID, handler, nil
 }
 
+func (c *controller) ListSessions(ctx context.Context, clusterID uint,
+	query *q.Query) (int64, []*TerminalSession, error) {
+	const op = "terminal controller: list sessions"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.clusterMgr.GetByID(ctx, clusterID); err != nil {
+		return 0, nil, err
+	}
+	total, sessions, err := c.terminalSessionMgr.ListByClusterID(ctx, clusterID, query)
+	if err != nil {
+		return 0, nil, err
+	}
+	resp := make([]*TerminalSession, 0, len(sessions))
+	for _, session := range sessions {
+		resp = append(resp, ofTerminalSession(session))
+	}
+	return total, resp, nil
+}
+
+func (c *controller) GetSessionRecording(ctx context.Context, clusterID, sessionID uint) ([]byte, error) {
+	const op = "terminal controller: get session recording"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	session, err := c.terminalSessionMgr.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.ClusterID != clusterID {
+		return nil, herrors.NewErrNotFound(herrors.TerminalSessionInDB,
+			fmt.Sprintf("terminal session %d was not found in cluster %d", sessionID, clusterID))
+	}
+	return c.terminalSessionSvc.GetRecording(ctx, session)
+}
+
+// newSession creates a terminal session restricted by the policy of environment,
+// and starts recording it if recording is enabled
+func (c *controller) newSession(ctx context.Context, ref ContainerRef) (Session, error) {
+	policy := c.terminalConfig.PolicyOf(ref.Environment)
+	var (
+		deadline time.Time
+		grantID  uint
+	)
+	switch policy.Mode {
+	case terminalconfig.PolicyModeDisabled:
+		return Session{}, perror.Wrapf(herrors.ErrTerminalDisabled, "environment: %s", ref.Environment)
+	case terminalconfig.PolicyModeJIT:
+		grant, err := c.getValidGrant(ctx, ref.ClusterID)
+		if err != nil {
+			return Session{}, err
+		}
+		deadline = *grant.ExpiresAt
+		grantID = grant.ID
+	}
+
+	session := Session{
+		id:       ref.String(),
+		bound:    make(chan error),
+		sizeChan: make(chan remotecommand.TerminalSize),
+		policy:   newSessionPolicy(policy, deadline),
+		grantID:  grantID,
+	}
+	if c.terminalSessionSvc == nil || !c.terminalSessionSvc.Enabled() {
+		return session, nil
+	}
+
+	terminalSession := &terminalsessionmodels.TerminalSession{
+		SessionID:   ref.RandomID,
+		ClusterID:   ref.ClusterID,
+		Environment: ref.Environment,
+		Pod:         ref.Pod,
+		Container:   ref.Container,
+		StartedAt:   time.Now(),
+	}
+	// sessions opened from front apis are not authenticated, which are recorded without user
+	if user, err := common.UserFromContext(ctx); err == nil {
+		terminalSession.UserID = user.GetID()
+	}
+	recording, err := c.terminalSessionSvc.Start(ctx, terminalSession)
+	if err != nil {
+		return Session{}, err
+	}
+	session.recording = recording
+	return session, nil
+}
+
 func genRandomID() (string, error) {
 	bytes := make([]byte, 5)
 	if _, err := rand.Read(bytes); err != nil {
@@ -0,0 +1,189 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	terminalconfig "github.com/horizoncd/horizon/pkg/config/terminal"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const (
+	_debugContainerPrefix  = "debugger-"
+	_defaultDebugTimeout   = time.Minute
+	_debugContainerPollGap = 2 * time.Second
+)
+
+func (c *controller) CreateDebugContainer(ctx context.Context, clusterID uint,
+	request *DebugContainerRequest) (*DebugContainer, error) {
+	const op = "terminal controller: create debug container"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	debugConfig := c.terminalConfig.Debug
+	if len(debugConfig.Images) == 0 {
+		return nil, herrors.ErrDebugContainerDisabled
+	}
+	image := request.Image
+	if image == "" {
+		image = debugConfig.Images[0]
+	} else if !imageAllowed(debugConfig, image) {
+		return nil, perror.Wrapf(herrors.ErrDebugImageNotAllowed, "image: %s", image)
+	}
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	// debug containers are the same as terminals, which are restricted by the policy of environment
+	switch c.terminalConfig.PolicyOf(cluster.EnvironmentName).Mode {
+	case terminalconfig.PolicyModeDisabled:
+		return nil, perror.Wrapf(herrors.ErrTerminalDisabled, "environment: %s", cluster.EnvironmentName)
+	case terminalconfig.PolicyModeJIT:
+		if _, err := c.getValidGrant(ctx, clusterID); err != nil {
+			return nil, err
+		}
+	}
+
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return nil, err
+	}
+	_, kubeClient, err := c.kubeClientFty.GetByK8SServer(regionEntity.Server,
+		string(regionEntity.Certificate))
+	if err != nil {
+		return nil, err
+	}
+	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, cluster.Template, cluster.TemplateRelease)
+	if err != nil {
+		return nil, err
+	}
+	envValue, err := c.clusterGitRepo.GetEnvValue(ctx, application.Name, cluster.Name, tr.ChartName)
+	if err != nil {
+		return nil, err
+	}
+
+	pod, err := kube.GetPod(ctx, kubeClient.Basic, envValue.Namespace, request.PodName)
+	if err != nil {
+		return nil, err
+	}
+	// the namespace may be shared by clusters, debugging pods of other clusters is not allowed
+	if !podOfCluster(pod, cluster.Name) {
+		return nil, herrors.NewErrNotFound(herrors.PodsInK8S,
+			fmt.Sprintf("pod %s is not found in cluster %s", request.PodName, cluster.Name))
+	}
+	if !hasContainer(pod, request.TargetContainer) {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "container %s is not found in pod %s",
+			request.TargetContainer, request.PodName)
+	}
+
+	randomID, err := genRandomID()
+	if err != nil {
+		return nil, err
+	}
+	container := v1.EphemeralContainer{
+		EphemeralContainerCommon: v1.EphemeralContainerCommon{
+			Name:                     _debugContainerPrefix + randomID,
+			Image:                    image,
+			ImagePullPolicy:          v1.PullIfNotPresent,
+			Stdin:                    true,
+			TTY:                      true,
+			TerminationMessagePolicy: v1.TerminationMessageReadFile,
+		},
+		// share the process namespace of target container
+		TargetContainerName: request.TargetContainer,
+	}
+	if err := kube.AddEphemeralContainer(ctx, kubeClient.Basic, envValue.Namespace,
+		request.PodName, container); err != nil {
+		return nil, err
+	}
+
+	c.recordEvent(ctx, clusterID, eventmodels.ClusterDebugContainerCreated, map[string]interface{}{
+		"pod":             request.PodName,
+		"container":       container.Name,
+		"targetContainer": request.TargetContainer,
+		"image":           image,
+	})
+
+	timeout := debugConfig.Timeout
+	if timeout <= 0 {
+		timeout = _defaultDebugTimeout
+	}
+	running := false
+	err = wait.PollImmediate(_debugContainerPollGap, timeout, func() (bool, error) {
+		current, err := kube.GetPod(ctx, kubeClient.Basic, envValue.Namespace, request.PodName)
+		if err != nil {
+			return false, err
+		}
+		for _, status := range current.Status.EphemeralContainerStatuses {
+			if status.Name != container.Name {
+				continue
+			}
+			if status.State.Terminated != nil {
+				return false, fmt.Errorf("debug container terminated: %s", status.State.Terminated.Reason)
+			}
+			running = status.State.Running != nil
+			return running, nil
+		}
+		return false, nil
+	})
+	if err != nil && err != wait.ErrWaitTimeout {
+		return nil, err
+	}
+
+	return &DebugContainer{
+		PodName:         request.PodName,
+		ContainerName:   container.Name,
+		TargetContainer: request.TargetContainer,
+		Image:           image,
+		Running:         running,
+	}, nil
+}
+
+func imageAllowed(config terminalconfig.Debug, image string) bool {
+	for _, allowed := range config.Images {
+		if allowed == image {
+			return true
+		}
+	}
+	return false
+}
+
+func podOfCluster(pod *v1.Pod, cluster string) bool {
+	return pod.Labels[common.ClusterClusterLabelKey] == cluster
+}
+
+func hasContainer(pod *v1.Pod, name string) bool {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/horizoncd/horizon/core/common"
+)
+
+func TestPodOfCluster(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:   "app-cluster-1",
+		Labels: map[string]string{common.ClusterClusterLabelKey: "app-cluster"},
+	}}
+	assert.True(t, podOfCluster(pod, "app-cluster"))
+	assert.False(t, podOfCluster(pod, "app-cluster-2"))
+	assert.False(t, podOfCluster(&v1.Pod{}, "app-cluster"))
+}
@@ -14,6 +14,91 @@
 
 package terminal
 
+import (
+	"time"
+
+	terminalaccessmodels "github.com/horizoncd/horizon/pkg/terminalaccess/models"
+	"github.com/horizoncd/horizon/pkg/terminalsession/models"
+)
+
 type SessionIDResp struct {
 	ID string `json:"id"`
 }
+
+type TerminalSession struct {
+	ID          uint       `json:"id"`
+	SessionID   string     `json:"sessionID"`
+	ClusterID   uint       `json:"clusterID"`
+	Environment string     `json:"environment"`
+	Pod         string     `json:"pod"`
+	Container   string     `json:"container"`
+	UserID      uint       `json:"userID"`
+	Size        int64      `json:"size"`
+	StartedAt   time.Time  `json:"startedAt"`
+	FinishedAt  *time.Time `json:"finishedAt"`
+}
+
+func ofTerminalSession(session *models.TerminalSession) *TerminalSession {
+	return &TerminalSession{
+		ID:          session.ID,
+		SessionID:   session.SessionID,
+		ClusterID:   session.ClusterID,
+		Environment: session.Environment,
+		Pod:         session.Pod,
+		Container:   session.Container,
+		UserID:      session.UserID,
+		Size:        session.Size,
+		StartedAt:   session.StartedAt,
+		FinishedAt:  session.FinishedAt,
+	}
+}
+
+type AccessRequest struct {
+	Reason string `json:"reason"`
+	// Duration is the requested duration in seconds
+	Duration int64 `json:"duration"`
+}
+
+type AccessGrant struct {
+	ID         uint       `json:"id"`
+	ClusterID  uint       `json:"clusterID"`
+	UserID     uint       `json:"userID"`
+	Reason     string     `json:"reason"`
+	Duration   int64      `json:"duration"`
+	Status     string     `json:"status"`
+	ApproverID uint       `json:"approverID"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+func ofAccessGrant(grant *terminalaccessmodels.TerminalAccessGrant) *AccessGrant {
+	return &AccessGrant{
+		ID:         grant.ID,
+		ClusterID:  grant.ClusterID,
+		UserID:     grant.UserID,
+		Reason:     grant.Reason,
+		Duration:   grant.Duration,
+		Status:     grant.Status,
+		ApproverID: grant.ApproverID,
+		ExpiresAt:  grant.ExpiresAt,
+		CreatedAt:  grant.CreatedAt,
+	}
+}
+
+type DebugContainerRequest struct {
+	PodName string `json:"podName"`
+	// TargetContainer is the container whose process namespace is shared with debug container
+	TargetContainer string `json:"targetContainer"`
+	// Image is the debug image, default is the first one in config
+	Image string `json:"image"`
+}
+
+type DebugContainer struct {
+	PodName string `json:"podName"`
+	// ContainerName is the name of debug container, which can be used to create shell
+	ContainerName   string `json:"containerName"`
+	TargetContainer string `json:"targetContainer"`
+	Image           string `json:"image"`
+	// Running is false if the debug container is not running after timeout, try creating shell later
+	Running bool `json:"running"`
+}
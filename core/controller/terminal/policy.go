@@ -0,0 +1,170 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/config/terminal"
+)
+
+const (
+	_keyEnter      = '\r'
+	_keyNewLine    = '\n'
+	_keyBackspace  = '\x7f'
+	_keyCtrlH      = '\b'
+	_keyCtrlC      = '\x03'
+	_keyCtrlU      = '\x15'
+	_keyCtrlD      = '\x04'
+	_keyEscape     = '\x1b'
+	_shellOperator = ";&|<>`$\\"
+)
+
+// sessionPolicy enforces the terminal policy of environment on a session
+type sessionPolicy struct {
+	// allowedCommands is nil if all commands are allowed
+	allowedCommands []string
+	idleTimeout     time.Duration
+	// deadline is zero if the session never expires
+	deadline time.Time
+	// lastActive is the unix nano of last input
+	lastActive int64
+	// line is the command line typed by user, which is only accessed by Read
+	line []rune
+}
+
+// newSessionPolicy returns nil if the session is unrestricted
+func newSessionPolicy(policy terminal.Policy, deadline time.Time) *sessionPolicy {
+	p := &sessionPolicy{
+		idleTimeout: policy.IdleTimeout,
+		deadline:    deadline,
+		lastActive:  time.Now().UnixNano(),
+	}
+	if policy.Mode == terminal.PolicyModeReadOnly {
+		p.allowedCommands = append([]string{}, policy.AllowedCommands...)
+	}
+	if p.allowedCommands == nil && p.idleTimeout <= 0 && p.deadline.IsZero() {
+		return nil
+	}
+	return p
+}
+
+func (p *sessionPolicy) touch(now time.Time) {
+	atomic.StoreInt64(&p.lastActive, now.UnixNano())
+}
+
+// expired returns the reason if the session should be disconnected
+func (p *sessionPolicy) expired(now time.Time) (string, bool) {
+	if !p.deadline.IsZero() && !now.Before(p.deadline) {
+		return "Terminal access expired", true
+	}
+	if p.idleTimeout > 0 {
+		lastActive := time.Unix(0, atomic.LoadInt64(&p.lastActive))
+		if now.Sub(lastActive) >= p.idleTimeout {
+			return fmt.Sprintf("Terminal is idle for more than %v", p.idleTimeout), true
+		}
+	}
+	return "", false
+}
+
+// filterInput returns the input to be sent to the process and the rejected command line.
+// In read-only mode, the command line is checked when user presses enter, and is killed if it's not allowed.
+// Escape sequences and control keys which may edit the line invisibly (history, completion) are dropped.
+func (p *sessionPolicy) filterInput(data string) (string, string) {
+	if p.allowedCommands == nil {
+		return data, ""
+	}
+
+	var (
+		out      strings.Builder
+		rejected string
+	)
+	runes := []rune(data)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == _keyEnter || r == _keyNewLine:
+			line := strings.TrimSpace(string(p.line))
+			p.line = p.line[:0]
+			if line == "" || p.allowed(line) {
+				out.WriteRune(_keyEnter)
+				continue
+			}
+			rejected = line
+			out.WriteRune(_keyCtrlU)
+		case r == _keyBackspace || r == _keyCtrlH:
+			if len(p.line) > 0 {
+				p.line = p.line[:len(p.line)-1]
+			}
+			out.WriteRune(r)
+		case r == _keyCtrlC || r == _keyCtrlU:
+			p.line = p.line[:0]
+			out.WriteRune(r)
+		case r == _keyCtrlD:
+			// exits shell if the line is empty
+			out.WriteRune(r)
+		case r == _keyEscape:
+			i = skipEscapeSequence(runes, i)
+		case r < ' ':
+			// drop other control keys, such as tab and ctrl-r
+		default:
+			p.line = append(p.line, r)
+			out.WriteRune(r)
+		}
+	}
+	return out.String(), rejected
+}
+
+// allowed returns whether the command line is in allowlist,
+// a command is allowed if it equals to an allowed command or starts with it followed by arguments
+func (p *sessionPolicy) allowed(line string) bool {
+	if strings.ContainsAny(line, _shellOperator) {
+		return false
+	}
+	// the command is compared with its full path, so /tmp/ls is not allowed by ls
+	line = strings.Join(strings.Fields(line), " ")
+	for _, command := range p.allowedCommands {
+		command = strings.Join(strings.Fields(command), " ")
+		if command == "" {
+			continue
+		}
+		if line == command || strings.HasPrefix(line, command+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// skipEscapeSequence returns the index of last rune of the escape sequence starting at i
+func skipEscapeSequence(runes []rune, i int) int {
+	if i+1 >= len(runes) {
+		return i
+	}
+	switch runes[i+1] {
+	case '[', 'O':
+		// CSI and SS3 sequences end with a rune in range 0x40-0x7e
+		for j := i + 2; j < len(runes); j++ {
+			if runes[j] >= 0x40 && runes[j] <= 0x7e {
+				return j
+			}
+		}
+		return len(runes) - 1
+	default:
+		return i + 1
+	}
+}
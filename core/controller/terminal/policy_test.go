@@ -0,0 +1,105 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/pkg/config/terminal"
+)
+
+func TestSessionPolicyFilterInput(t *testing.T) {
+	p := newSessionPolicy(terminal.Policy{
+		Mode:            terminal.PolicyModeReadOnly,
+		AllowedCommands: []string{"ls", "tail -f", "cat"},
+	}, time.Time{})
+	assert.NotNil(t, p)
+
+	cases := []struct {
+		input    string
+		out      string
+		rejected string
+	}{
+		{input: "ls -l\r", out: "ls -l\r"},
+		{input: "/bin/ls\r", out: "/bin/ls\x15", rejected: "/bin/ls"},
+		{input: "./ls -l\r", out: "./ls -l\x15", rejected: "./ls -l"},
+		{input: "tail -f app.log\r", out: "tail -f app.log\r"},
+		{input: "tail app.log\r", out: "tail app.log\x15", rejected: "tail app.log"},
+		{input: "rm -rf /\r", out: "rm -rf /\x15", rejected: "rm -rf /"},
+		{input: "cat a | sh\r", out: "cat a | sh\x15", rejected: "cat a | sh"},
+		{input: "ls; rm a\r", out: "ls; rm a\x15", rejected: "ls; rm a"},
+		{input: "rmx\x7f\x7f\x7fls\r", out: "rmx\x7f\x7f\x7fls\r"},
+		// history and completion are dropped
+		{input: "\x1b[A\r", out: "\r"},
+		{input: "l\ts\r", out: "ls\r"},
+		{input: "\r", out: "\r"},
+	}
+	for _, c := range cases {
+		out, rejected := p.filterInput(c.input)
+		assert.Equal(t, c.out, out, c.input)
+		assert.Equal(t, c.rejected, rejected, c.input)
+	}
+
+	// typed in several messages
+	out, rejected := p.filterInput("r")
+	assert.Equal(t, "r", out)
+	assert.Empty(t, rejected)
+	out, rejected = p.filterInput("m a\r")
+	assert.Equal(t, "m a\x15", out)
+	assert.Equal(t, "rm a", rejected)
+
+	// commands with path are allowed only if the path is listed
+	p = newSessionPolicy(terminal.Policy{
+		Mode:            terminal.PolicyModeReadOnly,
+		AllowedCommands: []string{"/bin/ls"},
+	}, time.Time{})
+	assert.True(t, p.allowed("/bin/ls -l"))
+	assert.False(t, p.allowed("ls"))
+	assert.False(t, p.allowed("/tmp/bin/ls"))
+}
+
+func TestSessionMapCloseByGrant(t *testing.T) {
+	sessions := SessionMap{Sessions: map[string]Session{
+		"a": {id: "a", grantID: 1},
+		"b": {id: "b", grantID: 1},
+		"c": {id: "c", grantID: 2},
+		"d": {id: "d"},
+	}}
+	assert.Equal(t, 0, sessions.CloseByGrant(0, 3, "revoked"))
+	assert.Equal(t, 2, sessions.CloseByGrant(1, 3, "revoked"))
+	assert.Equal(t, 2, len(sessions.Sessions))
+	assert.Equal(t, "c", sessions.Get("c").id)
+	assert.Equal(t, "d", sessions.Get("d").id)
+}
+
+func TestSessionPolicyExpired(t *testing.T) {
+	assert.Nil(t, newSessionPolicy(terminal.Policy{}, time.Time{}))
+
+	now := time.Now()
+	p := newSessionPolicy(terminal.Policy{IdleTimeout: time.Minute}, now.Add(time.Hour))
+	_, expired := p.expired(now.Add(30 * time.Second))
+	assert.False(t, expired)
+	_, expired = p.expired(now.Add(2 * time.Minute))
+	assert.True(t, expired)
+
+	p.touch(now.Add(2 * time.Minute))
+	_, expired = p.expired(now.Add(2 * time.Minute))
+	assert.False(t, expired)
+	_, expired = p.expired(now.Add(time.Hour))
+	assert.True(t, expired)
+}
@@ -27,7 +27,9 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
+	terminalsessionservice "github.com/horizoncd/horizon/pkg/terminalsession/service"
 	utillog "github.com/horizoncd/horizon/pkg/util/log"
 	"gopkg.in/igm/sockjs-go.v3/sockjs"
 	v1 "k8s.io/api/core/v1"
@@ -53,6 +55,12 @@ type Session struct {
 	sockJSSession sockjs.Session
 	sizeChan      chan remotecommand.TerminalSize
 	doneChan      chan struct{}
+	// recording is nil if terminal recording is disabled
+	recording *terminalsessionservice.Recording
+	// policy is nil if the terminal is unrestricted
+	policy *sessionPolicy
+	// grantID is the JIT access grant which the session is opened with, 0 if no grant is required
+	grantID uint
 }
 
 // Message is the messaging protocol between ShellController and TerminalSession.
@@ -96,8 +104,22 @@ func (t Session) Read(p []byte) (int, error) {
 
 	switch msg.Op {
 	case "stdin":
-		return copy(p, msg.Data), nil
+		if t.recording != nil {
+			t.recording.Input(msg.Data)
+		}
+		data := msg.Data
+		if t.policy != nil {
+			var rejected string
+			t.policy.touch(time.Now())
+			if data, rejected = t.policy.filterInput(msg.Data); rejected != "" {
+				_ = t.Toast(fmt.Sprintf("Command is not allowed: %s", rejected))
+			}
+		}
+		return copy(p, data), nil
 	case "resize":
+		if t.recording != nil {
+			t.recording.Resize(msg.Cols, msg.Rows)
+		}
 		t.sizeChan <- remotecommand.TerminalSize{Width: msg.Cols, Height: msg.Rows}
 		return 0, nil
 	default:
@@ -119,6 +141,9 @@ func (t Session) Write(p []byte) (int, error) {
 	if err = t.sockJSSession.Send(string(msg)); err != nil {
 		return 0, err
 	}
+	if t.recording != nil {
+		t.recording.Output(string(p))
+	}
 	return len(p), nil
 }
 
@@ -179,6 +204,40 @@ func (sm *SessionMap) Close(sessionID string, status uint32, reason string) {
 	delete(sm.Sessions, sessionID)
 }
 
+// CloseByGrant closes the sessions opened with the JIT access grant, and returns the number of closed sessions
+func (sm *SessionMap) CloseByGrant(grantID uint, status uint32, reason string) int {
+	sm.Lock.Lock()
+	defer sm.Lock.Unlock()
+
+	closed := 0
+	for sessionID, session := range sm.Sessions {
+		if grantID == 0 || session.grantID != grantID {
+			continue
+		}
+		if session.sockJSSession != nil {
+			if err := session.sockJSSession.Close(status, reason); err != nil {
+				log.Println(err)
+			}
+		}
+		delete(sm.Sessions, sessionID)
+		closed++
+	}
+	return closed
+}
+
+// Find returns the id of session in cluster with the random id
+func (sm *SessionMap) Find(clusterID uint, randomID string) (string, bool) {
+	sm.Lock.RLock()
+	defer sm.Lock.RUnlock()
+	for sessionID := range sm.Sessions {
+		id, _, _, rid, err := parseSessionID(sessionID)
+		if err == nil && id == clusterID && rid == randomID {
+			return sessionID, true
+		}
+	}
+	return "", false
+}
+
 var terminalSessions = SessionMap{Sessions: make(map[string]Session)}
 
 // handleTerminalSession is Called by net/http for any new /api/sockjs connections
@@ -302,16 +361,30 @@ func WaitForTerminal(k8sClient kubernetes.Interface, cfg *rest.Config, ref Conta
 	<-terminalSessions.Get(ref.String()).bound
 	close(terminalSessions.Get(ref.String()).bound)
 
+	// the session may be removed from terminalSessions when disconnected forcibly
+	session := terminalSessions.Get(ref.String())
+	if session.policy != nil {
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		go watchSession(ref.String(), session.policy, stopCh)
+	}
+
 	var err error
 	validShells := []string{"bash", "sh"}
 
 	for _, testShell := range validShells {
 		cmd := []string{testShell}
-		if err = startProcess(k8sClient, cfg, ref, cmd, terminalSessions.Get(ref.String())); err == nil {
+		if err = startProcess(k8sClient, cfg, ref, cmd, session); err == nil {
 			break
 		}
 	}
 
+	if session.recording != nil {
+		if err := session.recording.Close(context.Background()); err != nil {
+			log.Printf("WaitForTerminal: failed to save recording of session '%s': %v", ref.String(), err)
+		}
+	}
+
 	if err != nil {
 		terminalSessions.Close(ref.String(), 2, err.Error())
 		return
@@ -320,6 +393,23 @@ func WaitForTerminal(k8sClient kubernetes.Interface, cfg *rest.Config, ref Conta
 	terminalSessions.Close(ref.String(), 1, "Process exited")
 }
 
+// watchSession disconnects the session when it's idle or expired
+func watchSession(sessionID string, policy *sessionPolicy, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			if reason, expired := policy.expired(now); expired {
+				terminalSessions.Close(sessionID, 3, reason)
+				return
+			}
+		case <-stopCh:
+			return
+		}
+	}
+}
+
 type ContainerRef struct {
 	Environment string
 	Cluster     string
@@ -21,6 +21,7 @@ import (
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/q"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	mfaservice "github.com/horizoncd/horizon/pkg/mfa/service"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/user/manager"
 	"github.com/horizoncd/horizon/pkg/user/models"
@@ -34,19 +35,22 @@ type Controller interface {
 	UpdateByID(c context.Context, id uint, u *UpdateUserRequest) (*User, error)
 	ListUserLinks(ctx context.Context, uid uint) ([]*Link, error)
 	DeleteLinksByID(c context.Context, id uint) error
-	// LoginWithPasswd checks inputted email & password
-	LoginWithPasswd(ctx context.Context, request *LoginRequest) (*models.User, error)
+	// LoginWithPasswd checks inputted email & password, and the otp if the user enabled mfa.
+	// mfaVerified reports whether the multi-factor authentication is passed in this login.
+	LoginWithPasswd(ctx context.Context, request *LoginRequest) (user *models.User, mfaVerified bool, err error)
 }
 
 type controller struct {
 	userMgr  manager.Manager
 	linksMgr linkmanager.Manager
+	mfaSvc   mfaservice.Service
 }
 
 func NewController(param *param.Param) Controller {
 	return &controller{
 		userMgr:  param.UserMgr,
 		linksMgr: param.UserLinksMgr,
+		mfaSvc:   param.MFASvc,
 	}
 }
 
@@ -147,22 +151,37 @@ func (c *controller) DeleteLinksByID(ctx context.Context, id uint) error {
 	return c.linksMgr.DeleteByID(ctx, id)
 }
 
-func (c *controller) LoginWithPasswd(ctx context.Context, request *LoginRequest) (*models.User, error) {
+func (c *controller) LoginWithPasswd(ctx context.Context, request *LoginRequest) (*models.User, bool, error) {
 	users, err := c.userMgr.ListByEmail(ctx, []string{request.Email})
 	if err != nil {
-		return nil, nil
+		return nil, false, nil
 	}
 	if len(users) < 1 {
-		return nil, perror.Wrapf(herrors.ErrForbidden,
+		return nil, false, perror.Wrapf(herrors.ErrForbidden,
 			"there's no account with email = %v found", request.Email)
 	}
 	if len(users) > 1 {
-		return nil, perror.Wrapf(herrors.ErrDuplicatedKey,
+		return nil, false, perror.Wrapf(herrors.ErrDuplicatedKey,
 			"there's more than one account with email = %v", request.Email)
 	}
 	user := users[0]
 	if user.Password != request.Password {
-		return nil, nil
+		return nil, false, nil
 	}
-	return user, nil
+
+	enabled, err := c.mfaSvc.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	if !enabled {
+		return user, false, nil
+	}
+	if request.OTP == "" {
+		return nil, false, perror.Wrapf(herrors.ErrMFARequired,
+			"otp is required for account with email = %v", request.Email)
+	}
+	if err := c.mfaSvc.Verify(ctx, user.ID, request.OTP); err != nil {
+		return nil, false, err
+	}
+	return user, true, nil
 }
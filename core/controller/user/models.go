@@ -94,4 +94,6 @@ type LoginRequest struct {
 	Email string `json:"email"`
 	// password handled by sha256
 	Password string `json:"password"`
+	// OTP is a totp code or a recovery code, required when the user enabled mfa
+	OTP string `json:"otp,omitempty"`
 }
@@ -22,6 +22,7 @@ import (
 
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/encrypt"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/event/models"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
@@ -100,7 +101,7 @@ func (w *UpdateWebhookRequest) toModel(wm *wmodels.Webhook) *wmodels.Webhook {
 		wm.Description = *w.Description
 	}
 	if w.Secret != nil {
-		wm.Secret = *w.Secret
+		wm.Secret = encrypt.String(*w.Secret)
 	}
 	if len(w.Triggers) > 0 {
 		wm.Triggers = JoinTriggers(w.Triggers)
@@ -129,7 +130,7 @@ func (w *CreateWebhookRequest) toModel(ctx context.Context,
 		URL:              w.URL,
 		SSLVerifyEnabled: w.SSLVerifyEnabled,
 		Description:      w.Description,
-		Secret:           w.Secret,
+		Secret:           encrypt.String(w.Secret),
 		Triggers:         JoinTriggers(w.Triggers),
 	}
 	return wm, nil
@@ -202,7 +203,7 @@ func ofWebhookModel(wm *wmodels.Webhook) *Webhook {
 			URL:              wm.URL,
 			SSLVerifyEnabled: wm.SSLVerifyEnabled,
 			Description:      wm.Description,
-			Secret:           wm.Secret,
+			Secret:           string(wm.Secret),
 			Triggers:         ParseTriggerStr(wm.Triggers),
 		},
 		ID:        wm.ID,
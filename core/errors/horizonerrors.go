@@ -32,6 +32,8 @@ var (
 	ClusterInDB               = sourceType{name: "ClusterInDB"}
 	CollectionInDB            = sourceType{name: "CollectionInDB"}
 	ClusterStateInArgo        = sourceType{name: "ClusterStateInArgo"}
+	ClusterStateInFlux        = sourceType{name: "ClusterStateInFlux"}
+	ClusterStateInHelm        = sourceType{name: "ClusterStateInHelm"}
 	TagInDB                   = sourceType{name: "TagInDB"}
 	BadgeInDB                 = sourceType{name: "BadgeInDB"}
 	ApplicationInArgo         = sourceType{name: "ApplicationInArgo"}
@@ -75,12 +77,30 @@ var (
 	CheckInDB                 = sourceType{name: "CheckInDB"}
 	CheckRunInDB              = sourceType{name: "CheckRunInDB"}
 	PRMessageInDB             = sourceType{name: "PRMessageInDB"}
+	UserMFAInDB               = sourceType{name: "UserMFAInDB"}
+	TeamInDB                  = sourceType{name: "TeamInDB"}
+	TeamMemberInDB            = sourceType{name: "TeamMemberInDB"}
+	CustomRoleInDB            = sourceType{name: "CustomRoleInDB"}
+	GitTriggerInDB            = sourceType{name: "GitTriggerInDB"}
+	PreviewSettingInDB        = sourceType{name: "PreviewSettingInDB"}
+	PreviewClusterInDB        = sourceType{name: "PreviewClusterInDB"}
+	TerminalSessionInDB       = sourceType{name: "TerminalSessionInDB"}
+	TerminalAccessGrantInDB   = sourceType{name: "TerminalAccessGrantInDB"}
+	ClusterSecretInDB         = sourceType{name: "ClusterSecretInDB"}
+	ClusterDriftInDB          = sourceType{name: "ClusterDriftInDB"}
+	QuotaInDB                 = sourceType{name: "QuotaInDB"}
+	ClusterUsageInDB          = sourceType{name: "ClusterUsageInDB"}
+	QuotaReservationInDB      = sourceType{name: "QuotaReservationInDB"}
 
 	// S3
 	PipelinerunLog = sourceType{name: "PipelinerunLog"}
 	PipelinerunObj = sourceType{name: "PipelinerunObj"}
+	// TerminalRecording is the recording of terminal session
+	TerminalRecording = sourceType{name: "TerminalRecording"}
 
 	ArgoCD = sourceType{name: "ArgoCD"}
+	Flux   = sourceType{name: "Flux"}
+	Helm   = sourceType{name: "Helm"}
 
 	Tekton          = sourceType{name: "Tekton"}
 	TektonClient    = sourceType{name: "TektonClient"}
@@ -94,6 +114,7 @@ var (
 	// identity provider
 	Oauth2Token           = sourceType{name: "Oauth2Token"}
 	ProviderFromDiscovery = sourceType{name: "ProviderFromDiscovery"}
+	LDAPServer            = sourceType{name: "LDAPServer"}
 
 	StepInWorkload = sourceType{name: "StepInWorkload"}
 
@@ -225,9 +246,25 @@ var (
 	ErrClusterNoChange                        = errors.New("no change to cluster")
 	ErrShouldBuildDeployFirst                 = errors.New("clusters with build config should build and deploy first")
 	ErrBuildDeployNotSupported                = errors.New("builddeploy is not supported for this cluster")
+	ErrPreviewOfForkNotSupported              = errors.New("preview is not supported for merge requests from forks")
 	ErrFreedClusterNotSupportedRestart        = errors.New("freed cluster is not supported to restart")
 	ErrClusterUnderMaintenanceNoActionAllowed = errors.New("cluster is under maintenance, no action allowed")
 
+	// terminal
+	ErrTerminalDisabled          = errors.New("terminal is disabled in the environment")
+	ErrTerminalAccessNotGranted  = errors.New("terminal access is not granted, please request access first")
+	ErrTerminalAccessNotRequired = errors.New("terminal access is not required in the environment")
+	ErrDebugContainerDisabled    = errors.New("debug containers are disabled")
+	ErrDebugImageNotAllowed      = errors.New("debug image is not allowed")
+
+	// cluster secret
+	ErrSealedSecretsNotConfigured = errors.New("sealed secrets certificate is not configured for the region")
+	ErrClusterSecretExists        = errors.New("cluster secret already exists")
+
+	// quota
+	ErrQuotaExists   = errors.New("quota already exists")
+	ErrQuotaExceeded = errors.New("quota exceeded")
+
 	// pipelinerun
 
 	// context
@@ -264,6 +301,7 @@ var (
 
 	// helm
 	ErrLoadChartArchive = errors.New("failed to load archive")
+	ErrHelmActionFailed = errors.New("helm action failed")
 
 	// group
 	// ErrHasChildren used when delete a group which still has some children
@@ -284,6 +322,14 @@ var (
 	ErrOAuthTokenFormatError       = errors.New("Oauth token format error")
 	ErrOAuthNotGroupOwnerType      = errors.New("not group oauth app")
 
+	// ErrOAuthAuthorizationPending user has not authorized the device yet, ref: rfc8628
+	ErrOAuthAuthorizationPending = errors.New("authorization pending")
+	// ErrOAuthSlowDown device polls too frequently, ref: rfc8628
+	ErrOAuthSlowDown = errors.New("slow down")
+
+	// ErrTokenSourceNotAllowed the source ip of request is not in the allowed networks of the token
+	ErrTokenSourceNotAllowed = errors.New("token is not allowed to be used from this ip")
+
 	// ErrRegistryUsedByRegions used when deleting a registry that is still used by regions
 	ErrRegistryUsedByRegions = errors.New("cannot delete a registry when used by regions")
 
@@ -308,4 +354,13 @@ var (
 
 	// token
 	ErrTokenInvalid = errors.New("token is invalid")
+
+	// mfa
+	ErrMFARequired     = errors.New("multi-factor authentication is required")
+	ErrMFACodeInvalid  = errors.New("multi-factor authentication code is invalid")
+	ErrMFANotEnrolled  = errors.New("multi-factor authentication is not enrolled")
+	ErrMFAAlreadyExist = errors.New("multi-factor authentication is already enabled")
+
+	// ldap
+	ErrLDAPInvalidCredentials = errors.New("ldap username or password is incorrect")
 )
@@ -43,27 +43,43 @@ const (
 	KeyRefreshToken = "refresh_token"
 	KeyClientSecret = "client_secret"
 
-	KeyGrantType          = "grant_type"
-	GrantTypeAuthCode     = "authorization_code"
-	GrantTypeRefreshToken = "refresh_token"
+	KeyCodeChallenge       = "code_challenge"
+	KeyCodeChallengeMethod = "code_challenge_method"
+	KeyCodeVerifier        = "code_verifier"
+	KeyDeviceCode          = "device_code"
+	KeyUserCode            = "user_code"
+
+	KeyGrantType               = "grant_type"
+	GrantTypeAuthCode          = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeDeviceCode        = "urn:ietf:params:oauth:grant-type:device_code"
+
+	// error codes of device authorization grant, ref: rfc8628 3.5
+	ErrorAuthorizationPending = "authorization_pending"
+	ErrorSlowDown             = "slow_down"
+	ErrorExpiredToken         = "expired_token"
 
 	Authorized = "1"
 )
 
 type API struct {
-	oauthAppController oauthapp.Controller
-	oAuthServer        oauth.Controller
-	oauthHTMLLocation  string
-	scopeService       scope.Service
+	oauthAppController    oauthapp.Controller
+	oAuthServer           oauth.Controller
+	oauthHTMLLocation     string
+	deviceVerificationURI string
+	scopeService          scope.Service
 }
 
 func NewAPI(oauthServerController oauth.Controller,
-	oauthAppController oauthapp.Controller, oauthHTMLLocation string, scopeService scope.Service) *API {
+	oauthAppController oauthapp.Controller, oauthHTMLLocation string,
+	deviceVerificationURI string, scopeService scope.Service) *API {
 	return &API{
-		oAuthServer:        oauthServerController,
-		oauthAppController: oauthAppController,
-		oauthHTMLLocation:  oauthHTMLLocation,
-		scopeService:       scopeService,
+		oAuthServer:           oauthServerController,
+		oauthAppController:    oauthAppController,
+		oauthHTMLLocation:     oauthHTMLLocation,
+		deviceVerificationURI: deviceVerificationURI,
+		scopeService:          scopeService,
 	}
 }
 
@@ -80,6 +96,9 @@ type AuthorizationPageParams struct {
 	Scope       string
 	ClientName  string
 	ScopeBasic  []ScopeBasic
+
+	CodeChallenge       string
+	CodeChallengeMethod string
 }
 
 func (a *API) HandleAuthorizationGetReq(c *gin.Context) {
@@ -143,6 +162,9 @@ func (a *API) HandleAuthorizationGetReq(c *gin.Context) {
 		Scope:       c.Query(KeyScope),
 		RedirectURL: c.Query(KeyRedirectURI),
 		ScopeBasic:  scopeInfo(),
+
+		CodeChallenge:       c.Query(KeyCodeChallenge),
+		CodeChallengeMethod: c.Query(KeyCodeChallengeMethod),
 	}
 	authTemplate, err := template.ParseFiles(a.oauthHTMLLocation)
 	if err != nil {
@@ -206,6 +228,9 @@ func (a *API) handlerPostAuthorizationReq(c *gin.Context) {
 			State:        c.PostForm(KeyState),
 			UserIdentity: user.GetID(),
 			Request:      c.Request,
+
+			CodeChallenge:       c.PostForm(KeyCodeChallenge),
+			CodeChallengeMethod: c.PostForm(KeyCodeChallengeMethod),
 		})
 		if err != nil {
 			causeErr := perror.Cause(err)
@@ -241,16 +266,21 @@ func (a *API) HandleAccessTokenReq(c *gin.Context) {
 		return
 	}
 
-	keys := []string{
-		KeyClientID,
-		KeyClientSecret,
-		KeyRedirectURI,
-	}
-	if grantType == GrantTypeAuthCode {
-		keys = append(keys, KeyCode)
-	} else if grantType == GrantTypeRefreshToken {
-		keys = append(keys, KeyRefreshToken)
-	} else {
+	var keys []string
+	switch grantType {
+	case GrantTypeAuthCode:
+		keys = []string{KeyClientID, KeyRedirectURI, KeyCode}
+		// public clients using PKCE do not have client secret
+		if _, ok := c.GetPostForm(KeyCodeVerifier); !ok {
+			keys = append(keys, KeyClientSecret)
+		}
+	case GrantTypeRefreshToken:
+		keys = []string{KeyClientID, KeyClientSecret, KeyRedirectURI, KeyRefreshToken}
+	case GrantTypeClientCredentials:
+		keys = []string{KeyClientID, KeyClientSecret}
+	case GrantTypeDeviceCode:
+		keys = []string{KeyClientID, KeyDeviceCode}
+	default:
 		response.AbortWithRequestError(c, common.InvalidRequestParam, "grant_type not supported")
 		return
 	}
@@ -279,16 +309,28 @@ func (a *API) HandleAccessTokenReq(c *gin.Context) {
 		RedirectURL:  c.PostForm(KeyRedirectURI),
 		Request:      c.Request,
 	}
-	if grantType == GrantTypeAuthCode {
+	switch grantType {
+	case GrantTypeAuthCode:
 		tokenResponse, err = a.oAuthServer.GenAccessToken(c, &oauth.AccessTokenReq{
 			BaseTokenReq: baseTokenReq,
 			Code:         c.PostForm(KeyCode),
+			CodeVerifier: c.PostForm(KeyCodeVerifier),
 		})
-	} else {
+	case GrantTypeRefreshToken:
 		tokenResponse, err = a.oAuthServer.RefreshToken(c, &oauth.RefreshTokenReq{
 			BaseTokenReq: baseTokenReq,
 			RefreshToken: c.PostForm(KeyRefreshToken),
 		})
+	case GrantTypeClientCredentials:
+		tokenResponse, err = a.oAuthServer.ClientCredentials(c, &oauth.ClientCredentialsReq{
+			BaseTokenReq: baseTokenReq,
+			Scope:        c.PostForm(KeyScope),
+		})
+	case GrantTypeDeviceCode:
+		tokenResponse, err = a.oAuthServer.DeviceAccessToken(c, &oauth.DeviceAccessTokenReq{
+			BaseTokenReq: baseTokenReq,
+			DeviceCode:   c.PostForm(KeyDeviceCode),
+		})
 	}
 	if err != nil {
 		causeErr := perror.Cause(err)
@@ -298,8 +340,18 @@ func (a *API) HandleAccessTokenReq(c *gin.Context) {
 			response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
 			return
 		case herrors.ErrOAuthCodeExpired, herrors.ErrOAuthRefreshTokenExpired:
+			if grantType == GrantTypeDeviceCode {
+				response.AbortWithRequestError(c, ErrorExpiredToken, err.Error())
+				return
+			}
 			response.AbortWithUnauthorized(c, common.CodeExpired, err.Error())
 			return
+		case herrors.ErrOAuthAuthorizationPending:
+			response.AbortWithRequestError(c, ErrorAuthorizationPending, err.Error())
+			return
+		case herrors.ErrOAuthSlowDown:
+			response.AbortWithRequestError(c, ErrorSlowDown, err.Error())
+			return
 		default:
 			if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
 				if e.Source == herrors.OAuthInDB || e.Source == herrors.TokenInDB {
@@ -314,3 +366,102 @@ func (a *API) HandleAccessTokenReq(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, tokenResponse)
 }
+
+// DeviceCodeResponse is the device authorization response, ref: rfc8628 3.2
+type DeviceCodeResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+func (a *API) HandleDeviceCodeReq(c *gin.Context) {
+	clientID, ok := c.GetPostForm(KeyClientID)
+	if !ok {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, fmt.Sprintf("%s not exist", KeyClientID))
+		return
+	}
+	resp, err := a.oAuthServer.GenDeviceCode(c, &oauth.DeviceCodeReq{
+		ClientID: clientID,
+		Scope:    c.PostForm(KeyScope),
+		Request:  c.Request,
+	})
+	if err != nil {
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.OAuthInDB {
+			response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
+			return
+		}
+		response.AbortWithInternalError(c, err.Error())
+		log.Error(c, err.Error())
+		return
+	}
+
+	verificationURI := a.deviceVerificationURI
+	if verificationURI == "" {
+		scheme := "http"
+		if c.Request.TLS != nil {
+			scheme = "https"
+		}
+		verificationURI = (&url.URL{Scheme: scheme, Host: c.Request.Host, Path: BasicPath + DevicePath}).String()
+	}
+	q := url.Values{}
+	q.Set(KeyUserCode, resp.UserCode)
+	c.JSON(http.StatusOK, DeviceCodeResponse{
+		DeviceCode:              resp.DeviceCode,
+		UserCode:                resp.UserCode,
+		VerificationURI:         verificationURI,
+		VerificationURIComplete: verificationURI + "?" + q.Encode(),
+		ExpiresIn:               int64(resp.ExpiresIn.Seconds()),
+		Interval:                int64(resp.Interval.Seconds()),
+	})
+}
+
+func (a *API) HandleDeviceAuthorizationGetReq(c *gin.Context) {
+	userCode := c.Query(KeyUserCode)
+	if userCode == "" {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, fmt.Sprintf("%s not exist", KeyUserCode))
+		return
+	}
+	authorization, err := a.oAuthServer.GetDeviceAuthorization(c, userCode)
+	if err != nil {
+		a.abortWithDeviceError(c, err)
+		return
+	}
+	response.SuccessWithData(c, authorization)
+}
+
+func (a *API) HandleDeviceAuthorizationReq(c *gin.Context) {
+	userCode, ok := c.GetPostForm(KeyUserCode)
+	if !ok {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, fmt.Sprintf("%s not exist", KeyUserCode))
+		return
+	}
+	value := c.PostForm(KeyAuthorize)
+	if err := a.oAuthServer.AuthorizeDevice(c, userCode, value == Authorized); err != nil {
+		a.abortWithDeviceError(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) abortWithDeviceError(c *gin.Context, err error) {
+	log.Warning(c, err.Error())
+	switch perror.Cause(err) {
+	case herrors.ErrOAuthCodeExpired:
+		response.AbortWithRequestError(c, common.CodeExpired, err.Error())
+		return
+	case herrors.ErrOAuthReqNotValid:
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		if e.Source == herrors.OAuthInDB || e.Source == herrors.TokenInDB {
+			response.AbortWithNotExistError(c, err.Error())
+			return
+		}
+	}
+	response.AbortWithInternalError(c, err.Error())
+	log.Error(c, err.Error())
+}
@@ -25,6 +25,8 @@ const (
 	BasicPath       = "/login/oauth"
 	AuthorizePath   = "/authorize"
 	AccessTokenPath = "/access_token"
+	DevicePath      = "/device"
+	DeviceCodePath  = "/device/code"
 )
 
 func (a *API) RegisterRoute(engine *gin.Engine) {
@@ -43,6 +45,18 @@ func (a *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     AccessTokenPath,
 			Method:      http.MethodPost,
 			HandlerFunc: a.HandleAccessTokenReq,
+		}, {
+			Pattern:     DeviceCodePath,
+			Method:      http.MethodPost,
+			HandlerFunc: a.HandleDeviceCodeReq,
+		}, {
+			Pattern:     DevicePath,
+			Method:      http.MethodGet,
+			HandlerFunc: a.HandleDeviceAuthorizationGetReq,
+		}, {
+			Pattern:     DevicePath,
+			Method:      http.MethodPost,
+			HandlerFunc: a.HandleDeviceAuthorizationReq,
 		},
 	}
 	route.RegisterRoutes(apiGroup, routes)
@@ -254,8 +254,17 @@ func (a *API) LoginWithPassword(c *gin.Context) {
 		return
 	}
 
-	user, err := a.userCtl.LoginWithPasswd(c, request)
+	user, mfaVerified, err := a.userCtl.LoginWithPasswd(c, request)
 	if err != nil {
+		switch perror.Cause(err) {
+		case herrors.ErrMFARequired:
+			response.AbortWithRPCError(c, rpcerror.MFARequiredError.WithErrMsg(err.Error()))
+			return
+		case herrors.ErrMFACodeInvalid:
+			response.AbortWithRPCError(c,
+				rpcerror.Unauthorized.WithErrMsg("login failed: otp is incorrect!"))
+			return
+		}
 		response.AbortWithRPCError(c,
 			rpcerror.InternalError.WithErrMsg(
 				fmt.Sprintf("login failed, err: %v", err)))
@@ -285,4 +294,12 @@ func (a *API) LoginWithPassword(c *gin.Context) {
 			rpcerror.InternalError.WithErrMsgf("failed to set session: %v", err))
 		return
 	}
+
+	if mfaVerified {
+		if err = util.SetMFAVerified(session, c.Request, c.Writer); err != nil {
+			response.AbortWithRPCError(c,
+				rpcerror.InternalError.WithErrMsgf("failed to set session: %v", err))
+			return
+		}
+	}
 }
@@ -0,0 +1,144 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+func (a *API) ListGitTriggers(c *gin.Context) {
+	const op = "cluster: list git triggers"
+	clusterID, err := strconv.ParseUint(c.Param(common.ParamClusterID), 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	triggers, err := a.clusterCtl.ListGitTriggers(c, uint(clusterID))
+	if err != nil {
+		abortWithGitTriggerError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, triggers)
+}
+
+func (a *API) CreateGitTrigger(c *gin.Context) {
+	const op = "cluster: create git trigger"
+	clusterID, err := strconv.ParseUint(c.Param(common.ParamClusterID), 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	var request cluster.CreateGitTriggerRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestBody,
+			fmt.Sprintf("request body is invalid, err: %v", err))
+		return
+	}
+	trigger, err := a.clusterCtl.CreateGitTrigger(c, uint(clusterID), &request)
+	if err != nil {
+		abortWithGitTriggerError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, trigger)
+}
+
+func (a *API) UpdateGitTrigger(c *gin.Context) {
+	const op = "cluster: update git trigger"
+	clusterID, err := strconv.ParseUint(c.Param(common.ParamClusterID), 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	triggerID, err := strconv.ParseUint(c.Param(common.ParamGitTriggerID), 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	var request cluster.UpdateGitTriggerRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestBody,
+			fmt.Sprintf("request body is invalid, err: %v", err))
+		return
+	}
+	trigger, err := a.clusterCtl.UpdateGitTrigger(c, uint(clusterID), uint(triggerID), &request)
+	if err != nil {
+		abortWithGitTriggerError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, trigger)
+}
+
+func (a *API) DeleteGitTrigger(c *gin.Context) {
+	const op = "cluster: delete git trigger"
+	clusterID, err := strconv.ParseUint(c.Param(common.ParamClusterID), 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	triggerID, err := strconv.ParseUint(c.Param(common.ParamGitTriggerID), 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	if err := a.clusterCtl.DeleteGitTrigger(c, uint(clusterID), uint(triggerID)); err != nil {
+		abortWithGitTriggerError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) HandleGitWebhook(c *gin.Context) {
+	const op = "cluster: handle git webhook"
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestBody, err.Error())
+		return
+	}
+	resp, err := a.clusterCtl.HandleGitWebhook(c, c.Param(common.ParamGitKind), c.Request.Header, body)
+	if err != nil {
+		abortWithGitTriggerError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func abortWithGitTriggerError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	switch perror.Cause(err) {
+	case herrors.ErrParamInvalid, herrors.ErrBuildDeployNotSupported:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrForbidden, herrors.ErrQuotaExceeded:
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -431,6 +432,8 @@ func (a *API) Deploy(c *gin.Context) {
 
 const defaultTailLines = 1000
 
+// GetContainerLog streams logs of a container, logs of all pods are merged with pod name as prefix if podName is empty.
+// Logs can be followed, filtered by keyword and regex, and got from the previous terminated container.
 func (a *API) GetContainerLog(c *gin.Context) {
 	clusterIDStr := c.Param(common.ParamClusterID)
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
@@ -439,7 +442,13 @@ func (a *API) GetContainerLog(c *gin.Context) {
 		return
 	}
 
-	var tailLines int64 = defaultTailLines
+	request := &cluster.ContainerLogRequest{
+		PodName:       c.Query(common.ClusterQueryPodName),
+		ContainerName: c.Query(common.ClusterQueryContainerName),
+		TailLines:     defaultTailLines,
+		Keyword:       c.Query(common.ClusterQueryLogKeyword),
+		Regex:         c.Query(common.ClusterQueryLogRegex),
+	}
 	tailLinesStr := c.Query(common.ClusterQueryTailLines)
 	if tailLinesStr != "" {
 		tailLinesUint64, err := strconv.ParseUint(tailLinesStr, 10, 0)
@@ -447,25 +456,59 @@ func (a *API) GetContainerLog(c *gin.Context) {
 			response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
 			return
 		}
-		tailLines = int64(tailLinesUint64)
+		request.TailLines = int64(tailLinesUint64)
+	}
+	if sinceTimeStr := c.Query(common.ClusterQuerySinceTime); sinceTimeStr != "" {
+		sinceTime, err := time.Parse(time.RFC3339, sinceTimeStr)
+		if err != nil {
+			response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+			return
+		}
+		request.SinceTime = &sinceTime
+		// all logs since the time are returned unless tailLines is specified
+		if tailLinesStr == "" {
+			request.TailLines = 0
+		}
+	}
+	for query, value := range map[string]*bool{
+		common.ClusterQueryFollow:   &request.Follow,
+		common.ClusterQueryPrevious: &request.Previous,
+	} {
+		if str := c.Query(query); str != "" {
+			if *value, err = strconv.ParseBool(str); err != nil {
+				response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+				return
+			}
+		}
 	}
 
-	podName := c.Query(common.ClusterQueryPodName)
-	containerName := c.Query(common.ClusterQueryContainerName)
+	// logs are followed until the client disconnects
+	ctx, cancel := context.WithCancel(c)
+	defer cancel()
+	go func() {
+		select {
+		case <-c.Request.Context().Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
 
-	logC, err := a.clusterCtl.GetContainerLog(c, uint(clusterID), podName, containerName, tailLines)
+	logC, err := a.clusterCtl.StreamContainerLog(ctx, uint(clusterID), request)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+			return
+		}
 		_, _ = c.Writer.Write([]byte(err.Error()))
 		return
 	}
 
-	for logC != nil {
-		l, ok := <-logC
-		if !ok {
-			logC = nil
+	for l := range logC {
+		if _, err := c.Writer.Write([]byte(l)); err != nil {
+			cancel()
 			continue
 		}
-		_, _ = c.Writer.Write([]byte(l))
+		c.Writer.Flush()
 	}
 }
 
@@ -0,0 +1,91 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/cluster"
+	"github.com/horizoncd/horizon/pkg/server/response"
+)
+
+func (a *API) GetPreviewSetting(c *gin.Context) {
+	const op = "cluster: get preview setting"
+	clusterID, err := strconv.ParseUint(c.Param(common.ParamClusterID), 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	setting, err := a.clusterCtl.GetPreviewSetting(c, uint(clusterID))
+	if err != nil {
+		abortWithGitTriggerError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, setting)
+}
+
+func (a *API) UpdatePreviewSetting(c *gin.Context) {
+	const op = "cluster: update preview setting"
+	clusterID, err := strconv.ParseUint(c.Param(common.ParamClusterID), 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	var request cluster.UpdatePreviewSettingRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestBody,
+			fmt.Sprintf("request body is invalid, err: %v", err))
+		return
+	}
+	setting, err := a.clusterCtl.UpdatePreviewSetting(c, uint(clusterID), &request)
+	if err != nil {
+		abortWithGitTriggerError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, setting)
+}
+
+func (a *API) DeletePreviewSetting(c *gin.Context) {
+	const op = "cluster: delete preview setting"
+	clusterID, err := strconv.ParseUint(c.Param(common.ParamClusterID), 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	if err := a.clusterCtl.DeletePreviewSetting(c, uint(clusterID)); err != nil {
+		abortWithGitTriggerError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) ListPreviewClusters(c *gin.Context) {
+	const op = "cluster: list preview clusters"
+	clusterID, err := strconv.ParseUint(c.Param(common.ParamClusterID), 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	clusters, err := a.clusterCtl.ListPreviewClusters(c, uint(clusterID))
+	if err != nil {
+		abortWithGitTriggerError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, clusters)
+}
@@ -139,6 +139,45 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodPost,
 			Pattern:     fmt.Sprintf("/clusters/:%v/pipelineruns", common.ParamClusterID),
 			HandlerFunc: api.CreatePipelineRun,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/gittriggers", common.ParamClusterID),
+			HandlerFunc: api.ListGitTriggers,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/gittriggers", common.ParamClusterID),
+			HandlerFunc: api.CreateGitTrigger,
+		}, {
+			Method: http.MethodPut,
+			Pattern: fmt.Sprintf("/clusters/:%v/gittriggers/:%v",
+				common.ParamClusterID, common.ParamGitTriggerID),
+			HandlerFunc: api.UpdateGitTrigger,
+		}, {
+			Method: http.MethodDelete,
+			Pattern: fmt.Sprintf("/clusters/:%v/gittriggers/:%v",
+				common.ParamClusterID, common.ParamGitTriggerID),
+			HandlerFunc: api.DeleteGitTrigger,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/preview", common.ParamClusterID),
+			HandlerFunc: api.GetPreviewSetting,
+		}, {
+			Method:      http.MethodPut,
+			Pattern:     fmt.Sprintf("/clusters/:%v/preview", common.ParamClusterID),
+			HandlerFunc: api.UpdatePreviewSetting,
+		}, {
+			Method:      http.MethodDelete,
+			Pattern:     fmt.Sprintf("/clusters/:%v/preview", common.ParamClusterID),
+			HandlerFunc: api.DeletePreviewSetting,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/previewclusters", common.ParamClusterID),
+			HandlerFunc: api.ListPreviewClusters,
+		}, {
+			// git hosts send push and merge request events here, requests are verified by the webhook secret
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/gitwebhooks/:%v", common.ParamGitKind),
+			HandlerFunc: api.HandleGitWebhook,
 		},
 	}
 
@@ -0,0 +1,137 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustersecret
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/controller/clustersecret"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	_clusterIDParam  = "clusterID"
+	_secretNameParam = "secretName"
+)
+
+type API struct {
+	secretCtl clustersecret.Controller
+}
+
+func NewAPI(secretCtl clustersecret.Controller) *API {
+	return &API{secretCtl: secretCtl}
+}
+
+func (a *API) List(c *gin.Context) {
+	const op = "cluster secret: list"
+	clusterID, ok := parseClusterID(c)
+	if !ok {
+		return
+	}
+	secrets, err := a.secretCtl.List(c, clusterID)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, secrets)
+}
+
+func (a *API) Create(c *gin.Context) {
+	const op = "cluster secret: create"
+	clusterID, ok := parseClusterID(c)
+	if !ok {
+		return
+	}
+	var request clustersecret.CreateSecretRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid request body, err: %s",
+			err.Error())))
+		return
+	}
+	secret, err := a.secretCtl.Create(c, clusterID, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, secret)
+}
+
+func (a *API) Rotate(c *gin.Context) {
+	const op = "cluster secret: rotate"
+	clusterID, ok := parseClusterID(c)
+	if !ok {
+		return
+	}
+	var request clustersecret.RotateSecretRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid request body, err: %s",
+			err.Error())))
+		return
+	}
+	secret, err := a.secretCtl.Rotate(c, clusterID, c.Param(_secretNameParam), &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, secret)
+}
+
+func (a *API) Delete(c *gin.Context) {
+	const op = "cluster secret: delete"
+	clusterID, ok := parseClusterID(c)
+	if !ok {
+		return
+	}
+	if err := a.secretCtl.Delete(c, clusterID, c.Param(_secretNameParam)); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func parseClusterID(c *gin.Context) (uint, bool) {
+	str := c.Param(_clusterIDParam)
+	id, err := strconv.ParseUint(str, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid cluster id: %s, err: %s",
+			str, err.Error())))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	switch perror.Cause(err) {
+	case herrors.ErrParamInvalid, herrors.ErrSealedSecretsNotConfigured:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrClusterSecretExists:
+		response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
@@ -0,0 +1,95 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dora
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/dora"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+)
+
+const (
+	_environment = "environment"
+	_start       = "start"
+	_end         = "end"
+
+	// _defaultRange is the time range used when start is not specified
+	_defaultRange = 30 * 24 * time.Hour
+)
+
+type API struct {
+	doraCtl dora.Controller
+}
+
+func NewAPI(doraCtl dora.Controller) *API {
+	return &API{doraCtl: doraCtl}
+}
+
+func (a *API) GetMetrics(c *gin.Context) {
+	resourceType := c.Param(common.ParamResourceType)
+	resourceIDStr := c.Param(common.ParamResourceID)
+	resourceID, err := strconv.ParseUint(resourceIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(
+			fmt.Sprintf("invalid resource id: %s, err: %s", resourceIDStr, err.Error())))
+		return
+	}
+
+	query := &dora.Query{
+		Environment: c.Query(_environment),
+		End:         time.Now(),
+	}
+	if endStr := c.Query(_end); endStr != "" {
+		if query.End, err = time.Parse(time.RFC3339, endStr); err != nil {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(
+				fmt.Sprintf("invalid end: %s, err: %s", endStr, err.Error())))
+			return
+		}
+	}
+	query.Start = query.End.Add(-_defaultRange)
+	if startStr := c.Query(_start); startStr != "" {
+		if query.Start, err = time.Parse(time.RFC3339, startStr); err != nil {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(
+				fmt.Sprintf("invalid start: %s, err: %s", startStr, err.Error())))
+			return
+		}
+	}
+
+	metrics, err := a.doraCtl.GetMetrics(c, resourceType, uint(resourceID), query)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		if errors.Is(perror.Cause(err), herrors.ErrParamInvalid) {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(
+			fmt.Sprintf("failed to get dora metrics, err: %s", err.Error())))
+		return
+	}
+	response.SuccessWithData(c, metrics)
+}
@@ -125,6 +125,77 @@ func (a *API) LoginCallback(c *gin.Context) {
 	response.Success(c)
 }
 
+func (a *API) LoginWithLDAP(c *gin.Context) {
+	idpIDStr := c.Param(_idp)
+	idpID, err := strconv.ParseUint(idpIDStr, 10, 64)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg("idp ID is not found or invalid"))
+		return
+	}
+
+	request := &idp.LDAPLoginRequest{}
+	if err := c.ShouldBindJSON(request); err != nil {
+		response.AbortWithRPCError(c,
+			rpcerror.ParamError.WithErrMsg("request body is invalid"))
+		return
+	}
+
+	user, mfaVerified, err := a.idpCtrl.LoginWithLDAP(c, uint(idpID), request)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c,
+				rpcerror.NotFoundError.WithErrMsgf("idp with id = %d was not found", idpID))
+			return
+		}
+		switch perror.Cause(err) {
+		case herrors.ErrParamInvalid:
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		case herrors.ErrLDAPInvalidCredentials:
+			response.AbortWithRPCError(c,
+				rpcerror.Unauthorized.WithErrMsg("login failed: username or password is incorrect!"))
+			return
+		case herrors.ErrForbidden:
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		case herrors.ErrMFARequired:
+			response.AbortWithRPCError(c, rpcerror.MFARequiredError.WithErrMsg(err.Error()))
+			return
+		case herrors.ErrMFACodeInvalid:
+			response.AbortWithRPCError(c,
+				rpcerror.Unauthorized.WithErrMsg("login failed: otp is incorrect!"))
+			return
+		}
+		response.AbortWithRPCError(c,
+			rpcerror.InternalError.WithErrMsgf("login failed, err: %v", err))
+		return
+	}
+
+	session, err := util.GetSession(a.store, c.Request)
+	if err != nil {
+		response.AbortWithRPCError(c,
+			rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+
+	if err = util.SetSession(session, c.Request, c.Writer, user); err != nil {
+		response.AbortWithRPCError(c,
+			rpcerror.InternalError.WithErrMsgf(
+				"saving session into backend or response failed:\n"+
+					"err = %v", err))
+		return
+	}
+	if mfaVerified {
+		if err = util.SetMFAVerified(session, c.Request, c.Writer); err != nil {
+			response.AbortWithRPCError(c,
+				rpcerror.InternalError.WithErrMsgf("failed to set session: %v", err))
+			return
+		}
+	}
+
+	response.Success(c)
+}
+
 func (a *API) Logout(c *gin.Context) {
 	session, err := util.GetSession(a.store, c.Request)
 	if err != nil {
@@ -157,6 +228,10 @@ func (a *API) CreateIDP(c *gin.Context) {
 
 	_, err = a.idpCtrl.Create(c, &createParam)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
 		if _, ok := perror.Cause(err).(*herrors.HorizonErrCreateFailed); ok {
 			response.AbortWithRPCError(
 				c, rpcerror.ParamError.WithErrMsgf("failed to create idp\n"+
@@ -222,6 +297,10 @@ func (a *API) UpdateIDP(c *gin.Context) {
 
 	_, err = a.idpCtrl.Update(c, uint(idpID), updateParam)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
 		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
 			response.AbortWithRPCError(
 				c, rpcerror.NotFoundError.WithErrMsgf("idp with id = %d was not found", idpID),
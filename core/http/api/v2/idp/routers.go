@@ -58,6 +58,11 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodPut,
 			HandlerFunc: api.UpdateIDP,
 		},
+		{
+			Pattern:     fmt.Sprintf("/:%s/login", _idp),
+			Method:      http.MethodPost,
+			HandlerFunc: api.LoginWithLDAP,
+		},
 	}
 	route.RegisterRoutes(apiGroup, routes)
 	engine.GET("/apis/core/v2/login/callback", api.LoginCallback)
@@ -361,7 +361,6 @@ func validMemberType(memberType membermodels.MemberType) error {
 	switch memberType {
 	case membermodels.MemberUser:
 	case membermodels.MemberGroup:
-		return fmt.Errorf("this type of member is not supported yet")
 	default:
 		return fmt.Errorf("invalid memberType")
 	}
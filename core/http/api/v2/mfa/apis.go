@@ -0,0 +1,179 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mfa
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/sessions"
+
+	mfactl "github.com/horizoncd/horizon/core/controller/mfa"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/user/util"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	_userIDParam = "userID"
+)
+
+type API struct {
+	mfaCtl mfactl.Controller
+	store  sessions.Store
+}
+
+func NewAPI(ctl mfactl.Controller, store sessions.Store) *API {
+	return &API{
+		mfaCtl: ctl,
+		store:  store,
+	}
+}
+
+func (a *API) GetStatus(c *gin.Context) {
+	status, err := a.mfaCtl.GetStatus(c)
+	if err != nil {
+		abortWithError(c, "mfa: get status", err)
+		return
+	}
+	response.SuccessWithData(c, status)
+}
+
+func (a *API) Enroll(c *gin.Context) {
+	enrollment, err := a.mfaCtl.Enroll(c)
+	if err != nil {
+		abortWithError(c, "mfa: enroll", err)
+		return
+	}
+	response.SuccessWithData(c, enrollment)
+}
+
+func (a *API) Activate(c *gin.Context) {
+	request, ok := bindCode(c)
+	if !ok {
+		return
+	}
+	codes, err := a.mfaCtl.Activate(c, request.Code)
+	if err != nil {
+		abortWithError(c, "mfa: activate", err)
+		return
+	}
+	// the user has just proved the possession of the device
+	if !a.markVerified(c) {
+		return
+	}
+	response.SuccessWithData(c, codes)
+}
+
+func (a *API) Verify(c *gin.Context) {
+	request, ok := bindCode(c)
+	if !ok {
+		return
+	}
+	if err := a.mfaCtl.Verify(c, request.Code); err != nil {
+		abortWithError(c, "mfa: verify", err)
+		return
+	}
+	if !a.markVerified(c) {
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) Disable(c *gin.Context) {
+	request, ok := bindCode(c)
+	if !ok {
+		return
+	}
+	if err := a.mfaCtl.Disable(c, request.Code); err != nil {
+		abortWithError(c, "mfa: disable", err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) RegenerateRecoveryCodes(c *gin.Context) {
+	request, ok := bindCode(c)
+	if !ok {
+		return
+	}
+	codes, err := a.mfaCtl.RegenerateRecoveryCodes(c, request.Code)
+	if err != nil {
+		abortWithError(c, "mfa: regenerate recovery codes", err)
+		return
+	}
+	response.SuccessWithData(c, codes)
+}
+
+func (a *API) Reset(c *gin.Context) {
+	uid := c.Param(_userIDParam)
+	userID, err := strconv.ParseUint(uid, 10, 64)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsgf("invalid user id: %s", uid))
+		return
+	}
+	if err := a.mfaCtl.Reset(c, uint(userID)); err != nil {
+		abortWithError(c, "mfa: reset", err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) markVerified(c *gin.Context) bool {
+	session, err := util.GetSession(a.store, c.Request)
+	if err != nil {
+		response.AbortWithRPCError(c,
+			rpcerror.InternalError.WithErrMsgf("failed to get session: %v", err))
+		return false
+	}
+	if err := util.SetMFAVerified(session, c.Request, c.Writer); err != nil {
+		response.AbortWithRPCError(c,
+			rpcerror.InternalError.WithErrMsgf("failed to set session: %v", err))
+		return false
+	}
+	return true
+}
+
+func bindCode(c *gin.Context) (*mfactl.CodeRequest, bool) {
+	var request mfactl.CodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.Code == "" {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg("request body is invalid, code is required"))
+		return nil, false
+	}
+	return &request, true
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	switch perror.Cause(err) {
+	case herrors.ErrMFACodeInvalid, herrors.ErrMFANotEnrolled:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrMFAAlreadyExist:
+		response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrForbidden:
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	}
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
@@ -0,0 +1,70 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mfa
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/pkg/server/route"
+)
+
+// RegisterRoute register routes
+func (a *API) RegisterRoute(engine *gin.Engine) {
+	selfGroup := engine.Group("/apis/core/v2/users/self/mfa")
+	var selfRoutes = route.Routes{
+		{
+			Method:      http.MethodGet,
+			HandlerFunc: a.GetStatus,
+		},
+		{
+			Method:      http.MethodDelete,
+			HandlerFunc: a.Disable,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     "/enroll",
+			HandlerFunc: a.Enroll,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     "/activate",
+			HandlerFunc: a.Activate,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     "/verify",
+			HandlerFunc: a.Verify,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     "/recoverycodes",
+			HandlerFunc: a.RegenerateRecoveryCodes,
+		},
+	}
+	route.RegisterRoutes(selfGroup, selfRoutes)
+
+	userGroup := engine.Group("/apis/core/v2/users")
+	var userRoutes = route.Routes{
+		{
+			Method:      http.MethodDelete,
+			Pattern:     fmt.Sprintf("/:%s/mfa", _userIDParam),
+			HandlerFunc: a.Reset,
+		},
+	}
+	route.RegisterRoutes(userGroup, userRoutes)
+}
@@ -0,0 +1,186 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/controller/quota"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	_groupIDParam = "groupID"
+	// _environmentIDParam is the same as the param of environment routes
+	_environmentIDParam = "environment"
+	_quotaIDParam       = "quotaID"
+)
+
+type API struct {
+	quotaCtl quota.Controller
+}
+
+func NewAPI(quotaCtl quota.Controller) *API {
+	return &API{quotaCtl: quotaCtl}
+}
+
+func (a *API) ListOfGroup(c *gin.Context) {
+	const op = "quota: list of group"
+	groupID, ok := parseID(c, _groupIDParam)
+	if !ok {
+		return
+	}
+	quotas, err := a.quotaCtl.ListOfGroup(c, groupID)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, quotas)
+}
+
+func (a *API) ListOfEnvironment(c *gin.Context) {
+	const op = "quota: list of environment"
+	environmentID, ok := parseID(c, _environmentIDParam)
+	if !ok {
+		return
+	}
+	quotas, err := a.quotaCtl.ListOfEnvironment(c, environmentID)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, quotas)
+}
+
+func (a *API) CreateOfGroup(c *gin.Context) {
+	a.create(c, models.ResourceTypeGroup, _groupIDParam)
+}
+
+func (a *API) CreateOfEnvironment(c *gin.Context) {
+	a.create(c, models.ResourceTypeEnvironment, _environmentIDParam)
+}
+
+func (a *API) UpdateOfGroup(c *gin.Context) {
+	a.update(c, models.ResourceTypeGroup, _groupIDParam)
+}
+
+func (a *API) UpdateOfEnvironment(c *gin.Context) {
+	a.update(c, models.ResourceTypeEnvironment, _environmentIDParam)
+}
+
+func (a *API) DeleteOfGroup(c *gin.Context) {
+	a.delete(c, models.ResourceTypeGroup, _groupIDParam)
+}
+
+func (a *API) DeleteOfEnvironment(c *gin.Context) {
+	a.delete(c, models.ResourceTypeEnvironment, _environmentIDParam)
+}
+
+func (a *API) create(c *gin.Context, resourceType, resourceIDParam string) {
+	const op = "quota: create"
+	resourceID, ok := parseID(c, resourceIDParam)
+	if !ok {
+		return
+	}
+	var request quota.CreateQuotaRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid request body, err: %s",
+			err.Error())))
+		return
+	}
+	q, err := a.quotaCtl.Create(c, resourceType, resourceID, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, q)
+}
+
+func (a *API) update(c *gin.Context, resourceType, resourceIDParam string) {
+	const op = "quota: update"
+	resourceID, ok := parseID(c, resourceIDParam)
+	if !ok {
+		return
+	}
+	quotaID, ok := parseID(c, _quotaIDParam)
+	if !ok {
+		return
+	}
+	var request quota.UpdateQuotaRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid request body, err: %s",
+			err.Error())))
+		return
+	}
+	q, err := a.quotaCtl.Update(c, resourceType, resourceID, quotaID, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, q)
+}
+
+func (a *API) delete(c *gin.Context, resourceType, resourceIDParam string) {
+	const op = "quota: delete"
+	resourceID, ok := parseID(c, resourceIDParam)
+	if !ok {
+		return
+	}
+	quotaID, ok := parseID(c, _quotaIDParam)
+	if !ok {
+		return
+	}
+	if err := a.quotaCtl.Delete(c, resourceType, resourceID, quotaID); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func parseID(c *gin.Context, param string) (uint, bool) {
+	str := c.Param(param)
+	id, err := strconv.ParseUint(str, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid %s: %s, err: %s",
+			param, str, err.Error())))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	switch perror.Cause(err) {
+	case herrors.ErrParamInvalid:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrQuotaExists:
+		response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
@@ -0,0 +1,72 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/pkg/server/route"
+)
+
+// RegisterRoute registers routes of quotas
+func (a *API) RegisterRoute(engine *gin.Engine) {
+	apiV2Group := engine.Group("/apis/core/v2")
+	apiV2Routes := route.Routes{
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/groups/:%v/quotas", _groupIDParam),
+			HandlerFunc: a.ListOfGroup,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/groups/:%v/quotas", _groupIDParam),
+			HandlerFunc: a.CreateOfGroup,
+		},
+		{
+			Method:      http.MethodPut,
+			Pattern:     fmt.Sprintf("/groups/:%v/quotas/:%v", _groupIDParam, _quotaIDParam),
+			HandlerFunc: a.UpdateOfGroup,
+		},
+		{
+			Method:      http.MethodDelete,
+			Pattern:     fmt.Sprintf("/groups/:%v/quotas/:%v", _groupIDParam, _quotaIDParam),
+			HandlerFunc: a.DeleteOfGroup,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/environments/:%v/quotas", _environmentIDParam),
+			HandlerFunc: a.ListOfEnvironment,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/environments/:%v/quotas", _environmentIDParam),
+			HandlerFunc: a.CreateOfEnvironment,
+		},
+		{
+			Method:      http.MethodPut,
+			Pattern:     fmt.Sprintf("/environments/:%v/quotas/:%v", _environmentIDParam, _quotaIDParam),
+			HandlerFunc: a.UpdateOfEnvironment,
+		},
+		{
+			Method:      http.MethodDelete,
+			Pattern:     fmt.Sprintf("/environments/:%v/quotas/:%v", _environmentIDParam, _quotaIDParam),
+			HandlerFunc: a.DeleteOfEnvironment,
+		},
+	}
+	route.RegisterRoutes(apiV2Group, apiV2Routes)
+}
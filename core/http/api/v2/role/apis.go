@@ -16,8 +16,13 @@ package role
 
 import (
 	"github.com/gin-gonic/gin"
+
 	"github.com/horizoncd/horizon/core/controller/role"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
 )
 
 type API struct {
@@ -36,3 +41,76 @@ func (a *API) ListRole(c *gin.Context) {
 	}
 	response.SuccessWithData(c, roles)
 }
+
+func (a *API) GetRole(c *gin.Context) {
+	const op = "role: get"
+	r, err := a.roleCtrl.GetRole(c, c.Param(_roleParam))
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, r)
+}
+
+func (a *API) CreateRole(c *gin.Context) {
+	const op = "role: create"
+	var request role.CreateRoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+
+	r, err := a.roleCtrl.CreateRole(c, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, r)
+}
+
+func (a *API) UpdateRole(c *gin.Context) {
+	const op = "role: update"
+	var request role.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+
+	r, err := a.roleCtrl.UpdateRole(c, c.Param(_roleParam), &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, r)
+}
+
+func (a *API) DeleteRole(c *gin.Context) {
+	const op = "role: delete"
+	if err := a.roleCtrl.DeleteRole(c, c.Param(_roleParam)); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	switch perror.Cause(err) {
+	case herrors.ErrParamInvalid:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrNameConflict:
+		response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrForbidden:
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	}
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
@@ -0,0 +1,219 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package team
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	teamctl "github.com/horizoncd/horizon/core/controller/team"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+type API struct {
+	teamCtl teamctl.Controller
+}
+
+func NewAPI(ctl teamctl.Controller) *API {
+	return &API{
+		teamCtl: ctl,
+	}
+}
+
+func (a *API) Create(c *gin.Context) {
+	const op = "team: create"
+	var request teamctl.CreateTeamRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+
+	team, err := a.teamCtl.Create(c, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, team)
+}
+
+func (a *API) Get(c *gin.Context) {
+	const op = "team: get"
+	id, ok := parseUintParam(c, _teamIDParam)
+	if !ok {
+		return
+	}
+
+	team, err := a.teamCtl.GetByID(c, id)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, team)
+}
+
+func (a *API) List(c *gin.Context) {
+	const op = "team: list"
+	keywords := q.KeyWords{}
+	if filter := c.Query(common.TeamQueryName); filter != "" {
+		keywords[common.TeamQueryName] = filter
+	}
+	if idpIDStr := c.Query(common.TeamQueryIdpID); idpIDStr != "" {
+		idpID, err := strconv.ParseUint(idpIDStr, 10, 0)
+		if err != nil {
+			response.AbortWithRPCError(c, rpcerror.ParamError.
+				WithErrMsgf("invalid idp id: %s", idpIDStr))
+			return
+		}
+		keywords[common.TeamQueryIdpID] = uint(idpID)
+	}
+
+	query := q.New(keywords).WithPagination(c)
+	teams, total, err := a.teamCtl.List(c, query)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Items: teams,
+		Total: total,
+	})
+}
+
+func (a *API) Update(c *gin.Context) {
+	const op = "team: update"
+	id, ok := parseUintParam(c, _teamIDParam)
+	if !ok {
+		return
+	}
+	var request teamctl.UpdateTeamRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+
+	team, err := a.teamCtl.Update(c, id, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, team)
+}
+
+func (a *API) Delete(c *gin.Context) {
+	const op = "team: delete"
+	id, ok := parseUintParam(c, _teamIDParam)
+	if !ok {
+		return
+	}
+
+	if err := a.teamCtl.Delete(c, id); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) ListMembers(c *gin.Context) {
+	const op = "team: list members"
+	id, ok := parseUintParam(c, _teamIDParam)
+	if !ok {
+		return
+	}
+
+	members, err := a.teamCtl.ListMembers(c, id)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, members)
+}
+
+func (a *API) AddMembers(c *gin.Context) {
+	const op = "team: add members"
+	id, ok := parseUintParam(c, _teamIDParam)
+	if !ok {
+		return
+	}
+	var request teamctl.AddMembersRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+
+	if err := a.teamCtl.AddMembers(c, id, &request); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) RemoveMember(c *gin.Context) {
+	const op = "team: remove member"
+	id, ok := parseUintParam(c, _teamIDParam)
+	if !ok {
+		return
+	}
+	userID, ok := parseUintParam(c, _userIDParam)
+	if !ok {
+		return
+	}
+
+	if err := a.teamCtl.RemoveMember(c, id, userID); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func parseUintParam(c *gin.Context, name string) (uint, bool) {
+	str := c.Param(name)
+	id, err := strconv.ParseUint(str, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid %s: %s", name, str))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	switch perror.Cause(err) {
+	case herrors.ErrParamInvalid:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrNameConflict:
+		response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrForbidden:
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	}
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
@@ -0,0 +1,74 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package team
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/pkg/server/route"
+)
+
+const (
+	_teamIDParam = "teamID"
+	_userIDParam = "userID"
+)
+
+func (api *API) RegisterRoute(engine *gin.Engine) {
+	group := engine.Group("/apis/core/v2/teams")
+	var routers = route.Routes{
+		{
+			Method:      http.MethodGet,
+			HandlerFunc: api.List,
+		},
+		{
+			Method:      http.MethodPost,
+			HandlerFunc: api.Create,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/:%v", _teamIDParam),
+			HandlerFunc: api.Get,
+		},
+		{
+			Method:      http.MethodPut,
+			Pattern:     fmt.Sprintf("/:%v", _teamIDParam),
+			HandlerFunc: api.Update,
+		},
+		{
+			Method:      http.MethodDelete,
+			Pattern:     fmt.Sprintf("/:%v", _teamIDParam),
+			HandlerFunc: api.Delete,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/:%v/members", _teamIDParam),
+			HandlerFunc: api.ListMembers,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/:%v/members", _teamIDParam),
+			HandlerFunc: api.AddMembers,
+		},
+		{
+			Method:      http.MethodDelete,
+			Pattern:     fmt.Sprintf("/:%v/members/:%v", _teamIDParam, _userIDParam),
+			HandlerFunc: api.RemoveMember,
+		},
+	}
+	route.RegisterRoutes(group, routers)
+}
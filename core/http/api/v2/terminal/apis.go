@@ -16,12 +16,16 @@ package terminal
 
 import (
 	"fmt"
+	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/controller/terminal"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/request"
 	"github.com/horizoncd/horizon/pkg/server/response"
 	"github.com/horizoncd/horizon/pkg/server/rpcerror"
 	"github.com/horizoncd/horizon/pkg/util/log"
@@ -31,6 +35,11 @@ const (
 	_clusterIDParam     = "clusterID"
 	_podNameQuery       = "podName"
 	_containerNameQuery = "containerName"
+	_sessionIDParam     = "sessionID"
+	_grantIDParam       = "grantID"
+	_userIDQuery        = "userID"
+
+	_contentTypeAsciicast = "application/x-asciicast"
 )
 
 type API struct {
@@ -63,6 +72,11 @@ func (a *API) CreateShell(c *gin.Context) {
 				return
 			}
 		}
+		if cause := perror.Cause(err); cause == herrors.ErrTerminalDisabled ||
+			cause == herrors.ErrTerminalAccessNotGranted {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		log.WithFiled(c, "op", op).Errorf("%+v", err)
 		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
 		return
@@ -78,3 +92,231 @@ func (a *API) CreateShell(c *gin.Context) {
 	c.Request.URL.Path = fmt.Sprintf("/apis/core/v2/0/%s/websocket", sessionID)
 	sockJS.ServeHTTP(c.Writer, c.Request)
 }
+
+func (a *API) ListSessions(c *gin.Context) {
+	const op = "terminal: list sessions"
+	clusterIDStr := c.Param(_clusterIDParam)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid cluster id: %s, "+
+			"err: %s", clusterIDStr, err.Error())))
+		return
+	}
+
+	pageNumber, pageSize, err := request.GetPageParam(c)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	keywords := q.KeyWords{}
+	if userIDStr := c.Query(_userIDQuery); userIDStr != "" {
+		userID, err := strconv.ParseUint(userIDStr, 10, 0)
+		if err != nil {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid user id: %s", userIDStr)))
+			return
+		}
+		keywords[common.TerminalSessionQueryByUser] = uint(userID)
+	}
+	if podName := c.Query(_podNameQuery); podName != "" {
+		keywords[common.TerminalSessionQueryByPod] = podName
+	}
+	if containerName := c.Query(_containerNameQuery); containerName != "" {
+		keywords[common.TerminalSessionQueryByContainer] = containerName
+	}
+
+	total, sessions, err := a.terminalCtl.ListSessions(c, uint(clusterID), &q.Query{
+		PageNumber: pageNumber,
+		PageSize:   pageSize,
+		Keywords:   keywords,
+	})
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Total: total,
+		Items: sessions,
+	})
+}
+
+// GetSessionRecording returns the recording in asciicast format, which can be replayed by asciinema player
+func (a *API) GetSessionRecording(c *gin.Context) {
+	const op = "terminal: get session recording"
+	clusterIDStr := c.Param(_clusterIDParam)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid cluster id: %s, "+
+			"err: %s", clusterIDStr, err.Error())))
+		return
+	}
+	sessionIDStr := c.Param(_sessionIDParam)
+	sessionID, err := strconv.ParseUint(sessionIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid session id: %s, "+
+			"err: %s", sessionIDStr, err.Error())))
+		return
+	}
+
+	recording, err := a.terminalCtl.GetSessionRecording(c, uint(clusterID), uint(sessionID))
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	c.Data(http.StatusOK, _contentTypeAsciicast, recording)
+}
+
+func (a *API) Disconnect(c *gin.Context) {
+	const op = "terminal: disconnect"
+	clusterID, ok := a.parseUintParam(c, _clusterIDParam)
+	if !ok {
+		return
+	}
+	if err := a.terminalCtl.Disconnect(c, clusterID, c.Param(_sessionIDParam)); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) RequestAccess(c *gin.Context) {
+	const op = "terminal: request access"
+	clusterID, ok := a.parseUintParam(c, _clusterIDParam)
+	if !ok {
+		return
+	}
+	var request terminal.AccessRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid request body, err: %s",
+			err.Error())))
+		return
+	}
+	grant, err := a.terminalCtl.RequestAccess(c, clusterID, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, grant)
+}
+
+func (a *API) ListAccessGrants(c *gin.Context) {
+	const op = "terminal: list access grants"
+	clusterID, ok := a.parseUintParam(c, _clusterIDParam)
+	if !ok {
+		return
+	}
+	pageNumber, pageSize, err := request.GetPageParam(c)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	total, grants, err := a.terminalCtl.ListAccessGrants(c, clusterID, &q.Query{
+		PageNumber: pageNumber,
+		PageSize:   pageSize,
+	})
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Total: total,
+		Items: grants,
+	})
+}
+
+func (a *API) ApproveAccess(c *gin.Context) {
+	a.reviewAccess(c, "terminal: approve access", func(clusterID, grantID uint) error {
+		return a.terminalCtl.ApproveAccess(c, clusterID, grantID, true)
+	})
+}
+
+func (a *API) RejectAccess(c *gin.Context) {
+	a.reviewAccess(c, "terminal: reject access", func(clusterID, grantID uint) error {
+		return a.terminalCtl.ApproveAccess(c, clusterID, grantID, false)
+	})
+}
+
+func (a *API) RevokeAccess(c *gin.Context) {
+	a.reviewAccess(c, "terminal: revoke access", func(clusterID, grantID uint) error {
+		return a.terminalCtl.RevokeAccess(c, clusterID, grantID)
+	})
+}
+
+func (a *API) reviewAccess(c *gin.Context, op string, review func(clusterID, grantID uint) error) {
+	clusterID, ok := a.parseUintParam(c, _clusterIDParam)
+	if !ok {
+		return
+	}
+	grantID, ok := a.parseUintParam(c, _grantIDParam)
+	if !ok {
+		return
+	}
+	if err := review(clusterID, grantID); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+// CreateDebugContainer attaches a debug container to pod, and returns the container to create shell with
+func (a *API) CreateDebugContainer(c *gin.Context) {
+	const op = "terminal: create debug container"
+	clusterID, ok := a.parseUintParam(c, _clusterIDParam)
+	if !ok {
+		return
+	}
+	var request terminal.DebugContainerRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid request body, err: %s",
+			err.Error())))
+		return
+	}
+	if request.PodName == "" || request.TargetContainer == "" {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg("podName and targetContainer are required"))
+		return
+	}
+	container, err := a.terminalCtl.CreateDebugContainer(c, clusterID, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, container)
+}
+
+func (a *API) parseUintParam(c *gin.Context, param string) (uint, bool) {
+	str := c.Param(param)
+	id, err := strconv.ParseUint(str, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("invalid %s: %s, err: %s",
+			param, str, err.Error())))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	switch perror.Cause(err) {
+	case herrors.ErrNoPrivilege, herrors.ErrTerminalDisabled, herrors.ErrTerminalAccessNotGranted,
+		herrors.ErrDebugContainerDisabled, herrors.ErrDebugImageNotAllowed:
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrParamInvalid, herrors.ErrTerminalAccessNotRequired:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
@@ -32,6 +32,52 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     fmt.Sprintf("/clusters/:%v/shell", _clusterIDParam),
 			HandlerFunc: api.CreateShell,
 		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/terminalsessions", _clusterIDParam),
+			HandlerFunc: api.ListSessions,
+		},
+		{
+			Method: http.MethodGet,
+			Pattern: fmt.Sprintf("/clusters/:%v/terminalsessions/:%v/recording",
+				_clusterIDParam, _sessionIDParam),
+			HandlerFunc: api.GetSessionRecording,
+		},
+		{
+			Method:      http.MethodDelete,
+			Pattern:     fmt.Sprintf("/clusters/:%v/shell/:%v", _clusterIDParam, _sessionIDParam),
+			HandlerFunc: api.Disconnect,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/debugcontainers", _clusterIDParam),
+			HandlerFunc: api.CreateDebugContainer,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/terminalaccess", _clusterIDParam),
+			HandlerFunc: api.RequestAccess,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/terminalaccess", _clusterIDParam),
+			HandlerFunc: api.ListAccessGrants,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/terminalaccess/:%v/approve", _clusterIDParam, _grantIDParam),
+			HandlerFunc: api.ApproveAccess,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/terminalaccess/:%v/reject", _clusterIDParam, _grantIDParam),
+			HandlerFunc: api.RejectAccess,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/terminalaccess/:%v/revoke", _clusterIDParam, _grantIDParam),
+			HandlerFunc: api.RevokeAccess,
+		},
 	}
 	route.RegisterRoutes(coreGroup, coreRoutes)
 }
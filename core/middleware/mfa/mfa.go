@@ -0,0 +1,141 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mfa
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/sessions"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware"
+	usermiddle "github.com/horizoncd/horizon/core/middleware/user"
+	"github.com/horizoncd/horizon/pkg/auth"
+	"github.com/horizoncd/horizon/pkg/config/authenticate"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	mfaservice "github.com/horizoncd/horizon/pkg/mfa/service"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/user/util"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	// HTTPHeaderOTP carries a totp code or a recovery code to pass the step-up verification in place
+	HTTPHeaderOTP = "X-Horizon-OTP"
+
+	_defaultStepUpExpireIn = 5 * time.Minute
+)
+
+// Middleware requires a recent multi-factor authentication for admins (if configured)
+// and for the sensitive operations. Requests authenticated by tokens or access keys
+// are not interactive logins, so they are governed by scopes instead.
+func Middleware(mfaSvc mfaservice.Service, store sessions.Store,
+	config authenticate.MFAConfig, skippers ...middleware.Skipper) gin.HandlerFunc {
+	operations := config.StepUpOperations
+	if len(operations) == 0 {
+		operations = authenticate.DefaultStepUpOperations
+	}
+	expireIn := config.StepUpExpireIn
+	if expireIn == 0 {
+		expireIn = _defaultStepUpExpireIn
+	}
+
+	return middleware.New(func(c *gin.Context) {
+		if _, err := common.GetToken(c); err == nil ||
+			c.Request.Header.Get(usermiddle.HTTPHeaderOperator) != "" {
+			c.Next()
+			return
+		}
+		currentUser, err := common.UserFromContext(c)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		adminRequired := config.AdminRequired && currentUser.IsAdmin()
+		if !adminRequired && !isStepUpOperation(c, operations) {
+			c.Next()
+			return
+		}
+
+		enabled, err := mfaSvc.IsEnabled(c, currentUser.GetID())
+		if err != nil {
+			response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+			return
+		}
+		if !enabled {
+			if adminRequired || config.StepUpEnrollRequired {
+				response.AbortWithRPCError(c, rpcerror.MFARequiredError.
+					WithErrMsg("multi-factor authentication must be enrolled for this operation"))
+				return
+			}
+			c.Next()
+			return
+		}
+
+		// 1. verify the code in header
+		if code := c.Request.Header.Get(HTTPHeaderOTP); code != "" {
+			if err := mfaSvc.Verify(c, currentUser.GetID(), code); err != nil {
+				if perror.Cause(err) == herrors.ErrMFACodeInvalid {
+					response.AbortWithRPCError(c, rpcerror.MFARequiredError.WithErrMsg(err.Error()))
+					return
+				}
+				response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+				return
+			}
+			c.Next()
+			return
+		}
+
+		// 2. check the verification in session
+		session, err := util.GetSession(store, c.Request)
+		if err == nil {
+			verifiedAt := util.MFAVerifiedAt(session)
+			// admins are verified once per login, others once per sensitive operation window
+			if adminRequired && !verifiedAt.IsZero() && !isStepUpOperation(c, operations) {
+				c.Next()
+				return
+			}
+			if time.Since(verifiedAt) <= expireIn {
+				c.Next()
+				return
+			}
+		}
+
+		log.Infof(c, "user %s needs to pass multi-factor authentication", currentUser.String())
+		response.AbortWithRPCError(c, rpcerror.MFARequiredError.
+			WithErrMsgf("multi-factor authentication is required, verify it or set the %s header", HTTPHeaderOTP))
+	}, skippers...)
+}
+
+func isStepUpOperation(c *gin.Context, operations []authenticate.Operation) bool {
+	record, ok := c.Get(common.ContextAuthRecord)
+	if !ok {
+		return false
+	}
+	attr := record.(auth.AttributesRecord)
+	if !attr.IsResourceRequest() {
+		return false
+	}
+	for i := range operations {
+		if operations[i].Match(attr.GetResource(), attr.GetSubResource(), attr.GetVerb()) {
+			return true
+		}
+	}
+	return false
+}
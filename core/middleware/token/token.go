@@ -49,7 +49,8 @@ func MiddleWare(oauthCtl oauthcheck.Controller, skipMatchers ...middleware.Skipp
 		}
 
 		// 2. check token valid
-		if err := oauthCtl.ValidateToken(c, token); err != nil {
+		tokenModel, err := oauthCtl.ValidateToken(c, token)
+		if err != nil {
 			if perror.Cause(err) == herrors.ErrOAuthAccessTokenExpired {
 				response.AbortWithUnauthorized(c, common.CodeExpired, err.Error())
 				return
@@ -62,7 +63,17 @@ func MiddleWare(oauthCtl oauthcheck.Controller, skipMatchers ...middleware.Skipp
 			return
 		}
 
-		// 3. do scope check(get requestInfo, and do check)
+		// 3. check source ip of the token and record the usage
+		if err := oauthCtl.CheckTokenSource(c, tokenModel, c.ClientIP()); err != nil {
+			if perror.Cause(err) == herrors.ErrTokenSourceNotAllowed {
+				response.AbortWithForbiddenError(c, common.Forbidden, err.Error())
+				return
+			}
+			response.AbortWithInternalError(c, err.Error())
+			return
+		}
+
+		// 4. do scope check(get requestInfo, and do check)
 		user, err := oauthCtl.LoadAccessTokenUser(c, token)
 		if err != nil {
 			if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
@@ -0,0 +1,90 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/auth"
+	"github.com/horizoncd/horizon/pkg/authentication/user"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+)
+
+// fakeOauthChecker rejects every token source, and records the source ip and the checked token
+type fakeOauthChecker struct {
+	sourceIP     string
+	checkedToken *tokenmodels.Token
+}
+
+var _fakeToken = &tokenmodels.Token{Code: "token"}
+
+func (f *fakeOauthChecker) ValidateToken(context.Context, string) (*tokenmodels.Token, error) {
+	return _fakeToken, nil
+}
+
+func (f *fakeOauthChecker) LoadAccessTokenUser(context.Context, string) (user.User, error) {
+	return nil, nil
+}
+
+func (f *fakeOauthChecker) CheckScopePermission(context.Context, string, auth.RequestInfo) (bool, string, error) {
+	return false, "", nil
+}
+
+func (f *fakeOauthChecker) CheckTokenSource(_ context.Context, token *tokenmodels.Token, ip string) error {
+	f.sourceIP = ip
+	f.checkedToken = token
+	return herrors.ErrTokenSourceNotAllowed
+}
+
+func TestMiddlewareSourceIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	request := func(trustedProxies []string) string {
+		checker := &fakeOauthChecker{}
+		r := gin.New()
+		if len(trustedProxies) > 0 {
+			assert.Nil(t, r.SetTrustedProxies(trustedProxies))
+		}
+		r.Use(MiddleWare(checker))
+		r.GET("/apis/core/v2/users/self", func(c *gin.Context) {})
+
+		req := httptest.NewRequest(http.MethodGet, "/apis/core/v2/users/self", nil)
+		req.RemoteAddr = "192.0.2.1:52000"
+		req.Header.Set(common.AuthorizationHeaderKey, common.TokenHeaderValuePrefix+" token")
+		req.Header.Set("X-Forwarded-For", "10.0.0.1")
+		req.Header.Set("X-Real-IP", "10.0.0.1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		// the token loaded by validation is reused
+		assert.Equal(t, _fakeToken, checker.checkedToken)
+		return checker.sourceIP
+	}
+
+	// all proxies are trusted if unset, as gin does by default
+	assert.Equal(t, "10.0.0.1", request(nil))
+	// headers sent by clients directly are spoofed
+	assert.Equal(t, "192.0.2.1", request([]string{"198.51.100.0/24"}))
+	// headers set by a trusted proxy are respected
+	assert.Equal(t, "10.0.0.1", request([]string{"192.0.2.0/24"}))
+}
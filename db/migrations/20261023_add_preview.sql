@@ -0,0 +1,50 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- preview settings of base clusters, a preview cluster is created for each matched merge request
+CREATE TABLE `tb_preview_setting`
+(
+    `id`                    bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `cluster_id`            bigint(20) unsigned NOT NULL COMMENT 'base cluster id',
+    `target_branch_pattern` varchar(256)        NOT NULL DEFAULT '' COMMENT 'glob pattern of target branches, empty means all',
+    `expire_time`           varchar(64)         NOT NULL DEFAULT '' COMMENT 'lifetime of preview clusters, empty means never expire',
+    `enabled`               tinyint(1)          NOT NULL DEFAULT '1' COMMENT 'whether the preview is enabled',
+    `created_at`            datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`            datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`            bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`            bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`            bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_cluster_id_deleted_ts` (`cluster_id`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- preview clusters created for merge requests
+CREATE TABLE `tb_preview_cluster`
+(
+    `id`               bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `base_cluster_id`  bigint(20) unsigned NOT NULL COMMENT 'base cluster id',
+    `cluster_id`       bigint(20) unsigned NOT NULL COMMENT 'preview cluster id',
+    `merge_request_id` bigint(20)          NOT NULL COMMENT 'project-scoped number of merge request',
+    `source_branch`    varchar(256)        NOT NULL DEFAULT '' COMMENT 'source branch of merge request',
+    `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`       bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`       bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_base_cluster_merge_request` (`base_cluster_id`, `merge_request_id`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,37 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- recorded terminal sessions, the recordings are stored in s3
+CREATE TABLE `tb_terminal_session`
+(
+    `id`          bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `session_id`  varchar(64)         NOT NULL DEFAULT '' COMMENT 'random id of terminal session',
+    `cluster_id`  bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+    `environment` varchar(128)        NOT NULL DEFAULT '' COMMENT 'environment of cluster',
+    `pod`         varchar(256)        NOT NULL DEFAULT '' COMMENT 'pod name',
+    `container`   varchar(256)        NOT NULL DEFAULT '' COMMENT 'container name',
+    `user_id`     bigint(20) unsigned NOT NULL COMMENT 'user who opened the terminal',
+    `object`      varchar(1024)       NOT NULL DEFAULT '' COMMENT 's3 object of the recording',
+    `size`        bigint(20)          NOT NULL DEFAULT '0' COMMENT 'size of the recording in bytes',
+    `started_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `finished_at` datetime                     DEFAULT NULL,
+    `created_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_cluster_pod` (`cluster_id`, `pod`),
+    KEY `idx_user_id` (`user_id`),
+    KEY `idx_started_at` (`started_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
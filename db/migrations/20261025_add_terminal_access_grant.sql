@@ -0,0 +1,32 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- just-in-time terminal access of clusters in environments with jit terminal policy
+CREATE TABLE `tb_terminal_access_grant`
+(
+    `id`          bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `cluster_id`  bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+    `user_id`     bigint(20) unsigned NOT NULL COMMENT 'user who requested the access',
+    `reason`      varchar(1024)       NOT NULL DEFAULT '' COMMENT 'reason of the request',
+    `duration`    bigint(20)          NOT NULL DEFAULT '0' COMMENT 'requested duration in seconds',
+    `status`      varchar(32)         NOT NULL DEFAULT 'pending' COMMENT 'pending, approved, rejected or revoked',
+    `approver_id` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'user who approved or rejected the request',
+    `expires_at`  datetime                     DEFAULT NULL COMMENT 'when the approved access expires',
+    `created_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_cluster_user` (`cluster_id`, `user_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
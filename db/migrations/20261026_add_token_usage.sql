@@ -0,0 +1,20 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- last usage, source restriction and expiry notification of tokens
+ALTER TABLE `tb_token`
+    ADD COLUMN `allowed_cidrs`      varchar(1024) NOT NULL DEFAULT '' COMMENT 'comma separated networks the token can be used from',
+    ADD COLUMN `last_used_at`       datetime               DEFAULT NULL COMMENT 'when the token was used last time',
+    ADD COLUMN `last_used_ip`       varchar(64)   NOT NULL DEFAULT '' COMMENT 'source ip of the last usage',
+    ADD COLUMN `expiry_notified_at` datetime               DEFAULT NULL COMMENT 'when the owner was notified of the expiration';
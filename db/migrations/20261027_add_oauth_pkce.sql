@@ -0,0 +1,18 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- PKCE challenge of oauth authorization codes
+ALTER TABLE `tb_token`
+    ADD COLUMN `code_challenge`        varchar(128) NOT NULL DEFAULT '' COMMENT 'pkce code challenge',
+    ADD COLUMN `code_challenge_method` varchar(16)  NOT NULL DEFAULT '' COMMENT 'pkce code challenge method, plain or S256';
@@ -0,0 +1,20 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+
+-- encrypted credentials are longer than plaintext ones
+ALTER TABLE `tb_registry`
+    MODIFY COLUMN `token` text NOT NULL COMMENT 'harbor server token, encrypted if encryption is enabled';
+ALTER TABLE `tb_identity_provider`
+    MODIFY COLUMN `client_secret` text NOT NULL COMMENT 'client secret issued by idp, encrypted if encryption is enabled';
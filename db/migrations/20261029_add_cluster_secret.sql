@@ -0,0 +1,36 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+
+-- sealed secrets certificate of regions, which cluster secrets are sealed with
+ALTER TABLE `tb_region`
+    ADD COLUMN `sealed_secrets_certificate` text COMMENT 'pem encoded certificate of the sealed secrets controller';
+
+-- cluster secrets, only sealed values are stored
+CREATE TABLE `tb_cluster_secret`
+(
+    `id`           bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `cluster_id`   bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+    `name`         varchar(253)        NOT NULL COMMENT 'name of the secret, which is the key in the kubernetes secret',
+    `sealed_value` text                NOT NULL COMMENT 'value sealed with the sealed secrets certificate of the region',
+    `version`      bigint(20) unsigned NOT NULL DEFAULT '1' COMMENT 'version of the value, increased on rotation',
+    `created_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `created_by`   bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`   bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'user who rotated the value last time',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_cluster_name` (`cluster_id`, `name`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
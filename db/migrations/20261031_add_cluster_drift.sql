@@ -0,0 +1,31 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+
+-- latest configuration drift detected of clusters
+CREATE TABLE `tb_cluster_drift`
+(
+    `id`          bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `cluster_id`  bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+    `drifted`     tinyint(1)          NOT NULL DEFAULT '0' COMMENT 'whether live objects differ from the desired state',
+    `resources`   text COMMENT 'json encoded drifted resources and their field diff',
+    `detected_at` datetime                     DEFAULT NULL COMMENT 'time drift was detected first',
+    `checked_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'time of the last detection',
+    `created_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_cluster_id` (`cluster_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,51 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+
+-- quotas of groups and environments, zero or empty limits are unlimited
+CREATE TABLE `tb_quota`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `resource_type` varchar(32)         NOT NULL COMMENT 'groups or environments',
+    `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'id of the group or environment',
+    `environment`   varchar(128)        NOT NULL DEFAULT '' COMMENT 'environment restricted by a group quota, empty for all',
+    `max_clusters`  int(11)             NOT NULL DEFAULT '0' COMMENT 'max number of clusters',
+    `max_cpu`       varchar(64)         NOT NULL DEFAULT '' COMMENT 'max total cpu requests of clusters, such as 16',
+    `max_memory`    varchar(64)         NOT NULL DEFAULT '' COMMENT 'max total memory requests of clusters, such as 32Gi',
+    `max_replicas`  int(11)             NOT NULL DEFAULT '0' COMMENT 'max total replicas of clusters',
+    `created_by`    bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`    bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_resource_environment` (`resource_type`, `resource_id`, `environment`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- resources requested by clusters, calculated from the rendered values of clusters
+CREATE TABLE `tb_cluster_usage`
+(
+    `id`         bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `cluster_id` bigint(20) unsigned NOT NULL COMMENT 'cluster id',
+    `cpu`        bigint(20)          NOT NULL DEFAULT '0' COMMENT 'cpu requests in millicores',
+    `memory`     bigint(20)          NOT NULL DEFAULT '0' COMMENT 'memory requests in bytes',
+    `replicas`   int(11)             NOT NULL DEFAULT '0' COMMENT 'replicas of workloads',
+    `created_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_cluster_id` (`cluster_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
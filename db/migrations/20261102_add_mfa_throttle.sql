@@ -0,0 +1,19 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- reject reused totp codes and throttle failed verifications
+ALTER TABLE `tb_user_mfa`
+    ADD COLUMN `last_used_step`  bigint(20) NOT NULL DEFAULT '0' COMMENT 'time step of the last accepted totp code' AFTER `recovery_codes`,
+    ADD COLUMN `failed_attempts` int(11)    NOT NULL DEFAULT '0' COMMENT 'number of consecutive failed verifications' AFTER `last_used_step`,
+    ADD COLUMN `locked_until`    datetime            DEFAULT NULL COMMENT 'verifications are rejected before it' AFTER `failed_attempts`;
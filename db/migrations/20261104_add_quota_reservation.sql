@@ -0,0 +1,32 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- resources reserved under quotas by admitted cluster requests, until usage of the cluster is saved or they expire
+CREATE TABLE `tb_quota_reservation`
+(
+    `id`         bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `quota_id`   bigint(20) unsigned NOT NULL COMMENT 'quota id',
+    `cluster`    varchar(128)        NOT NULL COMMENT 'name of the cluster',
+    `clusters`   int(11)             NOT NULL DEFAULT '0' COMMENT '1 if the cluster is new to the quota',
+    `cpu`        bigint(20)          NOT NULL DEFAULT '0' COMMENT 'cpu requests in millicores',
+    `memory`     bigint(20)          NOT NULL DEFAULT '0' COMMENT 'memory requests in bytes',
+    `replicas`   int(11)             NOT NULL DEFAULT '0' COMMENT 'replicas of workloads',
+    `expires_at` datetime            NOT NULL COMMENT 'the reservation is not counted after it expires',
+    `created_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_quota_id` (`quota_id`),
+    KEY `idx_cluster` (`cluster`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
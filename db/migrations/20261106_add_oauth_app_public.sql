@@ -0,0 +1,18 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- public clients, such as native apps and single page apps, cannot keep a secret,
+-- they are allowed to omit the client secret if they use PKCE
+ALTER TABLE `tb_oauth_app`
+    ADD COLUMN `public` tinyint(1) NOT NULL DEFAULT 0 COMMENT 'is public client, 0-false, 1-true' AFTER `app_type`;
@@ -0,0 +1,811 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- PostgreSQL support starts from this version, so the migration creates the whole schema
+-- instead of replaying the mysql history. Later migrations are added to both engines.
+
+-- check table
+CREATE TABLE tb_check
+(
+    id            bigserial   NOT NULL,
+    resource_type varchar(64) NOT NULL DEFAULT '', -- resource type
+    resource_id   bigint      NOT NULL, -- resource id
+    created_at    timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts    bigint      DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by    bigint      NOT NULL DEFAULT 0, -- creator
+    updated_by    bigint      NOT NULL DEFAULT 0, -- updater
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_check_uk_resource_deleted ON tb_check (resource_type, resource_id, deleted_ts);
+
+-- check run table
+CREATE TABLE tb_checkrun
+(
+    id              bigserial    NOT NULL,
+    name            varchar(256) NOT NULL DEFAULT '', -- the name of check run
+    status          varchar(64)  NOT NULL DEFAULT '', -- the status of check run
+    pipeline_run_id bigint       NOT NULL, -- pipeline run id
+    check_id        bigint       NOT NULL, -- check id
+    message         varchar(256) NOT NULL DEFAULT '',
+    detail_url      varchar(256) NOT NULL DEFAULT '', -- the detail url of check run
+    created_at      timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts      bigint       DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by      bigint       NOT NULL DEFAULT 0, -- creator
+    updated_by      bigint       NOT NULL DEFAULT 0, -- updater
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_checkrun_uk_pipeline_run_id_check_id_deleted ON tb_checkrun (pipeline_run_id, check_id, deleted_ts);
+
+-- pr_msg table
+CREATE TABLE tb_pr_msg
+(
+    id              bigserial   NOT NULL,
+    pipeline_run_id bigint      NOT NULL, -- pipeline run id
+    content         text        NOT NULL, -- content of message
+    created_at      timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by      bigint      NOT NULL DEFAULT 0, -- creator
+    updated_by      bigint      NOT NULL DEFAULT 0, -- updater
+    deleted_ts      bigint      DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    message_type    smallint    NOT NULL DEFAULT 0, -- 0 for user message, 1 for system message
+    PRIMARY KEY (id)
+);
+
+-- group table
+CREATE TABLE tb_group
+(
+    id               bigserial    NOT NULL,
+    name             varchar(128) NOT NULL DEFAULT '',
+    path             varchar(32)  NOT NULL DEFAULT '',
+    description      varchar(256),
+    visibility_level varchar(16)  NOT NULL, -- public or private
+    parent_id        bigint       NOT NULL DEFAULT 0, -- ID of the parent group
+    traversal_ids    varchar(32)  NOT NULL DEFAULT '', -- ID path from the root, like 1,2,3
+    region_selector  varchar(512) NOT NULL DEFAULT '', -- used for filtering kubernetes
+    created_at       timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at       timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts       bigint       DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by       bigint       NOT NULL DEFAULT 0, -- creator
+    updated_by       bigint       NOT NULL DEFAULT 0, -- updater
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_group_uk_parentid_name_deletedts ON tb_group (parent_id, name, deleted_ts);
+CREATE UNIQUE INDEX tb_group_uk_parentid_path_deletedts ON tb_group (parent_id, path, deleted_ts);
+
+-- user table
+CREATE TABLE tb_user
+(
+    id         bigserial    NOT NULL,
+    name       varchar(64)  NOT NULL DEFAULT '',
+    full_name  varchar(128) DEFAULT '',
+    email      varchar(64)  NOT NULL DEFAULT '',
+    phone      varchar(32),
+    oidc_id    varchar(64)  NOT NULL, -- oidc id, which is a unique index in oidc system.
+    oidc_type  varchar(64)  NOT NULL, -- oidc type, such as google, github, gitlab etc.
+    admin      boolean      NOT NULL, -- is system admin
+    created_at timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts bigint       DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by bigint       NOT NULL DEFAULT 0,
+    user_type  smallint     NOT NULL DEFAULT 0, -- the option type is: 0 (common user), 1(robot user)
+    password   varchar(256) NOT NULL DEFAULT '', -- password of user
+    banned     boolean      NOT NULL DEFAULT false, -- whether user is banned
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_user_idx_name ON tb_user (name);
+CREATE UNIQUE INDEX tb_user_idx_email ON tb_user (email);
+
+-- template table
+CREATE TABLE tb_template
+(
+    id          bigserial    NOT NULL,
+    name        varchar(64)  NOT NULL DEFAULT '', -- the name of template
+    description varchar(256), -- the template description
+    repository  varchar(256) NOT NULL DEFAULT '',
+    group_id    bigint       NOT NULL DEFAULT 0,
+    chart_name  varchar(256) DEFAULT '',
+    only_owner  boolean      NOT NULL DEFAULT false,
+    without_ci  boolean      NOT NULL DEFAULT false, -- without_ci configuration
+    created_at  timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts  bigint       DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by  bigint       NOT NULL DEFAULT 0, -- creator
+    updated_by  bigint       NOT NULL DEFAULT 0, -- updater
+    type        varchar(64)  NOT NULL DEFAULT '', -- type of template, such as workload
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_template_idx_name ON tb_template (name);
+
+-- template release table
+CREATE TABLE tb_template_release
+(
+    id            bigserial     NOT NULL,
+    template_name varchar(64)   NOT NULL, -- the name of template
+    name          varchar(64)   NOT NULL DEFAULT '', -- the name of template release
+    description   varchar(256)  NOT NULL, -- description about this template release
+    recommended   boolean       NOT NULL, -- is the most recommended template
+    template      bigint        NOT NULL DEFAULT 0,
+    chart_name    varchar(256)  NOT NULL DEFAULT '',
+    only_owner    boolean       NOT NULL DEFAULT false,
+    chart_version varchar(256)  NOT NULL DEFAULT '', -- chart version on template repository
+    sync_status   varchar(64)   NOT NULL DEFAULT 'status_unknown', -- shows sync status
+    failed_reason varchar(2048) NOT NULL DEFAULT '', -- failed reason at last time
+    commit_id     varchar(256)  NOT NULL DEFAULT '', -- commit id at last sync
+    last_sync_at  timestamptz   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at    timestamptz   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    timestamptz   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts    bigint        DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by    bigint        NOT NULL DEFAULT 0, -- creator
+    updated_by    bigint        NOT NULL DEFAULT 0, -- updater
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_template_release_idx_template_name_name ON tb_template_release (template_name, name);
+
+-- member table
+CREATE TABLE tb_member
+(
+    id            bigserial   NOT NULL,
+    resource_type varchar(64) NOT NULL, -- groupapplicationcluster
+    resource_id   bigint      NOT NULL, -- resource id
+    role          varchar(64) NOT NULL, -- binding role name
+    member_type   smallint    NOT NULL DEFAULT 0, -- 0-USER, 1-group
+    membername_id bigint      NOT NULL, -- UserID or GroupID
+    granted_by    bigint      NOT NULL, -- who grant the role
+    created_by    bigint      NOT NULL, -- who create the role
+    created_at    timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts    bigint      NOT NULL DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_member_uk_resource_member_deleted ON tb_member (resource_type, resource_id, member_type, membername_id, deleted_ts);
+
+-- application table
+CREATE TABLE tb_application
+(
+    id               bigserial    NOT NULL,
+    group_id         bigint       NOT NULL, -- group id
+    name             varchar(64)  NOT NULL DEFAULT '', -- the name of application
+    description      varchar(256), -- the description of application
+    priority         varchar(16)  NOT NULL DEFAULT 'P3', -- the priority of application
+    git_url          varchar(128), -- git repo url
+    git_subfolder    varchar(128), -- git repo subfolder
+    git_branch       varchar(128), -- git default branch
+    git_ref          varchar(128),
+    git_ref_type     varchar(64),
+    template         varchar(64)  NOT NULL, -- template name
+    template_release varchar(64)  NOT NULL, -- template release
+    created_at       timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at       timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts       bigint       DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by       bigint       NOT NULL DEFAULT 0, -- creator
+    updated_by       bigint       NOT NULL DEFAULT 0, -- updater
+    image            varchar(256), -- artifact image url for the application
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_application_uk_name_deletedts ON tb_application (name, deleted_ts);
+
+-- registry table
+CREATE TABLE tb_registry
+(
+    id                       bigserial    NOT NULL,
+    name                     varchar(128) NOT NULL DEFAULT '', -- name of the harbor registry
+    server                   varchar(256) NOT NULL DEFAULT '', -- harbor server address
+    token                    varchar(512) NOT NULL DEFAULT '', -- harbor server token
+    path                     varchar(256) NOT NULL DEFAULT '', -- path of image
+    insecure_skip_tls_verify boolean      NOT NULL DEFAULT false, -- skip tls verify
+    kind                     varchar(256) NOT NULL DEFAULT 'harbor', -- which kind registry it is
+    created_at               timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at               timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts               bigint       DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by               bigint       NOT NULL DEFAULT 0, -- creator
+    updated_by               bigint       NOT NULL DEFAULT 0, -- updater
+    PRIMARY KEY (id)
+);
+
+-- environment table
+CREATE TABLE tb_environment
+(
+    id             bigserial    NOT NULL,
+    name           varchar(128) NOT NULL DEFAULT '', -- env name
+    display_name   varchar(128) NOT NULL DEFAULT '', -- display name
+    default_region varchar(128), -- default region of the environment
+    created_at     timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts     bigint       DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by     bigint       NOT NULL DEFAULT 0, -- creator
+    updated_by     bigint       NOT NULL DEFAULT 0, -- updater
+    auto_free      boolean      NOT NULL DEFAULT false, -- auto free configuration
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_environment_uk_name_deletedts ON tb_environment (name, deleted_ts);
+
+-- region table
+CREATE TABLE tb_region
+(
+    id             bigserial    NOT NULL,
+    name           varchar(128) NOT NULL DEFAULT '', -- region name
+    display_name   varchar(128) NOT NULL DEFAULT '', -- region display name
+    server         varchar(256), -- k8s server url
+    certificate    text, -- k8s kube config
+    ingress_domain text, -- k8s ingress domain
+    prometheus_url varchar(128), -- prometheus url
+    registry_id    bigint       NOT NULL, -- registry id
+    disabled       boolean      NOT NULL DEFAULT false, -- is disabled
+    created_at     timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts     bigint       DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by     bigint       NOT NULL DEFAULT 0, -- creator
+    updated_by     bigint       NOT NULL DEFAULT 0, -- updater
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_region_idx_name ON tb_region (name);
+
+-- environment_region table
+CREATE TABLE tb_environment_region
+(
+    id               bigserial    NOT NULL,
+    environment_name varchar(128) NOT NULL DEFAULT '', -- environment name
+    region_name      varchar(128) NOT NULL DEFAULT '', -- region name
+    is_default       boolean      NOT NULL DEFAULT false, -- is default region
+    disabled         boolean      NOT NULL DEFAULT false, -- is disabled
+    created_at       timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at       timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts       bigint       DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by       bigint       NOT NULL DEFAULT 0, -- creator
+    updated_by       bigint       NOT NULL DEFAULT 0, -- updater
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_environment_region_uk_env_region_deletedts ON tb_environment_region (environment_name, region_name, deleted_ts);
+
+-- cluster table
+CREATE TABLE tb_cluster
+(
+    id               bigserial    NOT NULL,
+    application_id   bigint       NOT NULL, -- application id
+    name             varchar(64)  NOT NULL DEFAULT '', -- the name of cluster
+    environment_name varchar(128) NOT NULL DEFAULT '',
+    region_name      varchar(128) NOT NULL DEFAULT '',
+    description      varchar(256), -- the description of cluster
+    git_url          varchar(128), -- git repo url
+    git_subfolder    varchar(128), -- git repo subfolder
+    git_branch       varchar(128), -- git branch
+    git_ref          varchar(128),
+    git_ref_type     varchar(64),
+    template         varchar(64)  NOT NULL, -- template name
+    template_release varchar(64)  NOT NULL, -- template release
+    status           varchar(64),
+    created_at       timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at       timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts       bigint       DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by       bigint       NOT NULL DEFAULT 0, -- creator
+    updated_by       bigint       NOT NULL DEFAULT 0, -- updater
+    expire_seconds   bigint       NOT NULL DEFAULT 0, -- expiration seconds, 0 means permanent
+    image            varchar(256), -- artifact image url for the cluster
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_cluster_uk_name_deletedts ON tb_cluster (name, deleted_ts);
+CREATE INDEX tb_cluster_idx_application_id ON tb_cluster (application_id);
+CREATE INDEX tb_cluster_idx_deleted_ts ON tb_cluster (deleted_ts);
+
+-- tag table
+CREATE TABLE tb_tag
+(
+    id            bigserial     NOT NULL,
+    resource_id   bigint        NOT NULL, -- resource id
+    resource_type varchar(64)   NOT NULL DEFAULT '', -- resource type
+    tag_key       varchar(64)   NOT NULL DEFAULT '', -- key of tag
+    tag_value     varchar(1280) NOT NULL DEFAULT '', -- value of tag
+    created_at    timestamptz   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    timestamptz   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by    bigint        NOT NULL DEFAULT 0, -- creator
+    updated_by    bigint        NOT NULL DEFAULT 0, -- updater
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_tag_uk_rtype_cid_tkey ON tb_tag (resource_type, resource_id, tag_key);
+CREATE INDEX tb_tag_idx_cluster_id ON tb_tag (resource_id);
+CREATE INDEX tb_tag_idx_key ON tb_tag (tag_key);
+
+-- cluster template schema tag table
+CREATE TABLE tb_cluster_template_schema_tag
+(
+    id         bigserial     NOT NULL,
+    cluster_id bigint        NOT NULL, -- cluster id
+    tag_key    varchar(64)   NOT NULL DEFAULT '', -- key of tag
+    tag_value  varchar(1280) NOT NULL DEFAULT '', -- value of tag
+    created_at timestamptz   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamptz   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by bigint        NOT NULL DEFAULT 0, -- creator
+    updated_by bigint        NOT NULL DEFAULT 0, -- updater
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_cluster_template_schema_tag_idx_cluster_id_key ON tb_cluster_template_schema_tag (cluster_id, tag_key);
+CREATE INDEX tb_cluster_template_schema_tag_idx_key ON tb_cluster_template_schema_tag (tag_key);
+
+-- pipelinerun table
+CREATE TABLE tb_pipelinerun
+(
+    id                 bigserial     NOT NULL,
+    cluster_id         bigint        NOT NULL, -- cluster id
+    action             varchar(64)   NOT NULL, -- action
+    status             varchar(64)   NOT NULL DEFAULT '', -- the pipelinerun status
+    title              varchar(256)  NOT NULL DEFAULT '', -- the title of pipelinerun
+    description        varchar(2048), -- the description of pipelinerun
+    git_url            varchar(128), -- git repo url
+    git_branch         varchar(128), -- the branch to build of this pipelinerun
+    git_ref            varchar(128),
+    git_ref_type       varchar(64),
+    git_commit         varchar(128), -- the commit to build of this pipelinerun
+    image_url          varchar(256), -- image url
+    last_config_commit varchar(128), -- the last commit of cluster config
+    config_commit      varchar(128), -- the new commit of cluster config
+    s3_bucket          varchar(128)  NOT NULL DEFAULT '', -- s3 bucket to storage this pipelinerun log
+    log_object         varchar(258)  NOT NULL DEFAULT '', -- s3 object for log
+    pr_object          varchar(258)  NOT NULL DEFAULT '', -- s3 object for pipelinerun
+    ci_event_id        varchar(36)   NOT NULL DEFAULT '', -- event id returned from ci component
+    started_at         timestamptz, -- start time of this pipelinerun
+    finished_at        timestamptz, -- finish time of this pipelinerun
+    rollback_from      bigint, -- the pipelinerun id that this pipelinerun rollback from
+    created_at         timestamptz   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at         timestamptz   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by         bigint        NOT NULL DEFAULT 0, -- creator
+    pusher             varchar(128)  NOT NULL DEFAULT '', -- git user whose push triggered the pipelinerun
+    PRIMARY KEY (id)
+);
+CREATE INDEX tb_pipelinerun_idx_cluster_action ON tb_pipelinerun (cluster_id, action);
+CREATE INDEX tb_pipelinerun_idx_cluster_config_commit ON tb_pipelinerun (cluster_id, config_commit);
+CREATE INDEX tb_pipelinerun_idx_ci_event_id ON tb_pipelinerun (ci_event_id);
+
+-- application region table
+CREATE TABLE tb_application_region
+(
+    id               bigserial    NOT NULL,
+    application_id   bigint       NOT NULL, -- application id
+    environment_name varchar(128) NOT NULL DEFAULT '', -- environment name
+    region_name      varchar(128) NOT NULL DEFAULT '', -- default deploy region of the environment
+    created_at       timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at       timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by       bigint       NOT NULL DEFAULT 0, -- creator
+    updated_by       bigint       NOT NULL DEFAULT 0, -- updater
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_application_region_idx_application_environment ON tb_application_region (application_id, environment_name);
+
+-- tekton pipeline
+CREATE TABLE tb_pipeline
+(
+    id             bigserial   NOT NULL,
+    pipelinerun_id bigint      NOT NULL, -- pipelinerun id
+    application    varchar(64) NOT NULL, -- application name
+    cluster        varchar(64) NOT NULL, -- cluster name
+    region         varchar(16) NOT NULL, -- region name
+    pipeline       varchar(16) NOT NULL DEFAULT '', -- pipeline name
+    result         varchar(16) NOT NULL DEFAULT '', -- result of the step, ok、failed or others
+    duration       integer     NOT NULL, -- duration
+    started_at     timestamptz NOT NULL, -- start time of this pipelinerun
+    finished_at    timestamptz NOT NULL, -- finish time of this pipelinerun
+    created_at     timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+CREATE INDEX tb_pipeline_idx_region_application_created_at ON tb_pipeline (region, application, created_at);
+
+-- tekton pipeline task
+CREATE TABLE tb_task
+(
+    id             bigserial   NOT NULL,
+    pipelinerun_id bigint      NOT NULL, -- pipelinerun id
+    application    varchar(64) NOT NULL, -- application name
+    cluster        varchar(64) NOT NULL, -- cluster name
+    region         varchar(16) NOT NULL, -- region name
+    pipeline       varchar(16) NOT NULL DEFAULT '', -- pipeline name
+    task           varchar(16) NOT NULL DEFAULT '', -- task name
+    result         varchar(16) NOT NULL DEFAULT '', -- result of the step, ok or failed
+    duration       integer     NOT NULL, -- duration
+    started_at     timestamptz NOT NULL, -- start time of this pipelinerun
+    finished_at    timestamptz NOT NULL, -- finish time of this pipelinerun
+    created_at     timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+CREATE INDEX tb_task_idx_region_application_created_at ON tb_task (region, application, created_at);
+
+-- tekton task step
+CREATE TABLE tb_step
+(
+    id             bigserial   NOT NULL,
+    pipelinerun_id bigint      NOT NULL, -- pipelinerun id
+    application    varchar(64) NOT NULL, -- application name
+    cluster        varchar(64) NOT NULL, -- cluster name
+    region         varchar(16) NOT NULL, -- region name
+    pipeline       varchar(16) NOT NULL DEFAULT '', -- pipeline name
+    task           varchar(16) NOT NULL DEFAULT '', -- task name
+    step           varchar(16) NOT NULL DEFAULT '', -- step name
+    result         varchar(16) NOT NULL DEFAULT '', -- result of the step, ok or failed
+    duration       integer     NOT NULL, -- duration
+    started_at     timestamptz NOT NULL, -- start time of this pipelinerun
+    finished_at    timestamptz NOT NULL, -- finish time of this pipelinerun
+    created_at     timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+CREATE INDEX tb_step_idx_region_application_created_at ON tb_step (region, application, created_at);
+
+-- oauth app table
+CREATE TABLE tb_oauth_app
+(
+    id           bigserial    NOT NULL,
+    name         varchar(128), -- short name of app client
+    client_id    varchar(128), -- oauth app client
+    redirect_url varchar(256), -- the authorization callback url
+    home_url     varchar(256), -- the oauth app home url
+    description  varchar(256), -- the desc of app
+    app_type     smallint     NOT NULL DEFAULT 1, -- 1 for HorizonOAuthAPP, 2 for DirectOAuthAPP
+    owner_type   smallint     NOT NULL DEFAULT 1, -- 1 for group, 2 for user
+    owner_id     bigint, -- group owner id
+    created_at   timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP, -- created_at
+    created_by   bigint       NOT NULL DEFAULT 0, -- creator
+    updated_at   timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by   bigint       NOT NULL DEFAULT 0, -- updater
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_oauth_app_idx_client_id ON tb_oauth_app (client_id);
+
+-- oauth client secret table
+CREATE TABLE tb_oauth_client_secret
+(
+    id            bigserial    NOT NULL,
+    client_id     varchar(256), -- oauth app client
+    client_secret varchar(256), -- oauth app secret
+    created_at    timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by    bigint       NOT NULL DEFAULT 0, -- creator
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_oauth_client_secret_idx_client_id_secret ON tb_oauth_client_secret (client_id, client_secret);
+
+-- token table
+CREATE TABLE tb_token
+(
+    id                    bigserial     NOT NULL,
+    name                  varchar(64)   NOT NULL DEFAULT '',
+    client_id             varchar(256), -- oauth app client
+    redirect_uri          varchar(256),
+    state                 varchar(256), --  authorize_code state info
+    code                  varchar(256)  NOT NULL DEFAULT '', -- private-token-code/authorize_code/access_token/refresh-token
+    created_at            timestamptz   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_in            bigint,
+    scope                 varchar(256),
+    user_id               bigint        NOT NULL DEFAULT 0,
+    created_by            bigint        NOT NULL DEFAULT 0,
+    ref_id                bigint, -- id associated to the access token for refresh token
+    allowed_cidrs         varchar(1024) NOT NULL DEFAULT '', -- comma separated networks the token can be used from
+    last_used_at          timestamptz, -- when the token was used last time
+    last_used_ip          varchar(64)   NOT NULL DEFAULT '', -- source ip of the last usage
+    expiry_notified_at    timestamptz, -- when the owner was notified of the expiration
+    code_challenge        varchar(128)  NOT NULL DEFAULT '', -- pkce code challenge
+    code_challenge_method varchar(16)   NOT NULL DEFAULT '', -- pkce code challenge method, plain or S256
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_token_idx_code ON tb_token (code);
+CREATE INDEX tb_token_idx_client_id ON tb_token (client_id);
+CREATE INDEX tb_token_idx_user_id ON tb_token (user_id);
+
+-- identity provider table
+CREATE TABLE tb_identity_provider
+(
+    id                         bigserial    NOT NULL,
+    display_name               varchar(128) NOT NULL DEFAULT '', -- name displayed on web
+    name                       varchar(128) NOT NULL DEFAULT '', -- name to generate index in db, unique
+    avatar                     varchar(256) NOT NULL DEFAULT '', -- link to avatar
+    authorization_endpoint     varchar(256) NOT NULL DEFAULT '', -- authorization endpoint of idp
+    token_endpoint             varchar(256) NOT NULL DEFAULT '', -- token endpoint of idp
+    userinfo_endpoint          varchar(256) NOT NULL DEFAULT '', -- userinfo endpoint of idp
+    revocation_endpoint        varchar(256) NOT NULL DEFAULT '', -- revocation endpoint of idp
+    issuer                     varchar(256) NOT NULL DEFAULT '', -- issuer of idp, generating discovery endpoint
+    scopes                     varchar(256) NOT NULL DEFAULT '', -- scopes when asking for authorization
+    signing_algs               varchar(256) NOT NULL DEFAULT '', -- algs for verifying signing
+    token_endpoint_auth_method varchar(256) NOT NULL DEFAULT 'client_secret_sent_as_post', -- how to carry client secret
+    jwks                       varchar(256) NOT NULL DEFAULT '', -- jwks endpoint, describe how to identify a token
+    client_id                  varchar(256) NOT NULL DEFAULT '', -- client id issued by idp
+    client_secret              varchar(256) NOT NULL DEFAULT '', -- client secret issued by idp
+    created_at                 timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP, -- time of first creating
+    updated_at                 timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP, -- time of last updating
+    deleted_ts                 bigint       DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by                 bigint       NOT NULL DEFAULT 0, -- creator
+    updated_by                 bigint       NOT NULL DEFAULT 0, -- updater
+    kind                       varchar(32)  NOT NULL DEFAULT 'oidc', -- protocol of identity provider, oidc or ldap
+    ldap_config                text, -- config of ldap provider in json
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_identity_provider_idx_name ON tb_identity_provider (name);
+
+-- idp and user relationship table
+CREATE TABLE tb_idp_user
+(
+    id         bigserial    NOT NULL,
+    sub        varchar(256) NOT NULL DEFAULT '', -- user id in idp
+    idp_id     bigint       NOT NULL DEFAULT 0, -- refer to tb_identify_provider
+    user_id    bigint       NOT NULL DEFAULT 0, -- refer to tb_user
+    name       varchar(256) NOT NULL DEFAULT '', -- user name from idp
+    email      varchar(256) NOT NULL DEFAULT '', -- user email from idp
+    deletable  boolean      NOT NULL DEFAULT false, -- whether this link can be deleted
+    created_at timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP, -- time of first creating
+    updated_at timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP, -- time of last updating
+    deleted_ts bigint       DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by bigint       NOT NULL DEFAULT 0, -- creator
+    updated_by bigint       NOT NULL DEFAULT 0, -- updater
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_idp_user_uni_idx_idp_sub ON tb_idp_user (idp_id, sub, deleted_ts);
+
+CREATE TABLE tb_event
+(
+    id            bigserial    NOT NULL,
+    req_id        varchar(256) NOT NULL DEFAULT '',
+    resource_type varchar(256) NOT NULL DEFAULT '',
+    resource_id   bigint       NOT NULL DEFAULT 0,
+    event_type    varchar(256) NOT NULL DEFAULT '',
+    created_at    timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by    bigint       NOT NULL DEFAULT 0,
+    extra         varchar(255) NOT NULL DEFAULT '', -- extra infos to describe the event
+    PRIMARY KEY (id)
+);
+CREATE INDEX tb_event_idx_req_id ON tb_event (req_id);
+CREATE INDEX tb_event_idx_resource_action ON tb_event (resource_id, resource_type, event_type);
+
+CREATE TABLE tb_event_cursor
+(
+    id         bigserial   NOT NULL,
+    position   bigint      NOT NULL DEFAULT 0,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_event_cursor_idx_value ON tb_event_cursor (position);
+
+CREATE TABLE tb_webhook
+(
+    id                 bigserial    NOT NULL,
+    enabled            boolean      NOT NULL DEFAULT true,
+    url                text         NOT NULL,
+    ssl_verify_enabled boolean      NOT NULL DEFAULT false,
+    description        varchar(256) NOT NULL DEFAULT '',
+    secret             text         NOT NULL,
+    triggers           text         NOT NULL,
+    resource_type      varchar(256) NOT NULL DEFAULT '',
+    resource_id        bigint       NOT NULL DEFAULT 0,
+    created_at         timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at         timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by         bigint       NOT NULL DEFAULT 0,
+    updated_by         bigint       NOT NULL DEFAULT 0,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE tb_webhook_log
+(
+    id               bigserial    NOT NULL,
+    webhook_id       bigint       NOT NULL,
+    event_id         bigint       NOT NULL,
+    url              text         NOT NULL,
+    request_headers  text         NOT NULL,
+    request_data     text         NOT NULL,
+    response_headers text         NOT NULL,
+    response_body    text         NOT NULL,
+    status           varchar(256) NOT NULL,
+    error_message    text         NOT NULL,
+    created_at       timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by       bigint       NOT NULL DEFAULT 0,
+    updated_at       timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+CREATE INDEX tb_webhook_log_idx_webhook_id_status ON tb_webhook_log (webhook_id, status);
+CREATE INDEX tb_webhook_log_idx_event_id ON tb_webhook_log (event_id);
+
+-- metatag table
+CREATE TABLE tb_metatag
+(
+    tag_key     varchar(64)  NOT NULL DEFAULT '', -- key of the metatag
+    tag_value   varchar(128) NOT NULL DEFAULT '', -- value of the metatag
+    description varchar(64)  NOT NULL DEFAULT '', -- description
+    created_at  timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX tb_metatag_idx_key_value ON tb_metatag (tag_key, tag_value);
+
+CREATE TABLE tb_badge
+(
+    id            bigserial    NOT NULL,
+    event_id      bigint       NOT NULL,
+    resource_type varchar(64)  NOT NULL DEFAULT '', -- resource type
+    resource_id   bigint       NOT NULL, -- resource id
+    name          varchar(64)  NOT NULL DEFAULT '', -- badge name
+    svg_link      varchar(256) NOT NULL DEFAULT '', -- badge svg link
+    redirect_link varchar(256) NOT NULL DEFAULT '', -- badge redirect link
+    created_at    timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts    bigint       DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by    bigint       NOT NULL DEFAULT 0, -- creator
+    updated_by    bigint       NOT NULL DEFAULT 0, -- updater
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_badge_idx_resource_name_deletedts ON tb_badge (resource_id, resource_type, name, deleted_ts);
+
+-- collection table
+CREATE TABLE tb_collection
+(
+    id            bigserial   NOT NULL,
+    resource_id   bigint      NOT NULL, -- resource id
+    resource_type varchar(64) NOT NULL DEFAULT '', -- resource type
+    user_id       bigint      NOT NULL, -- user id
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_collection_uk_user_resource ON tb_collection (user_id, resource_type, resource_id);
+
+-- user mfa table
+CREATE TABLE tb_user_mfa
+(
+    id             bigserial    NOT NULL,
+    user_id        bigint       NOT NULL, -- user id
+    secret         varchar(128) NOT NULL DEFAULT '', -- base32 encoded totp secret
+    enabled        boolean      NOT NULL DEFAULT false, -- whether the totp has been activated
+    recovery_codes text, -- sha256 of recovery codes joined by comma
+    created_at     timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts     bigint       DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by     bigint       NOT NULL DEFAULT 0, -- creator
+    updated_by     bigint       NOT NULL DEFAULT 0, -- updater
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_user_mfa_uk_user_deleted ON tb_user_mfa (user_id, deleted_ts);
+
+-- team table
+CREATE TABLE tb_team
+(
+    id             bigserial    NOT NULL,
+    name           varchar(128) NOT NULL DEFAULT '', -- name of team
+    description    varchar(256) NOT NULL DEFAULT '', -- description of team
+    idp_id         bigint       NOT NULL DEFAULT 0, -- identity provider the team is synced from, 0 means not synced
+    external_group varchar(256) NOT NULL DEFAULT '', -- group in identity provider the team is synced from
+    created_at     timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts     bigint       DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by     bigint       NOT NULL DEFAULT 0, -- creator
+    updated_by     bigint       NOT NULL DEFAULT 0, -- updater
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_team_uk_name_deleted ON tb_team (name, deleted_ts);
+CREATE INDEX tb_team_idx_idp_id ON tb_team (idp_id);
+
+-- team member table
+CREATE TABLE tb_team_member
+(
+    id         bigserial   NOT NULL,
+    team_id    bigint      NOT NULL, -- team id
+    user_id    bigint      NOT NULL, -- user id
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts bigint      DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by bigint      NOT NULL DEFAULT 0, -- creator
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_team_member_uk_team_user_deleted ON tb_team_member (team_id, user_id, deleted_ts);
+CREATE INDEX tb_team_member_idx_user_id ON tb_team_member (user_id);
+
+-- custom role table, built-in roles are still loaded from roles file
+CREATE TABLE tb_custom_role
+(
+    id          bigserial    NOT NULL,
+    name        varchar(64)  NOT NULL DEFAULT '', -- name of role
+    description varchar(256) NOT NULL DEFAULT '', -- description of role
+    base_role   varchar(64)  NOT NULL DEFAULT '', -- built-in role which the role ranks just below
+    rules       text, -- policy rules of role in json
+    created_at  timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts  bigint       DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by  bigint       NOT NULL DEFAULT 0, -- creator
+    updated_by  bigint       NOT NULL DEFAULT 0, -- updater
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_custom_role_uk_name_deleted ON tb_custom_role (name, deleted_ts);
+
+-- git trigger rules of clusters, BuildDeploy is started automatically when receiving matched git push events
+CREATE TABLE tb_git_trigger
+(
+    id             bigserial    NOT NULL,
+    cluster_id     bigint       NOT NULL, -- cluster id
+    branch_pattern varchar(256) NOT NULL DEFAULT '', -- glob pattern of branches, empty means never
+    tag_regex      varchar(256) NOT NULL DEFAULT '', -- regular expression of tags, empty means never
+    path_filter    varchar(256) NOT NULL DEFAULT '', -- only pushes changing files under the path trigger
+    enabled        boolean      NOT NULL DEFAULT true, -- whether the trigger is enabled
+    created_at     timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts     bigint       DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by     bigint       NOT NULL DEFAULT 0, -- creator
+    updated_by     bigint       NOT NULL DEFAULT 0, -- updater
+    PRIMARY KEY (id)
+);
+CREATE INDEX tb_git_trigger_idx_cluster_id ON tb_git_trigger (cluster_id);
+
+-- preview settings of base clusters, a preview cluster is created for each matched merge request
+CREATE TABLE tb_preview_setting
+(
+    id                    bigserial    NOT NULL,
+    cluster_id            bigint       NOT NULL, -- base cluster id
+    target_branch_pattern varchar(256) NOT NULL DEFAULT '', -- glob pattern of target branches, empty means all
+    expire_time           varchar(64)  NOT NULL DEFAULT '', -- lifetime of preview clusters, empty means never expire
+    enabled               boolean      NOT NULL DEFAULT true, -- whether the preview is enabled
+    created_at            timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at            timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts            bigint       DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by            bigint       NOT NULL DEFAULT 0, -- creator
+    updated_by            bigint       NOT NULL DEFAULT 0, -- updater
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_preview_setting_idx_cluster_id_deleted_ts ON tb_preview_setting (cluster_id, deleted_ts);
+
+-- preview clusters created for merge requests
+CREATE TABLE tb_preview_cluster
+(
+    id               bigserial    NOT NULL,
+    base_cluster_id  bigint       NOT NULL, -- base cluster id
+    cluster_id       bigint       NOT NULL, -- preview cluster id
+    merge_request_id bigint       NOT NULL, -- project-scoped number of merge request
+    source_branch    varchar(256) NOT NULL DEFAULT '', -- source branch of merge request
+    created_at       timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at       timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_ts       bigint       DEFAULT 0, -- deleted timestamp, 0 means not deleted
+    created_by       bigint       NOT NULL DEFAULT 0, -- creator
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_preview_cluster_idx_base_cluster_merge_request ON tb_preview_cluster (base_cluster_id, merge_request_id, deleted_ts);
+
+-- recorded terminal sessions, the recordings are stored in s3
+CREATE TABLE tb_terminal_session
+(
+    id          bigserial     NOT NULL,
+    session_id  varchar(64)   NOT NULL DEFAULT '', -- random id of terminal session
+    cluster_id  bigint        NOT NULL, -- cluster id
+    environment varchar(128)  NOT NULL DEFAULT '', -- environment of cluster
+    pod         varchar(256)  NOT NULL DEFAULT '', -- pod name
+    container   varchar(256)  NOT NULL DEFAULT '', -- container name
+    user_id     bigint        NOT NULL, -- user who opened the terminal
+    object      varchar(1024) NOT NULL DEFAULT '', -- s3 object of the recording
+    size        bigint        NOT NULL DEFAULT 0, -- size of the recording in bytes
+    started_at  timestamptz   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at timestamptz,
+    created_at  timestamptz   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  timestamptz   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+CREATE INDEX tb_terminal_session_idx_cluster_pod ON tb_terminal_session (cluster_id, pod);
+CREATE INDEX tb_terminal_session_idx_user_id ON tb_terminal_session (user_id);
+CREATE INDEX tb_terminal_session_idx_started_at ON tb_terminal_session (started_at);
+
+-- just-in-time terminal access of clusters in environments with jit terminal policy
+CREATE TABLE tb_terminal_access_grant
+(
+    id          bigserial     NOT NULL,
+    cluster_id  bigint        NOT NULL, -- cluster id
+    user_id     bigint        NOT NULL, -- user who requested the access
+    reason      varchar(1024) NOT NULL DEFAULT '', -- reason of the request
+    duration    bigint        NOT NULL DEFAULT 0, -- requested duration in seconds
+    status      varchar(32)   NOT NULL DEFAULT 'pending', -- pending, approved, rejected or revoked
+    approver_id bigint        NOT NULL DEFAULT 0, -- user who approved or rejected the request
+    expires_at  timestamptz, -- when the approved access expires
+    created_at  timestamptz   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  timestamptz   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+CREATE INDEX tb_terminal_access_grant_idx_cluster_user ON tb_terminal_access_grant (cluster_id, user_id);
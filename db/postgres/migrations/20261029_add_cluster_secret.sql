@@ -0,0 +1,34 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+
+-- sealed secrets certificate of regions, which cluster secrets are sealed with
+ALTER TABLE tb_region
+    ADD COLUMN sealed_secrets_certificate text; -- pem encoded certificate of the sealed secrets controller
+
+-- cluster secrets, only sealed values are stored
+CREATE TABLE tb_cluster_secret
+(
+    id           bigserial    NOT NULL,
+    cluster_id   bigint       NOT NULL, -- cluster id
+    name         varchar(253) NOT NULL, -- name of the secret, which is the key in the kubernetes secret
+    sealed_value text         NOT NULL, -- value sealed with the sealed secrets certificate of the region
+    version      bigint       NOT NULL DEFAULT 1, -- version of the value, increased on rotation
+    created_at   timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by   bigint       NOT NULL DEFAULT 0, -- creator
+    updated_by   bigint       NOT NULL DEFAULT 0, -- user who rotated the value last time
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_cluster_secret_idx_cluster_name ON tb_cluster_secret (cluster_id, name);
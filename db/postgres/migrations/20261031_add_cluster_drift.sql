@@ -0,0 +1,29 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+
+-- latest configuration drift detected of clusters
+CREATE TABLE tb_cluster_drift
+(
+    id          bigserial   NOT NULL,
+    cluster_id  bigint      NOT NULL, -- cluster id
+    drifted     boolean     NOT NULL DEFAULT false, -- whether live objects differ from the desired state
+    resources   text, -- json encoded drifted resources and their field diff
+    detected_at timestamptz          DEFAULT NULL, -- time drift was detected first
+    checked_at  timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP, -- time of the last detection
+    created_at  timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_cluster_drift_idx_cluster_id ON tb_cluster_drift (cluster_id);
@@ -0,0 +1,47 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+
+-- quotas of groups and environments, zero or empty limits are unlimited
+CREATE TABLE tb_quota
+(
+    id            bigserial    NOT NULL,
+    resource_type varchar(32)  NOT NULL, -- groups or environments
+    resource_id   bigint       NOT NULL, -- id of the group or environment
+    environment   varchar(128) NOT NULL DEFAULT '', -- environment restricted by a group quota, empty for all
+    max_clusters  integer      NOT NULL DEFAULT 0, -- max number of clusters
+    max_cpu       varchar(64)  NOT NULL DEFAULT '', -- max total cpu requests of clusters, such as 16
+    max_memory    varchar(64)  NOT NULL DEFAULT '', -- max total memory requests of clusters, such as 32Gi
+    max_replicas  integer      NOT NULL DEFAULT 0, -- max total replicas of clusters
+    created_by    bigint       NOT NULL DEFAULT 0, -- creator
+    updated_by    bigint       NOT NULL DEFAULT 0, -- updater
+    created_at    timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_quota_idx_resource_environment ON tb_quota (resource_type, resource_id, environment);
+
+-- resources requested by clusters, calculated from the rendered values of clusters
+CREATE TABLE tb_cluster_usage
+(
+    id         bigserial   NOT NULL,
+    cluster_id bigint      NOT NULL, -- cluster id
+    cpu        bigint      NOT NULL DEFAULT 0, -- cpu requests in millicores
+    memory     bigint      NOT NULL DEFAULT 0, -- memory requests in bytes
+    replicas   integer     NOT NULL DEFAULT 0, -- replicas of workloads
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX tb_cluster_usage_idx_cluster_id ON tb_cluster_usage (cluster_id);
@@ -0,0 +1,30 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- resources reserved under quotas by admitted cluster requests, until usage of the cluster is saved or they expire
+CREATE TABLE tb_quota_reservation
+(
+    id         bigserial    NOT NULL,
+    quota_id   bigint       NOT NULL, -- quota id
+    cluster    varchar(128) NOT NULL, -- name of the cluster
+    clusters   integer      NOT NULL DEFAULT 0, -- 1 if the cluster is new to the quota
+    cpu        bigint       NOT NULL DEFAULT 0, -- cpu requests in millicores
+    memory     bigint       NOT NULL DEFAULT 0, -- memory requests in bytes
+    replicas   integer      NOT NULL DEFAULT 0, -- replicas of workloads
+    expires_at timestamptz  NOT NULL, -- the reservation is not counted after it expires
+    created_at timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+CREATE INDEX tb_quota_reservation_idx_quota_id ON tb_quota_reservation (quota_id);
+CREATE INDEX tb_quota_reservation_idx_cluster ON tb_quota_reservation (cluster);
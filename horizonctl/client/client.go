@@ -0,0 +1,183 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const (
+	tokenHeader      = "Authorization"
+	tokenValuePrefix = "Bearer"
+	userAgent        = "horizonctl"
+)
+
+// Client calls the REST API of horizon on behalf of the owner of an access token
+type Client struct {
+	server     string
+	token      string
+	httpClient *http.Client
+}
+
+func New(server, token string) *Client {
+	return &Client{
+		server: strings.TrimSuffix(server, "/"),
+		token:  token,
+		// no timeout as logs are streamed until the server closes the connection
+		httpClient: &http.Client{},
+	}
+}
+
+// Error is returned when horizon responds with a non 2xx status code
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" && e.Message == "" {
+		return fmt.Sprintf("request failed with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("request failed with status %d, code: %s, message: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// IsUnauthorized returns true if the token is missing, expired or not permitted to the resource
+func IsUnauthorized(err error) bool {
+	e, ok := perror.Cause(err).(*Error)
+	return ok && (e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden)
+}
+
+func IsNotFound(err error) bool {
+	e, ok := perror.Cause(err).(*Error)
+	return ok && e.StatusCode == http.StatusNotFound
+}
+
+// response is the envelope of horizon's responses
+type response struct {
+	ErrorCode    string          `json:"errorCode,omitempty"`
+	ErrorMessage string          `json:"errorMessage,omitempty"`
+	Data         json.RawMessage `json:"data,omitempty"`
+}
+
+// do sends a request with json body and decodes data of the response into data if it's not nil
+func (c *Client) do(ctx context.Context, method, path string, query url.Values,
+	body, data interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return perror.Wrap(err, "failed to marshal request body")
+		}
+		reader = bytes.NewReader(b)
+	}
+	resp, err := c.send(ctx, method, path, query, reader)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var r response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil && err != io.EOF {
+		return perror.Wrapf(err, "failed to decode response of %s %s", method, path)
+	}
+	if data == nil || len(r.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(r.Data, data); err != nil {
+		return perror.Wrapf(err, "failed to decode data of %s %s", method, path)
+	}
+	return nil
+}
+
+// stream copies the plain text response to w until the server closes the connection or ctx is done
+func (c *Client) stream(ctx context.Context, path string, query url.Values, w io.Writer) error {
+	resp, err := c.send(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(w, resp.Body); err != nil && ctx.Err() == nil {
+		return perror.Wrapf(err, "failed to read %s", path)
+	}
+	return nil
+}
+
+// send sends the request and returns the response if the status code is 2xx, otherwise returns *Error
+func (c *Client) send(ctx context.Context, method, path string, query url.Values,
+	body io.Reader) (*http.Response, error) {
+	u := c.server + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, perror.Wrapf(err, "failed to create request of %s %s", method, path)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set(tokenHeader, fmt.Sprintf("%s %s", tokenValuePrefix, c.token))
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, perror.Wrapf(err, "failed to request %s %s", method, path)
+	}
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	e := &Error{StatusCode: resp.StatusCode}
+	b, _ := io.ReadAll(resp.Body)
+	var r response
+	if err := json.Unmarshal(b, &r); err == nil {
+		e.Code, e.Message = r.ErrorCode, r.ErrorMessage
+	} else {
+		e.Message = strings.TrimSpace(string(b))
+	}
+	return nil, e
+}
+
+// wait calls fn every interval until it returns true or error, or ctx is done
+func wait(ctx context.Context, interval time.Duration, fn func() (bool, error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		done, err := fn()
+		if err != nil || done {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
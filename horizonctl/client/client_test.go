@@ -0,0 +1,129 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeData(w http.ResponseWriter, data interface{}) {
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+func TestResolveCluster(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(_clustersPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		page, _ := strconv.Atoi(r.URL.Query().Get("pageNumber"))
+		items := []*Cluster{}
+		switch page {
+		case 1:
+			for i := 1; i <= maxPageSize; i++ {
+				items = append(items, &Cluster{ID: uint(i), Name: fmt.Sprintf("app-%d", i)})
+			}
+		case 2:
+			items = append(items, &Cluster{ID: 100, Name: "app"})
+		}
+		writeData(w, map[string]interface{}{"total": maxPageSize + 1, "items": items})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	c := New(server.URL, "token")
+	ctx := context.Background()
+
+	id, err := c.ResolveCluster(ctx, "42")
+	assert.Nil(t, err)
+	assert.Equal(t, uint(42), id)
+
+	id, err = c.ResolveCluster(ctx, "app")
+	assert.Nil(t, err)
+	assert.Equal(t, uint(100), id)
+
+	_, err = New(server.URL, "token").ResolveCluster(ctx, "app-not-exist")
+	assert.NotNil(t, err)
+}
+
+func TestError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case clusterPath(1, ""):
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"errorCode": "NotFound", "errorMessage": "cluster not found"})
+		case clusterPath(2, ""):
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte("unauthorized"))
+		case clusterPath(3, "restart"):
+			assert.Equal(t, http.MethodPost, r.Method)
+			writeData(w, map[string]uint{"pipelinerunID": 10})
+		}
+	}))
+	defer server.Close()
+	c := New(server.URL, "token")
+	ctx := context.Background()
+
+	_, err := c.GetCluster(ctx, 1)
+	assert.True(t, IsNotFound(err))
+	assert.Equal(t, "cluster not found", err.(*Error).Message)
+
+	_, err = c.GetCluster(ctx, 2)
+	assert.True(t, IsUnauthorized(err))
+	assert.Equal(t, "unauthorized", err.(*Error).Message)
+
+	prID, err := c.Restart(ctx, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(10), prID)
+}
+
+func TestDeviceLogin(t *testing.T) {
+	polls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc(_deviceCodePath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "cli", r.PostFormValue("client_id"))
+		_ = json.NewEncoder(w).Encode(&DeviceCode{
+			DeviceCode: "device", UserCode: "ABCD-EFGH", ExpiresIn: 60, Interval: 1})
+	})
+	mux.HandleFunc(_accessTokenPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, grantTypeDeviceCode, r.PostFormValue("grant_type"))
+		assert.Equal(t, "device", r.PostFormValue("device_code"))
+		polls++
+		if polls < 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"errorCode": errorAuthorizationPending})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(&Token{AccessToken: "access-token"})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var userCode string
+	c := New(server.URL, "")
+	token, err := c.DeviceLogin(context.Background(), "cli", "", func(code *DeviceCode) {
+		userCode = code.UserCode
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "ABCD-EFGH", userCode)
+	assert.Equal(t, "access-token", token.AccessToken)
+	assert.Equal(t, 2, polls)
+}
@@ -0,0 +1,193 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const (
+	_clustersPath = "/apis/core/v2/clusters"
+
+	// maxPageSize is the max page size allowed by horizon
+	maxPageSize = 50
+)
+
+func clusterPath(clusterID uint, subPath string) string {
+	if subPath == "" {
+		return fmt.Sprintf("%s/%d", _clustersPath, clusterID)
+	}
+	return fmt.Sprintf("%s/%d/%s", _clustersPath, clusterID, subPath)
+}
+
+func (c *Client) ListClusters(ctx context.Context, opts *ListClustersOptions) ([]*Cluster, int64, error) {
+	query := url.Values{}
+	if opts.Filter != "" {
+		query.Set("filter", opts.Filter)
+	}
+	for _, env := range opts.Environments {
+		query.Add("environment", env)
+	}
+	if opts.Region != "" {
+		query.Set("region", opts.Region)
+	}
+	if opts.Template != "" {
+		query.Set("template", opts.Template)
+	}
+	if opts.TagSelector != "" {
+		query.Set("tagSelector", opts.TagSelector)
+	}
+	if opts.PageNumber > 0 {
+		query.Set("pageNumber", strconv.Itoa(opts.PageNumber))
+	}
+	if opts.PageSize > 0 {
+		query.Set("pageSize", strconv.Itoa(opts.PageSize))
+	}
+
+	var data dataWithTotal
+	if err := c.do(ctx, http.MethodGet, _clustersPath, query, nil, &data); err != nil {
+		return nil, 0, err
+	}
+	var clusters []*Cluster
+	if len(data.Items) > 0 {
+		if err := json.Unmarshal(data.Items, &clusters); err != nil {
+			return nil, 0, perror.Wrap(err, "failed to decode clusters")
+		}
+	}
+	return clusters, data.Total, nil
+}
+
+// ResolveCluster returns the id of the cluster, nameOrID is an id or the exact name of a cluster
+func (c *Client) ResolveCluster(ctx context.Context, nameOrID string) (uint, error) {
+	if id, err := strconv.ParseUint(nameOrID, 10, 0); err == nil {
+		return uint(id), nil
+	}
+	// clusters are filtered by name fuzzily, so look through pages for the exact one
+	opts := &ListClustersOptions{Filter: nameOrID, PageNumber: 1, PageSize: maxPageSize}
+	for {
+		clusters, total, err := c.ListClusters(ctx, opts)
+		if err != nil {
+			return 0, err
+		}
+		for _, cluster := range clusters {
+			if cluster.Name == nameOrID {
+				return cluster.ID, nil
+			}
+		}
+		if len(clusters) == 0 || int64(opts.PageNumber*opts.PageSize) >= total {
+			return 0, &Error{StatusCode: http.StatusNotFound, Message: fmt.Sprintf("cluster %s not found", nameOrID)}
+		}
+		opts.PageNumber++
+	}
+}
+
+func (c *Client) GetCluster(ctx context.Context, clusterID uint) (*ClusterDetail, error) {
+	var cluster ClusterDetail
+	if err := c.do(ctx, http.MethodGet, clusterPath(clusterID, ""), nil, nil, &cluster); err != nil {
+		return nil, err
+	}
+	return &cluster, nil
+}
+
+// GetClusterStatus returns the status of the workloads of the cluster, such as Healthy, Progressing and Degraded
+func (c *Client) GetClusterStatus(ctx context.Context, clusterID uint) (string, error) {
+	var status struct {
+		Status string `json:"status"`
+	}
+	if err := c.do(ctx, http.MethodGet, clusterPath(clusterID, "status"), nil, nil, &status); err != nil {
+		return "", err
+	}
+	return status.Status, nil
+}
+
+func (c *Client) BuildDeploy(ctx context.Context, clusterID uint, request *BuildDeployRequest) (uint, error) {
+	return c.createPipelinerun(ctx, clusterID, "builddeploy", request)
+}
+
+func (c *Client) Deploy(ctx context.Context, clusterID uint, request *DeployRequest) (uint, error) {
+	return c.createPipelinerun(ctx, clusterID, "deploy", request)
+}
+
+// Rollback rolls the cluster back to the state after the pipelinerun
+func (c *Client) Rollback(ctx context.Context, clusterID, pipelinerunID uint) (uint, error) {
+	return c.createPipelinerun(ctx, clusterID, "rollback", map[string]uint{"pipelinerunID": pipelinerunID})
+}
+
+func (c *Client) Restart(ctx context.Context, clusterID uint) (uint, error) {
+	return c.createPipelinerun(ctx, clusterID, "restart", nil)
+}
+
+func (c *Client) createPipelinerun(ctx context.Context, clusterID uint, action string,
+	request interface{}) (uint, error) {
+	var resp pipelinerunIDResponse
+	if err := c.do(ctx, http.MethodPost, clusterPath(clusterID, action), nil, request, &resp); err != nil {
+		return 0, err
+	}
+	return resp.PipelinerunID, nil
+}
+
+// GetDiff returns the diff between the config of the cluster and the config to be deployed,
+// code info is returned if ref is not empty
+func (c *Client) GetDiff(ctx context.Context, clusterID uint, ref *GitRef) (*Diff, error) {
+	query := url.Values{}
+	if ref != nil {
+		switch {
+		case ref.Tag != "":
+			query.Set("targetTag", ref.Tag)
+		case ref.Branch != "":
+			query.Set("targetBranch", ref.Branch)
+		case ref.Commit != "":
+			query.Set("targetCommit", ref.Commit)
+		}
+	}
+	var diff Diff
+	if err := c.do(ctx, http.MethodGet, clusterPath(clusterID, "diffs"), query, nil, &diff); err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
+// ContainerLog writes logs of the cluster's containers to w, it blocks until ctx is done if logs are followed
+func (c *Client) ContainerLog(ctx context.Context, clusterID uint, opts *ContainerLogOptions, w io.Writer) error {
+	query := url.Values{}
+	if opts.PodName != "" {
+		query.Set("podName", opts.PodName)
+	}
+	if opts.ContainerName != "" {
+		query.Set("containerName", opts.ContainerName)
+	}
+	if opts.TailLines > 0 {
+		query.Set("tailLines", strconv.FormatInt(opts.TailLines, 10))
+	}
+	if opts.SinceTime != nil {
+		query.Set("sinceTime", opts.SinceTime.Format(time.RFC3339))
+	}
+	if opts.Follow {
+		query.Set("follow", "true")
+	}
+	if opts.Previous {
+		query.Set("previous", "true")
+	}
+	return c.stream(ctx, clusterPath(clusterID, "containerlog"), query, w)
+}
@@ -0,0 +1,137 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const (
+	_deviceCodePath  = "/login/oauth/device/code"
+	_accessTokenPath = "/login/oauth/access_token"
+
+	grantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+
+	// error codes of device authorization grant, ref: rfc8628 3.5
+	errorAuthorizationPending = "authorization_pending"
+	errorSlowDown             = "slow_down"
+
+	defaultDeviceInterval = 5 * time.Second
+)
+
+// DeviceCode is the device authorization response, ref: rfc8628 3.2
+type DeviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+type Token struct {
+	AccessToken  string        `json:"access_token"`
+	RefreshToken string        `json:"refresh_token,omitempty"`
+	ExpiresIn    time.Duration `json:"expires_in"`
+	Scope        string        `json:"scope"`
+	TokenType    string        `json:"token_type"`
+}
+
+// DeviceLogin gets an access token by the device authorization grant of the oauth app,
+// prompt is called with the user code to show to the user, and DeviceLogin blocks until
+// the user authorizes or denies the device, or the device code expires
+func (c *Client) DeviceLogin(ctx context.Context, clientID, scope string,
+	prompt func(code *DeviceCode)) (*Token, error) {
+	var code DeviceCode
+	if err := c.postForm(ctx, _deviceCodePath, url.Values{
+		"client_id": {clientID},
+		"scope":     {scope},
+	}, &code); err != nil {
+		return nil, err
+	}
+	prompt(&code)
+
+	if code.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(code.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+	interval := defaultDeviceInterval
+	if code.Interval > 0 {
+		interval = time.Duration(code.Interval) * time.Second
+	}
+	form := url.Values{
+		"grant_type":  {grantTypeDeviceCode},
+		"client_id":   {clientID},
+		"device_code": {code.DeviceCode},
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, perror.Wrap(ctx.Err(), "device code expired before authorization")
+		case <-time.After(interval):
+		}
+
+		var token Token
+		err := c.postForm(ctx, _accessTokenPath, form, &token)
+		if err == nil {
+			return &token, nil
+		}
+		e, ok := perror.Cause(err).(*Error)
+		if !ok {
+			return nil, err
+		}
+		switch e.Code {
+		case errorAuthorizationPending:
+		case errorSlowDown:
+			interval += defaultDeviceInterval
+		default:
+			return nil, err
+		}
+	}
+}
+
+// postForm posts the form to oauth endpoints, which respond with plain json instead of the envelope
+func (c *Client) postForm(ctx context.Context, path string, form url.Values, data interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.server+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return perror.Wrapf(err, "failed to create request of %s", path)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return perror.Wrapf(err, "failed to request %s", path)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var r response
+		_ = json.NewDecoder(resp.Body).Decode(&r)
+		return &Error{StatusCode: resp.StatusCode, Code: r.ErrorCode, Message: r.ErrorMessage}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(data); err != nil {
+		return perror.Wrapf(err, "failed to decode response of %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	_pipelinerunsPath = "/apis/core/v2/pipelineruns"
+
+	StatusOK        = "ok"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// IsFinished returns true if the pipelinerun is in a final status
+func (p *Pipelinerun) IsFinished() bool {
+	return p.Status == StatusOK || p.Status == StatusFailed || p.Status == StatusCancelled
+}
+
+func (c *Client) GetPipelinerun(ctx context.Context, pipelinerunID uint) (*Pipelinerun, error) {
+	var pr Pipelinerun
+	path := fmt.Sprintf("%s/%d", _pipelinerunsPath, pipelinerunID)
+	if err := c.do(ctx, http.MethodGet, path, nil, nil, &pr); err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// PipelinerunLog writes logs of the pipelinerun to w, logs are streamed until the pipelinerun finishes
+func (c *Client) PipelinerunLog(ctx context.Context, pipelinerunID uint, w io.Writer) error {
+	return c.stream(ctx, fmt.Sprintf("%s/%d/log", _pipelinerunsPath, pipelinerunID), nil, w)
+}
+
+// WaitPipelinerun polls the pipelinerun every interval until it finishes
+func (c *Client) WaitPipelinerun(ctx context.Context, pipelinerunID uint,
+	interval time.Duration) (*Pipelinerun, error) {
+	var pr *Pipelinerun
+	err := wait(ctx, interval, func() (bool, error) {
+		var err error
+		pr, err = c.GetPipelinerun(ctx, pipelinerunID)
+		if err != nil {
+			return false, err
+		}
+		return pr.IsFinished(), nil
+	})
+	return pr, err
+}
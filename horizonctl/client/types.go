@@ -0,0 +1,154 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// types mirror the json of horizon's responses, only fields used by horizonctl are kept
+
+type Scope struct {
+	Environment string `json:"environment"`
+	Region      string `json:"region"`
+}
+
+type Git struct {
+	URL       string `json:"url"`
+	Subfolder string `json:"subfolder,omitempty"`
+	Branch    string `json:"branch,omitempty"`
+	Tag       string `json:"tag,omitempty"`
+	Commit    string `json:"commit,omitempty"`
+}
+
+// Cluster is an item of cluster list
+type Cluster struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Scope       *Scope    `json:"scope"`
+	Git         *Git      `json:"git"`
+	FullPath    string    `json:"fullPath,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type ClusterDetail struct {
+	ID              uint      `json:"id"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description"`
+	Priority        string    `json:"priority"`
+	ExpireTime      string    `json:"expireTime"`
+	Scope           *Scope    `json:"scope"`
+	FullPath        string    `json:"fullPath"`
+	ApplicationName string    `json:"applicationName"`
+	ApplicationID   uint      `json:"applicationID"`
+	Git             *Git      `json:"git"`
+	Image           string    `json:"image"`
+	Status          string    `json:"status"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+type ListClustersOptions struct {
+	// Filter matches clusters whose name contains it
+	Filter       string
+	Environments []string
+	Region       string
+	Template     string
+	TagSelector  string
+	PageNumber   int
+	PageSize     int
+}
+
+// GitRef is one of branch, tag and commit to build with or to diff with
+type GitRef struct {
+	Branch string `json:"branch,omitempty"`
+	Tag    string `json:"tag,omitempty"`
+	Commit string `json:"commit,omitempty"`
+}
+
+type BuildDeployRequest struct {
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	Git         *GitRef `json:"git"`
+}
+
+type DeployRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	ImageTag    string `json:"imageTag"`
+}
+
+type Diff struct {
+	CodeInfo   *CodeInfo `json:"codeInfo"`
+	ConfigDiff string    `json:"configDiff"`
+}
+
+type CodeInfo struct {
+	Branch    string `json:"branch,omitempty"`
+	Tag       string `json:"tag,omitempty"`
+	CommitID  string `json:"commitID"`
+	CommitMsg string `json:"commitMsg"`
+	Link      string `json:"link"`
+}
+
+type ContainerLogOptions struct {
+	// PodName is the pod to get logs, logs of all pods are merged if it's empty
+	PodName       string
+	ContainerName string
+	TailLines     int64
+	SinceTime     *time.Time
+	Follow        bool
+	Previous      bool
+}
+
+type Pipelinerun struct {
+	ID          uint       `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Action      string     `json:"action"`
+	Status      string     `json:"status"`
+	GitURL      string     `json:"gitURL"`
+	GitBranch   string     `json:"gitBranch,omitempty"`
+	GitTag      string     `json:"gitTag,omitempty"`
+	GitCommit   string     `json:"gitCommit"`
+	ImageURL    string     `json:"imageURL"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	StartedAt   *time.Time `json:"startedAt"`
+	FinishedAt  *time.Time `json:"finishedAt"`
+	CanRollback bool       `json:"canRollback"`
+	CreatedBy   struct {
+		UserID   uint   `json:"userID"`
+		UserName string `json:"userName"`
+	} `json:"createdBy"`
+}
+
+type dataWithTotal struct {
+	Total int64           `json:"total"`
+	Items json.RawMessage `json:"items"`
+}
+
+type pipelinerunIDResponse struct {
+	PipelinerunID uint `json:"pipelinerunID"`
+}
+
+type User struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	FullName string `json:"fullName"`
+	Email    string `json:"email"`
+}
@@ -0,0 +1,166 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/horizonctl/client"
+	"github.com/horizoncd/horizon/horizonctl/printer"
+)
+
+func init() {
+	register(&command{
+		name:  "clusters",
+		usage: "(list [flags] | get <cluster> [flags])",
+		short: "List clusters or get a cluster",
+		run:   runClusters,
+	})
+}
+
+// stringSlice is a flag that can be set multiple times
+type stringSlice []string
+
+func (s *stringSlice) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSlice) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+func runClusters(ctx context.Context, stdout, stderr io.Writer, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("subcommand is required")
+	}
+	switch args[0] {
+	case "list":
+		return runListClusters(ctx, stdout, stderr, args[1:])
+	case "get":
+		return runGetCluster(ctx, stdout, stderr, args[1:])
+	default:
+		return usageErrorf("unknown subcommand %q", args[0])
+	}
+}
+
+func runListClusters(ctx context.Context, stdout, stderr io.Writer, args []string) error {
+	fs, o := newFlagSet("clusters list", stderr, true)
+	var environments stringSlice
+	opts := &client.ListClustersOptions{}
+	fs.StringVar(&opts.Filter, "filter", "", "list clusters whose name contains the filter")
+	fs.Var(&environments, "env", "list clusters in the environment, can be set multiple times")
+	fs.StringVar(&opts.Region, "region", "", "list clusters in the region")
+	fs.StringVar(&opts.Template, "template", "", "list clusters of the template")
+	fs.StringVar(&opts.TagSelector, "tag-selector", "", "list clusters matching the tag selector, e.g. key1=v1,key2")
+	fs.IntVar(&opts.PageNumber, "page", 1, "page number")
+	fs.IntVar(&opts.PageSize, "page-size", 20, "page size, at most 50")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usageErrorf("unexpected arguments %v", positional)
+	}
+	opts.Environments = environments
+
+	c, err := o.client()
+	if err != nil {
+		return err
+	}
+	clusters, total, err := c.ListClusters(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if clusters == nil {
+		clusters = []*client.Cluster{}
+	}
+	obj := map[string]interface{}{"total": total, "items": clusters}
+	return o.print(stdout, obj, func() *printer.Table {
+		table := &printer.Table{Header: []string{"id", "name", "environment", "region", "git ref", "updated at"}}
+		for _, cluster := range clusters {
+			var env, region string
+			if cluster.Scope != nil {
+				env, region = cluster.Scope.Environment, cluster.Scope.Region
+			}
+			table.AddRow(strconv.FormatUint(uint64(cluster.ID), 10), cluster.Name, env, region,
+				gitRef(cluster.Git), cluster.UpdatedAt.Format(time.RFC3339))
+		}
+		return table
+	})
+}
+
+func runGetCluster(ctx context.Context, stdout, stderr io.Writer, args []string) error {
+	fs, o := newFlagSet("clusters get", stderr, true)
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageErrorf("exactly one cluster is required")
+	}
+
+	c, err := o.client()
+	if err != nil {
+		return err
+	}
+	clusterID, err := c.ResolveCluster(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	cluster, err := c.GetCluster(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	return o.print(stdout, cluster, func() *printer.Table {
+		table := &printer.Table{}
+		table.AddRow("ID:", strconv.FormatUint(uint64(cluster.ID), 10))
+		table.AddRow("Name:", cluster.Name)
+		table.AddRow("Application:", cluster.ApplicationName)
+		if cluster.Scope != nil {
+			table.AddRow("Environment:", cluster.Scope.Environment)
+			table.AddRow("Region:", cluster.Scope.Region)
+		}
+		if cluster.Git != nil {
+			table.AddRow("Git:", cluster.Git.URL)
+			table.AddRow("Git Ref:", gitRef(cluster.Git))
+		}
+		table.AddRow("Image:", cluster.Image)
+		table.AddRow("Status:", cluster.Status)
+		table.AddRow("Priority:", cluster.Priority)
+		table.AddRow("Updated At:", cluster.UpdatedAt.Format(time.RFC3339))
+		return table
+	})
+}
+
+func gitRef(git *client.Git) string {
+	if git == nil {
+		return ""
+	}
+	switch {
+	case git.Tag != "":
+		return fmt.Sprintf("tag:%s", git.Tag)
+	case git.Branch != "":
+		return fmt.Sprintf("branch:%s", git.Branch)
+	case git.Commit != "":
+		return fmt.Sprintf("commit:%s", git.Commit)
+	}
+	return ""
+}
@@ -0,0 +1,256 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/horizoncd/horizon/horizonctl/client"
+	"github.com/horizoncd/horizon/horizonctl/printer"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// exit codes of horizonctl, so that CI can tell why it fails
+const (
+	ExitOK = iota
+	ExitError
+	ExitUsage
+	ExitUnauthorized
+	ExitNotFound
+	ExitPipelinerunFailed
+	ExitTimeout
+	ExitDiffFound
+)
+
+const (
+	EnvServer = "HORIZON_SERVER"
+	EnvToken  = "HORIZON_TOKEN"
+	EnvConfig = "HORIZON_CONFIG"
+)
+
+// exitError makes horizonctl exit with code
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return ""
+	}
+	return e.err.Error()
+}
+
+func usageErrorf(format string, args ...interface{}) error {
+	return &exitError{code: ExitUsage, err: fmt.Errorf(format, args...)}
+}
+
+type command struct {
+	name string
+	// usage is the synopsis of the command after its name
+	usage string
+	short string
+	run   func(ctx context.Context, stdout, stderr io.Writer, args []string) error
+}
+
+var commands = map[string]*command{}
+
+func register(cmd *command) {
+	commands[cmd.name] = cmd
+}
+
+// Run runs horizonctl with args without the program name, and returns the exit code
+func Run(args []string) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return run(ctx, os.Stdout, os.Stderr, args)
+}
+
+func run(ctx context.Context, stdout, stderr io.Writer, args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stderr)
+		if len(args) == 0 {
+			return ExitUsage
+		}
+		return ExitOK
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		printUsage(stderr)
+		return ExitUsage
+	}
+
+	err := cmd.run(ctx, stdout, stderr, args[1:])
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+	code := exitCode(err)
+	if msg := err.Error(); msg != "" {
+		fmt.Fprintf(stderr, "Error: %s\n", msg)
+	}
+	if code == ExitUsage {
+		fmt.Fprintf(stderr, "Usage: horizonctl %s %s\n", cmd.name, cmd.usage)
+	}
+	return code
+}
+
+func exitCode(err error) int {
+	var e *exitError
+	if errors.As(err, &e) {
+		return e.code
+	}
+	switch {
+	case client.IsUnauthorized(err):
+		return ExitUnauthorized
+	case client.IsNotFound(err):
+		return ExitNotFound
+	case perror.Cause(err) == context.DeadlineExceeded:
+		return ExitTimeout
+	}
+	return ExitError
+}
+
+func printUsage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "horizonctl controls clusters of Horizon.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Usage: horizonctl <command> [arguments] [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-14s %s\n", name, commands[name].short)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Server and token are read from flags, %s and %s, or the config file written by login.\n",
+		EnvServer, EnvToken)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Exit codes:")
+	fmt.Fprintln(w, "  0  success")
+	fmt.Fprintln(w, "  1  error")
+	fmt.Fprintln(w, "  2  invalid usage")
+	fmt.Fprintln(w, "  3  unauthorized or forbidden")
+	fmt.Fprintln(w, "  4  not found")
+	fmt.Fprintln(w, "  5  pipelinerun failed or cancelled")
+	fmt.Fprintln(w, "  6  timeout")
+	fmt.Fprintln(w, "  7  diff found, with diff -exit-code")
+}
+
+// options are flags shared by commands
+type options struct {
+	server     string
+	token      string
+	configFile string
+	output     string
+}
+
+func newFlagSet(name string, stderr io.Writer, withOutput bool) (*flag.FlagSet, *options) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	o := &options{}
+	fs.StringVar(&o.server, "server", "", fmt.Sprintf("horizon server url, overrides %s", EnvServer))
+	fs.StringVar(&o.token, "token", "", fmt.Sprintf("access token, overrides %s", EnvToken))
+	fs.StringVar(&o.configFile, "config", "", fmt.Sprintf("config file, overrides %s, default %s",
+		EnvConfig, defaultConfigFile()))
+	if withOutput {
+		fs.StringVar(&o.output, "o", printer.FormatTable, "output format, one of "+
+			strings.Join(printer.Formats, ", "))
+		fs.StringVar(&o.output, "output", printer.FormatTable, "output format, same as -o")
+	}
+	return fs, o
+}
+
+// parseFlags parses flags mixed with positional args, and returns the positional args
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, &exitError{code: ExitUsage}
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// client returns the client with server and token from flags, env or config file in order
+func (o *options) client() (*client.Client, error) {
+	if o.output != "" {
+		if err := printer.ValidateFormat(o.output); err != nil {
+			return nil, &exitError{code: ExitUsage, err: err}
+		}
+	}
+	server, token := o.server, o.token
+	if server == "" {
+		server = os.Getenv(EnvServer)
+	}
+	if token == "" {
+		token = os.Getenv(EnvToken)
+	}
+	if server == "" || token == "" {
+		config, err := loadConfig(o.configPath())
+		if err != nil {
+			return nil, err
+		}
+		if server == "" {
+			server = config.Server
+		}
+		if token == "" {
+			token = config.Token
+		}
+	}
+	if server == "" {
+		return nil, usageErrorf("server is not set, run login or set -server or %s", EnvServer)
+	}
+	if token == "" {
+		return nil, &exitError{code: ExitUnauthorized,
+			err: fmt.Errorf("token is not set, run login or set -token or %s", EnvToken)}
+	}
+	return client.New(server, token), nil
+}
+
+func (o *options) configPath() string {
+	if o.configFile != "" {
+		return o.configFile
+	}
+	if path := os.Getenv(EnvConfig); path != "" {
+		return path
+	}
+	return defaultConfigFile()
+}
+
+func (o *options) print(w io.Writer, obj interface{}, table func() *printer.Table) error {
+	return printer.Print(w, o.output, obj, table)
+}
@@ -0,0 +1,114 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeData(w http.ResponseWriter, data interface{}) {
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/apis/core/v2/users/self", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeData(w, map[string]interface{}{"id": 1, "name": "tony"})
+	})
+	mux.HandleFunc("/apis/core/v2/clusters/1/builddeploy", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Git map[string]string `json:"git"`
+		}
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "main", request.Git["branch"])
+		writeData(w, map[string]uint{"pipelinerunID": 10})
+	})
+	mux.HandleFunc("/apis/core/v2/clusters/1/restart", func(w http.ResponseWriter, r *http.Request) {
+		writeData(w, map[string]uint{"pipelinerunID": 11})
+	})
+	mux.HandleFunc("/apis/core/v2/pipelineruns/10", func(w http.ResponseWriter, r *http.Request) {
+		writeData(w, map[string]interface{}{"id": 10, "action": "builddeploy", "status": "failed"})
+	})
+	mux.HandleFunc("/apis/core/v2/pipelineruns/11", func(w http.ResponseWriter, r *http.Request) {
+		writeData(w, map[string]interface{}{"id": 11, "action": "restart", "status": "ok"})
+	})
+	mux.HandleFunc("/apis/core/v2/clusters/1/diffs", func(w http.ResponseWriter, r *http.Request) {
+		writeData(w, map[string]interface{}{"configDiff": "-a\n+b\n"})
+	})
+	mux.HandleFunc("/apis/core/v2/clusters/2", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestRun(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(EnvConfig, configFile)
+	t.Setenv(EnvServer, "")
+	t.Setenv(EnvToken, "")
+
+	run := func(args ...string) (int, string) {
+		var stdout, stderr bytes.Buffer
+		code := run(context.Background(), &stdout, &stderr, args)
+		return code, stdout.String()
+	}
+
+	code, _ := run("clusters", "get", "1")
+	assert.Equal(t, ExitUsage, code)
+
+	code, _ = run("login", "-server", server.URL, "-token", "invalid")
+	assert.Equal(t, ExitUnauthorized, code)
+	code, _ = run("login", "-server", server.URL, "-token", "token")
+	assert.Equal(t, ExitOK, code)
+	config, err := loadConfig(configFile)
+	assert.Nil(t, err)
+	assert.Equal(t, &Config{Server: server.URL, Token: "token"}, config)
+
+	code, out := run("restart", "1", "-wait", "-o", "json")
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, out, `"status": "ok"`)
+
+	code, _ = run("build-deploy", "1", "-branch", "main", "-tag", "v1")
+	assert.Equal(t, ExitUsage, code)
+	code, out = run("build-deploy", "1", "-branch", "main", "-wait", "-o", "yaml")
+	assert.Equal(t, ExitPipelinerunFailed, code)
+	assert.Contains(t, out, "status: failed")
+
+	code, out = run("diff", "1")
+	assert.Equal(t, ExitOK, code)
+	assert.Equal(t, "-a\n+b\n", out)
+	code, _ = run("diff", "1", "-exit-code")
+	assert.Equal(t, ExitDiffFound, code)
+
+	code, _ = run("clusters", "get", "2")
+	assert.Equal(t, ExitNotFound, code)
+
+	code, _ = run("clusters", "get", "1", "-o", "xml")
+	assert.Equal(t, ExitUsage, code)
+}
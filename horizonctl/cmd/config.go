@@ -0,0 +1,69 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// Config is saved by login so that later commands do not need server and token
+type Config struct {
+	Server string `json:"server"`
+	Token  string `json:"token"`
+}
+
+func defaultConfigFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".horizon", "config.yaml")
+	}
+	return filepath.Join(home, ".horizon", "config.yaml")
+}
+
+// loadConfig returns empty config if the file does not exist
+func loadConfig(path string) (*Config, error) {
+	var config Config
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &config, nil
+		}
+		return nil, perror.Wrapf(err, "failed to read config file %s", path)
+	}
+	if err := yaml.Unmarshal(b, &config); err != nil {
+		return nil, perror.Wrapf(err, "failed to parse config file %s", path)
+	}
+	return &config, nil
+}
+
+// saveConfig writes the config only readable by the current user as it contains the token
+func saveConfig(path string, config *Config) error {
+	b, err := yaml.Marshal(config)
+	if err != nil {
+		return perror.Wrap(err, "failed to marshal config")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return perror.Wrapf(err, "failed to create dir of config file %s", path)
+	}
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		return perror.Wrapf(err, "failed to write config file %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/horizoncd/horizon/horizonctl/client"
+	"github.com/horizoncd/horizon/horizonctl/printer"
+)
+
+func init() {
+	register(&command{
+		name:  "diff",
+		usage: "<cluster> [-branch <branch> | -tag <tag> | -commit <commit>] [-exit-code]",
+		short: "Show the config diff to be deployed, and the code info of a git ref",
+		run:   runDiff,
+	})
+}
+
+func runDiff(ctx context.Context, stdout, stderr io.Writer, args []string) error {
+	fs, o := newFlagSet("diff", stderr, true)
+	ref := &client.GitRef{}
+	fs.StringVar(&ref.Branch, "branch", "", "git branch to show code info")
+	fs.StringVar(&ref.Tag, "tag", "", "git tag to show code info")
+	fs.StringVar(&ref.Commit, "commit", "", "git commit to show code info")
+	exitCode := fs.Bool("exit-code", false, "exit with 7 if there is config diff")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageErrorf("exactly one cluster is required")
+	}
+	if err := validateGitRef(ref, false); err != nil {
+		return err
+	}
+
+	c, err := o.client()
+	if err != nil {
+		return err
+	}
+	clusterID, err := c.ResolveCluster(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	diff, err := c.GetDiff(ctx, clusterID, ref)
+	if err != nil {
+		return err
+	}
+
+	if o.output == printer.FormatTable {
+		// the diff is printed as is so that it can be piped to diff tools
+		if info := diff.CodeInfo; info != nil {
+			fmt.Fprintf(stderr, "Commit: %s %s\n", info.CommitID, info.CommitMsg)
+		}
+		fmt.Fprint(stdout, diff.ConfigDiff)
+	} else if err := o.print(stdout, diff, nil); err != nil {
+		return err
+	}
+	if *exitCode && diff.ConfigDiff != "" {
+		return &exitError{code: ExitDiffFound}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/horizoncd/horizon/horizonctl/client"
+)
+
+func init() {
+	register(&command{
+		name:  "login",
+		usage: "-server <url> (-token <token> | -client-id <id> [-scope <scope>])",
+		short: "Save server and access token, or get a token by oauth device authorization",
+		run:   runLogin,
+	})
+}
+
+func runLogin(ctx context.Context, stdout, stderr io.Writer, args []string) error {
+	fs, o := newFlagSet("login", stderr, false)
+	clientID := fs.String("client-id", "", "client id of the oauth app to authorize this device")
+	scope := fs.String("scope", "", "scopes requested by device authorization, default scopes of the app if empty")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	server := o.server
+	if server == "" {
+		server = os.Getenv(EnvServer)
+	}
+	if server == "" {
+		return usageErrorf("server is required")
+	}
+	if (o.token == "") == (*clientID == "") {
+		return usageErrorf("exactly one of -token and -client-id is required")
+	}
+
+	token := o.token
+	if *clientID != "" {
+		t, err := client.New(server, "").DeviceLogin(ctx, *clientID, *scope, func(code *client.DeviceCode) {
+			fmt.Fprintf(stderr, "Open %s and enter code %s to authorize horizonctl\n",
+				code.VerificationURI, code.UserCode)
+			fmt.Fprintf(stderr, "or open %s directly\n", code.VerificationURIComplete)
+		})
+		if err != nil {
+			return err
+		}
+		token = t.AccessToken
+	}
+
+	user, err := client.New(server, token).GetSelf(ctx)
+	if err != nil {
+		return err
+	}
+	path := o.configPath()
+	if err := saveConfig(path, &Config{Server: server, Token: token}); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Logged in to %s as %s, config saved to %s\n", server, user.Name, path)
+	return nil
+}
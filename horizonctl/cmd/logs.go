@@ -0,0 +1,127 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/horizoncd/horizon/horizonctl/client"
+)
+
+func init() {
+	register(&command{
+		name:  "logs",
+		usage: "<cluster> [-pod <pod>] [-container <container>] [-f] [-tail <lines>] [-since <duration|time>]",
+		short: "Print logs of the cluster's containers, logs of all pods are merged if pod is not set",
+		run:   runLogs,
+	})
+	register(&command{
+		name:  "pipelineruns",
+		usage: "(get <id> [flags] | logs <id>)",
+		short: "Get a pipelinerun or follow its logs",
+		run:   runPipelineruns,
+	})
+}
+
+func runLogs(ctx context.Context, stdout, stderr io.Writer, args []string) error {
+	fs, o := newFlagSet("logs", stderr, false)
+	opts := &client.ContainerLogOptions{}
+	fs.StringVar(&opts.PodName, "pod", "", "pod to print logs, logs of all pods are merged if empty")
+	fs.StringVar(&opts.ContainerName, "container", "", "container to print logs, default the first container")
+	fs.StringVar(&opts.ContainerName, "c", "", "same as -container")
+	fs.BoolVar(&opts.Follow, "follow", false, "follow logs until interrupted")
+	fs.BoolVar(&opts.Follow, "f", false, "same as -follow")
+	fs.BoolVar(&opts.Previous, "previous", false, "print logs of the previous terminated container")
+	fs.Int64Var(&opts.TailLines, "tail", 0, "lines of recent logs to print, default 1000 if -since is not set")
+	since := fs.String("since", "", "print logs since a relative duration like 10m, or a RFC3339 time")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageErrorf("exactly one cluster is required")
+	}
+	if *since != "" {
+		sinceTime, err := parseSince(*since)
+		if err != nil {
+			return err
+		}
+		opts.SinceTime = &sinceTime
+	}
+
+	c, err := o.client()
+	if err != nil {
+		return err
+	}
+	clusterID, err := c.ResolveCluster(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	return c.ContainerLog(ctx, clusterID, opts, stdout)
+}
+
+func parseSince(since string) (time.Time, error) {
+	if d, err := time.ParseDuration(since); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		return time.Time{}, usageErrorf("invalid since %q, should be a duration or a RFC3339 time", since)
+	}
+	return t, nil
+}
+
+func runPipelineruns(ctx context.Context, stdout, stderr io.Writer, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("subcommand is required")
+	}
+	var withOutput bool
+	switch args[0] {
+	case "get":
+		withOutput = true
+	case "logs":
+	default:
+		return usageErrorf("unknown subcommand %q", args[0])
+	}
+	fs, o := newFlagSet("pipelineruns "+args[0], stderr, withOutput)
+	positional, err := parseFlags(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageErrorf("exactly one pipelinerun is required")
+	}
+	prID, err := strconv.ParseUint(positional[0], 10, 0)
+	if err != nil {
+		return usageErrorf("invalid pipelinerun id %q", positional[0])
+	}
+
+	c, err := o.client()
+	if err != nil {
+		return err
+	}
+	if !withOutput {
+		// logs are streamed until the pipelinerun finishes
+		return c.PipelinerunLog(ctx, uint(prID), stdout)
+	}
+	pr, err := c.GetPipelinerun(ctx, uint(prID))
+	if err != nil {
+		return err
+	}
+	return printPipelinerun(stdout, o, pr)
+}
@@ -0,0 +1,231 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/horizoncd/horizon/horizonctl/client"
+	"github.com/horizoncd/horizon/horizonctl/printer"
+)
+
+const _pollInterval = 3 * time.Second
+
+func init() {
+	register(&command{
+		name:  "build-deploy",
+		usage: "<cluster> (-branch <branch> | -tag <tag> | -commit <commit>) [-title <title>] [-wait] [-follow]",
+		short: "Build the cluster from git and deploy it",
+		run:   runBuildDeploy,
+	})
+	register(&command{
+		name:  "deploy",
+		usage: "<cluster> [-image-tag <tag>] [-title <title>] [-wait]",
+		short: "Deploy the cluster with its latest config, optionally with another image tag",
+		run:   runDeploy,
+	})
+	register(&command{
+		name:  "rollback",
+		usage: "<cluster> -pipelinerun <id> [-wait]",
+		short: "Roll the cluster back to the state after a pipelinerun",
+		run:   runRollback,
+	})
+	register(&command{
+		name:  "restart",
+		usage: "<cluster> [-wait]",
+		short: "Restart all pods of the cluster",
+		run:   runRestart,
+	})
+}
+
+// waitOptions decides whether to wait for the pipelinerun created by an operation
+type waitOptions struct {
+	wait    bool
+	follow  bool
+	timeout time.Duration
+}
+
+func addWaitFlags(fs *flag.FlagSet, withFollow bool) *waitOptions {
+	w := &waitOptions{}
+	fs.BoolVar(&w.wait, "wait", false, "wait for the pipelinerun to finish, exit with 5 if it fails")
+	fs.DurationVar(&w.timeout, "timeout", 30*time.Minute, "max time to wait, exit with 6 if exceeded")
+	if withFollow {
+		fs.BoolVar(&w.follow, "follow", false, "stream logs of the pipelinerun to stderr, implies -wait")
+	}
+	return w
+}
+
+// runOperation resolves the cluster, runs the operation which creates a pipelinerun, and prints the pipelinerun
+func runOperation(ctx context.Context, stdout, stderr io.Writer, o *options, w *waitOptions,
+	cluster string, operation func(c *client.Client, clusterID uint) (uint, error)) error {
+	c, err := o.client()
+	if err != nil {
+		return err
+	}
+	clusterID, err := c.ResolveCluster(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	prID, err := operation(c, clusterID)
+	if err != nil {
+		return err
+	}
+	return w.finish(ctx, stdout, stderr, o, c, prID)
+}
+
+func (w *waitOptions) finish(ctx context.Context, stdout, stderr io.Writer, o *options,
+	c *client.Client, prID uint) error {
+	if !w.wait && !w.follow {
+		pr, err := c.GetPipelinerun(ctx, prID)
+		if err != nil {
+			return err
+		}
+		return printPipelinerun(stdout, o, pr)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+	if w.follow {
+		if err := c.PipelinerunLog(ctx, prID, stderr); err != nil {
+			return w.wrapTimeout(ctx, prID, err)
+		}
+	}
+	pr, err := c.WaitPipelinerun(ctx, prID, _pollInterval)
+	if err != nil {
+		return w.wrapTimeout(ctx, prID, err)
+	}
+	if err := printPipelinerun(stdout, o, pr); err != nil {
+		return err
+	}
+	if pr.Status != client.StatusOK {
+		return &exitError{code: ExitPipelinerunFailed, err: fmt.Errorf("pipelinerun %d %s", pr.ID, pr.Status)}
+	}
+	return nil
+}
+
+func (w *waitOptions) wrapTimeout(ctx context.Context, prID uint, err error) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return &exitError{code: ExitTimeout,
+			err: fmt.Errorf("timed out after %s waiting for pipelinerun %d", w.timeout, prID)}
+	}
+	return err
+}
+
+func printPipelinerun(stdout io.Writer, o *options, pr *client.Pipelinerun) error {
+	return o.print(stdout, pr, func() *printer.Table {
+		table := &printer.Table{Header: []string{"pipelinerun", "action", "status", "title", "created by"}}
+		table.AddRow(strconv.FormatUint(uint64(pr.ID), 10), pr.Action, pr.Status, pr.Title, pr.CreatedBy.UserName)
+		return table
+	})
+}
+
+func runBuildDeploy(ctx context.Context, stdout, stderr io.Writer, args []string) error {
+	fs, o := newFlagSet("build-deploy", stderr, true)
+	request := &client.BuildDeployRequest{Git: &client.GitRef{}}
+	fs.StringVar(&request.Git.Branch, "branch", "", "git branch to build")
+	fs.StringVar(&request.Git.Tag, "tag", "", "git tag to build")
+	fs.StringVar(&request.Git.Commit, "commit", "", "git commit to build")
+	fs.StringVar(&request.Title, "title", "build deploy by horizonctl", "title of the pipelinerun")
+	fs.StringVar(&request.Description, "description", "", "description of the pipelinerun")
+	w := addWaitFlags(fs, true)
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageErrorf("exactly one cluster is required")
+	}
+	if err := validateGitRef(request.Git, true); err != nil {
+		return err
+	}
+	return runOperation(ctx, stdout, stderr, o, w, positional[0], func(c *client.Client, clusterID uint) (uint, error) {
+		return c.BuildDeploy(ctx, clusterID, request)
+	})
+}
+
+func runDeploy(ctx context.Context, stdout, stderr io.Writer, args []string) error {
+	fs, o := newFlagSet("deploy", stderr, true)
+	request := &client.DeployRequest{}
+	fs.StringVar(&request.ImageTag, "image-tag", "", "image tag to deploy, the current image is kept if empty")
+	fs.StringVar(&request.Title, "title", "deploy by horizonctl", "title of the pipelinerun")
+	fs.StringVar(&request.Description, "description", "", "description of the pipelinerun")
+	w := addWaitFlags(fs, false)
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageErrorf("exactly one cluster is required")
+	}
+	return runOperation(ctx, stdout, stderr, o, w, positional[0], func(c *client.Client, clusterID uint) (uint, error) {
+		return c.Deploy(ctx, clusterID, request)
+	})
+}
+
+func runRollback(ctx context.Context, stdout, stderr io.Writer, args []string) error {
+	fs, o := newFlagSet("rollback", stderr, true)
+	prID := fs.Uint("pipelinerun", 0, "id of the pipelinerun to roll back to")
+	w := addWaitFlags(fs, false)
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageErrorf("exactly one cluster is required")
+	}
+	if *prID == 0 {
+		return usageErrorf("pipelinerun is required")
+	}
+	return runOperation(ctx, stdout, stderr, o, w, positional[0], func(c *client.Client, clusterID uint) (uint, error) {
+		return c.Rollback(ctx, clusterID, *prID)
+	})
+}
+
+func runRestart(ctx context.Context, stdout, stderr io.Writer, args []string) error {
+	fs, o := newFlagSet("restart", stderr, true)
+	w := addWaitFlags(fs, false)
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageErrorf("exactly one cluster is required")
+	}
+	return runOperation(ctx, stdout, stderr, o, w, positional[0], func(c *client.Client, clusterID uint) (uint, error) {
+		return c.Restart(ctx, clusterID)
+	})
+}
+
+// validateGitRef checks that at most one of branch, tag and commit is set, and exactly one if required
+func validateGitRef(ref *client.GitRef, required bool) error {
+	count := 0
+	for _, value := range []string{ref.Branch, ref.Tag, ref.Commit} {
+		if value != "" {
+			count++
+		}
+	}
+	if count > 1 || (required && count == 0) {
+		if required {
+			return usageErrorf("exactly one of -branch, -tag and -commit is required")
+		}
+		return usageErrorf("at most one of -branch, -tag and -commit can be set")
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/yaml"
+
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+)
+
+var Formats = []string{FormatTable, FormatJSON, FormatYAML}
+
+// Table is the human-readable form of an object
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+func (t *Table) AddRow(columns ...string) {
+	t.Rows = append(t.Rows, columns)
+}
+
+func ValidateFormat(format string) error {
+	for _, f := range Formats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported output format %q, should be one of %s", format, strings.Join(Formats, ", "))
+}
+
+// Print prints obj as json or yaml, or prints the table built from obj in table format
+func Print(w io.Writer, format string, obj interface{}, table func() *Table) error {
+	switch format {
+	case FormatJSON:
+		b, err := json.MarshalIndent(obj, "", "  ")
+		if err != nil {
+			return perror.Wrap(err, "failed to marshal output to json")
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case FormatYAML:
+		b, err := yaml.Marshal(obj)
+		if err != nil {
+			return perror.Wrap(err, "failed to marshal output to yaml")
+		}
+		_, err = w.Write(b)
+		return err
+	case FormatTable:
+		return printTable(w, table())
+	default:
+		return ValidateFormat(format)
+	}
+}
+
+func printTable(w io.Writer, table *Table) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 3, ' ', 0)
+	if len(table.Header) > 0 {
+		header := make([]string, 0, len(table.Header))
+		for _, h := range table.Header {
+			header = append(header, strings.ToUpper(h))
+		}
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+	}
+	for _, row := range table.Rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
@@ -66,6 +66,7 @@ var (
 	authorizeCodeExpireIn = time.Minute * 30
 	accessTokenExpireIn   = time.Second * 3
 	refreshTokenExpireIn  = time.Minute * 30
+	deviceCodeExpireIn    = time.Minute * 15
 	manager               *managerparam.Manager
 )
 
@@ -122,7 +123,7 @@ func TestServer(t *testing.T) {
 	tokenStore := tokenstore.NewStore(db)
 	oauthAppDAO := oauthdao.NewDAO(db)
 	oauthManager := oauthmanager.NewManager(oauthAppDAO, tokenStore, generator.NewAuthorizeGenerator(),
-		authorizeCodeExpireIn, accessTokenExpireIn, refreshTokenExpireIn)
+		authorizeCodeExpireIn, accessTokenExpireIn, refreshTokenExpireIn, deviceCodeExpireIn)
 	clientID := "ho_t65dvkmfqb8v8xzxfbc5"
 	clientIDGen := func(appType models.AppType) string {
 		return clientID
@@ -157,7 +158,7 @@ func TestServer(t *testing.T) {
 	authScopeService, err := scope.NewFileScopeService(createOauthScopeConfig())
 	assert.Nil(t, err)
 
-	api := oauthserver.NewAPI(oauthServerController, oauthAppController, "authFileLoc", authScopeService)
+	api := oauthserver.NewAPI(oauthServerController, oauthAppController, "authFileLoc", "", authScopeService)
 
 	userMiddleWare := func(c *gin.Context) {
 		common.SetUser(c, aUser)
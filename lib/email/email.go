@@ -0,0 +1,80 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package email
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"github.com/horizoncd/horizon/pkg/config/email"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+type Sender interface {
+	// Send sends a plain text email to the recipients
+	Send(ctx context.Context, to []string, subject, body string) error
+}
+
+type sender struct {
+	config email.Config
+	auth   smtp.Auth
+}
+
+// NewSender returns nil if smtp server is not configured
+func NewSender(config email.Config) Sender {
+	if config.Host == "" {
+		return nil
+	}
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+	return &sender{
+		config: config,
+		auth:   auth,
+	}
+}
+
+func (s *sender) Send(_ context.Context, to []string, subject, body string) error {
+	if len(to) == 0 {
+		return nil
+	}
+	port := s.config.Port
+	if port == 0 {
+		port = 25
+	}
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(port))
+	if err := smtp.SendMail(addr, s.auth, s.config.From, to, message(s.config.From, to, subject, body)); err != nil {
+		return perror.Wrapf(err, "failed to send email to %v", to)
+	}
+	return nil
+}
+
+func message(from string, to []string, subject, body string) []byte {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "From: %s\r\n", from)
+	fmt.Fprintf(buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(buf, "Subject: %s\r\n", subject)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return buf.Bytes()
+}
@@ -0,0 +1,283 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/config/encryption"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const (
+	// prefix marks encrypted values, an encrypted value looks like
+	// enc:v1:<key id>:<base64 wrapped data key>:<base64 nonce and ciphertext>
+	prefix        = "enc:v1:"
+	dataKeyLength = 32
+
+	// dataKeyTTL is how long a data key is reused for new values and an unwrapped data key is cached,
+	// so that the key provider is not called for every value
+	dataKeyTTL = time.Hour
+	// maxDataKeyUses limits values sealed by one data key, far below the limit of random gcm nonces
+	maxDataKeyUses = 1 << 20
+	// maxCachedDataKeys limits unwrapped data keys kept in memory
+	maxCachedDataKeys = 1024
+)
+
+// KeyProvider wraps data keys with the key encryption keys it manages
+type KeyProvider interface {
+	// KeyID returns the id of the key new data keys are wrapped with
+	KeyID() string
+	WrapKey(ctx context.Context, dataKey []byte) ([]byte, error)
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// Encrypter encrypts values with a random data key, which is stored along with the value
+// after wrapped by the key provider. The data key is reused for new values until it expires,
+// and unwrapped data keys are cached, so the key provider is only called when keys change.
+type Encrypter struct {
+	provider KeyProvider
+
+	lock sync.Mutex
+	// current is the data key new values are sealed with
+	current *dataKey
+	// unwrapped caches data keys by their key id and wrapped key
+	unwrapped map[string]*dataKey
+}
+
+type dataKey struct {
+	key      []byte
+	wrapped  []byte
+	keyID    string
+	uses     int
+	expireAt time.Time
+}
+
+func NewEncrypter(provider KeyProvider) *Encrypter {
+	return &Encrypter{
+		provider:  provider,
+		unwrapped: make(map[string]*dataKey),
+	}
+}
+
+// New returns nil if encryption is disabled
+func New(config encryption.Config) (*Encrypter, error) {
+	var (
+		provider KeyProvider
+		err      error
+	)
+	switch config.Provider {
+	case "":
+		return nil, nil
+	case encryption.ProviderLocal:
+		provider, err = NewLocalProvider(config.Local)
+	case encryption.ProviderVault:
+		provider, err = NewVaultProvider(config.Vault)
+	default:
+		return nil, perror.Errorf("unsupported encryption provider: %s", config.Provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewEncrypter(provider), nil
+}
+
+func (e *Encrypter) Encrypt(ctx context.Context, plaintext string) (string, error) {
+	key, err := e.currentDataKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(key.key, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return prefix + strings.Join([]string{
+		key.keyID,
+		base64.StdEncoding.EncodeToString(key.wrapped),
+		base64.StdEncoding.EncodeToString(sealed),
+	}, ":"), nil
+}
+
+// currentDataKey returns the data key for new values, a new one is generated and wrapped
+// when it expires, is used up or the primary key changes
+func (e *Encrypter) currentDataKey(ctx context.Context) (*dataKey, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	keyID := e.provider.KeyID()
+	if e.current != nil && e.current.keyID == keyID && e.current.uses < maxDataKeyUses &&
+		time.Now().Before(e.current.expireAt) {
+		e.current.uses++
+		return e.current, nil
+	}
+
+	key := make([]byte, dataKeyLength)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, perror.Wrap(err, "failed to generate data key")
+	}
+	wrapped, err := e.provider.WrapKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	e.current = &dataKey{
+		key:      key,
+		wrapped:  wrapped,
+		keyID:    keyID,
+		uses:     1,
+		expireAt: time.Now().Add(dataKeyTTL),
+	}
+	e.cacheLocked(e.current)
+	return e.current, nil
+}
+
+// unwrapDataKey returns the cached data key, or unwraps it by the key provider
+func (e *Encrypter) unwrapDataKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	cacheKey := keyID + ":" + string(wrapped)
+	e.lock.Lock()
+	cached, ok := e.unwrapped[cacheKey]
+	e.lock.Unlock()
+	if ok && time.Now().Before(cached.expireAt) {
+		return cached.key, nil
+	}
+
+	key, err := e.provider.UnwrapKey(ctx, keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	e.lock.Lock()
+	e.cacheLocked(&dataKey{
+		key:      key,
+		wrapped:  wrapped,
+		keyID:    keyID,
+		expireAt: time.Now().Add(dataKeyTTL),
+	})
+	e.lock.Unlock()
+	return key, nil
+}
+
+// cacheLocked caches the unwrapped data key, e.lock must be held
+func (e *Encrypter) cacheLocked(key *dataKey) {
+	if len(e.unwrapped) >= maxCachedDataKeys {
+		now := time.Now()
+		for k, v := range e.unwrapped {
+			if !now.Before(v.expireAt) {
+				delete(e.unwrapped, k)
+			}
+		}
+		if len(e.unwrapped) >= maxCachedDataKeys {
+			e.unwrapped = make(map[string]*dataKey)
+		}
+	}
+	e.unwrapped[key.keyID+":"+string(key.wrapped)] = key
+}
+
+// Decrypt returns the value as it is if it is not encrypted
+func (e *Encrypter) Decrypt(ctx context.Context, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	keyID, wrapped, sealed, err := parse(value)
+	if err != nil {
+		return "", err
+	}
+	dataKey, err := e.unwrapDataKey(ctx, keyID, wrapped)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dataKey, sealed)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Outdated reports whether the value is plaintext or encrypted with a key other than the primary one
+func (e *Encrypter) Outdated(value string) bool {
+	if value == "" {
+		return false
+	}
+	if !IsEncrypted(value) {
+		return true
+	}
+	keyID, _, _, err := parse(value)
+	return err != nil || keyID != e.provider.KeyID()
+}
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+func parse(value string) (keyID string, wrapped, sealed []byte, err error) {
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 {
+		return "", nil, nil, perror.New("malformed encrypted value")
+	}
+	if wrapped, err = base64.StdEncoding.DecodeString(parts[1]); err != nil {
+		return "", nil, nil, perror.Wrap(err, "malformed wrapped data key")
+	}
+	if sealed, err = base64.StdEncoding.DecodeString(parts[2]); err != nil {
+		return "", nil, nil, perror.Wrap(err, "malformed ciphertext")
+	}
+	return parts[0], wrapped, sealed, nil
+}
+
+// seal encrypts with aes-256-gcm and prepends the nonce to the ciphertext
+func seal(key, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, perror.Wrap(err, "failed to generate nonce")
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(key, sealed []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, perror.New("malformed ciphertext")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, perror.Wrap(err, "failed to decrypt")
+	}
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, perror.Wrap(err, "invalid key")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, perror.Wrap(err, "invalid key")
+	}
+	return aead, nil
+}
@@ -0,0 +1,224 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encrypt
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/config/encryption"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeKeyFile(t *testing.T, ids ...string) string {
+	lines := []string{"# test keys"}
+	for i, id := range ids {
+		key := []byte(strings.Repeat(string(rune('a'+i)), dataKeyLength))
+		lines = append(lines, id+":"+base64.StdEncoding.EncodeToString(key))
+	}
+	file := filepath.Join(t.TempDir(), "keys")
+	assert.Nil(t, ioutil.WriteFile(file, []byte(strings.Join(lines, "\n")), 0600))
+	return file
+}
+
+func TestLocalProvider(t *testing.T) {
+	ctx := context.Background()
+	keyFile := writeKeyFile(t, "k1")
+	e, err := New(encryption.Config{
+		Provider: encryption.ProviderLocal,
+		Local:    encryption.Local{KeyFile: keyFile},
+	})
+	assert.Nil(t, err)
+
+	encrypted, err := e.Encrypt(ctx, "secret")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(encrypted, "enc:v1:k1:"))
+	assert.False(t, e.Outdated(encrypted))
+	another, err := e.Encrypt(ctx, "secret")
+	assert.Nil(t, err)
+	assert.NotEqual(t, encrypted, another)
+
+	decrypted, err := e.Decrypt(ctx, encrypted)
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", decrypted)
+
+	// plaintext written before encryption was enabled
+	decrypted, err = e.Decrypt(ctx, "plaintext")
+	assert.Nil(t, err)
+	assert.Equal(t, "plaintext", decrypted)
+	assert.True(t, e.Outdated("plaintext"))
+	assert.False(t, e.Outdated(""))
+
+	// rotate by appending a new key
+	rotated, err := New(encryption.Config{
+		Provider: encryption.ProviderLocal,
+		Local:    encryption.Local{KeyFile: writeKeyFile(t, "k1", "k2")},
+	})
+	assert.Nil(t, err)
+	assert.True(t, rotated.Outdated(encrypted))
+	decrypted, err = rotated.Decrypt(ctx, encrypted)
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", decrypted)
+	reencrypted, err := rotated.Encrypt(ctx, decrypted)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(reencrypted, "enc:v1:k2:"))
+
+	// the old key is removed after re-encryption
+	_, err = e.Decrypt(ctx, reencrypted)
+	assert.NotNil(t, err)
+
+	_, err = New(encryption.Config{
+		Provider: encryption.ProviderLocal,
+		Local:    encryption.Local{KeyFile: keyFile, PrimaryKeyID: "k3"},
+	})
+	assert.NotNil(t, err)
+	_, _, err = parseKeys("k1:short")
+	assert.NotNil(t, err)
+}
+
+func TestVaultProvider(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		var req vaultRequest
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+		var resp vaultResponse
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/horizon":
+			resp.Data.Ciphertext = "vault:v1:" + req.Plaintext
+		case "/v1/transit/decrypt/horizon":
+			resp.Data.Plaintext = strings.TrimPrefix(req.Ciphertext, "vault:v1:")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		assert.Nil(t, json.NewEncoder(w).Encode(resp))
+	}))
+	defer server.Close()
+
+	e, err := New(encryption.Config{
+		Provider: encryption.ProviderVault,
+		Vault: encryption.Vault{
+			Address: server.URL,
+			Token:   "token",
+			KeyName: "horizon",
+		},
+	})
+	assert.Nil(t, err)
+	encrypted, err := e.Encrypt(ctx, "secret")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(encrypted, "enc:v1:horizon:"))
+	decrypted, err := e.Decrypt(ctx, encrypted)
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", decrypted)
+
+	forbidden, err := NewVaultProvider(encryption.Vault{Address: server.URL, KeyName: "horizon"})
+	assert.Nil(t, err)
+	_, err = NewEncrypter(forbidden).Encrypt(ctx, "secret")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "permission denied")
+}
+
+type countingProvider struct {
+	KeyProvider
+	wraps   int
+	unwraps int
+}
+
+func (p *countingProvider) WrapKey(ctx context.Context, dataKey []byte) ([]byte, error) {
+	p.wraps++
+	return p.KeyProvider.WrapKey(ctx, dataKey)
+}
+
+func (p *countingProvider) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	p.unwraps++
+	return p.KeyProvider.UnwrapKey(ctx, keyID, wrapped)
+}
+
+func TestDataKeyCache(t *testing.T) {
+	ctx := context.Background()
+	local, err := NewLocalProvider(encryption.Local{KeyFile: writeKeyFile(t, "k1")})
+	assert.Nil(t, err)
+	provider := &countingProvider{KeyProvider: local}
+	e := NewEncrypter(provider)
+
+	// the data key is wrapped once for new values
+	encrypted := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		value, err := e.Encrypt(ctx, "secret")
+		assert.Nil(t, err)
+		encrypted = append(encrypted, value)
+	}
+	assert.Equal(t, 1, provider.wraps)
+	for _, value := range encrypted {
+		decrypted, err := e.Decrypt(ctx, value)
+		assert.Nil(t, err)
+		assert.Equal(t, "secret", decrypted)
+	}
+	assert.Equal(t, 0, provider.unwraps)
+
+	// another instance unwraps the data key once
+	another := NewEncrypter(provider)
+	for _, value := range encrypted {
+		decrypted, err := another.Decrypt(ctx, value)
+		assert.Nil(t, err)
+		assert.Equal(t, "secret", decrypted)
+	}
+	assert.Equal(t, 1, provider.unwraps)
+
+	// a new data key is generated after the current one expires
+	e.current.expireAt = time.Now()
+	_, err = e.Encrypt(ctx, "secret")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, provider.wraps)
+}
+
+func TestString(t *testing.T) {
+	e, err := New(encryption.Config{
+		Provider: encryption.ProviderLocal,
+		Local:    encryption.Local{KeyFile: writeKeyFile(t, "k1")},
+	})
+	assert.Nil(t, err)
+	defer SetDefault(nil)
+
+	var s String
+	assert.Nil(t, s.Scan([]byte("plaintext")))
+	assert.Equal(t, String("plaintext"), s)
+	value, err := String("").Value()
+	assert.Nil(t, err)
+	assert.Equal(t, "", value)
+
+	SetDefault(e)
+	value, err = String("secret").Value()
+	assert.Nil(t, err)
+	assert.True(t, IsEncrypted(value.(string)))
+	assert.Nil(t, s.Scan(value))
+	assert.Equal(t, String("secret"), s)
+
+	SetDefault(nil)
+	assert.NotNil(t, s.Scan(value))
+}
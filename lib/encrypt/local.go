@@ -0,0 +1,101 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encrypt
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"strings"
+
+	"github.com/horizoncd/horizon/pkg/config/encryption"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// localProvider wraps data keys with keys read from a local file,
+// keys are rotated by appending a new key to the file and keeping the old ones until values are re-encrypted
+type localProvider struct {
+	keys    map[string][]byte
+	primary string
+}
+
+func NewLocalProvider(config encryption.Local) (KeyProvider, error) {
+	data, err := ioutil.ReadFile(config.KeyFile)
+	if err != nil {
+		return nil, perror.Wrapf(err, "failed to read key file %s", config.KeyFile)
+	}
+	keys, last, err := parseKeys(string(data))
+	if err != nil {
+		return nil, perror.WithMessagef(err, "failed to parse key file %s", config.KeyFile)
+	}
+	primary := config.PrimaryKeyID
+	if primary == "" {
+		primary = last
+	}
+	if _, ok := keys[primary]; !ok {
+		return nil, perror.Errorf("primary key %s not found in key file %s", primary, config.KeyFile)
+	}
+	return &localProvider{
+		keys:    keys,
+		primary: primary,
+	}, nil
+}
+
+// parseKeys parses lines of <key id>:<base64 encoded key>, empty lines and lines starting with # are ignored
+func parseKeys(data string) (keys map[string][]byte, last string, err error) {
+	keys = make(map[string][]byte)
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, "", perror.Errorf("line %d: expected <key id>:<base64 encoded key>", i+1)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, "", perror.Wrapf(err, "line %d: key is not base64 encoded", i+1)
+		}
+		if len(key) != dataKeyLength {
+			return nil, "", perror.Errorf("line %d: key must be %d bytes", i+1, dataKeyLength)
+		}
+		if _, ok := keys[parts[0]]; ok {
+			return nil, "", perror.Errorf("line %d: duplicated key id %s", i+1, parts[0])
+		}
+		keys[parts[0]] = key
+		last = parts[0]
+	}
+	if len(keys) == 0 {
+		return nil, "", perror.New("no key found")
+	}
+	return keys, last, nil
+}
+
+func (p *localProvider) KeyID() string {
+	return p.primary
+}
+
+func (p *localProvider) WrapKey(_ context.Context, dataKey []byte) ([]byte, error) {
+	return seal(p.keys[p.primary], dataKey)
+}
+
+func (p *localProvider) UnwrapKey(_ context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, perror.Errorf("key %s not found", keyID)
+	}
+	return open(key, wrapped)
+}
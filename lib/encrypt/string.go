@@ -0,0 +1,79 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encrypt
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// columnTimeout bounds key provider calls made when columns are read or written,
+// which have no context of the request
+const columnTimeout = 10 * time.Second
+
+// defaultEncrypter is used by String columns, values are stored in plaintext if it is nil
+var defaultEncrypter *Encrypter
+
+// SetDefault sets the encrypter of String columns, it should be called before the database is accessed
+func SetDefault(e *Encrypter) {
+	defaultEncrypter = e
+}
+
+func Default() *Encrypter {
+	return defaultEncrypter
+}
+
+// String is a string column which is encrypted by the default encrypter when written
+// and decrypted when read, plaintext values written before encryption was enabled are read as they are
+type String string
+
+func (s *String) Scan(value interface{}) error {
+	var str string
+	switch v := value.(type) {
+	case nil:
+	case []byte:
+		str = string(v)
+	case string:
+		str = v
+	default:
+		return perror.Errorf("failed to scan encrypted string from value: %v", value)
+	}
+	if IsEncrypted(str) {
+		if defaultEncrypter == nil {
+			return perror.New("failed to decrypt value: encryption is not configured")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), columnTimeout)
+		defer cancel()
+		decrypted, err := defaultEncrypter.Decrypt(ctx, str)
+		if err != nil {
+			return err
+		}
+		str = decrypted
+	}
+	*s = String(str)
+	return nil
+}
+
+func (s String) Value() (driver.Value, error) {
+	if s == "" || defaultEncrypter == nil {
+		return string(s), nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), columnTimeout)
+	defer cancel()
+	return defaultEncrypter.Encrypt(ctx, string(s))
+}
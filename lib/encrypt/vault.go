@@ -0,0 +1,133 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encrypt
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/config/encryption"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const (
+	defaultVaultMount   = "transit"
+	defaultVaultTimeout = 10 * time.Second
+)
+
+// vaultProvider wraps data keys with the vault transit secrets engine,
+// keys are rotated in vault and the key version is carried by the wrapped data key
+type vaultProvider struct {
+	config encryption.Vault
+	client *http.Client
+}
+
+func NewVaultProvider(config encryption.Vault) (KeyProvider, error) {
+	if config.Address == "" || config.KeyName == "" {
+		return nil, perror.New("vault address and key name are required")
+	}
+	if strings.Contains(config.KeyName, ":") {
+		return nil, perror.Errorf("invalid vault key name %s", config.KeyName)
+	}
+	if config.Mount == "" {
+		config.Mount = defaultVaultMount
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultVaultTimeout
+	}
+	return &vaultProvider{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+type vaultRequest struct {
+	Plaintext  string `json:"plaintext,omitempty"`
+	Ciphertext string `json:"ciphertext,omitempty"`
+}
+
+type vaultResponse struct {
+	Data   vaultRequest `json:"data"`
+	Errors []string     `json:"errors"`
+}
+
+func (p *vaultProvider) KeyID() string {
+	return p.config.KeyName
+}
+
+func (p *vaultProvider) WrapKey(ctx context.Context, dataKey []byte) ([]byte, error) {
+	resp, err := p.do(ctx, "encrypt", p.config.KeyName, &vaultRequest{
+		Plaintext: base64.StdEncoding.EncodeToString(dataKey),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(resp.Ciphertext), nil
+}
+
+func (p *vaultProvider) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	resp, err := p.do(ctx, "decrypt", keyID, &vaultRequest{
+		Ciphertext: string(wrapped),
+	})
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := base64.StdEncoding.DecodeString(resp.Plaintext)
+	if err != nil {
+		return nil, perror.Wrap(err, "vault returned malformed plaintext")
+	}
+	return dataKey, nil
+}
+
+func (p *vaultProvider) do(ctx context.Context, operation, keyName string,
+	body *vaultRequest) (*vaultRequest, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, perror.Wrap(err, "failed to marshal vault request")
+	}
+	url := fmt.Sprintf("%s/v1/%s/%s/%s", strings.TrimSuffix(p.config.Address, "/"),
+		p.config.Mount, operation, keyName)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, perror.Wrap(err, "failed to create vault request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", p.config.Token)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, perror.Wrapf(err, "failed to %s with vault", operation)
+	}
+	defer resp.Body.Close()
+	respData, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, perror.Wrap(err, "failed to read vault response")
+	}
+	var vaultResp vaultResponse
+	if err := json.Unmarshal(respData, &vaultResp); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, perror.Wrap(err, "failed to unmarshal vault response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, perror.Errorf("failed to %s with vault, status: %d, errors: %v",
+			operation, resp.StatusCode, vaultResp.Errors)
+	}
+	return &vaultResp.Data, nil
+}
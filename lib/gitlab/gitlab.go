@@ -124,6 +124,23 @@ type Interface interface {
 	// See https://docs.gitlab.com/ee/api/merge_requests.html#update-mr for more information.
 	CloseMR(ctx context.Context, pid interface{}, mrID int) (mr *gitlab.MergeRequest, err error)
 
+	// CreateMRNote create a note (comment) on a merge request for specified project.
+	// The pid can be the project's ID or relative path such as fist/second.
+	// See https://docs.gitlab.com/ee/api/notes.html#create-new-merge-request-note for more information.
+	CreateMRNote(ctx context.Context, pid interface{}, mrID int, body string) (*gitlab.Note, error)
+
+	// SetCommitStatus set the pipeline status of a commit for specified project.
+	// The pid can be the project's ID or relative path such as fist/second.
+	// See https://docs.gitlab.com/ee/api/commits.html#post-the-build-status-to-a-commit for more information.
+	SetCommitStatus(ctx context.Context, pid interface{}, sha string,
+		opt *gitlab.SetCommitStatusOptions) (*gitlab.CommitStatus, error)
+
+	// CreateDeployment create a deployment for specified project.
+	// The pid can be the project's ID or relative path such as fist/second.
+	// See https://docs.gitlab.com/ee/api/deployments.html#create-a-deployment for more information.
+	CreateDeployment(ctx context.Context, pid interface{},
+		opt *gitlab.CreateProjectDeploymentOptions) (*gitlab.Deployment, error)
+
 	// WriteFiles write including create, delete, update multiple files within a specified project.
 	// The pid can be the project's ID or relative path such as fist/second.
 	// See https://docs.gitlab.com/ee/api/commits.html#create-a-commit-with-multiple-files-and-actions
@@ -458,6 +475,47 @@ func (h *helper) CloseMR(ctx context.Context, pid interface{}, mrID int) (mr *gi
 	return nil, err2
 }
 
+func (h *helper) CreateMRNote(ctx context.Context, pid interface{},
+	mrID int, body string) (_ *gitlab.Note, err error) {
+	const op = "gitlab: create mr note"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	note, rsp, err := h.client.Notes.CreateMergeRequestNote(pid, mrID, &gitlab.CreateMergeRequestNoteOptions{
+		Body: &body,
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, parseError(rsp, err)
+	}
+
+	return note, nil
+}
+
+func (h *helper) SetCommitStatus(ctx context.Context, pid interface{}, sha string,
+	opt *gitlab.SetCommitStatusOptions) (_ *gitlab.CommitStatus, err error) {
+	const op = "gitlab: set commit status"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	status, rsp, err := h.client.Commits.SetCommitStatus(pid, sha, opt, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, parseError(rsp, err)
+	}
+
+	return status, nil
+}
+
+func (h *helper) CreateDeployment(ctx context.Context, pid interface{},
+	opt *gitlab.CreateProjectDeploymentOptions) (_ *gitlab.Deployment, err error) {
+	const op = "gitlab: create deployment"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	deployment, rsp, err := h.client.Deployments.CreateProjectDeployment(pid, opt, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, parseError(rsp, err)
+	}
+
+	return deployment, nil
+}
+
 func (h *helper) AcceptMR(ctx context.Context, pid interface{}, mrID int,
 	mergeCommitMsg *string, shouldRemoveSourceBranch *bool) (mr *gitlab.MergeRequest, err error) {
 	const op = "gitlab: accept mr"
@@ -0,0 +1,161 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// Migration is an up-migration loaded from a sql file named as <version>_<name>[.up].sql,
+// versions are ordered numerically, e.g. 20261027_add_oauth_pkce.sql
+type Migration struct {
+	Version    uint64
+	Name       string
+	File       string
+	Statements []string
+}
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_?(.*?)(\.up)?\.sql$`)
+
+// Load loads migrations from dir sorted by version, down-migrations are ignored
+func Load(dir string) ([]*Migration, error) {
+	migrations, err := load(dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// LoadSnapshot loads the latest full schema snapshot from dir, which is used to initialize empty databases,
+// snapshots are named as <version>[_name].sql and contain all tables of the schema at that version.
+// It returns nil if there is no snapshot.
+func LoadSnapshot(dir string) (*Migration, error) {
+	snapshots, err := load(dir)
+	if err != nil {
+		return nil, err
+	}
+	var latest *Migration
+	for _, snapshot := range snapshots {
+		if latest == nil || snapshot.Version > latest.Version {
+			latest = snapshot
+		}
+	}
+	return latest, nil
+}
+
+func load(dir string) ([]*Migration, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, perror.Wrapf(err, "failed to read migrations dir %s", dir)
+	}
+
+	versions := make(map[uint64]string)
+	migrations := make([]*Migration, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() || strings.HasSuffix(file.Name(), ".down.sql") {
+			continue
+		}
+		matches := fileNamePattern.FindStringSubmatch(file.Name())
+		if matches == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(matches[1], 10, 64)
+		if err != nil {
+			return nil, perror.Wrapf(err, "invalid version of migration %s", file.Name())
+		}
+		if exist, ok := versions[version]; ok {
+			return nil, perror.Errorf("duplicate migration version %d: %s and %s", version, exist, file.Name())
+		}
+		versions[version] = file.Name()
+
+		content, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, perror.Wrapf(err, "failed to read migration %s", file.Name())
+		}
+		migrations = append(migrations, &Migration{
+			Version:    version,
+			Name:       matches[2],
+			File:       file.Name(),
+			Statements: SplitStatements(string(content)),
+		})
+	}
+	return migrations, nil
+}
+
+// SplitStatements splits sql script into statements by semicolons outside of quotes and comments,
+// comments are dropped since drivers may not accept multiple statements in one query
+func SplitStatements(script string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+		quote      rune
+	)
+	flush := func() {
+		if stmt := strings.TrimSpace(current.String()); stmt != "" {
+			statements = append(statements, stmt)
+		}
+		current.Reset()
+	}
+
+	runes := []rune(script)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+		switch {
+		case quote != 0:
+			current.WriteRune(r)
+			if r == '\\' && quote != '`' && next != 0 {
+				current.WriteRune(next)
+				i++
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+			current.WriteRune(r)
+		case r == '-' && next == '-', r == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			current.WriteRune('\n')
+		case r == '/' && next == '*':
+			i += 2
+			for i < len(runes) && !(runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/') {
+				i++
+			}
+			i++
+			current.WriteRune(' ')
+		case r == ';':
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return statements
+}
@@ -0,0 +1,231 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- create table
+CREATE TABLE t
+(
+    id   bigint,
+    name varchar(64) NOT NULL DEFAULT 'a;b' COMMENT 'it''s -- not a comment'
+); # mysql comment
+/* block; comment */
+INSERT INTO t (id, name) VALUES (1, "c;\"d");
+
+`
+	statements := SplitStatements(script)
+	assert.Equal(t, 2, len(statements))
+	assert.Contains(t, statements[0], "DEFAULT 'a;b' COMMENT 'it''s -- not a comment'")
+	assert.NotContains(t, statements[0], "create table")
+	assert.Equal(t, `INSERT INTO t (id, name) VALUES (1, "c;\"d")`, statements[1])
+}
+
+func writeMigrations(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "migrations")
+	assert.Nil(t, err)
+	for name, content := range files {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"20211124_add_b.up.sql":   "CREATE TABLE b (id int);",
+		"20210908_add_a.up.sql":   "CREATE TABLE a (id int);",
+		"20210908_add_a.down.sql": "DROP TABLE a;",
+		"20220124.sql":            "CREATE TABLE c (id int); CREATE TABLE d (id int);",
+		"README.md":               "not a migration",
+	})
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	migrations, err := Load(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(migrations))
+	assert.Equal(t, uint64(20210908), migrations[0].Version)
+	assert.Equal(t, "add_a", migrations[0].Name)
+	assert.Equal(t, "add_b", migrations[1].Name)
+	assert.Equal(t, uint64(20220124), migrations[2].Version)
+	assert.Equal(t, 2, len(migrations[2].Statements))
+
+	dup := writeMigrations(t, map[string]string{
+		"20210908_add_a.sql": "CREATE TABLE a (id int);",
+		"20210908_add_b.sql": "CREATE TABLE b (id int);",
+	})
+	defer func() { _ = os.RemoveAll(dup) }()
+	_, err = Load(dup)
+	assert.NotNil(t, err)
+}
+
+func TestLoadSnapshot(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"20210908_initial_schema.sql": "CREATE TABLE a (id int);",
+		"20240130.sql":                "CREATE TABLE a (id int); CREATE TABLE b (id int);",
+		"20220124.sql":                "CREATE TABLE a (id int);",
+	})
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	snapshot, err := LoadSnapshot(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(20240130), snapshot.Version)
+	assert.Equal(t, 2, len(snapshot.Statements))
+}
+
+func TestRunnerWithSnapshot(t *testing.T) {
+	ctx := context.Background()
+	db, err := orm.NewTestDB()
+	assert.Nil(t, err)
+
+	snapshot := &Migration{Version: 2, File: "2.sql", Statements: []string{
+		"CREATE TABLE tb_user (id int)", "CREATE TABLE b (id int)",
+	}}
+	migrations := []*Migration{
+		{Version: 1, Name: "add_user", File: "1_add_user.sql", Statements: []string{"CREATE TABLE tb_user (id int)"}},
+		{Version: 2, Name: "add_b", File: "2_add_b.sql", Statements: []string{"CREATE TABLE b (id int)"}},
+		{Version: 3, Name: "add_c", File: "3_add_c.sql", Statements: []string{"CREATE TABLE c (id int)"}},
+	}
+	runner, err := NewRunner(db, snapshot, migrations, 0)
+	assert.Nil(t, err)
+
+	applied, err := runner.Up(ctx, true)
+	assert.Nil(t, err)
+	assert.Equal(t, []*Migration{snapshot, migrations[2]}, applied)
+
+	applied, err = runner.Up(ctx, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []*Migration{snapshot, migrations[2]}, applied)
+	assert.True(t, db.Migrator().HasTable("b"))
+	assert.True(t, db.Migrator().HasTable("c"))
+	status, err := runner.Status(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), status.Current)
+	assert.Equal(t, 0, len(status.Pending))
+	assert.Equal(t, 0, len(status.Unknown))
+
+	// tables exist but history is lost
+	assert.Nil(t, db.Exec("DELETE FROM "+TableName).Error)
+	_, err = runner.Up(ctx, false)
+	assert.Equal(t, ErrNoHistory, perror.Cause(err))
+	assert.Equal(t, ErrNoHistory, perror.Cause(runner.Check(ctx)))
+	_, err = runner.Baseline(ctx, 3, false)
+	assert.Nil(t, err)
+	assert.Nil(t, runner.Check(ctx))
+}
+
+func TestRunner(t *testing.T) {
+	ctx := context.Background()
+	db, err := orm.NewTestDB()
+	assert.Nil(t, err)
+
+	migrations := []*Migration{
+		{Version: 1, Name: "add_a", File: "1_add_a.sql", Statements: []string{"CREATE TABLE a (id int)"}},
+		{Version: 2, Name: "add_b", File: "2_add_b.sql", Statements: []string{"CREATE TABLE b (id int)"}},
+		{Version: 3, Name: "add_c", File: "3_add_c.sql",
+			Statements: []string{"CREATE TABLE c (id int)", "INSERT INTO c (id) VALUES (1)"}},
+	}
+	runner, err := NewRunner(db, nil, migrations[:2], 0)
+	assert.Nil(t, err)
+
+	err = runner.Check(ctx)
+	assert.Equal(t, ErrSchemaBehind, perror.Cause(err))
+
+	// dry run applies nothing
+	applied, err := runner.Up(ctx, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(applied))
+	assert.False(t, db.Migrator().HasTable("a"))
+
+	// baseline marks version 1 as applied without running it
+	applied, err = runner.Baseline(ctx, 1, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(applied))
+	assert.False(t, db.Migrator().HasTable("a"))
+
+	applied, err = runner.Up(ctx, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(applied))
+	assert.Equal(t, uint64(2), applied[0].Version)
+	assert.True(t, db.Migrator().HasTable("b"))
+	assert.Nil(t, runner.Check(ctx))
+
+	// a newer binary brings a new migration
+	runner, err = NewRunner(db, nil, migrations, 0)
+	assert.Nil(t, err)
+	status, err := runner.Status(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), status.Current)
+	assert.Equal(t, 1, len(status.Pending))
+	applied, err = runner.Up(ctx, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(applied))
+	var count int64
+	assert.Nil(t, db.Table("c").Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	// an older binary finds versions it does not know
+	runner, err = NewRunner(db, nil, migrations[:1], 0)
+	assert.Nil(t, err)
+	status, err = runner.Status(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(status.Pending))
+	assert.ElementsMatch(t, []uint64{2, 3}, status.Unknown)
+
+	// failed migration is not recorded
+	runner, err = NewRunner(db, nil, append(migrations, &Migration{
+		Version: 4, Name: "bad", File: "4_bad.sql", Statements: []string{"CREATE TABLE"},
+	}), 0)
+	assert.Nil(t, err)
+	_, err = runner.Up(ctx, false)
+	assert.NotNil(t, err)
+	assert.Equal(t, ErrSchemaBehind, perror.Cause(runner.Check(ctx)))
+}
+
+func TestPostgresMigrations(t *testing.T) {
+	mysqlMigrations, err := Load("../../db/migrations")
+	assert.Nil(t, err)
+	postgresMigrations, err := Load("../../db/postgres/migrations")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, postgresMigrations)
+
+	// the initial postgres migration covers all mysql migrations up to its version,
+	// every later migration must be ported to both engines
+	initial := postgresMigrations[0].Version
+	mysqlVersions := make(map[uint64]bool)
+	for _, m := range mysqlMigrations {
+		if m.Version >= initial {
+			mysqlVersions[m.Version] = true
+		}
+	}
+	postgresVersions := make(map[uint64]bool)
+	for _, m := range postgresMigrations {
+		postgresVersions[m.Version] = true
+		for _, stmt := range m.Statements {
+			assert.NotContains(t, stmt, "`", "%s has mysql quoted identifiers", m.File)
+		}
+	}
+	assert.Equal(t, mysqlVersions, postgresVersions)
+}
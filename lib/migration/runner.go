@@ -0,0 +1,351 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	// TableName is the table tracking applied migration versions
+	TableName = "tb_schema_migration"
+
+	lockName           = "horizon_schema_migration"
+	defaultLockTimeout = 5 * time.Minute
+	lockRetryInterval  = time.Second
+
+	dialectMySQL    = "mysql"
+	dialectPostgres = "postgres"
+
+	// probeTable is checked to tell whether a database without migration history is empty
+	probeTable = "tb_user"
+)
+
+var (
+	// ErrSchemaBehind means there are migrations not applied to the database yet
+	ErrSchemaBehind = errors.New("database schema is behind")
+	// ErrNoHistory means the database was migrated by hand, it needs a baseline before applying migrations
+	ErrNoHistory = errors.New("database has tables but no migration history")
+)
+
+// Status is the result of comparing local migrations with versions applied in the database
+type Status struct {
+	// Current is the latest applied version, 0 if nothing is applied
+	Current uint64
+	Pending []*Migration
+	// Unknown are applied versions not found locally, which means the schema is newer than the binary
+	Unknown []uint64
+}
+
+type Runner struct {
+	gormDB      *gorm.DB
+	db          *sql.DB
+	dialect     string
+	snapshot    *Migration
+	migrations  []*Migration
+	lockTimeout time.Duration
+}
+
+// NewRunner creates a runner, snapshot is optional and is applied instead of the migrations
+// up to its version when the database is empty
+func NewRunner(db *gorm.DB, snapshot *Migration, migrations []*Migration,
+	lockTimeout time.Duration) (*Runner, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if lockTimeout <= 0 {
+		lockTimeout = defaultLockTimeout
+	}
+	return &Runner{
+		gormDB:      db,
+		db:          sqlDB,
+		dialect:     db.Dialector.Name(),
+		snapshot:    snapshot,
+		migrations:  migrations,
+		lockTimeout: lockTimeout,
+	}, nil
+}
+
+// Status returns pending migrations without applying them
+func (r *Runner) Status(ctx context.Context) (*Status, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if err := r.ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	return r.status(ctx, conn)
+}
+
+// Check returns ErrSchemaBehind if there are pending migrations,
+// or ErrNoHistory if the database has tables but no migration history
+func (r *Runner) Check(ctx context.Context) error {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if err := r.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	status, err := r.status(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if status.Current == 0 && len(status.Pending) > 0 && r.hasTable(ctx, conn, probeTable) {
+		return perror.Wrapf(ErrNoHistory, "%d migrations are not recorded", len(status.Pending))
+	}
+	if len(status.Pending) > 0 {
+		return perror.Wrapf(ErrSchemaBehind, "current version is %d, %d migrations are pending from %s",
+			status.Current, len(status.Pending), status.Pending[0].File)
+	}
+	return nil
+}
+
+// Up applies pending migrations in order under a database lock, so that only one instance migrates at a time.
+// An empty database is initialized by the snapshot first, migrations up to the snapshot version are recorded
+// as applied without running them.
+// Applied migrations are returned, or the ones to be applied if dryRun is true.
+// MySQL does not roll back DDL, a migration failed halfway must be fixed by hand before retrying,
+// while PostgreSQL runs each migration in a transaction.
+func (r *Runner) Up(ctx context.Context, dryRun bool) ([]*Migration, error) {
+	return r.apply(ctx, 0, dryRun, false)
+}
+
+// Baseline marks pending migrations up to version as applied without running them,
+// which is used for databases whose schema was migrated by hand before.
+func (r *Runner) Baseline(ctx context.Context, version uint64, dryRun bool) ([]*Migration, error) {
+	return r.apply(ctx, version, dryRun, true)
+}
+
+func (r *Runner) apply(ctx context.Context, maxVersion uint64, dryRun, markOnly bool) ([]*Migration, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	unlock, err := r.lock(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := r.ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	status, err := r.status(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make([]*Migration, 0, len(status.Pending))
+	pending := status.Pending
+	if !markOnly && status.Current == 0 && r.snapshot != nil && len(pending) > 0 {
+		if r.hasTable(ctx, conn, probeTable) {
+			return nil, perror.Wrapf(ErrNoHistory,
+				"mark the migrations already applied by hand with baseline first")
+		}
+		applied = append(applied, r.snapshot)
+		if !dryRun {
+			if err := r.exec(ctx, conn, r.snapshot); err != nil {
+				return nil, err
+			}
+		}
+		for len(pending) > 0 && pending[0].Version <= r.snapshot.Version {
+			if !dryRun {
+				if err := r.record(ctx, conn, pending[0]); err != nil {
+					return applied, err
+				}
+			}
+			pending = pending[1:]
+		}
+	}
+
+	for _, m := range pending {
+		if maxVersion > 0 && m.Version > maxVersion {
+			break
+		}
+		if dryRun {
+			applied = append(applied, m)
+			continue
+		}
+		if !markOnly {
+			if err := r.exec(ctx, conn, m); err != nil {
+				return applied, err
+			}
+		}
+		if err := r.record(ctx, conn, m); err != nil {
+			return applied, err
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// hasTable checks the table on conn, since another connection of the pool may not see the same database,
+// e.g. in-memory sqlite
+func (r *Runner) hasTable(ctx context.Context, conn *sql.Conn, table string) bool {
+	tx := r.gormDB.WithContext(ctx)
+	tx.Statement.ConnPool = conn
+	return tx.Migrator().HasTable(table)
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func (r *Runner) exec(ctx context.Context, conn *sql.Conn, m *Migration) error {
+	if r.dialect != dialectPostgres {
+		return execStatements(ctx, conn, m)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := execStatements(ctx, tx, m); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func execStatements(ctx context.Context, db execer, m *Migration) error {
+	for i, stmt := range m.Statements {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return perror.Wrapf(err, "failed to apply %s, statement %d", m.File, i+1)
+		}
+	}
+	return nil
+}
+
+func (r *Runner) record(ctx context.Context, conn *sql.Conn, m *Migration) error {
+	query := fmt.Sprintf("INSERT INTO %s (version, name, applied_at) VALUES (?, ?, ?)", TableName)
+	if r.dialect == dialectPostgres {
+		query = fmt.Sprintf("INSERT INTO %s (version, name, applied_at) VALUES ($1, $2, $3)", TableName)
+	}
+	if _, err := conn.ExecContext(ctx, query, m.Version, m.Name, time.Now()); err != nil {
+		return perror.Wrapf(err, "failed to record migration %s", m.File)
+	}
+	return nil
+}
+
+func (r *Runner) ensureTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+    version    BIGINT       NOT NULL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    applied_at TIMESTAMP    NOT NULL
+)`, TableName))
+	if err != nil {
+		return perror.Wrapf(err, "failed to create table %s", TableName)
+	}
+	return nil
+}
+
+func (r *Runner) status(ctx context.Context, conn *sql.Conn) (*Status, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("SELECT version FROM %s", TableName))
+	if err != nil {
+		return nil, perror.Wrapf(err, "failed to query table %s", TableName)
+	}
+	defer func() { _ = rows.Close() }()
+
+	applied := make(map[uint64]bool)
+	status := &Status{}
+	for rows.Next() {
+		var version uint64
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+		if version > status.Current {
+			status.Current = version
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	local := make(map[uint64]bool, len(r.migrations))
+	for _, m := range r.migrations {
+		local[m.Version] = true
+		if !applied[m.Version] {
+			status.Pending = append(status.Pending, m)
+		}
+	}
+	for version := range applied {
+		if !local[version] {
+			status.Unknown = append(status.Unknown, version)
+		}
+	}
+	return status, nil
+}
+
+// lock acquires a named lock of the database session, returns a func to release it
+func (r *Runner) lock(ctx context.Context, conn *sql.Conn) (func(), error) {
+	switch r.dialect {
+	case dialectMySQL:
+		var locked sql.NullInt64
+		if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)",
+			lockName, int(r.lockTimeout.Seconds())).Scan(&locked); err != nil {
+			return nil, perror.Wrap(err, "failed to acquire migration lock")
+		}
+		if !locked.Valid || locked.Int64 != 1 {
+			return nil, perror.Errorf("timeout to acquire migration lock after %v", r.lockTimeout)
+		}
+		return func() {
+			_, _ = conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+		}, nil
+	case dialectPostgres:
+		// advisory locks of postgres have no timeout, so try until the deadline
+		deadline := time.Now().Add(r.lockTimeout)
+		for {
+			var locked bool
+			if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))",
+				lockName).Scan(&locked); err != nil {
+				return nil, perror.Wrap(err, "failed to acquire migration lock")
+			}
+			if locked {
+				break
+			}
+			if time.Now().After(deadline) {
+				return nil, perror.Errorf("timeout to acquire migration lock after %v", r.lockTimeout)
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(lockRetryInterval):
+			}
+		}
+		return func() {
+			_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", lockName)
+		}, nil
+	default:
+		// sqlite is used by a single process, no lock is needed
+		return func() {}, nil
+	}
+}
@@ -24,7 +24,9 @@ import (
 	"time"
 
 	"github.com/horizoncd/horizon/lib/q"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -32,10 +34,88 @@ import (
 	"gorm.io/plugin/prometheus"
 )
 
+// TestPostgresDSNEnv is the environment variable of the postgres dsn in key=value format which DAO tests
+// run against, e.g. "host=localhost port=5432 user=horizon password=horizon dbname=horizon_test"
+const TestPostgresDSNEnv = "HORIZON_TEST_POSTGRES_DSN"
+
+// NewDB opens the metadata database of the configured type, mysql is used if the type is not set
+func NewDB(config db.Config) (*gorm.DB, error) {
+	switch config.Type {
+	case "", db.TypeMySQL:
+		return NewMySQLDB(config)
+	case db.TypePostgres:
+		return NewPostgresDB(config)
+	default:
+		return nil, perror.Errorf("unsupported database type %s", config.Type)
+	}
+}
+
 func NewMySQLDB(db db.Config) (*gorm.DB, error) {
 	conn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local", db.Username,
 		db.Password, db.Host, db.Port, db.Database)
 
+	setDefaults(&db)
+
+	sqlDB, err := sql.Open("mysql", conn)
+	if err != nil {
+		return nil, err
+	}
+	setConnPool(sqlDB, db)
+
+	orm, err := gorm.Open(mysql.New(mysql.Config{
+		Conn: sqlDB,
+	}), newConfig(db))
+	if err != nil {
+		return nil, err
+	}
+
+	if db.PrometheusEnabled {
+		if err := orm.Use(prometheus.New(prometheus.Config{
+			DBName: "mysql",
+			MetricsCollector: []prometheus.MetricsCollector{
+				&MySQLMetricsCollector{},
+			},
+		})); err != nil {
+			return nil, err
+		}
+	}
+
+	return orm, nil
+}
+
+func NewPostgresDB(db db.Config) (*gorm.DB, error) {
+	sslMode := db.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		db.Host, db.Port, db.Username, db.Password, db.Database, sslMode)
+
+	setDefaults(&db)
+
+	orm, err := gorm.Open(postgres.Open(dsn), newConfig(db))
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := orm.DB()
+	if err != nil {
+		return nil, err
+	}
+	setConnPool(sqlDB, db)
+
+	if db.PrometheusEnabled {
+		// only connection pool stats are collected, SHOW STATUS of mysql has no equivalent in postgres
+		if err := orm.Use(prometheus.New(prometheus.Config{
+			DBName: "postgres",
+		})); err != nil {
+			return nil, err
+		}
+	}
+
+	return orm, nil
+}
+
+func setDefaults(db *db.Config) {
 	// Set default value for SlowThreshold if not provided
 	if db.SlowThreshold == 0 {
 		db.SlowThreshold = 200 * time.Millisecond
@@ -48,20 +128,17 @@ func NewMySQLDB(db db.Config) (*gorm.DB, error) {
 	if db.MaxOpenConns == 0 {
 		db.MaxOpenConns = 100
 	}
+}
 
-	sqlDB, err := sql.Open("mysql", conn)
-	if err != nil {
-		return nil, err
-	}
-
+func setConnPool(sqlDB *sql.DB, db db.Config) {
 	sqlDB.SetMaxIdleConns(db.MaxIdleConns)
 	sqlDB.SetMaxOpenConns(db.MaxOpenConns)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 	sqlDB.SetConnMaxIdleTime(time.Hour)
+}
 
-	orm, err := gorm.Open(mysql.New(mysql.Config{
-		Conn: sqlDB,
-	}), &gorm.Config{
+func newConfig(db db.Config) *gorm.Config {
+	return &gorm.Config{
 		NamingStrategy: schema.NamingStrategy{
 			TablePrefix:   "tb_",
 			SingularTable: true,
@@ -73,24 +150,41 @@ func NewMySQLDB(db db.Config) (*gorm.DB, error) {
 				IgnoreRecordNotFoundError: false,
 				Colorful:                  true,
 			}),
-	})
-
-	if db.PrometheusEnabled {
-		if err := orm.Use(prometheus.New(prometheus.Config{
-			DBName: "mysql",
-			MetricsCollector: []prometheus.MetricsCollector{
-				&MySQLMetricsCollector{},
-			},
-		})); err != nil {
-			return nil, err
-		}
 	}
+}
+
+func NewSqliteDB(file string) (*gorm.DB, error) {
+	orm, err := gorm.Open(sqlite.Open(file), newTestConfig())
 
 	return orm, err
 }
 
-func NewSqliteDB(file string) (*gorm.DB, error) {
-	orm, err := gorm.Open(sqlite.Open(file), &gorm.Config{
+// NewTestDB returns the database which DAO tests run against, it is an in-memory sqlite database by default.
+// If the TestPostgresDSNEnv environment variable is set, a new schema is created in the postgres database
+// for each call, so that tests of different packages do not see each other's tables.
+func NewTestDB() (*gorm.DB, error) {
+	dsn := os.Getenv(TestPostgresDSNEnv)
+	if dsn == "" {
+		return NewSqliteDB("")
+	}
+
+	orm, err := gorm.Open(postgres.Open(dsn), newTestConfig())
+	if err != nil {
+		return nil, err
+	}
+	testSchema := fmt.Sprintf("test_%d_%d", os.Getpid(), time.Now().UnixNano())
+	if err := orm.Exec(fmt.Sprintf("CREATE SCHEMA %s", testSchema)).Error; err != nil {
+		return nil, err
+	}
+	if sqlDB, err := orm.DB(); err == nil {
+		_ = sqlDB.Close()
+	}
+
+	return gorm.Open(postgres.Open(fmt.Sprintf("%s search_path=%s", dsn, testSchema)), newTestConfig())
+}
+
+func newTestConfig() *gorm.Config {
+	return &gorm.Config{
 		NamingStrategy: schema.NamingStrategy{
 			TablePrefix:   "tb_",
 			SingularTable: true,
@@ -104,9 +198,7 @@ func NewSqliteDB(file string) (*gorm.DB, error) {
 				Colorful:                  true,
 			},
 		),
-	})
-
-	return orm, err
+	}
 }
 
 func FormatSortExp(query *q.Query) string {
@@ -96,6 +96,21 @@ func (mr *MockInterfaceMockRecorder) CreateBranch(ctx, pid, branch, fromRef inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBranch", reflect.TypeOf((*MockInterface)(nil).CreateBranch), ctx, pid, branch, fromRef)
 }
 
+// CreateDeployment mocks base method.
+func (m *MockInterface) CreateDeployment(ctx context.Context, pid interface{}, opt *gitlab0.CreateProjectDeploymentOptions) (*gitlab0.Deployment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeployment", ctx, pid, opt)
+	ret0, _ := ret[0].(*gitlab0.Deployment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDeployment indicates an expected call of CreateDeployment.
+func (mr *MockInterfaceMockRecorder) CreateDeployment(ctx, pid, opt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeployment", reflect.TypeOf((*MockInterface)(nil).CreateDeployment), ctx, pid, opt)
+}
+
 // CreateGroup mocks base method.
 func (m *MockInterface) CreateGroup(ctx context.Context, name, path string, parentID *int, visibility string) (*gitlab0.Group, error) {
 	m.ctrl.T.Helper()
@@ -126,6 +141,21 @@ func (mr *MockInterfaceMockRecorder) CreateMR(ctx, pid, source, target, title in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMR", reflect.TypeOf((*MockInterface)(nil).CreateMR), ctx, pid, source, target, title)
 }
 
+// CreateMRNote mocks base method.
+func (m *MockInterface) CreateMRNote(ctx context.Context, pid interface{}, mrID int, body string) (*gitlab0.Note, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMRNote", ctx, pid, mrID, body)
+	ret0, _ := ret[0].(*gitlab0.Note)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMRNote indicates an expected call of CreateMRNote.
+func (mr *MockInterfaceMockRecorder) CreateMRNote(ctx, pid, mrID, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMRNote", reflect.TypeOf((*MockInterface)(nil).CreateMRNote), ctx, pid, mrID, body)
+}
+
 // CreateProject mocks base method.
 func (m *MockInterface) CreateProject(ctx context.Context, name string, groupID int, visibility string) (*gitlab0.Project, error) {
 	m.ctrl.T.Helper()
@@ -391,6 +421,21 @@ func (mr *MockInterfaceMockRecorder) ListTag(ctx, pid, listTagOptions interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTag", reflect.TypeOf((*MockInterface)(nil).ListTag), ctx, pid, listTagOptions)
 }
 
+// SetCommitStatus mocks base method.
+func (m *MockInterface) SetCommitStatus(ctx context.Context, pid interface{}, sha string, opt *gitlab0.SetCommitStatusOptions) (*gitlab0.CommitStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCommitStatus", ctx, pid, sha, opt)
+	ret0, _ := ret[0].(*gitlab0.CommitStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetCommitStatus indicates an expected call of SetCommitStatus.
+func (mr *MockInterfaceMockRecorder) SetCommitStatus(ctx, pid, sha, opt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCommitStatus", reflect.TypeOf((*MockInterface)(nil).SetCommitStatus), ctx, pid, sha, opt)
+}
+
 // TransferProject mocks base method.
 func (m *MockInterface) TransferProject(ctx context.Context, pid, gid interface{}) error {
 	m.ctrl.T.Helper()
@@ -170,6 +170,21 @@ func (mr *MockManagerMockRecorder) GetByNameFuzzilyIncludeSoftDelete(ctx, name i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByNameFuzzilyIncludeSoftDelete", reflect.TypeOf((*MockManager)(nil).GetByNameFuzzilyIncludeSoftDelete), ctx, name)
 }
 
+// GetPrivateApplications mocks base method.
+func (m *MockManager) GetPrivateApplications(ctx context.Context) ([]*models.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrivateApplications", ctx)
+	ret0, _ := ret[0].([]*models.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrivateApplications indicates an expected call of GetPrivateApplications.
+func (mr *MockManagerMockRecorder) GetPrivateApplications(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrivateApplications", reflect.TypeOf((*MockManager)(nil).GetPrivateApplications), ctx)
+}
+
 // List mocks base method.
 func (m *MockManager) List(ctx context.Context, groupIDs []uint, query *q.Query) (int, []*models.Application, error) {
 	m.ctrl.T.Helper()
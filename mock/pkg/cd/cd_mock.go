@@ -77,6 +77,21 @@ func (mr *MockCDMockRecorder) DeployCluster(ctx, params interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeployCluster", reflect.TypeOf((*MockCD)(nil).DeployCluster), ctx, params)
 }
 
+// GetClusterDrift mocks base method.
+func (m *MockCD) GetClusterDrift(ctx context.Context, params *cd.GetClusterDriftParams) (*cd.ClusterDrift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClusterDrift", ctx, params)
+	ret0, _ := ret[0].(*cd.ClusterDrift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClusterDrift indicates an expected call of GetClusterDrift.
+func (mr *MockCDMockRecorder) GetClusterDrift(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterDrift", reflect.TypeOf((*MockCD)(nil).GetClusterDrift), ctx, params)
+}
+
 // GetClusterState mocks base method.
 func (m *MockCD) GetClusterState(ctx context.Context, params *cd.GetClusterStateV2Params) (*cd.ClusterStateV2, error) {
 	m.ctrl.T.Helper()
@@ -35,6 +35,48 @@ func (m *MockGitGetter) EXPECT() *MockGitGetterMockRecorder {
 	return m.recorder
 }
 
+// CreateCommitStatus mocks base method.
+func (m *MockGitGetter) CreateCommitStatus(ctx context.Context, gitURL string, status *git.CommitStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCommitStatus", ctx, gitURL, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCommitStatus indicates an expected call of CreateCommitStatus.
+func (mr *MockGitGetterMockRecorder) CreateCommitStatus(ctx, gitURL, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCommitStatus", reflect.TypeOf((*MockGitGetter)(nil).CreateCommitStatus), ctx, gitURL, status)
+}
+
+// CreateDeployment mocks base method.
+func (m *MockGitGetter) CreateDeployment(ctx context.Context, gitURL string, deployment *git.Deployment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeployment", ctx, gitURL, deployment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDeployment indicates an expected call of CreateDeployment.
+func (mr *MockGitGetterMockRecorder) CreateDeployment(ctx, gitURL, deployment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeployment", reflect.TypeOf((*MockGitGetter)(nil).CreateDeployment), ctx, gitURL, deployment)
+}
+
+// CreateMergeRequestComment mocks base method.
+func (m *MockGitGetter) CreateMergeRequestComment(ctx context.Context, gitURL string, mrID int, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMergeRequestComment", ctx, gitURL, mrID, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateMergeRequestComment indicates an expected call of CreateMergeRequestComment.
+func (mr *MockGitGetterMockRecorder) CreateMergeRequestComment(ctx, gitURL, mrID, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMergeRequestComment", reflect.TypeOf((*MockGitGetter)(nil).CreateMergeRequestComment), ctx, gitURL, mrID, body)
+}
+
 // GetCommit mocks base method.
 func (m *MockGitGetter) GetCommit(ctx context.Context, gitURL, refType, ref string) (*git.Commit, error) {
 	m.ctrl.T.Helper()
@@ -108,6 +108,35 @@ func (mr *MockClusterGitRepoMockRecorder) DeleteCluster(ctx, application, cluste
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCluster", reflect.TypeOf((*MockClusterGitRepo)(nil).DeleteCluster), ctx, application, cluster, clusterID)
 }
 
+// EnsureSecretFile mocks base method.
+func (m *MockClusterGitRepo) EnsureSecretFile(ctx context.Context, application, cluster string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureSecretFile", ctx, application, cluster)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureSecretFile indicates an expected call of EnsureSecretFile.
+func (mr *MockClusterGitRepoMockRecorder) EnsureSecretFile(ctx, application, cluster interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureSecretFile", reflect.TypeOf((*MockClusterGitRepo)(nil).EnsureSecretFile), ctx, application, cluster)
+}
+
+// GetChartFiles mocks base method.
+func (m *MockClusterGitRepo) GetChartFiles(ctx context.Context, application, cluster, commit string) (*gitrepo.ChartFiles, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChartFiles", ctx, application, cluster, commit)
+	ret0, _ := ret[0].(*gitrepo.ChartFiles)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChartFiles indicates an expected call of GetChartFiles.
+func (mr *MockClusterGitRepoMockRecorder) GetChartFiles(ctx, application, cluster, commit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChartFiles", reflect.TypeOf((*MockClusterGitRepo)(nil).GetChartFiles), ctx, application, cluster, commit)
+}
+
 // GetCluster mocks base method.
 func (m *MockClusterGitRepo) GetCluster(ctx context.Context, application, cluster, templateName string) (*gitrepo.ClusterFiles, error) {
 	m.ctrl.T.Helper()
@@ -359,6 +388,21 @@ func (mr *MockClusterGitRepoMockRecorder) UpdateRestartTime(ctx, application, cl
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRestartTime", reflect.TypeOf((*MockClusterGitRepo)(nil).UpdateRestartTime), ctx, application, cluster, template)
 }
 
+// UpdateSecrets mocks base method.
+func (m *MockClusterGitRepo) UpdateSecrets(ctx context.Context, application, cluster, template string, secretValue *gitrepo.SecretValue) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSecrets", ctx, application, cluster, template, secretValue)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSecrets indicates an expected call of UpdateSecrets.
+func (mr *MockClusterGitRepoMockRecorder) UpdateSecrets(ctx, application, cluster, template, secretValue interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSecrets", reflect.TypeOf((*MockClusterGitRepo)(nil).UpdateSecrets), ctx, application, cluster, template, secretValue)
+}
+
 // UpdateTags mocks base method.
 func (m *MockClusterGitRepo) UpdateTags(ctx context.Context, application, cluster, templateName string, tags []*models.Tag) error {
 	m.ctrl.T.Helper()
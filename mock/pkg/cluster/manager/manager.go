@@ -193,6 +193,21 @@ func (mr *MockManagerMockRecorder) ListClusterWithExpiry(ctx, query interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClusterWithExpiry", reflect.TypeOf((*MockManager)(nil).ListClusterWithExpiry), ctx, query)
 }
 
+// ListRunningClusters mocks base method.
+func (m *MockManager) ListRunningClusters(ctx context.Context, query *q.Query) ([]*models.Cluster, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRunningClusters", ctx, query)
+	ret0, _ := ret[0].([]*models.Cluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRunningClusters indicates an expected call of ListRunningClusters.
+func (mr *MockManagerMockRecorder) ListRunningClusters(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRunningClusters", reflect.TypeOf((*MockManager)(nil).ListRunningClusters), ctx, query)
+}
+
 // UpdateByID mocks base method.
 func (m *MockManager) UpdateByID(ctx context.Context, id uint, cluster *models.Cluster) (*models.Cluster, error) {
 	m.ctrl.T.Helper()
@@ -35,6 +35,48 @@ func (m *MockHelper) EXPECT() *MockHelperMockRecorder {
 	return m.recorder
 }
 
+// CreateCommitStatus mocks base method.
+func (m *MockHelper) CreateCommitStatus(ctx context.Context, gitURL string, status *git.CommitStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCommitStatus", ctx, gitURL, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCommitStatus indicates an expected call of CreateCommitStatus.
+func (mr *MockHelperMockRecorder) CreateCommitStatus(ctx, gitURL, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCommitStatus", reflect.TypeOf((*MockHelper)(nil).CreateCommitStatus), ctx, gitURL, status)
+}
+
+// CreateDeployment mocks base method.
+func (m *MockHelper) CreateDeployment(ctx context.Context, gitURL string, deployment *git.Deployment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeployment", ctx, gitURL, deployment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDeployment indicates an expected call of CreateDeployment.
+func (mr *MockHelperMockRecorder) CreateDeployment(ctx, gitURL, deployment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeployment", reflect.TypeOf((*MockHelper)(nil).CreateDeployment), ctx, gitURL, deployment)
+}
+
+// CreateMergeRequestComment mocks base method.
+func (m *MockHelper) CreateMergeRequestComment(ctx context.Context, gitURL string, mrID int, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMergeRequestComment", ctx, gitURL, mrID, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateMergeRequestComment indicates an expected call of CreateMergeRequestComment.
+func (mr *MockHelperMockRecorder) CreateMergeRequestComment(ctx, gitURL, mrID, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMergeRequestComment", reflect.TypeOf((*MockHelper)(nil).CreateMergeRequestComment), ctx, gitURL, mrID, body)
+}
+
 // GetCommit mocks base method.
 func (m *MockHelper) GetCommit(ctx context.Context, gitURL, refType, ref string) (*git.Commit, error) {
 	m.ctrl.T.Helper()
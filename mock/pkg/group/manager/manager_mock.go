@@ -188,9 +188,9 @@ func (mr *MockManagerMockRecorder) GetByPaths(ctx, paths interface{}) *gomock.Ca
 }
 
 // GetChildren mocks base method.
-func (m *MockManager) GetChildren(ctx context.Context, parentID uint, pageNumber, pageSize int) ([]*models0.GroupOrApplication, int64, error) {
+func (m *MockManager) GetChildren(ctx context.Context, parentID uint, hiddenGroupIDs, hiddenApplicationIDs []uint, pageNumber, pageSize int) ([]*models0.GroupOrApplication, int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetChildren", ctx, parentID, pageNumber, pageSize)
+	ret := m.ctrl.Call(m, "GetChildren", ctx, parentID, hiddenGroupIDs, hiddenApplicationIDs, pageNumber, pageSize)
 	ret0, _ := ret[0].([]*models0.GroupOrApplication)
 	ret1, _ := ret[1].(int64)
 	ret2, _ := ret[2].(error)
@@ -198,9 +198,9 @@ func (m *MockManager) GetChildren(ctx context.Context, parentID uint, pageNumber
 }
 
 // GetChildren indicates an expected call of GetChildren.
-func (mr *MockManagerMockRecorder) GetChildren(ctx, parentID, pageNumber, pageSize interface{}) *gomock.Call {
+func (mr *MockManagerMockRecorder) GetChildren(ctx, parentID, hiddenGroupIDs, hiddenApplicationIDs, pageNumber, pageSize interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChildren", reflect.TypeOf((*MockManager)(nil).GetChildren), ctx, parentID, pageNumber, pageSize)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChildren", reflect.TypeOf((*MockManager)(nil).GetChildren), ctx, parentID, hiddenGroupIDs, hiddenApplicationIDs, pageNumber, pageSize)
 }
 
 // GetDefaultRegions mocks base method.
@@ -218,6 +218,21 @@ func (mr *MockManagerMockRecorder) GetDefaultRegions(ctx, id interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDefaultRegions", reflect.TypeOf((*MockManager)(nil).GetDefaultRegions), ctx, id)
 }
 
+// GetPrivateGroups mocks base method.
+func (m *MockManager) GetPrivateGroups(ctx context.Context) ([]*models0.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrivateGroups", ctx)
+	ret0, _ := ret[0].([]*models0.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrivateGroups indicates an expected call of GetPrivateGroups.
+func (mr *MockManagerMockRecorder) GetPrivateGroups(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrivateGroups", reflect.TypeOf((*MockManager)(nil).GetPrivateGroups), ctx)
+}
+
 // GetSelectableRegions mocks base method.
 func (m *MockManager) GetSelectableRegions(ctx context.Context, id uint) (models1.RegionParts, error) {
 	m.ctrl.T.Helper()
@@ -249,9 +264,9 @@ func (mr *MockManagerMockRecorder) GetSelectableRegionsByEnv(ctx, id, env interf
 }
 
 // GetSubGroups mocks base method.
-func (m *MockManager) GetSubGroups(ctx context.Context, id uint, pageNumber, pageSize int) ([]*models0.Group, int64, error) {
+func (m *MockManager) GetSubGroups(ctx context.Context, id uint, hiddenGroupIDs []uint, pageNumber, pageSize int) ([]*models0.Group, int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSubGroups", ctx, id, pageNumber, pageSize)
+	ret := m.ctrl.Call(m, "GetSubGroups", ctx, id, hiddenGroupIDs, pageNumber, pageSize)
 	ret0, _ := ret[0].([]*models0.Group)
 	ret1, _ := ret[1].(int64)
 	ret2, _ := ret[2].(error)
@@ -259,9 +274,9 @@ func (m *MockManager) GetSubGroups(ctx context.Context, id uint, pageNumber, pag
 }
 
 // GetSubGroups indicates an expected call of GetSubGroups.
-func (mr *MockManagerMockRecorder) GetSubGroups(ctx, id, pageNumber, pageSize interface{}) *gomock.Call {
+func (mr *MockManagerMockRecorder) GetSubGroups(ctx, id, hiddenGroupIDs, pageNumber, pageSize interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubGroups", reflect.TypeOf((*MockManager)(nil).GetSubGroups), ctx, id, pageNumber, pageSize)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubGroups", reflect.TypeOf((*MockManager)(nil).GetSubGroups), ctx, id, hiddenGroupIDs, pageNumber, pageSize)
 }
 
 // GetSubGroupsByGroupIDs mocks base method.
@@ -182,6 +182,21 @@ func (mr *MockManagerMockRecorder) ListMembersByUserID(ctx, userID interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembersByUserID", reflect.TypeOf((*MockManager)(nil).ListMembersByUserID), ctx, userID)
 }
 
+// ListMembersOfUser mocks base method.
+func (m *MockManager) ListMembersOfUser(ctx context.Context, userID uint, resourceTypes []models.ResourceType) ([]models.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMembersOfUser", ctx, userID, resourceTypes)
+	ret0, _ := ret[0].([]models.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMembersOfUser indicates an expected call of ListMembersOfUser.
+func (mr *MockManagerMockRecorder) ListMembersOfUser(ctx, userID, resourceTypes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembersOfUser", reflect.TypeOf((*MockManager)(nil).ListMembersOfUser), ctx, userID, resourceTypes)
+}
+
 // ListResourceOfMemberInfo mocks base method.
 func (m *MockManager) ListResourceOfMemberInfo(ctx context.Context, resourceType models.ResourceType, memberInfo uint) ([]uint, error) {
 	m.ctrl.T.Helper()
@@ -24,6 +24,7 @@ import (
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/lib/q"
 	"github.com/horizoncd/horizon/pkg/accesstoken/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 )
 
@@ -64,7 +65,7 @@ func (d *dao) ListAccessTokensByResource(ctx context.Context, resourceType strin
 
 	result := d.db.WithContext(ctx).Table("tb_token as t").
 		Joins("join tb_user as u on t.user_id = u.id").
-		Joins("join tb_member as m on u.id = m.membername_id").
+		Joins("join tb_member as m on u.id = m.membername_id and m.member_type = ?", membermodels.MemberUser).
 		Where("u.user_type = ?", usermodels.UserTypeRobot).
 		Where("m.resource_type = ?", resourceType).
 		Where("m.resource_id = ?", resourceID).
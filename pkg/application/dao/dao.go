@@ -42,6 +42,7 @@ type DAO interface {
 	GetByID(ctx context.Context, id uint, includeSoftDelete bool) (*models.Application, error)
 	GetByIDs(ctx context.Context, ids []uint) ([]*models.Application, error)
 	GetByGroupIDs(ctx context.Context, groupIDs []uint) ([]*models.Application, error)
+	GetByVisibilityLevel(ctx context.Context, visibilityLevel string) ([]*models.Application, error)
 	GetByName(ctx context.Context, name string) (*models.Application, error)
 	GetByNamesUnderGroup(ctx context.Context, groupID uint, names []string) ([]*models.Application, error)
 	// GetByNameFuzzily get applications that fuzzily matching the given name
@@ -81,7 +82,7 @@ func (d *dao) GetByNameFuzzily(ctx context.Context, name string,
 	includeSoftDelete bool) ([]*models.Application, error) {
 	var applications []*models.Application
 
-	statement := d.db.Unscoped().WithContext(ctx).Where("name like ?", fmt.Sprintf("%%%s%%", name))
+	statement := d.db.Unscoped().WithContext(ctx).Where("lower(name) like lower(?)", fmt.Sprintf("%%%s%%", name))
 	if !includeSoftDelete {
 		statement.Where("deleted_ts = 0")
 	}
@@ -141,6 +142,15 @@ func (d *dao) GetByGroupIDs(ctx context.Context, groupIDs []uint) ([]*models.App
 	return applications, result.Error
 }
 
+func (d *dao) GetByVisibilityLevel(ctx context.Context, visibilityLevel string) ([]*models.Application, error) {
+	var applications []*models.Application
+	result := d.db.WithContext(ctx).Raw(common.ApplicationQueryByVisibility, visibilityLevel).Scan(&applications)
+	if result.Error != nil {
+		return applications, herrors.NewErrGetFailed(herrors.ApplicationInDB, result.Error.Error())
+	}
+	return applications, nil
+}
+
 func (d *dao) GetByName(ctx context.Context, name string) (*models.Application, error) {
 	var application models.Application
 	result := d.db.WithContext(ctx).Raw(common.ApplicationQueryByName, name).First(&application)
@@ -235,6 +245,7 @@ func (d *dao) UpdateByID(ctx context.Context, id uint, application *models.Appli
 		// 2. update value
 		applicationInDB.Description = application.Description
 		applicationInDB.Priority = application.Priority
+		applicationInDB.VisibilityLevel = application.VisibilityLevel
 		applicationInDB.GitURL = application.GitURL
 		applicationInDB.GitSubfolder = application.GitSubfolder
 		applicationInDB.GitRefType = application.GitRefType
@@ -314,7 +325,7 @@ func (d *dao) List(ctx context.Context, groupIDs []uint,
 		for k, v := range query.Keywords {
 			switch k {
 			case corecommon.ApplicationQueryName:
-				statement = statement.Where("a.name like ?", fmt.Sprintf("%%%v%%", v))
+				statement = statement.Where("lower(a.name) like lower(?)", fmt.Sprintf("%%%v%%", v))
 			case corecommon.ApplicationQueryByTemplate:
 				statement = statement.Where("a.template = ?", v)
 			case corecommon.ApplicationQueryByRelease:
@@ -327,6 +338,8 @@ func (d *dao) List(ctx context.Context, groupIDs []uint,
 				}
 			case corecommon.ApplicationQueryWithDeleted:
 				withDeleted = true
+			case corecommon.ApplicationQueryHiddenApplications:
+				statement = statement.Where("a.id not in ?", v)
 			}
 		}
 		if !withDeleted {
@@ -341,8 +354,7 @@ func (d *dao) List(ctx context.Context, groupIDs []uint,
 			statementUser := genSQL().
 				Joins("join tb_member as m on m.resource_id = a.id").
 				Where("m.resource_type = ?", corecommon.ResourceApplication).
-				Where("m.member_type = '0'").
-				Where("m.membername_id = ?", userID).
+				Where(common.MemberOfUser, userID, userID).
 				Where("m.deleted_ts = 0")
 			if len(groupIDs) != 0 {
 				// union query of authorized applications inherited from user's groups
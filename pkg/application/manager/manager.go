@@ -21,6 +21,7 @@ import (
 	applicationdao "github.com/horizoncd/horizon/pkg/application/dao"
 	"github.com/horizoncd/horizon/pkg/application/models"
 	groupdao "github.com/horizoncd/horizon/pkg/group/dao"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	userdao "github.com/horizoncd/horizon/pkg/user/dao"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	"gorm.io/gorm"
@@ -34,6 +35,9 @@ type Manager interface {
 	GetByIDIncludeSoftDelete(ctx context.Context, id uint) (*models.Application, error)
 	GetByIDs(ctx context.Context, ids []uint) ([]*models.Application, error)
 	GetByGroupIDs(ctx context.Context, groupIDs []uint) ([]*models.Application, error)
+	// GetPrivateApplications return the applications marked as private,
+	// not including the ones only under private groups
+	GetPrivateApplications(ctx context.Context) ([]*models.Application, error)
 	GetByName(ctx context.Context, name string) (*models.Application, error)
 	GetByNameFuzzily(ctx context.Context, name string) ([]*models.Application, error)
 	// GetByNameFuzzily get applications that fuzzily matching the given name
@@ -96,6 +100,10 @@ func (m *manager) GetByGroupIDs(ctx context.Context, groupIDs []uint) ([]*models
 	return m.applicationDAO.GetByGroupIDs(ctx, groupIDs)
 }
 
+func (m *manager) GetPrivateApplications(ctx context.Context) ([]*models.Application, error) {
+	return m.applicationDAO.GetByVisibilityLevel(ctx, groupmodels.VisibilityPrivate)
+}
+
 func (m *manager) GetByName(ctx context.Context, name string) (*models.Application, error) {
 	application, err := m.applicationDAO.GetByName(ctx, name)
 	if err != nil {
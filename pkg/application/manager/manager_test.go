@@ -34,6 +34,7 @@ import (
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
 	"github.com/horizoncd/horizon/pkg/server/global"
+	teammodels "github.com/horizoncd/horizon/pkg/team/models"
 	userdao "github.com/horizoncd/horizon/pkg/user/dao"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	"github.com/horizoncd/horizon/pkg/util/log"
@@ -41,7 +42,7 @@ import (
 )
 
 var (
-	db, _     = orm.NewSqliteDB("")
+	db, _     = orm.NewTestDB()
 	ctx       context.Context
 	mgr       = New(db)
 	memberMgr = membermanager.New(db)
@@ -70,7 +71,7 @@ func TestMain(m *testing.M) {
 	if err := db.AutoMigrate(&models.Application{}); err != nil {
 		panic(err)
 	}
-	if err := db.AutoMigrate(&membermodels.Member{}, &usermodels.User{}); err != nil {
+	if err := db.AutoMigrate(&membermodels.Member{}, &usermodels.User{}, &teammodels.TeamMember{}); err != nil {
 		panic(err)
 	}
 	if err := db.AutoMigrate(&groupmodels.Group{}); err != nil {
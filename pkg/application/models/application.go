@@ -15,6 +15,7 @@
 package models
 
 import (
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	"github.com/horizoncd/horizon/pkg/server/global"
 )
 
@@ -33,6 +34,7 @@ type Application struct {
 	Name            string
 	Description     string
 	Priority        Priority
+	VisibilityLevel string
 	GitURL          string
 	GitSubfolder    string
 	GitRef          string
@@ -43,3 +45,9 @@ type Application struct {
 	CreatedBy       uint
 	UpdatedBy       uint
 }
+
+// IsPrivate returns whether the application itself is private,
+// privacy inherited from its groups is not considered
+func (a *Application) IsPrivate() bool {
+	return a.VisibilityLevel == groupmodels.VisibilityPrivate
+}
@@ -77,6 +77,9 @@ type (
 		// GetApplicationTree get resource-tree of an application in argoCD
 		GetApplicationTree(ctx context.Context, application string) (*v1alpha1.ApplicationTree, error)
 
+		// GetManagedResources get the desired and live states of resources managed by an application in argoCD
+		GetManagedResources(ctx context.Context, application string) ([]*v1alpha1.ResourceDiff, error)
+
 		// GetApplicationResource get a resource under an application in argoCD
 		GetApplicationResource(ctx context.Context, application string,
 			param ResourceParams, resource interface{}) error
@@ -427,6 +430,41 @@ func (h *helper) GetApplicationTree(ctx context.Context, application string) (
 	return tree, nil
 }
 
+func (h *helper) GetManagedResources(ctx context.Context, application string) (
+	resources []*v1alpha1.ResourceDiff, err error) {
+	const op = "argo: get managed resources"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	url := fmt.Sprintf("%v/api/v1/applications/%v/managed-resources", h.URL, application)
+	resp, err := h.sendHTTPRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, herrors.NewErrNotFound(herrors.ApplicationInArgo,
+			fmt.Sprintf("application %s not found", application))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrReadFailed, err.Error())
+	}
+
+	var response struct {
+		Items []*v1alpha1.ResourceDiff `json:"items"`
+	}
+	if err = json.Unmarshal(data, &response); err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+
+	return response.Items, nil
+}
+
 func (h *helper) GetApplicationResource(ctx context.Context, application string,
 	gvk ResourceParams, resource interface{}) (err error) {
 	const op = "argo: get application resource"
@@ -260,6 +260,19 @@ func SharedTestApplication(ctx context.Context, argoClient *helper, t *testing.T
 		t.Log("application tree:", string(data))
 	}
 
+	if _, err := argoClient.GetManagedResources(ctx, "notfound"); err == nil {
+		assert.NotNil(t, err)
+		_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+		assert.True(t, ok)
+	}
+
+	if resources, err := argoClient.GetManagedResources(ctx, _cluster2); err != nil {
+		t.Fatal(err)
+	} else {
+		assert.Equal(t, 1, len(resources))
+		assert.Equal(t, "Deployment", resources[0].Kind)
+	}
+
 	var deployment *apps.Deployment
 	err := argoClient.GetApplicationResource(ctx, _cluster2, ResourceParams{
 		Group:        "apps",
@@ -78,6 +78,8 @@ func NewArgoServer() *ArgoServer {
 		HandlerFunc(c.GetApplication)
 	r.Path("/api/v1/applications/{application}/resource-tree").Methods(http.MethodGet).
 		HandlerFunc(c.GetApplicationTree)
+	r.Path("/api/v1/applications/{application}/managed-resources").Methods(http.MethodGet).
+		HandlerFunc(c.GetManagedResources)
 	r.Path("/api/v1/applications/{application}/resource").Methods(http.MethodGet).
 		Queries("namespace", "{namespace}", "resourceName", "{resourceName}",
 			"group", "{group}", "version", "{version}", "kind", "{kind}").
@@ -236,6 +238,27 @@ func (argoServer *ArgoServer) DeleteApplication(w http.ResponseWriter, r *http.R
 	w.WriteHeader(http.StatusOK)
 }
 
+func (argoServer *ArgoServer) GetManagedResources(w http.ResponseWriter, r *http.Request) {
+	if strings.Contains(r.URL.Path, "notfound") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	d := []byte(`
+{
+  "items": [
+    {
+      "group": "apps",
+      "kind": "Deployment",
+      "namespace": "test-guanggao",
+      "name": "unit-test-repo-test-2",
+      "targetState": "{\"spec\":{\"replicas\":2}}",
+      "liveState": "{\"spec\":{\"replicas\":1}}"
+    }
+  ]
+}`)
+	_, _ = w.Write(d)
+}
+
 func (argoServer *ArgoServer) GetApplicationTree(w http.ResponseWriter, r *http.Request) {
 	if strings.Contains(r.URL.Path, "notfound") {
 		w.WriteHeader(http.StatusNotFound)
@@ -94,6 +94,8 @@ type CD interface {
 	GetResourceTree(ctx context.Context, params *GetResourceTreeParams) ([]ResourceNode, error)
 	GetStep(ctx context.Context, params *GetStepParams) (*Step, error)
 	GetPodEvents(ctx context.Context, params *GetPodEventsParams) ([]Event, error)
+	// GetClusterDrift compares the desired state of cluster with its live objects
+	GetClusterDrift(ctx context.Context, params *GetClusterDriftParams) (*ClusterDrift, error)
 }
 
 type cd struct {
@@ -104,7 +106,8 @@ type cd struct {
 	targetRevision    string
 }
 
-func NewCD(informerFactories *regioninformers.RegionInformers, clusterGitRepo gitrepo.ClusterGitRepo,
+// newArgoCD returns the cd engine backed by argocd
+func newArgoCD(informerFactories *regioninformers.RegionInformers, clusterGitRepo gitrepo.ClusterGitRepo,
 	argoCDMapper argocdconf.Mapper, regionArgoCDMapper argocdconf.RegionMapper, targetRevision string) CD {
 	return &cd{
 		kubeClientFactory: kubeclient.Fty,
@@ -241,7 +244,8 @@ func (c *cd) GetStep(ctx context.Context, params *GetStepParams) (*Step, error)
 	const op = "cd: get step"
 	defer wlog.Start(ctx, op).StopPrint()
 
-	_, kubeClient, err := c.kubeClientFactory.GetByK8SServer(params.RegionEntity.Server, params.RegionEntity.Certificate)
+	_, kubeClient, err := c.kubeClientFactory.GetByK8SServer(params.RegionEntity.Server,
+		string(params.RegionEntity.Certificate))
 	if err != nil {
 		return nil, err
 	}
@@ -257,9 +261,15 @@ func (c *cd) GetStep(ctx context.Context, params *GetStepParams) (*Step, error)
 		return nil, err
 	}
 
+	return getStepOfTree(resourceTreeInArgo, kubeClient), nil
+}
+
+// getStepOfTree returns step of the first workload in the tree which has steps
+func getStepOfTree(resourceTree *applicationV1alpha1.ApplicationTree, kubeClient *kube.Client) *Step {
+	var err error
 	ifContinue := true
 	step := (*workload.Step)(nil)
-	c.traverseResourceTree(resourceTreeInArgo, func(node *ResourceTreeNode) bool {
+	traverseResourceTree(resourceTree, func(node *ResourceTreeNode) bool {
 		if !ifContinue {
 			return ifContinue
 		}
@@ -288,7 +298,7 @@ func (c *cd) GetStep(ctx context.Context, params *GetStepParams) (*Step, error)
 			Replicas:     []int{},
 			ManualPaused: false,
 			AutoPromote:  false,
-		}, nil
+		}
 	}
 
 	return &Step{
@@ -298,7 +308,7 @@ func (c *cd) GetStep(ctx context.Context, params *GetStepParams) (*Step, error)
 		ManualPaused: step.ManualPaused,
 		AutoPromote:  step.AutoPromote,
 		Extra:        step.Extra,
-	}, nil
+	}
 }
 
 // GetClusterState fetches status of cluster
@@ -349,7 +359,8 @@ func (c *cd) GetClusterState(ctx context.Context,
 		return status, nil
 	}
 
-	_, kubeClient, err := c.kubeClientFactory.GetByK8SServer(params.RegionEntity.Server, params.RegionEntity.Certificate)
+	_, kubeClient, err := c.kubeClientFactory.GetByK8SServer(params.RegionEntity.Server,
+		string(params.RegionEntity.Certificate))
 	if err != nil {
 		return nil, err
 	}
@@ -360,34 +371,75 @@ func (c *cd) GetClusterState(ctx context.Context,
 		return nil, err
 	}
 
-	if argoApp.Status.Health.Status == health.HealthStatusHealthy {
-		isHealthy := true
-		c.traverseResourceTree(resourceTreeInArgo, func(node *ResourceTreeNode) bool {
-			if !isHealthy {
-				return false
-			}
-			workload.LoopAbilities(func(workload workload.Workload) bool {
-				if !workload.MatchGK(schema.GroupKind{Group: node.Group, Kind: node.Kind}) {
-					return true
-				}
-				gt := getter.New(workload)
-				nodeHealthy, err := gt.IsHealthy(node.ResourceNode, kubeClient)
-				if err != nil {
-					return true
-				}
-				log.Debugf(ctx, "[cd get status v2] node(%v) kind(%v) isHealthy(%v)", node.Name, node.Kind, nodeHealthy)
-				isHealthy = isHealthy && nodeHealthy
-				return isHealthy
-			})
-			// break if isHealthy is false
-			return isHealthy
-		})
+	if argoApp.Status.Health.Status == health.HealthStatusHealthy &&
+		!isTreeHealthy(ctx, resourceTreeInArgo, kubeClient) {
+		status.Status = string(health.HealthStatusProgressing)
+	}
+	return status, nil
+}
 
-		if !isHealthy {
-			status.Status = string(health.HealthStatusProgressing)
+// GetClusterDrift compares the target state of resources in argo with their live state
+func (c *cd) GetClusterDrift(ctx context.Context, params *GetClusterDriftParams) (*ClusterDrift, error) {
+	const op = "cd: get cluster drift"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	argo, err := c.factory.GetArgoCD(params.RegionEntity.Name, params.Environment)
+	if err != nil {
+		return nil, err
+	}
+
+	argoApp, err := argo.GetApplication(ctx, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	// the application is syncing or synced, nothing drifts
+	if argoApp.Operation != nil || argoApp.Status.Sync.Status == applicationV1alpha1.SyncStatusCodeSynced {
+		return &ClusterDrift{}, nil
+	}
+
+	resources, err := argo.GetManagedResources(ctx, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	drift := &ClusterDrift{}
+	for _, resource := range resources {
+		resourceDrift, err := resourceDiffDrift(resource)
+		if err != nil {
+			return nil, err
+		}
+		if resourceDrift != nil {
+			drift.Drifted = true
+			drift.Resources = append(drift.Resources, *resourceDrift)
 		}
 	}
-	return status, nil
+	return drift, nil
+}
+
+// isTreeHealthy returns false if any workload in the tree is not healthy
+func isTreeHealthy(ctx context.Context, resourceTree *applicationV1alpha1.ApplicationTree,
+	kubeClient *kube.Client) bool {
+	isHealthy := true
+	traverseResourceTree(resourceTree, func(node *ResourceTreeNode) bool {
+		if !isHealthy {
+			return false
+		}
+		workload.LoopAbilities(func(workload workload.Workload) bool {
+			if !workload.MatchGK(schema.GroupKind{Group: node.Group, Kind: node.Kind}) {
+				return true
+			}
+			gt := getter.New(workload)
+			nodeHealthy, err := gt.IsHealthy(node.ResourceNode, kubeClient)
+			if err != nil {
+				return true
+			}
+			log.Debugf(ctx, "[cd get status v2] node(%v) kind(%v) isHealthy(%v)", node.Name, node.Kind, nodeHealthy)
+			isHealthy = isHealthy && nodeHealthy
+			return isHealthy
+		})
+		// break if isHealthy is false
+		return isHealthy
+	})
+	return isHealthy
 }
 
 // Deprecated: using GetClusterState instead
@@ -401,7 +453,8 @@ func (c *cd) GetClusterStateV1(ctx context.Context,
 		return nil, err
 	}
 
-	_, kubeClient, err := c.kubeClientFactory.GetByK8SServer(params.RegionEntity.Server, params.RegionEntity.Certificate)
+	_, kubeClient, err := c.kubeClientFactory.GetByK8SServer(params.RegionEntity.Server,
+		string(params.RegionEntity.Certificate))
 	if err != nil {
 		return nil, err
 	}
@@ -566,7 +619,8 @@ func (c *cd) GetPodEvents(ctx context.Context,
 	const op = "cd: get cluster pod events"
 	defer wlog.Start(ctx, op).StopPrint()
 
-	_, kubeClient, err := c.kubeClientFactory.GetByK8SServer(params.RegionEntity.Server, params.RegionEntity.Certificate)
+	_, kubeClient, err := c.kubeClientFactory.GetByK8SServer(params.RegionEntity.Server,
+		string(params.RegionEntity.Certificate))
 	if err != nil {
 		return nil, err
 	}
@@ -580,10 +634,16 @@ func (c *cd) GetPodEvents(ctx context.Context,
 		return nil, err
 	}
 
+	return getPodEventsInTree(ctx, kubeClient, resourceTree, params.Namespace, params.Pod)
+}
+
+// getPodEventsInTree returns events of the pod if the pod is in the resource tree
+func getPodEventsInTree(ctx context.Context, kubeClient *kube.Client, resourceTree []ResourceNode,
+	namespace, podName string) (events []Event, err error) {
 	for i := range resourceTree {
 		pod := resourceTree[i].PodDetail
-		if pod != nil && pod.Metadata.Namespace == params.Namespace && pod.Metadata.Name == params.Pod {
-			k8sEvents, err := kube.GetPodEvents(ctx, kubeClient.Basic, params.Namespace, params.Pod)
+		if pod != nil && pod.Metadata.Namespace == namespace && pod.Metadata.Name == podName {
+			k8sEvents, err := kube.GetPodEvents(ctx, kubeClient.Basic, namespace, podName)
 			if err != nil {
 				return nil, err
 			}
@@ -0,0 +1,254 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	applicationV1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+	"sigs.k8s.io/yaml"
+)
+
+// resourceDiffDrift converts the diff of resource in argo to drift, returns nil if the resource is not drifted.
+// Hooks and live objects absent in git are ignored.
+func resourceDiffDrift(resource *applicationV1alpha1.ResourceDiff) (*ResourceDrift, error) {
+	if resource.Hook || isEmptyState(resource.TargetState) {
+		return nil, nil
+	}
+	resourceDrift := &ResourceDrift{
+		Group:     resource.Group,
+		Kind:      resource.Kind,
+		Namespace: resource.Namespace,
+		Name:      resource.Name,
+	}
+	if isEmptyState(resource.LiveState) {
+		resourceDrift.Missing = true
+		return resourceDrift, nil
+	}
+
+	// predicted state is the live state with target state applied, so they can be compared entirely
+	desiredState, liveState, subset := resource.PredictedLiveState, resource.NormalizedLiveState, false
+	if isEmptyState(desiredState) || isEmptyState(liveState) {
+		desiredState, liveState, subset = resource.TargetState, resource.LiveState, true
+	}
+	var desired, live interface{}
+	if err := json.Unmarshal([]byte(desiredState), &desired); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to parse state of %s: %v", resource.FullName(), err)
+	}
+	if err := json.Unmarshal([]byte(liveState), &live); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to parse state of %s: %v", resource.FullName(), err)
+	}
+	resourceDrift.Fields = diffFields("", desired, live, subset)
+	if len(resourceDrift.Fields) == 0 {
+		return nil, nil
+	}
+	redact(resourceDrift)
+	return resourceDrift, nil
+}
+
+// redact removes values of secret fields, only the paths are reported. All fields of secrets are redacted
+// because data may also be found elsewhere, like in the last-applied-configuration annotation
+func redact(resourceDrift *ResourceDrift) {
+	if resourceDrift.Group != "" || resourceDrift.Kind != "Secret" {
+		return
+	}
+	for i := range resourceDrift.Fields {
+		resourceDrift.Fields[i] = FieldDrift{Path: resourceDrift.Fields[i].Path, Redacted: true}
+	}
+}
+
+func isEmptyState(state string) bool {
+	return state == "" || state == "null"
+}
+
+// releaseDrift compares the manifest of the deployed release with live objects, releases in progress
+// are regarded as not drifted
+func releaseDrift(ctx context.Context, cfg *action.Configuration, kubeClient *kube.Client,
+	namespace, name string) (*ClusterDrift, error) {
+	rel, err := action.NewGet(cfg).Run(name)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrHelmActionFailed, "failed to get release %s: %v", name, err)
+	}
+	if rel.Info == nil || rel.Info.Status != release.StatusDeployed {
+		return &ClusterDrift{}, nil
+	}
+	return manifestDrift(ctx, kubeClient, namespace, rel.Manifest)
+}
+
+// manifestDrift compares objects in the manifest with live objects, only fields in the manifest are compared,
+// because live objects have fields defaulted by kubernetes
+func manifestDrift(ctx context.Context, kubeClient *kube.Client,
+	namespace, manifest string) (*ClusterDrift, error) {
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(kubeClient.Basic.Discovery()))
+
+	manifests := releaseutil.SplitManifests(manifest)
+	keys := make([]string, 0, len(manifests))
+	for key := range manifests {
+		keys = append(keys, key)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	drift := &ClusterDrift{}
+	for _, key := range keys {
+		var content map[string]interface{}
+		if err := yaml.Unmarshal([]byte(manifests[key]), &content); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to parse manifest: %v", err)
+		}
+		if len(content) == 0 {
+			continue
+		}
+		desired := &unstructured.Unstructured{Object: content}
+		gvk := desired.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrKubeDynamicCliResponseNotOK,
+				"failed to get mapping of %s: %v", gvk, err)
+		}
+
+		resourceDrift := ResourceDrift{
+			Group: gvk.Group,
+			Kind:  gvk.Kind,
+			Name:  desired.GetName(),
+		}
+		resourceClient := kubeClient.Dynamic.Resource(mapping.Resource)
+		var live *unstructured.Unstructured
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			resourceDrift.Namespace = desired.GetNamespace()
+			if resourceDrift.Namespace == "" {
+				resourceDrift.Namespace = namespace
+			}
+			live, err = resourceClient.Namespace(resourceDrift.Namespace).
+				Get(ctx, resourceDrift.Name, metav1.GetOptions{})
+		} else {
+			live, err = resourceClient.Get(ctx, resourceDrift.Name, metav1.GetOptions{})
+		}
+		if err != nil {
+			if !k8serrors.IsNotFound(err) {
+				return nil, perror.Wrapf(herrors.ErrKubeDynamicCliResponseNotOK,
+					"failed to get %s %s: %v", mapping.Resource.Resource, resourceDrift.Name, err)
+			}
+			resourceDrift.Missing = true
+		} else {
+			resourceDrift.Fields = diffFields("", normalize(desired.Object), normalize(live.Object), true)
+		}
+		if resourceDrift.Missing || len(resourceDrift.Fields) > 0 {
+			redact(&resourceDrift)
+			drift.Drifted = true
+			drift.Resources = append(drift.Resources, resourceDrift)
+		}
+	}
+	return drift, nil
+}
+
+// normalize converts the value to its json form, so that numbers are compared in the same type
+func normalize(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+// diffFields compares desired and live values recursively, fields absent in desired are ignored if subset is true
+func diffFields(path string, desired, live interface{}, subset bool) []FieldDrift {
+	switch desiredValue := desired.(type) {
+	case map[string]interface{}:
+		liveValue, ok := live.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(desiredValue))
+		for key := range desiredValue {
+			keys = append(keys, key)
+		}
+		if !subset {
+			for key := range liveValue {
+				if _, ok := desiredValue[key]; !ok {
+					keys = append(keys, key)
+				}
+			}
+		}
+		sort.Strings(keys)
+
+		var drifts []FieldDrift
+		for _, key := range keys {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			drifts = append(drifts, diffFields(fieldPath, desiredValue[key], liveValue[key], subset)...)
+		}
+		return drifts
+	case []interface{}:
+		liveValue, ok := live.([]interface{})
+		if !ok || len(liveValue) != len(desiredValue) {
+			break
+		}
+		var drifts []FieldDrift
+		for i := range desiredValue {
+			drifts = append(drifts,
+				diffFields(fmt.Sprintf("%s[%d]", path, i), desiredValue[i], liveValue[i], subset)...)
+		}
+		return drifts
+	default:
+		if reflect.DeepEqual(desired, live) || equalQuantity(desired, live) {
+			return nil
+		}
+	}
+	return []FieldDrift{{Path: path, Desired: desired, Live: live}}
+}
+
+// equalQuantity returns true if both values are the same quantity, like 1000m and 1,
+// quantities of live objects are in canonical form
+func equalQuantity(desired, live interface{}) bool {
+	desiredValue, ok := desired.(string)
+	if !ok {
+		return false
+	}
+	liveValue, ok := live.(string)
+	if !ok {
+		return false
+	}
+	desiredQuantity, err := resource.ParseQuantity(desiredValue)
+	if err != nil {
+		return false
+	}
+	liveQuantity, err := resource.ParseQuantity(liveValue)
+	if err != nil {
+		return false
+	}
+	return desiredQuantity.Cmp(liveQuantity) == 0
+}
@@ -0,0 +1,192 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+	"testing"
+
+	applicationV1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/horizoncd/horizon/pkg/util/kube"
+)
+
+func TestDiffFields(t *testing.T) {
+	desired := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": float64(2),
+			"containers": []interface{}{
+				map[string]interface{}{"image": "app:v2", "cpu": "1000m"},
+			},
+		},
+	}
+	live := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": float64(1),
+			"containers": []interface{}{
+				map[string]interface{}{"image": "app:v2", "cpu": "1", "imagePullPolicy": "Always"},
+			},
+		},
+		"status": map[string]interface{}{"replicas": float64(1)},
+	}
+
+	fields := diffFields("", desired, live, true)
+	assert.Equal(t, []FieldDrift{
+		{Path: "spec.replicas", Desired: float64(2), Live: float64(1)},
+	}, fields)
+
+	fields = diffFields("", desired, live, false)
+	assert.Equal(t, []FieldDrift{
+		{Path: "spec.containers[0].imagePullPolicy", Live: "Always"},
+		{Path: "spec.replicas", Desired: float64(2), Live: float64(1)},
+		{Path: "status", Live: map[string]interface{}{"replicas": float64(1)}},
+	}, fields)
+
+	fields = diffFields("args", []interface{}{"a"}, []interface{}{"a", "b"}, true)
+	assert.Equal(t, []FieldDrift{
+		{Path: "args", Desired: []interface{}{"a"}, Live: []interface{}{"a", "b"}},
+	}, fields)
+}
+
+func TestResourceDiffDrift(t *testing.T) {
+	drift, err := resourceDiffDrift(&applicationV1alpha1.ResourceDiff{
+		Kind:      "Service",
+		Name:      "app",
+		LiveState: `{"spec":{}}`,
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, drift)
+
+	drift, err = resourceDiffDrift(&applicationV1alpha1.ResourceDiff{
+		Kind:        "Service",
+		Name:        "app",
+		TargetState: `{"spec":{}}`,
+		LiveState:   "null",
+	})
+	assert.Nil(t, err)
+	assert.True(t, drift.Missing)
+
+	drift, err = resourceDiffDrift(&applicationV1alpha1.ResourceDiff{
+		Group:               "apps",
+		Kind:                "Deployment",
+		Name:                "app",
+		TargetState:         `{"spec":{"replicas":2}}`,
+		LiveState:           `{"spec":{"replicas":1},"status":{}}`,
+		PredictedLiveState:  `{"spec":{"replicas":2},"status":{}}`,
+		NormalizedLiveState: `{"spec":{"replicas":1},"status":{}}`,
+	})
+	assert.Nil(t, err)
+	assert.False(t, drift.Missing)
+	assert.Equal(t, []FieldDrift{
+		{Path: "spec.replicas", Desired: float64(2), Live: float64(1)},
+	}, drift.Fields)
+
+	drift, err = resourceDiffDrift(&applicationV1alpha1.ResourceDiff{
+		Group:       "apps",
+		Kind:        "Deployment",
+		Name:        "app",
+		TargetState: `{"spec":{"replicas":1}}`,
+		LiveState:   `{"spec":{"replicas":1},"status":{}}`,
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, drift)
+
+	// values of secrets are not reported
+	drift, err = resourceDiffDrift(&applicationV1alpha1.ResourceDiff{
+		Kind:                "Secret",
+		Name:                "app",
+		TargetState:         `{"data":{"password":"b2xk"}}`,
+		LiveState:           `{"data":{"password":"bmV3"}}`,
+		PredictedLiveState:  `{"data":{"password":"b2xk"}}`,
+		NormalizedLiveState: `{"data":{"password":"bmV3"}}`,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []FieldDrift{
+		{Path: "data.password", Redacted: true},
+	}, drift.Fields)
+}
+
+func TestManifestDrift(t *testing.T) {
+	basic := fake.NewSimpleClientset()
+	basic.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Namespaced: true, Kind: "Deployment"},
+			},
+		},
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "services", Namespaced: true, Kind: "Service"},
+			},
+		},
+	}
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "app",
+			"namespace": "test",
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(1),
+		},
+	}}
+	dynamic := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), deployment)
+	kubeClient := &kube.Client{Basic: basic, Dynamic: dynamic}
+
+	manifest := `---
+# Source: app/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 2
+---
+# Source: app/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+`
+	drift, err := manifestDrift(context.TODO(), kubeClient, "test", manifest)
+	assert.Nil(t, err)
+	assert.True(t, drift.Drifted)
+	assert.Equal(t, []ResourceDrift{
+		{
+			Group:     "apps",
+			Kind:      "Deployment",
+			Namespace: "test",
+			Name:      "app",
+			Fields: []FieldDrift{
+				{Path: "spec.replicas", Desired: float64(2), Live: float64(1)},
+			},
+		},
+		{
+			Kind:      "Service",
+			Namespace: "test",
+			Name:      "app",
+			Missing:   true,
+		},
+	}, drift.Resources)
+}
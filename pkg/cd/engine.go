@@ -0,0 +1,165 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+	"reflect"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	argocdconf "github.com/horizoncd/horizon/pkg/config/argocd"
+	fluxconf "github.com/horizoncd/horizon/pkg/config/flux"
+	helmconf "github.com/horizoncd/horizon/pkg/config/helm"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/regioninformers"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+)
+
+// RegionGetter gets region entity by region name
+type RegionGetter interface {
+	GetRegionEntity(ctx context.Context, regionName string) (*regionmodels.RegionEntity, error)
+}
+
+// engineCD dispatches requests to the cd engine configured for the region of a cluster
+type engineCD struct {
+	regionGetter RegionGetter
+	engines      map[string]CD
+}
+
+func NewCD(informerFactories *regioninformers.RegionInformers, clusterGitRepo gitrepo.ClusterGitRepo,
+	regionGetter RegionGetter, argoCDMapper argocdconf.Mapper, regionArgoCDMapper argocdconf.RegionMapper,
+	fluxConfig fluxconf.Config, templateRepo templaterepo.TemplateRepo, helmConfig helmconf.Config,
+	targetRevision string) CD {
+	return &engineCD{
+		regionGetter: regionGetter,
+		engines: map[string]CD{
+			regionmodels.CDEngineArgoCD: newArgoCD(informerFactories, clusterGitRepo,
+				argoCDMapper, regionArgoCDMapper, targetRevision),
+			regionmodels.CDEngineFlux: newFluxCD(clusterGitRepo, fluxConfig, targetRevision),
+			regionmodels.CDEngineHelm: newHelmCD(informerFactories, clusterGitRepo, templateRepo, helmConfig),
+		},
+	}
+}
+
+func (e *engineCD) engine(regionEntity *regionmodels.RegionEntity) (CD, error) {
+	name := regionmodels.CDEngineArgoCD
+	if regionEntity != nil && regionEntity.Region != nil && regionEntity.CDEngine != "" {
+		name = regionEntity.CDEngine
+	}
+	engine, ok := e.engines[name]
+	if !ok {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported cd engine: %s", name)
+	}
+	return engine, nil
+}
+
+func (e *engineCD) regionEntity(ctx context.Context, regionEntity *regionmodels.RegionEntity,
+	regionName string) (*regionmodels.RegionEntity, error) {
+	if regionEntity != nil {
+		return regionEntity, nil
+	}
+	return e.regionGetter.GetRegionEntity(ctx, regionName)
+}
+
+func (e *engineCD) CreateCluster(ctx context.Context, params *CreateClusterParams) error {
+	engine, err := e.engine(params.RegionEntity)
+	if err != nil {
+		return err
+	}
+	return engine.CreateCluster(ctx, params)
+}
+
+func (e *engineCD) DeployCluster(ctx context.Context, params *DeployClusterParams) error {
+	regionEntity, err := e.regionEntity(ctx, params.RegionEntity, params.Region)
+	if err != nil {
+		return err
+	}
+	engine, err := e.engine(regionEntity)
+	if err != nil {
+		return err
+	}
+	params.RegionEntity = regionEntity
+	return engine.DeployCluster(ctx, params)
+}
+
+func (e *engineCD) DeleteCluster(ctx context.Context, params *DeleteClusterParams) error {
+	regionEntity, err := e.regionEntity(ctx, params.RegionEntity, params.Region)
+	if err != nil {
+		return err
+	}
+	engine, err := e.engine(regionEntity)
+	if err != nil {
+		return err
+	}
+	params.RegionEntity = regionEntity
+	return engine.DeleteCluster(ctx, params)
+}
+
+func (e *engineCD) GetClusterState(ctx context.Context,
+	params *GetClusterStateV2Params) (*ClusterStateV2, error) {
+	engine, err := e.engine(params.RegionEntity)
+	if err != nil {
+		return nil, err
+	}
+	return engine.GetClusterState(ctx, params)
+}
+
+func (e *engineCD) GetResourceTree(ctx context.Context, params *GetResourceTreeParams) ([]ResourceNode, error) {
+	engine, err := e.engine(params.RegionEntity)
+	if err != nil {
+		return nil, err
+	}
+	return engine.GetResourceTree(ctx, params)
+}
+
+func (e *engineCD) GetStep(ctx context.Context, params *GetStepParams) (*Step, error) {
+	engine, err := e.engine(params.RegionEntity)
+	if err != nil {
+		return nil, err
+	}
+	return engine.GetStep(ctx, params)
+}
+
+func (e *engineCD) GetClusterDrift(ctx context.Context, params *GetClusterDriftParams) (*ClusterDrift, error) {
+	engine, err := e.engine(params.RegionEntity)
+	if err != nil {
+		return nil, err
+	}
+	return engine.GetClusterDrift(ctx, params)
+}
+
+func (e *engineCD) GetPodEvents(ctx context.Context, params *GetPodEventsParams) ([]Event, error) {
+	engine, err := e.engine(params.RegionEntity)
+	if err != nil {
+		return nil, err
+	}
+	return engine.GetPodEvents(ctx, params)
+}
+
+// GetClusterStateV1 is only supported by engines implementing LegacyCD
+func (e *engineCD) GetClusterStateV1(ctx context.Context, params *GetClusterStateParams) (*ClusterState, error) {
+	engine, err := e.engine(params.RegionEntity)
+	if err != nil {
+		return nil, err
+	}
+	legacyCD, ok := engine.(LegacyCD)
+	if !ok {
+		return nil, perror.Wrapf(herrors.ErrNotSupport, "cd %s does not support legacy cd", reflect.TypeOf(engine))
+	}
+	// nolint
+	return legacyCD.GetClusterStateV1(ctx, params)
+}
@@ -0,0 +1,612 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	applicationV1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/cluster/kubeclient"
+	fluxconf "github.com/horizoncd/horizon/pkg/config/flux"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	_fluxDefaultNamespace     = "flux-system"
+	_fluxDefaultInterval      = 5 * time.Minute
+	_fluxDefaultDeleteTimeout = 5 * time.Minute
+	_fluxDeletePollInterval   = 3 * time.Second
+
+	// _fluxReconcileAnnotation triggers reconciliation of flux objects immediately
+	_fluxReconcileAnnotation = "reconcile.fluxcd.io/requestedAt"
+	// _fluxChartRevisionLength is the length of commit appended to chart version by flux
+	// when reconcile strategy of chart is Revision
+	_fluxChartRevisionLength = 12
+)
+
+var (
+	gvrGitRepository = schema.GroupVersionResource{
+		Group:    "source.toolkit.fluxcd.io",
+		Version:  "v1",
+		Resource: "gitrepositories",
+	}
+	gvrHelmRelease = schema.GroupVersionResource{
+		Group:    "helm.toolkit.fluxcd.io",
+		Version:  "v2",
+		Resource: "helmreleases",
+	}
+
+	// treeResources are resources listed to build the resource tree of a cluster
+	treeResources = []schema.GroupVersionResource{
+		{Group: "apps", Version: "v1", Resource: "deployments"},
+		{Group: "apps", Version: "v1", Resource: "statefulsets"},
+		{Group: "apps", Version: "v1", Resource: "replicasets"},
+		{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"},
+		{Group: "serving.knative.dev", Version: "v1", Resource: "services"},
+		{Group: "", Version: "v1", Resource: "services"},
+		{Group: "", Version: "v1", Resource: "pods"},
+	}
+)
+
+// fluxCD is the cd engine backed by flux, clusters are deployed by
+// a GitRepository and a HelmRelease created in the region
+type fluxCD struct {
+	kubeClientFactory kubeclient.Factory
+	clusterGitRepo    gitrepo.ClusterGitRepo
+	config            fluxconf.Config
+	targetRevision    string
+}
+
+// newFluxCD returns the cd engine backed by flux
+func newFluxCD(clusterGitRepo gitrepo.ClusterGitRepo, config fluxconf.Config, targetRevision string) CD {
+	if config.Namespace == "" {
+		config.Namespace = _fluxDefaultNamespace
+	}
+	if config.Interval <= 0 {
+		config.Interval = _fluxDefaultInterval
+	}
+	if config.DeleteTimeout <= 0 {
+		config.DeleteTimeout = _fluxDefaultDeleteTimeout
+	}
+	return &fluxCD{
+		kubeClientFactory: kubeclient.Fty,
+		clusterGitRepo:    clusterGitRepo,
+		config:            config,
+		targetRevision:    targetRevision,
+	}
+}
+
+func (f *fluxCD) kubeClient(regionEntity *regionmodels.RegionEntity) (*kube.Client, error) {
+	_, kubeClient, err := f.kubeClientFactory.GetByK8SServer(regionEntity.Server,
+		string(regionEntity.Certificate))
+	return kubeClient, err
+}
+
+func (f *fluxCD) CreateCluster(ctx context.Context, params *CreateClusterParams) (err error) {
+	const op = "flux: create cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	kubeClient, err := f.kubeClient(params.RegionEntity)
+	if err != nil {
+		return err
+	}
+
+	objects := []struct {
+		gvr schema.GroupVersionResource
+		obj *unstructured.Unstructured
+	}{
+		{gvrGitRepository, f.assembleGitRepository(params.Cluster, params.GitRepoURL)},
+		{gvrHelmRelease, f.assembleHelmRelease(params.Cluster, params.Namespace, params.ValueFiles)},
+	}
+	// if object exists, skip it, else create it
+	for _, o := range objects {
+		_, err := kubeClient.Dynamic.Resource(o.gvr).Namespace(f.config.Namespace).
+			Create(ctx, o.obj, metav1.CreateOptions{})
+		if err != nil && !k8serrors.IsAlreadyExists(err) {
+			return perror.Wrapf(herrors.ErrKubeDynamicCliResponseNotOK,
+				"failed to create %s %s: %v", o.gvr.Resource, params.Cluster, err)
+		}
+	}
+	return nil
+}
+
+func (f *fluxCD) assembleGitRepository(cluster, gitRepoURL string) *unstructured.Unstructured {
+	spec := map[string]interface{}{
+		"url":      gitRepoURL,
+		"interval": f.config.Interval.String(),
+		"ref": map[string]interface{}{
+			"branch": f.targetRevision,
+		},
+	}
+	if f.config.GitSecretName != "" {
+		spec["secretRef"] = map[string]interface{}{
+			"name": f.config.GitSecretName,
+		}
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": gvrGitRepository.GroupVersion().String(),
+		"kind":       "GitRepository",
+		"metadata":   f.metadata(cluster),
+		"spec":       spec,
+	}}
+}
+
+func (f *fluxCD) assembleHelmRelease(cluster, namespace string, valueFiles []string) *unstructured.Unstructured {
+	files := make([]interface{}, 0, len(valueFiles))
+	for _, file := range valueFiles {
+		files = append(files, file)
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": gvrHelmRelease.GroupVersion().String(),
+		"kind":       "HelmRelease",
+		"metadata":   f.metadata(cluster),
+		"spec": map[string]interface{}{
+			"interval":         f.config.Interval.String(),
+			"releaseName":      cluster,
+			"targetNamespace":  namespace,
+			"storageNamespace": namespace,
+			"install": map[string]interface{}{
+				"createNamespace": true,
+			},
+			"chart": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"chart": ".",
+					"sourceRef": map[string]interface{}{
+						"kind": "GitRepository",
+						"name": cluster,
+					},
+					"valuesFiles":              files,
+					"ignoreMissingValuesFiles": true,
+					"reconcileStrategy":        "Revision",
+				},
+			},
+		},
+	}}
+}
+
+func (f *fluxCD) metadata(cluster string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":      cluster,
+		"namespace": f.config.Namespace,
+		"labels": map[string]interface{}{
+			common.ClusterClusterLabelKey: cluster,
+		},
+	}
+}
+
+func (f *fluxCD) DeployCluster(ctx context.Context, params *DeployClusterParams) (err error) {
+	const op = "flux: deploy cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	kubeClient, err := f.kubeClient(params.RegionEntity)
+	if err != nil {
+		return err
+	}
+
+	requestedAt := time.Now().Format(time.RFC3339Nano)
+	patches := []struct {
+		gvr  schema.GroupVersionResource
+		spec map[string]interface{}
+	}{
+		{gvrGitRepository, map[string]interface{}{
+			"ref": map[string]interface{}{
+				"commit": params.Revision,
+			},
+		}},
+		{gvrHelmRelease, nil},
+	}
+	for _, p := range patches {
+		patch := map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{
+					_fluxReconcileAnnotation: requestedAt,
+				},
+			},
+		}
+		if p.spec != nil {
+			patch["spec"] = p.spec
+		}
+		data, err := json.Marshal(patch)
+		if err != nil {
+			return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		_, err = kubeClient.Dynamic.Resource(p.gvr).Namespace(f.config.Namespace).
+			Patch(ctx, params.Cluster, types.MergePatchType, data, metav1.PatchOptions{})
+		if err != nil {
+			return f.wrapError(err, p.gvr, params.Cluster)
+		}
+	}
+	return nil
+}
+
+func (f *fluxCD) DeleteCluster(ctx context.Context, params *DeleteClusterParams) (err error) {
+	const op = "flux: delete cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	kubeClient, err := f.kubeClient(params.RegionEntity)
+	if err != nil {
+		return err
+	}
+
+	// 1. delete helm release, the release is uninstalled by flux before the object is removed
+	helmReleases := kubeClient.Dynamic.Resource(gvrHelmRelease).Namespace(f.config.Namespace)
+	err = helmReleases.Delete(ctx, params.Cluster, metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return f.wrapError(err, gvrHelmRelease, params.Cluster)
+	}
+
+	// 2. wait for helm release to delete completely
+	if err == nil {
+		err = wait.PollImmediate(_fluxDeletePollInterval, f.config.DeleteTimeout, func() (bool, error) {
+			_, err := helmReleases.Get(ctx, params.Cluster, metav1.GetOptions{})
+			if k8serrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		})
+		if err != nil {
+			return perror.Wrapf(herrors.ErrKubeDynamicCliResponseNotOK,
+				"failed to wait for helmrelease %s to be deleted: %v", params.Cluster, err)
+		}
+	}
+
+	// 3. delete git repository
+	err = kubeClient.Dynamic.Resource(gvrGitRepository).Namespace(f.config.Namespace).
+		Delete(ctx, params.Cluster, metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return f.wrapError(err, gvrGitRepository, params.Cluster)
+	}
+	return nil
+}
+
+func (f *fluxCD) wrapError(err error, gvr schema.GroupVersionResource, name string) error {
+	if k8serrors.IsNotFound(err) {
+		return herrors.NewErrNotFound(herrors.Flux,
+			fmt.Sprintf("%s %s not found in flux", gvr.Resource, name))
+	}
+	return perror.Wrapf(herrors.ErrKubeDynamicCliResponseNotOK,
+		"failed to operate %s %s: %v", gvr.Resource, name, err)
+}
+
+func (f *fluxCD) get(ctx context.Context, kubeClient *kube.Client,
+	gvr schema.GroupVersionResource, name string) (*unstructured.Unstructured, error) {
+	obj, err := kubeClient.Dynamic.Resource(gvr).Namespace(f.config.Namespace).
+		Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, f.wrapError(err, gvr, name)
+	}
+	return obj, nil
+}
+
+// GetClusterState fetches status of cluster by the Ready condition of HelmRelease
+func (f *fluxCD) GetClusterState(ctx context.Context,
+	params *GetClusterStateV2Params) (*ClusterStateV2, error) {
+	const op = "flux: get cluster status"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	kubeClient, err := f.kubeClient(params.RegionEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	helmRelease, err := kubeClient.Dynamic.Resource(gvrHelmRelease).Namespace(f.config.Namespace).
+		Get(ctx, params.Cluster, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, perror.Wrapf(
+				herrors.NewErrNotFound(herrors.ClusterStateInFlux, "cluster not found in flux"),
+				"failed to get cluster status from flux: helmrelease name = %v", params.Cluster)
+		}
+		return nil, f.wrapError(err, gvrHelmRelease, params.Cluster)
+	}
+
+	status := &ClusterStateV2{
+		Status: string(helmReleaseHealth(helmRelease)),
+	}
+	if status.Status != string(health.HealthStatusHealthy) {
+		return status, nil
+	}
+
+	lastConfigCommit, err := f.clusterGitRepo.GetConfigCommit(ctx, params.Application, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	gitRepository, err := f.get(ctx, kubeClient, gvrGitRepository, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	// revision of artifact is like main@sha1:<commit>, version of chart is like <version>+<commit[:12]>
+	sourceRevision, _, _ := unstructured.NestedString(gitRepository.Object, "status", "artifact", "revision")
+	chartRevision, _, _ := unstructured.NestedString(helmRelease.Object, "status", "lastAttemptedRevision")
+	commit := lastConfigCommit.Master
+	if len(commit) > _fluxChartRevisionLength {
+		commit = commit[:_fluxChartRevisionLength]
+	}
+	if !strings.HasSuffix(sourceRevision, lastConfigCommit.Master) ||
+		!strings.HasSuffix(chartRevision, "+"+commit) {
+		status.Status = string(health.HealthStatusProgressing)
+		log.Warningf(ctx,
+			"current revision(%s, %s) is not consistent with gitops repo commit(%s)",
+			sourceRevision, chartRevision, lastConfigCommit.Master)
+		return status, nil
+	}
+
+	resourceTree, err := f.resourceTree(ctx, kubeClient, helmRelease)
+	if err != nil {
+		return nil, err
+	}
+	if !isTreeHealthy(ctx, resourceTree, kubeClient) {
+		status.Status = string(health.HealthStatusProgressing)
+	}
+	return status, nil
+}
+
+// GetClusterDrift compares the manifest of release installed by HelmRelease with live objects
+func (f *fluxCD) GetClusterDrift(ctx context.Context, params *GetClusterDriftParams) (*ClusterDrift, error) {
+	const op = "flux: get cluster drift"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	restConfig, kubeClient, err := f.kubeClientFactory.GetByK8SServer(params.RegionEntity.Server,
+		string(params.RegionEntity.Certificate))
+	if err != nil {
+		return nil, err
+	}
+	helmRelease, err := f.get(ctx, kubeClient, gvrHelmRelease, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	// the release is reconciling, drift is judged after it's ready
+	if helmReleaseHealth(helmRelease) != health.HealthStatusHealthy {
+		return &ClusterDrift{}, nil
+	}
+
+	targetNamespace, _, _ := unstructured.NestedString(helmRelease.Object, "spec", "targetNamespace")
+	storageNamespace, _, _ := unstructured.NestedString(helmRelease.Object, "spec", "storageNamespace")
+	if storageNamespace == "" {
+		storageNamespace = helmRelease.GetNamespace()
+	}
+	if targetNamespace == "" {
+		targetNamespace = helmRelease.GetNamespace()
+	}
+	releaseName, _, _ := unstructured.NestedString(helmRelease.Object, "spec", "releaseName")
+	if releaseName == "" {
+		releaseName = params.Cluster
+	}
+
+	cfg, err := newHelmActionConfig(ctx, restConfig, storageNamespace)
+	if err != nil {
+		return nil, err
+	}
+	return releaseDrift(ctx, cfg, kubeClient, targetNamespace, releaseName)
+}
+
+// helmReleaseHealth converts the Ready condition of HelmRelease to health status
+func helmReleaseHealth(helmRelease *unstructured.Unstructured) health.HealthStatusCode {
+	conditions, _, _ := unstructured.NestedSlice(helmRelease.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		observedGeneration, _, _ := unstructured.NestedInt64(condition, "observedGeneration")
+		if observedGeneration < helmRelease.GetGeneration() {
+			return health.HealthStatusProgressing
+		}
+		switch condition["status"] {
+		case string(metav1.ConditionTrue):
+			return health.HealthStatusHealthy
+		case string(metav1.ConditionFalse):
+			// reasons ending with Failed like InstallFailed and UpgradeFailed mean remediation is required
+			if reason, _ := condition["reason"].(string); strings.HasSuffix(reason, "Failed") {
+				return health.HealthStatusDegraded
+			}
+		}
+		return health.HealthStatusProgressing
+	}
+	return health.HealthStatusProgressing
+}
+
+func (f *fluxCD) GetResourceTree(ctx context.Context,
+	params *GetResourceTreeParams) ([]ResourceNode, error) {
+	const op = "flux: get resource tree"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	kubeClient, err := f.kubeClient(params.RegionEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	helmRelease, err := f.get(ctx, kubeClient, gvrHelmRelease, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := f.listTreeObjects(ctx, kubeClient, helmRelease)
+	if err != nil {
+		return nil, err
+	}
+	return resourceNodes(ctx, objects), nil
+}
+
+// resourceNodes converts objects to resource nodes with pod details
+func resourceNodes(ctx context.Context, objects []unstructured.Unstructured) []ResourceNode {
+	resourceTree := make([]ResourceNode, 0, len(objects))
+	for i, node := range buildResourceTree(objects).Nodes {
+		n := ResourceNode{ResourceNode: node}
+		if n.Kind == "Pod" {
+			var pod corev1.Pod
+			err := runtime.DefaultUnstructuredConverter.FromUnstructured(objects[i].Object, &pod)
+			if err != nil {
+				log.Errorf(ctx, "failed to get pod detail: %v", err)
+				continue
+			}
+			t := Compact(pod)
+			n.PodDetail = &t
+		}
+		resourceTree = append(resourceTree, n)
+	}
+	return resourceTree
+}
+
+func (f *fluxCD) resourceTree(ctx context.Context, kubeClient *kube.Client,
+	helmRelease *unstructured.Unstructured) (*applicationV1alpha1.ApplicationTree, error) {
+	objects, err := f.listTreeObjects(ctx, kubeClient, helmRelease)
+	if err != nil {
+		return nil, err
+	}
+	return buildResourceTree(objects), nil
+}
+
+// listTreeObjects lists objects labeled with the cluster in the target namespace of HelmRelease
+func (f *fluxCD) listTreeObjects(ctx context.Context, kubeClient *kube.Client,
+	helmRelease *unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	namespace, _, _ := unstructured.NestedString(helmRelease.Object, "spec", "targetNamespace")
+	if namespace == "" {
+		namespace = helmRelease.GetNamespace()
+	}
+	labelSelector := fmt.Sprintf("%v=%v", common.ClusterClusterLabelKey, helmRelease.GetName())
+
+	objects := make([]unstructured.Unstructured, 0)
+	for _, gvr := range treeResources {
+		list, err := kubeClient.Dynamic.Resource(gvr).Namespace(namespace).
+			List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+		if err != nil {
+			// resources like rollouts are not served in every region
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return nil, perror.Wrapf(herrors.ErrKubeDynamicCliResponseNotOK,
+				"failed to list %s: %v", gvr.Resource, err)
+		}
+		objects = append(objects, list.Items...)
+	}
+	return objects, nil
+}
+
+// buildResourceTree converts objects to the tree in argo format, so that it can be traversed
+// by workload getters, the nodes are in the same order as objects
+func buildResourceTree(objects []unstructured.Unstructured) *applicationV1alpha1.ApplicationTree {
+	uids := make(map[types.UID]struct{}, len(objects))
+	for i := range objects {
+		uids[objects[i].GetUID()] = struct{}{}
+	}
+
+	tree := &applicationV1alpha1.ApplicationTree{
+		Nodes: make([]applicationV1alpha1.ResourceNode, 0, len(objects)),
+	}
+	for i := range objects {
+		obj := &objects[i]
+		gvk := obj.GroupVersionKind()
+		node := applicationV1alpha1.ResourceNode{
+			ResourceRef: applicationV1alpha1.ResourceRef{
+				Group:     gvk.Group,
+				Version:   gvk.Version,
+				Kind:      gvk.Kind,
+				Namespace: obj.GetNamespace(),
+				Name:      obj.GetName(),
+				UID:       string(obj.GetUID()),
+			},
+			ResourceVersion: obj.GetResourceVersion(),
+		}
+		createdAt := obj.GetCreationTimestamp()
+		node.CreatedAt = &createdAt
+		// only owners in the tree are kept, otherwise the tree can not be traversed
+		for _, owner := range obj.GetOwnerReferences() {
+			if _, ok := uids[owner.UID]; !ok {
+				continue
+			}
+			gv, _ := schema.ParseGroupVersion(owner.APIVersion)
+			node.ParentRefs = append(node.ParentRefs, applicationV1alpha1.ResourceRef{
+				Group:     gv.Group,
+				Version:   gv.Version,
+				Kind:      owner.Kind,
+				Namespace: obj.GetNamespace(),
+				Name:      owner.Name,
+				UID:       string(owner.UID),
+			})
+		}
+		if healthStatus, err := health.GetResourceHealth(obj, nil); err == nil && healthStatus != nil {
+			node.Health = &applicationV1alpha1.HealthStatus{
+				Status:  healthStatus.Status,
+				Message: healthStatus.Message,
+			}
+		}
+		tree.Nodes = append(tree.Nodes, node)
+	}
+	return tree
+}
+
+func (f *fluxCD) GetStep(ctx context.Context, params *GetStepParams) (*Step, error) {
+	const op = "flux: get step"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	kubeClient, err := f.kubeClient(params.RegionEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	helmRelease, err := f.get(ctx, kubeClient, gvrHelmRelease, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceTree, err := f.resourceTree(ctx, kubeClient, helmRelease)
+	if err != nil {
+		return nil, err
+	}
+	return getStepOfTree(resourceTree, kubeClient), nil
+}
+
+func (f *fluxCD) GetPodEvents(ctx context.Context,
+	params *GetPodEventsParams) ([]Event, error) {
+	const op = "flux: get cluster pod events"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	kubeClient, err := f.kubeClient(params.RegionEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceTree, err := f.GetResourceTree(ctx, &GetResourceTreeParams{
+		Environment:  params.Environment,
+		Cluster:      params.Cluster,
+		RegionEntity: params.RegionEntity,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return getPodEventsInTree(ctx, kubeClient, resourceTree, params.Namespace, params.Pod)
+}
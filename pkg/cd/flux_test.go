@@ -0,0 +1,287 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakeddynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	gitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	fluxconf "github.com/horizoncd/horizon/pkg/config/flux"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+)
+
+type fakeKubeClientFactory struct {
+	client *kube.Client
+}
+
+func (f *fakeKubeClientFactory) GetByK8SServer(string, string) (*rest.Config, *kube.Client, error) {
+	return nil, f.client, nil
+}
+
+func newFakeFluxCD(t *testing.T, clusterGitRepo gitrepo.ClusterGitRepo,
+	objects ...runtime.Object) (*fluxCD, *fakeddynamic.FakeDynamicClient) {
+	listKinds := map[schema.GroupVersionResource]string{
+		gvrGitRepository: "GitRepositoryList",
+		gvrHelmRelease:   "HelmReleaseList",
+	}
+	for _, gvr := range treeResources {
+		listKinds[gvr] = "UnstructuredList"
+	}
+	// the fake dynamic client only works with unstructured objects
+	for i, obj := range objects {
+		if _, ok := obj.(*unstructured.Unstructured); ok {
+			continue
+		}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		assert.Nil(t, err)
+		objects[i] = &unstructured.Unstructured{Object: content}
+	}
+	dynamicClient := fakeddynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		listKinds, objects...)
+
+	f := newFluxCD(clusterGitRepo, fluxconf.Config{GitSecretName: "gitops"}, "gitops").(*fluxCD)
+	f.kubeClientFactory = &fakeKubeClientFactory{client: &kube.Client{Dynamic: dynamicClient}}
+	return f, dynamicClient
+}
+
+func TestFluxCD(t *testing.T) {
+	ctx := context.Background()
+	regionEntity := &regionmodels.RegionEntity{Region: &regionmodels.Region{
+		Name:     "hz",
+		CDEngine: regionmodels.CDEngineFlux,
+	}}
+
+	mockCtl := gomock.NewController(t)
+	clusterGitRepo := gitrepomock.NewMockClusterGitRepo(mockCtl)
+	f, dynamicClient := newFakeFluxCD(t, clusterGitRepo)
+
+	createParams := &CreateClusterParams{
+		Environment:  "test",
+		Cluster:      "app-test",
+		GitRepoURL:   "ssh://git@gitlab.com/gitops/app-test.git",
+		ValueFiles:   []string{"application.yaml", "sre/sre.yaml"},
+		RegionEntity: regionEntity,
+		Namespace:    "test-app",
+	}
+	assert.Nil(t, f.CreateCluster(ctx, createParams))
+	// create again is ok
+	assert.Nil(t, f.CreateCluster(ctx, createParams))
+
+	gitRepository, err := dynamicClient.Resource(gvrGitRepository).Namespace(_fluxDefaultNamespace).
+		Get(ctx, "app-test", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "app-test", gitRepository.GetLabels()[common.ClusterClusterLabelKey])
+	url, _, _ := unstructured.NestedString(gitRepository.Object, "spec", "url")
+	assert.Equal(t, createParams.GitRepoURL, url)
+	branch, _, _ := unstructured.NestedString(gitRepository.Object, "spec", "ref", "branch")
+	assert.Equal(t, "gitops", branch)
+	secret, _, _ := unstructured.NestedString(gitRepository.Object, "spec", "secretRef", "name")
+	assert.Equal(t, "gitops", secret)
+
+	helmRelease, err := dynamicClient.Resource(gvrHelmRelease).Namespace(_fluxDefaultNamespace).
+		Get(ctx, "app-test", metav1.GetOptions{})
+	assert.Nil(t, err)
+	targetNamespace, _, _ := unstructured.NestedString(helmRelease.Object, "spec", "targetNamespace")
+	assert.Equal(t, "test-app", targetNamespace)
+	valuesFiles, _, _ := unstructured.NestedStringSlice(helmRelease.Object, "spec", "chart", "spec", "valuesFiles")
+	assert.Equal(t, createParams.ValueFiles, valuesFiles)
+
+	// deploy pins the revision
+	assert.Nil(t, f.DeployCluster(ctx, &DeployClusterParams{
+		Environment:  "test",
+		Cluster:      "app-test",
+		Revision:     "0123456789abcdef",
+		Region:       "hz",
+		RegionEntity: regionEntity,
+	}))
+	gitRepository, err = dynamicClient.Resource(gvrGitRepository).Namespace(_fluxDefaultNamespace).
+		Get(ctx, "app-test", metav1.GetOptions{})
+	assert.Nil(t, err)
+	commit, _, _ := unstructured.NestedString(gitRepository.Object, "spec", "ref", "commit")
+	assert.Equal(t, "0123456789abcdef", commit)
+	assert.NotEmpty(t, gitRepository.GetAnnotations()[_fluxReconcileAnnotation])
+
+	// cluster is healthy when helm release is ready with the latest commit
+	helmRelease, err = dynamicClient.Resource(gvrHelmRelease).Namespace(_fluxDefaultNamespace).
+		Get(ctx, "app-test", metav1.GetOptions{})
+	assert.Nil(t, err)
+	helmRelease.Object["status"] = map[string]interface{}{
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Ready", "status": "True", "observedGeneration": int64(0)},
+		},
+		"lastAttemptedRevision": "0.0.1+0123456789ab",
+	}
+	_, err = dynamicClient.Resource(gvrHelmRelease).Namespace(_fluxDefaultNamespace).
+		Update(ctx, helmRelease, metav1.UpdateOptions{})
+	assert.Nil(t, err)
+	gitRepository.Object["status"] = map[string]interface{}{
+		"artifact": map[string]interface{}{"revision": "gitops@sha1:0123456789abcdef"},
+	}
+	_, err = dynamicClient.Resource(gvrGitRepository).Namespace(_fluxDefaultNamespace).
+		Update(ctx, gitRepository, metav1.UpdateOptions{})
+	assert.Nil(t, err)
+
+	stateParams := &GetClusterStateV2Params{
+		Application:  "app",
+		Environment:  "test",
+		Cluster:      "app-test",
+		RegionEntity: regionEntity,
+	}
+	clusterGitRepo.EXPECT().GetConfigCommit(ctx, "app", "app-test").
+		Return(&gitrepo.ClusterCommit{Master: "0123456789abcdef", Gitops: "fedcba"}, nil)
+	state, err := f.GetClusterState(ctx, stateParams)
+	assert.Nil(t, err)
+	assert.Equal(t, string(health.HealthStatusHealthy), state.Status)
+
+	clusterGitRepo.EXPECT().GetConfigCommit(ctx, "app", "app-test").
+		Return(&gitrepo.ClusterCommit{Master: "abcdef0123456789", Gitops: "fedcba"}, nil)
+	state, err = f.GetClusterState(ctx, stateParams)
+	assert.Nil(t, err)
+	assert.Equal(t, string(health.HealthStatusProgressing), state.Status)
+
+	// delete
+	deleteParams := &DeleteClusterParams{
+		Environment:  "test",
+		Cluster:      "app-test",
+		Region:       "hz",
+		RegionEntity: regionEntity,
+	}
+	assert.Nil(t, f.DeleteCluster(ctx, deleteParams))
+	_, err = f.GetClusterState(ctx, stateParams)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	_, err = dynamicClient.Resource(gvrGitRepository).Namespace(_fluxDefaultNamespace).
+		Get(ctx, "app-test", metav1.GetOptions{})
+	assert.NotNil(t, err)
+	// delete again is ok
+	assert.Nil(t, f.DeleteCluster(ctx, deleteParams))
+}
+
+func TestFluxCDGetResourceTree(t *testing.T) {
+	ctx := context.Background()
+	regionEntity := &regionmodels.RegionEntity{Region: &regionmodels.Region{
+		Name:     "hz",
+		CDEngine: regionmodels.CDEngineFlux,
+	}}
+	labels := map[string]string{common.ClusterClusterLabelKey: "app-test"}
+	deployment := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "app-test", Namespace: "test-app", UID: "1",
+			Labels: labels},
+	}
+	replicaSet := &appsv1.ReplicaSet{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "ReplicaSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "app-test-5d4f8", Namespace: "test-app", UID: "2",
+			Labels: labels, OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "Deployment", Name: "app-test", UID: "1"},
+			}},
+	}
+	pod := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: "app-test-5d4f8-x2v7k", Namespace: "test-app", UID: "3",
+			Labels: labels, OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "app-test-5d4f8", UID: "2"},
+				// owners not in the tree are ignored
+				{APIVersion: "v1", Kind: "Node", Name: "node", UID: types.UID("node")},
+			}},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "app:v1"}}},
+	}
+	otherPod := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "test-app", UID: "4"},
+	}
+	helmRelease := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": gvrHelmRelease.GroupVersion().String(),
+		"kind":       "HelmRelease",
+		"metadata": map[string]interface{}{
+			"name":      "app-test",
+			"namespace": _fluxDefaultNamespace,
+		},
+		"spec": map[string]interface{}{
+			"targetNamespace": "test-app",
+		},
+	}}
+
+	f, _ := newFakeFluxCD(t, nil, helmRelease, deployment, replicaSet, pod, otherPod)
+	nodes, err := f.GetResourceTree(ctx, &GetResourceTreeParams{
+		Environment:  "test",
+		Cluster:      "app-test",
+		RegionEntity: regionEntity,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(nodes))
+
+	nodesByUID := make(map[string]ResourceNode, len(nodes))
+	for _, node := range nodes {
+		nodesByUID[node.UID] = node
+	}
+	assert.Equal(t, "Deployment", nodesByUID["1"].Kind)
+	assert.Nil(t, nodesByUID["1"].ParentRefs)
+	assert.Equal(t, "1", nodesByUID["2"].ParentRefs[0].UID)
+	assert.Equal(t, 1, len(nodesByUID["3"].ParentRefs))
+	assert.Equal(t, "2", nodesByUID["3"].ParentRefs[0].UID)
+	assert.NotNil(t, nodesByUID["3"].PodDetail)
+	assert.Equal(t, "app-test-5d4f8-x2v7k", nodesByUID["3"].PodDetail.Metadata.Name)
+
+	_, err = f.GetResourceTree(ctx, &GetResourceTreeParams{
+		Environment:  "test",
+		Cluster:      "app-other",
+		RegionEntity: regionEntity,
+	})
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
+
+func TestHelmReleaseHealth(t *testing.T) {
+	newHelmRelease := func(generation int64, conditions ...interface{}) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"status": map[string]interface{}{"conditions": conditions},
+		}}
+		obj.SetGeneration(generation)
+		return obj
+	}
+
+	assert.Equal(t, health.HealthStatusProgressing, helmReleaseHealth(newHelmRelease(1)))
+	assert.Equal(t, health.HealthStatusHealthy, helmReleaseHealth(newHelmRelease(1,
+		map[string]interface{}{"type": "Ready", "status": "True", "observedGeneration": int64(1)})))
+	// spec is changed but not reconciled yet
+	assert.Equal(t, health.HealthStatusProgressing, helmReleaseHealth(newHelmRelease(2,
+		map[string]interface{}{"type": "Ready", "status": "True", "observedGeneration": int64(1)})))
+	assert.Equal(t, health.HealthStatusDegraded, helmReleaseHealth(newHelmRelease(1,
+		map[string]interface{}{"type": "Ready", "status": "False", "reason": "UpgradeFailed",
+			"observedGeneration": int64(1)})))
+	assert.Equal(t, health.HealthStatusProgressing, helmReleaseHealth(newHelmRelease(1,
+		map[string]interface{}{"type": "Ready", "status": "Unknown", "reason": "Progressing",
+			"observedGeneration": int64(1)})))
+}
@@ -0,0 +1,542 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	applicationV1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/cluster/kubeclient"
+	helmconf "github.com/horizoncd/horizon/pkg/config/helm"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/regioninformers"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/util/kube"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/mergemap"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	_helmDefaultMaxHistory = 10
+	_helmDefaultTimeout    = 5 * time.Minute
+
+	// _helmDriver stores releases as secrets in the namespace of release
+	_helmDriver = "secret"
+	// _helmReleaseSelector selects the storage secrets of a release
+	_helmReleaseSelector = "owner=helm,name=%s"
+)
+
+// helmCD is the cd engine for regions without gitops controllers, the chart of cluster is
+// rendered from the template repo with values in the gitops repo, and installed by helm directly
+type helmCD struct {
+	kubeClientFactory kubeclient.Factory
+	informerFactories *regioninformers.RegionInformers
+	clusterGitRepo    gitrepo.ClusterGitRepo
+	templateRepo      templaterepo.TemplateRepo
+	config            helmconf.Config
+}
+
+// newHelmCD returns the cd engine backed by helm
+func newHelmCD(informerFactories *regioninformers.RegionInformers, clusterGitRepo gitrepo.ClusterGitRepo,
+	templateRepo templaterepo.TemplateRepo, config helmconf.Config) CD {
+	if config.MaxHistory <= 0 {
+		config.MaxHistory = _helmDefaultMaxHistory
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = _helmDefaultTimeout
+	}
+	return &helmCD{
+		kubeClientFactory: kubeclient.Fty,
+		informerFactories: informerFactories,
+		clusterGitRepo:    clusterGitRepo,
+		templateRepo:      templateRepo,
+		config:            config,
+	}
+}
+
+func (h *helmCD) kubeClient(regionEntity *regionmodels.RegionEntity) (*kube.Client, error) {
+	_, kubeClient, err := h.kubeClientFactory.GetByK8SServer(regionEntity.Server,
+		string(regionEntity.Certificate))
+	return kubeClient, err
+}
+
+func (h *helmCD) actionConfig(ctx context.Context, regionEntity *regionmodels.RegionEntity,
+	namespace string) (*action.Configuration, error) {
+	restConfig, _, err := h.kubeClientFactory.GetByK8SServer(regionEntity.Server,
+		string(regionEntity.Certificate))
+	if err != nil {
+		return nil, err
+	}
+	return newHelmActionConfig(ctx, restConfig, namespace)
+}
+
+// newHelmActionConfig returns the helm action config operating releases stored in the namespace
+func newHelmActionConfig(ctx context.Context, restConfig *rest.Config,
+	namespace string) (*action.Configuration, error) {
+	cfg := new(action.Configuration)
+	getter := &restClientGetter{restConfig: restConfig, namespace: namespace}
+	if err := cfg.Init(getter, namespace, _helmDriver, func(format string, v ...interface{}) {
+		log.Debugf(ctx, format, v...)
+	}); err != nil {
+		return nil, perror.Wrapf(herrors.ErrHelmActionFailed, "failed to init helm: %v", err)
+	}
+	return cfg, nil
+}
+
+// CreateCluster does nothing, the release is installed when the cluster is deployed for the first time
+func (h *helmCD) CreateCluster(ctx context.Context, params *CreateClusterParams) error {
+	return nil
+}
+
+func (h *helmCD) DeployCluster(ctx context.Context, params *DeployClusterParams) (err error) {
+	const op = "helm: deploy cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// 1. render chart with values at the revision
+	chartFiles, err := h.clusterGitRepo.GetChartFiles(ctx, params.Application, params.Cluster, params.Revision)
+	if err != nil {
+		return err
+	}
+	if len(chartFiles.Chart.Dependencies) == 0 {
+		return perror.Wrapf(herrors.ErrParamInvalid, "no template found in chart of cluster %s", params.Cluster)
+	}
+	dependency := chartFiles.Chart.Dependencies[0]
+	template, err := h.templateRepo.GetChart(dependency.Name, dependency.Version, time.Time{})
+	if err != nil {
+		return err
+	}
+	chrt := assembleReleaseChart(chartFiles.Chart, template, params.Revision)
+	values, err := mergeValueFiles(chartFiles.ValueFiles)
+	if err != nil {
+		return err
+	}
+	namespace, err := releaseNamespace(values, dependency.Name)
+	if err != nil {
+		return err
+	}
+
+	// 2. install release if it doesn't exist, else upgrade it
+	cfg, err := h.actionConfig(ctx, params.RegionEntity, namespace)
+	if err != nil {
+		return err
+	}
+	history := action.NewHistory(cfg)
+	history.Max = 1
+	_, err = history.Run(params.Cluster)
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		install := action.NewInstall(cfg)
+		install.ReleaseName = params.Cluster
+		install.Namespace = namespace
+		install.CreateNamespace = true
+		install.Timeout = h.config.Timeout
+		if _, err := install.Run(chrt, values); err != nil {
+			return perror.Wrapf(herrors.ErrHelmActionFailed,
+				"failed to install release %s: %v", params.Cluster, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return perror.Wrapf(herrors.ErrHelmActionFailed,
+			"failed to get history of release %s: %v", params.Cluster, err)
+	}
+
+	upgrade := action.NewUpgrade(cfg)
+	upgrade.Namespace = namespace
+	upgrade.MaxHistory = h.config.MaxHistory
+	upgrade.Timeout = h.config.Timeout
+	if _, err := upgrade.Run(params.Cluster, chrt, values); err != nil {
+		return perror.Wrapf(herrors.ErrHelmActionFailed,
+			"failed to upgrade release %s: %v", params.Cluster, err)
+	}
+	return nil
+}
+
+// assembleReleaseChart assembles the chart of cluster with the template as its only dependency,
+// the revision of gitops repo is set as the app version to tell which commit is deployed
+func assembleReleaseChart(clusterChart *gitrepo.Chart, template *chart.Chart, revision string) *chart.Chart {
+	dependencies := make([]*chart.Dependency, 0, len(clusterChart.Dependencies))
+	for _, dependency := range clusterChart.Dependencies {
+		dependencies = append(dependencies, &chart.Dependency{
+			Name:       dependency.Name,
+			Version:    dependency.Version,
+			Repository: dependency.Repository,
+		})
+	}
+	chrt := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion:   chart.APIVersionV2,
+			Name:         clusterChart.Name,
+			Version:      clusterChart.Version,
+			AppVersion:   revision,
+			Dependencies: dependencies,
+		},
+	}
+	chrt.AddDependency(copyChart(template))
+	return chrt
+}
+
+// copyChart copies the parts of chart modified by helm actions, charts from the template repo are cached
+// and shared between deployments
+func copyChart(c *chart.Chart) *chart.Chart {
+	copied := *c
+	if c.Metadata != nil {
+		metadata := *c.Metadata
+		metadata.Dependencies = make([]*chart.Dependency, 0, len(c.Metadata.Dependencies))
+		for _, dependency := range c.Metadata.Dependencies {
+			d := *dependency
+			metadata.Dependencies = append(metadata.Dependencies, &d)
+		}
+		copied.Metadata = &metadata
+	}
+	copied.SetDependencies()
+	for _, dependency := range c.Dependencies() {
+		copied.AddDependency(copyChart(dependency))
+	}
+	return &copied
+}
+
+// mergeValueFiles merges value files in order, values in latter files override the former
+func mergeValueFiles(valueFiles []gitrepo.ChartValueFile) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	for _, valueFile := range valueFiles {
+		fileValues, err := chartutil.ReadValues(valueFile.Content)
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"failed to read values from %s: %v", valueFile.FileName, err)
+		}
+		values, err = mergemap.Merge(values, fileValues)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// releaseNamespace gets the namespace of cluster from the env values of template
+func releaseNamespace(values map[string]interface{}, template string) (string, error) {
+	path := fmt.Sprintf("%s.%s.namespace", template, common.GitopsEnvValueNamespace)
+	value, err := chartutil.Values(values).PathValue(path)
+	if err != nil {
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "failed to get namespace from values: %v", err)
+	}
+	namespace, ok := value.(string)
+	if !ok || namespace == "" {
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "namespace in values is invalid: %v", value)
+	}
+	return namespace, nil
+}
+
+// namespace finds the namespace of release by its storage secrets
+func (h *helmCD) namespace(ctx context.Context, kubeClient *kube.Client, cluster string) (string, error) {
+	secrets, err := kubeClient.Basic.CoreV1().Secrets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf(_helmReleaseSelector, cluster),
+	})
+	if err != nil {
+		return "", perror.Wrapf(herrors.ErrKubeDynamicCliResponseNotOK,
+			"failed to list secrets of release %s: %v", cluster, err)
+	}
+	if len(secrets.Items) == 0 {
+		return "", herrors.NewErrNotFound(herrors.Helm, fmt.Sprintf("release %s not found in helm", cluster))
+	}
+	return secrets.Items[0].Namespace, nil
+}
+
+func (h *helmCD) DeleteCluster(ctx context.Context, params *DeleteClusterParams) (err error) {
+	const op = "helm: delete cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	kubeClient, err := h.kubeClient(params.RegionEntity)
+	if err != nil {
+		return err
+	}
+	namespace, err := h.namespace(ctx, kubeClient, params.Cluster)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil
+		}
+		return err
+	}
+
+	cfg, err := h.actionConfig(ctx, params.RegionEntity, namespace)
+	if err != nil {
+		return err
+	}
+	uninstall := action.NewUninstall(cfg)
+	uninstall.Timeout = h.config.Timeout
+	if _, err := uninstall.Run(params.Cluster); err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return perror.Wrapf(herrors.ErrHelmActionFailed,
+			"failed to uninstall release %s: %v", params.Cluster, err)
+	}
+	return nil
+}
+
+// GetClusterState fetches status of cluster by the status of release and the health of its resources
+func (h *helmCD) GetClusterState(ctx context.Context,
+	params *GetClusterStateV2Params) (*ClusterStateV2, error) {
+	const op = "helm: get cluster status"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	kubeClient, err := h.kubeClient(params.RegionEntity)
+	if err != nil {
+		return nil, err
+	}
+	notFoundErr := perror.Wrapf(
+		herrors.NewErrNotFound(herrors.ClusterStateInHelm, "cluster not found in helm"),
+		"failed to get cluster status from helm: release name = %v", params.Cluster)
+	namespace, err := h.namespace(ctx, kubeClient, params.Cluster)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil, notFoundErr
+		}
+		return nil, err
+	}
+
+	cfg, err := h.actionConfig(ctx, params.RegionEntity, namespace)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := action.NewGet(cfg).Run(params.Cluster)
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			return nil, notFoundErr
+		}
+		return nil, perror.Wrapf(herrors.ErrHelmActionFailed,
+			"failed to get release %s: %v", params.Cluster, err)
+	}
+
+	status := &ClusterStateV2{
+		Status: string(releaseHealth(rel)),
+	}
+	if status.Status != string(health.HealthStatusHealthy) {
+		return status, nil
+	}
+
+	lastConfigCommit, err := h.clusterGitRepo.GetConfigCommit(ctx, params.Application, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	if rel.Chart == nil || rel.Chart.Metadata == nil || rel.Chart.Metadata.AppVersion != lastConfigCommit.Master {
+		status.Status = string(health.HealthStatusProgressing)
+		log.Warningf(ctx, "current revision of release %s is not consistent with gitops repo commit(%s)",
+			params.Cluster, lastConfigCommit.Master)
+		return status, nil
+	}
+
+	resourceTree, err := h.resourceTree(params.RegionEntity, namespace, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	if !isTreeHealthy(ctx, resourceTree, kubeClient) {
+		status.Status = string(health.HealthStatusProgressing)
+	}
+	return status, nil
+}
+
+// GetClusterDrift compares the manifest of the deployed release with live objects
+func (h *helmCD) GetClusterDrift(ctx context.Context, params *GetClusterDriftParams) (*ClusterDrift, error) {
+	const op = "helm: get cluster drift"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	kubeClient, err := h.kubeClient(params.RegionEntity)
+	if err != nil {
+		return nil, err
+	}
+	namespace, err := h.namespace(ctx, kubeClient, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := h.actionConfig(ctx, params.RegionEntity, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return releaseDrift(ctx, cfg, kubeClient, namespace, params.Cluster)
+}
+
+// releaseHealth converts the status of release to health status
+func releaseHealth(rel *release.Release) health.HealthStatusCode {
+	if rel.Info == nil {
+		return health.HealthStatusProgressing
+	}
+	switch rel.Info.Status {
+	case release.StatusDeployed:
+		return health.HealthStatusHealthy
+	case release.StatusFailed:
+		return health.HealthStatusDegraded
+	}
+	return health.HealthStatusProgressing
+}
+
+func (h *helmCD) GetResourceTree(ctx context.Context,
+	params *GetResourceTreeParams) ([]ResourceNode, error) {
+	const op = "helm: get resource tree"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	kubeClient, err := h.kubeClient(params.RegionEntity)
+	if err != nil {
+		return nil, err
+	}
+	namespace, err := h.namespace(ctx, kubeClient, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := h.listTreeObjects(params.RegionEntity, namespace, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	return resourceNodes(ctx, objects), nil
+}
+
+func (h *helmCD) resourceTree(regionEntity *regionmodels.RegionEntity,
+	namespace, cluster string) (*applicationV1alpha1.ApplicationTree, error) {
+	objects, err := h.listTreeObjects(regionEntity, namespace, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return buildResourceTree(objects), nil
+}
+
+// listTreeObjects lists objects labeled with the cluster in the namespace from the region informers
+func (h *helmCD) listTreeObjects(regionEntity *regionmodels.RegionEntity,
+	namespace, cluster string) ([]unstructured.Unstructured, error) {
+	selector := labels.SelectorFromSet(labels.Set{common.ClusterClusterLabelKey: cluster})
+
+	objects := make([]unstructured.Unstructured, 0)
+	for _, gvr := range treeResources {
+		err := h.informerFactories.GetDynamicInformer(regionEntity.ID, gvr,
+			func(informer informers.GenericInformer) error {
+				items, err := informer.Lister().ByNamespace(namespace).List(selector)
+				if err != nil {
+					return err
+				}
+				for _, item := range items {
+					if obj, ok := item.(*unstructured.Unstructured); ok {
+						objects = append(objects, *obj)
+					}
+				}
+				return nil
+			})
+		if err != nil {
+			// resources like rollouts are not served in every region
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				continue
+			}
+			return nil, err
+		}
+	}
+	return objects, nil
+}
+
+func (h *helmCD) GetStep(ctx context.Context, params *GetStepParams) (*Step, error) {
+	const op = "helm: get step"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	kubeClient, err := h.kubeClient(params.RegionEntity)
+	if err != nil {
+		return nil, err
+	}
+	namespace, err := h.namespace(ctx, kubeClient, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceTree, err := h.resourceTree(params.RegionEntity, namespace, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	return getStepOfTree(resourceTree, kubeClient), nil
+}
+
+func (h *helmCD) GetPodEvents(ctx context.Context,
+	params *GetPodEventsParams) ([]Event, error) {
+	const op = "helm: get cluster pod events"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	kubeClient, err := h.kubeClient(params.RegionEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceTree, err := h.GetResourceTree(ctx, &GetResourceTreeParams{
+		Environment:  params.Environment,
+		Cluster:      params.Cluster,
+		RegionEntity: params.RegionEntity,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return getPodEventsInTree(ctx, kubeClient, resourceTree, params.Namespace, params.Pod)
+}
+
+// restClientGetter provides helm with the rest config of region, resources without namespace
+// are created in the namespace of release
+type restClientGetter struct {
+	restConfig *rest.Config
+	namespace  string
+}
+
+func (g *restClientGetter) ToRESTConfig() (*rest.Config, error) {
+	return rest.CopyConfig(g.restConfig), nil
+}
+
+func (g *restClientGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(rest.CopyConfig(g.restConfig))
+	if err != nil {
+		return nil, err
+	}
+	return memory.NewMemCacheClient(discoveryClient), nil
+}
+
+func (g *restClientGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	discoveryClient, err := g.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(discoveryClient)
+	return restmapper.NewShortcutExpander(mapper, discoveryClient), nil
+}
+
+func (g *restClientGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	return clientcmd.NewDefaultClientConfig(*clientcmdapi.NewConfig(), &clientcmd.ConfigOverrides{
+		Context: clientcmdapi.Context{Namespace: g.namespace},
+	})
+}
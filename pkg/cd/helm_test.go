@@ -0,0 +1,127 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"testing"
+
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+)
+
+func TestAssembleReleaseChart(t *testing.T) {
+	template := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "javaapp",
+			Version:    "v1.0.0",
+			Dependencies: []*chart.Dependency{
+				{Name: "sidecar", Version: "v1", Condition: "sidecar.enabled"},
+			},
+		},
+		Values: map[string]interface{}{"replicas": 1},
+	}
+	template.AddDependency(&chart.Chart{Metadata: &chart.Metadata{Name: "sidecar", Version: "v1"}})
+
+	clusterChart := &gitrepo.Chart{
+		APIVersion: "v2",
+		Name:       "app-test",
+		Version:    "1.0.0",
+		Dependencies: []gitrepo.Dependency{
+			{Name: "javaapp", Version: "v1.0.0", Repository: "https://charts.com"},
+		},
+	}
+	chrt := assembleReleaseChart(clusterChart, template, "commit")
+	assert.Nil(t, chrt.Validate())
+	assert.Equal(t, "app-test", chrt.Name())
+	assert.Equal(t, "commit", chrt.AppVersion())
+	assert.Equal(t, 1, len(chrt.Metadata.Dependencies))
+	assert.Equal(t, 1, len(chrt.Dependencies()))
+
+	// the template is copied, so processing dependencies doesn't affect the cached one
+	assert.Equal(t, "javaapp", chrt.Dependencies()[0].Name())
+	assert.Equal(t, chrt, chrt.Dependencies()[0].Parent())
+	assert.Nil(t, template.Parent())
+	assert.Nil(t, chartutil.ProcessDependencies(chrt, map[string]interface{}{
+		"javaapp": map[string]interface{}{"sidecar": map[string]interface{}{"enabled": false}},
+	}))
+	sub := chrt.Dependencies()[0]
+	assert.Equal(t, 0, len(sub.Dependencies()))
+	assert.Equal(t, 0, len(sub.Metadata.Dependencies))
+	assert.Equal(t, 1, len(template.Dependencies()))
+	assert.Equal(t, 1, len(template.Metadata.Dependencies))
+}
+
+func TestMergeValueFiles(t *testing.T) {
+	values, err := mergeValueFiles([]gitrepo.ChartValueFile{
+		{
+			FileName: "application.yaml",
+			Content:  []byte("javaapp:\n  app:\n    replicas: 1\n    image: v1\n"),
+		},
+		{
+			FileName: "system/env.yaml",
+			Content:  []byte("javaapp:\n  env:\n    namespace: test-app\n"),
+		},
+		{
+			FileName: "sre/sre.yaml",
+			Content:  []byte("javaapp:\n  app:\n    replicas: 2\n"),
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"javaapp": map[string]interface{}{
+			"app": map[string]interface{}{
+				"replicas": float64(2),
+				"image":    "v1",
+			},
+			"env": map[string]interface{}{
+				"namespace": "test-app",
+			},
+		},
+	}, values)
+
+	namespace, err := releaseNamespace(values, "javaapp")
+	assert.Nil(t, err)
+	assert.Equal(t, "test-app", namespace)
+
+	_, err = releaseNamespace(values, "tomcat")
+	assert.NotNil(t, err)
+
+	_, err = mergeValueFiles([]gitrepo.ChartValueFile{
+		{FileName: "application.yaml", Content: []byte("javaapp: [")},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestReleaseHealth(t *testing.T) {
+	cases := []struct {
+		status release.Status
+		health health.HealthStatusCode
+	}{
+		{release.StatusDeployed, health.HealthStatusHealthy},
+		{release.StatusFailed, health.HealthStatusDegraded},
+		{release.StatusPendingUpgrade, health.HealthStatusProgressing},
+		{release.StatusSuperseded, health.HealthStatusProgressing},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.health, releaseHealth(&release.Release{Info: &release.Info{Status: c.status}}))
+	}
+	assert.Equal(t, health.HealthStatusProgressing, releaseHealth(&release.Release{}))
+}
@@ -92,12 +92,22 @@ func (e *util) DeletePods(ctx context.Context,
 func (e *util) GetContainerLog(ctx context.Context, params *GetContainerLogParams) (<-chan string, error) {
 	var logC = make(chan string)
 	err := e.informerFactories.GetClientSet(params.RegionEntity.ID, func(clientset kubernetes.Interface) error {
-		podLogRequest := clientset.CoreV1().Pods(params.Namespace).GetLogs(params.Pod, &corev1.PodLogOptions{
+		options := &corev1.PodLogOptions{
 			Container:  params.Container,
-			TailLines:  &params.TailLines,
 			Timestamps: true,
-		})
-		stream, err := podLogRequest.Stream(context.TODO())
+			Follow:     params.Follow,
+			Previous:   params.Previous,
+		}
+		if params.TailLines > 0 {
+			options.TailLines = &params.TailLines
+		}
+		if params.SinceTime != nil {
+			sinceTime := metav1.NewTime(*params.SinceTime)
+			options.SinceTime = &sinceTime
+		}
+		podLogRequest := clientset.CoreV1().Pods(params.Namespace).GetLogs(params.Pod, options)
+		// the stream is closed when ctx is done, which is necessary for following logs
+		stream, err := podLogRequest.Stream(ctx)
 		if err != nil {
 			return herrors.NewErrGetFailed(herrors.PodLogsInK8S, err.Error())
 		}
@@ -265,7 +275,7 @@ func (e *util) Exec(ctx context.Context, params *ExecParams) (resp map[string]Ex
 type TraverseOperator func(node *ResourceTreeNode) bool
 
 // traverseResourceTree traverses tree by dfs
-func (c *cd) traverseResourceTree(resourceTree *applicationV1alpha1.ApplicationTree,
+func traverseResourceTree(resourceTree *applicationV1alpha1.ApplicationTree,
 	operators ...TraverseOperator) {
 	m := make(map[string]*applicationV1alpha1.ResourceNode)
 	for i, node := range resourceTree.Nodes {
@@ -15,6 +15,8 @@
 package cd
 
 import (
+	"time"
+
 	applicationV1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
 	"github.com/argoproj/gitops-engine/pkg/health"
 	corev1 "k8s.io/api/core/v1"
@@ -49,6 +51,13 @@ type GetClusterStateV2Params struct {
 	RegionEntity *regionmodels.RegionEntity
 }
 
+type GetClusterDriftParams struct {
+	Application  string
+	Environment  string
+	Cluster      string
+	RegionEntity *regionmodels.RegionEntity
+}
+
 type CreateClusterParams struct {
 	Environment  string
 	Cluster      string
@@ -59,10 +68,13 @@ type CreateClusterParams struct {
 }
 
 type DeployClusterParams struct {
+	Application string
 	Environment string
 	Cluster     string
 	Revision    string
 	Region      string
+	// RegionEntity is resolved from Region if it's nil
+	RegionEntity *regionmodels.RegionEntity
 }
 
 type GetPodEventsParams struct {
@@ -90,6 +102,8 @@ type DeleteClusterParams struct {
 	Environment string
 	Cluster     string
 	Region      string
+	// RegionEntity is resolved from Region if it's nil
+	RegionEntity *regionmodels.RegionEntity
 }
 
 type ExecuteActionParams struct {
@@ -108,7 +122,14 @@ type GetContainerLogParams struct {
 	Pod          string
 	Container    string
 	Environment  string
-	TailLines    int64
+	// TailLines is the number of lines from the end of logs, all logs are returned if it's not positive
+	TailLines int64
+	// SinceTime only returns logs after the time if it's not nil
+	SinceTime *time.Time
+	// Follow streams logs until the context is done
+	Follow bool
+	// Previous returns logs of previous terminated container
+	Previous bool
 }
 
 type ExecParams struct {
@@ -161,6 +182,32 @@ type ClusterStateV2 struct {
 	Status string `json:"status"`
 }
 
+// ClusterDrift is the difference between the desired state in git and the live objects of a cluster
+type ClusterDrift struct {
+	Drifted   bool            `json:"drifted"`
+	Resources []ResourceDrift `json:"resources,omitempty"`
+}
+
+// ResourceDrift is a drifted object of cluster
+type ResourceDrift struct {
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Missing means the object is desired but doesn't exist in the region
+	Missing bool         `json:"missing,omitempty"`
+	Fields  []FieldDrift `json:"fields,omitempty"`
+}
+
+// FieldDrift is a field whose live value differs from the desired one, a nil value means the field is absent
+type FieldDrift struct {
+	Path    string      `json:"path"`
+	Desired interface{} `json:"desired,omitempty"`
+	Live    interface{} `json:"live,omitempty"`
+	// Redacted means the values are sensitive and not reported, like fields of secrets
+	Redacted bool `json:"redacted,omitempty"`
+}
+
 // ClusterState cluster state
 type ClusterState struct {
 	// Status:
@@ -16,7 +16,6 @@ package code
 
 import (
 	"context"
-	"regexp"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
 	gitconfig "github.com/horizoncd/horizon/pkg/config/git"
@@ -34,12 +33,16 @@ type GitGetter interface {
 	GetHTTPLink(gitURL string) (string, error)
 	GetCommitHistoryLink(gitURL string, commit string) (string, error)
 	GetTagArchive(ctx context.Context, gitURL, tagName string) (*git.Tag, error)
+	// CreateMergeRequestComment to comment on the merge request (pull request) of a specified git URL
+	CreateMergeRequestComment(ctx context.Context, gitURL string, mrID int, body string) error
+	// CreateCommitStatus to publish the status of a commit
+	CreateCommitStatus(ctx context.Context, gitURL string, status *git.CommitStatus) error
+	// CreateDeployment to publish a deployment record of a commit
+	CreateDeployment(ctx context.Context, gitURL string, deployment *git.Deployment) error
 }
 
 var _ GitGetter = (*gitGetter)(nil)
 
-var regexHost = regexp.MustCompile(`^(?:https?:)?(?://)?(?:[^@\n]+@)?([^:/\n]+)`)
-
 const (
 	githubHost = "github.com"
 	githubURL  = "https://github.com"
@@ -55,7 +58,7 @@ func NewGitGetter(ctx context.Context, repos []*gitconfig.Repo) (GitGetter, erro
 	// Check if GitHub has been configured, and add it if not.
 	githubConfigured := false
 	for _, repo := range repos {
-		host, err := extractHostFromURL(repo.URL)
+		host, err := git.ExtractHostFromURL(repo.URL)
 		if err != nil {
 			return nil, err
 		}
@@ -137,8 +140,32 @@ func (g *gitGetter) GetTagArchive(ctx context.Context, gitURL, tagName string) (
 	return helper.GetTagArchive(ctx, gitURL, tagName)
 }
 
+func (g *gitGetter) CreateMergeRequestComment(ctx context.Context, gitURL string, mrID int, body string) error {
+	helper, err := g.getGitHelper(gitURL)
+	if err != nil {
+		return err
+	}
+	return helper.CreateMergeRequestComment(ctx, gitURL, mrID, body)
+}
+
+func (g *gitGetter) CreateCommitStatus(ctx context.Context, gitURL string, status *git.CommitStatus) error {
+	helper, err := g.getGitHelper(gitURL)
+	if err != nil {
+		return err
+	}
+	return helper.CreateCommitStatus(ctx, gitURL, status)
+}
+
+func (g *gitGetter) CreateDeployment(ctx context.Context, gitURL string, deployment *git.Deployment) error {
+	helper, err := g.getGitHelper(gitURL)
+	if err != nil {
+		return err
+	}
+	return helper.CreateDeployment(ctx, gitURL, deployment)
+}
+
 func (g *gitGetter) getGitHelper(gitURL string) (git.Helper, error) {
-	host, err := extractHostFromURL(gitURL)
+	host, err := git.ExtractHostFromURL(gitURL)
 	if err != nil {
 		return nil, err
 	}
@@ -150,11 +177,3 @@ func (g *gitGetter) getGitHelper(gitURL string) (git.Helper, error) {
 	}
 	return h, nil
 }
-
-func extractHostFromURL(gitURL string) (string, error) {
-	matches := regexHost.FindStringSubmatch(gitURL)
-	if len(matches) != 2 {
-		return "", perror.Wrapf(herrors.ErrParamInvalid, "error to extract host from url: %v", gitURL)
-	}
-	return matches[1], nil
-}
@@ -46,6 +46,7 @@ type DAO interface {
 	List(ctx context.Context, query *q.Query, userID uint,
 		withRegion bool, appIDs ...uint) (int, []*models.ClusterWithRegion, error)
 	ListClusterWithExpiry(ctx context.Context, query *q.Query) ([]*models.Cluster, error)
+	ListRunningClusters(ctx context.Context, query *q.Query) ([]*models.Cluster, error)
 	GetByNameFuzzily(ctx context.Context, name string, includeSoftDelete bool) ([]*models.Cluster, error)
 }
 
@@ -270,14 +271,13 @@ func (d *dao) List(ctx context.Context, query *q.Query, userID uint,
 			case common.ParamApplicationID:
 				statement = statement.Where("c.application_id = ?", v)
 			case common.ClusterQueryName:
-				statement = statement.Where("c.name like ?", fmt.Sprintf("%%%v%%", v))
+				statement = statement.Where("lower(c.name) like lower(?)", fmt.Sprintf("%%%v%%", v))
 			case common.ClusterQueryByUser:
 				statement = statement.
 					Joins("join tb_member as m on m.resource_id = c.id").
 					Where("m.resource_type = ?", common.ResourceCluster).
-					Where("m.member_type = '0'").
-					Where("m.deleted_ts = 0").
-					Where("m.membername_id = ?", v)
+					Where(sqlcommon.MemberOfUser, v, v).
+					Where("m.deleted_ts = 0")
 			case common.ClusterQueryByTemplate:
 				statement = statement.Where("c.template = ?", v)
 			case common.ClusterQueryByRelease:
@@ -299,6 +299,12 @@ func (d *dao) List(ctx context.Context, query *q.Query, userID uint,
 			case common.ClusterQueryOnlyDeleted:
 				statement = statement.Where("c.deleted_ts > ?", 0)
 				onlyDeleted = true
+			case common.ClusterQueryHiddenApplications:
+				statement = statement.Where("(c.application_id not in ? or c.id in (?))", v,
+					d.db.Table("tb_member as m").Select("m.resource_id").
+						Where("m.resource_type = ?", common.ResourceCluster).
+						Where(sqlcommon.MemberOfUser, userID, userID).
+						Where("m.deleted_ts = 0"))
 			}
 		}
 		if !onlyDeleted {
@@ -397,10 +403,34 @@ func (d *dao) ListClusterWithExpiry(ctx context.Context,
 	return clusters, nil
 }
 
+func (d *dao) ListRunningClusters(ctx context.Context,
+	query *q.Query) ([]*models.Cluster, error) {
+	var clusters []*models.Cluster
+	tx := d.db.WithContext(ctx)
+	offset := (query.PageNumber - 1) * query.PageSize
+	limit := query.PageSize
+	if idThan, ok := query.Keywords[common.IDThan]; ok {
+		tx = tx.Where("id > ?", idThan)
+	}
+	if environment, ok := query.Keywords[common.ClusterQueryEnvironment]; ok {
+		if _, ok := environment.(string); ok {
+			tx = tx.Where("environment_name = ?", environment)
+		} else {
+			tx = tx.Where("environment_name in ?", environment)
+		}
+	}
+	result := tx.Where("deleted_ts = ?", 0).Where("status = ?", "").
+		Order("id asc").Limit(limit).Offset(offset).Find(&clusters)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.ClusterInDB, result.Error.Error())
+	}
+	return clusters, nil
+}
+
 func (d *dao) GetByNameFuzzily(ctx context.Context, name string, includeSoftDelete bool) ([]*models.Cluster, error) {
 	var clusters []*models.Cluster
 
-	statement := d.db.Unscoped().WithContext(ctx).Where("name like ?", fmt.Sprintf("%%%s%%", name))
+	statement := d.db.Unscoped().WithContext(ctx).Where("lower(name) like lower(?)", fmt.Sprintf("%%%s%%", name))
 	if !includeSoftDelete {
 		statement.Where("deleted_ts = 0")
 	}
@@ -89,6 +89,18 @@ type ClusterValueFile struct {
 	Content  map[interface{}]interface{}
 }
 
+// ChartFiles are files of the cluster chart at a commit
+type ChartFiles struct {
+	Chart *Chart
+	// ValueFiles are in the order of RepoInfo.ValueFiles, values in latter files override the former
+	ValueFiles []ChartValueFile
+}
+
+type ChartValueFile struct {
+	FileName string
+	Content  []byte
+}
+
 type ClusterCommit struct {
 	Master string
 	Gitops string
@@ -123,6 +135,9 @@ type ClusterGitRepo interface {
 		application, cluster string) ([]ClusterValueFile, error)
 	// GetClusterTemplate parses cluster's template name and release from GitopsFileChart
 	GetClusterTemplate(ctx context.Context, application, cluster string) (*ClusterTemplate, error)
+	// GetChartFiles reads the chart and value files of cluster at the commit, which are rendered to
+	// deploy the cluster without gitops engines, missing value files are skipped
+	GetChartFiles(ctx context.Context, application, cluster, commit string) (*ChartFiles, error)
 	CreateCluster(ctx context.Context, params *CreateClusterParams) error
 	UpdateCluster(ctx context.Context, params *UpdateClusterParams) error
 	DeleteCluster(ctx context.Context, application, cluster string, clusterID uint) error
@@ -145,6 +160,12 @@ type ClusterGitRepo interface {
 	GetRepoInfo(ctx context.Context, application, cluster string) *RepoInfo
 	GetEnvValue(ctx context.Context, application, cluster, templateName string) (*EnvValue, error)
 	UpdateEnvValue(ctx context.Context, application, cluster, template string, envValue *EnvValue) (commitID string, err error)
+	// UpdateSecrets writes the sealed secret of cluster to the default branch, which takes effect on next deploy
+	UpdateSecrets(ctx context.Context, application, cluster, template string,
+		secretValue *SecretValue) (commitID string, err error)
+	// EnsureSecretFile creates an empty secret file in the default branch if it does not exist,
+	// which is a value file of argo cd application, for clusters created before cluster secrets are supported
+	EnsureSecretFile(ctx context.Context, application, cluster string) error
 	// Rollback rolls gitOps branch back to a specific commit if there are diffs
 	Rollback(ctx context.Context, application, cluster, commit string) (string, error)
 	UpdateTags(ctx context.Context, application, cluster, templateName string,
@@ -427,6 +448,58 @@ func (g *clusterGitopsRepo) GetClusterTemplate(ctx context.Context, application,
 	return nil, perror.Wrapf(herrors.ErrParamInvalid,
 		"failed to get cluster template from chart")
 }
+
+func (g *clusterGitopsRepo) GetChartFiles(ctx context.Context, application,
+	cluster, commit string) (_ *ChartFiles, err error) {
+	const op = "cluster git repo: get chart files"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// 1. get Chart file and value files from git
+	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	valueFiles := g.GetRepoInfo(ctx, application, cluster).ValueFiles
+	cases := make([]ReadFileParam, 0, len(valueFiles)+1)
+	cases = append(cases, ReadFileParam{FileName: common.GitopsFileChart})
+	for _, valueFile := range valueFiles {
+		cases = append(cases, ReadFileParam{FileName: valueFile})
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(cases))
+	for i := 0; i < len(cases); i++ {
+		go func(index int) {
+			defer wg.Done()
+			cases[index].Bytes, cases[index].Err = g.gitlabLib.GetFile(ctx, pid,
+				commit, cases[index].FileName)
+		}(i)
+	}
+	wg.Wait()
+
+	if cases[0].Err != nil {
+		return nil, cases[0].Err
+	}
+	var chart Chart
+	if err := yaml.Unmarshal(cases[0].Bytes, &chart); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"yaml Unmarshal err, file = %s", common.GitopsFileChart)
+	}
+
+	// 2. skip missing value files
+	chartFiles := &ChartFiles{Chart: &chart}
+	for _, oneCase := range cases[1:] {
+		if oneCase.Err != nil {
+			if _, ok := perror.Cause(oneCase.Err).(*herrors.HorizonErrNotFound); ok {
+				continue
+			}
+			return nil, oneCase.Err
+		}
+		chartFiles.ValueFiles = append(chartFiles.ValueFiles, ChartValueFile{
+			FileName: oneCase.FileName,
+			Content:  oneCase.Bytes,
+		})
+	}
+	return chartFiles, nil
+}
+
 func (g *clusterGitopsRepo) CreateCluster(ctx context.Context, params *CreateClusterParams) (err error) {
 	const op = "cluster git repo: create cluster"
 	defer wlog.Start(ctx, op).StopPrint()
@@ -520,6 +593,12 @@ func (g *clusterGitopsRepo) CreateCluster(ctx context.Context, params *CreateClu
 				FilePath: common.GitopsFileRestart,
 				Content:  string(restartYAML),
 			},
+			// create GitopsFileSecret file first, argo cd fails on missing value files
+			{
+				Action:   gitlablib.FileCreate,
+				FilePath: common.GitopsFileSecret,
+				Content:  "",
+			},
 		}
 
 		if applicationYAML != nil {
@@ -1060,7 +1139,8 @@ func (g *clusterGitopsRepo) GetRepoInfo(ctx context.Context, application, cluste
 	return &RepoInfo{
 		GitRepoURL: fmt.Sprintf("%v/%v/%v/%v.git", repoURL, g.clustersGroup.FullPath, application, cluster),
 		ValueFiles: []string{common.GitopsFileApplication, common.GitopsFilePipelineOutput,
-			common.GitopsFileEnv, common.GitopsFileBase, common.GitopsFileTags, common.GitopsFileRestart, common.GitopsFileSRE},
+			common.GitopsFileEnv, common.GitopsFileBase, common.GitopsFileTags, common.GitopsFileRestart, common.GitopsFileSRE,
+			common.GitopsFileSecret},
 	}
 }
 
@@ -1129,6 +1209,94 @@ func (g *clusterGitopsRepo) UpdateEnvValue(ctx context.Context,
 	return commit.ID, nil
 }
 
+func (g *clusterGitopsRepo) UpdateSecrets(ctx context.Context,
+	application, cluster, template string, secretValue *SecretValue) (commitID string, err error) {
+	const op = "cluster git repo: update secrets"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+
+	// the secret file does not exist in clusters created before cluster secrets are supported
+	fileExist := true
+	if _, err := g.gitlabLib.GetFile(ctx, pid, g.defaultBranch, common.GitopsFileSecret); err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return "", err
+		}
+		fileExist = false
+	}
+
+	var secretYAML []byte
+	marshal(&secretYAML, &err, assembleSecret(template, secretValue))
+	if err != nil {
+		return "", err
+	}
+
+	actions := []gitlablib.CommitAction{
+		{
+			Action: func() gitlablib.FileAction {
+				if fileExist {
+					return gitlablib.FileUpdate
+				}
+				return gitlablib.FileCreate
+			}(),
+			FilePath: common.GitopsFileSecret,
+			Content:  string(secretYAML),
+		},
+	}
+
+	commitMsg := angular.CommitMessage("cluster", angular.Subject{
+		Operator: currentUser.GetName(),
+		Action:   "update secrets",
+		Cluster:  angular.StringPtr(cluster),
+	}, nil)
+
+	// update in defaultBranch directly
+	commit, err := g.gitlabLib.WriteFiles(ctx, pid, g.defaultBranch, commitMsg, nil, actions)
+	if err != nil {
+		return "", err
+	}
+
+	return commit.ID, nil
+}
+
+func (g *clusterGitopsRepo) EnsureSecretFile(ctx context.Context, application, cluster string) error {
+	const op = "cluster git repo: ensure secret file"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	_, err := g.gitlabLib.GetFile(ctx, pid, g.defaultBranch, common.GitopsFileSecret)
+	if err == nil {
+		return nil
+	}
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+		return err
+	}
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	actions := []gitlablib.CommitAction{
+		{
+			Action:   gitlablib.FileCreate,
+			FilePath: common.GitopsFileSecret,
+			Content:  "",
+		},
+	}
+	commitMsg := angular.CommitMessage("cluster", angular.Subject{
+		Operator: currentUser.GetName(),
+		Action:   "create secret file",
+		Cluster:  angular.StringPtr(cluster),
+	}, nil)
+	_, err = g.gitlabLib.WriteFiles(ctx, pid, g.defaultBranch, commitMsg, nil, actions)
+	return err
+}
+
 func (g *clusterGitopsRepo) CheckAndSyncGitOpsBranch(ctx context.Context, application, cluster, commit string) error {
 	changed, err := g.manifestVersionChanged(ctx, application, cluster, commit)
 	if err != nil {
@@ -1642,6 +1810,14 @@ type EnvValue struct {
 	IngressDomain string `yaml:"ingressDomain"`
 }
 
+// SecretValue is the sealed secret of cluster, values of EncryptedData can only be
+// decrypted into the secret with the name in the namespace by the sealed secrets controller of the region
+type SecretValue struct {
+	Name          string
+	Namespace     string
+	EncryptedData map[string]string
+}
+
 func getNamespace(params *BaseParams) string {
 	if params.Namespace != "" {
 		return params.Namespace
@@ -1902,6 +2078,37 @@ func assembleRestart(templateName string) map[string]map[string]string {
 	return ret
 }
 
+// assembleSecret assembles the sealed secret manifest under secrets of the template,
+// which templates render as it is and reference the secret by name
+func assembleSecret(templateName string, secretValue *SecretValue) map[string]map[string]interface{} {
+	metadata := map[string]interface{}{
+		"name":      secretValue.Name,
+		"namespace": secretValue.Namespace,
+	}
+	encryptedData := secretValue.EncryptedData
+	if encryptedData == nil {
+		encryptedData = make(map[string]string)
+	}
+	return map[string]map[string]interface{}{
+		templateName: {
+			common.GitopsKeySecrets: map[string]interface{}{
+				"name": secretValue.Name,
+				"sealedSecret": map[string]interface{}{
+					"apiVersion": "bitnami.com/v1alpha1",
+					"kind":       "SealedSecret",
+					"metadata":   metadata,
+					"spec": map[string]interface{}{
+						"encryptedData": encryptedData,
+						"template": map[string]interface{}{
+							"metadata": metadata,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func assembleTags(templateName string,
 	tags []*tagmodels.Tag) map[string]map[string]map[string]string {
 	ret := make(map[string]map[string]map[string]string)
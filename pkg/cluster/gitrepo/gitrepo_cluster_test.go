@@ -638,3 +638,31 @@ java:
 	fmt.Println(output)
 	assert.Equal(t, expectedOutput, output)
 }
+
+func TestClusterGitRepo_EnsureSecretFile(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	gitlabmockLib := gitlablibmock.NewMockInterface(mockCtrl)
+	gitlabmockLib.EXPECT().GetCreatedGroup(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&gitlab.Group{}, nil).AnyTimes()
+	var clusterGitRepoInstance ClusterGitRepo // nolint
+	clusterGitRepoInstance, err := NewClusterGitlabRepo(ctx, rootGroup, &chartmuseumbase.Repo{},
+		gitlabmockLib, defaultBranch, defaultVisibility)
+	assert.Nil(t, err)
+
+	// the secret file is created if it does not exist
+	gitlabmockLib.EXPECT().GetFile(gomock.Any(), gomock.Any(), defaultBranch, common.GitopsFileSecret).
+		Return(nil, herrors.NewErrNotFound(herrors.GitlabResource, "test")).Times(1)
+	gitlabmockLib.EXPECT().WriteFiles(gomock.Any(), gomock.Any(), defaultBranch, gomock.Any(), gomock.Any(),
+		[]gitlablib.CommitAction{{
+			Action:   gitlablib.FileCreate,
+			FilePath: common.GitopsFileSecret,
+		}}).Return(&gitlab.Commit{}, nil).Times(1)
+	assert.Nil(t, clusterGitRepoInstance.EnsureSecretFile(ctx, "app", "cluster"))
+
+	// nothing is written if it exists
+	gitlabmockLib.EXPECT().GetFile(gomock.Any(), gomock.Any(), defaultBranch, common.GitopsFileSecret).
+		Return([]byte(""), nil).Times(1)
+	assert.Nil(t, clusterGitRepoInstance.EnsureSecretFile(ctx, "app", "cluster"))
+}
@@ -42,6 +42,9 @@ type Manager interface {
 	List(ctx context.Context, query *q.Query, appIDs ...uint) (int, []*models.ClusterWithRegion, error)
 	ListByApplicationID(ctx context.Context, applicationID uint) (int, []*models.ClusterWithRegion, error)
 	ListClusterWithExpiry(ctx context.Context, query *q.Query) ([]*models.Cluster, error)
+	// ListRunningClusters lists clusters neither deleted nor freed ordered by id,
+	// filtered by keywords IDThan and environment
+	ListRunningClusters(ctx context.Context, query *q.Query) ([]*models.Cluster, error)
 	GetByNameFuzzilyIncludeSoftDelete(ctx context.Context, name string) ([]*models.Cluster, error)
 }
 
@@ -146,3 +149,20 @@ func (m *manager) ListClusterWithExpiry(ctx context.Context,
 	}
 	return m.dao.ListClusterWithExpiry(ctx, query)
 }
+
+func (m *manager) ListRunningClusters(ctx context.Context,
+	query *q.Query) ([]*models.Cluster, error) {
+	if query == nil {
+		query = &q.Query{
+			PageNumber: common.DefaultPageNumber,
+			PageSize:   common.DefaultPageSize,
+		}
+	}
+	if query.PageNumber < 1 {
+		query.PageNumber = common.DefaultPageNumber
+	}
+	if query.PageSize < 1 {
+		query.PageSize = common.DefaultPageSize
+	}
+	return m.dao.ListRunningClusters(ctx, query)
+}
@@ -36,6 +36,7 @@ import (
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	"github.com/horizoncd/horizon/pkg/server/global"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	teammodels "github.com/horizoncd/horizon/pkg/team/models"
 	templatemodels "github.com/horizoncd/horizon/pkg/template/models"
 	userdao "github.com/horizoncd/horizon/pkg/user/dao"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
@@ -44,7 +45,7 @@ import (
 )
 
 var (
-	db, _     = orm.NewSqliteDB("")
+	db, _     = orm.NewTestDB()
 	ctx       context.Context
 	mgr       = New(db)
 	memberMgr = membermanager.New(db)
@@ -68,7 +69,7 @@ func TestMain(m *testing.M) {
 	}))
 	if err := db.AutoMigrate(&models.Cluster{}, &tagmodels.Tag{}, &usermodels.User{},
 		&envregionmodels.EnvironmentRegion{}, &regionmodels.Region{}, &membermodels.Member{},
-		&pipelinemodel.Pipelinerun{}, &templatemodels.Template{}); err != nil {
+		&pipelinemodel.Pipelinerun{}, &templatemodels.Template{}, &teammodels.TeamMember{}); err != nil {
 		panic(err)
 	}
 	userDAO := userdao.NewDAO(db)
@@ -0,0 +1,182 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dora
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/horizoncd/horizon/pkg/dora/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	// _window is the time range of deployments to compute metrics of
+	_window = 30 * 24 * time.Hour
+	// _refreshInterval is the interval to compute metrics, scrapes are served with the last computed metrics
+	_refreshInterval = 5 * time.Minute
+
+	horizonDoraDeployments          = "horizon_dora_deployments"
+	horizonDoraDeploymentFrequency  = "horizon_dora_deployment_frequency"
+	horizonDoraLeadTimeSeconds      = "horizon_dora_lead_time_seconds"
+	horizonDoraChangeFailureRate    = "horizon_dora_change_failure_rate"
+	horizonDoraTimeToRestoreSeconds = "horizon_dora_time_to_restore_seconds"
+)
+
+var labelKeys = []string{"application", "environment"}
+
+var helps = map[string]string{
+	horizonDoraDeployments:          "Number of deployments in the last 30 days",
+	horizonDoraDeploymentFrequency:  "Deployments per day in the last 30 days",
+	horizonDoraLeadTimeSeconds:      "Median duration from commit to deployment in the last 30 days",
+	horizonDoraChangeFailureRate:    "Rate of deployments followed by a rollback in the last 30 days",
+	horizonDoraTimeToRestoreSeconds: "Median duration from a failed deployment to its rollback in the last 30 days",
+}
+
+// NewMetrics registers the collector of DORA metrics, the metrics are computed periodically until ctx is done,
+// so that scrapes never query the database
+func NewMetrics(ctx context.Context, managers *managerparam.Manager) {
+	collector := &Collector{
+		managers: managers,
+	}
+	prometheus.MustRegister(collector)
+	go collector.run(ctx)
+}
+
+// Collector exports DORA metrics of each application and environment
+type Collector struct {
+	managers *managerparam.Manager
+
+	lock    sync.RWMutex
+	samples []*sample
+}
+
+// sample is the metrics of an application in an environment
+type sample struct {
+	labels  prometheus.Labels
+	metrics *models.Metrics
+}
+
+func (collector *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for name, help := range helps {
+		ch <- prometheus.NewDesc(name, help, labelKeys, nil)
+	}
+}
+
+func (collector *Collector) Collect(ch chan<- prometheus.Metric) {
+	collector.lock.RLock()
+	samples := collector.samples
+	collector.lock.RUnlock()
+	if len(samples) == 0 {
+		return
+	}
+
+	gauges := make(map[string]*prometheus.GaugeVec, len(helps))
+	for name, help := range helps {
+		gauges[name] = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: name,
+			Help: help,
+		}, labelKeys)
+	}
+	for _, s := range samples {
+		gauges[horizonDoraDeployments].With(s.labels).Set(float64(s.metrics.Deployments))
+		gauges[horizonDoraDeploymentFrequency].With(s.labels).Set(s.metrics.DeploymentFrequency)
+		gauges[horizonDoraLeadTimeSeconds].With(s.labels).Set(s.metrics.LeadTimeSeconds)
+		gauges[horizonDoraChangeFailureRate].With(s.labels).Set(s.metrics.ChangeFailureRate)
+		gauges[horizonDoraTimeToRestoreSeconds].With(s.labels).Set(s.metrics.TimeToRestoreSeconds)
+	}
+	for _, gauge := range gauges {
+		gauge.Collect(ch)
+	}
+}
+
+func (collector *Collector) run(ctx context.Context) {
+	collector.refresh(ctx)
+	ticker := time.NewTicker(_refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			collector.refresh(ctx)
+		}
+	}
+}
+
+// refresh computes the metrics of the last 30 days, the previous samples are kept if it fails
+func (collector *Collector) refresh(ctx context.Context) {
+	end := time.Now()
+	start := end.Add(-_window)
+
+	deployments, err := collector.managers.DoraMgr.ListDeployments(ctx, &models.Query{
+		Start: start,
+		End:   end,
+	})
+	if err != nil {
+		log.Errorf(ctx, "Failed to list deployments: %v", err)
+		return
+	}
+
+	type key struct {
+		applicationID uint
+		environment   string
+	}
+	grouped := make(map[key][]*models.Deployment)
+	applicationIDSet := make(map[uint]struct{})
+	applicationIDs := make([]uint, 0)
+	for _, d := range deployments {
+		k := key{applicationID: d.ApplicationID, environment: d.Environment}
+		grouped[k] = append(grouped[k], d)
+		if _, ok := applicationIDSet[d.ApplicationID]; !ok {
+			applicationIDSet[d.ApplicationID] = struct{}{}
+			applicationIDs = append(applicationIDs, d.ApplicationID)
+		}
+	}
+
+	samples := make([]*sample, 0, len(grouped))
+	if len(grouped) > 0 {
+		apps, err := collector.managers.ApplicationMgr.GetByIDs(ctx, applicationIDs)
+		if err != nil {
+			log.Errorf(ctx, "Failed to get applications: %v", err)
+			return
+		}
+		appNames := make(map[uint]string, len(apps))
+		for _, app := range apps {
+			appNames[app.ID] = app.Name
+		}
+		for k, ds := range grouped {
+			appName, ok := appNames[k.applicationID]
+			if !ok {
+				continue
+			}
+			samples = append(samples, &sample{
+				labels: prometheus.Labels{
+					"application": appName,
+					"environment": k.environment,
+				},
+				metrics: models.Calculate(ds, start, end),
+			})
+		}
+	}
+
+	collector.lock.Lock()
+	collector.samples = samples
+	collector.lock.Unlock()
+}
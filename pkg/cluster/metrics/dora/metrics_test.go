@@ -0,0 +1,66 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dora
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+)
+
+func TestCollector(t *testing.T) {
+	ctx := context.Background()
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&appmodels.Application{}, &clustermodels.Cluster{}, &prmodels.Pipelinerun{}))
+
+	app := &appmodels.Application{Name: "app"}
+	assert.Nil(t, db.Create(app).Error)
+	cluster := &clustermodels.Cluster{Name: "cluster", ApplicationID: app.ID, EnvironmentName: "online"}
+	assert.Nil(t, db.Create(cluster).Error)
+	finishedAt := time.Now().Add(-time.Hour)
+	assert.Nil(t, db.Create(&prmodels.Pipelinerun{
+		ClusterID:  cluster.ID,
+		Action:     prmodels.ActionBuildDeploy,
+		Status:     string(prmodels.StatusOK),
+		GitCommit:  "da1560886d4f094c3e6c9ef40349f7d38b5d27d7",
+		FinishedAt: &finishedAt,
+	}).Error)
+
+	collector := &Collector{managers: managerparam.InitManager(db)}
+	// nothing is exported before the first refresh
+	assert.Equal(t, 0, testutil.CollectAndCount(collector))
+
+	collector.refresh(ctx)
+	assert.Equal(t, len(helps), testutil.CollectAndCount(collector))
+	assert.Equal(t, 1, len(collector.samples))
+	assert.Equal(t, "app", collector.samples[0].labels["application"])
+	assert.Equal(t, 1, collector.samples[0].metrics.Deployments)
+
+	// scrapes are served from the cache until the next refresh
+	assert.Nil(t, db.Exec("delete from tb_pipelinerun").Error)
+	assert.Equal(t, len(helps), testutil.CollectAndCount(collector))
+	collector.refresh(ctx)
+	assert.Equal(t, 0, testutil.CollectAndCount(collector))
+}
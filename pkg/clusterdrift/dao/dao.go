@@ -0,0 +1,86 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/clusterdrift/models"
+)
+
+type DAO interface {
+	GetByClusterID(ctx context.Context, clusterID uint) (*models.ClusterDrift, error)
+	Create(ctx context.Context, drift *models.ClusterDrift) (*models.ClusterDrift, error)
+	Update(ctx context.Context, drift *models.ClusterDrift) error
+	DeleteByClusterID(ctx context.Context, clusterID uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) GetByClusterID(ctx context.Context, clusterID uint) (*models.ClusterDrift, error) {
+	var drift models.ClusterDrift
+	if err := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).First(&drift).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.ClusterDriftInDB,
+				fmt.Sprintf("drift of cluster %d was not found", clusterID))
+		}
+		return nil, herrors.NewErrGetFailed(herrors.ClusterDriftInDB, err.Error())
+	}
+	return &drift, nil
+}
+
+func (d *dao) Create(ctx context.Context, drift *models.ClusterDrift) (*models.ClusterDrift, error) {
+	if err := d.db.WithContext(ctx).Create(drift).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.ClusterDriftInDB, err.Error())
+	}
+	return drift, nil
+}
+
+func (d *dao) Update(ctx context.Context, drift *models.ClusterDrift) error {
+	result := d.db.WithContext(ctx).Model(&models.ClusterDrift{}).Where("id = ?", drift.ID).
+		Updates(map[string]interface{}{
+			"drifted":     drift.Drifted,
+			"resources":   drift.Resources,
+			"detected_at": drift.DetectedAt,
+			"checked_at":  drift.CheckedAt,
+		})
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.ClusterDriftInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return herrors.NewErrNotFound(herrors.ClusterDriftInDB,
+			fmt.Sprintf("cluster drift %d was not found", drift.ID))
+	}
+	return nil
+}
+
+func (d *dao) DeleteByClusterID(ctx context.Context, clusterID uint) error {
+	if err := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).
+		Delete(&models.ClusterDrift{}).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.ClusterDriftInDB, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/clusterdrift/dao"
+	"github.com/horizoncd/horizon/pkg/clusterdrift/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+type Manager interface {
+	// GetByClusterID returns HorizonErrNotFound if drift of the cluster has never been detected
+	GetByClusterID(ctx context.Context, clusterID uint) (*models.ClusterDrift, error)
+	// Upsert saves the latest drift of the cluster, and returns the drift saved before, which is nil
+	// if drift of the cluster has never been detected
+	Upsert(ctx context.Context, drift *models.ClusterDrift) (*models.ClusterDrift, error)
+	DeleteByClusterID(ctx context.Context, clusterID uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) GetByClusterID(ctx context.Context, clusterID uint) (*models.ClusterDrift, error) {
+	return m.dao.GetByClusterID(ctx, clusterID)
+}
+
+func (m *manager) Upsert(ctx context.Context, drift *models.ClusterDrift) (*models.ClusterDrift, error) {
+	previous, err := m.dao.GetByClusterID(ctx, drift.ClusterID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return nil, err
+		}
+		_, err = m.dao.Create(ctx, drift)
+		return nil, err
+	}
+
+	// keep the time drift was detected first
+	if drift.Drifted && previous.Drifted && previous.DetectedAt != nil {
+		drift.DetectedAt = previous.DetectedAt
+	}
+	drift.ID = previous.ID
+	if err := m.dao.Update(ctx, drift); err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+func (m *manager) DeleteByClusterID(ctx context.Context, clusterID uint) error {
+	return m.dao.DeleteByClusterID(ctx, clusterID)
+}
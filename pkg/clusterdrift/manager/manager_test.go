@@ -0,0 +1,87 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/clusterdrift/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewTestDB()
+	ctx   = context.TODO()
+	mgr   = New(db)
+)
+
+func init() {
+	if err := db.AutoMigrate(&models.ClusterDrift{}); err != nil {
+		panic(err)
+	}
+}
+
+func Test(t *testing.T) {
+	_, err := mgr.GetByClusterID(ctx, 1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	detectedAt := time.Now().Add(-time.Hour)
+	previous, err := mgr.Upsert(ctx, &models.ClusterDrift{
+		ClusterID:  1,
+		Drifted:    true,
+		Resources:  `[{"kind":"Deployment"}]`,
+		DetectedAt: &detectedAt,
+		CheckedAt:  detectedAt,
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, previous)
+
+	// the time drift was detected first is kept
+	now := time.Now()
+	previous, err = mgr.Upsert(ctx, &models.ClusterDrift{
+		ClusterID:  1,
+		Drifted:    true,
+		Resources:  `[{"kind":"Service"}]`,
+		DetectedAt: &now,
+		CheckedAt:  now,
+	})
+	assert.Nil(t, err)
+	assert.True(t, previous.Drifted)
+	drift, err := mgr.GetByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, `[{"kind":"Service"}]`, drift.Resources)
+	assert.Equal(t, detectedAt.Unix(), drift.DetectedAt.Unix())
+
+	_, err = mgr.Upsert(ctx, &models.ClusterDrift{
+		ClusterID: 1,
+		CheckedAt: time.Now(),
+	})
+	assert.Nil(t, err)
+	drift, err = mgr.GetByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.False(t, drift.Drifted)
+	assert.Nil(t, drift.DetectedAt)
+
+	assert.Nil(t, mgr.DeleteByClusterID(ctx, 1))
+	_, err = mgr.GetByClusterID(ctx, 1)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
@@ -0,0 +1,32 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// ClusterDrift is the latest drift detected of a cluster
+type ClusterDrift struct {
+	ID        uint `gorm:"primarykey"`
+	ClusterID uint
+	Drifted   bool
+	// Resources is the json encoded drifted resources
+	Resources string
+	// DetectedAt is the time drift was detected first, it's reset when drift is resolved
+	DetectedAt *time.Time
+	// CheckedAt is the time of the last detection
+	CheckedAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
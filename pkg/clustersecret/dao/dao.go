@@ -0,0 +1,105 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/clustersecret/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, secret *models.ClusterSecret) (*models.ClusterSecret, error)
+	GetByName(ctx context.Context, clusterID uint, name string) (*models.ClusterSecret, error)
+	ListByClusterID(ctx context.Context, clusterID uint) ([]*models.ClusterSecret, error)
+	// UpdateValue replaces the sealed value and increases the version
+	UpdateValue(ctx context.Context, id uint, sealedValue string, updatedBy uint) error
+	DeleteByID(ctx context.Context, id uint) error
+	DeleteByClusterID(ctx context.Context, clusterID uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, secret *models.ClusterSecret) (*models.ClusterSecret, error) {
+	if err := d.db.WithContext(ctx).Create(secret).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.ClusterSecretInDB, err.Error())
+	}
+	return secret, nil
+}
+
+func (d *dao) GetByName(ctx context.Context, clusterID uint, name string) (*models.ClusterSecret, error) {
+	var secret models.ClusterSecret
+	if err := d.db.WithContext(ctx).Where("cluster_id = ? and name = ?", clusterID, name).
+		First(&secret).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.ClusterSecretInDB,
+				fmt.Sprintf("secret %s of cluster %d was not found", name, clusterID))
+		}
+		return nil, herrors.NewErrGetFailed(herrors.ClusterSecretInDB, err.Error())
+	}
+	return &secret, nil
+}
+
+func (d *dao) ListByClusterID(ctx context.Context, clusterID uint) ([]*models.ClusterSecret, error) {
+	var secrets []*models.ClusterSecret
+	if err := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).
+		Order("name").Find(&secrets).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.ClusterSecretInDB, err.Error())
+	}
+	return secrets, nil
+}
+
+func (d *dao) UpdateValue(ctx context.Context, id uint, sealedValue string, updatedBy uint) error {
+	result := d.db.WithContext(ctx).Model(&models.ClusterSecret{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"sealed_value": sealedValue,
+			"version":      gorm.Expr("version + 1"),
+			"updated_by":   updatedBy,
+		})
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.ClusterSecretInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return herrors.NewErrNotFound(herrors.ClusterSecretInDB,
+			fmt.Sprintf("cluster secret %d was not found", id))
+	}
+	return nil
+}
+
+func (d *dao) DeleteByID(ctx context.Context, id uint) error {
+	if err := d.db.WithContext(ctx).Delete(&models.ClusterSecret{}, id).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.ClusterSecretInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) DeleteByClusterID(ctx context.Context, clusterID uint) error {
+	if err := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).
+		Delete(&models.ClusterSecret{}).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.ClusterSecretInDB, err.Error())
+	}
+	return nil
+}
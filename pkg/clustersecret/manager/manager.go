@@ -0,0 +1,78 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/clustersecret/dao"
+	"github.com/horizoncd/horizon/pkg/clustersecret/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+type Manager interface {
+	// Create returns ErrClusterSecretExists if the cluster has a secret with the same name
+	Create(ctx context.Context, secret *models.ClusterSecret) (*models.ClusterSecret, error)
+	GetByName(ctx context.Context, clusterID uint, name string) (*models.ClusterSecret, error)
+	// ListByClusterID lists secrets of a cluster ordered by name
+	ListByClusterID(ctx context.Context, clusterID uint) ([]*models.ClusterSecret, error)
+	// Rotate replaces the sealed value of the secret and increases its version
+	Rotate(ctx context.Context, id uint, sealedValue string, updatedBy uint) error
+	DeleteByID(ctx context.Context, id uint) error
+	DeleteByClusterID(ctx context.Context, clusterID uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Create(ctx context.Context, secret *models.ClusterSecret) (*models.ClusterSecret, error) {
+	_, err := m.dao.GetByName(ctx, secret.ClusterID, secret.Name)
+	if err == nil {
+		return nil, perror.Wrapf(herrors.ErrClusterSecretExists, "secret %s", secret.Name)
+	}
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+		return nil, err
+	}
+	secret.Version = 1
+	return m.dao.Create(ctx, secret)
+}
+
+func (m *manager) GetByName(ctx context.Context, clusterID uint, name string) (*models.ClusterSecret, error) {
+	return m.dao.GetByName(ctx, clusterID, name)
+}
+
+func (m *manager) ListByClusterID(ctx context.Context, clusterID uint) ([]*models.ClusterSecret, error) {
+	return m.dao.ListByClusterID(ctx, clusterID)
+}
+
+func (m *manager) Rotate(ctx context.Context, id uint, sealedValue string, updatedBy uint) error {
+	return m.dao.UpdateValue(ctx, id, sealedValue, updatedBy)
+}
+
+func (m *manager) DeleteByID(ctx context.Context, id uint) error {
+	return m.dao.DeleteByID(ctx, id)
+}
+
+func (m *manager) DeleteByClusterID(ctx context.Context, clusterID uint) error {
+	return m.dao.DeleteByClusterID(ctx, clusterID)
+}
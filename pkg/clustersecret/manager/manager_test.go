@@ -0,0 +1,86 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"testing"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/clustersecret/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewTestDB()
+	ctx   = context.TODO()
+	mgr   = New(db)
+)
+
+func init() {
+	if err := db.AutoMigrate(&models.ClusterSecret{}); err != nil {
+		panic(err)
+	}
+}
+
+func Test(t *testing.T) {
+	secret, err := mgr.Create(ctx, &models.ClusterSecret{
+		ClusterID:   1,
+		Name:        "DB_PASSWORD",
+		SealedValue: "sealed1",
+		CreatedBy:   1,
+		UpdatedBy:   1,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), secret.Version)
+
+	_, err = mgr.Create(ctx, &models.ClusterSecret{
+		ClusterID:   1,
+		Name:        "DB_PASSWORD",
+		SealedValue: "sealed2",
+	})
+	assert.Equal(t, herrors.ErrClusterSecretExists, perror.Cause(err))
+
+	_, err = mgr.Create(ctx, &models.ClusterSecret{
+		ClusterID:   1,
+		Name:        "API_KEY",
+		SealedValue: "sealed3",
+	})
+	assert.Nil(t, err)
+
+	assert.Nil(t, mgr.Rotate(ctx, secret.ID, "sealed4", 2))
+	secret, err = mgr.GetByName(ctx, 1, "DB_PASSWORD")
+	assert.Nil(t, err)
+	assert.Equal(t, "sealed4", secret.SealedValue)
+	assert.Equal(t, uint(2), secret.Version)
+	assert.Equal(t, uint(2), secret.UpdatedBy)
+
+	secrets, err := mgr.ListByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(secrets))
+	assert.Equal(t, "API_KEY", secrets[0].Name)
+
+	assert.Nil(t, mgr.DeleteByID(ctx, secret.ID))
+	_, err = mgr.GetByName(ctx, 1, "DB_PASSWORD")
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	assert.Nil(t, mgr.DeleteByClusterID(ctx, 1))
+	secrets, err = mgr.ListByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Empty(t, secrets)
+}
@@ -0,0 +1,33 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// ClusterSecret is a secret of cluster, whose value is sealed with the sealed secrets certificate
+// of the cluster's region, the plaintext value is never stored
+type ClusterSecret struct {
+	ID        uint `gorm:"primarykey"`
+	ClusterID uint
+	// Name is the key in the kubernetes secret of cluster
+	Name        string
+	SealedValue string
+	// Version is increased every time the value is rotated
+	Version   uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy uint
+	UpdatedBy uint
+}
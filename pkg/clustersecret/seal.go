@@ -0,0 +1,105 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustersecret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io"
+
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const sessionKeyLength = 32
+
+// Seal encrypts the value in the format of sealed secrets with strict scope,
+// so that it can only be decrypted by the sealed secrets controller owning the certificate,
+// and only into the secret with the name in the namespace
+func Seal(certificate, namespace, secretName string, value []byte) (string, error) {
+	publicKey, err := ParsePublicKey(certificate)
+	if err != nil {
+		return "", err
+	}
+	label := []byte(fmt.Sprintf("%s/%s", namespace, secretName))
+	sealed, err := hybridEncrypt(rand.Reader, publicKey, value, label)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// ParsePublicKey parses the rsa public key from a pem encoded certificate or public key
+func ParsePublicKey(certificate string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(certificate))
+	if block == nil {
+		return nil, perror.New("failed to decode sealed secrets certificate: no pem block found")
+	}
+	var key interface{}
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, perror.Wrap(err, "failed to parse sealed secrets certificate")
+		}
+		key = cert.PublicKey
+	case "PUBLIC KEY":
+		var err error
+		if key, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			return nil, perror.Wrap(err, "failed to parse sealed secrets public key")
+		}
+	default:
+		return nil, perror.Errorf("unsupported pem block type of sealed secrets certificate: %s", block.Type)
+	}
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, perror.New("sealed secrets certificate is not an rsa key")
+	}
+	return publicKey, nil
+}
+
+// hybridEncrypt is compatible with the one of sealed secrets: the session key encrypted by rsa-oaep
+// prefixed with its length, followed by the value encrypted by aes-gcm with the session key and zero nonce
+func hybridEncrypt(rnd io.Reader, publicKey *rsa.PublicKey, plaintext, label []byte) ([]byte, error) {
+	sessionKey := make([]byte, sessionKeyLength)
+	if _, err := io.ReadFull(rnd, sessionKey); err != nil {
+		return nil, perror.Wrap(err, "failed to generate session key")
+	}
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, perror.Wrap(err, "failed to create cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, perror.Wrap(err, "failed to create cipher")
+	}
+	rsaCiphertext, err := rsa.EncryptOAEP(sha256.New(), rnd, publicKey, sessionKey, label)
+	if err != nil {
+		return nil, perror.Wrap(err, "failed to encrypt session key")
+	}
+	ciphertext := make([]byte, 2, 2+len(rsaCiphertext)+len(plaintext)+aead.Overhead())
+	binary.BigEndian.PutUint16(ciphertext, uint16(len(rsaCiphertext)))
+	ciphertext = append(ciphertext, rsaCiphertext...)
+	// the session key is used only once, so the nonce can be zero
+	zeroNonce := make([]byte, aead.NonceSize())
+	return aead.Seal(ciphertext, zeroNonce, plaintext, nil), nil
+}
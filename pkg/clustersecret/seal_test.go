@@ -0,0 +1,83 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustersecret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// hybridDecrypt is what the sealed secrets controller does
+func hybridDecrypt(t *testing.T, privateKey *rsa.PrivateKey, ciphertext, label []byte) ([]byte, error) {
+	rsaLen := int(binary.BigEndian.Uint16(ciphertext))
+	sessionKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, privateKey, ciphertext[2:2+rsaLen], label)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(sessionKey)
+	assert.Nil(t, err)
+	aead, err := cipher.NewGCM(block)
+	assert.Nil(t, err)
+	return aead.Open(nil, make([]byte, aead.NonceSize()), ciphertext[2+rsaLen:], nil)
+}
+
+func TestSeal(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secret"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	assert.Nil(t, err)
+	certificate := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+
+	sealed, err := Seal(certificate, "ns", "app-secrets", []byte("password"))
+	assert.Nil(t, err)
+	ciphertext, err := base64.StdEncoding.DecodeString(sealed)
+	assert.Nil(t, err)
+
+	value, err := hybridDecrypt(t, privateKey, ciphertext, []byte("ns/app-secrets"))
+	assert.Nil(t, err)
+	assert.Equal(t, "password", string(value))
+
+	// strict scope, the value can not be decrypted into other secrets
+	_, err = hybridDecrypt(t, privateKey, ciphertext, []byte("other/app-secrets"))
+	assert.NotNil(t, err)
+
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	assert.Nil(t, err)
+	_, err = Seal(string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER})),
+		"ns", "app-secrets", []byte("password"))
+	assert.Nil(t, err)
+
+	_, err = Seal("not a certificate", "ns", "app-secrets", []byte("password"))
+	assert.NotNil(t, err)
+}
@@ -74,9 +74,10 @@ const (
 	UserQueryByEmail = "select * from tb_user where email = ? and user_type = ?"
 	UserListByEmail  = "select * from tb_user where email in ? and user_type = ?"
 	UserSearch       = "select * from tb_user where user_type = ?" +
-		" and (name like ? or full_name like ? or email like ?) limit ? offset ?"
+		" and (lower(name) like lower(?) or lower(full_name) like lower(?) or lower(email) like lower(?))" +
+		" limit ? offset ?"
 	UserSearchCount = "select count(1) from tb_user where user_type = ?" +
-		" and (name like ? or full_name like ? or email like ?)"
+		" and (lower(name) like lower(?) or lower(full_name) like lower(?) or lower(email) like lower(?))"
 	UserGetByID    = "select * from tb_user where id in ?"
 	UserDeleteByID = "delete from tb_user where id = ?"
 )
@@ -89,16 +90,25 @@ const (
 	MemberSingleDelete               = "update tb_member set deleted_ts = ? where ID = ?"
 	MemberHardDeleteByResourceTypeID = "delete from tb_member where resource_type = ?" +
 		" and resource_id = ?"
-	MemberHardDeleteByMemberNameID = "delete from tb_member where membername_id = ?"
-	// todo: fix user_type to query condition
-	MemberSelectAll = "select m.* from tb_member m join tb_user u on m.membername_id = u.id" +
-		" where m.resource_type = ? and m.resource_id = ? and m.deleted_ts = 0"
-	// todo: fix user_type to query condition
+	// MemberHardDeleteByMemberNameID deletes the bindings of a user, bindings of the team with the same id are kept
+	MemberHardDeleteByMemberNameID = "delete from tb_member where member_type = 0 and membername_id = ?"
+	// MemberSelectAll lists bindings of users and teams, bindings of users not in tb_user are skipped
+	MemberSelectAll = "select m.* from tb_member m left join tb_user u on m.member_type = 0 and m.membername_id = u.id" +
+		" where m.resource_type = ? and m.resource_id = ? and m.deleted_ts = 0" +
+		" and (m.member_type = 1 or u.id is not null)"
 	MemberSelectByUserEmails = "select tb_member.* from tb_member join tb_user on tb_member.membername_id = tb_user.id" +
 		" where tb_member.resource_type = ? and tb_member.resource_id = ? and tb_user.email in ?" +
 		" and tb_member.member_type = 0 and tb_member.deleted_ts = 0 and tb_user.deleted_ts = 0"
-	MemberListResource = "select resource_id from tb_member where resource_type = ? and" +
-		" membername_id = ? and deleted_ts = 0"
+	// MemberOfUser is the condition of bindings granted to user m.membername_id directly or through teams,
+	// the arguments are the user id twice
+	MemberOfUser = "((m.member_type = 0 and m.membername_id = ?) or (m.member_type = 1 and m.membername_id in" +
+		" (select tm.team_id from tb_team_member tm where tm.user_id = ? and tm.deleted_ts = 0)))"
+	MemberListResource = "select m.resource_id from tb_member m where m.resource_type = ? and " +
+		MemberOfUser + " and m.deleted_ts = 0"
+	MemberListResourceByRole = "select m.resource_id from tb_member m where m.resource_type = ? and m.role = ? and " +
+		MemberOfUser + " and m.deleted_ts = 0"
+	MemberListOfUser = "select m.* from tb_member m where m.resource_type in ? and " +
+		MemberOfUser + " and m.deleted_ts = 0"
 )
 
 /* sql about group */
@@ -112,28 +122,28 @@ const (
 	GroupQueryByID            = "select * from tb_group where id = ? and deleted_ts = 0"
 	GroupQueryByIDs           = "select * from tb_group where id in ? and deleted_ts = 0"
 	GroupQueryByPaths         = "select * from tb_group where path in ? and deleted_ts = 0"
-	GroupQueryByIDNameFuzzily = "select * from tb_group " +
-		"where traversal_ids like ? and name like ? and deleted_ts = 0"
+	GroupQueryByIDNameFuzzily = "select * from tb_group where (traversal_ids = ? or traversal_ids like ? " +
+		"or traversal_ids like ? or traversal_ids like ?) and lower(name) like lower(?) and deleted_ts = 0"
 	GroupAll                      = "select * from tb_group where deleted_ts = 0"
 	GroupUpdateTraversalIDs       = "update tb_group set traversal_ids = ?, updated_by = ? where id = ? and deleted_ts = 0"
 	GroupCountByParentID          = "select count(1) from tb_group where parent_id = ? and deleted_ts = 0"
-	GroupUpdateTraversalIDsPrefix = "update tb_group set traversal_ids = replace(traversal_ids, ?, ?), updated_by = ? " +
-		"where traversal_ids like ? and deleted_ts = 0"
+	GroupQueryByTraversalIDPrefix = "select * from tb_group where (traversal_ids = ? or traversal_ids like ?) " +
+		"and deleted_ts = 0"
 	GroupQueryByNameOrPathUnderParent = "select * from tb_group where parent_id = ? " +
 		"and (name = ? or path = ?) and deleted_ts = 0"
 	GroupQueryGroupChildren = "" +
-		"select * from (select g.id, g.name, g.path, description, updated_at, 'group' as type from tb_group g " +
-		"where g.parent_id=? and g.deleted_ts = 0 " +
+		"select * from (select g.id, g.name, g.path, g.visibility_level, description, updated_at, 'group' as type " +
+		"from tb_group g where g.parent_id=? and g.id not in ? and g.deleted_ts = 0 " +
 		"union " +
-		"select a.id, a.name, a.name as path, description, updated_at, 'application' as type from tb_application a " +
-		"where a.group_id=? and a.deleted_ts = 0) ga " +
+		"select a.id, a.name, a.name as path, a.visibility_level, description, updated_at, 'application' as type " +
+		"from tb_application a where a.group_id=? and a.id not in ? and a.deleted_ts = 0) ga " +
 		"order by ga.type desc,ga.updated_at desc limit ? offset ?"
 	GroupQueryGroupChildrenCount = "" +
 		"select count(1) from (select g.id, g.name, g.path, description, updated_at, 'group' as type from tb_group g " +
-		"where g.parent_id=? and g.deleted_ts = 0 " +
+		"where g.parent_id=? and g.id not in ? and g.deleted_ts = 0 " +
 		"union " +
 		"select a.id, a.name, a.name as path, description, updated_at, 'application' as type from tb_application a " +
-		"where a.group_id=? and a.deleted_ts = 0) ga"
+		"where a.group_id=? and a.id not in ? and a.deleted_ts = 0) ga"
 )
 
 /* sql about application */
@@ -142,7 +152,8 @@ const (
 	ApplicationQueryByGroupIDs        = "select * from tb_application where group_id in ? and deleted_ts = 0"
 	ApplicationQueryByID              = "select * from tb_application where id = ? and deleted_ts = 0"
 	ApplicationQueryByName            = "select * from tb_application where name = ? and deleted_ts = 0"
-	ApplicationQueryByFuzzily         = "select * from tb_application where name like ? and deleted_ts = 0"
+	ApplicationQueryByVisibility      = "select * from tb_application where visibility_level = ? and deleted_ts = 0"
+	ApplicationQueryByFuzzily         = "select * from tb_application where lower(name) like lower(?) and deleted_ts = 0"
 	ApplicationQueryByNamesUnderGroup = "select * from tb_application where group_id = ? and name in ? " +
 		"and deleted_ts = 0"
 	ApplicationDeleteByID     = "update tb_application set deleted_ts = ?, updated_by = ? where id = ?"
@@ -173,11 +184,11 @@ const (
 	EnvironmentRegionGetByEnvAndRegion = "select * from tb_environment_region where environment_name = ? and " +
 		"region_name = ? and deleted_ts = 0"
 	EnvironmentRegionGetDefaultByEnv = "select * from tb_environment_region where environment_name = ? and " +
-		"is_default = 1 and deleted_ts = 0"
+		"is_default = true and deleted_ts = 0"
 	EnvironmentRegionsGetDefault = "select * from tb_environment_region where " +
-		"is_default = 1 and deleted_ts = 0"
-	EnvironmentRegionSetDefaultByID   = "update tb_environment_region set is_default = 1 where id = ?"
-	EnvironmentRegionUnsetDefaultByID = "update tb_environment_region set is_default = 0 where id = ?"
+		"is_default = true and deleted_ts = 0"
+	EnvironmentRegionSetDefaultByID   = "update tb_environment_region set is_default = true where id = ?"
+	EnvironmentRegionUnsetDefaultByID = "update tb_environment_region set is_default = false where id = ?"
 )
 
 /* sql about region */
@@ -245,7 +256,7 @@ const (
 	TagDeleteAllByResourceTypeID = "delete from tb_tag where resource_type = ?" +
 		" and resource_id = ?"
 	TagDeleteByResourceTypeIDAndKeys = "delete from tb_tag where resource_type = ?" +
-		" and resource_id = ? and `tag_key` not in ?"
+		" and resource_id = ? and tag_key not in ?"
 )
 
 /* sql about cluster template tag */
@@ -254,7 +265,7 @@ const (
 		"order by id"
 	ClusterTemplateSchemaTagDeleteAllByClusterID     = "delete from tb_cluster_template_schema_tag where cluster_id = ?"
 	ClusterTemplateSchemaTagDeleteByClusterIDAndKeys = "delete from tb_cluster_template_schema_tag where cluster_id = ?" +
-		" and `tag_key` not in ?"
+		" and tag_key not in ?"
 )
 
 /* sql about application region */
@@ -289,3 +300,11 @@ const (
 	TaskDeleteByCluster     = "delete from tb_task where cluster= ?"
 	StepDeleteByCluster     = "delete from tb_step where cluster= ?"
 )
+
+/* sql about user mfa*/
+const (
+	UserMFARecordFailure = "update tb_user_mfa set " +
+		"locked_until = case when failed_attempts + 1 >= ? then ? else locked_until end, " +
+		"failed_attempts = case when failed_attempts + 1 >= ? then 0 else failed_attempts + 1 end " +
+		"where id = ?"
+)
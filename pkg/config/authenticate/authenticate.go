@@ -14,6 +14,8 @@
 
 package authenticate
 
+import "time"
+
 type Key struct {
 	AccessKey string `yaml:"accessKey"`
 	SecretKey string `yaml:"secretKey"`
@@ -23,3 +25,52 @@ type Key struct {
 type Keys []*Key
 
 type KeysConfig map[string]Keys
+
+// MFAConfig configures the multi-factor authentication
+type MFAConfig struct {
+	// Issuer is the name displayed in authenticator apps
+	Issuer string `yaml:"issuer"`
+	// AdminRequired forces admins to finish the multi-factor authentication
+	// before any request of them is served
+	AdminRequired bool `yaml:"adminRequired"`
+	// StepUpExpireIn is how long a verification stays valid for sensitive operations
+	StepUpExpireIn time.Duration `yaml:"stepUpExpireIn"`
+	// StepUpEnrollRequired rejects sensitive operations of users who have not enrolled,
+	// otherwise step-up is only applied to users that enabled the multi-factor authentication
+	StepUpEnrollRequired bool `yaml:"stepUpEnrollRequired"`
+	// StepUpOperations are the sensitive operations which need a recent verification,
+	// defaults to DefaultStepUpOperations if empty
+	StepUpOperations []Operation `yaml:"stepUpOperations"`
+}
+
+// Operation matches a request by its resource, subresource and verb,
+// an empty or "*" resource matches every resource
+type Operation struct {
+	Resource    string   `yaml:"resource"`
+	SubResource string   `yaml:"subResource"`
+	Verbs       []string `yaml:"verbs"`
+}
+
+var DefaultStepUpOperations = []Operation{
+	{Resource: "clusters", Verbs: []string{"delete"}},
+	{Resource: "clusters", SubResource: "terminal", Verbs: []string{"*"}},
+	{Resource: "clusters", SubResource: "shell", Verbs: []string{"*"}},
+	{Resource: "clusters", SubResource: "exec", Verbs: []string{"*"}},
+	{Resource: "personalaccesstokens", Verbs: []string{"create"}},
+	{Resource: "*", SubResource: "accesstokens", Verbs: []string{"create"}},
+}
+
+func (o *Operation) Match(resource, subResource, verb string) bool {
+	if o.Resource != "" && o.Resource != "*" && o.Resource != resource {
+		return false
+	}
+	if o.SubResource != subResource {
+		return false
+	}
+	for _, v := range o.Verbs {
+		if v == "*" || v == verb {
+			return true
+		}
+	}
+	return false
+}
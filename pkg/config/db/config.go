@@ -16,14 +16,33 @@ package db
 
 import "time"
 
+const (
+	TypeMySQL    = "mysql"
+	TypePostgres = "postgres"
+)
+
 type Config struct {
+	Type              string        `yaml:"type"`
 	Host              string        `yaml:"host"`
 	Port              int           `yaml:"port"`
 	Username          string        `yaml:"username"`
 	Password          string        `yaml:"password,omitempty"`
 	Database          string        `yaml:"database"`
+	SSLMode           string        `yaml:"sslMode"`
 	PrometheusEnabled bool          `yaml:"prometheusEnabled"`
 	SlowThreshold     time.Duration `yaml:"slowThreshold"`
 	MaxIdleConns      int           `yaml:"maxIdleConns"`
 	MaxOpenConns      int           `yaml:"maxOpenConns"`
+	Migration         Migration     `yaml:"migration"`
+}
+
+type Migration struct {
+	// Dir is the directory of schema snapshots, which has migration sql files in its migrations subdir,
+	// defaults to db for mysql and db/postgres for postgres
+	Dir string `yaml:"dir"`
+	// AutoApply applies pending migrations on startup,
+	// otherwise the server refuses to start until they are applied by the migrate command,
+	// or baselined by it for databases deployed before migrations were tracked
+	AutoApply   bool          `yaml:"autoApply"`
+	LockTimeout time.Duration `yaml:"lockTimeout"`
 }
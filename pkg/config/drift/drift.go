@@ -0,0 +1,33 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drift
+
+import "time"
+
+// Config of detecting configuration drift between gitops repo and live objects of clusters
+type Config struct {
+	Enabled bool `yaml:"enabled"`
+	// Interval is the interval of detecting, default 10m
+	Interval time.Duration `yaml:"interval"`
+	// Environments are environments whose clusters are detected, keyed by environment name
+	Environments map[string]EnvironmentConfig `yaml:"environments"`
+}
+
+type EnvironmentConfig struct {
+	// Notify sends emails to members of clusters when drift is detected
+	Notify bool `yaml:"notify"`
+	// AutoResync deploys the last config commit of clusters to overwrite drifted objects
+	AutoResync bool `yaml:"autoResync"`
+}
@@ -0,0 +1,46 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import "time"
+
+const (
+	ProviderLocal = "local"
+	ProviderVault = "vault"
+)
+
+// Config configures envelope encryption of credentials stored in the database,
+// encryption is disabled if Provider is empty
+type Config struct {
+	Provider string `yaml:"provider"`
+	Local    Local  `yaml:"local"`
+	Vault    Vault  `yaml:"vault"`
+}
+
+type Local struct {
+	// KeyFile contains one key per line in the format of <key id>:<base64 encoded 32 bytes key>
+	KeyFile string `yaml:"keyFile"`
+	// PrimaryKeyID is the key new values are encrypted with, defaults to the last key in the file
+	PrimaryKeyID string `yaml:"primaryKeyID"`
+}
+
+// Vault is a vault transit secrets engine or a service compatible with its http api
+type Vault struct {
+	Address string        `yaml:"address"`
+	Token   string        `yaml:"token"`
+	Mount   string        `yaml:"mount"`
+	KeyName string        `yaml:"keyName"`
+	Timeout time.Duration `yaml:"timeout"`
+}
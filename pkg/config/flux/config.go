@@ -0,0 +1,29 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flux
+
+import "time"
+
+// Config of the flux cd engine, which is used by regions whose cd engine is flux
+type Config struct {
+	// Namespace is where GitRepository and HelmRelease objects of clusters are created, default flux-system
+	Namespace string `yaml:"namespace"`
+	// Interval is the reconcile interval of GitRepository and HelmRelease objects, default 5m
+	Interval time.Duration `yaml:"interval"`
+	// GitSecretName is the secret in Namespace with credentials to pull gitops repos
+	GitSecretName string `yaml:"gitSecretName"`
+	// DeleteTimeout is the max time to wait for HelmRelease objects to be uninstalled, default 5m
+	DeleteTimeout time.Duration `yaml:"deleteTimeout"`
+}
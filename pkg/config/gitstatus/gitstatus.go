@@ -0,0 +1,24 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitstatus
+
+type Config struct {
+	// Enabled reports commit statuses and deployments of pipelineruns back to the source git host
+	Enabled bool `yaml:"enabled"`
+	// PipelinerunURLTemplate is a go template to render the link of a pipelinerun shown in git host,
+	// such as https://horizon.example.com/clusters/{{ .ClusterID }}/pipelineruns/{{ .PipelinerunID }}.
+	// Fields PipelinerunID, ClusterID, Cluster, Application and Environment are available.
+	PipelinerunURLTemplate string `yaml:"pipelinerunURLTemplate"`
+}
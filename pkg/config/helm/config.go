@@ -0,0 +1,25 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import "time"
+
+// Config of the helm cd engine, which is used by regions whose cd engine is helm
+type Config struct {
+	// MaxHistory is the max number of revisions saved per release, default 10
+	MaxHistory int `yaml:"maxHistory"`
+	// Timeout is the max time to wait for hooks of releases, default 5m
+	Timeout time.Duration `yaml:"timeout"`
+}
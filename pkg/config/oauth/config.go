@@ -29,4 +29,7 @@ type Server struct {
 	AuthorizeCodeExpireIn time.Duration `yaml:"authorizeCodeExpireIn"`
 	AccessTokenExpireIn   time.Duration `yaml:"accessTokenExpireIn"`
 	RefreshTokenExpireIn  time.Duration `yaml:"refreshTokenExpireIn"`
+	DeviceCodeExpireIn    time.Duration `yaml:"deviceCodeExpireIn"`
+	// DeviceVerificationURI is the page where users enter the user code of device authorization
+	DeviceVerificationURI string `yaml:"deviceVerificationURI"`
 }
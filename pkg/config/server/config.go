@@ -16,4 +16,10 @@ package server
 
 type Config struct {
 	Port int `yaml:"port"`
+	// TrustedProxies are the IPs or CIDRs of the reverse proxies in front of horizon, such as a load balancer.
+	// The client IP is taken from X-Forwarded-For or X-Real-IP only if the request comes from a trusted proxy,
+	// otherwise the remote address is used, so that the headers cannot be spoofed by clients.
+	// If unset, every proxy is trusted as before, then the allowed networks of access tokens can be bypassed
+	// by clients setting these headers, so it should be set when tokens are restricted by networks.
+	TrustedProxies []string `yaml:"trustedProxies"`
 }
@@ -0,0 +1,74 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/config/tekton"
+)
+
+const (
+	// PolicyModeDisabled disallows opening terminals
+	PolicyModeDisabled = "disabled"
+	// PolicyModeReadOnly only allows the commands in allowlist
+	PolicyModeReadOnly = "readonly"
+	// PolicyModeJIT requires a time-boxed access granted by an approver
+	PolicyModeJIT = "jit"
+)
+
+type Config struct {
+	Recording Recording `yaml:"recording"`
+	// Policies are terminal policies keyed by environment name,
+	// terminals of environments without policy are unrestricted
+	Policies map[string]Policy `yaml:"policies"`
+	Debug    Debug             `yaml:"debug"`
+}
+
+// Policy restricts terminals of an environment
+type Policy struct {
+	// Mode is one of disabled, readonly and jit, empty means unrestricted
+	Mode string `yaml:"mode"`
+	// AllowedCommands are the commands allowed in readonly mode, such as "ls" or "tail -f".
+	// Commands are compared with the path typed by user, "/bin/ls" should be listed to allow it.
+	AllowedCommands []string `yaml:"allowedCommands"`
+	// IdleTimeout disconnects the terminal when there is no input for the duration, zero means never
+	IdleTimeout time.Duration `yaml:"idleTimeout"`
+	// MaxGrantDuration is the max duration of access granted in jit mode
+	MaxGrantDuration time.Duration `yaml:"maxGrantDuration"`
+}
+
+// Debug configures ephemeral debug containers, which are attached to pods without shell
+type Debug struct {
+	// Images are the debug images approved by admin, the first one is the default.
+	// Debug containers are disabled if there is no image.
+	Images []string `yaml:"images"`
+	// Timeout is how long to wait for the debug container to be running, default is 1 minute
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// PolicyOf returns the policy of the environment
+func (c Config) PolicyOf(environment string) Policy {
+	return c.Policies[environment]
+}
+
+// Recording records terminal sessions in asciicast format to s3
+type Recording struct {
+	Enabled bool `yaml:"enabled"`
+	// Storage is the s3 storage of recordings, which has the same format as logStorage of tekton
+	Storage tekton.LogStorage `yaml:"storage"`
+	// Retention is how long the recordings are kept, recordings are kept forever if it's zero
+	Retention time.Duration `yaml:"retention"`
+}
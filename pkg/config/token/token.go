@@ -21,4 +21,14 @@ type Config struct {
 	JwtSigningKey string `yaml:"jwtSigningKey"`
 	// CallbackTokenExpireIn is the expiration time of token for tekton callback
 	CallbackTokenExpireIn time.Duration `yaml:"callbackTokenExpireIn"`
+	// ExpiryNotification notifies owners of access tokens before the tokens expire
+	ExpiryNotification ExpiryNotification `yaml:"expiryNotification"`
+}
+
+type ExpiryNotification struct {
+	Enabled bool `yaml:"enabled"`
+	// DaysBefore is how many days before the expiration owners are notified
+	DaysBefore int `yaml:"daysBefore"`
+	// Interval is the interval of checking tokens
+	Interval time.Duration `yaml:"interval"`
 }
@@ -0,0 +1,103 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/customrole/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, role *models.CustomRole) (*models.CustomRole, error)
+	GetByName(ctx context.Context, name string) (*models.CustomRole, error)
+	List(ctx context.Context) ([]*models.CustomRole, error)
+	Update(ctx context.Context, role *models.CustomRole) (*models.CustomRole, error)
+	DeleteByName(ctx context.Context, name string) error
+	CountMembers(ctx context.Context, name string) (int64, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, role *models.CustomRole) (*models.CustomRole, error) {
+	if err := d.db.WithContext(ctx).Create(role).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.CustomRoleInDB, err.Error())
+	}
+	return role, nil
+}
+
+func (d *dao) GetByName(ctx context.Context, name string) (*models.CustomRole, error) {
+	var role models.CustomRole
+	if err := d.db.WithContext(ctx).Where("name = ?", name).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.CustomRoleInDB,
+				fmt.Sprintf("role with name = %s was not found", name))
+		}
+		return nil, herrors.NewErrGetFailed(herrors.CustomRoleInDB, err.Error())
+	}
+	return &role, nil
+}
+
+func (d *dao) List(ctx context.Context) ([]*models.CustomRole, error) {
+	var roles []*models.CustomRole
+	if err := d.db.WithContext(ctx).Order("name asc").Find(&roles).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.CustomRoleInDB, err.Error())
+	}
+	return roles, nil
+}
+
+func (d *dao) Update(ctx context.Context, role *models.CustomRole) (*models.CustomRole, error) {
+	// use map to update zero values, e.g. description = ''
+	if err := d.db.WithContext(ctx).Model(&models.CustomRole{}).Where("id = ?", role.ID).
+		Updates(map[string]interface{}{
+			"description": role.Description,
+			"base_role":   role.BaseRole,
+			"rules":       role.Rules,
+			"updated_by":  role.UpdatedBy,
+		}).Error; err != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.CustomRoleInDB, err.Error())
+	}
+	return d.GetByName(ctx, role.Name)
+}
+
+func (d *dao) DeleteByName(ctx context.Context, name string) error {
+	if err := d.db.WithContext(ctx).Where("name = ?", name).
+		Delete(&models.CustomRole{}).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.CustomRoleInDB, err.Error())
+	}
+	return nil
+}
+
+// CountMembers counts the members granted the role
+func (d *dao) CountMembers(ctx context.Context, name string) (int64, error) {
+	var count int64
+	if err := d.db.WithContext(ctx).Model(&membermodels.Member{}).Where("role = ?", name).
+		Count(&count).Error; err != nil {
+		return 0, herrors.NewErrGetFailed(herrors.MemberInfoInDB, err.Error())
+	}
+	return count, nil
+}
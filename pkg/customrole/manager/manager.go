@@ -0,0 +1,68 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/customrole/dao"
+	"github.com/horizoncd/horizon/pkg/customrole/models"
+)
+
+type Manager interface {
+	Create(ctx context.Context, role *models.CustomRole) (*models.CustomRole, error)
+	GetByName(ctx context.Context, name string) (*models.CustomRole, error)
+	// List lists all the custom roles ordered by name
+	List(ctx context.Context) ([]*models.CustomRole, error)
+	// Update updates desc, base role and rules of the role, name can't be changed
+	Update(ctx context.Context, role *models.CustomRole) (*models.CustomRole, error)
+	DeleteByName(ctx context.Context, name string) error
+	// CountMembers counts the members granted the role
+	CountMembers(ctx context.Context, name string) (int64, error)
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Create(ctx context.Context, role *models.CustomRole) (*models.CustomRole, error) {
+	return m.dao.Create(ctx, role)
+}
+
+func (m *manager) GetByName(ctx context.Context, name string) (*models.CustomRole, error) {
+	return m.dao.GetByName(ctx, name)
+}
+
+func (m *manager) List(ctx context.Context) ([]*models.CustomRole, error) {
+	return m.dao.List(ctx)
+}
+
+func (m *manager) Update(ctx context.Context, role *models.CustomRole) (*models.CustomRole, error) {
+	return m.dao.Update(ctx, role)
+}
+
+func (m *manager) DeleteByName(ctx context.Context, name string) error {
+	return m.dao.DeleteByName(ctx, name)
+}
+
+func (m *manager) CountMembers(ctx context.Context, name string) (int64, error) {
+	return m.dao.CountMembers(ctx, name)
+}
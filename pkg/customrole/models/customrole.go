@@ -0,0 +1,78 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+// CustomRole is a role defined through api, it's served together with the built-in roles from file
+type CustomRole struct {
+	global.Model
+
+	Name        string
+	Description string
+	// BaseRole is the built-in role which the custom role ranks just below,
+	// rules of the custom role must not exceed the rules of it
+	BaseRole  string
+	Rules     Rules `gorm:"column:rules"`
+	CreatedBy uint
+	UpdatedBy uint
+}
+
+func (r *CustomRole) ToRole() types.Role {
+	return types.Role{
+		Name:        r.Name,
+		Desc:        r.Description,
+		PolicyRules: r.Rules,
+	}
+}
+
+// Rules is stored as json in db
+type Rules []types.PolicyRule
+
+func (r *Rules) Scan(value interface{}) error {
+	var bts []byte
+	switch v := value.(type) {
+	case []byte:
+		bts = v
+	case string:
+		bts = []byte(v)
+	case nil:
+		return nil
+	default:
+		return fmt.Errorf("failed to unmarshal Rules from value: %v", value)
+	}
+	if len(bts) == 0 {
+		return nil
+	}
+	return json.Unmarshal(bts, r)
+}
+
+func (r Rules) Value() (driver.Value, error) {
+	if r == nil {
+		return "[]", nil
+	}
+	bts, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(bts), nil
+}
@@ -0,0 +1,87 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/dora/models"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+)
+
+// _commitBatchSize is the max number of commits in one query
+const _commitBatchSize = 500
+
+type DAO interface {
+	// ListDeployments lists the successful deployments selected by query
+	ListDeployments(ctx context.Context, query *models.Query) ([]*models.Deployment, error)
+	// ListCommitBuilds lists the pipelineruns which built the commits
+	ListCommitBuilds(ctx context.Context, commits []string) ([]*models.CommitBuild, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) ListDeployments(ctx context.Context, query *models.Query) ([]*models.Deployment, error) {
+	statement := d.db.WithContext(ctx).Table("tb_pipelinerun as pr").
+		Select("pr.id as pipelinerun_id, pr.cluster_id, c.application_id, c.environment_name as environment, "+
+			"pr.action, pr.git_commit, pr.finished_at").
+		Joins("join tb_cluster as c on c.id = pr.cluster_id").
+		Where("pr.action in ?", []string{prmodels.ActionBuildDeploy, prmodels.ActionDeploy, prmodels.ActionRollback}).
+		Where("pr.status = ?", string(prmodels.StatusOK)).
+		Where("pr.finished_at >= ? and pr.finished_at < ?", query.Start, query.End)
+	if query.ApplicationIDs != nil {
+		statement = statement.Where("c.application_id in ?", query.ApplicationIDs)
+	}
+	if query.Environment != "" {
+		statement = statement.Where("c.environment_name = ?", query.Environment)
+	}
+
+	var deployments []*models.Deployment
+	if err := statement.Scan(&deployments).Error; err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.PipelinerunInDB, err.Error())
+	}
+	return deployments, nil
+}
+
+func (d *dao) ListCommitBuilds(ctx context.Context, commits []string) ([]*models.CommitBuild, error) {
+	builds := make([]*models.CommitBuild, 0)
+	// commits are queried in batches to keep the size of statement bounded
+	for start := 0; start < len(commits); start += _commitBatchSize {
+		end := start + _commitBatchSize
+		if end > len(commits) {
+			end = len(commits)
+		}
+		var batch []*models.CommitBuild
+		if err := d.db.WithContext(ctx).Table("tb_pipelinerun as pr").
+			Select("c.application_id, pr.git_commit, pr.created_at").
+			Joins("join tb_cluster as c on c.id = pr.cluster_id").
+			Where("pr.action = ?", prmodels.ActionBuildDeploy).
+			Where("pr.git_commit in ?", commits[start:end]).
+			Scan(&batch).Error; err != nil {
+			return nil, herrors.NewErrGetFailed(herrors.PipelinerunInDB, err.Error())
+		}
+		builds = append(builds, batch...)
+	}
+	return builds, nil
+}
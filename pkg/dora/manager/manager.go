@@ -0,0 +1,84 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/dora/dao"
+	"github.com/horizoncd/horizon/pkg/dora/models"
+)
+
+type Manager interface {
+	// ListDeployments lists the successful deployments selected by query,
+	// with the time when their commits were first built
+	ListDeployments(ctx context.Context, query *models.Query) ([]*models.Deployment, error)
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type commitKey struct {
+	applicationID uint
+	commit        string
+}
+
+func (m *manager) ListDeployments(ctx context.Context, query *models.Query) ([]*models.Deployment, error) {
+	deployments, err := m.dao.ListDeployments(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	commitSet := make(map[string]struct{})
+	commits := make([]string, 0)
+	for _, d := range deployments {
+		if d.GitCommit == "" {
+			continue
+		}
+		if _, ok := commitSet[d.GitCommit]; !ok {
+			commitSet[d.GitCommit] = struct{}{}
+			commits = append(commits, d.GitCommit)
+		}
+	}
+	builds, err := m.dao.ListCommitBuilds(ctx, commits)
+	if err != nil {
+		return nil, err
+	}
+
+	firstSeen := make(map[commitKey]time.Time)
+	for _, b := range builds {
+		key := commitKey{applicationID: b.ApplicationID, commit: b.GitCommit}
+		if t, ok := firstSeen[key]; !ok || b.CreatedAt.Before(t) {
+			firstSeen[key] = b.CreatedAt
+		}
+	}
+	for _, d := range deployments {
+		if t, ok := firstSeen[commitKey{applicationID: d.ApplicationID, commit: d.GitCommit}]; ok {
+			t := t
+			d.CommitFirstSeenAt = &t
+		}
+	}
+	return deployments, nil
+}
@@ -0,0 +1,122 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"sort"
+	"time"
+
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+)
+
+// Deployment is a successful pipelinerun which changed the running version of a cluster,
+// its action is one of builddeploy, deploy and rollback
+type Deployment struct {
+	PipelinerunID uint
+	ClusterID     uint
+	ApplicationID uint
+	Environment   string
+	Action        string
+	GitCommit     string
+	FinishedAt    time.Time
+	// CommitFirstSeenAt is the time when the commit was first built by horizon in the application,
+	// it's used as the commit time to compute lead time. It's nil when the commit is unknown.
+	CommitFirstSeenAt *time.Time
+}
+
+// CommitBuild is a pipelinerun which built the commit in the application
+type CommitBuild struct {
+	ApplicationID uint
+	GitCommit     string
+	CreatedAt     time.Time
+}
+
+// Query selects the deployments which finished in [Start, End)
+type Query struct {
+	// ApplicationIDs filters the applications, all applications are selected if it's nil
+	ApplicationIDs []uint
+	// Environment filters the environment of clusters, all environments are selected if it's empty
+	Environment string
+	Start       time.Time
+	End         time.Time
+}
+
+// Metrics are the four key metrics of software delivery performance defined by DORA
+type Metrics struct {
+	// Deployments is the number of deployments which change the code or config, rollbacks are not included
+	Deployments int `json:"deployments"`
+	// DeploymentFrequency is the number of deployments per day
+	DeploymentFrequency float64 `json:"deploymentFrequency"`
+	// LeadTimeSeconds is the median of durations from commit to deployment
+	LeadTimeSeconds float64 `json:"leadTimeSeconds"`
+	// FailedDeployments is the number of deployments followed by a rollback
+	FailedDeployments int `json:"failedDeployments"`
+	// ChangeFailureRate is the rate of deployments followed by a rollback
+	ChangeFailureRate float64 `json:"changeFailureRate"`
+	// TimeToRestoreSeconds is the median of durations from a failed deployment to its rollback
+	TimeToRestoreSeconds float64 `json:"timeToRestoreSeconds"`
+}
+
+// Calculate computes metrics of the deployments finished in [start, end)
+func Calculate(deployments []*Deployment, start, end time.Time) *Metrics {
+	metrics := &Metrics{}
+
+	byCluster := make(map[uint][]*Deployment)
+	for _, d := range deployments {
+		byCluster[d.ClusterID] = append(byCluster[d.ClusterID], d)
+	}
+
+	var leadTimes, restoreTimes []float64
+	for _, ds := range byCluster {
+		sort.Slice(ds, func(i, j int) bool {
+			return ds[i].FinishedAt.Before(ds[j].FinishedAt)
+		})
+		for i, d := range ds {
+			if d.Action == prmodels.ActionRollback {
+				continue
+			}
+			metrics.Deployments++
+			if d.GitCommit != "" && d.CommitFirstSeenAt != nil && !d.CommitFirstSeenAt.After(d.FinishedAt) {
+				leadTimes = append(leadTimes, d.FinishedAt.Sub(*d.CommitFirstSeenAt).Seconds())
+			}
+			if i+1 < len(ds) && ds[i+1].Action == prmodels.ActionRollback {
+				metrics.FailedDeployments++
+				restoreTimes = append(restoreTimes, ds[i+1].FinishedAt.Sub(d.FinishedAt).Seconds())
+			}
+		}
+	}
+
+	if days := end.Sub(start).Hours() / 24; days > 0 {
+		metrics.DeploymentFrequency = float64(metrics.Deployments) / days
+	}
+	if metrics.Deployments > 0 {
+		metrics.ChangeFailureRate = float64(metrics.FailedDeployments) / float64(metrics.Deployments)
+	}
+	metrics.LeadTimeSeconds = median(leadTimes)
+	metrics.TimeToRestoreSeconds = median(restoreTimes)
+	return metrics
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
@@ -0,0 +1,60 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+)
+
+func TestCalculate(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(10 * 24 * time.Hour)
+	at := func(hours int) time.Time {
+		return start.Add(time.Duration(hours) * time.Hour)
+	}
+	atPtr := func(hours int) *time.Time {
+		tm := at(hours)
+		return &tm
+	}
+
+	deployments := []*Deployment{
+		// cluster 1: deploy, deploy and rollback, deploy
+		{ClusterID: 1, Action: prmodels.ActionBuildDeploy, GitCommit: "a",
+			FinishedAt: at(2), CommitFirstSeenAt: atPtr(0)},
+		{ClusterID: 1, Action: prmodels.ActionRollback, FinishedAt: at(30)},
+		{ClusterID: 1, Action: prmodels.ActionBuildDeploy, GitCommit: "b",
+			FinishedAt: at(26), CommitFirstSeenAt: atPtr(20)},
+		{ClusterID: 1, Action: prmodels.ActionDeploy, FinishedAt: at(50)},
+		// cluster 2: deploy with unknown commit time
+		{ClusterID: 2, Action: prmodels.ActionBuildDeploy, GitCommit: "c", FinishedAt: at(5)},
+	}
+
+	metrics := Calculate(deployments, start, end)
+	assert.Equal(t, 4, metrics.Deployments)
+	assert.Equal(t, 0.4, metrics.DeploymentFrequency)
+	// median of 2h and 6h
+	assert.Equal(t, float64(4*3600), metrics.LeadTimeSeconds)
+	assert.Equal(t, 1, metrics.FailedDeployments)
+	assert.Equal(t, 0.25, metrics.ChangeFailureRate)
+	assert.Equal(t, float64(4*3600), metrics.TimeToRestoreSeconds)
+
+	metrics = Calculate(nil, start, end)
+	assert.Equal(t, &Metrics{}, metrics)
+}
@@ -32,7 +32,7 @@ import (
 )
 
 var (
-	db, _        = orm.NewSqliteDB("")
+	db, _        = orm.NewTestDB()
 	regionMgr    = regionmanager.New(db)
 	ctx          context.Context
 	mgr          = New(db)
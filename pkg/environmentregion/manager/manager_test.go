@@ -32,7 +32,7 @@ import (
 )
 
 var (
-	db, _     = orm.NewSqliteDB("")
+	db, _     = orm.NewTestDB()
 	ctx       context.Context
 	regionMgr = regionmanager.New(db)
 	envMgr    = envmanager.New(db)
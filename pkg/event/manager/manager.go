@@ -143,6 +143,24 @@ var supportedEvents = map[string]string{
 	models.PipelinerunCreated:     "New pipelinerun has been created",
 	models.PipelinerunCancelled:   "Pipelinerun has been cancelled",
 	models.PipelinerunExecuted:    "Pipelinerun has been executed",
+	models.PipelinerunFinished:    "Pipelinerun has finished with a result",
+
+	models.ClusterTerminalAccessRequested: "Terminal access of cluster has been requested",
+	models.ClusterTerminalAccessApproved:  "Terminal access of cluster has been approved",
+	models.ClusterTerminalAccessRejected:  "Terminal access of cluster has been rejected",
+	models.ClusterTerminalAccessRevoked:   "Terminal access of cluster has been revoked",
+	models.ClusterTerminalDisconnected:    "Terminal of cluster has been disconnected forcibly",
+	models.ClusterDebugContainerCreated:   "Ephemeral debug container has been attached to a pod of cluster",
+
+	models.ApplicationAccessTokenExpiring: "Access token of application is about to expire",
+	models.ClusterAccessTokenExpiring:     "Access token of cluster is about to expire",
+
+	models.ClusterSecretCreated: "Secret of cluster has been created",
+	models.ClusterSecretRotated: "Secret of cluster has been rotated",
+	models.ClusterSecretDeleted: "Secret of cluster has been deleted",
+
+	models.ClusterDrifted:       "Live objects of cluster have drifted from gitops repo",
+	models.ClusterDriftResolved: "Drift of cluster has been resolved",
 }
 
 func (m *manager) ListSupportEvents() map[string]string {
@@ -44,6 +44,28 @@ const (
 	PipelinerunCreated     string = "pipelineruns_created"
 	PipelinerunCancelled   string = "pipelineruns_cancelled"
 	PipelinerunExecuted    string = "pipelineruns_executed"
+	PipelinerunFinished    string = "pipelineruns_finished"
+
+	// terminal access of clusters, which are for auditing
+	ClusterTerminalAccessRequested string = "clusters_terminal_access_requested"
+	ClusterTerminalAccessApproved  string = "clusters_terminal_access_approved"
+	ClusterTerminalAccessRejected  string = "clusters_terminal_access_rejected"
+	ClusterTerminalAccessRevoked   string = "clusters_terminal_access_revoked"
+	ClusterTerminalDisconnected    string = "clusters_terminal_disconnected"
+	ClusterDebugContainerCreated   string = "clusters_debug_container_created"
+
+	// resource access tokens which will expire soon
+	ApplicationAccessTokenExpiring string = "applications_access_token_expiring"
+	ClusterAccessTokenExpiring     string = "clusters_access_token_expiring"
+
+	// secrets of clusters, which are for auditing
+	ClusterSecretCreated string = "clusters_secret_created"
+	ClusterSecretRotated string = "clusters_secret_rotated"
+	ClusterSecretDeleted string = "clusters_secret_deleted"
+
+	// configuration drift between gitops repo and live objects of clusters
+	ClusterDrifted       string = "clusters_drifted"
+	ClusterDriftResolved string = "clusters_drift_resolved"
 	// TODO: add group events
 )
 
@@ -0,0 +1,263 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitstatus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	"github.com/horizoncd/horizon/pkg/cluster/code"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	gitstatusconfig "github.com/horizoncd/horizon/pkg/config/gitstatus"
+	"github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/git"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	contextPrefix = "horizon"
+	maxAttempts   = 3
+)
+
+// Reporter reports commit statuses and deployments of pipelineruns back to the source git host by events
+type Reporter struct {
+	config         gitstatusconfig.Config
+	gitGetter      code.GitGetter
+	applicationMgr applicationmanager.Manager
+	clusterMgr     clustermanager.Manager
+	prMgr          *prmanager.PRManager
+	retryInterval  time.Duration
+}
+
+func NewReporter(config gitstatusconfig.Config, manager *managerparam.Manager,
+	gitGetter code.GitGetter) *Reporter {
+	return &Reporter{
+		config:         config,
+		gitGetter:      gitGetter,
+		applicationMgr: manager.ApplicationMgr,
+		clusterMgr:     manager.ClusterMgr,
+		prMgr:          manager.PRMgr,
+		retryInterval:  time.Second,
+	}
+}
+
+type urlParams struct {
+	PipelinerunID uint
+	ClusterID     uint
+	Cluster       string
+	Application   string
+	Environment   string
+}
+
+// target contains everything needed to report a pipelinerun to git host
+type target struct {
+	pipelinerun *prmodels.Pipelinerun
+	cluster     *clustermodels.Cluster
+	application string
+}
+
+// Process reports the pipelinerun and cluster events to git host.
+// Failures are retried and logged here, so that they never block the event cursor.
+func (r *Reporter) Process(ctx context.Context, events []*models.Event, _ bool) error {
+	for _, event := range events {
+		switch event.ResourceType {
+		case common.ResourcePipelinerun:
+			r.processPipelinerunEvent(ctx, event)
+		case common.ResourceCluster:
+			r.processClusterEvent(ctx, event)
+		}
+	}
+	return nil
+}
+
+func (r *Reporter) processPipelinerunEvent(ctx context.Context, event *models.Event) {
+	var state string
+	switch event.EventType {
+	case models.PipelinerunCreated:
+		state = git.CommitStatePending
+	case models.PipelinerunExecuted:
+		state = git.CommitStateRunning
+	case models.PipelinerunCancelled:
+		state = git.CommitStateCanceled
+	case models.PipelinerunFinished:
+	default:
+		return
+	}
+
+	pr, err := r.prMgr.PipelineRun.GetByID(ctx, event.ResourceID)
+	if err != nil {
+		log.Warningf(ctx, "failed to get pipelinerun %d, err: %v", event.ResourceID, err)
+		return
+	}
+	if event.EventType == models.PipelinerunFinished {
+		state = finishedState(pr.Status)
+		if state == "" {
+			return
+		}
+	}
+
+	t := r.getTarget(ctx, pr)
+	if t == nil {
+		return
+	}
+	r.retry(ctx, fmt.Sprintf("commit status of pipelinerun %d", pr.ID), func() error {
+		return r.gitGetter.CreateCommitStatus(ctx, pr.GitURL, &git.CommitStatus{
+			Commit:      pr.GitCommit,
+			Ref:         refOfPipelinerun(pr),
+			State:       state,
+			Context:     statusContext(t.cluster),
+			Description: fmt.Sprintf("%s %s", pr.Action, state),
+			TargetURL:   r.renderURL(ctx, t),
+		})
+	})
+
+	// successful deployments are reported by cluster events, only failures are reported here
+	if state == git.CommitStateFailed {
+		r.reportDeployment(ctx, t, git.CommitStateFailed)
+	}
+}
+
+func (r *Reporter) processClusterEvent(ctx context.Context, event *models.Event) {
+	switch event.EventType {
+	case models.ClusterBuildDeployed, models.ClusterDeployed, models.ClusterRollbacked:
+	default:
+		return
+	}
+	if event.Extra == nil {
+		return
+	}
+	var extra clustermodels.ClusterEventExtra
+	if err := json.Unmarshal([]byte(*event.Extra), &extra); err != nil {
+		log.Warningf(ctx, "failed to unmarshal extra of event %d, err: %v", event.ID, err)
+		return
+	}
+	if extra.Pipelinerun == nil {
+		return
+	}
+	t := r.getTarget(ctx, extra.Pipelinerun)
+	if t == nil {
+		return
+	}
+	r.reportDeployment(ctx, t, git.CommitStateSuccess)
+}
+
+func (r *Reporter) reportDeployment(ctx context.Context, t *target, state string) {
+	pr := t.pipelinerun
+	refType, ref := pr.GitRefType, pr.GitRef
+	if refType == code.GitRefTypeCommit {
+		refType, ref = "", ""
+	}
+	r.retry(ctx, fmt.Sprintf("deployment of pipelinerun %d", pr.ID), func() error {
+		return r.gitGetter.CreateDeployment(ctx, pr.GitURL, &git.Deployment{
+			Commit:      pr.GitCommit,
+			RefType:     refType,
+			Ref:         ref,
+			Environment: fmt.Sprintf("%s/%s", t.cluster.EnvironmentName, t.cluster.Name),
+			State:       state,
+			Description: fmt.Sprintf("%s of cluster %s", pr.Action, t.cluster.Name),
+			TargetURL:   r.renderURL(ctx, t),
+		})
+	})
+}
+
+// getTarget returns nil if the pipelinerun can not be reported
+func (r *Reporter) getTarget(ctx context.Context, pr *prmodels.Pipelinerun) *target {
+	if pr.GitURL == "" || pr.GitCommit == "" {
+		return nil
+	}
+	cluster, err := r.clusterMgr.GetByIDIncludeSoftDelete(ctx, pr.ClusterID)
+	if err != nil {
+		log.Warningf(ctx, "failed to get cluster %d, err: %v", pr.ClusterID, err)
+		return nil
+	}
+	t := &target{pipelinerun: pr, cluster: cluster}
+	app, err := r.applicationMgr.GetByIDIncludeSoftDelete(ctx, cluster.ApplicationID)
+	if err != nil {
+		log.Warningf(ctx, "failed to get application %d, err: %v", cluster.ApplicationID, err)
+	} else {
+		t.application = app.Name
+	}
+	return t
+}
+
+func (r *Reporter) renderURL(ctx context.Context, t *target) string {
+	if r.config.PipelinerunURLTemplate == "" {
+		return ""
+	}
+	tmpl, err := template.New("pipelinerunURL").Parse(r.config.PipelinerunURLTemplate)
+	if err != nil {
+		log.Warningf(ctx, "failed to parse pipelinerun url template: %v", err)
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, &urlParams{
+		PipelinerunID: t.pipelinerun.ID,
+		ClusterID:     t.cluster.ID,
+		Cluster:       t.cluster.Name,
+		Application:   t.application,
+		Environment:   t.cluster.EnvironmentName,
+	}); err != nil {
+		log.Warningf(ctx, "failed to render pipelinerun url template: %v", err)
+		return ""
+	}
+	return buf.String()
+}
+
+func (r *Reporter) retry(ctx context.Context, what string, f func() error) {
+	var err error
+	for i := 0; i < maxAttempts; i++ {
+		if i > 0 {
+			time.Sleep(r.retryInterval * time.Duration(i))
+		}
+		if err = f(); err == nil {
+			return
+		}
+	}
+	log.Errorf(ctx, "failed to report %s after %d attempts, err: %v", what, maxAttempts, err)
+}
+
+func finishedState(status string) string {
+	switch prmodels.PipelineStatus(status) {
+	case prmodels.StatusOK:
+		return git.CommitStateSuccess
+	case prmodels.StatusFailed:
+		return git.CommitStateFailed
+	case prmodels.StatusCancelled:
+		return git.CommitStateCanceled
+	default:
+		return ""
+	}
+}
+
+func refOfPipelinerun(pr *prmodels.Pipelinerun) string {
+	if pr.GitRefType == code.GitRefTypeCommit {
+		return ""
+	}
+	return pr.GitRef
+}
+
+func statusContext(cluster *clustermodels.Cluster) string {
+	return fmt.Sprintf("%s/%s/%s", contextPrefix, cluster.EnvironmentName, cluster.Name)
+}
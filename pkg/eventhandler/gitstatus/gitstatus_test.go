@@ -0,0 +1,169 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitstatus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	codemock "github.com/horizoncd/horizon/mock/pkg/cluster/code"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	gitstatusconfig "github.com/horizoncd/horizon/pkg/config/gitstatus"
+	"github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/git"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+)
+
+func TestReporter(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	err = db.AutoMigrate(&appmodels.Application{}, &clustermodels.Cluster{}, &prmodels.Pipelinerun{},
+		&membermodels.Member{})
+	assert.Nil(t, err)
+
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "tony",
+		ID:   1,
+	})
+	mgr := managerparam.InitManager(db)
+
+	app := &appmodels.Application{Name: "app"}
+	assert.Nil(t, db.Create(app).Error)
+	cluster, err := mgr.ClusterMgr.Create(ctx, &clustermodels.Cluster{
+		ApplicationID:   app.ID,
+		Name:            "app-test",
+		EnvironmentName: "test",
+	}, nil, nil)
+	assert.Nil(t, err)
+	gitURL := "ssh://git@github.com/horizoncd/horizon.git"
+	pr, err := mgr.PRMgr.PipelineRun.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID:  cluster.ID,
+		Action:     prmodels.ActionBuildDeploy,
+		Status:     string(prmodels.StatusFailed),
+		GitURL:     gitURL,
+		GitRefType: git.GitRefTypeBranch,
+		GitRef:     "master",
+		GitCommit:  "abc",
+	})
+	assert.Nil(t, err)
+	// pipelinerun without git info is not reported
+	prWithoutGit, err := mgr.PRMgr.PipelineRun.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: cluster.ID,
+		Action:    prmodels.ActionRestart,
+		Status:    string(prmodels.StatusOK),
+	})
+	assert.Nil(t, err)
+
+	mockCtl := gomock.NewController(t)
+	gitGetter := codemock.NewMockGitGetter(mockCtl)
+	reporter := NewReporter(gitstatusconfig.Config{
+		Enabled:                true,
+		PipelinerunURLTemplate: "https://horizon.org/clusters/{{ .ClusterID }}/pipelineruns/{{ .PipelinerunID }}",
+	}, mgr, gitGetter)
+	reporter.retryInterval = 0
+
+	expectedContext := "horizon/test/app-test"
+	expectedURL := "https://horizon.org/clusters/1/pipelineruns/1"
+	gitGetter.EXPECT().CreateCommitStatus(ctx, gitURL, &git.CommitStatus{
+		Commit:      "abc",
+		Ref:         "master",
+		State:       git.CommitStateRunning,
+		Context:     expectedContext,
+		Description: "builddeploy running",
+		TargetURL:   expectedURL,
+	}).Return(nil).Times(1)
+	// failures are retried
+	gitGetter.EXPECT().CreateCommitStatus(ctx, gitURL, &git.CommitStatus{
+		Commit:      "abc",
+		Ref:         "master",
+		State:       git.CommitStateFailed,
+		Context:     expectedContext,
+		Description: "builddeploy failed",
+		TargetURL:   expectedURL,
+	}).Return(errors.New("unavailable")).Times(1)
+	gitGetter.EXPECT().CreateCommitStatus(ctx, gitURL, gomock.Any()).Return(nil).Times(1)
+	gitGetter.EXPECT().CreateDeployment(ctx, gitURL, &git.Deployment{
+		Commit:      "abc",
+		RefType:     git.GitRefTypeBranch,
+		Ref:         "master",
+		Environment: "test/app-test",
+		State:       git.CommitStateFailed,
+		Description: "builddeploy of cluster app-test",
+		TargetURL:   expectedURL,
+	}).Return(nil).Times(1)
+	gitGetter.EXPECT().CreateDeployment(ctx, gitURL, &git.Deployment{
+		Commit:      "abc",
+		RefType:     git.GitRefTypeBranch,
+		Ref:         "master",
+		Environment: "test/app-test",
+		State:       git.CommitStateSuccess,
+		Description: "builddeploy of cluster app-test",
+		TargetURL:   expectedURL,
+	}).Return(nil).Times(1)
+
+	extraBytes, err := json.Marshal(&clustermodels.ClusterEventExtra{Pipelinerun: pr})
+	assert.Nil(t, err)
+	extra := string(extraBytes)
+	events := []*models.Event{
+		{
+			EventSummary: models.EventSummary{
+				ResourceType: common.ResourcePipelinerun,
+				ResourceID:   pr.ID,
+				EventType:    models.PipelinerunExecuted,
+			},
+		},
+		{
+			EventSummary: models.EventSummary{
+				ResourceType: common.ResourcePipelinerun,
+				ResourceID:   pr.ID,
+				EventType:    models.PipelinerunFinished,
+			},
+		},
+		{
+			EventSummary: models.EventSummary{
+				ResourceType: common.ResourcePipelinerun,
+				ResourceID:   prWithoutGit.ID,
+				EventType:    models.PipelinerunFinished,
+			},
+		},
+		{
+			EventSummary: models.EventSummary{
+				ResourceType: common.ResourceCluster,
+				ResourceID:   cluster.ID,
+				EventType:    models.ClusterBuildDeployed,
+				Extra:        &extra,
+			},
+		},
+		{
+			EventSummary: models.EventSummary{
+				ResourceType: common.ResourceCluster,
+				ResourceID:   cluster.ID,
+				EventType:    models.ClusterUpdated,
+			},
+		},
+	}
+	assert.Nil(t, reporter.Process(ctx, events, false))
+}
@@ -0,0 +1,97 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quotausage
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/quota/clusterusage"
+	quotamanager "github.com/horizoncd/horizon/pkg/quota/manager"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// Refresher refreshes resources requested by clusters by cluster events,
+// which are summed up to check quotas of groups and environments
+type Refresher struct {
+	recorder   *clusterusage.Recorder
+	quotaMgr   quotamanager.Manager
+	clusterMgr clustermanager.Manager
+}
+
+func NewRefresher(calculator *clusterusage.Calculator, manager *managerparam.Manager) *Refresher {
+	return &Refresher{
+		recorder:   clusterusage.NewRecorder(calculator, manager),
+		quotaMgr:   manager.QuotaMgr,
+		clusterMgr: manager.ClusterMgr,
+	}
+}
+
+// Process refreshes usage of clusters whose values are changed, and deletes usage of clusters deleted or freed.
+// Failures are logged here, so that they never block the event cursor.
+func (r *Refresher) Process(ctx context.Context, events []*models.Event, _ bool) error {
+	for _, event := range events {
+		if event.ResourceType != common.ResourceCluster {
+			continue
+		}
+		switch event.EventType {
+		case models.ClusterCreated, models.ClusterUpdated, models.ClusterRollbacked:
+			r.refresh(ctx, event.ResourceID)
+		case models.ClusterDeleted, models.ClusterFreed:
+			if err := r.quotaMgr.DeleteClusterUsage(ctx, event.ResourceID); err != nil {
+				log.Warningf(ctx, "failed to delete usage of cluster %d, err: %v", event.ResourceID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Run calculates usage of clusters created before quotas are enabled
+func (r *Refresher) Run(ctx context.Context) {
+	ids, err := r.quotaMgr.ListClusterIDsWithoutUsage(ctx)
+	if err != nil {
+		log.Errorf(ctx, "failed to list clusters without usage, err: %v", err)
+		return
+	}
+	for _, id := range ids {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		r.refresh(ctx, id)
+	}
+}
+
+func (r *Refresher) refresh(ctx context.Context, clusterID uint) {
+	cluster, err := r.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			log.Warningf(ctx, "failed to get cluster %d, err: %v", clusterID, err)
+		}
+		return
+	}
+	if cluster.Status == common.ClusterStatusFreed {
+		return
+	}
+	if err := r.recorder.Record(ctx, cluster); err != nil {
+		log.Warningf(ctx, "failed to record usage of cluster %d, err: %v", clusterID, err)
+	}
+}
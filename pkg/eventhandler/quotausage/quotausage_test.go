@@ -0,0 +1,124 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quotausage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	templaterepomock "github.com/horizoncd/horizon/mock/pkg/templaterepo"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/quota/clusterusage"
+	quotamodels "github.com/horizoncd/horizon/pkg/quota/models"
+)
+
+const _deploymentTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}
+spec:
+  replicas: {{ .Values.replicas }}
+  template:
+    spec:
+      containers:
+        - name: app
+          resources:
+            requests:
+              cpu: 500m
+              memory: 1Gi
+`
+
+func TestRefresher(t *testing.T) {
+	ctx := context.TODO()
+	db, err := orm.NewTestDB()
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&appmodels.Application{}, &clustermodels.Cluster{},
+		&quotamodels.ClusterUsage{}))
+	mgr := managerparam.InitManager(db)
+
+	app := &appmodels.Application{Name: "app"}
+	assert.Nil(t, db.Create(app).Error)
+	cluster1 := &clustermodels.Cluster{Name: "cluster1", ApplicationID: app.ID}
+	assert.Nil(t, db.Create(cluster1).Error)
+	cluster2 := &clustermodels.Cluster{Name: "cluster2", ApplicationID: app.ID}
+	assert.Nil(t, db.Create(cluster2).Error)
+	freed := &clustermodels.Cluster{Name: "freed", ApplicationID: app.ID, Status: common.ClusterStatusFreed}
+	assert.Nil(t, db.Create(freed).Error)
+
+	mockCtl := gomock.NewController(t)
+	templateRepo := templaterepomock.NewMockTemplateRepo(mockCtl)
+	templateRepo.EXPECT().GetChart("javaapp", "v1.0.0", gomock.Any()).Return(&chart.Chart{
+		Metadata:  &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "javaapp", Version: "v1.0.0"},
+		Templates: []*chart.File{{Name: "templates/deployment.yaml", Data: []byte(_deploymentTemplate)}},
+	}, nil).AnyTimes()
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	clusterGitRepo.EXPECT().DefaultBranch().Return("master").AnyTimes()
+	for cluster, replicas := range map[string]string{"cluster1": "2", "cluster2": "1"} {
+		clusterGitRepo.EXPECT().GetChartFiles(gomock.Any(), "app", cluster, "master").Return(&gitrepo.ChartFiles{
+			Chart: &gitrepo.Chart{Dependencies: []gitrepo.Dependency{{Name: "javaapp", Version: "v1.0.0"}}},
+			ValueFiles: []gitrepo.ChartValueFile{{
+				FileName: common.GitopsFileApplication,
+				Content:  []byte("javaapp:\n  replicas: " + replicas + "\n"),
+			}},
+		}, nil).AnyTimes()
+	}
+	refresher := NewRefresher(clusterusage.NewCalculator(templateRepo, clusterGitRepo), mgr)
+
+	assert.Nil(t, refresher.Process(ctx, []*models.Event{{
+		EventSummary: models.EventSummary{
+			ResourceType: common.ResourceCluster,
+			ResourceID:   cluster1.ID,
+			EventType:    models.ClusterCreated,
+		},
+	}}, false))
+	usage, err := mgr.QuotaMgr.GetClusterUsage(ctx, cluster1.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1000), usage.CPU)
+	assert.Equal(t, int64(2<<30), usage.Memory)
+	assert.Equal(t, 2, usage.Replicas)
+
+	// usage of clusters created before is calculated, except freed clusters
+	refresher.Run(ctx)
+	usage, err = mgr.QuotaMgr.GetClusterUsage(ctx, cluster2.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, usage.Replicas)
+	_, err = mgr.QuotaMgr.GetClusterUsage(ctx, freed.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	assert.Nil(t, refresher.Process(ctx, []*models.Event{{
+		EventSummary: models.EventSummary{
+			ResourceType: common.ResourceCluster,
+			ResourceID:   cluster1.ID,
+			EventType:    models.ClusterFreed,
+		},
+	}}, false))
+	_, err = mgr.QuotaMgr.GetClusterUsage(ctx, cluster1.ID)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
@@ -442,7 +442,7 @@ func (w *WebhookLogGenerator) Process(ctx context.Context, events []*models.Even
 	}
 	for _, dependencyMap := range conditionsToCreate {
 		for _, dependency := range dependencyMap {
-			headers, err := w.makeRequestHeaders(dependency.webhook.Secret)
+			headers, err := w.makeRequestHeaders(string(dependency.webhook.Secret))
 			if err != nil {
 				log.Errorf(ctx, fmt.Sprintf("failed to make headers, error: %+v", err))
 				continue
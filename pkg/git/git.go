@@ -16,6 +16,7 @@ package git
 
 import (
 	"context"
+	"net/http"
 	"regexp"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
@@ -34,6 +35,12 @@ type Helper interface {
 	GetHTTPLink(gitURL string) (string, error)
 	GetCommitHistoryLink(gitURL string, commit string) (string, error)
 	GetTagArchive(ctx context.Context, gitURL, tagName string) (*Tag, error)
+	// CreateMergeRequestComment to comment on the merge request (pull request) of a specified git URL
+	CreateMergeRequestComment(ctx context.Context, gitURL string, mrID int, body string) error
+	// CreateCommitStatus to publish the status of a commit
+	CreateCommitStatus(ctx context.Context, gitURL string, status *CommitStatus) error
+	// CreateDeployment to publish a deployment record of a commit
+	CreateDeployment(ctx context.Context, gitURL string, deployment *Deployment) error
 }
 
 type Constructor func(ctx context.Context, config *git.Repo) (Helper, error)
@@ -53,6 +60,43 @@ func NewHelper(ctx context.Context, config *git.Repo) (Helper, error) {
 	return nil, perror.Wrapf(herrors.ErrParamInvalid, "Repo initializes failed, kind = %v is not implement", config.Kind)
 }
 
+var regexHost = regexp.MustCompile(`^(?:https?:)?(?://)?(?:[^@\n]+@)?([^:/\n]+)`)
+
+// WebhookParser parses the webhook requests sent by git host
+type WebhookParser interface {
+	// Verify checks the webhook request with the secret configured in git host
+	Verify(header http.Header, body []byte, secret string) error
+	// ParsePushEvent parses the push event of branch or tag,
+	// nil is returned when the request is not a push event or the ref is deleted.
+	ParsePushEvent(header http.Header, body []byte) (*PushEvent, error)
+	// ParseMergeRequestEvent parses the event of merge request (pull request in GitHub),
+	// nil is returned when the request is not a merge request event or the action is not concerned.
+	ParseMergeRequestEvent(header http.Header, body []byte) (*MergeRequestEvent, error)
+}
+
+var webhookParsers = make(map[string]WebhookParser)
+
+func RegisterWebhookParser(kind string, parser WebhookParser) {
+	webhookParsers[kind] = parser
+}
+
+func GetWebhookParser(kind string) (WebhookParser, error) {
+	parser, ok := webhookParsers[kind]
+	if !ok {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "webhook of git kind = %v is not implement", kind)
+	}
+	return parser, nil
+}
+
+// ExtractHostFromURL extract host from gitURL, both http and ssh url are supported.
+func ExtractHostFromURL(gitURL string) (string, error) {
+	matches := regexHost.FindStringSubmatch(gitURL)
+	if len(matches) != 2 {
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "error to extract host from url: %v", gitURL)
+	}
+	return matches[1], nil
+}
+
 // ExtractProjectPathFromURL extract git project path from gitURL.
 func ExtractProjectPathFromURL(gitURL string) (string, error) {
 	pattern := regexp.MustCompile(`^(?:http(?:s?)|ssh)://.+?/(.+?)(?:.git)?$`)
@@ -34,6 +34,7 @@ const Kind = "github"
 
 func init() {
 	git.Register(Kind, New)
+	git.RegisterWebhookParser(Kind, &WebhookParser{})
 }
 
 type Helper struct {
@@ -211,3 +212,90 @@ func (h Helper) GetCommitHistoryLink(gitURL string, commit string) (string, erro
 
 	return fmt.Sprintf("%s/commits/%s", httpLink, commit), nil
 }
+
+func (h Helper) CreateMergeRequestComment(ctx context.Context, gitURL string, mrID int, body string) error {
+	pid, err := git.ExtractProjectPathFromURL(gitURL)
+	if err != nil {
+		return err
+	}
+	paths := strings.Split(pid, "/")
+	// comments on pull requests are created by the issues api in GitHub
+	_, _, err = h.client.Issues.CreateComment(ctx, paths[0], paths[1], mrID, &github.IssueComment{
+		Body: &body,
+	})
+	if err != nil {
+		return perror.Wrapf(herrors.ErrHTTPRequestFailed,
+			"failed to comment on pull request %d of %s: err = %v", mrID, gitURL, err)
+	}
+	return nil
+}
+
+func (h Helper) CreateCommitStatus(ctx context.Context, gitURL string, status *git.CommitStatus) error {
+	pid, err := git.ExtractProjectPathFromURL(gitURL)
+	if err != nil {
+		return err
+	}
+	paths := strings.Split(pid, "/")
+	state := toGithubState(status.State)
+	_, _, err = h.client.Repositories.CreateStatus(ctx, paths[0], paths[1], status.Commit, &github.RepoStatus{
+		State:       &state,
+		Context:     &status.Context,
+		Description: &status.Description,
+		TargetURL:   &status.TargetURL,
+	})
+	if err != nil {
+		return perror.Wrapf(herrors.ErrHTTPRequestFailed,
+			"failed to create status of commit %s of %s: err = %v", status.Commit, gitURL, err)
+	}
+	return nil
+}
+
+func (h Helper) CreateDeployment(ctx context.Context, gitURL string, deployment *git.Deployment) error {
+	pid, err := git.ExtractProjectPathFromURL(gitURL)
+	if err != nil {
+		return err
+	}
+	paths := strings.Split(pid, "/")
+	// the deployment is recorded on the commit, commit statuses are not required
+	autoMerge, requiredContexts := false, []string{}
+	created, _, err := h.client.Repositories.CreateDeployment(ctx, paths[0], paths[1], &github.DeploymentRequest{
+		Ref:              &deployment.Commit,
+		Environment:      &deployment.Environment,
+		Description:      &deployment.Description,
+		AutoMerge:        &autoMerge,
+		RequiredContexts: &requiredContexts,
+	})
+	if err != nil {
+		return perror.Wrapf(herrors.ErrHTTPRequestFailed,
+			"failed to create deployment of commit %s of %s: err = %v", deployment.Commit, gitURL, err)
+	}
+	state := toGithubState(deployment.State)
+	if deployment.State == git.CommitStateRunning {
+		state = "in_progress"
+	}
+	_, _, err = h.client.Repositories.CreateDeploymentStatus(ctx, paths[0], paths[1], created.GetID(),
+		&github.DeploymentStatusRequest{
+			State:       &state,
+			Description: &deployment.Description,
+			LogURL:      &deployment.TargetURL,
+		})
+	if err != nil {
+		return perror.Wrapf(herrors.ErrHTTPRequestFailed,
+			"failed to create status of deployment %d of %s: err = %v", created.GetID(), gitURL, err)
+	}
+	return nil
+}
+
+// toGithubState converts git.CommitState* to the states of github, which are error, failure, pending and success
+func toGithubState(state string) string {
+	switch state {
+	case git.CommitStateSuccess:
+		return "success"
+	case git.CommitStateFailed:
+		return "failure"
+	case git.CommitStateCanceled:
+		return "error"
+	default:
+		return "pending"
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/git"
+)
+
+const (
+	_headerEvent     = "X-GitHub-Event"
+	_headerSignature = "X-Hub-Signature-256"
+	_signaturePrefix = "sha256="
+
+	_eventPush        = "push"
+	_eventPullRequest = "pull_request"
+
+	_prActionOpened      = "opened"
+	_prActionReopened    = "reopened"
+	_prActionSynchronize = "synchronize"
+	_prActionClosed      = "closed"
+
+	_refPrefixBranch = "refs/heads/"
+	_refPrefixTag    = "refs/tags/"
+)
+
+type pushEvent struct {
+	Ref     string `json:"ref"`
+	After   string `json:"after"`
+	Deleted bool   `json:"deleted"`
+	Pusher  struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"pusher"`
+	Repository struct {
+		CloneURL string `json:"clone_url"`
+		SSHURL   string `json:"ssh_url"`
+		HTMLURL  string `json:"html_url"`
+	} `json:"repository"`
+	HeadCommit *struct {
+		Message string `json:"message"`
+	} `json:"head_commit"`
+	Commits []struct {
+		Added    []string `json:"added"`
+		Modified []string `json:"modified"`
+		Removed  []string `json:"removed"`
+	} `json:"commits"`
+}
+
+type pullRequestEvent struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Title   string `json:"title"`
+		HTMLURL string `json:"html_url"`
+		Merged  bool   `json:"merged"`
+		Head    struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+			// Repo is null if the fork has been deleted
+			Repo *struct {
+				ID int64 `json:"id"`
+			} `json:"repo"`
+		} `json:"head"`
+		Base struct {
+			Ref  string `json:"ref"`
+			Repo struct {
+				ID int64 `json:"id"`
+			} `json:"repo"`
+		} `json:"base"`
+	} `json:"pull_request"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+	Repository struct {
+		CloneURL string `json:"clone_url"`
+		SSHURL   string `json:"ssh_url"`
+		HTMLURL  string `json:"html_url"`
+	} `json:"repository"`
+}
+
+// WebhookParser parses the repository webhooks of github
+type WebhookParser struct{}
+
+var _ git.WebhookParser = (*WebhookParser)(nil)
+
+func (p *WebhookParser) Verify(header http.Header, body []byte, secret string) error {
+	signature := strings.TrimPrefix(header.Get(_headerSignature), _signaturePrefix)
+	expected, err := hex.DecodeString(signature)
+	if secret == "" || err != nil {
+		return perror.Wrap(herrors.ErrForbidden, "github webhook signature is invalid")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return perror.Wrap(herrors.ErrForbidden, "github webhook signature is invalid")
+	}
+	return nil
+}
+
+func (p *WebhookParser) ParsePushEvent(header http.Header, body []byte) (*git.PushEvent, error) {
+	if header.Get(_headerEvent) != _eventPush {
+		return nil, nil
+	}
+	var e pushEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to unmarshal github event: %v", err)
+	}
+	if e.Deleted {
+		return nil, nil
+	}
+
+	event := &git.PushEvent{
+		Commit:      e.After,
+		Pusher:      e.Pusher.Name,
+		PusherEmail: e.Pusher.Email,
+	}
+	switch {
+	case strings.HasPrefix(e.Ref, _refPrefixBranch):
+		event.RefType = git.GitRefTypeBranch
+		event.Ref = strings.TrimPrefix(e.Ref, _refPrefixBranch)
+	case strings.HasPrefix(e.Ref, _refPrefixTag):
+		event.RefType = git.GitRefTypeTag
+		event.Ref = strings.TrimPrefix(e.Ref, _refPrefixTag)
+	default:
+		return nil, nil
+	}
+	if e.HeadCommit != nil {
+		event.Message = e.HeadCommit.Message
+	}
+	for _, url := range []string{e.Repository.CloneURL, e.Repository.SSHURL, e.Repository.HTMLURL} {
+		if url != "" {
+			event.RepoURLs = append(event.RepoURLs, url)
+		}
+	}
+	for _, commit := range e.Commits {
+		event.ChangedFiles = append(event.ChangedFiles, commit.Added...)
+		event.ChangedFiles = append(event.ChangedFiles, commit.Modified...)
+		event.ChangedFiles = append(event.ChangedFiles, commit.Removed...)
+	}
+	return event, nil
+}
+
+func (p *WebhookParser) ParseMergeRequestEvent(header http.Header, body []byte) (*git.MergeRequestEvent, error) {
+	if header.Get(_headerEvent) != _eventPullRequest {
+		return nil, nil
+	}
+	var e pullRequestEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to unmarshal github event: %v", err)
+	}
+
+	pr := e.PullRequest
+	event := &git.MergeRequestEvent{
+		ID:           e.Number,
+		Title:        pr.Title,
+		URL:          pr.HTMLURL,
+		SourceBranch: pr.Head.Ref,
+		TargetBranch: pr.Base.Ref,
+		Commit:       pr.Head.SHA,
+		Author:       e.Sender.Login,
+		Fork:         pr.Head.Repo == nil || pr.Head.Repo.ID != pr.Base.Repo.ID,
+	}
+	switch e.Action {
+	case _prActionOpened, _prActionReopened:
+		event.Action = git.MergeRequestActionOpen
+	case _prActionSynchronize:
+		event.Action = git.MergeRequestActionUpdate
+	case _prActionClosed:
+		if pr.Merged {
+			event.Action = git.MergeRequestActionMerge
+		} else {
+			event.Action = git.MergeRequestActionClose
+		}
+	default:
+		return nil, nil
+	}
+	for _, url := range []string{e.Repository.CloneURL, e.Repository.SSHURL, e.Repository.HTMLURL} {
+		if url != "" {
+			event.RepoURLs = append(event.RepoURLs, url)
+		}
+	}
+	return event, nil
+}
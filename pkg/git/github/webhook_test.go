@@ -0,0 +1,142 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/git"
+)
+
+func TestWebhookParser(t *testing.T) {
+	parser := &WebhookParser{}
+	body := []byte(`{
+		"ref": "refs/tags/v1.0.0",
+		"after": "da1560886d4f094c3e6c9ef40349f7d38b5d27d7",
+		"deleted": false,
+		"pusher": {"name": "tony", "email": "tony@example.com"},
+		"repository": {
+			"clone_url": "https://github.com/demo/app.git",
+			"ssh_url": "git@github.com:demo/app.git",
+			"html_url": "https://github.com/demo/app"
+		},
+		"head_commit": {"message": "release"},
+		"commits": []
+	}`)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "push")
+	header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	assert.Nil(t, parser.Verify(header, body, "secret"))
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(parser.Verify(header, body, "other")))
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(parser.Verify(header, []byte("{}"), "secret")))
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(parser.Verify(http.Header{}, body, "secret")))
+
+	event, err := parser.ParsePushEvent(header, body)
+	assert.Nil(t, err)
+	assert.Equal(t, &git.PushEvent{
+		RepoURLs: []string{"https://github.com/demo/app.git",
+			"git@github.com:demo/app.git", "https://github.com/demo/app"},
+		RefType:     git.GitRefTypeTag,
+		Ref:         "v1.0.0",
+		Commit:      "da1560886d4f094c3e6c9ef40349f7d38b5d27d7",
+		Message:     "release",
+		Pusher:      "tony",
+		PusherEmail: "tony@example.com",
+	}, event)
+
+	// deleted refs and other events are ignored
+	event, err = parser.ParsePushEvent(header, []byte(`{"ref": "refs/heads/dev", "deleted": true}`))
+	assert.Nil(t, err)
+	assert.Nil(t, event)
+	header.Set("X-GitHub-Event", "ping")
+	event, err = parser.ParsePushEvent(header, body)
+	assert.Nil(t, err)
+	assert.Nil(t, event)
+}
+
+func TestParseMergeRequestEvent(t *testing.T) {
+	parser := &WebhookParser{}
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "pull_request")
+
+	event, err := parser.ParseMergeRequestEvent(header, []byte(`{
+		"action": "opened",
+		"number": 3,
+		"pull_request": {
+			"title": "add feature",
+			"html_url": "https://github.com/demo/app/pull/3",
+			"head": {"ref": "feature", "sha": "da1560886d4f094c3e6c9ef40349f7d38b5d27d7", "repo": {"id": 7}},
+			"base": {"ref": "main", "repo": {"id": 7}}
+		},
+		"sender": {"login": "tony"},
+		"repository": {
+			"clone_url": "https://github.com/demo/app.git",
+			"ssh_url": "git@github.com:demo/app.git",
+			"html_url": "https://github.com/demo/app"
+		}
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, &git.MergeRequestEvent{
+		RepoURLs: []string{"https://github.com/demo/app.git",
+			"git@github.com:demo/app.git", "https://github.com/demo/app"},
+		Action:       git.MergeRequestActionOpen,
+		ID:           3,
+		Title:        "add feature",
+		URL:          "https://github.com/demo/app/pull/3",
+		SourceBranch: "feature",
+		TargetBranch: "main",
+		Commit:       "da1560886d4f094c3e6c9ef40349f7d38b5d27d7",
+		Author:       "tony",
+	}, event)
+
+	// pull requests from forks
+	event, err = parser.ParseMergeRequestEvent(header, []byte(`{"action": "synchronize", "number": 3,
+		"pull_request": {"head": {"repo": {"id": 8}}, "base": {"repo": {"id": 7}}}}`))
+	assert.Nil(t, err)
+	assert.True(t, event.Fork)
+	event, err = parser.ParseMergeRequestEvent(header, []byte(`{"action": "synchronize", "number": 3,
+		"pull_request": {"head": {"repo": null}, "base": {"repo": {"id": 7}}}}`))
+	assert.Nil(t, err)
+	assert.True(t, event.Fork)
+
+	event, err = parser.ParseMergeRequestEvent(header,
+		[]byte(`{"action": "closed", "number": 3, "pull_request": {"merged": true}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, git.MergeRequestActionMerge, event.Action)
+	event, err = parser.ParseMergeRequestEvent(header,
+		[]byte(`{"action": "closed", "number": 3, "pull_request": {"merged": false}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, git.MergeRequestActionClose, event.Action)
+
+	// other actions and events are ignored
+	event, err = parser.ParseMergeRequestEvent(header, []byte(`{"action": "labeled", "number": 3}`))
+	assert.Nil(t, err)
+	assert.Nil(t, event)
+	header.Set("X-GitHub-Event", "push")
+	event, err = parser.ParseMergeRequestEvent(header, []byte(`{"action": "opened", "number": 3}`))
+	assert.Nil(t, err)
+	assert.Nil(t, event)
+}
@@ -32,6 +32,7 @@ const Kind = "gitlab"
 
 func init() {
 	git.Register(Kind, New)
+	git.RegisterWebhookParser(Kind, &WebhookParser{})
 }
 
 type Helper struct {
@@ -189,3 +190,54 @@ func (h Helper) GetCommitHistoryLink(gitURL string, commit string) (string, erro
 
 	return fmt.Sprintf("%s/-/commits/%s", httpLink, commit), nil
 }
+
+func (h Helper) CreateMergeRequestComment(ctx context.Context, gitURL string, mrID int, body string) error {
+	pid, err := git.ExtractProjectPathFromURL(gitURL)
+	if err != nil {
+		return err
+	}
+	_, err = h.client.CreateMRNote(ctx, pid, mrID, body)
+	return err
+}
+
+func (h Helper) CreateCommitStatus(ctx context.Context, gitURL string, status *git.CommitStatus) error {
+	pid, err := git.ExtractProjectPathFromURL(gitURL)
+	if err != nil {
+		return err
+	}
+	opt := &gitlab.SetCommitStatusOptions{
+		// the states of gitlab are the same as git.CommitState*
+		State:       gitlab.BuildStateValue(status.State),
+		Name:        &status.Context,
+		Description: &status.Description,
+		TargetURL:   &status.TargetURL,
+	}
+	if status.Ref != "" {
+		opt.Ref = &status.Ref
+	}
+	_, err = h.client.SetCommitStatus(ctx, pid, status.Commit, opt)
+	return err
+}
+
+func (h Helper) CreateDeployment(ctx context.Context, gitURL string, deployment *git.Deployment) error {
+	pid, err := git.ExtractProjectPathFromURL(gitURL)
+	if err != nil {
+		return err
+	}
+	ref, tag := deployment.Commit, false
+	switch deployment.RefType {
+	case git.GitRefTypeBranch:
+		ref = deployment.Ref
+	case git.GitRefTypeTag:
+		ref, tag = deployment.Ref, true
+	}
+	state := gitlab.DeploymentStatusValue(deployment.State)
+	_, err = h.client.CreateDeployment(ctx, pid, &gitlab.CreateProjectDeploymentOptions{
+		Environment: &deployment.Environment,
+		Ref:         &ref,
+		SHA:         &deployment.Commit,
+		Tag:         &tag,
+		Status:      &state,
+	})
+	return err
+}
@@ -0,0 +1,189 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitlab
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/git"
+)
+
+const (
+	_headerToken = "X-Gitlab-Token"
+
+	_objectKindPush    = "push"
+	_objectKindTagPush = "tag_push"
+	_objectKindMR      = "merge_request"
+
+	_mrActionOpen   = "open"
+	_mrActionReopen = "reopen"
+	_mrActionUpdate = "update"
+	_mrActionClose  = "close"
+	_mrActionMerge  = "merge"
+
+	_refPrefixBranch = "refs/heads/"
+	_refPrefixTag    = "refs/tags/"
+	_emptyCommit     = "0000000000000000000000000000000000000000"
+)
+
+type pushEvent struct {
+	ObjectKind   string `json:"object_kind"`
+	Ref          string `json:"ref"`
+	After        string `json:"after"`
+	UserUsername string `json:"user_username"`
+	UserEmail    string `json:"user_email"`
+	Project      struct {
+		GitHTTPURL string `json:"git_http_url"`
+		GitSSHURL  string `json:"git_ssh_url"`
+		WebURL     string `json:"web_url"`
+	} `json:"project"`
+	Commits []struct {
+		ID       string   `json:"id"`
+		Message  string   `json:"message"`
+		Added    []string `json:"added"`
+		Modified []string `json:"modified"`
+		Removed  []string `json:"removed"`
+	} `json:"commits"`
+}
+
+type mergeRequestEvent struct {
+	ObjectKind string `json:"object_kind"`
+	User       struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		GitHTTPURL string `json:"git_http_url"`
+		GitSSHURL  string `json:"git_ssh_url"`
+		WebURL     string `json:"web_url"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID             int    `json:"iid"`
+		SourceProjectID int    `json:"source_project_id"`
+		TargetProjectID int    `json:"target_project_id"`
+		Title           string `json:"title"`
+		URL             string `json:"url"`
+		SourceBranch    string `json:"source_branch"`
+		TargetBranch    string `json:"target_branch"`
+		Action          string `json:"action"`
+		// OldRev is only set when the update action is caused by new commits
+		OldRev     string `json:"oldrev"`
+		LastCommit struct {
+			ID string `json:"id"`
+		} `json:"last_commit"`
+	} `json:"object_attributes"`
+}
+
+// WebhookParser parses the system hooks or project hooks of gitlab
+type WebhookParser struct{}
+
+var _ git.WebhookParser = (*WebhookParser)(nil)
+
+func (p *WebhookParser) Verify(header http.Header, _ []byte, secret string) error {
+	token := header.Get(_headerToken)
+	if secret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		return perror.Wrap(herrors.ErrForbidden, "gitlab webhook token is invalid")
+	}
+	return nil
+}
+
+func (p *WebhookParser) ParsePushEvent(_ http.Header, body []byte) (*git.PushEvent, error) {
+	var e pushEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to unmarshal gitlab event: %v", err)
+	}
+	if (e.ObjectKind != _objectKindPush && e.ObjectKind != _objectKindTagPush) ||
+		e.After == "" || e.After == _emptyCommit {
+		return nil, nil
+	}
+
+	event := &git.PushEvent{
+		Commit:      e.After,
+		Pusher:      e.UserUsername,
+		PusherEmail: e.UserEmail,
+	}
+	switch {
+	case strings.HasPrefix(e.Ref, _refPrefixBranch):
+		event.RefType = git.GitRefTypeBranch
+		event.Ref = strings.TrimPrefix(e.Ref, _refPrefixBranch)
+	case strings.HasPrefix(e.Ref, _refPrefixTag):
+		event.RefType = git.GitRefTypeTag
+		event.Ref = strings.TrimPrefix(e.Ref, _refPrefixTag)
+	default:
+		return nil, nil
+	}
+	for _, url := range []string{e.Project.GitHTTPURL, e.Project.GitSSHURL, e.Project.WebURL} {
+		if url != "" {
+			event.RepoURLs = append(event.RepoURLs, url)
+		}
+	}
+	for _, commit := range e.Commits {
+		if commit.ID == e.After {
+			event.Message = commit.Message
+		}
+		event.ChangedFiles = append(event.ChangedFiles, commit.Added...)
+		event.ChangedFiles = append(event.ChangedFiles, commit.Modified...)
+		event.ChangedFiles = append(event.ChangedFiles, commit.Removed...)
+	}
+	return event, nil
+}
+
+func (p *WebhookParser) ParseMergeRequestEvent(_ http.Header, body []byte) (*git.MergeRequestEvent, error) {
+	var e mergeRequestEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to unmarshal gitlab event: %v", err)
+	}
+	if e.ObjectKind != _objectKindMR {
+		return nil, nil
+	}
+
+	attrs := e.ObjectAttributes
+	event := &git.MergeRequestEvent{
+		ID:           attrs.IID,
+		Title:        attrs.Title,
+		URL:          attrs.URL,
+		SourceBranch: attrs.SourceBranch,
+		TargetBranch: attrs.TargetBranch,
+		Commit:       attrs.LastCommit.ID,
+		Author:       e.User.Username,
+		Fork:         attrs.SourceProjectID != attrs.TargetProjectID,
+	}
+	switch attrs.Action {
+	case _mrActionOpen, _mrActionReopen:
+		event.Action = git.MergeRequestActionOpen
+	case _mrActionUpdate:
+		// title or description changes are not concerned
+		if attrs.OldRev == "" {
+			return nil, nil
+		}
+		event.Action = git.MergeRequestActionUpdate
+	case _mrActionClose:
+		event.Action = git.MergeRequestActionClose
+	case _mrActionMerge:
+		event.Action = git.MergeRequestActionMerge
+	default:
+		return nil, nil
+	}
+	for _, url := range []string{e.Project.GitHTTPURL, e.Project.GitSSHURL, e.Project.WebURL} {
+		if url != "" {
+			event.RepoURLs = append(event.RepoURLs, url)
+		}
+	}
+	return event, nil
+}
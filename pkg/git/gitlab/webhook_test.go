@@ -0,0 +1,155 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitlab
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/git"
+)
+
+func TestWebhookParser(t *testing.T) {
+	parser := &WebhookParser{}
+
+	header := http.Header{}
+	header.Set("X-Gitlab-Token", "secret")
+	assert.Nil(t, parser.Verify(header, nil, "secret"))
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(parser.Verify(header, nil, "other")))
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(parser.Verify(http.Header{}, nil, "")))
+
+	event, err := parser.ParsePushEvent(header, []byte(`{
+		"object_kind": "push",
+		"ref": "refs/heads/master",
+		"after": "da1560886d4f094c3e6c9ef40349f7d38b5d27d7",
+		"user_username": "tony",
+		"user_email": "tony@example.com",
+		"project": {
+			"git_ssh_url": "git@example.com:demo/app.git",
+			"git_http_url": "https://example.com/demo/app.git",
+			"web_url": "https://example.com/demo/app"
+		},
+		"commits": [
+			{"id": "b6568db1bc1dcd7f8b4d5a946b0b91f9dacd7327", "message": "init", "added": ["a.go"]},
+			{"id": "da1560886d4f094c3e6c9ef40349f7d38b5d27d7", "message": "fix bug\n\ndetail",
+				"modified": ["b.go"], "removed": ["c.go"]}
+		]
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, &git.PushEvent{
+		RepoURLs: []string{"https://example.com/demo/app.git",
+			"git@example.com:demo/app.git", "https://example.com/demo/app"},
+		RefType:      git.GitRefTypeBranch,
+		Ref:          "master",
+		Commit:       "da1560886d4f094c3e6c9ef40349f7d38b5d27d7",
+		Message:      "fix bug\n\ndetail",
+		Pusher:       "tony",
+		PusherEmail:  "tony@example.com",
+		ChangedFiles: []string{"a.go", "b.go", "c.go"},
+	}, event)
+
+	event, err = parser.ParsePushEvent(header, []byte(`{
+		"object_kind": "tag_push",
+		"ref": "refs/tags/v1.0.0",
+		"after": "82b3d5ae55f7080f1e6022629cdb57bfae7cccc7"
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, git.GitRefTypeTag, event.RefType)
+	assert.Equal(t, "v1.0.0", event.Ref)
+
+	// deleted branch and other events are ignored
+	event, err = parser.ParsePushEvent(header, []byte(`{
+		"object_kind": "push",
+		"ref": "refs/heads/master",
+		"after": "0000000000000000000000000000000000000000"
+	}`))
+	assert.Nil(t, err)
+	assert.Nil(t, event)
+	event, err = parser.ParsePushEvent(header, []byte(`{"object_kind": "merge_request"}`))
+	assert.Nil(t, err)
+	assert.Nil(t, event)
+
+	_, err = parser.ParsePushEvent(header, []byte(`{`))
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
+
+func TestParseMergeRequestEvent(t *testing.T) {
+	parser := &WebhookParser{}
+
+	event, err := parser.ParseMergeRequestEvent(http.Header{}, []byte(`{
+		"object_kind": "merge_request",
+		"user": {"username": "tony"},
+		"project": {
+			"git_ssh_url": "git@example.com:demo/app.git",
+			"git_http_url": "https://example.com/demo/app.git",
+			"web_url": "https://example.com/demo/app"
+		},
+		"object_attributes": {
+			"iid": 12,
+			"source_project_id": 5,
+			"target_project_id": 5,
+			"title": "add feature",
+			"url": "https://example.com/demo/app/-/merge_requests/12",
+			"source_branch": "feature",
+			"target_branch": "master",
+			"action": "update",
+			"oldrev": "b6568db1bc1dcd7f8b4d5a946b0b91f9dacd7327",
+			"last_commit": {"id": "da1560886d4f094c3e6c9ef40349f7d38b5d27d7"}
+		}
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, &git.MergeRequestEvent{
+		RepoURLs: []string{"https://example.com/demo/app.git",
+			"git@example.com:demo/app.git", "https://example.com/demo/app"},
+		Action:       git.MergeRequestActionUpdate,
+		ID:           12,
+		Title:        "add feature",
+		URL:          "https://example.com/demo/app/-/merge_requests/12",
+		SourceBranch: "feature",
+		TargetBranch: "master",
+		Commit:       "da1560886d4f094c3e6c9ef40349f7d38b5d27d7",
+		Author:       "tony",
+	}, event)
+
+	// merge requests from forks
+	event, err = parser.ParseMergeRequestEvent(http.Header{}, []byte(`{
+		"object_kind": "merge_request",
+		"object_attributes": {"iid": 12, "action": "open", "source_project_id": 6, "target_project_id": 5}
+	}`))
+	assert.Nil(t, err)
+	assert.True(t, event.Fork)
+
+	event, err = parser.ParseMergeRequestEvent(http.Header{}, []byte(`{
+		"object_kind": "merge_request",
+		"object_attributes": {"iid": 12, "action": "merge"}
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, git.MergeRequestActionMerge, event.Action)
+
+	// updates without new commits and other events are ignored
+	event, err = parser.ParseMergeRequestEvent(http.Header{}, []byte(`{
+		"object_kind": "merge_request",
+		"object_attributes": {"iid": 12, "action": "update"}
+	}`))
+	assert.Nil(t, err)
+	assert.Nil(t, event)
+	event, err = parser.ParseMergeRequestEvent(http.Header{}, []byte(`{"object_kind": "push"}`))
+	assert.Nil(t, err)
+	assert.Nil(t, event)
+}
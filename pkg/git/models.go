@@ -20,6 +20,17 @@ const (
 	GitRefTypeCommit = "commit"
 )
 
+const (
+	// MergeRequestActionOpen means the merge request is opened or reopened
+	MergeRequestActionOpen = "open"
+	// MergeRequestActionUpdate means new commits are pushed to the source branch
+	MergeRequestActionUpdate = "update"
+	// MergeRequestActionClose means the merge request is closed without merging
+	MergeRequestActionClose = "close"
+	// MergeRequestActionMerge means the merge request is merged
+	MergeRequestActionMerge = "merge"
+)
+
 type Tag struct {
 	ShortID     string
 	Name        string
@@ -31,9 +42,77 @@ type Commit struct {
 	Message string
 }
 
+const (
+	CommitStatePending  = "pending"
+	CommitStateRunning  = "running"
+	CommitStateSuccess  = "success"
+	CommitStateFailed   = "failed"
+	CommitStateCanceled = "canceled"
+)
+
+// CommitStatus is the status of a commit published to git host, such as the result of build
+type CommitStatus struct {
+	Commit string
+	// Ref is the branch or tag of the commit, optional
+	Ref   string
+	State string
+	// Context distinguishes the statuses of different systems or clusters on the same commit
+	Context     string
+	Description string
+	TargetURL   string
+}
+
+// Deployment is a deployment record of a commit to an environment published to git host
+type Deployment struct {
+	Commit string
+	// RefType and Ref are the branch or tag deployed, the commit is used as ref if they are empty
+	RefType     string
+	Ref         string
+	Environment string
+	// State is one of CommitStateRunning, CommitStateSuccess and CommitStateFailed
+	State       string
+	Description string
+	TargetURL   string
+}
+
 // SearchParams contains parameters for searching operation
 type SearchParams struct {
 	Filter     string
 	PageNumber int
 	PageSize   int
 }
+
+// PushEvent is a push of branch or tag received from the webhook of git host
+type PushEvent struct {
+	// RepoURLs are the urls of the pushed repository in different protocols
+	RepoURLs []string
+	// RefType is branch or tag
+	RefType string
+	Ref     string
+	// Commit is the head commit after the push
+	Commit      string
+	Message     string
+	Pusher      string
+	PusherEmail string
+	// ChangedFiles are the files added, modified or removed by the pushed commits
+	ChangedFiles []string
+}
+
+// MergeRequestEvent is a change of merge request (pull request in GitHub)
+// received from the webhook of git host
+type MergeRequestEvent struct {
+	// RepoURLs are the urls of the target repository in different protocols
+	RepoURLs []string
+	Action   string
+	// ID is the project-scoped number of merge request, e.g. the iid of GitLab
+	ID           int
+	Title        string
+	URL          string
+	SourceBranch string
+	TargetBranch string
+	// Commit is the head commit of the source branch
+	Commit string
+	Author string
+	// Fork is true if the source branch belongs to another repository, such as a fork of the target repository
+	Fork bool
+}
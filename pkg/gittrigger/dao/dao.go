@@ -0,0 +1,108 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/gittrigger/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, trigger *models.GitTrigger) (*models.GitTrigger, error)
+	GetByID(ctx context.Context, id uint) (*models.GitTrigger, error)
+	ListByClusterID(ctx context.Context, clusterID uint) ([]*models.GitTrigger, error)
+	ListEnabledByGitURLs(ctx context.Context, gitURLs []string) ([]*models.GitTrigger, error)
+	Update(ctx context.Context, trigger *models.GitTrigger) (*models.GitTrigger, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, trigger *models.GitTrigger) (*models.GitTrigger, error) {
+	if err := d.db.WithContext(ctx).Create(trigger).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.GitTriggerInDB, err.Error())
+	}
+	return trigger, nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.GitTrigger, error) {
+	var trigger models.GitTrigger
+	if err := d.db.WithContext(ctx).Where("id = ?", id).First(&trigger).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.GitTriggerInDB,
+				fmt.Sprintf("git trigger with id = %d was not found", id))
+		}
+		return nil, herrors.NewErrGetFailed(herrors.GitTriggerInDB, err.Error())
+	}
+	return &trigger, nil
+}
+
+func (d *dao) ListByClusterID(ctx context.Context, clusterID uint) ([]*models.GitTrigger, error) {
+	var triggers []*models.GitTrigger
+	if err := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).
+		Order("id asc").Find(&triggers).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.GitTriggerInDB, err.Error())
+	}
+	return triggers, nil
+}
+
+func (d *dao) ListEnabledByGitURLs(ctx context.Context, gitURLs []string) ([]*models.GitTrigger, error) {
+	var triggers []*models.GitTrigger
+	if len(gitURLs) == 0 {
+		return triggers, nil
+	}
+	clusters := d.db.Model(&clustermodels.Cluster{}).Select("id").Where("git_url in ?", gitURLs)
+	if err := d.db.WithContext(ctx).Where("enabled = ?", true).
+		Where("cluster_id in (?)", clusters).
+		Order("id asc").Find(&triggers).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.GitTriggerInDB, err.Error())
+	}
+	return triggers, nil
+}
+
+func (d *dao) Update(ctx context.Context, trigger *models.GitTrigger) (*models.GitTrigger, error) {
+	result := d.db.WithContext(ctx).Model(&models.GitTrigger{}).Where("id = ?", trigger.ID).
+		Updates(map[string]interface{}{
+			"branch_pattern": trigger.BranchPattern,
+			"tag_regex":      trigger.TagRegex,
+			"path_filter":    trigger.PathFilter,
+			"enabled":        trigger.Enabled,
+			"secret":         trigger.Secret,
+		})
+	if result.Error != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.GitTriggerInDB, result.Error.Error())
+	}
+	return d.GetByID(ctx, trigger.ID)
+}
+
+func (d *dao) Delete(ctx context.Context, id uint) error {
+	if err := d.db.WithContext(ctx).Where("id = ?", id).Delete(&models.GitTrigger{}).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.GitTriggerInDB, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/gittrigger/dao"
+	"github.com/horizoncd/horizon/pkg/gittrigger/models"
+)
+
+type Manager interface {
+	Create(ctx context.Context, trigger *models.GitTrigger) (*models.GitTrigger, error)
+	GetByID(ctx context.Context, id uint) (*models.GitTrigger, error)
+	ListByClusterID(ctx context.Context, clusterID uint) ([]*models.GitTrigger, error)
+	// ListEnabledByGitURLs lists the enabled triggers of clusters built from the repository,
+	// urls with or without the .git suffix are both matched
+	ListEnabledByGitURLs(ctx context.Context, gitURLs []string) ([]*models.GitTrigger, error)
+	Update(ctx context.Context, trigger *models.GitTrigger) (*models.GitTrigger, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Create(ctx context.Context, trigger *models.GitTrigger) (*models.GitTrigger, error) {
+	return m.dao.Create(ctx, trigger)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.GitTrigger, error) {
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) ListByClusterID(ctx context.Context, clusterID uint) ([]*models.GitTrigger, error) {
+	return m.dao.ListByClusterID(ctx, clusterID)
+}
+
+func (m *manager) ListEnabledByGitURLs(ctx context.Context, gitURLs []string) ([]*models.GitTrigger, error) {
+	urls := make([]string, 0, len(gitURLs)*2)
+	for _, url := range gitURLs {
+		url = strings.TrimSuffix(url, ".git")
+		urls = append(urls, url, url+".git")
+	}
+	return m.dao.ListEnabledByGitURLs(ctx, urls)
+}
+
+func (m *manager) Update(ctx context.Context, trigger *models.GitTrigger) (*models.GitTrigger, error) {
+	return m.dao.Update(ctx, trigger)
+}
+
+func (m *manager) Delete(ctx context.Context, id uint) error {
+	return m.dao.Delete(ctx, id)
+}
@@ -0,0 +1,80 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/horizoncd/horizon/lib/encrypt"
+	"github.com/horizoncd/horizon/pkg/git"
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+// GitTrigger is a rule of cluster to start BuildDeploy automatically when receiving push events from git host
+type GitTrigger struct {
+	global.Model
+
+	ClusterID uint
+	// BranchPattern is a glob pattern of the branches whose pushes trigger the build, empty means never
+	BranchPattern string
+	// TagRegex is a regular expression of the tags whose pushes trigger the build, empty means never
+	TagRegex string
+	// PathFilter restricts branch pushes to those changing files under the path, empty means no restriction
+	PathFilter string
+	Enabled    bool
+	CreatedBy  uint
+	UpdatedBy  uint
+	// Secret verifies the webhooks sent by the repository for the trigger
+	Secret encrypt.String
+}
+
+// Match checks whether the push event triggers the build
+func (t *GitTrigger) Match(event *git.PushEvent) bool {
+	if !t.Enabled || event == nil {
+		return false
+	}
+	switch event.RefType {
+	case git.GitRefTypeBranch:
+		if t.BranchPattern == "" {
+			return false
+		}
+		if matched, err := path.Match(t.BranchPattern, event.Ref); err != nil || !matched {
+			return false
+		}
+		return t.matchPath(event.ChangedFiles)
+	case git.GitRefTypeTag:
+		if t.TagRegex == "" {
+			return false
+		}
+		matched, err := regexp.MatchString(t.TagRegex, event.Ref)
+		return err == nil && matched
+	}
+	return false
+}
+
+func (t *GitTrigger) matchPath(files []string) bool {
+	filter := strings.Trim(t.PathFilter, "/")
+	if filter == "" {
+		return true
+	}
+	for _, file := range files {
+		if file == filter || strings.HasPrefix(file, filter+"/") {
+			return true
+		}
+	}
+	return false
+}
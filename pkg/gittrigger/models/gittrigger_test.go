@@ -0,0 +1,57 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/pkg/git"
+)
+
+func TestMatch(t *testing.T) {
+	trigger := &GitTrigger{
+		BranchPattern: "release/*",
+		TagRegex:      `^v\d+\.\d+\.\d+$`,
+		PathFilter:    "/app/",
+		Enabled:       true,
+	}
+	branch := func(ref string, files ...string) *git.PushEvent {
+		return &git.PushEvent{RefType: git.GitRefTypeBranch, Ref: ref, ChangedFiles: files}
+	}
+	tag := func(ref string) *git.PushEvent {
+		return &git.PushEvent{RefType: git.GitRefTypeTag, Ref: ref}
+	}
+
+	assert.True(t, trigger.Match(branch("release/1.0", "app/main.go")))
+	assert.True(t, trigger.Match(branch("release/1.0", "README.md", "app")))
+	assert.False(t, trigger.Match(branch("release/1.0", "application/main.go")))
+	assert.False(t, trigger.Match(branch("release/1.0")))
+	assert.False(t, trigger.Match(branch("master", "app/main.go")))
+	assert.False(t, trigger.Match(branch("release/1.0/hotfix", "app/main.go")))
+	assert.True(t, trigger.Match(tag("v1.2.3")))
+	assert.False(t, trigger.Match(tag("v1.2")))
+	assert.False(t, trigger.Match(nil))
+
+	trigger.PathFilter = ""
+	assert.True(t, trigger.Match(branch("release/1.0")))
+
+	trigger.TagRegex = ""
+	assert.False(t, trigger.Match(tag("v1.2.3")))
+
+	trigger.Enabled = false
+	assert.False(t, trigger.Match(branch("release/1.0")))
+}
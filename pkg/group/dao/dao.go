@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/horizoncd/horizon/core/common"
@@ -60,10 +61,11 @@ type DAO interface {
 	UpdateBasic(ctx context.Context, group *models.Group) error
 	// ListWithoutPage query groups without paging
 	ListWithoutPage(ctx context.Context, query *q.Query) ([]*models.Group, error)
-	// List query groups with paging
-	List(ctx context.Context, query *q.Query) ([]*models.Group, int64, error)
-	// ListChildren children of a group
-	ListChildren(ctx context.Context, parentID uint, pageNumber, pageSize int) ([]*models.GroupOrApplication, int64, error)
+	// List query groups with paging, excluding the groups of the specified ids
+	List(ctx context.Context, query *q.Query, excludedIDs ...uint) ([]*models.Group, int64, error)
+	// ListChildren children of a group, excluding the groups and applications of the specified ids
+	ListChildren(ctx context.Context, parentID uint, excludedGroupIDs, excludedApplicationIDs []uint,
+		pageNumber, pageSize int) ([]*models.GroupOrApplication, int64, error)
 	// Transfer move a group under another parent group
 	Transfer(ctx context.Context, id, newParentID uint) error
 	// GetByNameOrPathUnderParent get by name or path under a specified parent
@@ -96,7 +98,9 @@ func (d *dao) UpdateRegionSelector(ctx context.Context, id uint, regionSelector
 
 func (d *dao) GetByIDNameFuzzily(ctx context.Context, id uint, name string) ([]*models.Group, error) {
 	var groups []*models.Group
-	result := d.db.WithContext(ctx).Raw(dbcommon.GroupQueryByIDNameFuzzily, fmt.Sprintf("%%%d%%", id),
+	// match the id as the whole traversal ids, the first, the last or a middle one
+	result := d.db.WithContext(ctx).Raw(dbcommon.GroupQueryByIDNameFuzzily, strconv.Itoa(int(id)),
+		fmt.Sprintf("%d,%%", id), fmt.Sprintf("%%,%d", id), fmt.Sprintf("%%,%d,%%", id),
 		fmt.Sprintf("%%%s%%", name)).Scan(&groups)
 
 	if result.Error != nil {
@@ -106,18 +110,27 @@ func (d *dao) GetByIDNameFuzzily(ctx context.Context, id uint, name string) ([]*
 	return groups, result.Error
 }
 
-func (d *dao) ListChildren(ctx context.Context, parentID uint, pageNumber, pageSize int) (
-	[]*models.GroupOrApplication, int64, error) {
+func (d *dao) ListChildren(ctx context.Context, parentID uint, excludedGroupIDs, excludedApplicationIDs []uint,
+	pageNumber, pageSize int) ([]*models.GroupOrApplication, int64, error) {
 	var gas []*models.GroupOrApplication
 	var count int64
 
-	result := d.db.WithContext(ctx).Raw(dbcommon.GroupQueryGroupChildren, parentID, parentID,
-		pageSize, (pageNumber-1)*pageSize).Scan(&gas)
+	// "not in" an empty list excludes everything, and no record has the id 0
+	if len(excludedGroupIDs) == 0 {
+		excludedGroupIDs = []uint{0}
+	}
+	if len(excludedApplicationIDs) == 0 {
+		excludedApplicationIDs = []uint{0}
+	}
+
+	result := d.db.WithContext(ctx).Raw(dbcommon.GroupQueryGroupChildren, parentID, excludedGroupIDs,
+		parentID, excludedApplicationIDs, pageSize, (pageNumber-1)*pageSize).Scan(&gas)
 	if result.Error != nil {
 		return nil, 0, herrors.NewErrGetFailed(herrors.GroupInDB, result.Error.Error())
 	}
 
-	result = d.db.WithContext(ctx).Raw(dbcommon.GroupQueryGroupChildrenCount, parentID, parentID).Scan(&count)
+	result = d.db.WithContext(ctx).Raw(dbcommon.GroupQueryGroupChildrenCount, parentID, excludedGroupIDs,
+		parentID, excludedApplicationIDs).Scan(&count)
 
 	if result.Error != nil {
 		return nil, 0, herrors.NewErrGetFailed(herrors.GroupInDB, result.Error.Error())
@@ -159,12 +172,21 @@ func (d *dao) Transfer(ctx context.Context, id, newParentID uint) error {
 			return herrors.NewErrUpdateFailed(herrors.GroupInDB, err.Error())
 		}
 
-		// update traversalIDs
+		// update traversalIDs of the group and its subgroups, the prefix is replaced row by row
+		// since string functions to do it in one statement differ between databases
 		oldTIDs := group.TraversalIDs
 		newTIDs := fmt.Sprintf("%s,%d", pGroup.TraversalIDs, group.ID)
-		if err := tx.Exec(dbcommon.GroupUpdateTraversalIDsPrefix, oldTIDs, newTIDs,
-			currentUser.GetID(), oldTIDs+"%").Error; err != nil {
-			return herrors.NewErrUpdateFailed(herrors.GroupInDB, err.Error())
+		var groups []*models.Group
+		if err := tx.Raw(dbcommon.GroupQueryByTraversalIDPrefix, oldTIDs, oldTIDs+",%").
+			Scan(&groups).Error; err != nil {
+			return herrors.NewErrGetFailed(herrors.GroupInDB, err.Error())
+		}
+		for _, g := range groups {
+			traversalIDs := newTIDs + strings.TrimPrefix(g.TraversalIDs, oldTIDs)
+			if err := tx.Exec(dbcommon.GroupUpdateTraversalIDs, traversalIDs,
+				currentUser.GetID(), g.ID).Error; err != nil {
+				return herrors.NewErrUpdateFailed(herrors.GroupInDB, err.Error())
+			}
 		}
 
 		// commit when return nil
@@ -235,7 +257,7 @@ func (d *dao) CheckPathUnique(ctx context.Context, group *models.Group) error {
 
 func (d *dao) GetByNameFuzzily(ctx context.Context, name string, includeSoftDelete bool) ([]*models.Group, error) {
 	var groups []*models.Group
-	statement := d.db.Unscoped().WithContext(ctx).Where("name like ?", fmt.Sprintf("%%%s%%", name))
+	statement := d.db.Unscoped().WithContext(ctx).Where("lower(name) like lower(?)", fmt.Sprintf("%%%s%%", name))
 	if !includeSoftDelete {
 		statement.Where("deleted_ts = 0")
 	}
@@ -388,13 +410,17 @@ func (d *dao) ListWithoutPage(ctx context.Context, query *q.Query) ([]*models.Gr
 	return groups, result.Error
 }
 
-func (d *dao) List(ctx context.Context, query *q.Query) ([]*models.Group, int64, error) {
+func (d *dao) List(ctx context.Context, query *q.Query, excludedIDs ...uint) ([]*models.Group, int64, error) {
 	var groups []*models.Group
 
 	sort := orm.FormatSortExp(query)
 	offset := (query.PageNumber - 1) * query.PageSize
 	var count int64
-	result := d.db.WithContext(ctx).Order(sort).Where(query.Keywords).Offset(offset).Limit(query.PageSize).Find(&groups).
+	statement := d.db.WithContext(ctx).Where(query.Keywords)
+	if len(excludedIDs) > 0 {
+		statement = statement.Where("id not in ?", excludedIDs)
+	}
+	result := statement.Order(sort).Offset(offset).Limit(query.PageSize).Find(&groups).
 		Offset(-1).Count(&count)
 	if result.Error != nil {
 		return nil, 0, herrors.NewErrListFailed(herrors.GroupInDB, result.Error.Error())
@@ -452,7 +478,7 @@ func (d *dao) ListByTraversalIDsContains(ctx context.Context, ids []uint) ([]*mo
 				tdb = tdb.Or("traversal_ids like ?", fmt.Sprintf("%s,%%", group.TraversalIDs))
 			}
 		}
-		tdb.Find(&children)
+		result = tdb.Find(&children)
 		if result.Error != nil {
 			return herrors.NewErrListFailed(herrors.GroupInDB, result.Error.Error())
 		}
@@ -41,6 +41,9 @@ const (
 
 	// _parentID one of the field of the group table
 	_parentID = "parent_id"
+
+	// _visibilityLevel one of the field of the group table
+	_visibilityLevel = "visibility_level"
 )
 
 // nolint
@@ -67,14 +70,20 @@ type Manager interface {
 	GetAll(ctx context.Context) ([]*models.Group, error)
 	// UpdateBasic update basic info of a group
 	UpdateBasic(ctx context.Context, group *models.Group) error
+	// GetPrivateGroups return the groups marked as private, not including their subgroups
+	GetPrivateGroups(ctx context.Context) ([]*models.Group, error)
 	// GetSubGroupsUnderParentIDs get subgroups under the given parent groups without paging
 	GetSubGroupsUnderParentIDs(ctx context.Context, parentIDs []uint) ([]*models.Group, error)
 	// Transfer move a group under another parent group
 	Transfer(ctx context.Context, id, newParentID uint) error
-	// GetSubGroups get subgroups of a parent group, order by updateTime desc by default with paging
-	GetSubGroups(ctx context.Context, id uint, pageNumber, pageSize int) ([]*models.Group, int64, error)
-	// GetChildren get children of a parent group, order by updateTime desc by default with paging
-	GetChildren(ctx context.Context, parentID uint, pageNumber, pageSize int) ([]*models.GroupOrApplication, int64, error)
+	// GetSubGroups get subgroups of a parent group except the hidden ones,
+	// order by updateTime desc by default with paging
+	GetSubGroups(ctx context.Context, id uint, hiddenGroupIDs []uint,
+		pageNumber, pageSize int) ([]*models.Group, int64, error)
+	// GetChildren get children of a parent group except the hidden ones,
+	// order by updateTime desc by default with paging
+	GetChildren(ctx context.Context, parentID uint, hiddenGroupIDs, hiddenApplicationIDs []uint,
+		pageNumber, pageSize int) ([]*models.GroupOrApplication, int64, error)
 	// GetByNameOrPathUnderParent get by name or path under a specified parent
 	GetByNameOrPathUnderParent(ctx context.Context, name, path string, parentID uint) ([]*models.Group, error)
 	// GetSubGroupsByGroupIDs get groups and its subGroups by specified groupIDs
@@ -113,14 +122,15 @@ func New(db *gorm.DB) Manager {
 	}
 }
 
-func (m manager) GetChildren(ctx context.Context, parentID uint, pageNumber, pageSize int) (
-	[]*models.GroupOrApplication, int64, error) {
-	return m.groupDAO.ListChildren(ctx, parentID, pageNumber, pageSize)
+func (m manager) GetChildren(ctx context.Context, parentID uint, hiddenGroupIDs, hiddenApplicationIDs []uint,
+	pageNumber, pageSize int) ([]*models.GroupOrApplication, int64, error) {
+	return m.groupDAO.ListChildren(ctx, parentID, hiddenGroupIDs, hiddenApplicationIDs, pageNumber, pageSize)
 }
 
-func (m manager) GetSubGroups(ctx context.Context, id uint, pageNumber, pageSize int) ([]*models.Group, int64, error) {
+func (m manager) GetSubGroups(ctx context.Context, id uint, hiddenGroupIDs []uint,
+	pageNumber, pageSize int) ([]*models.Group, int64, error) {
 	query := formatListGroupQuery(id, pageNumber, pageSize)
-	return m.groupDAO.List(ctx, query)
+	return m.groupDAO.List(ctx, query, hiddenGroupIDs...)
 }
 
 func (m manager) Transfer(ctx context.Context, id, newParentID uint) error {
@@ -216,6 +226,13 @@ func (m manager) GetSubGroupsUnderParentIDs(ctx context.Context, parentIDs []uin
 	return m.groupDAO.ListWithoutPage(ctx, query)
 }
 
+func (m manager) GetPrivateGroups(ctx context.Context) ([]*models.Group, error) {
+	query := q.New(q.KeyWords{
+		_visibilityLevel: models.VisibilityPrivate,
+	})
+	return m.groupDAO.ListWithoutPage(ctx, query)
+}
+
 // checkApplicationExists check application is already exists under the same parent
 func (m manager) checkApplicationExists(ctx context.Context, group *models.Group) error {
 	apps, err := m.applicationDAO.GetByNamesUnderGroup(ctx,
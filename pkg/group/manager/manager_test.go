@@ -50,7 +50,7 @@ import (
 
 var (
 	// use tmp sqlite
-	db, _        = orm.NewSqliteDB("")
+	db, _        = orm.NewTestDB()
 	ctx          context.Context
 	notExistID   = uint(100)
 	Mgr          = New(db)
@@ -314,6 +314,8 @@ func TestTransferGroup(t *testing.T) {
 	assert.Nil(t, err)
 	_, err = Mgr.Create(ctx, getGroup(g3.ID, "2", "d"))
 	assert.Nil(t, err)
+	g5, err := Mgr.Create(ctx, getGroup(g2.ID, "Eve", "e"))
+	assert.Nil(t, err)
 
 	// not valid transfer: name conflict
 	err = Mgr.Transfer(ctx, g2.ID, g3.ID)
@@ -332,6 +334,17 @@ func TestTransferGroup(t *testing.T) {
 		strconv.Itoa(int(g2.ID)),
 	}
 	assert.Equal(t, strings.Join(expect, ","), group.TraversalIDs)
+
+	group, err = Mgr.GetByID(ctx, g5.ID)
+	assert.Nil(t, err)
+	expect = append(expect, strconv.Itoa(int(g5.ID)))
+	assert.Equal(t, strings.Join(expect, ","), group.TraversalIDs)
+
+	// search is case-insensitive and matches whole ids of traversal ids
+	groups, err := Mgr.GetByIDNameFuzzily(ctx, g1.ID, "eV")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(groups))
+	assert.Equal(t, g5.ID, groups[0].ID)
 }
 
 func TestManagerGetChildren(t *testing.T) {
@@ -372,20 +385,22 @@ func TestManagerGetChildren(t *testing.T) {
 						ID:        g2.ID,
 						UpdatedAt: g2.UpdatedAt,
 					},
-					Name:        "2",
-					Path:        "b",
-					Type:        "group",
-					Description: "",
+					Name:            "2",
+					Path:            "b",
+					VisibilityLevel: "private",
+					Type:            "group",
+					Description:     "",
 				},
 				{
 					Model: global.Model{
 						ID:        g1.ID,
 						UpdatedAt: g1.UpdatedAt,
 					},
-					Name:        "1",
-					Path:        "a",
-					Type:        "group",
-					Description: "",
+					Name:            "1",
+					Path:            "a",
+					VisibilityLevel: "private",
+					Type:            "group",
+					Description:     "",
 				},
 			},
 			want1: 3,
@@ -414,7 +429,7 @@ func TestManagerGetChildren(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, got1, err := Mgr.GetChildren(ctx, tt.args.parentID, tt.args.pageNumber, tt.args.pageSize)
+			got, got1, err := Mgr.GetChildren(ctx, tt.args.parentID, nil, nil, tt.args.pageNumber, tt.args.pageSize)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetChildren() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -22,6 +22,13 @@ import (
 
 const RootGroupID = 0
 
+const (
+	// VisibilityPrivate hides the group and everything under it from non-members
+	VisibilityPrivate = "private"
+	// VisibilityPublic exposes the group to everyone with the default role
+	VisibilityPublic = "public"
+)
+
 type Group struct {
 	global.Model
 	Name            string
@@ -35,6 +42,12 @@ type Group struct {
 	UpdatedBy       uint
 }
 
+// IsPrivate returns whether the group itself is private,
+// privacy inherited from parent groups is not considered
+func (g *Group) IsPrivate() bool {
+	return g.VisibilityLevel == VisibilityPrivate
+}
+
 type GroupRegionSelectors struct {
 	*Group
 	RegionSelectors RegionSelectors
@@ -42,10 +55,11 @@ type GroupRegionSelectors struct {
 
 type GroupOrApplication struct {
 	global.Model
-	Name        string
-	Path        string
-	Description string
-	Type        string
+	Name            string
+	Path            string
+	VisibilityLevel string
+	Description     string
+	Type            string
 }
 
 type Groups []*Group
@@ -67,14 +67,15 @@ func ConvertGroupToChild(group *models.Group, full *Full) *Child {
 // ConvertGroupOrApplicationToChild format Child based on groupOrApplication
 func ConvertGroupOrApplicationToChild(groupOrApplication *models.GroupOrApplication, full *Full) *Child {
 	return &Child{
-		ID:          groupOrApplication.ID,
-		Name:        groupOrApplication.Name,
-		Path:        groupOrApplication.Path,
-		Description: groupOrApplication.Description,
-		UpdatedAt:   groupOrApplication.UpdatedAt,
-		FullName:    full.FullName,
-		FullPath:    full.FullPath,
-		Type:        groupOrApplication.Type,
+		ID:              groupOrApplication.ID,
+		Name:            groupOrApplication.Name,
+		Path:            groupOrApplication.Path,
+		VisibilityLevel: groupOrApplication.VisibilityLevel,
+		Description:     groupOrApplication.Description,
+		UpdatedAt:       groupOrApplication.UpdatedAt,
+		FullName:        full.FullName,
+		FullPath:        full.FullPath,
+		Type:            groupOrApplication.Type,
 	}
 }
 
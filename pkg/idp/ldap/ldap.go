@@ -0,0 +1,190 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+
+	goldap "github.com/go-ldap/ldap/v3"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/idp/models"
+)
+
+// Conn is the part of ldap connection used by horizon
+type Conn interface {
+	Bind(username, password string) error
+	Search(request *goldap.SearchRequest) (*goldap.SearchResult, error)
+	Close()
+}
+
+// Dialer opens a connection to the server described by config
+type Dialer func(config *models.LDAPConfig) (Conn, error)
+
+// Dial is the default Dialer which connects to a real ldap server
+func Dial(config *models.LDAPConfig) (Conn, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify} // nolint
+	conn, err := goldap.DialURL(config.URL, goldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, perror.Wrapf(herrors.NewErrGetFailed(herrors.LDAPServer, err.Error()),
+			"failed to connect to ldap server %s", config.URL)
+	}
+	if config.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, perror.Wrapf(herrors.NewErrGetFailed(herrors.LDAPServer, err.Error()),
+				"failed to start tls with ldap server %s", config.URL)
+		}
+	}
+	return conn, nil
+}
+
+// Entry is the user authenticated by ldap server
+type Entry struct {
+	DN       string
+	Name     string
+	Email    string
+	FullName string
+	// Groups contains both names and dns of groups which the user belongs to
+	Groups []string
+}
+
+// Authenticate finds the user by name and verifies the password by binding as the user
+func Authenticate(ctx context.Context, dial Dialer, config *models.LDAPConfig,
+	username, password string) (*Entry, error) {
+	if config == nil || config.URL == "" || config.UserBaseDN == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "ldap config is incomplete")
+	}
+	// an empty password means unauthenticated bind, which always succeeds on many servers
+	if username == "" || password == "" {
+		return nil, perror.Wrap(herrors.ErrLDAPInvalidCredentials, "username and password are required")
+	}
+	config = config.WithDefaults()
+
+	conn, err := dial(config)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := bindServiceAccount(conn, config); err != nil {
+		return nil, err
+	}
+
+	result, err := conn.Search(goldap.NewSearchRequest(config.UserBaseDN,
+		goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 2, 0, false,
+		fillFilter(config.UserFilter, username),
+		[]string{config.NameAttribute, config.EmailAttribute, config.FullNameAttribute, "memberOf"}, nil))
+	if err != nil {
+		return nil, perror.Wrapf(herrors.NewErrGetFailed(herrors.LDAPServer, err.Error()),
+			"failed to search user %s", username)
+	}
+	if len(result.Entries) != 1 {
+		return nil, perror.Wrapf(herrors.ErrLDAPInvalidCredentials,
+			"expected exactly one entry of user %s, but got %d", username, len(result.Entries))
+	}
+	userEntry := result.Entries[0]
+
+	if err := conn.Bind(userEntry.DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return nil, perror.Wrapf(herrors.ErrLDAPInvalidCredentials, "failed to bind as %s", userEntry.DN)
+		}
+		return nil, perror.Wrapf(herrors.NewErrGetFailed(herrors.LDAPServer, err.Error()),
+			"failed to bind as %s", userEntry.DN)
+	}
+
+	entry := &Entry{
+		DN:       userEntry.DN,
+		Name:     userEntry.GetAttributeValue(config.NameAttribute),
+		Email:    userEntry.GetAttributeValue(config.EmailAttribute),
+		FullName: userEntry.GetAttributeValue(config.FullNameAttribute),
+	}
+	if entry.Name == "" {
+		entry.Name = username
+	}
+	if entry.FullName == "" {
+		entry.FullName = entry.Name
+	}
+
+	groups := make(map[string]struct{})
+	// active directory lists groups of user in attribute memberOf
+	for _, dn := range userEntry.GetAttributeValues("memberOf") {
+		groups[dn] = struct{}{}
+		if name := firstRDNValue(dn); name != "" {
+			groups[name] = struct{}{}
+		}
+	}
+	if config.GroupBaseDN != "" {
+		// search groups with the service account, the user may not have permission to do it
+		if err := bindServiceAccount(conn, config); err != nil {
+			return nil, err
+		}
+		result, err := conn.Search(goldap.NewSearchRequest(config.GroupBaseDN,
+			goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 0, 0, false,
+			fillFilter(config.GroupFilter, userEntry.DN),
+			[]string{config.GroupNameAttribute}, nil))
+		if err != nil {
+			return nil, perror.Wrapf(herrors.NewErrGetFailed(herrors.LDAPServer, err.Error()),
+				"failed to search groups of %s", userEntry.DN)
+		}
+		for _, groupEntry := range result.Entries {
+			groups[groupEntry.DN] = struct{}{}
+			if name := groupEntry.GetAttributeValue(config.GroupNameAttribute); name != "" {
+				groups[name] = struct{}{}
+			}
+		}
+	}
+	for group := range groups {
+		entry.Groups = append(entry.Groups, group)
+	}
+	return entry, nil
+}
+
+// InGroup checks whether the entry belongs to the group, group can be either name or dn
+func (e *Entry) InGroup(group string) bool {
+	for _, g := range e.Groups {
+		if strings.EqualFold(g, group) {
+			return true
+		}
+	}
+	return false
+}
+
+func bindServiceAccount(conn Conn, config *models.LDAPConfig) error {
+	if config.BindDN == "" {
+		return nil
+	}
+	if err := conn.Bind(config.BindDN, config.BindPassword); err != nil {
+		return perror.Wrapf(herrors.NewErrGetFailed(herrors.LDAPServer, err.Error()),
+			"failed to bind as service account %s", config.BindDN)
+	}
+	return nil
+}
+
+func fillFilter(filter, value string) string {
+	return strings.ReplaceAll(filter, "%s", goldap.EscapeFilter(value))
+}
+
+// firstRDNValue returns "admins" for dn "cn=admins,ou=groups,dc=example,dc=com"
+func firstRDNValue(dn string) string {
+	parsed, err := goldap.ParseDN(dn)
+	if err != nil || len(parsed.RDNs) == 0 || len(parsed.RDNs[0].Attributes) == 0 {
+		return ""
+	}
+	return parsed.RDNs[0].Attributes[0].Value
+}
@@ -0,0 +1,101 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldap_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/idp/ldap"
+	"github.com/horizoncd/horizon/pkg/idp/ldap/ldaptest"
+	"github.com/horizoncd/horizon/pkg/idp/models"
+)
+
+func newDirectory() *ldaptest.Directory {
+	d := ldaptest.NewDirectory()
+	d.AddEntry("cn=admin,dc=example,dc=com", "admin-secret", nil)
+	d.AddEntry("uid=tom,ou=people,dc=example,dc=com", "tom-secret", map[string][]string{
+		"uid":      {"tom"},
+		"mail":     {"tom@example.com"},
+		"cn":       {"Tom Cat"},
+		"memberOf": {"cn=ops,ou=ad,dc=example,dc=com"},
+	})
+	d.AddEntry("uid=jerry,ou=people,dc=example,dc=com", "", map[string][]string{
+		"uid":  {"jerry"},
+		"mail": {"jerry@example.com"},
+	})
+	d.AddEntry("cn=developers,ou=groups,dc=example,dc=com", "", map[string][]string{
+		"cn":     {"developers"},
+		"member": {"uid=tom,ou=people,dc=example,dc=com", "uid=jerry,ou=people,dc=example,dc=com"},
+	})
+	d.AddEntry("cn=admins,ou=groups,dc=example,dc=com", "", map[string][]string{
+		"cn":     {"admins"},
+		"member": {"uid=jerry,ou=people,dc=example,dc=com"},
+	})
+	return d
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	d := newDirectory()
+	config := &models.LDAPConfig{
+		URL:          "ldap://ldap.example.com",
+		BindDN:       "cn=admin,dc=example,dc=com",
+		BindPassword: "admin-secret",
+		UserBaseDN:   "ou=people,dc=example,dc=com",
+		GroupBaseDN:  "ou=groups,dc=example,dc=com",
+	}
+
+	entry, err := ldap.Authenticate(ctx, d.Dial, config, "tom", "tom-secret")
+	assert.Nil(t, err)
+	assert.Equal(t, "uid=tom,ou=people,dc=example,dc=com", entry.DN)
+	assert.Equal(t, "tom", entry.Name)
+	assert.Equal(t, "tom@example.com", entry.Email)
+	assert.Equal(t, "Tom Cat", entry.FullName)
+	assert.True(t, entry.InGroup("developers"))
+	assert.True(t, entry.InGroup("CN=developers,ou=groups,dc=example,dc=com"))
+	assert.True(t, entry.InGroup("ops"))
+	assert.False(t, entry.InGroup("admins"))
+
+	_, err = ldap.Authenticate(ctx, d.Dial, config, "tom", "wrong")
+	assert.Equal(t, herrors.ErrLDAPInvalidCredentials, perror.Cause(err))
+
+	_, err = ldap.Authenticate(ctx, d.Dial, config, "tom", "")
+	assert.Equal(t, herrors.ErrLDAPInvalidCredentials, perror.Cause(err))
+
+	_, err = ldap.Authenticate(ctx, d.Dial, config, "nobody", "tom-secret")
+	assert.Equal(t, herrors.ErrLDAPInvalidCredentials, perror.Cause(err))
+
+	// wildcards in username must not match other users
+	_, err = ldap.Authenticate(ctx, d.Dial, config, "t*", "tom-secret")
+	assert.Equal(t, herrors.ErrLDAPInvalidCredentials, perror.Cause(err))
+
+	// jerry has no password, so binding as jerry always fails
+	_, err = ldap.Authenticate(ctx, d.Dial, config, "jerry", "anything")
+	assert.Equal(t, herrors.ErrLDAPInvalidCredentials, perror.Cause(err))
+
+	wrongServiceAccount := *config
+	wrongServiceAccount.BindPassword = "wrong"
+	_, err = ldap.Authenticate(ctx, d.Dial, &wrongServiceAccount, "tom", "tom-secret")
+	_, ok := perror.Cause(err).(*herrors.HorizonErrGetFailed)
+	assert.True(t, ok)
+
+	_, err = ldap.Authenticate(ctx, d.Dial, &models.LDAPConfig{}, "tom", "tom-secret")
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
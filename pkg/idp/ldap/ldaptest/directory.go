@@ -0,0 +1,216 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ldaptest provides an in-process ldap directory for testing.
+package ldaptest
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	goldap "github.com/go-ldap/ldap/v3"
+
+	"github.com/horizoncd/horizon/pkg/idp/ldap"
+	"github.com/horizoncd/horizon/pkg/idp/models"
+)
+
+type entry struct {
+	dn         string
+	password   string
+	attributes map[string][]string
+}
+
+// Directory is an in-memory ldap directory which supports simple bind and
+// searching with equality, presence, and, or, not filters
+type Directory struct {
+	sync.RWMutex
+	entries []*entry
+}
+
+func NewDirectory() *Directory {
+	return &Directory{}
+}
+
+// AddEntry adds an entry which can be bound with password, empty password disables binding
+func (d *Directory) AddEntry(dn, password string, attributes map[string][]string) {
+	d.Lock()
+	defer d.Unlock()
+	d.entries = append(d.entries, &entry{dn: dn, password: password, attributes: attributes})
+}
+
+// Dial implements ldap.Dialer
+func (d *Directory) Dial(config *models.LDAPConfig) (ldap.Conn, error) {
+	return &conn{directory: d}, nil
+}
+
+type conn struct {
+	directory *Directory
+}
+
+func (c *conn) Bind(username, password string) error {
+	c.directory.RLock()
+	defer c.directory.RUnlock()
+	for _, e := range c.directory.entries {
+		if strings.EqualFold(e.dn, username) && e.password != "" && e.password == password {
+			return nil
+		}
+	}
+	return goldap.NewError(goldap.LDAPResultInvalidCredentials,
+		fmt.Errorf("invalid credentials of %s", username))
+}
+
+func (c *conn) Search(request *goldap.SearchRequest) (*goldap.SearchResult, error) {
+	c.directory.RLock()
+	defer c.directory.RUnlock()
+
+	result := &goldap.SearchResult{}
+	for _, e := range c.directory.entries {
+		if !strings.HasSuffix(strings.ToLower(e.dn), strings.ToLower(request.BaseDN)) {
+			continue
+		}
+		matched, rest, err := match(request.Filter, e)
+		if err != nil || rest != "" {
+			return nil, goldap.NewError(goldap.LDAPResultFilterError,
+				fmt.Errorf("invalid filter %s", request.Filter))
+		}
+		if !matched {
+			continue
+		}
+		attributes := make(map[string][]string)
+		for _, name := range request.Attributes {
+			if values := e.get(name); len(values) > 0 {
+				attributes[name] = values
+			}
+		}
+		result.Entries = append(result.Entries, goldap.NewEntry(e.dn, attributes))
+		if request.SizeLimit > 0 && len(result.Entries) >= request.SizeLimit {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (c *conn) Close() {}
+
+func (e *entry) get(name string) []string {
+	for k, v := range e.attributes {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+// match evaluates the first filter in f against entry, and returns the unparsed remainder
+func match(f string, e *entry) (bool, string, error) {
+	if !strings.HasPrefix(f, "(") {
+		return false, "", fmt.Errorf("filter should start with (: %s", f)
+	}
+	f = f[1:]
+	if f == "" {
+		return false, "", fmt.Errorf("unexpected end of filter")
+	}
+	switch f[0] {
+	case '&', '|', '!':
+		op := f[0]
+		f = f[1:]
+		results := make([]bool, 0)
+		for strings.HasPrefix(f, "(") {
+			var (
+				matched bool
+				err     error
+			)
+			matched, f, err = match(f, e)
+			if err != nil {
+				return false, "", err
+			}
+			results = append(results, matched)
+		}
+		if !strings.HasPrefix(f, ")") {
+			return false, "", fmt.Errorf("filter should end with ): %s", f)
+		}
+		f = f[1:]
+		switch op {
+		case '&':
+			for _, r := range results {
+				if !r {
+					return false, f, nil
+				}
+			}
+			return true, f, nil
+		case '|':
+			for _, r := range results {
+				if r {
+					return true, f, nil
+				}
+			}
+			return false, f, nil
+		default:
+			if len(results) != 1 {
+				return false, "", fmt.Errorf("not filter should have exactly one child")
+			}
+			return !results[0], f, nil
+		}
+	}
+
+	end := strings.Index(f, ")")
+	if end < 0 {
+		return false, "", fmt.Errorf("filter should end with ): %s", f)
+	}
+	item, rest := f[:end], f[end+1:]
+	parts := strings.SplitN(item, "=", 2)
+	if len(parts) != 2 {
+		return false, "", fmt.Errorf("invalid filter item: %s", item)
+	}
+	values := e.get(parts[0])
+	if strings.EqualFold(parts[0], "objectClass") && parts[1] == "*" {
+		return true, rest, nil
+	}
+	if parts[1] == "*" {
+		return len(values) > 0, rest, nil
+	}
+	expected, err := unescape(parts[1])
+	if err != nil {
+		return false, "", err
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, expected) {
+			return true, rest, nil
+		}
+	}
+	return false, rest, nil
+}
+
+// unescape decodes the \XX sequences produced by goldap.EscapeFilter
+func unescape(value string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' {
+			b.WriteByte(value[i])
+			continue
+		}
+		if i+2 >= len(value) {
+			return "", fmt.Errorf("invalid escape sequence in %s", value)
+		}
+		bts, err := hex.DecodeString(value[i+1 : i+3])
+		if err != nil {
+			return "", err
+		}
+		b.Write(bts)
+		i += 2
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,118 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	DefaultLDAPUserFilter         = "(uid=%s)"
+	DefaultLDAPNameAttribute      = "uid"
+	DefaultLDAPEmailAttribute     = "mail"
+	DefaultLDAPFullNameAttribute  = "cn"
+	DefaultLDAPGroupFilter        = "(|(member=%s)(uniqueMember=%s))"
+	DefaultLDAPGroupNameAttribute = "cn"
+)
+
+// LDAPConfig describes how to authenticate users against a ldap or active directory server
+type LDAPConfig struct {
+	// URL is the address of server, like ldap://ldap.example.com:389 or ldaps://ldap.example.com:636
+	URL                string `json:"url"`
+	StartTLS           bool   `json:"startTLS,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+	// BindDN and BindPassword are the credentials of the service account used to search users and groups,
+	// anonymous search is used when BindDN is empty
+	BindDN       string `json:"bindDN,omitempty"`
+	BindPassword string `json:"bindPassword,omitempty"`
+
+	UserBaseDN string `json:"userBaseDN"`
+	// UserFilter is the filter to find user by login name, %s is replaced by the escaped name.
+	// For active directory, it's usually (sAMAccountName=%s)
+	UserFilter        string `json:"userFilter,omitempty"`
+	NameAttribute     string `json:"nameAttribute,omitempty"`
+	EmailAttribute    string `json:"emailAttribute,omitempty"`
+	FullNameAttribute string `json:"fullNameAttribute,omitempty"`
+
+	// GroupBaseDN enables group sync when it's not empty
+	GroupBaseDN string `json:"groupBaseDN,omitempty"`
+	// GroupFilter is the filter to find groups of user, %s is replaced by the escaped dn of user
+	GroupFilter        string `json:"groupFilter,omitempty"`
+	GroupNameAttribute string `json:"groupNameAttribute,omitempty"`
+	// GroupMappings binds members of ldap groups to horizon groups
+	GroupMappings []LDAPGroupMapping `json:"groupMappings,omitempty"`
+}
+
+// LDAPGroupMapping grants Role of horizon group GroupID to members of ldap group LDAPGroup
+type LDAPGroupMapping struct {
+	// LDAPGroup is the name or dn of ldap group
+	LDAPGroup string `json:"ldapGroup"`
+	GroupID   uint   `json:"groupID"`
+	Role      string `json:"role"`
+}
+
+// WithDefaults returns a copy of config with empty fields set to default values
+func (c LDAPConfig) WithDefaults() *LDAPConfig {
+	if c.UserFilter == "" {
+		c.UserFilter = DefaultLDAPUserFilter
+	}
+	if c.NameAttribute == "" {
+		c.NameAttribute = DefaultLDAPNameAttribute
+	}
+	if c.EmailAttribute == "" {
+		c.EmailAttribute = DefaultLDAPEmailAttribute
+	}
+	if c.FullNameAttribute == "" {
+		c.FullNameAttribute = DefaultLDAPFullNameAttribute
+	}
+	if c.GroupFilter == "" {
+		c.GroupFilter = DefaultLDAPGroupFilter
+	}
+	if c.GroupNameAttribute == "" {
+		c.GroupNameAttribute = DefaultLDAPGroupNameAttribute
+	}
+	return &c
+}
+
+func (c *LDAPConfig) Scan(value interface{}) error {
+	var bts []byte
+	switch v := value.(type) {
+	case []byte:
+		bts = v
+	case string:
+		bts = []byte(v)
+	case nil:
+		return nil
+	default:
+		return fmt.Errorf("failed to unmarshal LDAPConfig from value: %v", value)
+	}
+	if len(bts) == 0 {
+		return nil
+	}
+	return json.Unmarshal(bts, c)
+}
+
+func (c *LDAPConfig) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	bts, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(bts), nil
+}
@@ -18,6 +18,7 @@ import (
 	"database/sql/driver"
 	"fmt"
 
+	"github.com/horizoncd/horizon/lib/encrypt"
 	"github.com/horizoncd/horizon/pkg/server/global"
 )
 
@@ -37,7 +38,21 @@ type IdentityProvider struct {
 	TokenEndpointAuthMethod *TokenEndpointAuthMethod
 	Jwks                    string
 	ClientID                string
-	ClientSecret            string
+	ClientSecret            encrypt.String
+	// Kind is the protocol of the provider, empty means oidc
+	Kind       Kind
+	LDAPConfig *LDAPConfig `gorm:"column:ldap_config"`
+}
+
+type Kind string
+
+const (
+	KindOIDC Kind = "oidc"
+	KindLDAP Kind = "ldap"
+)
+
+func (idp *IdentityProvider) IsLDAP() bool {
+	return idp.Kind == KindLDAP
 }
 
 type TokenEndpointAuthMethod uint8
@@ -94,6 +109,10 @@ func (t *TokenEndpointAuthMethod) Scan(value interface{}) error {
 }
 
 func (t *TokenEndpointAuthMethod) Value() (driver.Value, error) {
+	// identity providers like ldap have no token endpoint
+	if t == nil {
+		return nil, nil
+	}
 	return t.String()
 }
 
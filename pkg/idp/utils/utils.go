@@ -30,6 +30,8 @@ type Claims struct {
 	Sub   string `json:"sub"`
 	Name  string `json:"name"`
 	Email string `json:"email"`
+	// Groups is the groups claim, which is used to sync teams
+	Groups []string `json:"groups"`
 }
 
 func MakeOuath2Config(ctx context.Context, idp *models.IdentityProvider,
@@ -40,7 +42,7 @@ func MakeOuath2Config(ctx context.Context, idp *models.IdentityProvider,
 		idp.TokenEndpoint != "" {
 		conf := &oauth2.Config{
 			ClientID:     idp.ClientID,
-			ClientSecret: idp.ClientSecret,
+			ClientSecret: string(idp.ClientSecret),
 			Endpoint: oauth2.Endpoint{
 				AuthURL:  idp.AuthorizationEndpoint,
 				TokenURL: idp.TokenEndpoint},
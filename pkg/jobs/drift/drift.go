@@ -0,0 +1,309 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/email"
+	"github.com/horizoncd/horizon/lib/q"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	"github.com/horizoncd/horizon/pkg/cd"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	clusterdriftmanager "github.com/horizoncd/horizon/pkg/clusterdrift/manager"
+	clusterdriftmodels "github.com/horizoncd/horizon/pkg/clusterdrift/models"
+	driftconfig "github.com/horizoncd/horizon/pkg/config/drift"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	membermanager "github.com/horizoncd/horizon/pkg/member/manager"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	defaultInterval = 10 * time.Minute
+	batchSize       = 100
+)
+
+// Extra is the extra info of drift events
+type Extra struct {
+	Resources []cd.ResourceDrift `json:"resources,omitempty"`
+	// Resynced is true if the last config commit has been deployed to overwrite drifted objects
+	Resynced bool `json:"resynced,omitempty"`
+}
+
+// Detector detects configuration drift between gitops repo and live objects of clusters
+// in configured environments periodically. Drift events are recorded when drift is detected or resolved,
+// so that webhooks are triggered, members of clusters are notified by email and drifted objects are
+// overwritten by resyncing if the environment is configured to.
+type Detector struct {
+	config         driftconfig.Config
+	sender         email.Sender
+	cd             cd.CD
+	clusterGitRepo gitrepo.ClusterGitRepo
+	clusterMgr     clustermanager.Manager
+	applicationMgr applicationmanager.Manager
+	regionMgr      regionmanager.Manager
+	driftMgr       clusterdriftmanager.Manager
+	memberMgr      membermanager.Manager
+	userMgr        usermanager.Manager
+	eventSvc       eventservice.Service
+}
+
+func New(config driftconfig.Config, sender email.Sender, parameter *param.Param) *Detector {
+	if config.Interval <= 0 {
+		config.Interval = defaultInterval
+	}
+	return &Detector{
+		config:         config,
+		sender:         sender,
+		cd:             parameter.CD,
+		clusterGitRepo: parameter.ClusterGitRepo,
+		clusterMgr:     parameter.ClusterMgr,
+		applicationMgr: parameter.ApplicationMgr,
+		regionMgr:      parameter.RegionMgr,
+		driftMgr:       parameter.ClusterDriftMgr,
+		memberMgr:      parameter.MemberMgr,
+		userMgr:        parameter.UserMgr,
+		eventSvc:       eventservice.New(parameter.Manager),
+	}
+}
+
+func (d *Detector) Run(ctx context.Context) {
+	if !d.config.Enabled || len(d.config.Environments) == 0 {
+		return
+	}
+	log.Infof(ctx, "Starting detecting drift of clusters every %v", d.config.Interval)
+	defer log.Infof(ctx, "Stopping detecting drift of clusters")
+	ticker := time.NewTicker(d.config.Interval)
+	defer ticker.Stop()
+	for {
+		d.detect(ctx, time.Now())
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (d *Detector) detect(ctx context.Context, now time.Time) {
+	environments := make([]string, 0, len(d.config.Environments))
+	for environment := range d.config.Environments {
+		environments = append(environments, environment)
+	}
+	sort.Strings(environments)
+
+	cursor := uint(0)
+	for {
+		clusters, err := d.clusterMgr.ListRunningClusters(ctx, &q.Query{
+			PageNumber: common.DefaultPageNumber,
+			PageSize:   batchSize,
+			Keywords: q.KeyWords{
+				common.IDThan:                  cursor,
+				common.ClusterQueryEnvironment: environments,
+			},
+		})
+		if err != nil {
+			log.Errorf(ctx, "failed to list clusters: %v", err)
+			return
+		}
+		if len(clusters) == 0 {
+			return
+		}
+		for _, cluster := range clusters {
+			cursor = cluster.ID
+			if err := d.detectCluster(ctx, cluster, now); err != nil {
+				log.Errorf(ctx, "failed to detect drift of cluster %s: %v", cluster.Name, err)
+			}
+		}
+	}
+}
+
+func (d *Detector) detectCluster(ctx context.Context, cluster *clustermodels.Cluster, now time.Time) error {
+	application, err := d.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return err
+	}
+	regionEntity, err := d.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return err
+	}
+	drift, err := d.cd.GetClusterDrift(ctx, &cd.GetClusterDriftParams{
+		Application:  application.Name,
+		Environment:  cluster.EnvironmentName,
+		Cluster:      cluster.Name,
+		RegionEntity: regionEntity,
+	})
+	if err != nil {
+		// the cluster has not been deployed yet
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil
+		}
+		return err
+	}
+
+	resources, err := json.Marshal(drift.Resources)
+	if err != nil {
+		return err
+	}
+	current := &clusterdriftmodels.ClusterDrift{
+		ClusterID: cluster.ID,
+		Drifted:   drift.Drifted,
+		CheckedAt: now,
+	}
+	if drift.Drifted {
+		current.Resources = string(resources)
+		current.DetectedAt = &now
+	}
+	previous, err := d.driftMgr.Upsert(ctx, current)
+	if err != nil {
+		return err
+	}
+
+	if !drift.Drifted {
+		if previous != nil && previous.Drifted {
+			d.recordEvent(ctx, cluster.ID, eventmodels.ClusterDriftResolved, &Extra{})
+		}
+		return nil
+	}
+	// the same drift has been handled
+	if previous != nil && previous.Drifted && previous.Resources == current.Resources {
+		return nil
+	}
+
+	envConfig := d.config.Environments[cluster.EnvironmentName]
+	extra := &Extra{Resources: drift.Resources}
+	if envConfig.AutoResync {
+		if err := d.resync(ctx, application.Name, cluster); err != nil {
+			log.Errorf(ctx, "failed to resync cluster %s: %v", cluster.Name, err)
+		} else {
+			extra.Resynced = true
+		}
+	}
+	d.recordEvent(ctx, cluster.ID, eventmodels.ClusterDrifted, extra)
+	if envConfig.Notify {
+		if err := d.notify(ctx, cluster, drift, extra.Resynced); err != nil {
+			log.Errorf(ctx, "failed to notify drift of cluster %s: %v", cluster.Name, err)
+		}
+	}
+	return nil
+}
+
+// resync deploys the last config commit to overwrite drifted objects
+func (d *Detector) resync(ctx context.Context, application string, cluster *clustermodels.Cluster) error {
+	commit, err := d.clusterGitRepo.GetConfigCommit(ctx, application, cluster.Name)
+	if err != nil {
+		return err
+	}
+	return d.cd.DeployCluster(ctx, &cd.DeployClusterParams{
+		Application: application,
+		Environment: cluster.EnvironmentName,
+		Cluster:     cluster.Name,
+		Revision:    commit.Master,
+		Region:      cluster.RegionName,
+	})
+}
+
+func (d *Detector) recordEvent(ctx context.Context, clusterID uint, eventType string, extra *Extra) {
+	data, err := json.Marshal(extra)
+	if err != nil {
+		log.Warningf(ctx, "failed to marshal extra of event: %v", err)
+		return
+	}
+	extraStr := string(data)
+	d.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, clusterID, eventType, &extraStr)
+}
+
+// notify sends emails to user members of the cluster
+func (d *Detector) notify(ctx context.Context, cluster *clustermodels.Cluster,
+	drift *cd.ClusterDrift, resynced bool) error {
+	if d.sender == nil {
+		return nil
+	}
+	members, err := d.memberMgr.ListDirectMember(ctx, membermodels.TypeApplicationCluster, cluster.ID)
+	if err != nil {
+		return err
+	}
+	userIDs := make([]uint, 0, len(members))
+	for _, member := range members {
+		if member.MemberType == membermodels.MemberUser {
+			userIDs = append(userIDs, member.MemberNameID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+	users, err := d.userMgr.GetUserByIDs(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+	to := make([]string, 0, len(users))
+	for _, user := range users {
+		if user.Email != "" {
+			to = append(to, user.Email)
+		}
+	}
+	if len(to) == 0 {
+		return nil
+	}
+
+	subject := fmt.Sprintf("[Horizon] Configuration drift detected in cluster %s", cluster.Name)
+	return d.sender.Send(ctx, to, subject, emailBody(cluster, drift, resynced))
+}
+
+func emailBody(cluster *clustermodels.Cluster, drift *cd.ClusterDrift, resynced bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Live objects of cluster %s (environment: %s) differ from its gitops repo, "+
+		"they may have been modified without Horizon, like by kubectl edit.\n\n", cluster.Name,
+		cluster.EnvironmentName)
+	for _, resource := range drift.Resources {
+		fmt.Fprintf(&b, "%s %s", resource.Kind, resource.Name)
+		if resource.Namespace != "" {
+			fmt.Fprintf(&b, " in namespace %s", resource.Namespace)
+		}
+		if resource.Missing {
+			b.WriteString(": missing\n")
+			continue
+		}
+		b.WriteString(":\n")
+		for _, field := range resource.Fields {
+			if field.Redacted {
+				fmt.Fprintf(&b, "  %s: changed\n", field.Path)
+				continue
+			}
+			fmt.Fprintf(&b, "  %s: desired %v, live %v\n", field.Path, field.Desired, field.Live)
+		}
+	}
+	if resynced {
+		b.WriteString("\nThe last configuration has been redeployed to overwrite the drifted objects.\n")
+	} else {
+		b.WriteString("\nPlease update the configuration in Horizon, or redeploy the cluster to revert the changes.\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,196 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	cdmock "github.com/horizoncd/horizon/mock/pkg/cd"
+	gitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/cd"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	clusterdriftmodels "github.com/horizoncd/horizon/pkg/clusterdrift/models"
+	driftconfig "github.com/horizoncd/horizon/pkg/config/drift"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+type sentEmail struct {
+	to      []string
+	subject string
+}
+
+type fakeSender struct {
+	sent []sentEmail
+}
+
+func (s *fakeSender) Send(_ context.Context, to []string, subject, _ string) error {
+	s.sent = append(s.sent, sentEmail{to: to, subject: subject})
+	return nil
+}
+
+func TestDetector(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	err = db.AutoMigrate(&appmodels.Application{}, &clustermodels.Cluster{}, &regionmodels.Region{},
+		&registrymodels.Registry{}, &usermodels.User{}, &membermodels.Member{}, &eventmodels.Event{},
+		&clusterdriftmodels.ClusterDrift{})
+	assert.Nil(t, err)
+
+	ctx := context.TODO()
+	mgr := managerparam.InitManager(db)
+
+	registry := &registrymodels.Registry{Name: "registry"}
+	assert.Nil(t, db.Create(registry).Error)
+	assert.Nil(t, db.Create(&regionmodels.Region{Name: "hz", RegistryID: registry.ID}).Error)
+	application := &appmodels.Application{Name: "app"}
+	assert.Nil(t, db.Create(application).Error)
+	online := &clustermodels.Cluster{
+		ApplicationID:   application.ID,
+		Name:            "app-online",
+		EnvironmentName: "online",
+		RegionName:      "hz",
+	}
+	assert.Nil(t, db.Create(online).Error)
+	// clusters in environments not configured are not detected
+	assert.Nil(t, db.Create(&clustermodels.Cluster{
+		ApplicationID:   application.ID,
+		Name:            "app-test",
+		EnvironmentName: "test",
+		RegionName:      "hz",
+	}).Error)
+	user, err := mgr.UserMgr.Create(ctx, &usermodels.User{Name: "owner", Email: "owner@horizon.org"})
+	assert.Nil(t, err)
+	_, err = mgr.MemberMgr.Create(ctx, &membermodels.Member{
+		ResourceType: membermodels.TypeApplicationCluster,
+		ResourceID:   online.ID,
+		Role:         "owner",
+		MemberType:   membermodels.MemberUser,
+		MemberNameID: user.ID,
+	})
+	assert.Nil(t, err)
+
+	mockCtl := gomock.NewController(t)
+	cdMock := cdmock.NewMockCD(mockCtl)
+	clusterGitRepo := gitrepomock.NewMockClusterGitRepo(mockCtl)
+	sender := &fakeSender{}
+	detector := New(driftconfig.Config{
+		Enabled: true,
+		Environments: map[string]driftconfig.EnvironmentConfig{
+			"online": {Notify: true, AutoResync: true},
+		},
+	}, sender, &param.Param{
+		Manager:        mgr,
+		CD:             cdMock,
+		ClusterGitRepo: clusterGitRepo,
+	})
+
+	drift := &cd.ClusterDrift{
+		Drifted: true,
+		Resources: []cd.ResourceDrift{
+			{
+				Group:     "apps",
+				Kind:      "Deployment",
+				Namespace: "online",
+				Name:      "app-online",
+				Fields:    []cd.FieldDrift{{Path: "spec.replicas", Desired: 2, Live: 1}},
+			},
+		},
+	}
+	cdMock.EXPECT().GetClusterDrift(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, params *cd.GetClusterDriftParams) (*cd.ClusterDrift, error) {
+			assert.Equal(t, "app", params.Application)
+			assert.Equal(t, "app-online", params.Cluster)
+			assert.Equal(t, "hz", params.RegionEntity.Name)
+			return drift, nil
+		}).Times(2)
+	clusterGitRepo.EXPECT().GetConfigCommit(gomock.Any(), "app", "app-online").
+		Return(&gitrepo.ClusterCommit{Master: "commit"}, nil).Times(1)
+	cdMock.EXPECT().DeployCluster(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, params *cd.DeployClusterParams) error {
+			assert.Equal(t, "commit", params.Revision)
+			return nil
+		}).Times(1)
+
+	now := time.Now()
+	detector.detect(ctx, now)
+	assert.Equal(t, 1, len(sender.sent))
+	assert.Equal(t, []string{user.Email}, sender.sent[0].to)
+	clusterDrift, err := mgr.ClusterDriftMgr.GetByClusterID(ctx, online.ID)
+	assert.Nil(t, err)
+	assert.True(t, clusterDrift.Drifted)
+	events, err := mgr.EventMgr.ListEventsByRange(ctx, 0, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, eventmodels.ClusterDrifted, events[0].EventType)
+	var extra Extra
+	assert.Nil(t, json.Unmarshal([]byte(*events[0].Extra), &extra))
+	assert.True(t, extra.Resynced)
+	assert.Equal(t, "spec.replicas", extra.Resources[0].Fields[0].Path)
+
+	// the same drift is not handled again
+	detector.detect(ctx, now.Add(time.Minute))
+	assert.Equal(t, 1, len(sender.sent))
+	events, err = mgr.EventMgr.ListEventsByRange(ctx, 0, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(events))
+
+	cdMock.EXPECT().GetClusterDrift(gomock.Any(), gomock.Any()).Return(&cd.ClusterDrift{}, nil).Times(1)
+	detector.detect(ctx, now.Add(2*time.Minute))
+	clusterDrift, err = mgr.ClusterDriftMgr.GetByClusterID(ctx, online.ID)
+	assert.Nil(t, err)
+	assert.False(t, clusterDrift.Drifted)
+	events, err = mgr.EventMgr.ListEventsByRange(ctx, 0, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, eventmodels.ClusterDriftResolved, events[1].EventType)
+}
+
+func TestEmailBody(t *testing.T) {
+	body := emailBody(&clustermodels.Cluster{Name: "app-online", EnvironmentName: "online"}, &cd.ClusterDrift{
+		Drifted: true,
+		Resources: []cd.ResourceDrift{
+			{
+				Group:     "apps",
+				Kind:      "Deployment",
+				Namespace: "online",
+				Name:      "app-online",
+				Fields:    []cd.FieldDrift{{Path: "spec.replicas", Desired: 2, Live: 1}},
+			},
+			{
+				Kind:      "Secret",
+				Namespace: "online",
+				Name:      "app-online",
+				Fields:    []cd.FieldDrift{{Path: "data.password", Redacted: true}},
+			},
+		},
+	}, false)
+	assert.Contains(t, body, "spec.replicas: desired 2, live 1")
+	assert.Contains(t, body, "data.password: changed")
+}
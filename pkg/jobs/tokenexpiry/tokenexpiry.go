@@ -0,0 +1,190 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenexpiry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/email"
+	tokenconfig "github.com/horizoncd/horizon/pkg/config/token"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	membermanager "github.com/horizoncd/horizon/pkg/member/manager"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	defaultDaysBefore = 7
+	defaultInterval   = time.Hour
+	batchSize         = 100
+	timeFormat        = "2006-01-02 15:04:05"
+)
+
+// Extra is the extra info of access token expiring events
+type Extra struct {
+	TokenID   uint      `json:"tokenID"`
+	TokenName string    `json:"tokenName"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Notifier notifies owners of access tokens which are about to expire,
+// personal tokens are notified to the user by email, and resource tokens are notified to
+// the creator by email and to the resources by events, so that webhooks of them are triggered.
+type Notifier struct {
+	config    tokenconfig.ExpiryNotification
+	sender    email.Sender
+	tokenMgr  tokenmanager.Manager
+	userMgr   usermanager.Manager
+	memberMgr membermanager.Manager
+	eventSvc  eventservice.Service
+}
+
+func New(config tokenconfig.ExpiryNotification, sender email.Sender, mgr *managerparam.Manager) *Notifier {
+	if config.DaysBefore <= 0 {
+		config.DaysBefore = defaultDaysBefore
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaultInterval
+	}
+	return &Notifier{
+		config:    config,
+		sender:    sender,
+		tokenMgr:  mgr.TokenMgr,
+		userMgr:   mgr.UserMgr,
+		memberMgr: mgr.MemberMgr,
+		eventSvc:  eventservice.New(mgr),
+	}
+}
+
+func (n *Notifier) Run(ctx context.Context) {
+	if !n.config.Enabled {
+		return
+	}
+	log.Infof(ctx, "Starting notifying expiring access tokens every %v", n.config.Interval)
+	defer log.Infof(ctx, "Stopping notifying expiring access tokens")
+	ticker := time.NewTicker(n.config.Interval)
+	defer ticker.Stop()
+	for {
+		n.check(ctx, time.Now())
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (n *Notifier) check(ctx context.Context, now time.Time) {
+	deadline := now.Add(time.Duration(n.config.DaysBefore) * 24 * time.Hour)
+	cursor := uint(0)
+	for {
+		tokens, err := n.tokenMgr.ListExpirableAccessTokens(ctx, cursor, batchSize)
+		if err != nil {
+			log.Errorf(ctx, "failed to list access tokens: %v", err)
+			return
+		}
+		if len(tokens) == 0 {
+			return
+		}
+		for _, token := range tokens {
+			cursor = token.ID
+			expiresAt, ok := token.ExpiresAt()
+			if !ok || expiresAt.After(deadline) {
+				continue
+			}
+			// tokens which have already expired are only marked, it's too late to notify
+			if expiresAt.After(now) {
+				if err := n.notify(ctx, token, expiresAt); err != nil {
+					log.Errorf(ctx, "failed to notify expiration of token %d: %v", token.ID, err)
+					continue
+				}
+			}
+			if err := n.tokenMgr.UpdateExpiryNotifiedAt(ctx, token.ID, now); err != nil {
+				log.Errorf(ctx, "failed to mark token %d as notified: %v", token.ID, err)
+			}
+		}
+	}
+}
+
+func (n *Notifier) notify(ctx context.Context, token *tokenmodels.Token, expiresAt time.Time) error {
+	user, err := n.userMgr.GetUserByID(ctx, token.UserID)
+	if err != nil {
+		return err
+	}
+
+	if user.UserType != usermodels.UserTypeRobot {
+		return n.sendEmail(ctx, user, token, expiresAt)
+	}
+
+	// resource access token is owned by a robot, notify the human who created it
+	if token.CreatedBy != 0 {
+		creator, err := n.userMgr.GetUserByID(ctx, token.CreatedBy)
+		if err != nil {
+			return err
+		}
+		if err := n.sendEmail(ctx, creator, token, expiresAt); err != nil {
+			return err
+		}
+	}
+
+	members, err := n.memberMgr.ListMembersByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	extra, err := json.Marshal(Extra{
+		TokenID:   token.ID,
+		TokenName: token.Name,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	extraStr := string(extra)
+	for _, member := range members {
+		switch member.ResourceType {
+		case membermodels.TypeApplication:
+			n.eventSvc.CreateEventIgnoreError(ctx, common.ResourceApplication, member.ResourceID,
+				eventmodels.ApplicationAccessTokenExpiring, &extraStr)
+		case membermodels.TypeApplicationCluster:
+			n.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, member.ResourceID,
+				eventmodels.ClusterAccessTokenExpiring, &extraStr)
+		}
+	}
+	return nil
+}
+
+func (n *Notifier) sendEmail(ctx context.Context, user *usermodels.User,
+	token *tokenmodels.Token, expiresAt time.Time) error {
+	if n.sender == nil || user.Email == "" {
+		return nil
+	}
+	subject := fmt.Sprintf("[Horizon] Access token %s expires at %s", token.Name, expiresAt.Format(timeFormat))
+	body := fmt.Sprintf("Hi %s,\n\n"+
+		"The access token %s (id: %d) will expire at %s.\n"+
+		"Please create a new token and replace it in time, "+
+		"otherwise pipelines and scripts using it will break.\n",
+		user.FullName, token.Name, token.ID, expiresAt.Format(timeFormat))
+	return n.sender.Send(ctx, []string{user.Email}, subject, body)
+}
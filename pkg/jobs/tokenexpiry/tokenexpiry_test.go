@@ -0,0 +1,117 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenexpiry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	tokenconfig "github.com/horizoncd/horizon/pkg/config/token"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/token/generator"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+type sentEmail struct {
+	to      []string
+	subject string
+}
+
+type fakeSender struct {
+	sent []sentEmail
+}
+
+func (s *fakeSender) Send(_ context.Context, to []string, subject, _ string) error {
+	s.sent = append(s.sent, sentEmail{to: to, subject: subject})
+	return nil
+}
+
+func TestNotifier(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	err = db.AutoMigrate(&tokenmodels.Token{}, &usermodels.User{}, &membermodels.Member{}, &eventmodels.Event{})
+	assert.Nil(t, err)
+
+	ctx := context.TODO()
+	mgr := managerparam.InitManager(db)
+
+	owner, err := mgr.UserMgr.Create(ctx, &usermodels.User{Name: "owner", Email: "owner@horizon.org"})
+	assert.Nil(t, err)
+	robot, err := mgr.UserMgr.Create(ctx, &usermodels.User{
+		Name: "robot", Email: "robot@noreply.com", UserType: usermodels.UserTypeRobot})
+	assert.Nil(t, err)
+	_, err = mgr.MemberMgr.Create(ctx, &membermodels.Member{
+		ResourceType: membermodels.TypeApplicationCluster,
+		ResourceID:   1,
+		Role:         "owner",
+		MemberType:   membermodels.MemberUser,
+		MemberNameID: robot.ID,
+	})
+	assert.Nil(t, err)
+
+	gen := generator.NewGeneralAccessTokenGenerator()
+	now := time.Now()
+	createToken := func(name string, userID uint, expiresIn time.Duration) *tokenmodels.Token {
+		token, err := mgr.TokenMgr.CreateToken(ctx, &tokenmodels.Token{
+			Name:      name,
+			Code:      gen.Generate(&generator.CodeGenerateInfo{Token: tokenmodels.Token{UserID: userID}}),
+			CreatedAt: now.Add(-time.Hour * 24),
+			CreatedBy: owner.ID,
+			ExpiresIn: expiresIn,
+			UserID:    userID,
+		})
+		assert.Nil(t, err)
+		return token
+	}
+	personal := createToken("personal", owner.ID, time.Hour*24*3)
+	resource := createToken("resource", robot.ID, time.Hour*24*2)
+	later := createToken("later", owner.ID, time.Hour*24*30)
+	expired := createToken("expired", owner.ID, time.Hour)
+
+	sender := &fakeSender{}
+	notifier := New(tokenconfig.ExpiryNotification{Enabled: true, DaysBefore: 7}, sender, mgr)
+	notifier.check(ctx, now)
+
+	assert.Equal(t, 2, len(sender.sent))
+	for _, sent := range sender.sent {
+		assert.Equal(t, []string{owner.Email}, sent.to)
+	}
+
+	events, err := mgr.EventMgr.ListEventsByRange(ctx, 0, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, eventmodels.ClusterAccessTokenExpiring, events[0].EventType)
+	assert.Equal(t, uint(1), events[0].ResourceID)
+
+	for _, token := range []*tokenmodels.Token{personal, resource, expired} {
+		tokenInDB, err := mgr.TokenMgr.LoadTokenByID(ctx, token.ID)
+		assert.Nil(t, err)
+		assert.NotNil(t, tokenInDB.ExpiryNotifiedAt)
+	}
+	tokenInDB, err := mgr.TokenMgr.LoadTokenByID(ctx, later.ID)
+	assert.Nil(t, err)
+	assert.Nil(t, tokenInDB.ExpiryNotifiedAt)
+
+	// notified tokens are not notified again
+	notifier.check(ctx, now)
+	assert.Equal(t, 2, len(sender.sent))
+}
@@ -47,6 +47,7 @@ type DAO interface {
 	ListResourceOfMemberInfoByRole(ctx context.Context,
 		resourceType models.ResourceType, info uint, role string) ([]uint, error)
 	ListMembersByUserID(ctx context.Context, userID uint) ([]models.Member, error)
+	ListMembersOfUser(ctx context.Context, userID uint, resourceTypes []models.ResourceType) ([]models.Member, error)
 }
 
 var (
@@ -181,7 +182,8 @@ func (d *dao) ListDirectMemberOnCondition(ctx context.Context, resourceType mode
 func (d *dao) ListResourceOfMemberInfo(ctx context.Context,
 	resourceType models.ResourceType, memberInfo uint) ([]uint, error) {
 	var resources []uint
-	result := d.db.WithContext(ctx).Raw(common.MemberListResource, resourceType, memberInfo).Scan(&resources)
+	result := d.db.WithContext(ctx).Raw(common.MemberListResource, resourceType,
+		memberInfo, memberInfo).Scan(&resources)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -191,11 +193,8 @@ func (d *dao) ListResourceOfMemberInfo(ctx context.Context,
 func (d *dao) ListResourceOfMemberInfoByRole(ctx context.Context,
 	resourceType models.ResourceType, info uint, role string) ([]uint, error) {
 	members := make([]uint, 0)
-	res := d.db.Model(model).WithContext(ctx).Select("resource_id").Where(&models.Member{
-		ResourceType: resourceType,
-		Role:         role,
-		MemberNameID: info,
-	}).Find(&members)
+	res := d.db.WithContext(ctx).Raw(common.MemberListResourceByRole, resourceType, role,
+		info, info).Scan(&members)
 	if res.Error != nil {
 		return nil, perror.Wrapf(herrors.NewErrGetFailed(herrors.MemberInfoInDB, res.Error.Error()),
 			"failed to get members:\n"+
@@ -207,6 +206,7 @@ func (d *dao) ListResourceOfMemberInfoByRole(ctx context.Context,
 func (d *dao) ListMembersByUserID(ctx context.Context, userID uint) ([]models.Member, error) {
 	var members []models.Member
 	result := d.db.Model(model).WithContext(ctx).
+		Where("member_type = ?", models.MemberUser).
 		Where("membername_id = ?", userID).
 		Where("deleted_ts = 0").
 		Scan(&members)
@@ -215,3 +215,13 @@ func (d *dao) ListMembersByUserID(ctx context.Context, userID uint) ([]models.Me
 	}
 	return members, nil
 }
+
+func (d *dao) ListMembersOfUser(ctx context.Context, userID uint,
+	resourceTypes []models.ResourceType) ([]models.Member, error) {
+	var members []models.Member
+	result := d.db.WithContext(ctx).Raw(common.MemberListOfUser, resourceTypes, userID, userID).Scan(&members)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return members, nil
+}
@@ -48,7 +48,7 @@ type Manager interface {
 	// DeleteMemberByResourceTypeID Delete a member by memberID
 	HardDeleteMemberByResourceTypeID(ctx context.Context, resourceType string, resourceID uint) error
 
-	// DeleteMemberByMemberNameID Delete member by memberNameID
+	// DeleteMemberByMemberNameID Delete the bindings of user memberNameID
 	DeleteMemberByMemberNameID(ctx context.Context, memberNameID uint) error
 
 	// ListDirectMember List the direct member of the resource
@@ -59,14 +59,21 @@ type Manager interface {
 	ListDirectMemberOnCondition(ctx context.Context, resourceType models.ResourceType,
 		resourceID uint) ([]models.Member, error)
 
-	// ListResourceOfMemberInfo list the resource id of the specified resourceType and memberInfo
+	// ListResourceOfMemberInfo list the resource id of the specified resourceType which user memberInfo
+	// is bound to directly or through teams
 	ListResourceOfMemberInfo(ctx context.Context,
 		resourceType models.ResourceType, memberInfo uint) ([]uint, error)
 
+	// ListResourceOfMemberInfoByRole is like ListResourceOfMemberInfo, but only bindings of role are counted
 	ListResourceOfMemberInfoByRole(ctx context.Context,
 		resourceType models.ResourceType, memberInfo uint, role string) ([]uint, error)
 
+	// ListMembersByUserID lists the direct bindings of user, bindings of teams are not included
 	ListMembersByUserID(ctx context.Context, userID uint) ([]models.Member, error)
+
+	// ListMembersOfUser lists the bindings on resources of the types, which are granted to user directly
+	// or through teams
+	ListMembersOfUser(ctx context.Context, userID uint, resourceTypes []models.ResourceType) ([]models.Member, error)
 }
 
 type manager struct {
@@ -133,3 +140,8 @@ func (m *manager) ListResourceOfMemberInfoByRole(ctx context.Context,
 func (m *manager) ListMembersByUserID(ctx context.Context, userID uint) ([]models.Member, error) {
 	return m.dao.ListMembersByUserID(ctx, userID)
 }
+
+func (m *manager) ListMembersOfUser(ctx context.Context, userID uint,
+	resourceTypes []models.ResourceType) ([]models.Member, error) {
+	return m.dao.ListMembersOfUser(ctx, userID, resourceTypes)
+}
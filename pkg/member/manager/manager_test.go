@@ -25,13 +25,14 @@ import (
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	"github.com/horizoncd/horizon/pkg/member/models"
 	"github.com/horizoncd/horizon/pkg/server/global"
+	teammodels "github.com/horizoncd/horizon/pkg/team/models"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	"github.com/stretchr/testify/assert"
 )
 
 var (
-	db, _ = orm.NewSqliteDB("")
+	db, _ = orm.NewTestDB()
 	ctx   context.Context
 	mgr   = New(db)
 )
@@ -178,10 +179,53 @@ func TestListResourceOfMemberInfo(t *testing.T) {
 	assert.Equal(t, 2, len(resourceIDs))
 	assert.Equal(t, uint(11), resourceIDs[0])
 	assert.Equal(t, uint(22), resourceIDs[1])
+
+	// bindings of team 1 are not granted to user 1, but to the members of team 1
+	teamMember := &models.Member{
+		ResourceType: models.TypeGroup,
+		ResourceID:   33,
+		Role:         "guest",
+		MemberType:   models.MemberGroup,
+		MemberNameID: 1,
+		GrantedBy:    grantedByAdmin,
+	}
+	_, err = mgr.Create(ctx, teamMember)
+	assert.Nil(t, err)
+	assert.Nil(t, db.Create(&teammodels.TeamMember{TeamID: 1, UserID: 2}).Error)
+
+	resourceIDs, err = mgr.ListResourceOfMemberInfo(ctx, models.TypeGroup, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{11, 22}, resourceIDs)
+	members, err := mgr.ListMembersByUserID(ctx, 1)
+	assert.Nil(t, err)
+	for _, m := range members {
+		assert.Equal(t, models.MemberUser, m.MemberType)
+	}
+
+	resourceIDs, err = mgr.ListResourceOfMemberInfo(ctx, models.TypeGroup, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{33}, resourceIDs)
+	resourceIDs, err = mgr.ListResourceOfMemberInfoByRole(ctx, models.TypeGroup, 2, "guest")
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{33}, resourceIDs)
+	resourceIDs, err = mgr.ListResourceOfMemberInfoByRole(ctx, models.TypeGroup, 2, "owner")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(resourceIDs))
+
+	directMembers, err := mgr.ListDirectMember(ctx, models.TypeGroup, 33)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(directMembers))
+	assert.Equal(t, models.MemberGroup, directMembers[0].MemberType)
+
+	// deleting bindings of user 1 keeps bindings of team 1
+	assert.Nil(t, mgr.DeleteMemberByMemberNameID(ctx, 1))
+	directMembers, err = mgr.ListDirectMember(ctx, models.TypeGroup, 33)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(directMembers))
 }
 
 func TestMain(m *testing.M) {
-	if err := db.AutoMigrate(&models.Member{}, &usermodels.User{}); err != nil {
+	if err := db.AutoMigrate(&models.Member{}, &usermodels.User{}, &teammodels.TeamMember{}); err != nil {
 		panic(err)
 	}
 
@@ -52,7 +52,7 @@ type MemberType uint8
 const (
 	// MemberUser represent the user binding.
 	MemberUser MemberType = iota
-	// MemberGroup represent the group binding.
+	// MemberGroup represent the team binding, MemberNameID is the id of team.
 	MemberGroup
 )
 
@@ -68,9 +68,9 @@ type Member struct {
 	// role binding info
 	// Role: owner/maintainer/...
 	Role string
-	// MemberType: user/group
+	// MemberType: user/team
 	MemberType MemberType `gorm:"column:member_type"`
-	// userID or groupID
+	// userID or teamID
 	MemberNameID uint `gorm:"column:membername_id"`
 
 	// TODO(tom): change go user
@@ -35,11 +35,13 @@ import (
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
 	roleservice "github.com/horizoncd/horizon/pkg/rbac/role"
+	teammanager "github.com/horizoncd/horizon/pkg/team/manager"
 	templatemanager "github.com/horizoncd/horizon/pkg/template/manager"
 	templatereleasemanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/visibility"
 	webhookmanager "github.com/horizoncd/horizon/pkg/webhook/manager"
 )
 
@@ -57,7 +59,8 @@ type Service interface {
 	RemoveMember(ctx context.Context, memberID uint) error
 	// ListMember list all the member of the resource
 	ListMember(ctx context.Context, resourceType string, resourceID uint) ([]models.Member, error)
-	// GetMemberOfResource return the current user's role of the resource (member from direct or parent)
+	// GetMemberOfResource return the current user's role of the resource (member from direct or parent),
+	// non-members play the default role on public resources and nothing on private ones
 	GetMemberOfResource(ctx context.Context, resourceType string, resourceID string) (*models.Member, error)
 	// RequirePermissionEqualOrHigher helps to check if your permission is higher than specified member
 	RequirePermissionEqualOrHigher(ctx context.Context, role, resourceType string, resourceID uint) error
@@ -75,11 +78,13 @@ type service struct {
 	oauthManager              oauthmanager.Manager
 	userManager               usermanager.Manager
 	webhookManager            webhookmanager.Manager
+	teamManager               teammanager.Manager
+	visibilityChecker         visibility.Checker
 }
 
 func NewService(roleService roleservice.Service, oauthManager oauthmanager.Manager,
 	manager *managerparam.Manager) Service {
-	return &service{
+	s := &service{
 		memberManager:             manager.MemberMgr,
 		groupManager:              manager.GroupMgr,
 		applicationManager:        manager.ApplicationMgr,
@@ -91,7 +96,10 @@ func NewService(roleService roleservice.Service, oauthManager oauthmanager.Manag
 		oauthManager:              oauthManager,
 		userManager:               manager.UserMgr,
 		webhookManager:            manager.WebhookMgr,
+		teamManager:               manager.TeamMgr,
 	}
+	s.visibilityChecker = visibility.NewChecker(manager)
+	return s
 }
 
 func (s *service) RequirePermissionEqualOrHigher(ctx context.Context, role,
@@ -105,8 +113,7 @@ func (s *service) RequirePermissionEqualOrHigher(ctx context.Context, role,
 		return nil
 	}
 	var userMemberInfo *models.Member
-	userMemberInfo, err = s.getMember(ctx, resourceType,
-		resourceID, models.MemberUser, currentUser.GetID())
+	userMemberInfo, err = s.getMemberOfUser(ctx, resourceType, resourceID, currentUser.GetID())
 	if err != nil {
 		return err
 	}
@@ -131,6 +138,12 @@ func (s *service) CreateMember(ctx context.Context, postMember PostMember) (*mod
 		return nil, err
 	}
 
+	if postMember.MemberType == models.MemberGroup {
+		if _, err := s.teamManager.GetByID(ctx, postMember.MemberInfo); err != nil {
+			return nil, err
+		}
+	}
+
 	// 1. check exist
 	memberItem, err := s.memberManager.Get(ctx, models.ResourceType(postMember.ResourceType), postMember.ResourceID,
 		postMember.MemberType, postMember.MemberInfo)
@@ -168,7 +181,7 @@ func (s *service) getOauthAppMember(ctx context.Context, clientID string) (*mode
 	if !app.IsGroupOwnerType() {
 		return nil, herror.ErrOAuthNotGroupOwnerType
 	}
-	return s.getMember(ctx, common.ResourceGroup, app.OwnerID, models.MemberUser, currentUser.GetID())
+	return s.getMemberOfUser(ctx, common.ResourceGroup, app.OwnerID, currentUser.GetID())
 }
 
 func (s *service) getCheckrunMember(ctx context.Context, checkrunID uint) (*models.Member, error) {
@@ -198,8 +211,7 @@ func (s *service) getPipelinerunMember(ctx context.Context, pipelinerunID uint)
 		log.Warningf(ctx, msg)
 		return nil, herror.NewErrNotFound(herror.MemberInfoInDB, msg)
 	}
-	return s.getMember(ctx, common.ResourceCluster,
-		pipeline.ClusterID, models.MemberUser, currentUser.GetID())
+	return s.getMemberOfUser(ctx, common.ResourceCluster, pipeline.ClusterID, currentUser.GetID())
 }
 
 func (s *service) listPipelinerunMember(ctx context.Context, pipelinerunID uint) ([]models.Member, error) {
@@ -263,15 +275,22 @@ func (s *service) GetMemberOfResource(ctx context.Context,
 		memberInfo, err = s.getOauthAppMember(ctx, resourceIDStr)
 	default:
 		resourceID, _ := strconv.Atoi(resourceIDStr)
-		memberInfo, err = s.getMember(ctx, resourceType, uint(resourceID), models.MemberUser, currentUser.GetID())
+		memberInfo, err = s.getMemberOfUser(ctx, resourceType, uint(resourceID), currentUser.GetID())
 	}
 	if err != nil {
 		return nil, err
 	}
 	if memberInfo == nil {
+		resourceID, _ := strconv.Atoi(resourceIDStr)
+		private, err := s.visibilityChecker.IsPrivate(ctx, resourceType, uint(resourceID))
+		if err != nil {
+			return nil, err
+		}
+		if private {
+			return nil, nil
+		}
 		defaultRole := s.roleService.GetDefaultRole(ctx)
 		if nil != defaultRole {
-			resourceID, _ := strconv.Atoi(resourceIDStr)
 			memberInfo = &models.Member{
 				MemberType:   models.MemberUser,
 				Role:         defaultRole.Name,
@@ -305,13 +324,9 @@ func (s *service) RemoveMember(ctx context.Context, memberID uint) error {
 	}
 
 	// 3. check if common user
-	user, err := s.userManager.GetUserByID(ctx, memberItem.MemberNameID)
-	if err != nil {
+	if err := s.checkCommonUser(ctx, memberItem); err != nil {
 		return err
 	}
-	if user.UserType != usermodels.UserTypeCommon {
-		return perror.Wrapf(herror.ErrParamInvalid, "member of user type %d does not support updated", user.UserType)
-	}
 
 	return s.memberManager.DeleteMember(ctx, memberID)
 }
@@ -338,13 +353,9 @@ func (s *service) UpdateMember(ctx context.Context, memberID uint, role string)
 	}
 
 	// 3. check if common user
-	user, err := s.userManager.GetUserByID(ctx, memberItem.MemberNameID)
-	if err != nil {
+	if err := s.checkCommonUser(ctx, memberItem); err != nil {
 		return nil, err
 	}
-	if user.UserType != usermodels.UserTypeCommon {
-		return nil, perror.Wrapf(herror.ErrParamInvalid, "member of user type %d does not support updated", user.UserType)
-	}
 
 	// 4. update the role
 	return s.memberManager.UpdateByID(ctx, memberItem.ID, role)
@@ -396,20 +407,73 @@ func DeduplicateMember(members []models.Member) []models.Member {
 	return retMembers
 }
 
-// getMember return the direct member or member from the parent
-func (s *service) getMember(ctx context.Context, resourceType string, resourceID uint,
-	memberType models.MemberType, memberInfo uint) (*models.Member, error) {
+// getMemberOfUser returns the member which grants the highest role to the user,
+// among the member of the user and the members of teams which the user belongs to
+func (s *service) getMemberOfUser(ctx context.Context, resourceType string, resourceID uint,
+	userID uint) (*models.Member, error) {
 	members, err := s.ListMember(ctx, resourceType, resourceID)
 	if err != nil {
 		return nil, err
 	}
-	for _, item := range members {
-		if item.MemberType == memberType &&
-			item.MemberNameID == memberInfo {
-			return &item, nil
+
+	var (
+		result  *models.Member
+		teamIDs map[uint]struct{}
+	)
+	for i := range members {
+		item := &members[i]
+		switch item.MemberType {
+		case models.MemberUser:
+			if item.MemberNameID != userID {
+				continue
+			}
+		case models.MemberGroup:
+			// query teams of user lazily, most resources have no team member
+			if teamIDs == nil {
+				ids, err := s.teamManager.ListTeamIDsByUserID(ctx, userID)
+				if err != nil {
+					return nil, err
+				}
+				teamIDs = make(map[uint]struct{}, len(ids))
+				for _, id := range ids {
+					teamIDs[id] = struct{}{}
+				}
+			}
+			if _, ok := teamIDs[item.MemberNameID]; !ok {
+				continue
+			}
+		default:
+			continue
+		}
+
+		if result == nil {
+			result = item
+			continue
+		}
+		compResult, err := s.roleService.RoleCompare(ctx, item.Role, result.Role)
+		if err != nil {
+			log.Warningf(ctx, "failed to compare role %s with %s: %v", item.Role, result.Role, err)
+			continue
+		}
+		if compResult == roleservice.RoleBigger {
+			result = item
 		}
 	}
-	return nil, nil
+	return result, nil
+}
+
+func (s *service) checkCommonUser(ctx context.Context, member *models.Member) error {
+	if member.MemberType != models.MemberUser {
+		return nil
+	}
+	user, err := s.userManager.GetUserByID(ctx, member.MemberNameID)
+	if err != nil {
+		return err
+	}
+	if user.UserType != usermodels.UserTypeCommon {
+		return perror.Wrapf(herror.ErrParamInvalid, "member of user type %d does not support updated", user.UserType)
+	}
+	return nil
 }
 
 func (s *service) listGroupMembers(ctx context.Context, resourceID uint) ([]models.Member, error) {
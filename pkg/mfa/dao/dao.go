@@ -0,0 +1,124 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/common"
+	"github.com/horizoncd/horizon/pkg/mfa/models"
+)
+
+type DAO interface {
+	GetByUserID(ctx context.Context, userID uint) (*models.UserMFA, error)
+	Create(ctx context.Context, mfa *models.UserMFA) (*models.UserMFA, error)
+	Update(ctx context.Context, mfa *models.UserMFA) (*models.UserMFA, error)
+	DeleteByUserID(ctx context.Context, userID uint) error
+	// UseStep records step as the last used step and resets failed attempts,
+	// it returns false if step is not after the last used one
+	UseStep(ctx context.Context, id uint, step int64) (bool, error)
+	// RecordFailure increases failed attempts, and locks until lockedUntil once maxAttempts is reached
+	RecordFailure(ctx context.Context, id uint, maxAttempts int, lockedUntil time.Time) error
+	// ResetFailures clears failed attempts
+	ResetFailures(ctx context.Context, id uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) GetByUserID(ctx context.Context, userID uint) (*models.UserMFA, error) {
+	var mfa models.UserMFA
+	if err := d.db.WithContext(ctx).Where("user_id = ?", userID).First(&mfa).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.UserMFAInDB, err.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.UserMFAInDB, err.Error())
+	}
+	return &mfa, nil
+}
+
+func (d *dao) Create(ctx context.Context, mfa *models.UserMFA) (*models.UserMFA, error) {
+	if err := d.db.WithContext(ctx).Create(mfa).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.UserMFAInDB, err.Error())
+	}
+	return mfa, nil
+}
+
+func (d *dao) Update(ctx context.Context, mfa *models.UserMFA) (*models.UserMFA, error) {
+	// use map to update zero values, e.g. enabled = false and recovery_codes = ''
+	if err := d.db.WithContext(ctx).Model(&models.UserMFA{}).Where("id = ?", mfa.ID).
+		Updates(map[string]interface{}{
+			"secret":         mfa.Secret,
+			"enabled":        mfa.Enabled,
+			"recovery_codes": mfa.RecoveryCodes,
+			"updated_by":     mfa.UpdatedBy,
+		}).Error; err != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.UserMFAInDB, err.Error())
+	}
+	return d.GetByUserID(ctx, mfa.UserID)
+}
+
+func (d *dao) DeleteByUserID(ctx context.Context, userID uint) error {
+	if err := d.db.WithContext(ctx).Where("user_id = ?", userID).
+		Delete(&models.UserMFA{}).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.UserMFAInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) UseStep(ctx context.Context, id uint, step int64) (bool, error) {
+	// compare and set in a single statement, so that concurrent requests can't use the same code
+	result := d.db.WithContext(ctx).Model(&models.UserMFA{}).
+		Where("id = ? AND last_used_step < ?", id, step).
+		Updates(map[string]interface{}{
+			"last_used_step":  step,
+			"failed_attempts": 0,
+			"locked_until":    nil,
+		})
+	if result.Error != nil {
+		return false, herrors.NewErrUpdateFailed(herrors.UserMFAInDB, result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (d *dao) RecordFailure(ctx context.Context, id uint, maxAttempts int, lockedUntil time.Time) error {
+	// locked_until is assigned before failed_attempts, since mysql evaluates assignments from left to right
+	if err := d.db.WithContext(ctx).Exec(common.UserMFARecordFailure,
+		maxAttempts, lockedUntil, maxAttempts, id).Error; err != nil {
+		return herrors.NewErrUpdateFailed(herrors.UserMFAInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) ResetFailures(ctx context.Context, id uint) error {
+	if err := d.db.WithContext(ctx).Model(&models.UserMFA{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"failed_attempts": 0,
+			"locked_until":    nil,
+		}).Error; err != nil {
+		return herrors.NewErrUpdateFailed(herrors.UserMFAInDB, err.Error())
+	}
+	return nil
+}
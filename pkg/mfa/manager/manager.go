@@ -0,0 +1,72 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/mfa/dao"
+	"github.com/horizoncd/horizon/pkg/mfa/models"
+)
+
+type Manager interface {
+	GetByUserID(ctx context.Context, userID uint) (*models.UserMFA, error)
+	Create(ctx context.Context, mfa *models.UserMFA) (*models.UserMFA, error)
+	Update(ctx context.Context, mfa *models.UserMFA) (*models.UserMFA, error)
+	DeleteByUserID(ctx context.Context, userID uint) error
+	// UseStep records step as the last used step, it returns false if the step has been used
+	UseStep(ctx context.Context, id uint, step int64) (bool, error)
+	RecordFailure(ctx context.Context, id uint, maxAttempts int, lockedUntil time.Time) error
+	ResetFailures(ctx context.Context, id uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) GetByUserID(ctx context.Context, userID uint) (*models.UserMFA, error) {
+	return m.dao.GetByUserID(ctx, userID)
+}
+
+func (m *manager) Create(ctx context.Context, mfa *models.UserMFA) (*models.UserMFA, error) {
+	return m.dao.Create(ctx, mfa)
+}
+
+func (m *manager) Update(ctx context.Context, mfa *models.UserMFA) (*models.UserMFA, error) {
+	return m.dao.Update(ctx, mfa)
+}
+
+func (m *manager) DeleteByUserID(ctx context.Context, userID uint) error {
+	return m.dao.DeleteByUserID(ctx, userID)
+}
+
+func (m *manager) UseStep(ctx context.Context, id uint, step int64) (bool, error) {
+	return m.dao.UseStep(ctx, id, step)
+}
+
+func (m *manager) RecordFailure(ctx context.Context, id uint, maxAttempts int, lockedUntil time.Time) error {
+	return m.dao.RecordFailure(ctx, id, maxAttempts, lockedUntil)
+}
+
+func (m *manager) ResetFailures(ctx context.Context, id uint) error {
+	return m.dao.ResetFailures(ctx, id)
+}
@@ -0,0 +1,52 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+// UserMFA stores the totp secret and recovery codes of a user
+type UserMFA struct {
+	global.Model
+	UserID uint `gorm:"column:user_id"`
+	Secret string
+	// Enabled is false until the user proves the possession of secret by a valid code
+	Enabled bool
+	// RecoveryCodes are hashed codes joined by comma, a used code will be removed
+	RecoveryCodes string
+	// LastUsedStep is the time step of the last accepted totp code, codes of it and former steps are rejected
+	LastUsedStep int64 `gorm:"column:last_used_step"`
+	// FailedAttempts is the number of consecutive failed verifications
+	FailedAttempts int `gorm:"column:failed_attempts"`
+	// LockedUntil rejects all verifications before it after too many failed attempts
+	LockedUntil *time.Time `gorm:"column:locked_until"`
+	CreatedBy   uint
+	UpdatedBy   uint
+}
+
+func (UserMFA) TableName() string {
+	return "tb_user_mfa"
+}
+
+func (m *UserMFA) RecoveryCodeHashes() []string {
+	if m.RecoveryCodes == "" {
+		return nil
+	}
+	return strings.Split(m.RecoveryCodes, ",")
+}
@@ -0,0 +1,119 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/mfa"
+	mfamanager "github.com/horizoncd/horizon/pkg/mfa/manager"
+	"github.com/horizoncd/horizon/pkg/mfa/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+)
+
+const (
+	// maxFailedAttempts is the number of consecutive failed verifications before locking,
+	// which makes brute forcing totp codes impractical together with lockDuration
+	maxFailedAttempts = 5
+	lockDuration      = 5 * time.Minute
+)
+
+type Service interface {
+	// IsEnabled returns whether the user has enabled multi-factor authentication
+	IsEnabled(ctx context.Context, userID uint) (bool, error)
+	// Verify checks a totp code or a recovery code of the user, each totp code and recovery code can only be used once
+	Verify(ctx context.Context, userID uint, code string) error
+}
+
+type service struct {
+	mfaMgr mfamanager.Manager
+}
+
+func NewService(manager *managerparam.Manager) Service {
+	return &service{
+		mfaMgr: manager.UserMFAMgr,
+	}
+}
+
+func (s *service) IsEnabled(ctx context.Context, userID uint) (bool, error) {
+	userMFA, err := s.mfaMgr.GetByUserID(ctx, userID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return false, nil
+		}
+		return false, err
+	}
+	return userMFA.Enabled, nil
+}
+
+func (s *service) Verify(ctx context.Context, userID uint, code string) error {
+	userMFA, err := s.mfaMgr.GetByUserID(ctx, userID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return perror.Wrapf(herrors.ErrMFANotEnrolled, "user %d has not enrolled mfa", userID)
+		}
+		return err
+	}
+	if !userMFA.Enabled {
+		return perror.Wrapf(herrors.ErrMFANotEnrolled, "user %d has not enabled mfa", userID)
+	}
+
+	now := time.Now()
+	if userMFA.LockedUntil != nil && now.Before(*userMFA.LockedUntil) {
+		return perror.Wrapf(herrors.ErrMFACodeInvalid,
+			"too many failed attempts, try again after %s", userMFA.LockedUntil.Format(time.RFC3339))
+	}
+
+	if step, ok := mfa.MatchStep(userMFA.Secret, code, now); ok {
+		used, err := s.mfaMgr.UseStep(ctx, userMFA.ID, step)
+		if err != nil {
+			return err
+		}
+		if used {
+			return nil
+		}
+		return s.fail(ctx, userMFA, "totp code has been used")
+	}
+
+	// fall back to recovery codes
+	hashed := mfa.HashRecoveryCode(code)
+	hashes := userMFA.RecoveryCodeHashes()
+	for i, h := range hashes {
+		if h != hashed {
+			continue
+		}
+		remains := append(append([]string{}, hashes[:i]...), hashes[i+1:]...)
+		userMFA.RecoveryCodes = strings.Join(remains, ",")
+		userMFA.UpdatedBy = userID
+		if _, err := s.mfaMgr.Update(ctx, userMFA); err != nil {
+			return err
+		}
+		return s.mfaMgr.ResetFailures(ctx, userMFA.ID)
+	}
+	return s.fail(ctx, userMFA, "code is neither a valid totp code nor a recovery code")
+}
+
+// fail records a failed attempt, user is locked for a while after too many consecutive failures
+func (s *service) fail(ctx context.Context, userMFA *models.UserMFA, reason string) error {
+	if err := s.mfaMgr.RecordFailure(ctx, userMFA.ID, maxFailedAttempts,
+		time.Now().Add(lockDuration)); err != nil {
+		return err
+	}
+	return perror.Wrap(herrors.ErrMFACodeInvalid, reason)
+}
@@ -0,0 +1,88 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mfa
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// secret of the test vectors in RFC 6238, ascii "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateCode(t *testing.T) {
+	cases := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, c := range cases {
+		code, err := GenerateCode(rfcSecret, time.Unix(c.unix, 0))
+		assert.Nil(t, err)
+		assert.Equal(t, c.code, code)
+	}
+}
+
+func TestValidateCode(t *testing.T) {
+	secret, err := GenerateSecret()
+	assert.Nil(t, err)
+
+	now := time.Now()
+	code, err := GenerateCode(secret, now)
+	assert.Nil(t, err)
+	assert.True(t, ValidateCode(secret, code, now))
+
+	// clock drift within one period is accepted
+	assert.True(t, ValidateCode(secret, code, now.Add(Period)))
+	assert.True(t, ValidateCode(secret, code, now.Add(-Period)))
+	assert.False(t, ValidateCode(secret, code, now.Add(3*Period)))
+
+	assert.False(t, ValidateCode(secret, "", now))
+	assert.False(t, ValidateCode(secret, "12345", now))
+	assert.False(t, ValidateCode("not-base32!", code, now))
+
+	step, ok := MatchStep(rfcSecret, "287082", time.Unix(59+30, 0))
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), step)
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes()
+	assert.Nil(t, err)
+	assert.Equal(t, recoveryCodeCount, len(codes))
+
+	seen := map[string]struct{}{}
+	for _, code := range codes {
+		assert.Equal(t, recoveryCodeLength+1, len(code))
+		seen[HashRecoveryCode(code)] = struct{}{}
+	}
+	assert.Equal(t, recoveryCodeCount, len(seen))
+	assert.Equal(t, HashRecoveryCode(codes[0]), HashRecoveryCode(" "+strings.ToUpper(codes[0])+" "))
+}
+
+func TestKeyURI(t *testing.T) {
+	uri := KeyURI("Horizon", "tom@horizoncd.io", rfcSecret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Horizon:tom@horizoncd.io?"))
+	assert.Contains(t, uri, "secret="+rfcSecret)
+	assert.Contains(t, uri, "issuer=Horizon")
+}
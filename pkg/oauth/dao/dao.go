@@ -19,7 +19,10 @@ import (
 
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/pkg/common"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	"github.com/horizoncd/horizon/pkg/oauth/models"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	"golang.org/x/net/context"
 	"gorm.io/gorm"
 )
@@ -34,6 +37,10 @@ type DAO interface {
 	DeleteSecret(ctx context.Context, clientID string, clientSecretID uint) error
 	DeleteSecretByClientID(ctx context.Context, clientID string) error
 	ListSecret(ctx context.Context, clientID string) ([]models.OauthClientSecret, error)
+	// CreateTokenWithRobot creates the token of robot in a transaction,
+	// the robot and its membership are created along with the token if the ID of robot is 0
+	CreateTokenWithRobot(ctx context.Context, token *tokenmodels.Token, robot *usermodels.User,
+		member *membermodels.Member) (*tokenmodels.Token, error)
 }
 
 func NewDAO(db *gorm.DB) DAO {
@@ -125,3 +132,29 @@ func (d *dao) ListSecret(ctx context.Context, clientID string) ([]models.OauthCl
 	}
 	return secrets, nil
 }
+
+func (d *dao) CreateTokenWithRobot(ctx context.Context, token *tokenmodels.Token, robot *usermodels.User,
+	member *membermodels.Member) (*tokenmodels.Token, error) {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if robot.ID == 0 {
+			if err := tx.Create(robot).Error; err != nil {
+				return herrors.NewErrInsertFailed(herrors.UserInDB, err.Error())
+			}
+			if member != nil {
+				member.MemberNameID = robot.ID
+				if err := tx.Create(member).Error; err != nil {
+					return herrors.NewErrInsertFailed(herrors.MemberInfoInDB, err.Error())
+				}
+			}
+		}
+		token.UserID = robot.ID
+		if err := tx.Create(token).Error; err != nil {
+			return herrors.NewErrInsertFailed(herrors.TokenInDB, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
@@ -0,0 +1,174 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/token/generator"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"golang.org/x/net/context"
+)
+
+const (
+	// DevicePollInterval is the minimum interval devices poll for tokens
+	DevicePollInterval = 5 * time.Second
+
+	// user code is made of consonants to avoid forming words, ref: rfc8628 6.1
+	userCodeCharset = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength  = 8
+)
+
+func genUserCode() (string, error) {
+	var b strings.Builder
+	for i := 0; i < userCodeLength; i++ {
+		if i == userCodeLength/2 {
+			b.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(userCodeCharset))))
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(userCodeCharset[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// normalizeUserCode makes user code typed by users case-insensitive and tolerant of separators
+func normalizeUserCode(userCode string) string {
+	code := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(userCode))
+	if len(code) != userCodeLength {
+		return code
+	}
+	return code[:userCodeLength/2] + "-" + code[userCodeLength/2:]
+}
+
+func (m *OauthManager) GenDeviceCode(ctx context.Context,
+	req *DeviceAuthorizationRequest) (*tokenmodels.Token, error) {
+	if _, err := m.oauthAppDAO.GetApp(ctx, req.ClientID); err != nil {
+		return nil, err
+	}
+	userCode, err := genUserCode()
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrOAuthInternal, err.Error())
+	}
+	token := &tokenmodels.Token{
+		ClientID:  req.ClientID,
+		State:     userCode,
+		CreatedAt: time.Now(),
+		ExpiresIn: m.deviceCodeExpireTime,
+		Scope:     req.Scope,
+	}
+	token.Code = m.deviceCodeGenerator.Generate(&generator.CodeGenerateInfo{
+		Token:   *token,
+		Request: req.Request,
+	})
+	return m.tokenStore.Create(ctx, token)
+}
+
+func (m *OauthManager) GetDeviceCodeByUserCode(ctx context.Context, userCode string) (*tokenmodels.Token, error) {
+	token, err := m.tokenStore.GetDeviceCodeByUserCode(ctx, normalizeUserCode(userCode))
+	if err != nil {
+		return nil, err
+	}
+	if token.CreatedAt.Add(m.deviceCodeExpireTime).Before(time.Now()) {
+		return nil, perror.Wrap(herrors.ErrOAuthCodeExpired, "")
+	}
+	return token, nil
+}
+
+// AuthorizeDevice approves or denies the device authorization request identified by user code,
+// the device code is deleted once denied
+func (m *OauthManager) AuthorizeDevice(ctx context.Context, userCode string, userID uint, approved bool) error {
+	token, err := m.GetDeviceCodeByUserCode(ctx, userCode)
+	if err != nil {
+		return err
+	}
+	if token.UserID != 0 {
+		return perror.Wrap(herrors.ErrOAuthReqNotValid, "device has already been authorized")
+	}
+	if !approved {
+		return m.tokenStore.DeleteByID(ctx, token.ID)
+	}
+	return m.tokenStore.UpdateUserByID(ctx, token.ID, userID)
+}
+
+func (m *OauthManager) GenDeviceTokens(ctx context.Context, req *OauthTokensRequest) (*OauthTokensResponse, error) {
+	deviceToken, err := m.tokenStore.GetByCode(ctx, req.DeviceCode)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil, perror.Wrap(err, "device code not exist")
+		}
+		return nil, err
+	}
+	if deviceToken.ClientID != req.ClientID {
+		return nil, perror.Wrapf(herrors.ErrOAuthReqNotValid,
+			"req client id = %s, device code client id = %s", req.ClientID, deviceToken.ClientID)
+	}
+	// devices are public clients in most cases, which do not have a secret
+	if err := m.checkClient(ctx, req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if deviceToken.CreatedAt.Add(m.deviceCodeExpireTime).Before(now) {
+		if delErr := m.tokenStore.DeleteByID(ctx, deviceToken.ID); delErr != nil {
+			log.Warningf(ctx, "delete expired device code error, err = %v", delErr)
+		}
+		return nil, perror.Wrap(herrors.ErrOAuthCodeExpired, "")
+	}
+	// last usage of device code is the last time the device polled
+	lastPolledAt := deviceToken.LastUsedAt
+	if err := m.tokenStore.UpdateLastUsed(ctx, deviceToken.ID, now, ""); err != nil {
+		return nil, err
+	}
+	if lastPolledAt != nil && now.Sub(*lastPolledAt) < DevicePollInterval {
+		return nil, perror.Wrap(herrors.ErrOAuthSlowDown, "")
+	}
+	if deviceToken.UserID == 0 {
+		return nil, perror.Wrap(herrors.ErrOAuthAuthorizationPending, "")
+	}
+
+	accessToken := m.NewAccessToken(deviceToken, req)
+	accessTokenInDB, err := m.tokenStore.Create(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken := m.NewRefreshToken(accessToken, req)
+	refreshToken.RefID = accessTokenInDB.ID
+	refreshTokenInDB, err := m.tokenStore.Create(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.tokenStore.DeleteByID(ctx, deviceToken.ID); err != nil {
+		log.Warningf(ctx, "Delete device code error, id = %d, error = %v", deviceToken.ID, err)
+	}
+	return &OauthTokensResponse{
+		AccessToken:  accessTokenInDB,
+		RefreshToken: refreshTokenInDB,
+	}, nil
+}
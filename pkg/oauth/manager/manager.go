@@ -22,11 +22,13 @@ import (
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	oauthdao "github.com/horizoncd/horizon/pkg/oauth/dao"
 	"github.com/horizoncd/horizon/pkg/oauth/models"
 	"github.com/horizoncd/horizon/pkg/token/generator"
 	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
 	tokenstore "github.com/horizoncd/horizon/pkg/token/store"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	"github.com/horizoncd/horizon/pkg/util/log"
 	"golang.org/x/net/context"
 	"k8s.io/apimachinery/pkg/util/rand"
@@ -40,6 +42,16 @@ type AuthorizeGenerateRequest struct {
 	Scope        string
 	UserIdentify uint
 	Request      *http.Request
+
+	// PKCE challenge, ref: rfc7636
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+type DeviceAuthorizationRequest struct {
+	ClientID string
+	Scope    string
+	Request  *http.Request
 }
 
 type OauthTokensRequest struct {
@@ -48,6 +60,15 @@ type OauthTokensRequest struct {
 	Code         string // authorization code
 	RefreshToken string // refresh token
 	RedirectURL  string
+	CodeVerifier string // PKCE code verifier
+	DeviceCode   string // device code
+
+	// Scope and Robot are the scope and identity of the client for client_credentials grant,
+	// the robot is created along with the token if its ID is 0
+	Scope string
+	Robot *usermodels.User
+	// RobotMember is the membership granted to the robot when it's created, which is optional
+	RobotMember *membermodels.Member
 
 	Request *http.Request
 
@@ -68,6 +89,7 @@ type CreateOAuthAppReq struct {
 	OwnerType   models.OwnerType
 	OwnerID     uint
 	APPType     models.AppType
+	Public      bool
 }
 
 type UpdateOauthAppReq struct {
@@ -91,6 +113,14 @@ type Manager interface {
 	GenAuthorizeCode(ctx context.Context, req *AuthorizeGenerateRequest) (*tokenmodels.Token, error)
 	GenOauthTokens(ctx context.Context, req *OauthTokensRequest) (*OauthTokensResponse, error)
 	RefreshOauthTokens(ctx context.Context, req *OauthTokensRequest) (*OauthTokensResponse, error)
+	// GenClientCredentialsTokens issues access token of client_credentials grant, ref: rfc6749 4.4
+	GenClientCredentialsTokens(ctx context.Context, req *OauthTokensRequest) (*OauthTokensResponse, error)
+
+	// device authorization grant, ref: rfc8628
+	GenDeviceCode(ctx context.Context, req *DeviceAuthorizationRequest) (*tokenmodels.Token, error)
+	GetDeviceCodeByUserCode(ctx context.Context, userCode string) (*tokenmodels.Token, error)
+	AuthorizeDevice(ctx context.Context, userCode string, userID uint, approved bool) error
+	GenDeviceTokens(ctx context.Context, req *OauthTokensRequest) (*OauthTokensResponse, error)
 }
 
 var _ Manager = &OauthManager{}
@@ -99,14 +129,17 @@ func NewManager(oauthAppDAO oauthdao.DAO, tokenStore tokenstore.Store,
 	gen generator.CodeGenerator,
 	authorizeCodeExpireTime,
 	accessTokenExpireTime,
-	refreshTokenExpireTime time.Duration) *OauthManager {
+	refreshTokenExpireTime,
+	deviceCodeExpireTime time.Duration) *OauthManager {
 	return &OauthManager{
 		oauthAppDAO:                oauthAppDAO,
 		tokenStore:                 tokenStore,
 		authorizationCodeGenerator: gen,
+		deviceCodeGenerator:        generator.NewDeviceCodeGenerator(),
 		authorizeCodeExpireTime:    authorizeCodeExpireTime,
 		accessTokenExpireTime:      accessTokenExpireTime,
 		refreshTokenExpireTime:     refreshTokenExpireTime,
+		deviceCodeExpireTime:       deviceCodeExpireTime,
 		clientIDGenerate:           GenClientID,
 	}
 }
@@ -115,9 +148,11 @@ type OauthManager struct {
 	oauthAppDAO                oauthdao.DAO
 	tokenStore                 tokenstore.Store
 	authorizationCodeGenerator generator.CodeGenerator
+	deviceCodeGenerator        generator.CodeGenerator
 	authorizeCodeExpireTime    time.Duration
 	accessTokenExpireTime      time.Duration
 	refreshTokenExpireTime     time.Duration
+	deviceCodeExpireTime       time.Duration
 	clientIDGenerate           ClientIDGenerate
 }
 
@@ -155,6 +190,7 @@ func (m *OauthManager) CreateOauthApp(ctx context.Context, info *CreateOAuthAppR
 		OwnerType:   info.OwnerType,
 		OwnerID:     info.OwnerID,
 		AppType:     info.APPType,
+		Public:      info.Public,
 		CreatedBy:   user.GetID(),
 		UpdatedBy:   user.GetID(),
 	}
@@ -264,6 +300,9 @@ func (m *OauthManager) NewAuthorizationToken(req *AuthorizeGenerateRequest) *tok
 		ExpiresIn:   m.authorizeCodeExpireTime,
 		Scope:       req.Scope,
 		UserID:      req.UserIdentify,
+
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
 	}
 	token.Code = m.authorizationCodeGenerator.Generate(&generator.CodeGenerateInfo{
 		Token:   *token,
@@ -314,6 +353,12 @@ func (m *OauthManager) GenAuthorizeCode(ctx context.Context,
 		log.Warningf(ctx, "redirect URL not match")
 		return nil, perror.Wrapf(herrors.ErrOAuthReqNotValid, "redirect URL not match")
 	}
+	if req.CodeChallenge != "" && req.CodeChallengeMethod == "" {
+		req.CodeChallengeMethod = CodeChallengeMethodPlain
+	}
+	if err := validateCodeChallenge(req.CodeChallenge, req.CodeChallengeMethod); err != nil {
+		return nil, err
+	}
 
 	authorizationToken := m.NewAuthorizationToken(req)
 	_, err = m.tokenStore.Create(ctx, authorizationToken)
@@ -321,6 +366,10 @@ func (m *OauthManager) GenAuthorizeCode(ctx context.Context,
 }
 
 func (m *OauthManager) checkByAuthorizationCode(req *OauthTokensRequest, codeToken *tokenmodels.Token) error {
+	if req.ClientID != codeToken.ClientID {
+		return perror.Wrapf(herrors.ErrOAuthReqNotValid,
+			"req client id = %s, code client id = %s", req.ClientID, codeToken.ClientID)
+	}
 	if req.RedirectURL != codeToken.RedirectURI {
 		return perror.Wrapf(herrors.ErrOAuthReqNotValid,
 			"req redirect url = %s, code redirect url = %s", req.RedirectURL, codeToken.RedirectURI)
@@ -328,13 +377,20 @@ func (m *OauthManager) checkByAuthorizationCode(req *OauthTokensRequest, codeTok
 	if codeToken.CreatedAt.Add(m.authorizeCodeExpireTime).Before(time.Now()) {
 		return perror.Wrap(herrors.ErrOAuthCodeExpired, "")
 	}
+	if codeToken.CodeChallenge != "" {
+		if !verifyCodeChallenge(codeToken.CodeChallenge, codeToken.CodeChallengeMethod, req.CodeVerifier) {
+			return perror.Wrap(herrors.ErrOAuthReqNotValid, "code verifier not match")
+		}
+	} else if req.ClientSecret == "" {
+		// public clients are allowed to omit the client secret only if they use PKCE
+		return perror.Wrapf(herrors.ErrOAuthSecretNotValid, "clientId = %s, secret is empty", req.ClientID)
+	}
 	return nil
 }
 
 func (m *OauthManager) GenOauthTokens(ctx context.Context, req *OauthTokensRequest) (*OauthTokensResponse, error) {
-	// check client secret
-	err := m.checkClientSecret(ctx, req)
-	if err != nil {
+	// check client secret, public clients do not have one
+	if err := m.checkClient(ctx, req); err != nil {
 		return nil, err
 	}
 
@@ -419,6 +475,48 @@ func (m *OauthManager) RefreshOauthTokens(ctx context.Context,
 	}, nil
 }
 
+func (m *OauthManager) GenClientCredentialsTokens(ctx context.Context,
+	req *OauthTokensRequest) (*OauthTokensResponse, error) {
+	// client credentials grant is only for confidential clients
+	if err := m.checkClientSecret(ctx, req); err != nil {
+		return nil, err
+	}
+
+	token := &tokenmodels.Token{
+		ClientID:  req.ClientID,
+		CreatedAt: time.Now(),
+		ExpiresIn: m.accessTokenExpireTime,
+		Scope:     req.Scope,
+		UserID:    req.Robot.ID,
+	}
+	token.Code = req.AccessTokenGenerator.Generate(&generator.CodeGenerateInfo{
+		Token:   *token,
+		Request: req.Request,
+	})
+	// refresh token is not issued, clients can request a new one with credentials, ref: rfc6749 4.4.3.
+	// The robot is created only when the token is issued, so that invalid requests leave nothing behind.
+	accessToken, err := m.oauthAppDAO.CreateTokenWithRobot(ctx, token, req.Robot, req.RobotMember)
+	if err != nil {
+		return nil, err
+	}
+	return &OauthTokensResponse{AccessToken: accessToken}, nil
+}
+
+// checkClient checks the secret of the client, only public clients are allowed to omit it
+func (m *OauthManager) checkClient(ctx context.Context, req *OauthTokensRequest) error {
+	if req.ClientSecret != "" {
+		return m.checkClientSecret(ctx, req)
+	}
+	oauthApp, err := m.oauthAppDAO.GetApp(ctx, req.ClientID)
+	if err != nil {
+		return err
+	}
+	if !oauthApp.Public {
+		return perror.Wrapf(herrors.ErrOAuthSecretNotValid, "clientId = %s, secret is empty", req.ClientID)
+	}
+	return nil
+}
+
 func (m *OauthManager) checkClientSecret(ctx context.Context, req *OauthTokensRequest) error {
 	secrets, err := m.oauthAppDAO.ListSecret(ctx, req.ClientID)
 	if err != nil {
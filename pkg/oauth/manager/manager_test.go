@@ -17,6 +17,7 @@ package manager
 import (
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -29,12 +30,15 @@ import (
 	"github.com/horizoncd/horizon/lib/orm"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	oauthdao "github.com/horizoncd/horizon/pkg/oauth/dao"
 	"github.com/horizoncd/horizon/pkg/oauth/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
 	"github.com/horizoncd/horizon/pkg/token/generator"
 	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
 	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
 	tokenstore "github.com/horizoncd/horizon/pkg/token/store"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	callbacks "github.com/horizoncd/horizon/pkg/util/ormcallbacks"
 )
 
@@ -56,6 +60,7 @@ var (
 	authorizeCodeExpireIn = time.Second * 3
 	accessTokenExpireIn   = time.Second * 3
 	refreshTokenExpireIn  = time.Second * 3
+	deviceCodeExpireIn    = time.Second * 3
 )
 
 func checkOAuthApp(req *CreateOAuthAppReq, app *models.OauthApp) bool {
@@ -514,8 +519,9 @@ func TestUpdateToken(t *testing.T) {
 }
 
 func TestMain(m *testing.M) {
-	db, _ = orm.NewSqliteDB("")
-	if err := db.AutoMigrate(&tokenmodels.Token{}, &models.OauthApp{}, &models.OauthClientSecret{}); err != nil {
+	db, _ = orm.NewTestDB()
+	if err := db.AutoMigrate(&tokenmodels.Token{}, &models.OauthApp{}, &models.OauthClientSecret{},
+		&usermodels.User{}, &membermodels.Member{}); err != nil {
 		panic(err)
 	}
 	db = db.WithContext(context.WithValue(context.Background(), common.UserContextKey(), aUser))
@@ -524,7 +530,264 @@ func TestMain(m *testing.M) {
 	tokenStore = tokenstore.NewStore(db)
 	oauthAppDAO = oauthdao.NewDAO(db)
 	oauthManager = NewManager(oauthAppDAO, tokenStore, generator.NewOauthAccessGenerator(),
-		authorizeCodeExpireIn, accessTokenExpireIn, refreshTokenExpireIn)
+		authorizeCodeExpireIn, accessTokenExpireIn, refreshTokenExpireIn, deviceCodeExpireIn)
 	tokenManager = tokenmanager.New(db)
 	os.Exit(m.Run())
 }
+
+func createTestOauthApp(t *testing.T, public bool) (*models.OauthApp, *models.OauthClientSecret) {
+	oauthApp, err := oauthManager.CreateOauthApp(ctx, &CreateOAuthAppReq{
+		Name:        "OauthTest",
+		RedirectURI: "https://example.com/oauth/redirect",
+		HomeURL:     "https://example.com",
+		Desc:        "This is an example  oauth app",
+		OwnerType:   models.GroupOwnerType,
+		OwnerID:     1,
+		APPType:     models.HorizonOAuthAPP,
+		Public:      public,
+	})
+	assert.Nil(t, err)
+	secret, err := oauthManager.CreateSecret(ctx, oauthApp.ClientID)
+	assert.Nil(t, err)
+	return oauthApp, secret
+}
+
+func TestPKCE(t *testing.T) {
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	assert.True(t, verifyCodeChallenge(challenge, CodeChallengeMethodS256, verifier))
+	assert.False(t, verifyCodeChallenge(challenge, CodeChallengeMethodS256, verifier+"x"))
+	assert.True(t, verifyCodeChallenge(verifier, CodeChallengeMethodPlain, verifier))
+	assert.False(t, verifyCodeChallenge("short", CodeChallengeMethodPlain, "short"))
+
+	oauthApp, secret := createTestOauthApp(t, true)
+	confidentialApp, _ := createTestOauthApp(t, false)
+	defer func() {
+		assert.Nil(t, oauthManager.DeleteOAuthApp(ctx, oauthApp.ClientID))
+		assert.Nil(t, oauthManager.DeleteOAuthApp(ctx, confidentialApp.ClientID))
+	}()
+
+	// unsupported method
+	_, err := oauthManager.GenAuthorizeCode(ctx, &AuthorizeGenerateRequest{
+		ClientID:            oauthApp.ClientID,
+		RedirectURL:         oauthApp.RedirectURL,
+		UserIdentify:        43,
+		CodeChallenge:       challenge,
+		CodeChallengeMethod: "S512",
+	})
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+
+	genCodeOf := func(app *models.OauthApp) *tokenmodels.Token {
+		codeToken, err := oauthManager.GenAuthorizeCode(ctx, &AuthorizeGenerateRequest{
+			ClientID:            app.ClientID,
+			RedirectURL:         app.RedirectURL,
+			UserIdentify:        43,
+			CodeChallenge:       challenge,
+			CodeChallengeMethod: CodeChallengeMethodS256,
+		})
+		assert.Nil(t, err)
+		return codeToken
+	}
+	genCode := func() *tokenmodels.Token {
+		return genCodeOf(oauthApp)
+	}
+	newRequest := func(codeToken *tokenmodels.Token) *OauthTokensRequest {
+		return &OauthTokensRequest{
+			ClientID:              oauthApp.ClientID,
+			Code:                  codeToken.Code,
+			RedirectURL:           oauthApp.RedirectURL,
+			CodeVerifier:          verifier,
+			AccessTokenGenerator:  generator.NewHorizonAppUserToServerAccessGenerator(),
+			RefreshTokenGenerator: generator.NewRefreshTokenGenerator(),
+		}
+	}
+
+	// wrong verifier
+	req := newRequest(genCode())
+	req.CodeVerifier = verifier[1:] + "x"
+	_, err = oauthManager.GenOauthTokens(ctx, req)
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+
+	// public client without secret
+	codeToken := genCode()
+	tokens, err := oauthManager.GenOauthTokens(ctx, newRequest(codeToken))
+	assert.Nil(t, err)
+	assert.Equal(t, uint(43), tokens.AccessToken.UserID)
+	_, err = tokenManager.LoadTokenByCode(ctx, codeToken.Code)
+	assert.NotNil(t, err)
+
+	// confidential client with wrong secret
+	req = newRequest(genCode())
+	req.ClientSecret = "err-secret"
+	_, err = oauthManager.GenOauthTokens(ctx, req)
+	assert.Equal(t, herrors.ErrOAuthSecretNotValid, perror.Cause(err))
+
+	// confidential client with both secret and verifier
+	req = newRequest(genCode())
+	req.ClientSecret = secret.ClientSecret
+	_, err = oauthManager.GenOauthTokens(ctx, req)
+	assert.Nil(t, err)
+
+	// confidential client can not omit the secret even if it uses PKCE
+	req = newRequest(genCodeOf(confidentialApp))
+	req.ClientID = confidentialApp.ClientID
+	_, err = oauthManager.GenOauthTokens(ctx, req)
+	assert.Equal(t, herrors.ErrOAuthSecretNotValid, perror.Cause(err))
+
+	// code issued to another client can not be exchanged
+	_, err = oauthManager.GenOauthTokens(ctx, newRequest(genCodeOf(confidentialApp)))
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+
+	// code without challenge can not be exchanged without secret
+	codeToken, err = oauthManager.GenAuthorizeCode(ctx, &AuthorizeGenerateRequest{
+		ClientID:     oauthApp.ClientID,
+		RedirectURL:  oauthApp.RedirectURL,
+		UserIdentify: 43,
+	})
+	assert.Nil(t, err)
+	_, err = oauthManager.GenOauthTokens(ctx, newRequest(codeToken))
+	assert.Equal(t, herrors.ErrOAuthSecretNotValid, perror.Cause(err))
+}
+
+func TestClientCredentials(t *testing.T) {
+	oauthApp, secret := createTestOauthApp(t, false)
+	defer func() {
+		assert.Nil(t, oauthManager.DeleteOAuthApp(ctx, oauthApp.ClientID))
+	}()
+
+	robotEmail := "oauthapps_client_credentials_robot@noreply.com"
+	req := &OauthTokensRequest{
+		ClientID:     oauthApp.ClientID,
+		ClientSecret: "err-secret",
+		Scope:        "applications:read-only",
+		Robot: &usermodels.User{
+			Name:     "robot",
+			Email:    robotEmail,
+			UserType: usermodels.UserTypeRobot,
+		},
+		RobotMember: &membermodels.Member{
+			ResourceType: membermodels.TypeGroup,
+			ResourceID:   1,
+			Role:         "guest",
+			MemberType:   membermodels.MemberUser,
+		},
+		AccessTokenGenerator: generator.NewHorizonAppUserToServerAccessGenerator(),
+	}
+	_, err := oauthManager.GenClientCredentialsTokens(ctx, req)
+	assert.Equal(t, herrors.ErrOAuthSecretNotValid, perror.Cause(err))
+
+	req.ClientSecret = ""
+	_, err = oauthManager.GenClientCredentialsTokens(ctx, req)
+	assert.Equal(t, herrors.ErrOAuthSecretNotValid, perror.Cause(err))
+
+	// the robot is not created by invalid requests
+	var robots int64
+	assert.Nil(t, db.Model(&usermodels.User{}).Where("email = ?", robotEmail).Count(&robots).Error)
+	assert.Equal(t, int64(0), robots)
+
+	req.ClientSecret = secret.ClientSecret
+	tokens, err := oauthManager.GenClientCredentialsTokens(ctx, req)
+	assert.Nil(t, err)
+	assert.Nil(t, tokens.RefreshToken)
+	assert.NotEqual(t, uint(0), req.Robot.ID)
+	tokenInDB, err := tokenManager.LoadTokenByCode(ctx, tokens.AccessToken.Code)
+	assert.Nil(t, err)
+	assert.Equal(t, req.Robot.ID, tokenInDB.UserID)
+	assert.Equal(t, req.Scope, tokenInDB.Scope)
+	assert.Equal(t, accessTokenExpireIn, tokenInDB.ExpiresIn)
+	var member membermodels.Member
+	assert.Nil(t, db.Where("membername_id = ?", req.Robot.ID).First(&member).Error)
+	assert.Equal(t, uint(1), member.ResourceID)
+
+	// the existing robot is reused
+	robotID := req.Robot.ID
+	tokens, err = oauthManager.GenClientCredentialsTokens(ctx, &OauthTokensRequest{
+		ClientID:             oauthApp.ClientID,
+		ClientSecret:         secret.ClientSecret,
+		Robot:                &usermodels.User{Model: global.Model{ID: robotID}},
+		AccessTokenGenerator: generator.NewHorizonAppUserToServerAccessGenerator(),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, robotID, tokens.AccessToken.UserID)
+	assert.Nil(t, db.Model(&usermodels.User{}).Where("email = ?", robotEmail).Count(&robots).Error)
+	assert.Equal(t, int64(1), robots)
+}
+
+func TestDeviceAuthorization(t *testing.T) {
+	assert.Equal(t, "BCDF-GHJK", normalizeUserCode("bcdf ghjk"))
+	assert.Equal(t, "BCDF-GHJK", normalizeUserCode("BCDFGHJK"))
+	userCode, err := genUserCode()
+	assert.Nil(t, err)
+	assert.Equal(t, userCode, normalizeUserCode(userCode))
+
+	oauthApp, _ := createTestOauthApp(t, true)
+	defer func() {
+		assert.Nil(t, oauthManager.DeleteOAuthApp(ctx, oauthApp.ClientID))
+	}()
+
+	_, err = oauthManager.GenDeviceCode(ctx, &DeviceAuthorizationRequest{ClientID: "not-exist"})
+	assert.NotNil(t, err)
+
+	deviceToken, err := oauthManager.GenDeviceCode(ctx, &DeviceAuthorizationRequest{
+		ClientID: oauthApp.ClientID,
+		Scope:    "clusters:read-write",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, deviceCodeExpireIn, deviceToken.ExpiresIn)
+
+	req := &OauthTokensRequest{
+		ClientID:              oauthApp.ClientID,
+		DeviceCode:            deviceToken.Code,
+		AccessTokenGenerator:  generator.NewHorizonAppUserToServerAccessGenerator(),
+		RefreshTokenGenerator: generator.NewRefreshTokenGenerator(),
+	}
+
+	// pending and polling too fast
+	_, err = oauthManager.GenDeviceTokens(ctx, req)
+	assert.Equal(t, herrors.ErrOAuthAuthorizationPending, perror.Cause(err))
+	_, err = oauthManager.GenDeviceTokens(ctx, req)
+	assert.Equal(t, herrors.ErrOAuthSlowDown, perror.Cause(err))
+
+	// wrong client
+	wrongClientReq := *req
+	wrongClientReq.ClientID = "other"
+	_, err = oauthManager.GenDeviceTokens(ctx, &wrongClientReq)
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+
+	// authorize by user code typed in lower case
+	codeInDB, err := oauthManager.GetDeviceCodeByUserCode(ctx, strings.ToLower(deviceToken.State))
+	assert.Nil(t, err)
+	assert.Equal(t, deviceToken.ID, codeInDB.ID)
+	assert.Nil(t, oauthManager.AuthorizeDevice(ctx, deviceToken.State, 43, true))
+	err = oauthManager.AuthorizeDevice(ctx, deviceToken.State, 43, true)
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+
+	// clear last poll to avoid slowing down
+	assert.Nil(t, tokenStore.UpdateLastUsed(ctx, deviceToken.ID, time.Now().Add(-DevicePollInterval), ""))
+	tokens, err := oauthManager.GenDeviceTokens(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(43), tokens.AccessToken.UserID)
+	assert.Equal(t, "clusters:read-write", tokens.AccessToken.Scope)
+	assert.Equal(t, tokens.AccessToken.ID, tokens.RefreshToken.RefID)
+	_, err = tokenManager.LoadTokenByCode(ctx, deviceToken.Code)
+	assert.NotNil(t, err)
+
+	// denied
+	deviceToken, err = oauthManager.GenDeviceCode(ctx, &DeviceAuthorizationRequest{ClientID: oauthApp.ClientID})
+	assert.Nil(t, err)
+	assert.Nil(t, oauthManager.AuthorizeDevice(ctx, deviceToken.State, 43, false))
+	req.DeviceCode = deviceToken.Code
+	_, err = oauthManager.GenDeviceTokens(ctx, req)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	// expired
+	deviceToken, err = oauthManager.GenDeviceCode(ctx, &DeviceAuthorizationRequest{ClientID: oauthApp.ClientID})
+	assert.Nil(t, err)
+	time.Sleep(deviceCodeExpireIn)
+	err = oauthManager.AuthorizeDevice(ctx, deviceToken.State, 43, true)
+	assert.Equal(t, herrors.ErrOAuthCodeExpired, perror.Cause(err))
+	req.DeviceCode = deviceToken.Code
+	_, err = oauthManager.GenDeviceTokens(ctx, req)
+	assert.Equal(t, herrors.ErrOAuthCodeExpired, perror.Cause(err))
+}
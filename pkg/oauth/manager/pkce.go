@@ -0,0 +1,58 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// PKCE code challenge methods, ref: rfc7636
+const (
+	CodeChallengeMethodPlain = "plain"
+	CodeChallengeMethodS256  = "S256"
+
+	codeVerifierMinLength = 43
+	codeVerifierMaxLength = 128
+)
+
+func validateCodeChallenge(challenge, method string) error {
+	if challenge == "" {
+		if method != "" {
+			return perror.Wrap(herrors.ErrOAuthReqNotValid, "code challenge is empty")
+		}
+		return nil
+	}
+	if method != CodeChallengeMethodPlain && method != CodeChallengeMethodS256 {
+		return perror.Wrapf(herrors.ErrOAuthReqNotValid, "code challenge method %s not supported", method)
+	}
+	return nil
+}
+
+func verifyCodeChallenge(challenge, method, verifier string) bool {
+	if len(verifier) < codeVerifierMinLength || len(verifier) > codeVerifierMaxLength {
+		return false
+	}
+	expected := verifier
+	if method == CodeChallengeMethodS256 {
+		sum := sha256.Sum256([]byte(verifier))
+		expected = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
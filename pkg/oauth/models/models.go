@@ -48,6 +48,8 @@ type OauthApp struct {
 	OwnerType   OwnerType `gorm:"column:owner_type"`
 	OwnerID     uint      `gorm:"column:owner_id"`
 	AppType     AppType   `gorm:"column:app_type"`
+	// Public clients cannot keep a secret, they are allowed to omit the secret if they use PKCE
+	Public bool `gorm:"column:public"`
 
 	CreatedAt time.Time `gorm:"column:created_at"`
 	CreatedBy uint      `gorm:"column:created_by"`
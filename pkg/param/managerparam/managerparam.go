@@ -25,20 +25,31 @@ import (
 	applicationregionmanager "github.com/horizoncd/horizon/pkg/applicationregion/manager"
 	badgemanager "github.com/horizoncd/horizon/pkg/badge/manager"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	clusterdriftmanager "github.com/horizoncd/horizon/pkg/clusterdrift/manager"
+	clustersecretmanager "github.com/horizoncd/horizon/pkg/clustersecret/manager"
+	customrolemanager "github.com/horizoncd/horizon/pkg/customrole/manager"
+	doramanager "github.com/horizoncd/horizon/pkg/dora/manager"
 	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	environmentregionmanager "github.com/horizoncd/horizon/pkg/environmentregion/manager"
 	eventManager "github.com/horizoncd/horizon/pkg/event/manager"
+	gittriggermanager "github.com/horizoncd/horizon/pkg/gittrigger/manager"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	idpmanager "github.com/horizoncd/horizon/pkg/idp/manager"
+	mfamanager "github.com/horizoncd/horizon/pkg/mfa/manager"
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
 	pipelinemanager "github.com/horizoncd/horizon/pkg/pr/pipeline/manager"
+	previewmanager "github.com/horizoncd/horizon/pkg/preview/manager"
+	quotamanager "github.com/horizoncd/horizon/pkg/quota/manager"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	registrymanager "github.com/horizoncd/horizon/pkg/registry/manager"
 	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
+	teammanager "github.com/horizoncd/horizon/pkg/team/manager"
 	templatemanager "github.com/horizoncd/horizon/pkg/template/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	templateschematagmanager "github.com/horizoncd/horizon/pkg/templateschematag/manager"
 	trtmanager "github.com/horizoncd/horizon/pkg/templateschematag/manager"
+	terminalaccessmanager "github.com/horizoncd/horizon/pkg/terminalaccess/manager"
+	terminalsessionmanager "github.com/horizoncd/horizon/pkg/terminalsession/manager"
 	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	linkmanager "github.com/horizoncd/horizon/pkg/userlink/manager"
@@ -72,6 +83,17 @@ type Manager struct {
 	EventMgr             eventManager.Manager
 	TokenMgr             tokenmanager.Manager
 	BadgeMgr             badgemanager.Manager
+	UserMFAMgr           mfamanager.Manager
+	TeamMgr              teammanager.Manager
+	CustomRoleMgr        customrolemanager.Manager
+	GitTriggerMgr        gittriggermanager.Manager
+	PreviewMgr           previewmanager.Manager
+	DoraMgr              doramanager.Manager
+	TerminalSessionMgr   terminalsessionmanager.Manager
+	TerminalAccessMgr    terminalaccessmanager.Manager
+	ClusterSecretMgr     clustersecretmanager.Manager
+	ClusterDriftMgr      clusterdriftmanager.Manager
+	QuotaMgr             quotamanager.Manager
 }
 
 func InitManager(db *gorm.DB) *Manager {
@@ -102,5 +124,16 @@ func InitManager(db *gorm.DB) *Manager {
 		EventMgr:             eventManager.New(db),
 		TokenMgr:             tokenmanager.New(db),
 		BadgeMgr:             badgemanager.New(db),
+		UserMFAMgr:           mfamanager.New(db),
+		TeamMgr:              teammanager.New(db),
+		CustomRoleMgr:        customrolemanager.New(db),
+		GitTriggerMgr:        gittriggermanager.New(db),
+		PreviewMgr:           previewmanager.New(db),
+		DoraMgr:              doramanager.New(db),
+		TerminalSessionMgr:   terminalsessionmanager.New(db),
+		TerminalAccessMgr:    terminalaccessmanager.New(db),
+		ClusterSecretMgr:     clustersecretmanager.New(db),
+		ClusterDriftMgr:      clusterdriftmanager.New(db),
+		QuotaMgr:             quotamanager.New(db),
 	}
 }
@@ -28,16 +28,19 @@ import (
 	groupsvc "github.com/horizoncd/horizon/pkg/group/service"
 	"github.com/horizoncd/horizon/pkg/hook/hook"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
+	mfaservice "github.com/horizoncd/horizon/pkg/mfa/service"
 	oauthmanager "github.com/horizoncd/horizon/pkg/oauth/manager"
 	"github.com/horizoncd/horizon/pkg/oauth/scope"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	prservice "github.com/horizoncd/horizon/pkg/pr/service"
+	terminalsessionservice "github.com/horizoncd/horizon/pkg/terminalsession/service"
 	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
 
 	"github.com/horizoncd/horizon/core/controller/build"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
 	"github.com/horizoncd/horizon/pkg/templaterelease/output"
 	templateschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
 	userservice "github.com/horizoncd/horizon/pkg/user/service"
 )
 
@@ -56,9 +59,14 @@ type Param struct {
 	UserSvc        userservice.Service
 	TokenSvc       tokenservice.Service
 	RoleService    role.Service
+	// CustomRoleSvc is the same role service as RoleService, which also manages custom roles
+	CustomRoleSvc  role.CustomService
 	PRService      prservice.Service
 	ScopeService   scope.Service
 	GrafanaService grafana.Service
+	MFASvc         mfaservice.Service
+	// TerminalSessionSvc records terminal sessions
+	TerminalSessionSvc terminalsessionservice.Service
 
 	// others
 	Hook                 hook.Hook
@@ -69,6 +77,7 @@ type Param struct {
 	OutputGetter         output.Getter
 	TektonFty            factory.Factory
 	ClusterGitRepo       clustergitrepo.ClusterGitRepo
+	TemplateRepo         templaterepo.TemplateRepo
 	GitGetter            code.GitGetter
 	BuildSchema          *build.Schema
 }
@@ -63,6 +63,8 @@ type Pipelinerun struct {
 	GitRefType string `json:"gitRefType"`
 	// GitCommit the git commit this pipelinerun to build with, can be empty when action is not builddeploy
 	GitCommit string `json:"gitCommit"`
+	// Pusher the git user whose push triggered this pipelinerun, empty when it's not triggered by git push
+	Pusher string `json:"pusher"`
 	// ImageURL image url of this pipelinerun to build or deploy image
 	ImageURL string `json:"imageURL"`
 	// the two commit used to compare the config difference of this pipelinerun
@@ -110,6 +112,8 @@ type PipelineBasic struct {
 	GitTag string `json:"gitTag,omitempty"`
 	// GitCommit the git commit this pipelinerun to build with, can be empty when action is not builddeploy
 	GitCommit string `json:"gitCommit"`
+	// Pusher the git user whose push triggered this pipelinerun, empty when it's not triggered by git push
+	Pusher string `json:"pusher,omitempty"`
 	// ImageURL image url of this pipelinerun to build image
 	ImageURL string `json:"imageURL"`
 
@@ -33,7 +33,7 @@ import (
 
 var (
 	// use tmp sqlite
-	db, _     = orm.NewSqliteDB("")
+	db, _     = orm.NewTestDB()
 	ctx       = context.TODO()
 	startTime *metav1.Time
 )
@@ -62,6 +62,7 @@ func (s *service) OfPipelineBasic(ctx context.Context,
 		Status:           pr.Status,
 		GitURL:           pr.GitURL,
 		GitCommit:        pr.GitCommit,
+		Pusher:           pr.Pusher,
 		ImageURL:         pr.ImageURL,
 		LastConfigCommit: pr.LastConfigCommit,
 		ConfigCommit:     pr.ConfigCommit,
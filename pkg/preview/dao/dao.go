@@ -0,0 +1,143 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/preview/models"
+)
+
+type DAO interface {
+	CreateSetting(ctx context.Context, setting *models.PreviewSetting) (*models.PreviewSetting, error)
+	GetSettingByClusterID(ctx context.Context, clusterID uint) (*models.PreviewSetting, error)
+	ListEnabledSettingsByGitURLs(ctx context.Context, gitURLs []string) ([]*models.PreviewSetting, error)
+	UpdateSetting(ctx context.Context, setting *models.PreviewSetting) (*models.PreviewSetting, error)
+	DeleteSetting(ctx context.Context, clusterID uint) error
+
+	CreateCluster(ctx context.Context, cluster *models.PreviewCluster) (*models.PreviewCluster, error)
+	GetClusterByMergeRequest(ctx context.Context, baseClusterID uint, mergeRequestID int) (*models.PreviewCluster, error)
+	ListClustersByBaseClusterID(ctx context.Context, baseClusterID uint) ([]*models.PreviewCluster, error)
+	DeleteCluster(ctx context.Context, id uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) CreateSetting(ctx context.Context, setting *models.PreviewSetting) (*models.PreviewSetting, error) {
+	if err := d.db.WithContext(ctx).Create(setting).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.PreviewSettingInDB, err.Error())
+	}
+	return setting, nil
+}
+
+func (d *dao) GetSettingByClusterID(ctx context.Context, clusterID uint) (*models.PreviewSetting, error) {
+	var setting models.PreviewSetting
+	if err := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).First(&setting).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.PreviewSettingInDB,
+				fmt.Sprintf("preview setting of cluster %d was not found", clusterID))
+		}
+		return nil, herrors.NewErrGetFailed(herrors.PreviewSettingInDB, err.Error())
+	}
+	return &setting, nil
+}
+
+func (d *dao) ListEnabledSettingsByGitURLs(ctx context.Context,
+	gitURLs []string) ([]*models.PreviewSetting, error) {
+	var settings []*models.PreviewSetting
+	if len(gitURLs) == 0 {
+		return settings, nil
+	}
+	clusters := d.db.Model(&clustermodels.Cluster{}).Select("id").Where("git_url in ?", gitURLs)
+	if err := d.db.WithContext(ctx).Where("enabled = ?", true).
+		Where("cluster_id in (?)", clusters).
+		Order("id asc").Find(&settings).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.PreviewSettingInDB, err.Error())
+	}
+	return settings, nil
+}
+
+func (d *dao) UpdateSetting(ctx context.Context, setting *models.PreviewSetting) (*models.PreviewSetting, error) {
+	result := d.db.WithContext(ctx).Model(&models.PreviewSetting{}).Where("id = ?", setting.ID).
+		Updates(map[string]interface{}{
+			"target_branch_pattern": setting.TargetBranchPattern,
+			"expire_time":           setting.ExpireTime,
+			"enabled":               setting.Enabled,
+			"secret":                setting.Secret,
+		})
+	if result.Error != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.PreviewSettingInDB, result.Error.Error())
+	}
+	return d.GetSettingByClusterID(ctx, setting.ClusterID)
+}
+
+func (d *dao) DeleteSetting(ctx context.Context, clusterID uint) error {
+	if err := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).
+		Delete(&models.PreviewSetting{}).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.PreviewSettingInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) CreateCluster(ctx context.Context, cluster *models.PreviewCluster) (*models.PreviewCluster, error) {
+	if err := d.db.WithContext(ctx).Create(cluster).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.PreviewClusterInDB, err.Error())
+	}
+	return cluster, nil
+}
+
+func (d *dao) GetClusterByMergeRequest(ctx context.Context,
+	baseClusterID uint, mergeRequestID int) (*models.PreviewCluster, error) {
+	var cluster models.PreviewCluster
+	if err := d.db.WithContext(ctx).Where("base_cluster_id = ?", baseClusterID).
+		Where("merge_request_id = ?", mergeRequestID).First(&cluster).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.PreviewClusterInDB,
+				fmt.Sprintf("preview cluster of cluster %d for merge request %d was not found",
+					baseClusterID, mergeRequestID))
+		}
+		return nil, herrors.NewErrGetFailed(herrors.PreviewClusterInDB, err.Error())
+	}
+	return &cluster, nil
+}
+
+func (d *dao) ListClustersByBaseClusterID(ctx context.Context,
+	baseClusterID uint) ([]*models.PreviewCluster, error) {
+	var clusters []*models.PreviewCluster
+	if err := d.db.WithContext(ctx).Where("base_cluster_id = ?", baseClusterID).
+		Order("id asc").Find(&clusters).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.PreviewClusterInDB, err.Error())
+	}
+	return clusters, nil
+}
+
+func (d *dao) DeleteCluster(ctx context.Context, id uint) error {
+	if err := d.db.WithContext(ctx).Where("id = ?", id).Delete(&models.PreviewCluster{}).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.PreviewClusterInDB, err.Error())
+	}
+	return nil
+}
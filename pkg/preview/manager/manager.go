@@ -0,0 +1,92 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/preview/dao"
+	"github.com/horizoncd/horizon/pkg/preview/models"
+)
+
+type Manager interface {
+	CreateSetting(ctx context.Context, setting *models.PreviewSetting) (*models.PreviewSetting, error)
+	GetSettingByClusterID(ctx context.Context, clusterID uint) (*models.PreviewSetting, error)
+	// ListEnabledSettingsByGitURLs lists the enabled settings of clusters built from the repository,
+	// urls with or without the .git suffix are both matched
+	ListEnabledSettingsByGitURLs(ctx context.Context, gitURLs []string) ([]*models.PreviewSetting, error)
+	UpdateSetting(ctx context.Context, setting *models.PreviewSetting) (*models.PreviewSetting, error)
+	DeleteSetting(ctx context.Context, clusterID uint) error
+
+	CreateCluster(ctx context.Context, cluster *models.PreviewCluster) (*models.PreviewCluster, error)
+	GetClusterByMergeRequest(ctx context.Context, baseClusterID uint, mergeRequestID int) (*models.PreviewCluster, error)
+	ListClustersByBaseClusterID(ctx context.Context, baseClusterID uint) ([]*models.PreviewCluster, error)
+	DeleteCluster(ctx context.Context, id uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) CreateSetting(ctx context.Context, setting *models.PreviewSetting) (*models.PreviewSetting, error) {
+	return m.dao.CreateSetting(ctx, setting)
+}
+
+func (m *manager) GetSettingByClusterID(ctx context.Context, clusterID uint) (*models.PreviewSetting, error) {
+	return m.dao.GetSettingByClusterID(ctx, clusterID)
+}
+
+func (m *manager) ListEnabledSettingsByGitURLs(ctx context.Context,
+	gitURLs []string) ([]*models.PreviewSetting, error) {
+	urls := make([]string, 0, len(gitURLs)*2)
+	for _, url := range gitURLs {
+		url = strings.TrimSuffix(url, ".git")
+		urls = append(urls, url, url+".git")
+	}
+	return m.dao.ListEnabledSettingsByGitURLs(ctx, urls)
+}
+
+func (m *manager) UpdateSetting(ctx context.Context, setting *models.PreviewSetting) (*models.PreviewSetting, error) {
+	return m.dao.UpdateSetting(ctx, setting)
+}
+
+func (m *manager) DeleteSetting(ctx context.Context, clusterID uint) error {
+	return m.dao.DeleteSetting(ctx, clusterID)
+}
+
+func (m *manager) CreateCluster(ctx context.Context, cluster *models.PreviewCluster) (*models.PreviewCluster, error) {
+	return m.dao.CreateCluster(ctx, cluster)
+}
+
+func (m *manager) GetClusterByMergeRequest(ctx context.Context,
+	baseClusterID uint, mergeRequestID int) (*models.PreviewCluster, error) {
+	return m.dao.GetClusterByMergeRequest(ctx, baseClusterID, mergeRequestID)
+}
+
+func (m *manager) ListClustersByBaseClusterID(ctx context.Context,
+	baseClusterID uint) ([]*models.PreviewCluster, error) {
+	return m.dao.ListClustersByBaseClusterID(ctx, baseClusterID)
+}
+
+func (m *manager) DeleteCluster(ctx context.Context, id uint) error {
+	return m.dao.DeleteCluster(ctx, id)
+}
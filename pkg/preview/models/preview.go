@@ -0,0 +1,77 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/horizoncd/horizon/lib/encrypt"
+	"github.com/horizoncd/horizon/pkg/git"
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+// PreviewSetting enables preview environments of a base cluster, a preview cluster is created
+// from the base cluster's config for each opened merge request of the cluster's repository
+type PreviewSetting struct {
+	global.Model
+
+	// ClusterID is the id of base cluster
+	ClusterID uint
+	// TargetBranchPattern is a glob pattern of the target branches of merge requests, empty means all
+	TargetBranchPattern string
+	// ExpireTime is the lifetime of preview clusters such as 72h, empty means never expire
+	ExpireTime string
+	Enabled    bool
+	CreatedBy  uint
+	UpdatedBy  uint
+	// Secret verifies the webhooks sent by the repository for the setting
+	Secret encrypt.String
+}
+
+// Match checks whether the merge request event concerns the setting
+func (s *PreviewSetting) Match(event *git.MergeRequestEvent) bool {
+	if !s.Enabled || event == nil {
+		return false
+	}
+	if s.TargetBranchPattern == "" {
+		return true
+	}
+	matched, err := path.Match(s.TargetBranchPattern, event.TargetBranch)
+	return err == nil && matched
+}
+
+// PreviewCluster is the cluster created for a merge request
+type PreviewCluster struct {
+	global.Model
+
+	BaseClusterID  uint
+	ClusterID      uint
+	MergeRequestID int
+	SourceBranch   string
+	CreatedBy      uint
+}
+
+// ClusterName generates the name of preview cluster, like <base>-mr<id>,
+// the base name is truncated to keep the name within the max length of cluster name
+func ClusterName(baseName string, mergeRequestID int) string {
+	const maxLength = 53
+	suffix := fmt.Sprintf("-mr%d", mergeRequestID)
+	if len(baseName)+len(suffix) > maxLength {
+		baseName = strings.TrimRight(baseName[:maxLength-len(suffix)], "-")
+	}
+	return baseName + suffix
+}
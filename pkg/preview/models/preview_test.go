@@ -0,0 +1,49 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/pkg/git"
+)
+
+func TestPreviewSettingMatch(t *testing.T) {
+	event := &git.MergeRequestEvent{ID: 1, SourceBranch: "feature", TargetBranch: "release/v1"}
+
+	setting := &PreviewSetting{Enabled: true}
+	assert.True(t, setting.Match(event))
+	setting.TargetBranchPattern = "release/*"
+	assert.True(t, setting.Match(event))
+	setting.TargetBranchPattern = "master"
+	assert.False(t, setting.Match(event))
+	setting.TargetBranchPattern = "["
+	assert.False(t, setting.Match(event))
+
+	setting = &PreviewSetting{Enabled: false}
+	assert.False(t, setting.Match(event))
+	assert.False(t, (&PreviewSetting{Enabled: true}).Match(nil))
+}
+
+func TestClusterName(t *testing.T) {
+	assert.Equal(t, "app-dev-mr12", ClusterName("app-dev", 12))
+
+	name := ClusterName(strings.Repeat("a", 46)+"-"+strings.Repeat("b", 7), 123)
+	assert.Equal(t, strings.Repeat("a", 46)+"-mr123", name)
+	assert.LessOrEqual(t, len(name), 53)
+}
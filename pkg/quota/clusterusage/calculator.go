@@ -0,0 +1,130 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterusage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/util/mergemap"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// Calculator calculates resources requested by clusters by rendering their templates
+type Calculator struct {
+	templateRepo   templaterepo.TemplateRepo
+	clusterGitRepo gitrepo.ClusterGitRepo
+}
+
+func NewCalculator(templateRepo templaterepo.TemplateRepo, clusterGitRepo gitrepo.ClusterGitRepo) *Calculator {
+	return &Calculator{
+		templateRepo:   templateRepo,
+		clusterGitRepo: clusterGitRepo,
+	}
+}
+
+// ClusterValues are values of the template to be changed in the gitops repo of cluster
+type ClusterValues struct {
+	// TemplateRelease is the new template release of cluster, it's nil if not changed
+	TemplateRelease *trmodels.TemplateRelease
+	// TemplateConfig replaces the application values of cluster, it's nil if not changed
+	TemplateConfig map[string]interface{}
+	// MergePatch merges the template config into the application values instead of replacing them
+	MergePatch bool
+}
+
+// ClusterResources calculates resources of the cluster with values in the default branch of its gitops repo,
+// the changes are applied before rendering if it's not nil
+func (c *Calculator) ClusterResources(ctx context.Context, application, cluster string,
+	changes *ClusterValues) (*Resources, error) {
+	const op = "quota calculator: cluster resources"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	chartFiles, err := c.clusterGitRepo.GetChartFiles(ctx, application, cluster, c.clusterGitRepo.DefaultBranch())
+	if err != nil {
+		return nil, err
+	}
+	if len(chartFiles.Chart.Dependencies) == 0 {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "no template found in chart of cluster %s", cluster)
+	}
+	dependency := chartFiles.Chart.Dependencies[0]
+	if changes == nil {
+		changes = &ClusterValues{}
+	}
+
+	values := make(map[string]interface{})
+	for _, valueFile := range chartFiles.ValueFiles {
+		if valueFile.FileName == common.GitopsFileApplication && changes.TemplateConfig != nil && !changes.MergePatch {
+			continue
+		}
+		fileValues, err := chartutil.ReadValues(valueFile.Content)
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"failed to read values from %s: %v", valueFile.FileName, err)
+		}
+		values, err = mergemap.Merge(values, fileValues)
+		if err != nil {
+			return nil, err
+		}
+	}
+	templateValues, _ := values[dependency.Name].(map[string]interface{})
+	if templateValues == nil {
+		templateValues = make(map[string]interface{})
+	}
+	if changes.TemplateConfig != nil {
+		templateValues, err = mergemap.Merge(templateValues, changes.TemplateConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	chartName, chartVersion := dependency.Name, dependency.Version
+	if changes.TemplateRelease != nil {
+		chartName, chartVersion = changes.TemplateRelease.ChartName, changes.TemplateRelease.ChartVersion
+	}
+	return c.render(chartName, chartVersion, templateValues, cluster)
+}
+
+// TemplateResources calculates resources of a cluster to be created with values of the template release
+func (c *Calculator) TemplateResources(ctx context.Context, release *trmodels.TemplateRelease,
+	values map[string]interface{}, cluster string) (*Resources, error) {
+	const op = "quota calculator: template resources"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return c.render(release.ChartName, release.ChartVersion, values, cluster)
+}
+
+func (c *Calculator) render(chartName, chartVersion string, values map[string]interface{},
+	cluster string) (*Resources, error) {
+	template, err := c.templateRepo.GetChart(chartName, chartVersion, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	namespace := ""
+	value, err := chartutil.Values(values).PathValue(fmt.Sprintf("%s.namespace", common.GitopsEnvValueNamespace))
+	if err == nil {
+		namespace, _ = value.(string)
+	}
+	return Render(template, values, cluster, namespace)
+}
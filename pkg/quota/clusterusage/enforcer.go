@@ -0,0 +1,301 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterusage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	quotamanager "github.com/horizoncd/horizon/pkg/quota/manager"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+	"github.com/horizoncd/horizon/pkg/quota/service"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	"github.com/horizoncd/horizon/pkg/util/mergemap"
+)
+
+// reservationTTL is how long resources reserved for an admitted request are counted,
+// they are released earlier once usage of the cluster is recorded after the request succeeds
+const reservationTTL = 5 * time.Minute
+
+// Target is the cluster to be admitted
+type Target struct {
+	GroupID     uint
+	Environment string
+	// ClusterID is zero for clusters to be created
+	ClusterID uint
+	Cluster   string
+	// Counted is true if the cluster is new to quotas of the environment
+	Counted bool
+	// Previous is the resources requested by the cluster before the request
+	Previous Resources
+	// Resources calculates resources requested by the cluster after the request,
+	// it's called only if quotas limit resources
+	Resources func() (*Resources, error)
+}
+
+// Enforcer admits clusters to be created and updated within quotas of their groups and environments
+type Enforcer struct {
+	calculator *Calculator
+	quotaSvc   service.Service
+	quotaMgr   quotamanager.Manager
+}
+
+func NewEnforcer(calculator *Calculator, manager *managerparam.Manager) *Enforcer {
+	return &Enforcer{
+		calculator: calculator,
+		quotaSvc:   service.NewService(manager),
+		quotaMgr:   manager.QuotaMgr,
+	}
+}
+
+// CreationValues returns values of the template release for a cluster to be created,
+// which are the same as those written to its gitops repo, the namespace is derived from the group if it's empty
+func CreationValues(application *appmodels.Application, release *trmodels.TemplateRelease,
+	environment, region, namespace, cluster string,
+	templateConfig map[string]interface{}) (map[string]interface{}, error) {
+	if namespace == "" {
+		namespace = fmt.Sprintf("%v-%v", environment, application.GroupID)
+	}
+	return mergemap.Merge(map[string]interface{}{
+		common.GitopsEnvValueNamespace: map[string]interface{}{
+			"environment": environment,
+			"region":      region,
+			"namespace":   namespace,
+		},
+		common.GitopsBaseValueNamespace: map[string]interface{}{
+			"application": application.Name,
+			"cluster":     cluster,
+			"template": map[string]interface{}{
+				"name":    release.TemplateName,
+				"release": release.ChartVersion,
+			},
+			"priority": string(application.Priority),
+		},
+	}, templateConfig)
+}
+
+// CreationTarget returns the target of creating the cluster of the application with the template release,
+// the template config is the final one written to its gitops repo
+func (e *Enforcer) CreationTarget(ctx context.Context, application *appmodels.Application,
+	release *trmodels.TemplateRelease, environment, region, namespace, cluster string,
+	templateConfig map[string]interface{}) *Target {
+	return &Target{
+		GroupID:     application.GroupID,
+		Environment: environment,
+		Cluster:     cluster,
+		Counted:     true,
+		Resources: func() (*Resources, error) {
+			values, err := CreationValues(application, release, environment, region, namespace, cluster,
+				templateConfig)
+			if err != nil {
+				return nil, err
+			}
+			return e.calculator.TemplateResources(ctx, release, values, cluster)
+		},
+	}
+}
+
+// UpdateTarget returns the target of updating the cluster of the application to the environment with the changes,
+// nil is returned if neither the cluster is moved nor its values are changed
+func (e *Enforcer) UpdateTarget(ctx context.Context, application *appmodels.Application,
+	cluster *clustermodels.Cluster, environment string, changes *ClusterValues) (*Target, error) {
+	counted := environment != "" && environment != cluster.EnvironmentName
+	if !counted {
+		environment = cluster.EnvironmentName
+		if changes.TemplateConfig == nil && changes.TemplateRelease == nil {
+			return nil, nil
+		}
+	}
+
+	t := &Target{
+		GroupID:     application.GroupID,
+		Environment: environment,
+		ClusterID:   cluster.ID,
+		Cluster:     cluster.Name,
+		Counted:     counted,
+		Resources: func() (*Resources, error) {
+			return e.calculator.ClusterResources(ctx, application.Name, cluster.Name, changes)
+		},
+	}
+	if !counted {
+		usage, err := e.quotaMgr.GetClusterUsage(ctx, cluster.ID)
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+				return nil, err
+			}
+		} else {
+			t.Previous = Resources{
+				CPU:      usage.CPU,
+				Memory:   usage.Memory,
+				Replicas: usage.Replicas,
+			}
+		}
+	}
+	return t, nil
+}
+
+// Check returns the reason if the target exceeds any of its quotas.
+// Resources are checked only if they are increased, so that clusters beyond quotas lowered can still be updated.
+// The quotas are locked while checking, and resources of the target are reserved under them if it's admitted,
+// so that concurrent requests never exceed the quotas together before usage of their clusters is recorded.
+func (e *Enforcer) Check(ctx context.Context, t *Target) (string, error) {
+	quotas, err := e.quotaSvc.ListOfCluster(ctx, t.GroupID, t.Environment)
+	if err != nil {
+		return "", err
+	}
+	if len(quotas) == 0 {
+		return "", nil
+	}
+
+	// scopes and resources are resolved before locking, rendering templates takes a while
+	scopes := make([]*service.Scope, 0, len(quotas))
+	quotaIDs := make([]uint, 0, len(quotas))
+	requested := &Resources{}
+	calculated := false
+	for _, quota := range quotas {
+		scope, err := e.quotaSvc.Scope(ctx, quota)
+		if err != nil {
+			return "", err
+		}
+		scopes = append(scopes, scope)
+		quotaIDs = append(quotaIDs, quota.ID)
+		if !calculated && (quota.MaxCPU != "" || quota.MaxMemory != "" || quota.MaxReplicas > 0) {
+			if requested, err = t.Resources(); err != nil {
+				return "", err
+			}
+			calculated = true
+		}
+	}
+
+	reason := ""
+	err = e.quotaMgr.Lock(ctx, quotaIDs, func(mgr quotamanager.Manager) error {
+		reservations := make([]*models.QuotaReservation, 0, len(quotas))
+		for i, quota := range quotas {
+			var (
+				usage = &models.Usage{}
+				err   error
+			)
+			if scopes[i] != nil {
+				usage, err = mgr.GetUsage(ctx, scopes[i].GroupIDs, scopes[i].Environment, t.ClusterID)
+				if err != nil {
+					return err
+				}
+			}
+			reserved, err := mgr.GetReserved(ctx, quota.ID, t.Cluster)
+			if err != nil {
+				return err
+			}
+			usage.Clusters += reserved.Clusters
+			usage.CPU += reserved.CPU
+			usage.Memory += reserved.Memory
+			usage.Replicas += reserved.Replicas
+
+			if reason, err = exceeds(quota, usage, t, requested); err != nil || reason != "" {
+				return err
+			}
+			reservation := &models.QuotaReservation{
+				QuotaID:   quota.ID,
+				Cluster:   t.Cluster,
+				CPU:       increment(requested.CPU, t.Previous.CPU),
+				Memory:    increment(requested.Memory, t.Previous.Memory),
+				Replicas:  int(increment(int64(requested.Replicas), int64(t.Previous.Replicas))),
+				ExpiresAt: time.Now().Add(reservationTTL),
+			}
+			if t.Counted {
+				reservation.Clusters = 1
+			}
+			reservations = append(reservations, reservation)
+		}
+		return mgr.Reserve(ctx, reservations)
+	})
+	if err != nil {
+		return "", err
+	}
+	return reason, nil
+}
+
+// Admit is the same as Check, but returns ErrQuotaExceeded with the reason if the target exceeds any of its quotas
+func (e *Enforcer) Admit(ctx context.Context, t *Target) error {
+	reason, err := e.Check(ctx, t)
+	if err != nil {
+		return err
+	}
+	if reason != "" {
+		return perror.Wrap(herrors.ErrQuotaExceeded, reason)
+	}
+	return nil
+}
+
+// exceeds returns the reason if the requested resources of the target exceed the quota with the usage
+func exceeds(quota *models.Quota, usage *models.Usage, t *Target, requested *Resources) (string, error) {
+	if t.Counted && quota.MaxClusters > 0 && usage.Clusters+1 > quota.MaxClusters {
+		return fmt.Sprintf("number of clusters exceeds %d limited by %s",
+			quota.MaxClusters, describe(quota)), nil
+	}
+	if quota.MaxCPU != "" && requested.CPU > t.Previous.CPU {
+		maxCPU, err := resource.ParseQuantity(quota.MaxCPU)
+		if err != nil {
+			return "", perror.Wrapf(herrors.ErrParamInvalid, "invalid cpu of quota %d: %v", quota.ID, err)
+		}
+		if total := usage.CPU + requested.CPU; total > maxCPU.MilliValue() {
+			return fmt.Sprintf("cpu requests %s exceed %s limited by %s",
+				resource.NewMilliQuantity(total, resource.DecimalSI), quota.MaxCPU, describe(quota)), nil
+		}
+	}
+	if quota.MaxMemory != "" && requested.Memory > t.Previous.Memory {
+		maxMemory, err := resource.ParseQuantity(quota.MaxMemory)
+		if err != nil {
+			return "", perror.Wrapf(herrors.ErrParamInvalid, "invalid memory of quota %d: %v", quota.ID, err)
+		}
+		if total := usage.Memory + requested.Memory; total > maxMemory.Value() {
+			return fmt.Sprintf("memory requests %s exceed %s limited by %s",
+				resource.NewQuantity(total, resource.BinarySI), quota.MaxMemory, describe(quota)), nil
+		}
+	}
+	if quota.MaxReplicas > 0 && requested.Replicas > t.Previous.Replicas {
+		if total := usage.Replicas + requested.Replicas; total > quota.MaxReplicas {
+			return fmt.Sprintf("replicas %d exceed %d limited by %s",
+				total, quota.MaxReplicas, describe(quota)), nil
+		}
+	}
+	return "", nil
+}
+
+// increment returns how much the resource is increased by the request, decrements are not reserved
+func increment(requested, previous int64) int64 {
+	if requested > previous {
+		return requested - previous
+	}
+	return 0
+}
+
+func describe(quota *models.Quota) string {
+	if quota.ResourceType == models.ResourceTypeEnvironment {
+		return fmt.Sprintf("quota %d of environment %d", quota.ID, quota.ResourceID)
+	}
+	if quota.Environment == "" {
+		return fmt.Sprintf("quota %d of group %d", quota.ID, quota.ResourceID)
+	}
+	return fmt.Sprintf("quota %d of group %d in environment %s", quota.ID, quota.ResourceID, quota.Environment)
+}
@@ -0,0 +1,68 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterusage
+
+import (
+	"context"
+
+	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	quotamanager "github.com/horizoncd/horizon/pkg/quota/manager"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+)
+
+// Recorder saves resources requested by clusters, which are summed up to check quotas
+type Recorder struct {
+	calculator     *Calculator
+	quotaMgr       quotamanager.Manager
+	applicationMgr appmanager.Manager
+}
+
+func NewRecorder(calculator *Calculator, manager *managerparam.Manager) *Recorder {
+	return &Recorder{
+		calculator:     calculator,
+		quotaMgr:       manager.QuotaMgr,
+		applicationMgr: manager.ApplicationMgr,
+	}
+}
+
+// Record calculates resources of the cluster with values in its gitops repo and saves them as its usage
+func (r *Recorder) Record(ctx context.Context, cluster *clustermodels.Cluster) error {
+	application, err := r.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return err
+	}
+	resources, err := r.calculator.ClusterResources(ctx, application.Name, cluster.Name, nil)
+	if err != nil {
+		return err
+	}
+	return r.quotaMgr.UpsertClusterUsage(ctx, &models.ClusterUsage{
+		ClusterID: cluster.ID,
+		CPU:       resources.CPU,
+		Memory:    resources.Memory,
+		Replicas:  resources.Replicas,
+	})
+}
+
+// RecordAndRelease records usage of the cluster written by an admitted request,
+// and then releases the resources reserved for the request.
+// The reservation is kept until it expires if the usage can not be recorded.
+func (r *Recorder) RecordAndRelease(ctx context.Context, cluster *clustermodels.Cluster) error {
+	if err := r.Record(ctx, cluster); err != nil {
+		return err
+	}
+	return r.quotaMgr.Release(ctx, cluster.Name)
+}
@@ -0,0 +1,153 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterusage
+
+import (
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// Resources are requested by workloads of a cluster
+type Resources struct {
+	// CPU is in millicores
+	CPU int64
+	// Memory is in bytes
+	Memory   int64
+	Replicas int
+}
+
+// workloads whose replicas of pod template are counted
+var _workloadKinds = map[string]bool{
+	"Deployment":  true,
+	"StatefulSet": true,
+	"ReplicaSet":  true,
+	"Rollout":     true,
+}
+
+type workload struct {
+	Spec struct {
+		Replicas *int32                 `json:"replicas"`
+		Template corev1.PodTemplateSpec `json:"template"`
+	} `json:"spec"`
+}
+
+// Render renders the template chart with values of the template,
+// and sums up resources requested by the workloads rendered
+func Render(template *chart.Chart, values map[string]interface{}, release, namespace string) (*Resources, error) {
+	renderValues, err := chartutil.ToRenderValues(template, values, chartutil.ReleaseOptions{
+		Name:      release,
+		Namespace: namespace,
+		Revision:  1,
+		IsInstall: true,
+	}, chartutil.DefaultCapabilities)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to assemble values of template %s: %v",
+			template.Name(), err)
+	}
+	files, err := engine.Render(template, renderValues)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to render template %s: %v", template.Name(), err)
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if strings.HasSuffix(name, "NOTES.txt") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var manifests strings.Builder
+	for _, name := range names {
+		manifests.WriteString("\n---\n")
+		manifests.WriteString(files[name])
+	}
+	return FromManifests(manifests.String())
+}
+
+// FromManifests sums up resources requested by the workloads in yaml manifests,
+// which are replicas multiplied by requests of the pod template, limits are taken if requests are not set
+func FromManifests(manifests string) (*Resources, error) {
+	resources := &Resources{}
+	for _, manifest := range releaseutil.SplitManifests(manifests) {
+		var typeMeta metav1.TypeMeta
+		if err := yaml.Unmarshal([]byte(manifest), &typeMeta); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to unmarshal manifest: %v", err)
+		}
+		if !_workloadKinds[typeMeta.Kind] {
+			continue
+		}
+		var w workload
+		if err := yaml.Unmarshal([]byte(manifest), &w); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to unmarshal %s: %v", typeMeta.Kind, err)
+		}
+		replicas := 1
+		if w.Spec.Replicas != nil {
+			replicas = int(*w.Spec.Replicas)
+		}
+		cpu, memory := podRequests(&w.Spec.Template.Spec)
+		resources.Replicas += replicas
+		resources.CPU += cpu * int64(replicas)
+		resources.Memory += memory * int64(replicas)
+	}
+	return resources, nil
+}
+
+// podRequests returns cpu in millicores and memory in bytes requested by the pod,
+// init containers run one by one before containers, so the larger of them is requested
+func podRequests(spec *corev1.PodSpec) (int64, int64) {
+	var cpu, memory int64
+	for i := range spec.Containers {
+		c, m := containerRequests(&spec.Containers[i])
+		cpu += c
+		memory += m
+	}
+	for i := range spec.InitContainers {
+		c, m := containerRequests(&spec.InitContainers[i])
+		if c > cpu {
+			cpu = c
+		}
+		if m > memory {
+			memory = m
+		}
+	}
+	return cpu, memory
+}
+
+func containerRequests(container *corev1.Container) (int64, int64) {
+	var cpu, memory int64
+	if quantity, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
+		cpu = quantity.MilliValue()
+	} else if quantity, ok := container.Resources.Limits[corev1.ResourceCPU]; ok {
+		cpu = quantity.MilliValue()
+	}
+	if quantity, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
+		memory = quantity.Value()
+	} else if quantity, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
+		memory = quantity.Value()
+	}
+	return cpu, memory
+}
@@ -0,0 +1,145 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterusage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const _deploymentTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}
+  namespace: {{ .Values.env.namespace }}
+spec:
+  replicas: {{ .Values.app.spec.replicas }}
+  template:
+    spec:
+      containers:
+        - name: app
+          resources:
+            requests:
+              cpu: {{ .Values.app.spec.cpu | quote }}
+              memory: {{ .Values.app.spec.memory }}
+`
+
+func testChart() *chart.Chart {
+	return &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "javaapp",
+			Version:    "v1.0.0",
+		},
+		Templates: []*chart.File{
+			{Name: "templates/deployment.yaml", Data: []byte(_deploymentTemplate)},
+			{Name: "templates/service.yaml", Data: []byte(`apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Release.Name }}
+spec:
+  replicas: "not a workload"
+`)},
+			{Name: "templates/NOTES.txt", Data: []byte("kind: Deployment")},
+		},
+		Values: map[string]interface{}{
+			"app": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"replicas": 1,
+					"cpu":      "500m",
+					"memory":   "1Gi",
+				},
+			},
+		},
+	}
+}
+
+func TestFromManifests(t *testing.T) {
+	resources, err := FromManifests(`
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  replicas: 2
+  template:
+    spec:
+      initContainers:
+        - name: init
+          resources:
+            requests:
+              cpu: "2"
+              memory: 128Mi
+      containers:
+        - name: app
+          resources:
+            requests:
+              cpu: 500m
+              memory: 1Gi
+        - name: sidecar
+          resources:
+            requests:
+              cpu: 100m
+              memory: 128Mi
+---
+apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          resources:
+            limits:
+              cpu: "1"
+              memory: 2Gi
+---
+apiVersion: batch/v1
+kind: Job
+spec:
+  template:
+    spec:
+      containers:
+        - name: job
+          resources:
+            requests:
+              cpu: "8"
+`)
+	assert.Nil(t, err)
+	// init container of deployment requests more cpu than containers, limits are taken for rollout
+	assert.Equal(t, &Resources{
+		CPU:      2*2000 + 1000,
+		Memory:   2*(1152<<20) + (2 << 30),
+		Replicas: 3,
+	}, resources)
+
+	_, err = FromManifests("kind: [")
+	assert.NotNil(t, err)
+}
+
+func TestRender(t *testing.T) {
+	resources, err := Render(testChart(), map[string]interface{}{
+		"app": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replicas": 3,
+			},
+		},
+		"env": map[string]interface{}{
+			"namespace": "online-1",
+		},
+	}, "cluster", "online-1")
+	assert.Nil(t, err)
+	assert.Equal(t, &Resources{CPU: 1500, Memory: 3 << 30, Replicas: 3}, resources)
+}
@@ -0,0 +1,260 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, quota *models.Quota) (*models.Quota, error)
+	GetByID(ctx context.Context, id uint) (*models.Quota, error)
+	Get(ctx context.Context, resourceType string, resourceID uint, environment string) (*models.Quota, error)
+	ListByResources(ctx context.Context, resourceType string, resourceIDs []uint) ([]*models.Quota, error)
+	Update(ctx context.Context, quota *models.Quota) error
+	DeleteByID(ctx context.Context, id uint) error
+
+	GetClusterUsage(ctx context.Context, clusterID uint) (*models.ClusterUsage, error)
+	CreateClusterUsage(ctx context.Context, usage *models.ClusterUsage) (*models.ClusterUsage, error)
+	UpdateClusterUsage(ctx context.Context, usage *models.ClusterUsage) error
+	DeleteClusterUsage(ctx context.Context, clusterID uint) error
+	// ListClusterIDsWithoutUsage lists clusters whose usage has never been calculated, freed clusters are skipped
+	ListClusterIDsWithoutUsage(ctx context.Context) ([]uint, error)
+	// GetUsage sums up clusters of applications in the groups and the environment,
+	// clusters of all groups or all environments are summed up if groupIDs or environment is empty
+	GetUsage(ctx context.Context, groupIDs []uint, environment string, excludedClusterID uint) (*models.Usage, error)
+
+	// Lock locks the quotas in a transaction until fn returns, the DAO passed to fn works in the transaction
+	Lock(ctx context.Context, ids []uint, fn func(tx DAO) error) error
+	CreateReservations(ctx context.Context, reservations []*models.QuotaReservation) error
+	DeleteReservations(ctx context.Context, cluster string) error
+	DeleteReservationsExpiredBefore(ctx context.Context, deadline time.Time) error
+	// GetReserved sums up reservations of the quota which expire after now, excluding those of the cluster
+	GetReserved(ctx context.Context, quotaID uint, excludedCluster string, now time.Time) (*models.Usage, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, quota *models.Quota) (*models.Quota, error) {
+	if err := d.db.WithContext(ctx).Create(quota).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.QuotaInDB, err.Error())
+	}
+	return quota, nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.Quota, error) {
+	var quota models.Quota
+	if err := d.db.WithContext(ctx).Where("id = ?", id).First(&quota).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.QuotaInDB, fmt.Sprintf("quota %d was not found", id))
+		}
+		return nil, herrors.NewErrGetFailed(herrors.QuotaInDB, err.Error())
+	}
+	return &quota, nil
+}
+
+func (d *dao) Get(ctx context.Context, resourceType string, resourceID uint,
+	environment string) (*models.Quota, error) {
+	var quota models.Quota
+	if err := d.db.WithContext(ctx).
+		Where("resource_type = ? and resource_id = ? and environment = ?", resourceType, resourceID, environment).
+		First(&quota).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.QuotaInDB,
+				fmt.Sprintf("quota of %s %d in environment %q was not found", resourceType, resourceID, environment))
+		}
+		return nil, herrors.NewErrGetFailed(herrors.QuotaInDB, err.Error())
+	}
+	return &quota, nil
+}
+
+func (d *dao) ListByResources(ctx context.Context, resourceType string,
+	resourceIDs []uint) ([]*models.Quota, error) {
+	var quotas []*models.Quota
+	if len(resourceIDs) == 0 {
+		return quotas, nil
+	}
+	if err := d.db.WithContext(ctx).Where("resource_type = ? and resource_id in ?", resourceType, resourceIDs).
+		Order("id asc").Find(&quotas).Error; err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.QuotaInDB, err.Error())
+	}
+	return quotas, nil
+}
+
+func (d *dao) Update(ctx context.Context, quota *models.Quota) error {
+	result := d.db.WithContext(ctx).Model(&models.Quota{}).Where("id = ?", quota.ID).
+		Updates(map[string]interface{}{
+			"max_clusters": quota.MaxClusters,
+			"max_cpu":      quota.MaxCPU,
+			"max_memory":   quota.MaxMemory,
+			"max_replicas": quota.MaxReplicas,
+			"updated_by":   quota.UpdatedBy,
+		})
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.QuotaInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return herrors.NewErrNotFound(herrors.QuotaInDB, fmt.Sprintf("quota %d was not found", quota.ID))
+	}
+	return nil
+}
+
+func (d *dao) DeleteByID(ctx context.Context, id uint) error {
+	if err := d.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Quota{}).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.QuotaInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) GetClusterUsage(ctx context.Context, clusterID uint) (*models.ClusterUsage, error) {
+	var usage models.ClusterUsage
+	if err := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).First(&usage).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.ClusterUsageInDB,
+				fmt.Sprintf("usage of cluster %d was not found", clusterID))
+		}
+		return nil, herrors.NewErrGetFailed(herrors.ClusterUsageInDB, err.Error())
+	}
+	return &usage, nil
+}
+
+func (d *dao) CreateClusterUsage(ctx context.Context, usage *models.ClusterUsage) (*models.ClusterUsage, error) {
+	if err := d.db.WithContext(ctx).Create(usage).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.ClusterUsageInDB, err.Error())
+	}
+	return usage, nil
+}
+
+func (d *dao) UpdateClusterUsage(ctx context.Context, usage *models.ClusterUsage) error {
+	if err := d.db.WithContext(ctx).Model(&models.ClusterUsage{}).Where("id = ?", usage.ID).
+		Updates(map[string]interface{}{
+			"cpu":      usage.CPU,
+			"memory":   usage.Memory,
+			"replicas": usage.Replicas,
+		}).Error; err != nil {
+		return herrors.NewErrUpdateFailed(herrors.ClusterUsageInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) DeleteClusterUsage(ctx context.Context, clusterID uint) error {
+	if err := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).
+		Delete(&models.ClusterUsage{}).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.ClusterUsageInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) ListClusterIDsWithoutUsage(ctx context.Context) ([]uint, error) {
+	var ids []uint
+	if err := d.db.WithContext(ctx).Table("tb_cluster c").Select("c.id").
+		Joins("left join tb_cluster_usage u on u.cluster_id = c.id").
+		Where("c.deleted_ts = 0 and c.status <> ? and u.id is null", common.ClusterStatusFreed).
+		Order("c.id asc").Scan(&ids).Error; err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ClusterUsageInDB, err.Error())
+	}
+	return ids, nil
+}
+
+func (d *dao) GetUsage(ctx context.Context, groupIDs []uint, environment string,
+	excludedClusterID uint) (*models.Usage, error) {
+	query := d.db.WithContext(ctx).Table("tb_cluster c").
+		Select("count(c.id) as clusters, coalesce(sum(u.cpu), 0) as cpu, " +
+			"coalesce(sum(u.memory), 0) as memory, coalesce(sum(u.replicas), 0) as replicas").
+		Joins("join tb_application a on a.id = c.application_id and a.deleted_ts = 0").
+		Joins("left join tb_cluster_usage u on u.cluster_id = c.id").
+		Where("c.deleted_ts = 0")
+	if len(groupIDs) > 0 {
+		query = query.Where("a.group_id in ?", groupIDs)
+	}
+	if environment != "" {
+		query = query.Where("c.environment_name = ?", environment)
+	}
+	if excludedClusterID != 0 {
+		query = query.Where("c.id <> ?", excludedClusterID)
+	}
+	var usage models.Usage
+	if err := query.Scan(&usage).Error; err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ClusterUsageInDB, err.Error())
+	}
+	return &usage, nil
+}
+
+func (d *dao) Lock(ctx context.Context, ids []uint, fn func(tx DAO) error) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// locks are taken in the order of ids, so that concurrent requests never deadlock
+		var quotas []*models.Quota
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id in ?", ids).
+			Order("id asc").Find(&quotas).Error; err != nil {
+			return herrors.NewErrGetFailed(herrors.QuotaInDB, err.Error())
+		}
+		return fn(&dao{db: tx})
+	})
+}
+
+func (d *dao) CreateReservations(ctx context.Context, reservations []*models.QuotaReservation) error {
+	if len(reservations) == 0 {
+		return nil
+	}
+	if err := d.db.WithContext(ctx).Create(reservations).Error; err != nil {
+		return herrors.NewErrInsertFailed(herrors.QuotaReservationInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) DeleteReservations(ctx context.Context, cluster string) error {
+	if err := d.db.WithContext(ctx).Where("cluster = ?", cluster).
+		Delete(&models.QuotaReservation{}).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.QuotaReservationInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) DeleteReservationsExpiredBefore(ctx context.Context, deadline time.Time) error {
+	if err := d.db.WithContext(ctx).Where("expires_at < ?", deadline).
+		Delete(&models.QuotaReservation{}).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.QuotaReservationInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) GetReserved(ctx context.Context, quotaID uint, excludedCluster string,
+	now time.Time) (*models.Usage, error) {
+	var usage models.Usage
+	if err := d.db.WithContext(ctx).Model(&models.QuotaReservation{}).
+		Select("coalesce(sum(clusters), 0) as clusters, coalesce(sum(cpu), 0) as cpu, "+
+			"coalesce(sum(memory), 0) as memory, coalesce(sum(replicas), 0) as replicas").
+		Where("quota_id = ? and cluster <> ? and expires_at > ?", quotaID, excludedCluster, now).
+		Scan(&usage).Error; err != nil {
+		return nil, herrors.NewErrGetFailed(herrors.QuotaReservationInDB, err.Error())
+	}
+	return &usage, nil
+}
@@ -0,0 +1,160 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/quota/dao"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+)
+
+type Manager interface {
+	// Create returns ErrQuotaExists if the resource has a quota in the same environment
+	Create(ctx context.Context, quota *models.Quota) (*models.Quota, error)
+	GetByID(ctx context.Context, id uint) (*models.Quota, error)
+	// ListByResources lists quotas of the groups or environments
+	ListByResources(ctx context.Context, resourceType string, resourceIDs []uint) ([]*models.Quota, error)
+	// Update updates limits of the quota
+	Update(ctx context.Context, quota *models.Quota) error
+	DeleteByID(ctx context.Context, id uint) error
+
+	// GetClusterUsage returns HorizonErrNotFound if usage of the cluster has never been calculated
+	GetClusterUsage(ctx context.Context, clusterID uint) (*models.ClusterUsage, error)
+	// UpsertClusterUsage saves the latest resources requested by the cluster
+	UpsertClusterUsage(ctx context.Context, usage *models.ClusterUsage) error
+	DeleteClusterUsage(ctx context.Context, clusterID uint) error
+	// ListClusterIDsWithoutUsage lists clusters whose usage has never been calculated, freed clusters are skipped
+	ListClusterIDsWithoutUsage(ctx context.Context) ([]uint, error)
+	// GetUsage sums up clusters of applications in the groups and the environment,
+	// clusters of all groups or all environments are summed up if groupIDs or environment is empty,
+	// the excluded cluster is not summed up if it's not zero
+	GetUsage(ctx context.Context, groupIDs []uint, environment string, excludedClusterID uint) (*models.Usage, error)
+
+	// Lock locks the quotas until fn returns, the manager passed to fn works in the same transaction,
+	// so that concurrent requests check and reserve the quotas one by one
+	Lock(ctx context.Context, quotaIDs []uint, fn func(mgr Manager) error) error
+	// Reserve saves the reservations and deletes the expired ones, earlier reservations of the same clusters
+	// are replaced, so that a request checked both by the webhook and the controller is reserved once
+	Reserve(ctx context.Context, reservations []*models.QuotaReservation) error
+	// Release deletes reservations of the cluster, which are counted in its usage afterwards
+	Release(ctx context.Context, cluster string) error
+	// GetReserved sums up the reservations of the quota not expired, excluding those of the cluster
+	GetReserved(ctx context.Context, quotaID uint, excludedCluster string) (*models.Usage, error)
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Create(ctx context.Context, quota *models.Quota) (*models.Quota, error) {
+	_, err := m.dao.Get(ctx, quota.ResourceType, quota.ResourceID, quota.Environment)
+	if err == nil {
+		return nil, perror.Wrapf(herrors.ErrQuotaExists, "quota of %s %d in environment %q",
+			quota.ResourceType, quota.ResourceID, quota.Environment)
+	}
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+		return nil, err
+	}
+	return m.dao.Create(ctx, quota)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.Quota, error) {
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) ListByResources(ctx context.Context, resourceType string,
+	resourceIDs []uint) ([]*models.Quota, error) {
+	return m.dao.ListByResources(ctx, resourceType, resourceIDs)
+}
+
+func (m *manager) Update(ctx context.Context, quota *models.Quota) error {
+	return m.dao.Update(ctx, quota)
+}
+
+func (m *manager) DeleteByID(ctx context.Context, id uint) error {
+	return m.dao.DeleteByID(ctx, id)
+}
+
+func (m *manager) GetClusterUsage(ctx context.Context, clusterID uint) (*models.ClusterUsage, error) {
+	return m.dao.GetClusterUsage(ctx, clusterID)
+}
+
+func (m *manager) UpsertClusterUsage(ctx context.Context, usage *models.ClusterUsage) error {
+	previous, err := m.dao.GetClusterUsage(ctx, usage.ClusterID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return err
+		}
+		_, err = m.dao.CreateClusterUsage(ctx, usage)
+		return err
+	}
+	usage.ID = previous.ID
+	return m.dao.UpdateClusterUsage(ctx, usage)
+}
+
+func (m *manager) DeleteClusterUsage(ctx context.Context, clusterID uint) error {
+	return m.dao.DeleteClusterUsage(ctx, clusterID)
+}
+
+func (m *manager) ListClusterIDsWithoutUsage(ctx context.Context) ([]uint, error) {
+	return m.dao.ListClusterIDsWithoutUsage(ctx)
+}
+
+func (m *manager) GetUsage(ctx context.Context, groupIDs []uint, environment string,
+	excludedClusterID uint) (*models.Usage, error) {
+	return m.dao.GetUsage(ctx, groupIDs, environment, excludedClusterID)
+}
+
+func (m *manager) Lock(ctx context.Context, quotaIDs []uint, fn func(mgr Manager) error) error {
+	return m.dao.Lock(ctx, quotaIDs, func(tx dao.DAO) error {
+		return fn(&manager{dao: tx})
+	})
+}
+
+func (m *manager) Reserve(ctx context.Context, reservations []*models.QuotaReservation) error {
+	if err := m.dao.DeleteReservationsExpiredBefore(ctx, time.Now()); err != nil {
+		return err
+	}
+	replaced := make(map[string]struct{})
+	for _, reservation := range reservations {
+		if _, ok := replaced[reservation.Cluster]; ok {
+			continue
+		}
+		replaced[reservation.Cluster] = struct{}{}
+		if err := m.dao.DeleteReservations(ctx, reservation.Cluster); err != nil {
+			return err
+		}
+	}
+	return m.dao.CreateReservations(ctx, reservations)
+}
+
+func (m *manager) Release(ctx context.Context, cluster string) error {
+	return m.dao.DeleteReservations(ctx, cluster)
+}
+
+func (m *manager) GetReserved(ctx context.Context, quotaID uint,
+	excludedCluster string) (*models.Usage, error) {
+	return m.dao.GetReserved(ctx, quotaID, excludedCluster, time.Now())
+}
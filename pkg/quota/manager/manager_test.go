@@ -0,0 +1,182 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+)
+
+var (
+	db, _ = orm.NewTestDB()
+	ctx   = context.TODO()
+	mgr   = New(db)
+)
+
+func init() {
+	if err := db.AutoMigrate(&models.Quota{}, &models.ClusterUsage{}, &models.QuotaReservation{},
+		&appmodels.Application{}, &clustermodels.Cluster{}); err != nil {
+		panic(err)
+	}
+}
+
+func TestQuota(t *testing.T) {
+	quota, err := mgr.Create(ctx, &models.Quota{
+		ResourceType: models.ResourceTypeGroup,
+		ResourceID:   1,
+		Environment:  "online",
+		MaxClusters:  10,
+		MaxCPU:       "16",
+	})
+	assert.Nil(t, err)
+
+	_, err = mgr.Create(ctx, &models.Quota{
+		ResourceType: models.ResourceTypeGroup,
+		ResourceID:   1,
+		Environment:  "online",
+	})
+	assert.Equal(t, herrors.ErrQuotaExists, perror.Cause(err))
+
+	// quotas of the same group in other environments are allowed
+	_, err = mgr.Create(ctx, &models.Quota{
+		ResourceType: models.ResourceTypeGroup,
+		ResourceID:   1,
+	})
+	assert.Nil(t, err)
+
+	quotas, err := mgr.ListByResources(ctx, models.ResourceTypeGroup, []uint{1, 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(quotas))
+	quotas, err = mgr.ListByResources(ctx, models.ResourceTypeEnvironment, []uint{1})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(quotas))
+
+	quota.MaxClusters = 0
+	quota.MaxMemory = "32Gi"
+	assert.Nil(t, mgr.Update(ctx, quota))
+	quota, err = mgr.GetByID(ctx, quota.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, quota.MaxClusters)
+	assert.Equal(t, "16", quota.MaxCPU)
+	assert.Equal(t, "32Gi", quota.MaxMemory)
+
+	assert.Nil(t, mgr.DeleteByID(ctx, quota.ID))
+	_, err = mgr.GetByID(ctx, quota.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
+
+func TestUsage(t *testing.T) {
+	app1 := &appmodels.Application{Name: "app1", GroupID: 1}
+	app2 := &appmodels.Application{Name: "app2", GroupID: 2}
+	assert.Nil(t, db.Create(app1).Error)
+	assert.Nil(t, db.Create(app2).Error)
+	clusters := []*clustermodels.Cluster{
+		{Name: "cluster1", ApplicationID: app1.ID, EnvironmentName: "online"},
+		{Name: "cluster2", ApplicationID: app1.ID, EnvironmentName: "test"},
+		{Name: "cluster3", ApplicationID: app2.ID, EnvironmentName: "online"},
+	}
+	for _, cluster := range clusters {
+		assert.Nil(t, db.Create(cluster).Error)
+		assert.Nil(t, mgr.UpsertClusterUsage(ctx, &models.ClusterUsage{
+			ClusterID: cluster.ID,
+			CPU:       1000,
+			Memory:    1 << 30,
+			Replicas:  1,
+		}))
+	}
+	assert.Nil(t, mgr.UpsertClusterUsage(ctx, &models.ClusterUsage{
+		ClusterID: clusters[0].ID,
+		CPU:       2000,
+		Memory:    2 << 30,
+		Replicas:  2,
+	}))
+
+	clusterUsage, err := mgr.GetClusterUsage(ctx, clusters[0].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2000), clusterUsage.CPU)
+
+	usage, err := mgr.GetUsage(ctx, []uint{1}, "", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, models.Usage{Clusters: 2, CPU: 3000, Memory: 3 << 30, Replicas: 3}, *usage)
+
+	usage, err = mgr.GetUsage(ctx, nil, "online", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, models.Usage{Clusters: 2, CPU: 3000, Memory: 3 << 30, Replicas: 3}, *usage)
+
+	usage, err = mgr.GetUsage(ctx, []uint{1, 2}, "online", clusters[0].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.Usage{Clusters: 1, CPU: 1000, Memory: 1 << 30, Replicas: 1}, *usage)
+
+	// clusters without usage are counted
+	assert.Nil(t, mgr.DeleteClusterUsage(ctx, clusters[1].ID))
+	ids, err := mgr.ListClusterIDsWithoutUsage(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{clusters[1].ID}, ids)
+	usage, err = mgr.GetUsage(ctx, []uint{1}, "", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, models.Usage{Clusters: 2, CPU: 2000, Memory: 2 << 30, Replicas: 2}, *usage)
+
+	// deleted clusters are not counted
+	assert.Nil(t, db.Delete(clusters[0]).Error)
+	usage, err = mgr.GetUsage(ctx, []uint{1}, "", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, models.Usage{Clusters: 1}, *usage)
+}
+
+func TestReservation(t *testing.T) {
+	now := time.Now()
+	err := mgr.Lock(ctx, []uint{1}, func(mgr Manager) error {
+		return mgr.Reserve(ctx, []*models.QuotaReservation{
+			{QuotaID: 1, Cluster: "cluster1", Clusters: 1, CPU: 1000, Replicas: 1, ExpiresAt: now.Add(time.Minute)},
+			{QuotaID: 1, Cluster: "cluster2", CPU: 500, Memory: 1 << 30, ExpiresAt: now.Add(time.Minute)},
+			{QuotaID: 1, Cluster: "cluster3", CPU: 2000, ExpiresAt: now.Add(-time.Minute)},
+			{QuotaID: 2, Cluster: "cluster1", Clusters: 1, CPU: 1000, ExpiresAt: now.Add(time.Minute)},
+		})
+	})
+	assert.Nil(t, err)
+
+	// expired reservations are not counted
+	reserved, err := mgr.GetReserved(ctx, 1, "")
+	assert.Nil(t, err)
+	assert.Equal(t, models.Usage{Clusters: 1, CPU: 1500, Memory: 1 << 30, Replicas: 1}, *reserved)
+
+	reserved, err = mgr.GetReserved(ctx, 1, "cluster1")
+	assert.Nil(t, err)
+	assert.Equal(t, models.Usage{CPU: 500, Memory: 1 << 30}, *reserved)
+
+	// reserving for the same cluster again replaces its earlier reservations
+	assert.Nil(t, mgr.Reserve(ctx, []*models.QuotaReservation{
+		{QuotaID: 1, Cluster: "cluster2", CPU: 200, ExpiresAt: now.Add(time.Minute)},
+	}))
+	reserved, err = mgr.GetReserved(ctx, 1, "")
+	assert.Nil(t, err)
+	assert.Equal(t, models.Usage{Clusters: 1, CPU: 1200, Replicas: 1}, *reserved)
+
+	assert.Nil(t, mgr.Release(ctx, "cluster1"))
+	reserved, err = mgr.GetReserved(ctx, 2, "")
+	assert.Nil(t, err)
+	assert.Equal(t, models.Usage{}, *reserved)
+}
@@ -0,0 +1,77 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+const (
+	ResourceTypeGroup       = "groups"
+	ResourceTypeEnvironment = "environments"
+)
+
+// Quota limits clusters of applications under a group and its subgroups, or clusters in an environment.
+// Limits which are zero or empty are unlimited.
+type Quota struct {
+	ID           uint `gorm:"primarykey"`
+	ResourceType string
+	ResourceID   uint
+	// Environment restricts a group quota to clusters in the environment, it's empty for all environments
+	Environment string
+	MaxClusters int
+	// MaxCPU and MaxMemory are kubernetes quantities, such as 16 and 32Gi
+	MaxCPU      string `gorm:"column:max_cpu"`
+	MaxMemory   string
+	MaxReplicas int
+	CreatedBy   uint
+	UpdatedBy   uint
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ClusterUsage is the resources requested by a cluster, which is calculated from the rendered values of the cluster
+type ClusterUsage struct {
+	ID        uint `gorm:"primarykey"`
+	ClusterID uint
+	// CPU is in millicores
+	CPU int64 `gorm:"column:cpu"`
+	// Memory is in bytes
+	Memory    int64
+	Replicas  int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Usage is the resources requested by clusters restricted by a quota
+type Usage struct {
+	Clusters int
+	CPU      int64
+	Memory   int64
+	Replicas int
+}
+
+// QuotaReservation is the resources reserved under a quota by an admitted request of the cluster,
+// which are counted until usage of the cluster is saved or the reservation expires
+type QuotaReservation struct {
+	ID      uint `gorm:"primarykey"`
+	QuotaID uint
+	Cluster string
+	// Clusters is 1 if the cluster is new to the quota
+	Clusters  int
+	CPU       int64 `gorm:"column:cpu"`
+	Memory    int64
+	Replicas  int
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
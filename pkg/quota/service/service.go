@@ -0,0 +1,127 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+
+	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	quotamanager "github.com/horizoncd/horizon/pkg/quota/manager"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+)
+
+type Service interface {
+	// ListOfGroup lists quotas of the group and its ancestors, which are inherited by the group
+	ListOfGroup(ctx context.Context, groupID uint) ([]*models.Quota, error)
+	// ListOfCluster lists quotas restricting clusters of applications in the group and the environment
+	ListOfCluster(ctx context.Context, groupID uint, environment string) ([]*models.Quota, error)
+	// GetUsage sums up clusters restricted by the quota, the excluded cluster is not summed up if it's not zero
+	GetUsage(ctx context.Context, quota *models.Quota, excludedClusterID uint) (*models.Usage, error)
+	// Scope returns the groups and the environment of clusters restricted by the quota,
+	// nil is returned if the group of the quota has been deleted
+	Scope(ctx context.Context, quota *models.Quota) (*Scope, error)
+}
+
+// Scope is the clusters restricted by a quota
+type Scope struct {
+	// GroupIDs are the group of a group quota and its subgroups, it's nil for environment quotas
+	GroupIDs []uint
+	// Environment is empty for group quotas of all environments
+	Environment string
+}
+
+type service struct {
+	quotaMgr quotamanager.Manager
+	groupMgr groupmanager.Manager
+	envMgr   envmanager.Manager
+}
+
+func NewService(manager *managerparam.Manager) Service {
+	return &service{
+		quotaMgr: manager.QuotaMgr,
+		groupMgr: manager.GroupMgr,
+		envMgr:   manager.EnvMgr,
+	}
+}
+
+func (s *service) ListOfGroup(ctx context.Context, groupID uint) ([]*models.Quota, error) {
+	group, err := s.groupMgr.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return s.quotaMgr.ListByResources(ctx, models.ResourceTypeGroup,
+		groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs))
+}
+
+func (s *service) ListOfCluster(ctx context.Context, groupID uint, environment string) ([]*models.Quota, error) {
+	groupQuotas, err := s.ListOfGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	quotas := make([]*models.Quota, 0, len(groupQuotas))
+	for _, quota := range groupQuotas {
+		if quota.Environment == "" || quota.Environment == environment {
+			quotas = append(quotas, quota)
+		}
+	}
+
+	env, err := s.envMgr.GetByName(ctx, environment)
+	if err != nil {
+		return nil, err
+	}
+	envQuotas, err := s.quotaMgr.ListByResources(ctx, models.ResourceTypeEnvironment, []uint{env.ID})
+	if err != nil {
+		return nil, err
+	}
+	return append(quotas, envQuotas...), nil
+}
+
+func (s *service) GetUsage(ctx context.Context, quota *models.Quota,
+	excludedClusterID uint) (*models.Usage, error) {
+	scope, err := s.Scope(ctx, quota)
+	if err != nil {
+		return nil, err
+	}
+	if scope == nil {
+		return &models.Usage{}, nil
+	}
+	return s.quotaMgr.GetUsage(ctx, scope.GroupIDs, scope.Environment, excludedClusterID)
+}
+
+func (s *service) Scope(ctx context.Context, quota *models.Quota) (*Scope, error) {
+	if quota.ResourceType == models.ResourceTypeEnvironment {
+		env, err := s.envMgr.GetByID(ctx, quota.ResourceID)
+		if err != nil {
+			return nil, err
+		}
+		return &Scope{Environment: env.Name}, nil
+	}
+
+	groups, err := s.groupMgr.GetSubGroupsByGroupIDs(ctx, []uint{quota.ResourceID})
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		// the group has been deleted
+		return nil, nil
+	}
+	groupIDs := make([]uint, 0, len(groups))
+	for _, group := range groups {
+		groupIDs = append(groupIDs, group.ID)
+	}
+	return &Scope{GroupIDs: groupIDs, Environment: quota.Environment}, nil
+}
@@ -0,0 +1,254 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/admission"
+	admissionmodels "github.com/horizoncd/horizon/pkg/admission/models"
+	applicationgitrepo "github.com/horizoncd/horizon/pkg/application/gitrepo"
+	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	quotaconfig "github.com/horizoncd/horizon/pkg/config/quota"
+	hctx "github.com/horizoncd/horizon/pkg/context"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/quota/clusterusage"
+	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/util/mergemap"
+)
+
+// clusterRequest contains fields of cluster creation and update requests of api v1 and v2 which affect quotas
+type clusterRequest struct {
+	Name        string  `json:"name"`
+	Environment *string `json:"environment"`
+	// TemplateInfo and TemplateConfig are of api v2
+	TemplateInfo   *codemodels.TemplateInfo `json:"templateInfo"`
+	TemplateConfig map[string]interface{}   `json:"templateConfig"`
+	// Template and TemplateInput are of api v1
+	Template      *codemodels.TemplateInfo `json:"template"`
+	TemplateInput *struct {
+		Application map[string]interface{} `json:"application"`
+	} `json:"templateInput"`
+}
+
+func (r *clusterRequest) templateInfo() *codemodels.TemplateInfo {
+	if r.TemplateInfo != nil {
+		return r.TemplateInfo
+	}
+	return r.Template
+}
+
+func (r *clusterRequest) templateConfig() map[string]interface{} {
+	if r.TemplateConfig != nil {
+		return r.TemplateConfig
+	}
+	if r.TemplateInput != nil {
+		return r.TemplateInput.Application
+	}
+	return nil
+}
+
+// Webhook is a built-in validating admission webhook, which denies creating and updating clusters
+// beyond quotas of their groups and environments
+type Webhook struct {
+	config             quotaconfig.Config
+	calculator         *clusterusage.Calculator
+	enforcer           *clusterusage.Enforcer
+	applicationMgr     appmanager.Manager
+	clusterMgr         clustermanager.Manager
+	templateReleaseMgr trmanager.Manager
+	applicationGitRepo applicationgitrepo.ApplicationGitRepo
+}
+
+var _ admission.Webhook = (*Webhook)(nil)
+
+func NewWebhook(config quotaconfig.Config, param *param.Param, templateRepo templaterepo.TemplateRepo) *Webhook {
+	calculator := clusterusage.NewCalculator(templateRepo, param.ClusterGitRepo)
+	return &Webhook{
+		config:             config,
+		calculator:         calculator,
+		enforcer:           clusterusage.NewEnforcer(calculator, param.Manager),
+		applicationMgr:     param.ApplicationMgr,
+		clusterMgr:         param.ClusterMgr,
+		templateReleaseMgr: param.TemplateReleaseMgr,
+		applicationGitRepo: param.ApplicationGitRepo,
+	}
+}
+
+func (w *Webhook) IgnoreError() bool {
+	return w.config.IgnoreError
+}
+
+// Interest returns true for creating clusters of applications and updating clusters
+func (w *Webhook) Interest(req *admission.Request) bool {
+	switch {
+	case req.Operation.Eq(admissionmodels.OperationCreate):
+		return req.Resource == common.ResourceApplication && req.SubResource == common.ResourceCluster
+	case req.Operation.Eq(admissionmodels.OperationUpdate):
+		return req.Resource == common.ResourceCluster && req.SubResource == ""
+	}
+	return false
+}
+
+func (w *Webhook) Handle(ctx context.Context, req *admission.Request) (*admission.Response, error) {
+	var request clusterRequest
+	if req.Object != nil {
+		body, err := json.Marshal(req.Object)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(body, &request); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid cluster request: %v", err)
+		}
+	}
+	mergePatch, _ := strconv.ParseBool(option(req, common.ClusterQueryMergePatch))
+
+	var (
+		t   *clusterusage.Target
+		err error
+	)
+	if req.Operation.Eq(admissionmodels.OperationCreate) {
+		t, err = w.creationTarget(ctx, req, &request, mergePatch)
+	} else {
+		t, err = w.updateTarget(ctx, req, &request, mergePatch)
+	}
+	if err != nil {
+		return nil, err
+	}
+	reason := ""
+	if t != nil {
+		reason, err = w.enforcer.Check(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+	}
+	allowed := reason == ""
+	return &admission.Response{Allowed: &allowed, Result: reason}, nil
+}
+
+func (w *Webhook) creationTarget(ctx context.Context, req *admission.Request, request *clusterRequest,
+	mergePatch bool) (*clusterusage.Target, error) {
+	applicationID, err := strconv.ParseUint(req.Name, 10, 0)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid application id: %s", req.Name)
+	}
+	application, err := w.applicationMgr.GetByID(ctx, uint(applicationID))
+	if err != nil {
+		return nil, err
+	}
+	scope, ok := hctx.ScopeFromContext(ctx)
+	if !ok {
+		scope = option(req, hctx.ParamScope)
+	}
+	scopeArray := strings.Split(scope, "/")
+	if len(scopeArray) != 2 {
+		// invalid requests are rejected by the api
+		return nil, nil
+	}
+	environment, region := scopeArray[0], scopeArray[1]
+
+	resources := func() (*clusterusage.Resources, error) {
+		templateInfo := request.templateInfo()
+		if templateInfo == nil {
+			templateInfo = &codemodels.TemplateInfo{
+				Name:    application.Template,
+				Release: application.TemplateRelease,
+			}
+		}
+		templateRelease, err := w.templateReleaseMgr.GetByTemplateNameAndRelease(ctx,
+			templateInfo.Name, templateInfo.Release)
+		if err != nil {
+			return nil, err
+		}
+
+		// the same as creating clusters, config of the application is inherited if the template is not changed
+		templateConfig := request.templateConfig()
+		if templateInfo.Name == application.Template && (templateConfig == nil || mergePatch) {
+			applicationFile, err := w.applicationGitRepo.GetApplication(ctx, application.Name, environment)
+			if err != nil {
+				return nil, err
+			}
+			inherited, err := mergemap.Merge(make(map[string]interface{}), applicationFile.TemplateConf)
+			if err != nil {
+				return nil, err
+			}
+			if templateConfig, err = mergemap.Merge(inherited, templateConfig); err != nil {
+				return nil, err
+			}
+		}
+		values, err := clusterusage.CreationValues(application, templateRelease, environment, region, "",
+			request.Name, templateConfig)
+		if err != nil {
+			return nil, err
+		}
+		return w.calculator.TemplateResources(ctx, templateRelease, values, request.Name)
+	}
+	return &clusterusage.Target{
+		GroupID:     application.GroupID,
+		Environment: environment,
+		Cluster:     request.Name,
+		Counted:     true,
+		Resources:   resources,
+	}, nil
+}
+
+func (w *Webhook) updateTarget(ctx context.Context, req *admission.Request, request *clusterRequest,
+	mergePatch bool) (*clusterusage.Target, error) {
+	clusterID, err := strconv.ParseUint(req.Name, 10, 0)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid cluster id: %s", req.Name)
+	}
+	cluster, err := w.clusterMgr.GetByID(ctx, uint(clusterID))
+	if err != nil {
+		return nil, err
+	}
+	application, err := w.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &clusterusage.ClusterValues{
+		TemplateConfig: request.templateConfig(),
+		MergePatch:     mergePatch,
+	}
+	if templateInfo := request.templateInfo(); templateInfo != nil &&
+		(templateInfo.Name != cluster.Template || templateInfo.Release != cluster.TemplateRelease) {
+		changes.TemplateRelease, err = w.templateReleaseMgr.GetByTemplateNameAndRelease(ctx,
+			templateInfo.Name, templateInfo.Release)
+		if err != nil {
+			return nil, err
+		}
+	}
+	environment := ""
+	if request.Environment != nil {
+		environment = *request.Environment
+	}
+	return w.enforcer.UpdateTarget(ctx, application, cluster, environment, changes)
+}
+
+// option returns the query of request, which is empty if not set or set multiple times
+func option(req *admission.Request, key string) string {
+	value, _ := req.Options[key].(string)
+	return value
+}
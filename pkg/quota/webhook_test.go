@@ -0,0 +1,208 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	applicationgitrepomock "github.com/horizoncd/horizon/mock/pkg/application/gitrepo"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	templaterepomock "github.com/horizoncd/horizon/mock/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/admission"
+	admissionmodels "github.com/horizoncd/horizon/pkg/admission/models"
+	applicationgitrepo "github.com/horizoncd/horizon/pkg/application/gitrepo"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	quotaconfig "github.com/horizoncd/horizon/pkg/config/quota"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/quota/models"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+)
+
+const _deploymentTemplate = `apiVersion: apps/v1
+kind: Deployment
+spec:
+  replicas: {{ .Values.app.spec.replicas }}
+  template:
+    spec:
+      containers:
+        - name: app
+          resources:
+            requests:
+              cpu: 500m
+`
+
+func testChart() *chart.Chart {
+	return &chart.Chart{
+		Metadata:  &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "javaapp", Version: "v1.0.0"},
+		Templates: []*chart.File{{Name: "templates/deployment.yaml", Data: []byte(_deploymentTemplate)}},
+		Values: map[string]interface{}{
+			"app": map[string]interface{}{"spec": map[string]interface{}{"replicas": 1}},
+		},
+	}
+}
+
+func appConfig(replicas int) map[string]interface{} {
+	return map[string]interface{}{
+		"app": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replicas": replicas,
+			},
+		},
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	ctx := context.TODO()
+	db, _ := orm.NewTestDB()
+	assert.Nil(t, db.AutoMigrate(&groupmodels.Group{}, &appmodels.Application{}, &clustermodels.Cluster{},
+		&envmodels.Environment{}, &trmodels.TemplateRelease{}, &models.Quota{}, &models.ClusterUsage{},
+		&models.QuotaReservation{}))
+	manager := managerparam.InitManager(db)
+
+	assert.Nil(t, db.Create(&groupmodels.Group{Name: "a", Path: "a", TraversalIDs: "1"}).Error)
+	assert.Nil(t, db.Create(&groupmodels.Group{Name: "b", Path: "b", ParentID: 1, TraversalIDs: "1,2"}).Error)
+	env := &envmodels.Environment{Name: "online"}
+	assert.Nil(t, db.Create(env).Error)
+	assert.Nil(t, db.Create(&trmodels.TemplateRelease{TemplateName: "javaapp", ChartName: "javaapp",
+		Name: "v1.0.0", ChartVersion: "v1.0.0-abc"}).Error)
+	app := &appmodels.Application{Name: "app", GroupID: 2, Template: "javaapp", TemplateRelease: "v1.0.0"}
+	assert.Nil(t, db.Create(app).Error)
+	cluster1 := &clustermodels.Cluster{Name: "cluster1", ApplicationID: app.ID, EnvironmentName: "online",
+		Template: "javaapp", TemplateRelease: "v1.0.0"}
+	assert.Nil(t, db.Create(cluster1).Error)
+	assert.Nil(t, manager.QuotaMgr.UpsertClusterUsage(ctx, &models.ClusterUsage{
+		ClusterID: cluster1.ID, CPU: 1000, Memory: 2 << 30, Replicas: 2}))
+
+	// quotas of ancestors and the environment are applied
+	_, err := manager.QuotaMgr.Create(ctx, &models.Quota{
+		ResourceType: models.ResourceTypeGroup, ResourceID: 1, MaxClusters: 2})
+	assert.Nil(t, err)
+	envQuota, err := manager.QuotaMgr.Create(ctx, &models.Quota{
+		ResourceType: models.ResourceTypeEnvironment, ResourceID: env.ID, MaxCPU: "2"})
+	assert.Nil(t, err)
+	// quotas of other environments are not applied
+	_, err = manager.QuotaMgr.Create(ctx, &models.Quota{
+		ResourceType: models.ResourceTypeGroup, ResourceID: 2, Environment: "test", MaxClusters: 1})
+	assert.Nil(t, err)
+
+	mockCtl := gomock.NewController(t)
+	templateRepo := templaterepomock.NewMockTemplateRepo(mockCtl)
+	templateRepo.EXPECT().GetChart("javaapp", "v1.0.0-abc", gomock.Any()).Return(testChart(), nil).AnyTimes()
+	applicationGitRepo := applicationgitrepomock.NewMockApplicationGitRepo2(mockCtl)
+	applicationGitRepo.EXPECT().GetApplication(gomock.Any(), "app", "online").
+		Return(&applicationgitrepo.GetResponse{TemplateConf: appConfig(1)}, nil).AnyTimes()
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	clusterGitRepo.EXPECT().DefaultBranch().Return("master").AnyTimes()
+	clusterGitRepo.EXPECT().GetChartFiles(gomock.Any(), "app", "cluster1", "master").Return(&gitrepo.ChartFiles{
+		Chart: &gitrepo.Chart{Dependencies: []gitrepo.Dependency{{Name: "javaapp", Version: "v1.0.0-abc"}}},
+		ValueFiles: []gitrepo.ChartValueFile{
+			{FileName: common.GitopsFileEnv, Content: []byte("javaapp:\n  env:\n    namespace: online-2\n")},
+			{FileName: common.GitopsFileApplication, Content: []byte("javaapp:\n  app:\n    spec:\n      replicas: 2\n")},
+		},
+	}, nil).AnyTimes()
+
+	webhook := NewWebhook(quotaconfig.Config{Enabled: true}, &param.Param{
+		Manager:            manager,
+		ApplicationGitRepo: applicationGitRepo,
+		ClusterGitRepo:     clusterGitRepo,
+	}, templateRepo)
+
+	createRequest := func(object map[string]interface{}) *admission.Request {
+		return &admission.Request{
+			Operation:   admissionmodels.OperationCreate,
+			Resource:    common.ResourceApplication,
+			Name:        "1",
+			SubResource: common.ResourceCluster,
+			Object:      object,
+			Options:     map[string]interface{}{"scope": "online/hz"},
+		}
+	}
+	updateRequest := func(object map[string]interface{}) *admission.Request {
+		return &admission.Request{
+			Operation: admissionmodels.OperationUpdate,
+			Resource:  common.ResourceCluster,
+			Name:      "1",
+			Object:    object,
+		}
+	}
+	assert.True(t, webhook.Interest(createRequest(nil)))
+	assert.True(t, webhook.Interest(updateRequest(nil)))
+	assert.False(t, webhook.Interest(&admission.Request{Operation: admissionmodels.OperationCreate,
+		Resource: common.ResourceCluster, Name: "1", SubResource: "builddeploy"}))
+
+	// config of the application is inherited, 1000m + 500m <= 2
+	resp, err := webhook.Handle(ctx, createRequest(map[string]interface{}{"name": "cluster2"}))
+	assert.Nil(t, err)
+	assert.True(t, *resp.Allowed)
+
+	// 1000m + 3 * 500m > 2
+	resp, err = webhook.Handle(ctx, createRequest(map[string]interface{}{
+		"name":           "cluster2",
+		"templateConfig": appConfig(3),
+	}))
+	assert.Nil(t, err)
+	assert.False(t, *resp.Allowed)
+	assert.Contains(t, resp.Result, "cpu requests 2500m exceed 2")
+
+	// cluster2 is reserved until it's written
+	resp, err = webhook.Handle(ctx, createRequest(map[string]interface{}{
+		"name":           "cluster4",
+		"templateConfig": appConfig(1),
+	}))
+	assert.Nil(t, err)
+	assert.False(t, *resp.Allowed)
+	assert.Contains(t, resp.Result, "number of clusters exceeds 2")
+
+	// the cluster controller releases the reservation after cluster2 is written
+	cluster2 := &clustermodels.Cluster{Name: "cluster2", ApplicationID: app.ID, EnvironmentName: "online"}
+	assert.Nil(t, db.Create(cluster2).Error)
+	assert.Nil(t, manager.QuotaMgr.Release(ctx, "cluster2"))
+	resp, err = webhook.Handle(ctx, createRequest(map[string]interface{}{"name": "cluster3"}))
+	assert.Nil(t, err)
+	assert.False(t, *resp.Allowed)
+	assert.Contains(t, resp.Result, "number of clusters exceeds 2")
+
+	// updates without changing values are not checked
+	resp, err = webhook.Handle(ctx, updateRequest(map[string]interface{}{"description": "cluster1"}))
+	assert.Nil(t, err)
+	assert.True(t, *resp.Allowed)
+
+	// 4 * 500m <= 2
+	resp, err = webhook.Handle(ctx, updateRequest(map[string]interface{}{"templateConfig": appConfig(4)}))
+	assert.Nil(t, err)
+	assert.True(t, *resp.Allowed)
+	resp, err = webhook.Handle(ctx, updateRequest(map[string]interface{}{"templateConfig": appConfig(5)}))
+	assert.Nil(t, err)
+	assert.False(t, *resp.Allowed)
+
+	// decreasing resources is allowed even if the quota is exceeded
+	envQuota.MaxCPU = "100m"
+	assert.Nil(t, manager.QuotaMgr.Update(ctx, envQuota))
+	resp, err = webhook.Handle(ctx, updateRequest(map[string]interface{}{"templateConfig": appConfig(1)}))
+	assert.Nil(t, err)
+	assert.True(t, *resp.Allowed)
+}
@@ -17,14 +17,23 @@ package rbac
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	membermanager "github.com/horizoncd/horizon/pkg/member/manager"
 	"github.com/horizoncd/horizon/pkg/member/models"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
 	"github.com/horizoncd/horizon/pkg/rbac/types"
+	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
 	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/visibility"
 )
 
 // Authorizer use the basic rbac rules to check if the user
@@ -35,31 +44,53 @@ type Authorizer interface {
 
 type VisitorFunc func(fmt.Stringer, *types.PolicyRule, error) bool
 
-func NewAuthorizer(roleservice role.Service, memberservice memberservice.Service) Authorizer {
+func NewAuthorizer(roleservice role.Service, memberservice memberservice.Service,
+	manager *managerparam.Manager) Authorizer {
 	return &authorizer{
-		roleService:   roleservice,
-		memberService: memberservice,
+		roleService:       roleservice,
+		memberService:     memberservice,
+		memberManager:     manager.MemberMgr,
+		tokenManager:      manager.TokenMgr,
+		visibilityChecker: visibility.NewChecker(manager),
 	}
 }
 
 type authorizer struct {
-	roleService   role.Service
-	memberService memberservice.Service
+	roleService       role.Service
+	memberService     memberservice.Service
+	memberManager     membermanager.Manager
+	tokenManager      tokenmanager.Manager
+	visibilityChecker visibility.Checker
 }
 
 const (
-	NotChecked        = "not checked"
 	ResourceFormatErr = "format error"
 	AnonymousUser     = "anonymous user"
 	InternalError     = "internal error"
 	MemberNotExist    = "member not exist"
+	PrivateResource   = "private resource is only accessible to members"
 	RoleNotExist      = "role not exist"
 	AdminAllow        = "admin allows everything"
+	SelfAllow         = "user allows to access itself"
+	TokenOwnerAllow   = "token owner allows to access its token"
+	TokenOwnerDeny    = "token is owned by others"
+	TokenNotExist     = "token not exist"
+)
+
+const (
+	_resourceEnvironments         = "environments"
+	_resourceUsers                = "users"
+	_resourcePersonalAccessTokens = "personalaccesstokens"
+	_resourceAccessTokens         = "accesstokens"
+	_resourceTeams                = "teams"
+	_resourceRoles                = "roles"
+
+	_userSelf = "self"
 )
 
 func (a *authorizer) Authorize(ctx context.Context, attr auth.Attributes) (auth.Decision,
 	string, error) {
-	// 0. check (admin allows everything, and some are not checked)
+	// 0. check (admin allows everything)
 	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
 		return auth.DecisionDeny, AnonymousUser, nil
@@ -68,16 +99,36 @@ func (a *authorizer) Authorize(ctx context.Context, attr auth.Attributes) (auth.
 		return auth.DecisionAllow, AdminAllow, nil
 	}
 
-	// TODO(tom): members, users, accesstokens and environments need to add to auth check
-	if attr.IsResourceRequest() && (attr.GetResource() == "members" ||
-		attr.GetResource() == "environments" || attr.GetResource() == "users" ||
-		attr.GetResource() == "personalaccesstokens" ||
-		(attr.GetResource() == "accesstokens" && attr.GetVerb() == "delete")) {
-		log.Warning(ctx,
-			"members|environments|access tokens are not authed yet")
-		return auth.DecisionAllow, NotChecked, nil
+	// resources which are not bound to members directly
+	if attr.IsResourceRequest() {
+		switch attr.GetResource() {
+		case common.ResourceMember:
+			return a.authorizeMember(ctx, attr)
+		case _resourceAccessTokens:
+			if attr.GetName() != "" {
+				return a.authorizeAccessToken(ctx, currentUser, attr)
+			}
+		case _resourcePersonalAccessTokens:
+			if attr.GetName() != "" {
+				return a.authorizePersonalAccessToken(ctx, currentUser, attr)
+			}
+			return a.authorizeByDefaultRole(ctx, attr)
+		case _resourceUsers:
+			if attr.GetName() == _userSelf || attr.GetName() == strconv.Itoa(int(currentUser.GetID())) {
+				return auth.DecisionAllow, SelfAllow, nil
+			}
+			return a.authorizeByDefaultRole(ctx, attr)
+		case _resourceEnvironments, _resourceTeams, _resourceRoles:
+			return a.authorizeByDefaultRole(ctx, attr)
+		}
 	}
 
+	return a.authorizeResource(ctx, attr)
+}
+
+// authorizeResource checks the attributes with the role the user plays on the resource
+func (a *authorizer) authorizeResource(ctx context.Context, attr auth.Attributes) (auth.Decision,
+	string, error) {
 	// 1. get the member
 	resourceIDStr := attr.GetName()
 	member, err := a.memberService.GetMemberOfResource(ctx, attr.GetResource(), resourceIDStr)
@@ -90,8 +141,17 @@ func (a *authorizer) Authorize(ctx context.Context, attr auth.Attributes) (auth.
 	// 2. get the role
 	var role *types.Role
 	if member == nil {
-		// TODO(tom): non public resources
-		// now default resource are public, if there is a default role, non member can choose the default role
+		// non members play the default role on public resources only
+		resourceID, _ := strconv.ParseUint(resourceIDStr, 10, 0)
+		private, err := a.visibilityChecker.IsPrivate(ctx, attr.GetResource(), uint(resourceID))
+		if err != nil {
+			return auth.DecisionDeny, InternalError, err
+		}
+		if private {
+			log.Warningf(ctx, "user %s is not a member of private resourceType = %s, resourceID = %s",
+				attr.GetUser().String(), attr.GetResource(), attr.GetName())
+			return auth.DecisionDeny, PrivateResource, nil
+		}
 		defaultRole := a.roleService.GetDefaultRole(ctx)
 		if defaultRole == nil {
 			log.Warningf(ctx, " user %s member and role not found of resourceType = %s, resourceID = %s",
@@ -117,6 +177,116 @@ func (a *authorizer) Authorize(ctx context.Context, attr auth.Attributes) (auth.
 	return VisitRoles(member, role, attr)
 }
 
+// authorizeByDefaultRole checks global resources, such as environments and users,
+// which everyone plays the default role on.
+func (a *authorizer) authorizeByDefaultRole(ctx context.Context, attr auth.Attributes) (auth.Decision,
+	string, error) {
+	defaultRole := a.roleService.GetDefaultRole(ctx)
+	if defaultRole == nil {
+		return auth.DecisionDeny, RoleNotExist, nil
+	}
+	return VisitRoles(nil, defaultRole, attr)
+}
+
+// authorizeMember checks the request to a member, such as /members/:memberID,
+// with the rules of <resourceType>/members of the resource the member belongs to.
+func (a *authorizer) authorizeMember(ctx context.Context, attr auth.Attributes) (auth.Decision,
+	string, error) {
+	memberID, err := strconv.ParseUint(attr.GetName(), 10, 0)
+	if err != nil {
+		return auth.DecisionDeny, ResourceFormatErr, nil
+	}
+	member, err := a.memberService.GetMember(ctx, uint(memberID))
+	if err != nil {
+		return auth.DecisionDeny, InternalError, err
+	}
+	if member == nil {
+		return auth.DecisionDeny, MemberNotExist, nil
+	}
+	return a.authorizeResource(ctx,
+		subResourceAttributes(attr, string(member.ResourceType), member.ResourceID, common.ResourceMember))
+}
+
+// authorizePersonalAccessToken only allows the owner of a personal access token to access it
+func (a *authorizer) authorizePersonalAccessToken(ctx context.Context, currentUser userauth.User,
+	attr auth.Attributes) (auth.Decision, string, error) {
+	token, reason, err := a.loadToken(ctx, attr.GetName())
+	if token == nil {
+		return auth.DecisionDeny, reason, err
+	}
+	if token.UserID != currentUser.GetID() {
+		return auth.DecisionDeny, TokenOwnerDeny, nil
+	}
+	return auth.DecisionAllow, TokenOwnerAllow, nil
+}
+
+// authorizeAccessToken checks the request to a resource access token, such as /accesstokens/:id,
+// with the rules of <resourceType>/accesstokens of the resources the token is bound to.
+// The creator of the token is always allowed.
+func (a *authorizer) authorizeAccessToken(ctx context.Context, currentUser userauth.User,
+	attr auth.Attributes) (auth.Decision, string, error) {
+	token, reason, err := a.loadToken(ctx, attr.GetName())
+	if token == nil {
+		return auth.DecisionDeny, reason, err
+	}
+	if token.CreatedBy == currentUser.GetID() {
+		return auth.DecisionAllow, TokenOwnerAllow, nil
+	}
+
+	// the robot user of a resource token is a member of the resource
+	members, err := a.memberManager.ListMembersByUserID(ctx, token.UserID)
+	if err != nil {
+		return auth.DecisionDeny, InternalError, err
+	}
+	if len(members) == 0 {
+		return auth.DecisionDeny, MemberNotExist, nil
+	}
+	var decision auth.Decision
+	for _, member := range members {
+		decision, reason, err = a.authorizeResource(ctx,
+			subResourceAttributes(attr, string(member.ResourceType), member.ResourceID, _resourceAccessTokens))
+		if err != nil || decision != auth.DecisionAllow {
+			return decision, reason, err
+		}
+	}
+	return decision, reason, nil
+}
+
+// loadToken loads the token of the request, the reason of the denial is returned if the token is not loaded.
+// A missing token keeps the not found error, so that the request is answered with 404 instead of 500.
+func (a *authorizer) loadToken(ctx context.Context, idStr string) (*tokenmodels.Token, string, error) {
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		return nil, ResourceFormatErr, nil
+	}
+	token, err := a.tokenManager.LoadTokenByID(ctx, uint(id))
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil, TokenNotExist, err
+		}
+		return nil, InternalError, err
+	}
+	return token, "", nil
+}
+
+// subResourceAttributes rewrites attributes to the subresource of the resource,
+// e.g. /members/1 to /groups/2/members
+func subResourceAttributes(attr auth.Attributes, resource string,
+	resourceID uint, subResource string) auth.Attributes {
+	return auth.AttributesRecord{
+		User:            attr.GetUser(),
+		Verb:            attr.GetVerb(),
+		APIGroup:        attr.GetAPIGroup(),
+		APIVersion:      attr.GetAPIVersion(),
+		Resource:        resource,
+		SubResource:     subResource,
+		Name:            strconv.FormatUint(uint64(resourceID), 10),
+		Scope:           attr.GetScope(),
+		ResourceRequest: true,
+		Path:            attr.GetPath(),
+	}
+}
+
 func VisitRoles(member *models.Member, role *types.Role,
 	attr auth.Attributes) (_ auth.Decision, reason string, err error) {
 	var memberInfo string
@@ -18,17 +18,23 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	managermock "github.com/horizoncd/horizon/mock/pkg/member/manager"
 	servicemock "github.com/horizoncd/horizon/mock/pkg/member/service"
 	rolemock "github.com/horizoncd/horizon/mock/pkg/rbac/role"
+	visibilitymock "github.com/horizoncd/horizon/mock/pkg/visibility"
 	"github.com/horizoncd/horizon/pkg/auth"
 	"github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/member/models"
 	"github.com/horizoncd/horizon/pkg/rbac/types"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
 )
 
 // members and pipelineruns are allowed
@@ -46,9 +52,11 @@ func TestAuthMember(t *testing.T) {
 	mockCtl := gomock.NewController(t)
 	memberServiceMock := servicemock.NewMockService(mockCtl)
 	roleServiceMock := rolemock.NewMockService(mockCtl)
+	visibilityCheckerMock := visibilitymock.NewMockChecker(mockCtl)
 	testAuthorizer := Authorizer(&authorizer{
-		roleService:   roleServiceMock,
-		memberService: memberServiceMock,
+		roleService:       roleServiceMock,
+		memberService:     memberServiceMock,
+		visibilityChecker: visibilityCheckerMock,
 	})
 
 	authRecord := auth.AttributesRecord{
@@ -57,15 +65,52 @@ func TestAuthMember(t *testing.T) {
 		APIGroup:        "/apis/core",
 		APIVersion:      "v1",
 		Resource:        "members",
+		Name:            "1",
 		ResourceRequest: true,
 		Path:            "",
 	}
 
 	ctx = context.WithValue(ctx, common.UserContextKey(), defaultUser)
+	// members are checked by the rules of the resource they belong to
+	memberServiceMock.EXPECT().GetMember(ctx, uint(1)).Return(&models.Member{
+		ResourceType: models.TypeGroup,
+		ResourceID:   2,
+	}, nil).Times(2)
+	memberServiceMock.EXPECT().GetMemberOfResource(ctx, "groups", "2").Return(&models.Member{
+		Role: "maintainer",
+	}, nil).Times(2)
+	roleServiceMock.EXPECT().GetRole(ctx, "maintainer").Return(&types.Role{
+		Name: "maintainer",
+		PolicyRules: []types.PolicyRule{{
+			Verbs:     []string{"get", "update"},
+			APIGroups: []string{"*"},
+			Resources: []string{"groups/members"},
+			Scopes:    []string{"*"},
+		}},
+	}, nil).Times(1)
 	decision, reason, err := testAuthorizer.Authorize(ctx, authRecord)
 	assert.Nil(t, err)
+	assert.Equal(t, auth.DecisionDeny, decision)
+
+	roleServiceMock.EXPECT().GetRole(ctx, "maintainer").Return(&types.Role{
+		Name: "maintainer",
+		PolicyRules: []types.PolicyRule{{
+			Verbs:     []string{"delete"},
+			APIGroups: []string{"*"},
+			Resources: []string{"groups/members"},
+			Scopes:    []string{"*"},
+		}},
+	}, nil).Times(1)
+	decision, _, err = testAuthorizer.Authorize(ctx, authRecord)
+	assert.Nil(t, err)
 	assert.Equal(t, auth.DecisionAllow, decision)
-	assert.Equal(t, NotChecked, reason)
+
+	// member id is invalid
+	authRecord.Name = "abc"
+	decision, reason, err = testAuthorizer.Authorize(ctx, authRecord)
+	assert.Nil(t, err)
+	assert.Equal(t, auth.DecisionDeny, decision)
+	assert.Equal(t, ResourceFormatErr, reason)
 
 	authRecord = auth.AttributesRecord{
 		User:            defaultUser,
@@ -88,11 +133,22 @@ func TestAuthMember(t *testing.T) {
 	// member not exist
 	memberServiceMock.EXPECT().GetMemberOfResource(ctx, gomock.Any(),
 		gomock.Any()).Return(nil, nil).Times(1)
+	visibilityCheckerMock.EXPECT().IsPrivate(ctx, "groups", uint(123)).Return(false, nil).Times(1)
 	roleServiceMock.EXPECT().GetDefaultRole(ctx).Return(nil).Times(1)
 	decision, reason, err = testAuthorizer.Authorize(ctx, authRecord)
 	assert.Equal(t, auth.DecisionDeny, decision)
 	assert.Equal(t, MemberNotExist, reason)
 	assert.Nil(t, err)
+
+	// non members never play the default role on private resources
+	authRecord.Verb = "get"
+	memberServiceMock.EXPECT().GetMemberOfResource(ctx, gomock.Any(),
+		gomock.Any()).Return(nil, nil).Times(1)
+	visibilityCheckerMock.EXPECT().IsPrivate(ctx, "groups", uint(123)).Return(true, nil).Times(1)
+	decision, reason, err = testAuthorizer.Authorize(ctx, authRecord)
+	assert.Equal(t, auth.DecisionDeny, decision)
+	assert.Equal(t, PrivateResource, reason)
+	assert.Nil(t, err)
 }
 
 // nolint
@@ -146,3 +202,210 @@ func TestAuthRole(t *testing.T) {
 	assert.Equal(t, auth.DecisionDeny, decision)
 	assert.Nil(t, err)
 }
+
+type fakeTokenManager struct {
+	tokens map[uint]*tokenmodels.Token
+}
+
+func (f *fakeTokenManager) CreateToken(context.Context, *tokenmodels.Token) (*tokenmodels.Token, error) {
+	return nil, nil
+}
+
+func (f *fakeTokenManager) LoadTokenByID(_ context.Context, id uint) (*tokenmodels.Token, error) {
+	token, ok := f.tokens[id]
+	if !ok {
+		return nil, herrors.NewErrNotFound(herrors.TokenInDB, "not found")
+	}
+	return token, nil
+}
+
+func (f *fakeTokenManager) LoadTokenByCode(context.Context, string) (*tokenmodels.Token, error) {
+	return nil, nil
+}
+
+func (f *fakeTokenManager) RevokeTokenByID(context.Context, uint) error {
+	return nil
+}
+
+func (f *fakeTokenManager) RevokeTokenByClientID(context.Context, string) error {
+	return nil
+}
+
+func (f *fakeTokenManager) UpdateLastUsed(context.Context, uint, time.Time, string) error {
+	return nil
+}
+
+func (f *fakeTokenManager) UpdateExpiryNotifiedAt(context.Context, uint, time.Time) error {
+	return nil
+}
+
+func (f *fakeTokenManager) ListExpirableAccessTokens(context.Context, uint, int) ([]*tokenmodels.Token, error) {
+	return nil, nil
+}
+
+var guestRole = &types.Role{
+	Name: "guest",
+	PolicyRules: []types.PolicyRule{
+		{
+			Verbs:     []string{"get"},
+			APIGroups: []string{"*"},
+			Resources: []string{"environments", "users", "groups/accesstokens", "teams", "teams/members", "roles"},
+			Scopes:    []string{"*"},
+		},
+		{
+			Verbs:     []string{"create"},
+			APIGroups: []string{"*"},
+			Resources: []string{"personalaccesstokens"},
+			Scopes:    []string{"*"},
+		},
+	},
+}
+
+// nolint
+func TestAuthGlobalResources(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	roleServiceMock := rolemock.NewMockService(mockCtl)
+	testAuthorizer := Authorizer(&authorizer{
+		roleService:   roleServiceMock,
+		memberService: servicemock.NewMockService(mockCtl),
+	})
+	roleServiceMock.EXPECT().GetDefaultRole(gomock.Any()).Return(guestRole).AnyTimes()
+
+	record := func(verb, resource, name, subResource string) auth.AttributesRecord {
+		return auth.AttributesRecord{
+			User:            defaultUser,
+			Verb:            verb,
+			APIGroup:        "core",
+			APIVersion:      "v2",
+			Resource:        resource,
+			SubResource:     subResource,
+			Name:            name,
+			ResourceRequest: true,
+		}
+	}
+
+	cases := []struct {
+		attr     auth.AttributesRecord
+		decision auth.Decision
+	}{
+		{record("get", "environments", "", ""), auth.DecisionAllow},
+		{record("get", "environments", "dev", ""), auth.DecisionAllow},
+		{record("create", "environments", "", ""), auth.DecisionDeny},
+		{record("update", "environments", "dev", ""), auth.DecisionDeny},
+		{record("delete", "environments", "dev", ""), auth.DecisionDeny},
+		{record("get", "users", "", ""), auth.DecisionAllow},
+		{record("get", "users", "2", ""), auth.DecisionAllow},
+		{record("get", "users", "2", "links"), auth.DecisionDeny},
+		{record("update", "users", "2", ""), auth.DecisionDeny},
+		{record("get", "users", "1", "links"), auth.DecisionAllow},
+		{record("delete", "users", "self", "mfa"), auth.DecisionAllow},
+		{record("create", "personalaccesstokens", "", ""), auth.DecisionAllow},
+		{record("get", "teams", "1", "members"), auth.DecisionAllow},
+		{record("create", "teams", "", ""), auth.DecisionDeny},
+		{record("create", "teams", "1", "members"), auth.DecisionDeny},
+		{record("delete", "teams", "1", "members"), auth.DecisionDeny},
+		{record("get", "roles", "deployer", ""), auth.DecisionAllow},
+		{record("create", "roles", "", ""), auth.DecisionDeny},
+		{record("update", "roles", "deployer", ""), auth.DecisionDeny},
+	}
+	for _, c := range cases {
+		decision, reason, err := testAuthorizer.Authorize(ctx, c.attr)
+		assert.Nil(t, err)
+		assert.Equal(t, c.decision, decision, "%+v: %s", c.attr, reason)
+	}
+}
+
+// nolint
+func TestAuthAccessTokens(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	memberServiceMock := servicemock.NewMockService(mockCtl)
+	memberManagerMock := managermock.NewMockManager(mockCtl)
+	roleServiceMock := rolemock.NewMockService(mockCtl)
+	testAuthorizer := Authorizer(&authorizer{
+		roleService:   roleServiceMock,
+		memberService: memberServiceMock,
+		memberManager: memberManagerMock,
+		tokenManager: &fakeTokenManager{tokens: map[uint]*tokenmodels.Token{
+			// personal access token of current user
+			1: {ID: 1, UserID: 1, CreatedBy: 1},
+			// personal access token of others
+			2: {ID: 2, UserID: 2, CreatedBy: 2},
+			// resource access token created by others, robot user 3
+			3: {ID: 3, UserID: 3, CreatedBy: 2},
+			// resource access token created by current user, robot user 4
+			4: {ID: 4, UserID: 4, CreatedBy: 1},
+		}},
+	})
+
+	record := func(resource, name string) auth.AttributesRecord {
+		return auth.AttributesRecord{
+			User:            defaultUser,
+			Verb:            "delete",
+			APIGroup:        "core",
+			APIVersion:      "v2",
+			Resource:        resource,
+			Name:            name,
+			ResourceRequest: true,
+		}
+	}
+
+	// personal access tokens could only be deleted by its owner
+	decision, reason, err := testAuthorizer.Authorize(ctx, record("personalaccesstokens", "1"))
+	assert.Nil(t, err)
+	assert.Equal(t, auth.DecisionAllow, decision)
+	assert.Equal(t, TokenOwnerAllow, reason)
+
+	decision, reason, err = testAuthorizer.Authorize(ctx, record("personalaccesstokens", "2"))
+	assert.Nil(t, err)
+	assert.Equal(t, auth.DecisionDeny, decision)
+	assert.Equal(t, TokenOwnerDeny, reason)
+
+	// a missing token is reported as not found rather than an internal error
+	decision, reason, err = testAuthorizer.Authorize(ctx, record("personalaccesstokens", "5"))
+	assert.Equal(t, auth.DecisionDeny, decision)
+	assert.Equal(t, TokenNotExist, reason)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	decision, reason, err = testAuthorizer.Authorize(ctx, record("accesstokens", "5"))
+	assert.Equal(t, auth.DecisionDeny, decision)
+	assert.Equal(t, TokenNotExist, reason)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	// resource access tokens could be deleted by its creator
+	decision, reason, err = testAuthorizer.Authorize(ctx, record("accesstokens", "4"))
+	assert.Nil(t, err)
+	assert.Equal(t, auth.DecisionAllow, decision)
+	assert.Equal(t, TokenOwnerAllow, reason)
+
+	// or by the members who are allowed to delete access tokens of the resource
+	memberManagerMock.EXPECT().ListMembersByUserID(ctx, uint(3)).Return([]models.Member{{
+		ResourceType: models.TypeGroup,
+		ResourceID:   2,
+		Role:         "owner",
+	}}, nil).Times(2)
+	memberServiceMock.EXPECT().GetMemberOfResource(ctx, "groups", "2").Return(&models.Member{
+		Role: "guest",
+	}, nil).Times(1)
+	roleServiceMock.EXPECT().GetRole(ctx, "guest").Return(guestRole, nil).Times(1)
+	decision, _, err = testAuthorizer.Authorize(ctx, record("accesstokens", "3"))
+	assert.Nil(t, err)
+	assert.Equal(t, auth.DecisionDeny, decision)
+
+	memberServiceMock.EXPECT().GetMemberOfResource(ctx, "groups", "2").Return(&models.Member{
+		Role: "owner",
+	}, nil).Times(1)
+	roleServiceMock.EXPECT().GetRole(ctx, "owner").Return(&types.Role{
+		Name: "owner",
+		PolicyRules: []types.PolicyRule{{
+			Verbs:     []string{"*"},
+			APIGroups: []string{"*"},
+			Resources: []string{"groups/accesstokens"},
+			Scopes:    []string{"*"},
+		}},
+	}, nil).Times(1)
+	decision, _, err = testAuthorizer.Authorize(ctx, record("accesstokens", "3"))
+	assert.Nil(t, err)
+	assert.Equal(t, auth.DecisionAllow, decision)
+}
@@ -0,0 +1,244 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	roleconfig "github.com/horizoncd/horizon/pkg/config/role"
+	customrolemanager "github.com/horizoncd/horizon/pkg/customrole/manager"
+	"github.com/horizoncd/horizon/pkg/customrole/models"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// CustomService serves the built-in roles from file together with the custom roles from db.
+// A custom role ranks below its base role and every built-in role whose rules it doesn't cover,
+// and custom roles are reloaded periodically, so changes made on any replica take effect on all replicas.
+type CustomService interface {
+	Service
+	// IsBuiltin returns whether the role is a built-in role from file, which is read-only
+	IsBuiltin(roleName string) bool
+	// Reload loads the custom roles from db immediately
+	Reload(ctx context.Context) error
+	// Sync reloads the custom roles every interval until ctx is done
+	Sync(ctx context.Context, interval time.Duration)
+}
+
+type customRoleService struct {
+	builtin *fileRoleService
+	manager customrolemanager.Manager
+	// snapshot stores *roleSnapshot, it's replaced as a whole on reloading
+	snapshot atomic.Value
+}
+
+type roleSnapshot struct {
+	roles []types.Role
+	// rank of built-in role is 2*i, where i is the index of the built-in role,
+	// and rank of custom role is 2*i+1, where i is the index of the lowest built-in role it doesn't cover
+	ranks map[string]int
+	index map[string]int
+	// baseRanks are the ranks of base roles of custom roles
+	baseRanks map[string]int
+}
+
+func NewCustomService(ctx context.Context, config roleconfig.Config,
+	manager customrolemanager.Manager) (CustomService, error) {
+	builtin, err := NewFileRoleFrom2(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	s := &customRoleService{
+		builtin: builtin.(*fileRoleService),
+		manager: manager,
+	}
+	s.snapshot.Store(s.buildSnapshot(ctx, nil))
+	if err := s.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ValidateCustomRole prevents privilege escalation: a custom role must not be allowed
+// to do anything which is not allowed by its base role
+func ValidateCustomRole(baseRole *types.Role, role *types.Role) error {
+	for i := range role.PolicyRules {
+		if permission, ok := types.Covers(baseRole.PolicyRules, &role.PolicyRules[i]); !ok {
+			return fmt.Errorf("rules[%d] of role %s allows %s, which is not allowed by base role %s",
+				i, role.Name, permission, baseRole.Name)
+		}
+	}
+	return nil
+}
+
+func (s *customRoleService) load() *roleSnapshot {
+	return s.snapshot.Load().(*roleSnapshot)
+}
+
+func (s *customRoleService) buildSnapshot(ctx context.Context, customRoles []*models.CustomRole) *roleSnapshot {
+	below := make(map[string][]*models.CustomRole)
+	for _, customRole := range customRoles {
+		if s.IsBuiltin(customRole.Name) {
+			log.Warningf(ctx, "custom role %s is ignored, which conflicts with built-in role", customRole.Name)
+			continue
+		}
+		if !s.IsBuiltin(customRole.BaseRole) {
+			log.Warningf(ctx, "custom role %s is ignored, base role %s is not found",
+				customRole.Name, customRole.BaseRole)
+			continue
+		}
+		// built-in roles may be changed in file after the custom role is created
+		baseRole, r := s.builtin.roleRankMap[customRole.BaseRole].role, customRole.ToRole()
+		if err := ValidateCustomRole(&baseRole, &r); err != nil {
+			log.Warningf(ctx, "custom role %s is ignored: %v", customRole.Name, err)
+			continue
+		}
+		lowest := s.lowestUncovered(customRole.BaseRole, &r)
+		below[lowest] = append(below[lowest], customRole)
+	}
+
+	snapshot := &roleSnapshot{
+		ranks:     make(map[string]int),
+		index:     make(map[string]int),
+		baseRanks: make(map[string]int),
+	}
+	for i, roleName := range s.builtin.RolePriorityRankDesc {
+		item := s.builtin.roleRankMap[roleName]
+		snapshot.index[roleName] = len(snapshot.roles)
+		snapshot.ranks[roleName] = 2 * i
+		snapshot.roles = append(snapshot.roles, item.role)
+		for _, customRole := range below[roleName] {
+			snapshot.index[customRole.Name] = len(snapshot.roles)
+			snapshot.ranks[customRole.Name] = 2*i + 1
+			snapshot.baseRanks[customRole.Name] = 2 * s.builtin.roleRankMap[customRole.BaseRole].rank
+			snapshot.roles = append(snapshot.roles, customRole.ToRole())
+		}
+	}
+	return snapshot
+}
+
+// lowestUncovered returns the lowest built-in role whose rules are not covered by the custom role,
+// which is the base role if the custom role covers all the built-in roles below its base role.
+// The custom role ranks just below it, so that it never ranks above a built-in role allowing more.
+func (s *customRoleService) lowestUncovered(baseRole string, role *types.Role) string {
+	names := s.builtin.RolePriorityRankDesc
+	for i := len(names) - 1; i > s.builtin.roleRankMap[baseRole].rank; i-- {
+		builtinRole := s.builtin.roleRankMap[names[i]].role
+		if !covers(role, &builtinRole) {
+			return names[i]
+		}
+	}
+	return baseRole
+}
+
+// covers returns whether role1 allows everything allowed by role2
+func covers(role1, role2 *types.Role) bool {
+	for i := range role2.PolicyRules {
+		if _, ok := types.Covers(role1.PolicyRules, &role2.PolicyRules[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *customRoleService) IsBuiltin(roleName string) bool {
+	_, ok := s.builtin.roleRankMap[roleName]
+	return ok
+}
+
+func (s *customRoleService) Reload(ctx context.Context) error {
+	customRoles, err := s.manager.List(ctx)
+	if err != nil {
+		return err
+	}
+	s.snapshot.Store(s.buildSnapshot(ctx, customRoles))
+	return nil
+}
+
+func (s *customRoleService) Sync(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Reload(ctx); err != nil {
+				log.Errorf(ctx, "failed to reload custom roles: %+v", err)
+			}
+		}
+	}
+}
+
+func (s *customRoleService) ListRole(ctx context.Context) ([]types.Role, error) {
+	snapshot := s.load()
+	roles := make([]types.Role, len(snapshot.roles))
+	copy(roles, snapshot.roles)
+	return roles, nil
+}
+
+func (s *customRoleService) GetRole(ctx context.Context, roleName string) (*types.Role, error) {
+	snapshot := s.load()
+	i, ok := snapshot.index[roleName]
+	if !ok {
+		return nil, ErrorRoleNotFound
+	}
+	role := snapshot.roles[i]
+	return &role, nil
+}
+
+func (s *customRoleService) GetDefaultRole(ctx context.Context) *types.Role {
+	return s.builtin.GetDefaultRole(ctx)
+}
+
+// RoleCompare compares roles by ranks, except that a role is smaller than a custom role unless
+// it's a built-in role not lower than the base role, or a custom role covering the rules of the custom role.
+// So a member can't grant roles allowing what its role doesn't allow.
+func (s *customRoleService) RoleCompare(ctx context.Context, role1, role2 string) (CompResult, error) {
+	snapshot := s.load()
+	rank1, ok1 := snapshot.ranks[role1]
+	rank2, ok2 := snapshot.ranks[role2]
+	if !ok1 || !ok2 {
+		log.Errorf(ctx, "role %s or %s cannot found", role1, role2)
+		return RoleCanNotCompare, ErrorRoleNotFound
+	}
+	if baseRank2, custom2 := snapshot.baseRanks[role2]; custom2 && role1 != role2 {
+		if _, custom1 := snapshot.baseRanks[role1]; !custom1 {
+			if rank1 <= baseRank2 {
+				return RoleBigger, nil
+			}
+			return RoleSmaller, nil
+		}
+		r1, r2 := snapshot.roles[snapshot.index[role1]], snapshot.roles[snapshot.index[role2]]
+		switch covers1, covers2 := covers(&r1, &r2), covers(&r2, &r1); {
+		case covers1 && covers2:
+			return RoleEqual, nil
+		case covers1:
+			return RoleBigger, nil
+		default:
+			return RoleSmaller, nil
+		}
+	}
+	if rank1 < rank2 {
+		return RoleBigger, nil
+	} else if rank1 > rank2 {
+		return RoleSmaller, nil
+	}
+	return RoleEqual, nil
+}
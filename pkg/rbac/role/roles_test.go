@@ -19,8 +19,12 @@ import (
 	"strings"
 	"testing"
 
+	roleconfig "github.com/horizoncd/horizon/pkg/config/role"
+	customrolemanager "github.com/horizoncd/horizon/pkg/customrole/manager"
+	"github.com/horizoncd/horizon/pkg/customrole/models"
 	"github.com/horizoncd/horizon/pkg/rbac/types"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
 )
 
 var (
@@ -230,3 +234,113 @@ func TestRole(t *testing.T) {
 
 	assert.NotNil(t, service.GetDefaultRole(ctx))
 }
+
+type fakeCustomRoleManager struct {
+	customrolemanager.Manager
+	roles []*models.CustomRole
+}
+
+func (m *fakeCustomRoleManager) List(ctx context.Context) ([]*models.CustomRole, error) {
+	return m.roles, nil
+}
+
+func TestCustomService(t *testing.T) {
+	var config roleconfig.Config
+	assert.Nil(t, yaml.Unmarshal([]byte(roleForTestOk), &config))
+	manager := &fakeCustomRoleManager{}
+	service, err := NewCustomService(ctx, config, manager)
+	assert.Nil(t, err)
+	assert.True(t, service.IsBuiltin("owner"))
+	assert.False(t, service.IsBuiltin("deployer"))
+
+	deployer := &models.CustomRole{
+		Name:     "deployer",
+		BaseRole: "maintainer",
+		Rules: []types.PolicyRule{{
+			Verbs:     []string{"get", "create"},
+			APIGroups: []string{"/api/core/v1/*"},
+			Resources: []string{"clusters/builddeploy", "clusters/deploy"},
+			Scopes:    []string{"online/*"},
+		}},
+	}
+	escalated := &models.CustomRole{
+		Name:     "escalated",
+		BaseRole: "maintainer",
+		Rules: []types.PolicyRule{{
+			Verbs:     []string{"delete"},
+			APIGroups: []string{"/api/core/v1/*"},
+			Resources: []string{"clusters"},
+			Scopes:    []string{"*"},
+		}},
+	}
+	baseRole, err := service.GetRole(ctx, "maintainer")
+	assert.Nil(t, err)
+	r := deployer.ToRole()
+	assert.Nil(t, ValidateCustomRole(baseRole, &r))
+	r = escalated.ToRole()
+	assert.NotNil(t, ValidateCustomRole(baseRole, &r))
+
+	// custom roles take effect after reloading, invalid ones are ignored
+	manager.roles = []*models.CustomRole{deployer, escalated,
+		{Name: "owner", BaseRole: "maintainer"}, {Name: "orphan", BaseRole: "notExist"}}
+	_, err = service.GetRole(ctx, "deployer")
+	assert.Equal(t, ErrorRoleNotFound, err)
+	assert.Nil(t, service.Reload(ctx))
+
+	roles, err := service.ListRole(ctx)
+	assert.Nil(t, err)
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	assert.Equal(t, []string{"owner", "maintainer", "deployer"}, names)
+
+	role, err := service.GetRole(ctx, "deployer")
+	assert.Nil(t, err)
+	assert.Equal(t, deployer.Rules[0], role.PolicyRules[0])
+	_, err = service.GetRole(ctx, "escalated")
+	assert.Equal(t, ErrorRoleNotFound, err)
+
+	// custom role ranks just below its base role
+	result, err := service.RoleCompare(ctx, "deployer", "maintainer")
+	assert.Nil(t, err)
+	assert.Equal(t, RoleSmaller, result)
+	result, err = service.RoleCompare(ctx, "owner", "deployer")
+	assert.Nil(t, err)
+	assert.Equal(t, RoleBigger, result)
+
+	// custom role based on a higher role ranks below built-in roles it doesn't cover,
+	// and only built-in roles not lower than its base role or custom roles covering it are not smaller
+	viewer := &models.CustomRole{
+		Name:     "viewer",
+		BaseRole: "owner",
+		Rules: []types.PolicyRule{{
+			Verbs:     []string{"get"},
+			APIGroups: []string{"/api/core/v1/*"},
+			Resources: []string{"groups"},
+			Scopes:    []string{"*"},
+		}},
+	}
+	manager.roles = []*models.CustomRole{deployer, viewer}
+	assert.Nil(t, service.Reload(ctx))
+	for _, c := range []struct {
+		role1, role2 string
+		result       CompResult
+	}{
+		{"viewer", "maintainer", RoleSmaller},
+		{"maintainer", "viewer", RoleSmaller},
+		{"owner", "viewer", RoleBigger},
+		{"viewer", "viewer", RoleEqual},
+		{"viewer", "deployer", RoleSmaller},
+		{"deployer", "viewer", RoleSmaller},
+		{"maintainer", "deployer", RoleBigger},
+	} {
+		result, err = service.RoleCompare(ctx, c.role1, c.role2)
+		assert.Nil(t, err)
+		assert.Equal(t, c.result, result, "%s vs %s", c.role1, c.role2)
+	}
+
+	_, err = service.RoleCompare(ctx, "deployer", "escalated")
+	assert.Equal(t, ErrorRoleNotFound, err)
+	assert.Equal(t, "maintainer", service.GetDefaultRole(ctx).Name)
+}
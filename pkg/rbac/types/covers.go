@@ -0,0 +1,95 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Covers checks whether everything allowed by rule is also allowed by rules,
+// it returns the first permission of rule which is not covered by rules
+func Covers(rules []PolicyRule, rule *PolicyRule) (string, bool) {
+	for _, verb := range rule.Verbs {
+		for _, apiGroup := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				for _, scope := range rule.Scopes {
+					if !coveredByAny(rules, func(r *PolicyRule) bool {
+						return exactCovers(r.Verbs, verb, VerbAll) &&
+							exactCovers(r.APIGroups, apiGroup, APIGroupAll) &&
+							resourceCovers(r.Resources, resource) &&
+							prefixCovers(r.Scopes, scope, ScopeAll)
+					}) {
+						return fmt.Sprintf("verb %s on resource %s of apiGroup %s in scope %s",
+							verb, resource, apiGroup, scope), false
+					}
+				}
+			}
+		}
+		for _, url := range rule.NonResourceURLs {
+			if !coveredByAny(rules, func(r *PolicyRule) bool {
+				return exactCovers(r.Verbs, verb, VerbAll) &&
+					prefixCovers(r.NonResourceURLs, url, NonResourceAll)
+			}) {
+				return fmt.Sprintf("verb %s on nonResourceURL %s", verb, url), false
+			}
+		}
+	}
+	return "", true
+}
+
+func coveredByAny(rules []PolicyRule, covers func(*PolicyRule) bool) bool {
+	for i := range rules {
+		if covers(&rules[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func exactCovers(patterns []string, value, all string) bool {
+	for _, pattern := range patterns {
+		if pattern == all || pattern == value {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceCovers follows ResourceMatches, "*/subresource" covers the subresource of any resource
+func resourceCovers(patterns []string, resource string) bool {
+	if exactCovers(patterns, resource, ResourceAll) {
+		return true
+	}
+	parts := strings.SplitN(resource, "/", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	return exactCovers(patterns, "*/"+parts[1], ResourceAll)
+}
+
+// prefixCovers follows ScopeMatches and NonResourceURLMatches, "prefix*" covers anything starts with prefix
+func prefixCovers(patterns []string, value, all string) bool {
+	for _, pattern := range patterns {
+		if pattern == all || pattern == value {
+			return true
+		}
+		if strings.HasSuffix(pattern, "*") &&
+			strings.HasPrefix(value, strings.TrimRight(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
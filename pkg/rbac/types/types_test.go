@@ -269,3 +269,86 @@ func TestRuleAllow(t *testing.T) {
 		assert.Equal(t, RuleAllow(v.attr, &v.policy), v.allowed)
 	}
 }
+
+func TestCovers(t *testing.T) {
+	base := []PolicyRule{
+		{
+			Verbs:     []string{"get", "create"},
+			APIGroups: []string{"core"},
+			Resources: []string{"applications", "applications/clusters", "*/members"},
+			Scopes:    []string{"online/*"},
+		},
+		{
+			Verbs:           []string{"*"},
+			APIGroups:       []string{"core"},
+			Resources:       []string{"clusters"},
+			Scopes:          []string{"*"},
+			NonResourceURLs: []string{"/apis/front/*"},
+		},
+	}
+
+	caseTable := []struct {
+		rule    PolicyRule
+		covered bool
+	}{
+		{
+			rule: PolicyRule{
+				Verbs:     []string{"get"},
+				APIGroups: []string{"core"},
+				Resources: []string{"applications", "clusters/members"},
+				Scopes:    []string{"online/hz"},
+			},
+			covered: true,
+		}, {
+			// verbs of different rules are combined
+			rule: PolicyRule{
+				Verbs:     []string{"delete", "get"},
+				APIGroups: []string{"core"},
+				Resources: []string{"clusters"},
+				Scopes:    []string{"online/*"},
+			},
+			covered: true,
+		}, {
+			rule: PolicyRule{
+				Verbs:     []string{"delete"},
+				APIGroups: []string{"core"},
+				Resources: []string{"applications"},
+				Scopes:    []string{"online/hz"},
+			},
+			covered: false,
+		}, {
+			// wildcards are not covered by specific values
+			rule: PolicyRule{
+				Verbs:     []string{"*"},
+				APIGroups: []string{"core"},
+				Resources: []string{"applications"},
+				Scopes:    []string{"online/hz"},
+			},
+			covered: false,
+		}, {
+			rule: PolicyRule{
+				Verbs:     []string{"get"},
+				APIGroups: []string{"core"},
+				Resources: []string{"applications"},
+				Scopes:    []string{"test/*"},
+			},
+			covered: false,
+		}, {
+			rule: PolicyRule{
+				Verbs:           []string{"get"},
+				NonResourceURLs: []string{"/apis/front/v1/*"},
+			},
+			covered: true,
+		}, {
+			rule: PolicyRule{
+				Verbs:           []string{"get"},
+				NonResourceURLs: []string{"*"},
+			},
+			covered: false,
+		},
+	}
+	for i, c := range caseTable {
+		_, covered := Covers(base, &c.rule)
+		assert.Equal(t, c.covered, covered, "case %d", i)
+	}
+}
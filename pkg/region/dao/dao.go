@@ -103,7 +103,8 @@ func (d *dao) UpdateByID(ctx context.Context, id uint, region *models.Region) er
 		return err
 	}
 
-	// can only update displayName, server, Certificate, ingressDomain、prometheusURL, registryID
+	// can only update displayName, server, Certificate, ingressDomain、prometheusURL, registryID,
+	// disabled and sealedSecretsCertificate
 	regionInDB.DisplayName = region.DisplayName
 	regionInDB.Server = region.Server
 	regionInDB.Certificate = region.Certificate
@@ -111,6 +112,8 @@ func (d *dao) UpdateByID(ctx context.Context, id uint, region *models.Region) er
 	regionInDB.PrometheusURL = region.PrometheusURL
 	regionInDB.RegistryID = region.RegistryID
 	regionInDB.Disabled = region.Disabled
+	regionInDB.SealedSecretsCertificate = region.SealedSecretsCertificate
+	regionInDB.CDEngine = region.CDEngine
 	result := d.db.WithContext(ctx).Save(regionInDB)
 	if result.Error != nil {
 		return herrors.NewErrUpdateFailed(herrors.RegionInDB, result.Error.Error())
@@ -43,7 +43,7 @@ import (
 )
 
 var (
-	db, _          = orm.NewSqliteDB("")
+	db, _          = orm.NewTestDB()
 	ctx            context.Context
 	mgr            = New(db)
 	envregionMgr   = envregionmanager.New(db)
@@ -15,6 +15,7 @@
 package models
 
 import (
+	"github.com/horizoncd/horizon/lib/encrypt"
 	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
 	"github.com/horizoncd/horizon/pkg/server/global"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
@@ -26,15 +27,25 @@ type Region struct {
 	Name          string
 	DisplayName   string
 	Server        string
-	Certificate   string
+	Certificate   encrypt.String
 	IngressDomain string
 	PrometheusURL string
 	RegistryID    uint `gorm:"column:registry_id"`
 	Disabled      bool
-	CreatedBy     uint
-	UpdatedBy     uint
+	// SealedSecretsCertificate is the pem encoded certificate of the sealed secrets controller in the region
+	SealedSecretsCertificate string
+	// CDEngine is the engine deploying clusters in the region, empty means argocd
+	CDEngine  string `gorm:"column:cd_engine"`
+	CreatedBy uint
+	UpdatedBy uint
 }
 
+const (
+	CDEngineArgoCD = "argocd"
+	CDEngineFlux   = "flux"
+	CDEngineHelm   = "helm"
+)
+
 // RegionEntity region entity, region with registry
 type RegionEntity struct {
 	*Region
@@ -337,6 +337,13 @@ func (f *RegionInformers) whetherGVRExist(regionID uint, gvr schema.GroupVersion
 	return ok
 }
 
+func (f *RegionInformers) whetherDynamicGVRExist(regionID uint, gvr schema.GroupVersionResource) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	_, ok := f.clients[regionID].dynamicWatched[gvr]
+	return ok
+}
+
 func (f *RegionInformers) watchGVR(regionID uint, gvr schema.GroupVersionResource) error {
 	f.mu.Lock()
 	client := f.clients[regionID]
@@ -375,9 +382,10 @@ func (f *RegionInformers) ensureGVR(regionID uint, gvr schema.GroupVersionResour
 }
 
 func (f *RegionInformers) ensureDynamicGVR(regionID uint, gvr schema.GroupVersionResource) error {
-	if !f.whetherGVRExist(regionID, gvr) {
+	if !f.whetherDynamicGVRExist(regionID, gvr) {
 		if !f.resourceExistInK8S(gvr, f.clients[regionID]) {
-			return fmt.Errorf("resource %s not exist in region %d", gvr.String(), regionID)
+			return herrors.NewErrNotFound(herrors.ResourceInK8S,
+				fmt.Sprintf("resource %s not exist in region %d", gvr.String(), regionID))
 		}
 		f.watchDynamicGvr(regionID, gvr)
 	}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/horizoncd/horizon/lib/encrypt"
 	"github.com/horizoncd/horizon/lib/orm"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	"github.com/horizoncd/horizon/pkg/registry/models"
@@ -26,7 +27,7 @@ import (
 
 var (
 	// use tmp sqlite
-	db, _ = orm.NewSqliteDB("")
+	db, _ = orm.NewTestDB()
 	ctx   context.Context
 	mgr   = New(db)
 )
@@ -54,7 +55,7 @@ func Test(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, registry.Name, "1")
 	assert.Equal(t, registry.Server, "2")
-	assert.Equal(t, registry.Token, "1")
+	assert.Equal(t, registry.Token, encrypt.String("1"))
 
 	err = mgr.UpdateByID(ctx, id, &models.Registry{
 		Name:   "2",
@@ -65,7 +66,7 @@ func Test(t *testing.T) {
 	registry, _ = mgr.GetByID(ctx, id)
 	assert.Equal(t, registry.Name, "2")
 	assert.Equal(t, registry.Server, "1")
-	assert.Equal(t, registry.Token, "2")
+	assert.Equal(t, registry.Token, encrypt.String("2"))
 
 	err = mgr.DeleteByID(ctx, id)
 	assert.Nil(t, err)
@@ -15,6 +15,7 @@
 package models
 
 import (
+	"github.com/horizoncd/horizon/lib/encrypt"
 	"github.com/horizoncd/horizon/pkg/server/global"
 )
 
@@ -24,7 +25,7 @@ type Registry struct {
 	Name   string
 	Server string
 	Path   string
-	Token  string
+	Token  encrypt.String
 	// for delete
 	InsecureSkipTLSVerify bool `gorm:"column:insecure_skip_tls_verify"`
 	Kind                  string
@@ -76,4 +76,8 @@ var (
 		HTTPCode:  http.StatusConflict,
 		ErrorCode: "Conflict",
 	}
+	MFARequiredError = RPCError{
+		HTTPCode:  http.StatusForbidden,
+		ErrorCode: "MFARequired",
+	}
 )
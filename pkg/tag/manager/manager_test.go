@@ -28,7 +28,7 @@ import (
 )
 
 var (
-	db, _ = orm.NewSqliteDB("")
+	db, _ = orm.NewTestDB()
 	ctx   context.Context
 	mgr   = New(db)
 )
@@ -0,0 +1,253 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/team/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, team *models.Team) (*models.Team, error)
+	GetByID(ctx context.Context, id uint) (*models.Team, error)
+	GetByName(ctx context.Context, name string) (*models.Team, error)
+	List(ctx context.Context, query *q.Query) (int64, []*models.Team, error)
+	ListByIDs(ctx context.Context, ids []uint) ([]*models.Team, error)
+	Update(ctx context.Context, team *models.Team) (*models.Team, error)
+	Delete(ctx context.Context, id uint) error
+
+	ListMembers(ctx context.Context, teamID uint) ([]*models.TeamMember, error)
+	AddMembers(ctx context.Context, teamID uint, userIDs []uint, createdBy uint) error
+	RemoveMember(ctx context.Context, teamID uint, userID uint) error
+	ListTeamIDsByUserID(ctx context.Context, userID uint) ([]uint, error)
+	SyncExternalTeams(ctx context.Context, idpID uint, userID uint, externalGroups []string) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, team *models.Team) (*models.Team, error) {
+	if err := d.db.WithContext(ctx).Create(team).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.TeamInDB, err.Error())
+	}
+	return team, nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.Team, error) {
+	var team models.Team
+	if err := d.db.WithContext(ctx).Where("id = ?", id).First(&team).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.TeamInDB,
+				fmt.Sprintf("team with id = %d was not found", id))
+		}
+		return nil, herrors.NewErrGetFailed(herrors.TeamInDB, err.Error())
+	}
+	return &team, nil
+}
+
+func (d *dao) GetByName(ctx context.Context, name string) (*models.Team, error) {
+	var team models.Team
+	if err := d.db.WithContext(ctx).Where("name = ?", name).First(&team).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.TeamInDB,
+				fmt.Sprintf("team with name = %s was not found", name))
+		}
+		return nil, herrors.NewErrGetFailed(herrors.TeamInDB, err.Error())
+	}
+	return &team, nil
+}
+
+func (d *dao) List(ctx context.Context, query *q.Query) (int64, []*models.Team, error) {
+	var (
+		teams []*models.Team
+		total int64
+	)
+	tx := d.db.WithContext(ctx).Model(&models.Team{})
+	if query != nil {
+		for k, v := range query.Keywords {
+			switch k {
+			case common.TeamQueryName:
+				tx = tx.Where("lower(name) like lower(?)", fmt.Sprintf("%%%v%%", v))
+			case common.TeamQueryIdpID:
+				tx = tx.Where("idp_id = ?", v)
+			}
+		}
+	}
+	if err := tx.Count(&total).Error; err != nil {
+		return 0, nil, herrors.NewErrListFailed(herrors.TeamInDB, err.Error())
+	}
+	if query != nil {
+		tx = tx.Limit(query.Limit()).Offset(query.Offset())
+	}
+	if err := tx.Order("id asc").Find(&teams).Error; err != nil {
+		return 0, nil, herrors.NewErrListFailed(herrors.TeamInDB, err.Error())
+	}
+	return total, teams, nil
+}
+
+func (d *dao) ListByIDs(ctx context.Context, ids []uint) ([]*models.Team, error) {
+	var teams []*models.Team
+	if len(ids) == 0 {
+		return teams, nil
+	}
+	if err := d.db.WithContext(ctx).Where("id in ?", ids).Find(&teams).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.TeamInDB, err.Error())
+	}
+	return teams, nil
+}
+
+func (d *dao) Update(ctx context.Context, team *models.Team) (*models.Team, error) {
+	// use map to update zero values, e.g. description = ''
+	if err := d.db.WithContext(ctx).Model(&models.Team{}).Where("id = ?", team.ID).
+		Updates(map[string]interface{}{
+			"name":           team.Name,
+			"description":    team.Description,
+			"idp_id":         team.IdpID,
+			"external_group": team.ExternalGroup,
+			"updated_by":     team.UpdatedBy,
+		}).Error; err != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.TeamInDB, err.Error())
+	}
+	return d.GetByID(ctx, team.ID)
+}
+
+// Delete deletes the team with its members and the member bindings of it
+func (d *dao) Delete(ctx context.Context, id uint) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("team_id = ?", id).Delete(&models.TeamMember{}).Error; err != nil {
+			return herrors.NewErrDeleteFailed(herrors.TeamMemberInDB, err.Error())
+		}
+		if err := tx.Where("member_type = ? and membername_id = ?", membermodels.MemberGroup, id).
+			Delete(&membermodels.Member{}).Error; err != nil {
+			return herrors.NewErrDeleteFailed(herrors.MemberInfoInDB, err.Error())
+		}
+		if err := tx.Where("id = ?", id).Delete(&models.Team{}).Error; err != nil {
+			return herrors.NewErrDeleteFailed(herrors.TeamInDB, err.Error())
+		}
+		return nil
+	})
+}
+
+func (d *dao) ListMembers(ctx context.Context, teamID uint) ([]*models.TeamMember, error) {
+	var members []*models.TeamMember
+	if err := d.db.WithContext(ctx).Where("team_id = ?", teamID).
+		Order("id asc").Find(&members).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.TeamMemberInDB, err.Error())
+	}
+	return members, nil
+}
+
+func (d *dao) AddMembers(ctx context.Context, teamID uint, userIDs []uint, createdBy uint) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return addMembers(tx, teamID, userIDs, createdBy)
+	})
+}
+
+func addMembers(tx *gorm.DB, teamID uint, userIDs []uint, createdBy uint) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	var existing []uint
+	if err := tx.Model(&models.TeamMember{}).Where("team_id = ? and user_id in ?", teamID, userIDs).
+		Pluck("user_id", &existing).Error; err != nil {
+		return herrors.NewErrListFailed(herrors.TeamMemberInDB, err.Error())
+	}
+	existingSet := make(map[uint]struct{}, len(existing))
+	for _, id := range existing {
+		existingSet[id] = struct{}{}
+	}
+	for _, userID := range userIDs {
+		if _, ok := existingSet[userID]; ok {
+			continue
+		}
+		existingSet[userID] = struct{}{}
+		if err := tx.Create(&models.TeamMember{
+			TeamID:    teamID,
+			UserID:    userID,
+			CreatedBy: createdBy,
+		}).Error; err != nil {
+			return herrors.NewErrInsertFailed(herrors.TeamMemberInDB, err.Error())
+		}
+	}
+	return nil
+}
+
+func (d *dao) RemoveMember(ctx context.Context, teamID uint, userID uint) error {
+	if err := d.db.WithContext(ctx).Where("team_id = ? and user_id = ?", teamID, userID).
+		Delete(&models.TeamMember{}).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.TeamMemberInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) ListTeamIDsByUserID(ctx context.Context, userID uint) ([]uint, error) {
+	var ids []uint
+	if err := d.db.WithContext(ctx).Model(&models.TeamMember{}).Where("user_id = ?", userID).
+		Pluck("team_id", &ids).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.TeamMemberInDB, err.Error())
+	}
+	return ids, nil
+}
+
+// SyncExternalTeams makes the user a member of exactly the teams of idp whose external group is in externalGroups
+func (d *dao) SyncExternalTeams(ctx context.Context, idpID uint, userID uint, externalGroups []string) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var teams []*models.Team
+		if err := tx.Where("idp_id = ?", idpID).Find(&teams).Error; err != nil {
+			return herrors.NewErrListFailed(herrors.TeamInDB, err.Error())
+		}
+		if len(teams) == 0 {
+			return nil
+		}
+		groups := make(map[string]struct{}, len(externalGroups))
+		for _, group := range externalGroups {
+			groups[group] = struct{}{}
+		}
+		var joined, left []uint
+		for _, team := range teams {
+			if _, ok := groups[team.ExternalGroup]; ok {
+				joined = append(joined, team.ID)
+			} else {
+				left = append(left, team.ID)
+			}
+		}
+		if len(left) > 0 {
+			if err := tx.Where("team_id in ? and user_id = ?", left, userID).
+				Delete(&models.TeamMember{}).Error; err != nil {
+				return herrors.NewErrDeleteFailed(herrors.TeamMemberInDB, err.Error())
+			}
+		}
+		for _, teamID := range joined {
+			if err := addMembers(tx, teamID, []uint{userID}, userID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,102 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/team/dao"
+	"github.com/horizoncd/horizon/pkg/team/models"
+)
+
+type Manager interface {
+	Create(ctx context.Context, team *models.Team) (*models.Team, error)
+	GetByID(ctx context.Context, id uint) (*models.Team, error)
+	GetByName(ctx context.Context, name string) (*models.Team, error)
+	List(ctx context.Context, query *q.Query) (int64, []*models.Team, error)
+	ListByIDs(ctx context.Context, ids []uint) ([]*models.Team, error)
+	Update(ctx context.Context, team *models.Team) (*models.Team, error)
+	// Delete deletes the team with its members and the member bindings of it
+	Delete(ctx context.Context, id uint) error
+
+	ListMembers(ctx context.Context, teamID uint) ([]*models.TeamMember, error)
+	// AddMembers adds users to team, users who are already in the team are ignored
+	AddMembers(ctx context.Context, teamID uint, userIDs []uint, createdBy uint) error
+	RemoveMember(ctx context.Context, teamID uint, userID uint) error
+	// ListTeamIDsByUserID lists ids of the teams which user belongs to
+	ListTeamIDsByUserID(ctx context.Context, userID uint) ([]uint, error)
+	// SyncExternalTeams syncs user's memberships of teams synced from idp by the groups of user in idp
+	SyncExternalTeams(ctx context.Context, idpID uint, userID uint, externalGroups []string) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Create(ctx context.Context, team *models.Team) (*models.Team, error) {
+	return m.dao.Create(ctx, team)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.Team, error) {
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) GetByName(ctx context.Context, name string) (*models.Team, error) {
+	return m.dao.GetByName(ctx, name)
+}
+
+func (m *manager) List(ctx context.Context, query *q.Query) (int64, []*models.Team, error) {
+	return m.dao.List(ctx, query)
+}
+
+func (m *manager) ListByIDs(ctx context.Context, ids []uint) ([]*models.Team, error) {
+	return m.dao.ListByIDs(ctx, ids)
+}
+
+func (m *manager) Update(ctx context.Context, team *models.Team) (*models.Team, error) {
+	return m.dao.Update(ctx, team)
+}
+
+func (m *manager) Delete(ctx context.Context, id uint) error {
+	return m.dao.Delete(ctx, id)
+}
+
+func (m *manager) ListMembers(ctx context.Context, teamID uint) ([]*models.TeamMember, error) {
+	return m.dao.ListMembers(ctx, teamID)
+}
+
+func (m *manager) AddMembers(ctx context.Context, teamID uint, userIDs []uint, createdBy uint) error {
+	return m.dao.AddMembers(ctx, teamID, userIDs, createdBy)
+}
+
+func (m *manager) RemoveMember(ctx context.Context, teamID uint, userID uint) error {
+	return m.dao.RemoveMember(ctx, teamID, userID)
+}
+
+func (m *manager) ListTeamIDsByUserID(ctx context.Context, userID uint) ([]uint, error) {
+	return m.dao.ListTeamIDsByUserID(ctx, userID)
+}
+
+func (m *manager) SyncExternalTeams(ctx context.Context, idpID uint,
+	userID uint, externalGroups []string) error {
+	return m.dao.SyncExternalTeams(ctx, idpID, userID, externalGroups)
+}
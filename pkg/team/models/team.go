@@ -0,0 +1,43 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "github.com/horizoncd/horizon/pkg/server/global"
+
+// Team is a set of users which can be bound as a member of groups, applications and clusters
+type Team struct {
+	global.Model
+
+	Name        string
+	Description string
+	// IdpID and ExternalGroup are set when the team is synced from a group of identity provider,
+	// its members are maintained by the identity provider on login and can't be edited manually
+	IdpID         uint
+	ExternalGroup string
+	CreatedBy     uint
+	UpdatedBy     uint
+}
+
+func (t *Team) IsSynced() bool {
+	return t.IdpID != 0
+}
+
+type TeamMember struct {
+	global.Model
+
+	TeamID    uint
+	UserID    uint
+	CreatedBy uint
+}
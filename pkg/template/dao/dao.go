@@ -223,7 +223,7 @@ func (d dao) ListV2(ctx context.Context, query *q.Query, groupIDs ...uint) ([]*m
 			case common.TemplateQueryByGroup:
 				statement = statement.Where("t.group_id = ?", v)
 			case common.TemplateQueryName:
-				statement = statement.Where("t.name like ?", fmt.Sprintf("%%%v%%", v))
+				statement = statement.Where("lower(t.name) like lower(?)", fmt.Sprintf("%%%v%%", v))
 			case common.TemplateQueryType:
 				tp := reflect.TypeOf(v)
 				if tp.Kind() == reflect.Slice {
@@ -241,9 +241,8 @@ func (d dao) ListV2(ctx context.Context, query *q.Query, groupIDs ...uint) ([]*m
 				statement = statement.
 					Joins("join tb_member as m on m.resource_id = t.id").
 					Where("m.resource_type = ?", common.ResourceTemplate).
-					Where("m.member_type = '0'").
-					Where("m.deleted_ts = 0").
-					Where("m.membername_id = ?", v)
+					Where(dbsql.MemberOfUser, v, v).
+					Where("m.deleted_ts = 0")
 			}
 		}
 		statement = statement.Where("t.deleted_ts = 0")
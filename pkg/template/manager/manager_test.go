@@ -156,7 +156,7 @@ func Test(t *testing.T) {
 }
 
 func TestMain(m *testing.M) {
-	db, _ = orm.NewSqliteDB("")
+	db, _ = orm.NewTestDB()
 	if err := db.AutoMigrate(&models.Template{}, &trmodels.TemplateRelease{},
 		&membermodels.Member{},
 		&applicationmodel.Application{}, &clustermodel.Cluster{}); err != nil {
@@ -140,7 +140,7 @@ func Test(t *testing.T) {
 }
 
 func TestMain(m *testing.M) {
-	db, _ = orm.NewSqliteDB("")
+	db, _ = orm.NewTestDB()
 	if err := db.AutoMigrate(&trmodels.TemplateRelease{},
 		&applicationmodel.Application{}, &tmodels.Template{},
 		&membermodels.Member{}); err != nil {
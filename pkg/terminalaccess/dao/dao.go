@@ -0,0 +1,119 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/terminalaccess/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, grant *models.TerminalAccessGrant) (*models.TerminalAccessGrant, error)
+	GetByID(ctx context.Context, id uint) (*models.TerminalAccessGrant, error)
+	// UpdateStatus updates the status of grant whose status is from
+	UpdateStatus(ctx context.Context, id uint, from, to string, approverID uint, expiresAt *time.Time) error
+	// ListByClusterID lists grants of a cluster, newest first
+	ListByClusterID(ctx context.Context, clusterID uint, query *q.Query) (int64, []*models.TerminalAccessGrant, error)
+	// GetValid returns the approved grant of user which expires latest
+	GetValid(ctx context.Context, clusterID, userID uint, now time.Time) (*models.TerminalAccessGrant, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, grant *models.TerminalAccessGrant) (*models.TerminalAccessGrant, error) {
+	if err := d.db.WithContext(ctx).Create(grant).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.TerminalAccessGrantInDB, err.Error())
+	}
+	return grant, nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.TerminalAccessGrant, error) {
+	var grant models.TerminalAccessGrant
+	if err := d.db.WithContext(ctx).Where("id = ?", id).First(&grant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.TerminalAccessGrantInDB,
+				fmt.Sprintf("terminal access grant %d was not found", id))
+		}
+		return nil, herrors.NewErrGetFailed(herrors.TerminalAccessGrantInDB, err.Error())
+	}
+	return &grant, nil
+}
+
+func (d *dao) UpdateStatus(ctx context.Context, id uint, from, to string,
+	approverID uint, expiresAt *time.Time) error {
+	result := d.db.WithContext(ctx).Model(&models.TerminalAccessGrant{}).
+		Where("id = ? and status = ?", id, from).
+		Updates(map[string]interface{}{
+			"status":      to,
+			"approver_id": approverID,
+			"expires_at":  expiresAt,
+		})
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.TerminalAccessGrantInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return herrors.NewErrNotFound(herrors.TerminalAccessGrantInDB,
+			fmt.Sprintf("terminal access grant %d in status %s was not found", id, from))
+	}
+	return nil
+}
+
+func (d *dao) ListByClusterID(ctx context.Context, clusterID uint,
+	query *q.Query) (int64, []*models.TerminalAccessGrant, error) {
+	statement := d.db.WithContext(ctx).Model(&models.TerminalAccessGrant{}).Where("cluster_id = ?", clusterID)
+
+	var total int64
+	if err := statement.Count(&total).Error; err != nil {
+		return 0, nil, herrors.NewErrListFailed(herrors.TerminalAccessGrantInDB, err.Error())
+	}
+	var grants []*models.TerminalAccessGrant
+	if query != nil && !query.WithoutPagination {
+		statement = statement.Offset(query.Offset()).Limit(query.Limit())
+	}
+	if err := statement.Order("id desc").Find(&grants).Error; err != nil {
+		return 0, nil, herrors.NewErrListFailed(herrors.TerminalAccessGrantInDB, err.Error())
+	}
+	return total, grants, nil
+}
+
+func (d *dao) GetValid(ctx context.Context, clusterID, userID uint,
+	now time.Time) (*models.TerminalAccessGrant, error) {
+	var grant models.TerminalAccessGrant
+	if err := d.db.WithContext(ctx).
+		Where("cluster_id = ? and user_id = ? and status = ? and expires_at > ?",
+			clusterID, userID, models.StatusApproved, now).
+		Order("expires_at desc").First(&grant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.TerminalAccessGrantInDB,
+				fmt.Sprintf("valid terminal access grant of user %d was not found", userID))
+		}
+		return nil, herrors.NewErrGetFailed(herrors.TerminalAccessGrantInDB, err.Error())
+	}
+	return &grant, nil
+}
@@ -0,0 +1,80 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/terminalaccess/dao"
+	"github.com/horizoncd/horizon/pkg/terminalaccess/models"
+)
+
+type Manager interface {
+	Create(ctx context.Context, grant *models.TerminalAccessGrant) (*models.TerminalAccessGrant, error)
+	GetByID(ctx context.Context, id uint) (*models.TerminalAccessGrant, error)
+	// Approve approves a pending grant, which expires at the time
+	Approve(ctx context.Context, id, approverID uint, expiresAt time.Time) error
+	// Reject rejects a pending grant
+	Reject(ctx context.Context, id, approverID uint) error
+	// Revoke revokes an approved grant
+	Revoke(ctx context.Context, id, approverID uint) error
+	// ListByClusterID lists grants of a cluster, newest first
+	ListByClusterID(ctx context.Context, clusterID uint, query *q.Query) (int64, []*models.TerminalAccessGrant, error)
+	// GetValid returns the approved grant of user which expires latest
+	GetValid(ctx context.Context, clusterID, userID uint) (*models.TerminalAccessGrant, error)
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Create(ctx context.Context, grant *models.TerminalAccessGrant) (*models.TerminalAccessGrant, error) {
+	grant.Status = models.StatusPending
+	return m.dao.Create(ctx, grant)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.TerminalAccessGrant, error) {
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) Approve(ctx context.Context, id, approverID uint, expiresAt time.Time) error {
+	return m.dao.UpdateStatus(ctx, id, models.StatusPending, models.StatusApproved, approverID, &expiresAt)
+}
+
+func (m *manager) Reject(ctx context.Context, id, approverID uint) error {
+	return m.dao.UpdateStatus(ctx, id, models.StatusPending, models.StatusRejected, approverID, nil)
+}
+
+func (m *manager) Revoke(ctx context.Context, id, approverID uint) error {
+	now := time.Now()
+	return m.dao.UpdateStatus(ctx, id, models.StatusApproved, models.StatusRevoked, approverID, &now)
+}
+
+func (m *manager) ListByClusterID(ctx context.Context, clusterID uint,
+	query *q.Query) (int64, []*models.TerminalAccessGrant, error) {
+	return m.dao.ListByClusterID(ctx, clusterID, query)
+}
+
+func (m *manager) GetValid(ctx context.Context, clusterID, userID uint) (*models.TerminalAccessGrant, error) {
+	return m.dao.GetValid(ctx, clusterID, userID, time.Now())
+}
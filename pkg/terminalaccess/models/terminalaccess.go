@@ -0,0 +1,42 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+	StatusRevoked  = "revoked"
+)
+
+// TerminalAccessGrant is a just-in-time terminal access of a cluster requested by a user
+type TerminalAccessGrant struct {
+	ID        uint `gorm:"primarykey"`
+	ClusterID uint
+	// UserID is the user who requested the access
+	UserID uint
+	Reason string
+	// Duration is the requested duration in seconds
+	Duration int64
+	Status   string
+	// ApproverID is the user who approved or rejected the request
+	ApproverID uint
+	// ExpiresAt is set when the request is approved
+	ExpiresAt *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
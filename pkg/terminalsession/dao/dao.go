@@ -0,0 +1,131 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/terminalsession/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, session *models.TerminalSession) (*models.TerminalSession, error)
+	GetByID(ctx context.Context, id uint) (*models.TerminalSession, error)
+	// Finish records the recording object of a finished session
+	Finish(ctx context.Context, id uint, object string, size int64, finishedAt time.Time) error
+	// ListByClusterID lists sessions of a cluster, newest first
+	ListByClusterID(ctx context.Context, clusterID uint, query *q.Query) (int64, []*models.TerminalSession, error)
+	// ListStartedBefore lists at most limit sessions started before the time
+	ListStartedBefore(ctx context.Context, before time.Time, limit int) ([]*models.TerminalSession, error)
+	DeleteByIDs(ctx context.Context, ids []uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, session *models.TerminalSession) (*models.TerminalSession, error) {
+	if err := d.db.WithContext(ctx).Create(session).Error; err != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.TerminalSessionInDB, err.Error())
+	}
+	return session, nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.TerminalSession, error) {
+	var session models.TerminalSession
+	if err := d.db.WithContext(ctx).Where("id = ?", id).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.TerminalSessionInDB,
+				fmt.Sprintf("terminal session %d was not found", id))
+		}
+		return nil, herrors.NewErrGetFailed(herrors.TerminalSessionInDB, err.Error())
+	}
+	return &session, nil
+}
+
+func (d *dao) Finish(ctx context.Context, id uint, object string, size int64, finishedAt time.Time) error {
+	if err := d.db.WithContext(ctx).Model(&models.TerminalSession{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"object":      object,
+			"size":        size,
+			"finished_at": finishedAt,
+		}).Error; err != nil {
+		return herrors.NewErrUpdateFailed(herrors.TerminalSessionInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) ListByClusterID(ctx context.Context, clusterID uint,
+	query *q.Query) (int64, []*models.TerminalSession, error) {
+	statement := d.db.WithContext(ctx).Model(&models.TerminalSession{}).Where("cluster_id = ?", clusterID)
+	if query != nil {
+		for k, v := range query.Keywords {
+			switch k {
+			case common.TerminalSessionQueryByUser:
+				statement = statement.Where("user_id = ?", v)
+			case common.TerminalSessionQueryByPod:
+				statement = statement.Where("pod = ?", v)
+			case common.TerminalSessionQueryByContainer:
+				statement = statement.Where("container = ?", v)
+			}
+		}
+	}
+
+	var total int64
+	if err := statement.Count(&total).Error; err != nil {
+		return 0, nil, herrors.NewErrListFailed(herrors.TerminalSessionInDB, err.Error())
+	}
+	var sessions []*models.TerminalSession
+	if query != nil && !query.WithoutPagination {
+		statement = statement.Offset(query.Offset()).Limit(query.Limit())
+	}
+	if err := statement.Order("id desc").Find(&sessions).Error; err != nil {
+		return 0, nil, herrors.NewErrListFailed(herrors.TerminalSessionInDB, err.Error())
+	}
+	return total, sessions, nil
+}
+
+func (d *dao) ListStartedBefore(ctx context.Context, before time.Time,
+	limit int) ([]*models.TerminalSession, error) {
+	var sessions []*models.TerminalSession
+	if err := d.db.WithContext(ctx).Where("started_at < ?", before).
+		Order("id asc").Limit(limit).Find(&sessions).Error; err != nil {
+		return nil, herrors.NewErrListFailed(herrors.TerminalSessionInDB, err.Error())
+	}
+	return sessions, nil
+}
+
+func (d *dao) DeleteByIDs(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := d.db.WithContext(ctx).Where("id in ?", ids).
+		Delete(&models.TerminalSession{}).Error; err != nil {
+		return herrors.NewErrDeleteFailed(herrors.TerminalSessionInDB, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/terminalsession/dao"
+	"github.com/horizoncd/horizon/pkg/terminalsession/models"
+)
+
+type Manager interface {
+	Create(ctx context.Context, session *models.TerminalSession) (*models.TerminalSession, error)
+	GetByID(ctx context.Context, id uint) (*models.TerminalSession, error)
+	// Finish records the recording object of a finished session
+	Finish(ctx context.Context, id uint, object string, size int64, finishedAt time.Time) error
+	// ListByClusterID lists sessions of a cluster, newest first
+	ListByClusterID(ctx context.Context, clusterID uint, query *q.Query) (int64, []*models.TerminalSession, error)
+	// ListStartedBefore lists at most limit sessions started before the time
+	ListStartedBefore(ctx context.Context, before time.Time, limit int) ([]*models.TerminalSession, error)
+	DeleteByIDs(ctx context.Context, ids []uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Create(ctx context.Context, session *models.TerminalSession) (*models.TerminalSession, error) {
+	return m.dao.Create(ctx, session)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.TerminalSession, error) {
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) Finish(ctx context.Context, id uint, object string, size int64, finishedAt time.Time) error {
+	return m.dao.Finish(ctx, id, object, size, finishedAt)
+}
+
+func (m *manager) ListByClusterID(ctx context.Context, clusterID uint,
+	query *q.Query) (int64, []*models.TerminalSession, error) {
+	return m.dao.ListByClusterID(ctx, clusterID, query)
+}
+
+func (m *manager) ListStartedBefore(ctx context.Context, before time.Time,
+	limit int) ([]*models.TerminalSession, error) {
+	return m.dao.ListStartedBefore(ctx, before, limit)
+}
+
+func (m *manager) DeleteByIDs(ctx context.Context, ids []uint) error {
+	return m.dao.DeleteByIDs(ctx, ids)
+}
@@ -0,0 +1,38 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// TerminalSession is a recorded shell session into a container of cluster
+type TerminalSession struct {
+	ID uint `gorm:"primarykey"`
+	// SessionID is the random id of terminal session
+	SessionID   string
+	ClusterID   uint
+	Environment string
+	Pod         string
+	Container   string
+	// UserID is the user who opened the terminal
+	UserID uint
+	// Object is the s3 object of the recording in asciicast format, it's empty until the session finished
+	Object string
+	// Size is the size of recording in bytes
+	Size       int64
+	StartedAt  time.Time
+	FinishedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,122 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	// Version is the version of asciicast format,
+	// see https://docs.asciinema.org/manual/asciicast/v2/ for more information.
+	Version = 2
+
+	EventInput  = "i"
+	EventOutput = "o"
+	EventResize = "r"
+
+	DefaultWidth  = 80
+	DefaultHeight = 24
+)
+
+// Header is the first line of an asciicast recording
+type Header struct {
+	Version   int               `json:"version"`
+	Width     uint16            `json:"width"`
+	Height    uint16            `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Recorder writes a terminal session to w in asciicast format,
+// it's safe to record events from multiple goroutines
+type Recorder struct {
+	lock  sync.Mutex
+	w     io.Writer
+	start time.Time
+	err   error
+	now   func() time.Time
+}
+
+func New(w io.Writer, title string) (*Recorder, error) {
+	return newRecorder(w, title, time.Now)
+}
+
+func newRecorder(w io.Writer, title string, now func() time.Time) (*Recorder, error) {
+	r := &Recorder{
+		w:     w,
+		start: now(),
+		now:   now,
+	}
+	header, err := json.Marshal(&Header{
+		Version:   Version,
+		Width:     DefaultWidth,
+		Height:    DefaultHeight,
+		Timestamp: r.start.Unix(),
+		Title:     title,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(w, "%s\n", header); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Input records the data typed by user
+func (r *Recorder) Input(data string) {
+	r.record(EventInput, data)
+}
+
+// Output records the data printed by process
+func (r *Recorder) Output(data string) {
+	r.record(EventOutput, data)
+}
+
+// Resize records the new size of terminal
+func (r *Recorder) Resize(width, height uint16) {
+	r.record(EventResize, fmt.Sprintf("%dx%d", width, height))
+}
+
+// Err returns the first error when writing events, no more events are written after an error
+func (r *Recorder) Err() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.err
+}
+
+func (r *Recorder) record(code, data string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.err != nil {
+		return
+	}
+
+	elapsed := float64(r.now().Sub(r.start).Microseconds()) / 1e6
+	event, err := json.Marshal([]interface{}{elapsed, code, data})
+	if err != nil {
+		r.err = err
+		return
+	}
+	if _, err := fmt.Fprintf(r.w, "%s\n", event); err != nil {
+		r.err = err
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recorder
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	start := time.Unix(1672531200, 0)
+	now := start
+	buf := &bytes.Buffer{}
+	r, err := newRecorder(buf, "pod/container", func() time.Time { return now })
+	assert.Nil(t, err)
+
+	r.Resize(120, 40)
+	now = start.Add(1500 * time.Millisecond)
+	r.Input("ls\r")
+	now = start.Add(2 * time.Second)
+	r.Output("a.txt\r\n")
+	assert.Nil(t, r.Err())
+
+	expected := `{"version":2,"width":80,"height":24,"timestamp":1672531200,"title":"pod/container"}
+[0,"r","120x40"]
+[1.5,"i","ls\r"]
+[2,"o","a.txt\r\n"]
+`
+	assert.Equal(t, expected, buf.String())
+}
@@ -0,0 +1,231 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/s3"
+	"github.com/horizoncd/horizon/pkg/config/terminal"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/terminalsession/manager"
+	"github.com/horizoncd/horizon/pkg/terminalsession/models"
+	"github.com/horizoncd/horizon/pkg/terminalsession/recorder"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	ContentTypeAsciicast = "application/x-asciicast"
+
+	_retentionInterval = time.Hour
+	_retentionBatch    = 100
+)
+
+type Service interface {
+	// Enabled returns whether terminal sessions should be recorded
+	Enabled() bool
+	// Start creates a terminal session and starts recording it
+	Start(ctx context.Context, session *models.TerminalSession) (*Recording, error)
+	// GetRecording returns the recording of a finished session in asciicast format
+	GetRecording(ctx context.Context, session *models.TerminalSession) ([]byte, error)
+	// Run deletes the recordings exceeding retention periodically
+	Run(ctx context.Context)
+}
+
+type service struct {
+	config terminal.Recording
+	mgr    manager.Manager
+	s3     s3.Interface
+}
+
+func New(config terminal.Recording, mgr manager.Manager) (Service, error) {
+	s := &service{
+		config: config,
+		mgr:    mgr,
+	}
+	if !config.Enabled {
+		return s, nil
+	}
+	driver, err := s3.NewDriver(s3.Params{
+		AccessKey:        config.Storage.AccessKey,
+		SecretKey:        config.Storage.SecretKey,
+		Region:           config.Storage.Region,
+		Endpoint:         config.Storage.Endpoint,
+		Bucket:           config.Storage.Bucket,
+		DisableSSL:       config.Storage.DisableSSL,
+		SkipVerify:       config.Storage.SkipVerify,
+		S3ForcePathStyle: config.Storage.S3ForcePathStyle,
+		ContentType:      ContentTypeAsciicast,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.s3 = driver
+	return s, nil
+}
+
+func (s *service) Enabled() bool {
+	return s.config.Enabled
+}
+
+func (s *service) Start(ctx context.Context, session *models.TerminalSession) (*Recording, error) {
+	if !s.Enabled() {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "terminal recording is not enabled")
+	}
+	file, err := ioutil.TempFile("", "terminal-*.cast")
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrS3PutObjFailed, "failed to create temp file: %v", err)
+	}
+	rec, err := recorder.New(file, fmt.Sprintf("%s/%s", session.Pod, session.Container))
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return nil, perror.Wrapf(herrors.ErrS3PutObjFailed, "failed to write recording header: %v", err)
+	}
+	session, err = s.mgr.Create(ctx, session)
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return nil, err
+	}
+	return &Recording{
+		Recorder: rec,
+		session:  session,
+		file:     file,
+		svc:      s,
+	}, nil
+}
+
+func (s *service) GetRecording(ctx context.Context, session *models.TerminalSession) ([]byte, error) {
+	if session.Object == "" || s.s3 == nil {
+		return nil, herrors.NewErrNotFound(herrors.TerminalRecording,
+			fmt.Sprintf("recording of terminal session %d not found", session.ID))
+	}
+	b, err := s.s3.GetObject(ctx, session.Object)
+	if err != nil {
+		if e, ok := err.(awserr.Error); ok {
+			if e.Code() == awss3.ErrCodeNoSuchKey {
+				return nil, herrors.NewErrNotFound(herrors.TerminalRecording, err.Error())
+			}
+		}
+		return nil, perror.Wrap(herrors.ErrS3GetObjFailed, err.Error())
+	}
+	return b, nil
+}
+
+func (s *service) Run(ctx context.Context) {
+	if !s.Enabled() || s.config.Retention <= 0 {
+		return
+	}
+	log.Infof(ctx, "Starting deleting terminal recordings older than %v every %v",
+		s.config.Retention, _retentionInterval)
+	defer log.Infof(ctx, "Stopping deleting terminal recordings")
+	ticker := time.NewTicker(_retentionInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.clean(ctx, time.Now().Add(-s.config.Retention))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *service) clean(ctx context.Context, before time.Time) {
+	for {
+		sessions, err := s.mgr.ListStartedBefore(ctx, before, _retentionBatch)
+		if err != nil {
+			log.Errorf(ctx, "failed to list expired terminal sessions: %v", err)
+			return
+		}
+		if len(sessions) == 0 {
+			return
+		}
+		ids := make([]uint, 0, len(sessions))
+		for _, session := range sessions {
+			if session.Object != "" {
+				if err := s.s3.DeleteObjects(ctx, session.Object); err != nil {
+					log.Errorf(ctx, "failed to delete recording %s: %v", session.Object, err)
+					return
+				}
+			}
+			ids = append(ids, session.ID)
+		}
+		if err := s.mgr.DeleteByIDs(ctx, ids); err != nil {
+			log.Errorf(ctx, "failed to delete expired terminal sessions: %v", err)
+			return
+		}
+		log.Infof(ctx, "deleted %d expired terminal sessions", len(ids))
+		if len(sessions) < _retentionBatch {
+			return
+		}
+	}
+}
+
+// Recording records a terminal session into a temp file, and uploads it to s3 when closed
+type Recording struct {
+	*recorder.Recorder
+	session *models.TerminalSession
+	file    *os.File
+	svc     *service
+}
+
+func (r *Recording) Session() *models.TerminalSession {
+	return r.session
+}
+
+// Close uploads the recording and marks the session finished
+func (r *Recording) Close(ctx context.Context) error {
+	defer func() {
+		_ = r.file.Close()
+		_ = os.Remove(r.file.Name())
+	}()
+	if err := r.Err(); err != nil {
+		log.Warningf(ctx, "terminal session %d is partially recorded: %v", r.session.ID, err)
+	}
+
+	size, err := r.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return perror.Wrap(herrors.ErrS3PutObjFailed, err.Error())
+	}
+	if _, err := r.file.Seek(0, io.SeekStart); err != nil {
+		return perror.Wrap(herrors.ErrS3PutObjFailed, err.Error())
+	}
+	object := ObjectPath(r.session)
+	if err := r.svc.s3.PutObject(ctx, object, r.file, map[string]string{
+		"cluster-id": fmt.Sprintf("%d", r.session.ClusterID),
+		"pod":        r.session.Pod,
+		"container":  r.session.Container,
+		"user-id":    fmt.Sprintf("%d", r.session.UserID),
+	}); err != nil {
+		return perror.Wrap(herrors.ErrS3PutObjFailed, err.Error())
+	}
+	return r.svc.mgr.Finish(ctx, r.session.ID, object, size, time.Now())
+}
+
+// ObjectPath returns the s3 object of recording, which is indexed by cluster, pod and user
+func ObjectPath(session *models.TerminalSession) string {
+	return fmt.Sprintf("terminal/%d/%s/%d/%s.cast", session.ClusterID, session.Pod, session.UserID, session.SessionID)
+}
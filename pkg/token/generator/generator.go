@@ -28,9 +28,11 @@ import (
 // ref: https://github.blog/2021-04-05-behind-githubs-new-authentication-token-formats/
 const (
 	HorizonAppUserToServerAccessTokenPrefix = "hu_"
+	HorizonAppServerToServerTokenPrefix     = "hs_"
 	OauthAPPAccessTokenPrefix               = "ho_"
 	AccessTokenPrefix                       = "ha_"
 	RefreshTokenPrefix                      = "hr_"
+	DeviceCodePrefix                        = "hd_"
 )
 
 func NewAuthorizeGenerator() CodeGenerator {
@@ -41,6 +43,10 @@ func NewHorizonAppUserToServerAccessGenerator() CodeGenerator {
 	return &basicTokenGenerator{prefix: HorizonAppUserToServerAccessTokenPrefix}
 }
 
+func NewHorizonAppServerToServerAccessGenerator() CodeGenerator {
+	return &basicTokenGenerator{prefix: HorizonAppServerToServerTokenPrefix}
+}
+
 func NewOauthAccessGenerator() CodeGenerator {
 	return &basicTokenGenerator{prefix: OauthAPPAccessTokenPrefix}
 }
@@ -53,6 +59,10 @@ func NewRefreshTokenGenerator() CodeGenerator {
 	return &basicTokenGenerator{prefix: RefreshTokenPrefix}
 }
 
+func NewDeviceCodeGenerator() CodeGenerator {
+	return &basicTokenGenerator{prefix: DeviceCodePrefix}
+}
+
 type authorizationCodeGenerator struct{}
 
 type basicTokenGenerator struct {
@@ -16,6 +16,7 @@ package manager
 
 import (
 	"context"
+	"time"
 
 	"github.com/horizoncd/horizon/pkg/token/models"
 	"github.com/horizoncd/horizon/pkg/token/store"
@@ -28,6 +29,9 @@ type Manager interface {
 	LoadTokenByCode(ctx context.Context, code string) (*models.Token, error)
 	RevokeTokenByID(context.Context, uint) error
 	RevokeTokenByClientID(ctx context.Context, clientID string) error
+	UpdateLastUsed(ctx context.Context, id uint, usedAt time.Time, ip string) error
+	UpdateExpiryNotifiedAt(ctx context.Context, id uint, notifiedAt time.Time) error
+	ListExpirableAccessTokens(ctx context.Context, startID uint, limit int) ([]*models.Token, error)
 }
 
 func New(db *gorm.DB) Manager {
@@ -57,3 +61,16 @@ func (m *manager) RevokeTokenByID(ctx context.Context, id uint) error {
 func (m *manager) RevokeTokenByClientID(ctx context.Context, clientID string) error {
 	return m.store.DeleteByClientID(ctx, clientID)
 }
+
+func (m *manager) UpdateLastUsed(ctx context.Context, id uint, usedAt time.Time, ip string) error {
+	return m.store.UpdateLastUsed(ctx, id, usedAt, ip)
+}
+
+func (m *manager) UpdateExpiryNotifiedAt(ctx context.Context, id uint, notifiedAt time.Time) error {
+	return m.store.UpdateExpiryNotifiedAt(ctx, id, notifiedAt)
+}
+
+func (m *manager) ListExpirableAccessTokens(ctx context.Context, startID uint,
+	limit int) ([]*models.Token, error) {
+	return m.store.ListExpirableAccessTokens(ctx, startID, limit)
+}
@@ -46,7 +46,7 @@ var (
 )
 
 func TestMain(m *testing.M) {
-	db, _ = orm.NewSqliteDB("")
+	db, _ = orm.NewTestDB()
 	if err := db.AutoMigrate(&tokenmodels.Token{}); err != nil {
 		panic(err)
 	}
@@ -104,3 +104,49 @@ func TestTokenBasic(t *testing.T) {
 	_, err = tokenManager.LoadTokenByID(ctx, tokenWithClientIDInDB.ID)
 	assert.NotNil(t, err)
 }
+
+func TestTokenUsageAndExpiry(t *testing.T) {
+	newToken := func(expiresIn time.Duration) *tokenmodels.Token {
+		token, err := tokenManager.CreateToken(ctx, &tokenmodels.Token{
+			Name: "tokenName",
+			Code: userAccessTokenGenerator.Generate(&generator.CodeGenerateInfo{
+				Token: tokenmodels.Token{UserID: aUser.GetID()},
+			}),
+			Scope:     "clusters:read-write",
+			CreatedAt: time.Now(),
+			ExpiresIn: expiresIn,
+			UserID:    aUser.GetID(),
+		})
+		assert.Nil(t, err)
+		return token
+	}
+	expirable := newToken(time.Hour * 24)
+	neverExpire := newToken(0)
+
+	// last used
+	usedAt := time.Now()
+	err := tokenManager.UpdateLastUsed(ctx, expirable.ID, usedAt, "10.0.0.1")
+	assert.Nil(t, err)
+	tokenInDB, err := tokenManager.LoadTokenByID(ctx, expirable.ID)
+	assert.Nil(t, err)
+	assert.NotNil(t, tokenInDB.LastUsedAt)
+	assert.Equal(t, "10.0.0.1", tokenInDB.LastUsedIP)
+
+	// expirable tokens
+	tokens, err := tokenManager.ListExpirableAccessTokens(ctx, 0, 100)
+	assert.Nil(t, err)
+	ids := make([]uint, 0, len(tokens))
+	for _, token := range tokens {
+		ids = append(ids, token.ID)
+	}
+	assert.Contains(t, ids, expirable.ID)
+	assert.NotContains(t, ids, neverExpire.ID)
+
+	err = tokenManager.UpdateExpiryNotifiedAt(ctx, expirable.ID, time.Now())
+	assert.Nil(t, err)
+	tokens, err = tokenManager.ListExpirableAccessTokens(ctx, expirable.ID-1, 100)
+	assert.Nil(t, err)
+	for _, token := range tokens {
+		assert.NotEqual(t, expirable.ID, token.ID)
+	}
+}
@@ -28,7 +28,8 @@ type Token struct {
 
 	// token basic info
 	Name string `gorm:"column:name"`
-	// Code authorize_code/access_token/refresh_token
+	// Code authorize_code/access_token/refresh_token/device_code,
+	// user code of device_code is saved in State
 	Code      string        `gorm:"column:code"`
 	CreatedAt time.Time     `gorm:"column:created_at"`
 	CreatedBy uint          `gorm:"column:created_by"`
@@ -39,4 +40,25 @@ type Token struct {
 	RefID uint `gorm:"column:ref_id"`
 
 	UserID uint `gorm:"column:user_id"`
+
+	// AllowedCIDRs is a comma separated list of networks the token can be used from,
+	// empty means the token can be used from anywhere
+	AllowedCIDRs string `gorm:"column:allowed_cidrs"`
+	// LastUsedAt and LastUsedIP record the latest usage of the token
+	LastUsedAt *time.Time `gorm:"column:last_used_at"`
+	LastUsedIP string     `gorm:"column:last_used_ip"`
+	// ExpiryNotifiedAt is set once the owner has been notified that the token is about to expire
+	ExpiryNotifiedAt *time.Time `gorm:"column:expiry_notified_at"`
+
+	// PKCE challenge of authorize_code, ref: rfc7636
+	CodeChallenge       string `gorm:"column:code_challenge"`
+	CodeChallengeMethod string `gorm:"column:code_challenge_method"`
+}
+
+// ExpiresAt returns the expiration time of the token and false if the token never expires
+func (t *Token) ExpiresAt() (time.Time, bool) {
+	if t.ExpiresIn <= 0 {
+		return time.Time{}, false
+	}
+	return t.CreatedAt.Add(t.ExpiresIn), true
 }
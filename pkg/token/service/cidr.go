@@ -0,0 +1,85 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"net"
+	"strings"
+
+	herror "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const cidrSeparator = ","
+
+// FormatAllowedCIDRs validates the networks a token can be used from and joins them to
+// the form stored in db, a single ip is treated as a network containing only itself.
+func FormatAllowedCIDRs(cidrs []string) (string, error) {
+	formatted := make([]string, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return "", perror.Wrapf(herror.ErrParamInvalid, "invalid ip: %s", cidr)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				bits = 8 * net.IPv4len
+			}
+			formatted = append(formatted, (&net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}).String())
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return "", perror.Wrapf(herror.ErrParamInvalid, "invalid cidr: %s", cidr)
+		}
+		formatted = append(formatted, ipNet.String())
+	}
+	return strings.Join(formatted, cidrSeparator), nil
+}
+
+// SplitAllowedCIDRs is the reverse of FormatAllowedCIDRs
+func SplitAllowedCIDRs(cidrs string) []string {
+	if cidrs == "" {
+		return nil
+	}
+	return strings.Split(cidrs, cidrSeparator)
+}
+
+// SourceAllowed checks whether ip is in one of the allowed networks,
+// every source is allowed if there are no networks
+func SourceAllowed(allowedCIDRs string, ip string) bool {
+	if allowedCIDRs == "" {
+		return true
+	}
+	source := net.ParseIP(ip)
+	if source == nil {
+		return false
+	}
+	for _, cidr := range SplitAllowedCIDRs(allowedCIDRs) {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if ipNet.Contains(source) {
+			return true
+		}
+	}
+	return false
+}
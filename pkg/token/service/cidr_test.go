@@ -0,0 +1,40 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowedCIDRs(t *testing.T) {
+	cidrs, err := FormatAllowedCIDRs([]string{" 10.1.2.3/8", "192.168.1.1", "", "fd00::/8", "::1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.0/8,192.168.1.1/32,fd00::/8,::1/128", cidrs)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.1/32", "fd00::/8", "::1/128"}, SplitAllowedCIDRs(cidrs))
+
+	_, err = FormatAllowedCIDRs([]string{"10.0.0.256"})
+	assert.NotNil(t, err)
+	_, err = FormatAllowedCIDRs([]string{"10.0.0.0/40"})
+	assert.NotNil(t, err)
+
+	assert.True(t, SourceAllowed("", "1.1.1.1"))
+	assert.True(t, SourceAllowed(cidrs, "10.20.30.40"))
+	assert.True(t, SourceAllowed(cidrs, "192.168.1.1"))
+	assert.True(t, SourceAllowed(cidrs, "::1"))
+	assert.False(t, SourceAllowed(cidrs, "192.168.1.2"))
+	assert.False(t, SourceAllowed(cidrs, "invalid"))
+}
@@ -31,9 +31,10 @@ import (
 )
 
 type Service interface {
-	// CreateAccessToken used for personal access Token and resource access Token
+	// CreateAccessToken used for personal access Token and resource access Token,
+	// the token can only be used from allowedCIDRs if it is not empty
 	CreateAccessToken(ctx context.Context, name, expiresAtStr string,
-		userID uint, scopes []string) (*tokenmodels.Token, error)
+		userID uint, scopes []string, allowedCIDRs []string) (*tokenmodels.Token, error)
 	CreateJWTToken(subject string, expiresIn time.Duration, options ...ClaimsOption) (string, error)
 	ParseJWTToken(tokenStr string) (Claims, error)
 }
@@ -51,7 +52,7 @@ type service struct {
 }
 
 func (s *service) CreateAccessToken(ctx context.Context, name, expiresAtStr string,
-	userID uint, scopes []string) (*tokenmodels.Token, error) {
+	userID uint, scopes []string, allowedCIDRs []string) (*tokenmodels.Token, error) {
 	// 1. check expiration date
 	createdAt := time.Now()
 	expiresIn := time.Duration(0)
@@ -65,12 +66,17 @@ func (s *service) CreateAccessToken(ctx context.Context, name, expiresAtStr stri
 		}
 		expiresIn = expiredAt.Sub(createdAt)
 	}
+	cidrs, err := FormatAllowedCIDRs(allowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
 	// 2. generate user access token
 	gen := generator.NewGeneralAccessTokenGenerator()
 	token, err := s.genAccessToken(gen, name, userID, scopes, createdAt, expiresIn)
 	if err != nil {
 		return nil, err
 	}
+	token.AllowedCIDRs = cidrs
 	// 3. create token in db
 	token, err = s.tokenManager.CreateToken(ctx, token)
 	if err != nil {
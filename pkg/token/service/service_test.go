@@ -75,12 +75,18 @@ func TestService(t *testing.T) {
 	scopes := make([]string, 2)
 	scopes = append(scopes, "clusters:read-write")
 	scopes = append(scopes, "applications:read-only")
-	token, err := tokenSvc.CreateAccessToken(ctx, name, expiresAtStr, aUser.GetID(), scopes)
+	token, err := tokenSvc.CreateAccessToken(ctx, name, expiresAtStr, aUser.GetID(), scopes,
+		[]string{"10.0.0.0/8", "192.168.1.1"})
 	assert.Nil(t, err)
 	tokenInDB, err := tokenManager.LoadTokenByID(ctx, token.ID)
 	assert.Nil(t, err)
 	assert.Equal(t, name, tokenInDB.Name)
 	assert.Equal(t, strings.Join(scopes, " "), tokenInDB.Scope)
+	assert.Equal(t, "10.0.0.0/8,192.168.1.1/32", tokenInDB.AllowedCIDRs)
+
+	_, err = tokenSvc.CreateAccessToken(ctx, name, expiresAtStr, aUser.GetID(), scopes,
+		[]string{"10.0.0.0/33"})
+	assert.NotNil(t, err)
 
 	// Create JWT token
 	jwtToken, err := tokenSvc.CreateJWTToken(strconv.Itoa(int(aUser.GetID())), 2*time.Hour,
@@ -17,9 +17,12 @@ package store
 import (
 	"context"
 	goerrors "errors"
+	"fmt"
+	"time"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/pkg/common"
+	"github.com/horizoncd/horizon/pkg/token/generator"
 	"github.com/horizoncd/horizon/pkg/token/models"
 	"gorm.io/gorm"
 )
@@ -77,6 +80,30 @@ func (s *store) UpdateByID(ctx context.Context, id uint, token *models.Token) er
 	return nil
 }
 
+func (s *store) GetDeviceCodeByUserCode(ctx context.Context, userCode string) (*models.Token, error) {
+	var token models.Token
+	result := s.db.WithContext(ctx).Model(token).
+		Where("state = ?", userCode).
+		Where("code like ?", fmt.Sprintf("%s%%", generator.DeviceCodePrefix)).
+		First(&token)
+	if result.Error != nil {
+		if goerrors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, herrors.NewErrNotFound(herrors.TokenInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.TokenInDB, result.Error.Error())
+	}
+	return &token, nil
+}
+
+func (s *store) UpdateUserByID(ctx context.Context, id uint, userID uint) error {
+	result := s.db.WithContext(ctx).Model(&models.Token{}).Where("id = ?", id).
+		Update("user_id", userID)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.TokenInDB, result.Error.Error())
+	}
+	return nil
+}
+
 func (s *store) DeleteByID(ctx context.Context, id uint) error {
 	result := s.db.WithContext(ctx).Exec(common.DeleteTokenByID, id)
 	return result.Error
@@ -91,3 +118,38 @@ func (s *store) DeleteByClientID(ctx context.Context, clientID string) error {
 	result := s.db.WithContext(ctx).Exec(common.DeleteByClientID, clientID)
 	return result.Error
 }
+
+func (s *store) UpdateLastUsed(ctx context.Context, id uint, usedAt time.Time, ip string) error {
+	result := s.db.WithContext(ctx).Model(&models.Token{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_used_at": usedAt,
+			"last_used_ip": ip,
+		})
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.TokenInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (s *store) UpdateExpiryNotifiedAt(ctx context.Context, id uint, notifiedAt time.Time) error {
+	result := s.db.WithContext(ctx).Model(&models.Token{}).Where("id = ?", id).
+		Update("expiry_notified_at", notifiedAt)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.TokenInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (s *store) ListExpirableAccessTokens(ctx context.Context, startID uint, limit int) ([]*models.Token, error) {
+	var tokens []*models.Token
+	result := s.db.WithContext(ctx).
+		Where("id > ?", startID).
+		Where("code like ?", fmt.Sprintf("%s%%", generator.AccessTokenPrefix)).
+		Where("expires_in > 0").
+		Where("expiry_notified_at is null").
+		Order("id asc").Limit(limit).Find(&tokens)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.TokenInDB, result.Error.Error())
+	}
+	return tokens, nil
+}
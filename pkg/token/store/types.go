@@ -16,6 +16,7 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/horizoncd/horizon/pkg/token/models"
 )
@@ -25,7 +26,16 @@ type Store interface {
 	GetByID(ctx context.Context, id uint) (*models.Token, error)
 	GetByCode(ctx context.Context, code string) (*models.Token, error)
 	UpdateByID(ctx context.Context, id uint, token *models.Token) error
+	// GetDeviceCodeByUserCode gets the device_code by the user code displayed to user
+	GetDeviceCodeByUserCode(ctx context.Context, userCode string) (*models.Token, error)
+	// UpdateUserByID sets the user authorized the token, used by device_code
+	UpdateUserByID(ctx context.Context, id uint, userID uint) error
 	DeleteByID(ctx context.Context, id uint) error
 	DeleteByCode(ctx context.Context, code string) error
 	DeleteByClientID(ctx context.Context, clientID string) error
+	UpdateLastUsed(ctx context.Context, id uint, usedAt time.Time, ip string) error
+	UpdateExpiryNotifiedAt(ctx context.Context, id uint, notifiedAt time.Time) error
+	// ListExpirableAccessTokens lists access tokens which have an expiration and whose owner
+	// has not been notified yet, ordered by id and starting after startID
+	ListExpirableAccessTokens(ctx context.Context, startID uint, limit int) ([]*models.Token, error)
 }
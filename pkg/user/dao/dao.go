@@ -67,7 +67,7 @@ func (d *dao) List(ctx context.Context, query *q.Query) (int64, []*models.User,
 		for k, v := range query.Keywords {
 			switch k {
 			case corecommon.UserQueryName:
-				tx = tx.Where("name like ?", fmt.Sprintf("%%%v%%", v))
+				tx = tx.Where("lower(name) like lower(?)", fmt.Sprintf("%%%v%%", v))
 			case corecommon.UserQueryType:
 				tx = tx.Where("user_type in ?", v)
 			case corecommon.UserQueryID:
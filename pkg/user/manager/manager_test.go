@@ -36,7 +36,7 @@ import (
 )
 
 var (
-	db, _ = orm.NewSqliteDB("")
+	db, _ = orm.NewTestDB()
 	ctx   context.Context
 	mgrs  = managerparam.InitManager(db)
 	mgr   = mgrs.UserMgr
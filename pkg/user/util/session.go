@@ -16,6 +16,7 @@ package util
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gorilla/sessions"
 	"github.com/horizoncd/horizon/core/common"
@@ -35,6 +36,8 @@ func SetSession(ss *sessions.Session,
 		Email:    user.Email,
 		Admin:    user.Admin,
 	}
+	// a new login should pass the multi-factor authentication again
+	delete(ss.Values, common.SessionKeyMFAVerifiedAt)
 
 	if err := ss.Save(request, response); err != nil {
 		return perror.Wrapf(herrors.ErrSessionSaveFailed,
@@ -51,3 +54,28 @@ func GetSession(store sessions.Store, request *http.Request) (*sessions.Session,
 	}
 	return session, nil
 }
+
+// SetMFAVerified records the time when user passed the multi-factor authentication
+func SetMFAVerified(ss *sessions.Session,
+	request *http.Request, response http.ResponseWriter) error {
+	ss.Values[common.SessionKeyMFAVerifiedAt] = time.Now().Unix()
+
+	if err := ss.Save(request, response); err != nil {
+		return perror.Wrapf(herrors.ErrSessionSaveFailed,
+			"err = %v", err)
+	}
+	return nil
+}
+
+// MFAVerifiedAt returns the time when user passed the multi-factor authentication,
+// zero time means never
+func MFAVerifiedAt(ss *sessions.Session) time.Time {
+	if ss == nil {
+		return time.Time{}
+	}
+	verifiedAt, ok := ss.Values[common.SessionKeyMFAVerifiedAt].(int64)
+	if !ok {
+		return time.Time{}
+	}
+	return time.Unix(verifiedAt, 0)
+}
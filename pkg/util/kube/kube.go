@@ -17,6 +17,7 @@ package kube
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
@@ -33,6 +34,7 @@ import (
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
@@ -154,6 +156,29 @@ func DeletePods(ctx context.Context, kubeClientset kubernetes.Interface, namespa
 	return nil
 }
 
+// AddEphemeralContainer attaches an ephemeral container to the pod,
+// it patches the ephemeralcontainers subresource with pod as kubectl debug does, which requires kubernetes 1.22+
+func AddEphemeralContainer(ctx context.Context, kubeClientset kubernetes.Interface, namespace, podName string,
+	container v1.EphemeralContainer) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"ephemeralContainers": []v1.EphemeralContainer{container},
+		},
+	})
+	if err != nil {
+		return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	_, err = kubeClientset.CoreV1().Pods(namespace).Patch(ctx, podName, types.StrategicMergePatchType,
+		patch, metav1.PatchOptions{}, "ephemeralcontainers")
+	if err != nil {
+		if kubeerror.IsNotFound(err) {
+			return herrors.NewErrNotFound(herrors.PodsInK8S, err.Error())
+		}
+		return herrors.NewErrUpdateFailed(herrors.PodsInK8S, err.Error())
+	}
+	return nil
+}
+
 func BuildClient(kubeconfig string) (*rest.Config, kubernetes.Interface, error) {
 	var restConfig *rest.Config
 	var err error
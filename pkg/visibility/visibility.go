@@ -0,0 +1,274 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package visibility
+
+import (
+	"context"
+	"sort"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	membermanager "github.com/horizoncd/horizon/pkg/member/manager"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
+)
+
+// nolint
+//
+// Checker tells whether a resource is hidden from the users who are not members of it.
+// Groups and applications can be marked as private, and the privacy of a group is inherited by subgroups,
+// applications, clusters and pipelineruns under it, the privacy of an application by its clusters and pipelineruns.
+//
+//go:generate mockgen -source=$GOFILE -destination=../../mock/pkg/visibility/visibility_mock.go -package=mock_visibility
+type Checker interface {
+	// IsPrivate returns whether the resource is private, either by itself or inherited from its ancestors
+	IsPrivate(ctx context.Context, resourceType string, resourceID uint) (bool, error)
+	// HiddenResources returns the groups under private groups, the private applications and the applications
+	// under private groups which the current user is not a member of,
+	// neither directly, through teams nor through the groups above them
+	HiddenResources(ctx context.Context) (*HiddenResources, error)
+}
+
+// HiddenResources are the resources hidden from the current user, ids are in ascending order
+type HiddenResources struct {
+	GroupIDs       []uint
+	ApplicationIDs []uint
+}
+
+type checker struct {
+	groupMgr       groupmanager.Manager
+	applicationMgr applicationmanager.Manager
+	clusterMgr     clustermanager.Manager
+	prMgr          *prmanager.PRManager
+	memberMgr      membermanager.Manager
+}
+
+func NewChecker(manager *managerparam.Manager) Checker {
+	return &checker{
+		groupMgr:       manager.GroupMgr,
+		applicationMgr: manager.ApplicationMgr,
+		clusterMgr:     manager.ClusterMgr,
+		prMgr:          manager.PRMgr,
+		memberMgr:      manager.MemberMgr,
+	}
+}
+
+func (c *checker) IsPrivate(ctx context.Context, resourceType string, resourceID uint) (bool, error) {
+	// collections and missing resources are left to the handlers
+	if resourceID == 0 {
+		return false, nil
+	}
+	private, err := c.isPrivate(ctx, resourceType, resourceID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return false, nil
+		}
+		return false, err
+	}
+	return private, nil
+}
+
+func (c *checker) isPrivate(ctx context.Context, resourceType string, resourceID uint) (bool, error) {
+	switch resourceType {
+	case common.ResourceGroup:
+		return c.isGroupPrivate(ctx, resourceID)
+	case common.ResourceApplication:
+		application, err := c.applicationMgr.GetByID(ctx, resourceID)
+		if err != nil {
+			return false, err
+		}
+		if application.IsPrivate() {
+			return true, nil
+		}
+		return c.isGroupPrivate(ctx, application.GroupID)
+	case common.ResourceCluster:
+		cluster, err := c.clusterMgr.GetByID(ctx, resourceID)
+		if err != nil {
+			return false, err
+		}
+		return c.isPrivate(ctx, common.ResourceApplication, cluster.ApplicationID)
+	case common.ResourcePipelinerun:
+		pipelinerun, err := c.prMgr.PipelineRun.GetByID(ctx, resourceID)
+		if err != nil {
+			return false, err
+		}
+		if pipelinerun == nil {
+			return false, herrors.NewErrNotFound(herrors.PipelinerunInDB, "pipelinerun not found")
+		}
+		return c.isPrivate(ctx, common.ResourceCluster, pipelinerun.ClusterID)
+	case common.ResourceCheckrun:
+		checkrun, err := c.prMgr.Check.GetCheckRunByID(ctx, resourceID)
+		if err != nil {
+			return false, err
+		}
+		if checkrun == nil {
+			return false, herrors.NewErrNotFound(herrors.CheckRunInDB, "checkrun not found")
+		}
+		return c.isPrivate(ctx, common.ResourcePipelinerun, checkrun.PipelineRunID)
+	default:
+		return false, nil
+	}
+}
+
+// isGroupPrivate checks the group and all of its ancestors
+func (c *checker) isGroupPrivate(ctx context.Context, groupID uint) (bool, error) {
+	if c.groupMgr.IsRootGroup(groupID) {
+		return false, nil
+	}
+	group, err := c.groupMgr.GetByID(ctx, groupID)
+	if err != nil {
+		return false, err
+	}
+	if group.IsPrivate() {
+		return true, nil
+	}
+	ancestors, err := c.groupMgr.GetByIDs(ctx, groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs))
+	if err != nil {
+		return false, err
+	}
+	for _, ancestor := range ancestors {
+		if ancestor.IsPrivate() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *checker) HiddenResources(ctx context.Context) (*HiddenResources, error) {
+	hidden := &HiddenResources{GroupIDs: []uint{}, ApplicationIDs: []uint{}}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// admin sees everything
+	if currentUser.IsAdmin() {
+		return hidden, nil
+	}
+
+	privateGroups, err := c.groupMgr.GetPrivateGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	privateApplications, err := c.applicationMgr.GetPrivateApplications(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(privateGroups) == 0 && len(privateApplications) == 0 {
+		return hidden, nil
+	}
+
+	// resolve the bindings of the user directly and through teams at once,
+	// the user is a member of a resource if bound to it or to any group above it,
+	// which is the same as the member service resolves members for authorization
+	members, err := c.memberMgr.ListMembersOfUser(ctx, currentUser.GetID(),
+		[]membermodels.ResourceType{membermodels.TypeGroup, membermodels.TypeApplication})
+	if err != nil {
+		return nil, err
+	}
+	memberGroupIDs := make(map[uint]struct{})
+	memberApplicationIDs := make(map[uint]struct{})
+	for _, member := range members {
+		if member.ResourceType == membermodels.TypeGroup {
+			memberGroupIDs[member.ResourceID] = struct{}{}
+		} else {
+			memberApplicationIDs[member.ResourceID] = struct{}{}
+		}
+	}
+	isGroupMember := func(traversalIDs string) bool {
+		for _, id := range groupmanager.FormatIDsFromTraversalIDs(traversalIDs) {
+			if _, ok := memberGroupIDs[id]; ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	// groups under private groups, including the private groups themselves
+	groups := make(map[uint]*groupmodels.Group)
+	if len(privateGroups) > 0 {
+		privateGroupIDs := make([]uint, 0, len(privateGroups))
+		for _, group := range privateGroups {
+			privateGroupIDs = append(privateGroupIDs, group.ID)
+		}
+		subGroups, err := c.groupMgr.GetSubGroupsByGroupIDs(ctx, privateGroupIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range subGroups {
+			groups[group.ID] = group
+			if !isGroupMember(group.TraversalIDs) {
+				hidden.GroupIDs = append(hidden.GroupIDs, group.ID)
+			}
+		}
+	}
+
+	// applications under private groups and private applications
+	candidates := privateApplications
+	if len(groups) > 0 {
+		groupIDs := make([]uint, 0, len(groups))
+		for id := range groups {
+			groupIDs = append(groupIDs, id)
+		}
+		applications, err := c.applicationMgr.GetByGroupIDs(ctx, groupIDs)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, applications...)
+	}
+	// private applications may be under public groups, whose members are members of the applications as well
+	var missingGroupIDs []uint
+	for _, application := range candidates {
+		if _, ok := groups[application.GroupID]; !ok {
+			missingGroupIDs = append(missingGroupIDs, application.GroupID)
+		}
+	}
+	if len(missingGroupIDs) > 0 {
+		missingGroups, err := c.groupMgr.GetByIDs(ctx, missingGroupIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range missingGroups {
+			groups[group.ID] = group
+		}
+	}
+	checked := make(map[uint]struct{}, len(candidates))
+	for _, application := range candidates {
+		if _, ok := checked[application.ID]; ok {
+			continue
+		}
+		checked[application.ID] = struct{}{}
+		if _, ok := memberApplicationIDs[application.ID]; ok {
+			continue
+		}
+		if group, ok := groups[application.GroupID]; ok && isGroupMember(group.TraversalIDs) {
+			continue
+		}
+		hidden.ApplicationIDs = append(hidden.ApplicationIDs, application.ID)
+	}
+
+	for _, ids := range [][]uint{hidden.GroupIDs, hidden.ApplicationIDs} {
+		ids := ids
+		sort.Slice(ids, func(i, j int) bool {
+			return ids[i] < ids[j]
+		})
+	}
+	return hidden, nil
+}
@@ -0,0 +1,170 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package visibility_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	teammodels "github.com/horizoncd/horizon/pkg/team/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	"github.com/horizoncd/horizon/pkg/visibility"
+)
+
+var (
+	db, _   = orm.NewTestDB()
+	ctx     = common.WithContext(context.Background(), &userauth.DefaultInfo{Name: "tony", ID: 1})
+	ctx2    = common.WithContext(context.Background(), &userauth.DefaultInfo{Name: "jerry", ID: 2})
+	manager = managerparam.InitManager(db)
+)
+
+func init() {
+	if err := db.AutoMigrate(&groupmodels.Group{}, &appmodels.Application{}, &clustermodels.Cluster{},
+		&membermodels.Member{}, &prmodels.Pipelinerun{}, &teammodels.TeamMember{}, &usermodels.User{}); err != nil {
+		panic(err)
+	}
+	for _, name := range []string{"tony", "jerry"} {
+		if err := db.Create(&usermodels.User{Name: name}).Error; err != nil {
+			panic(err)
+		}
+	}
+}
+
+func Test(t *testing.T) {
+	checker := visibility.NewChecker(manager)
+
+	// the creator becomes the owner of the groups
+	private, err := manager.GroupMgr.Create(ctx, &groupmodels.Group{
+		Name:            "private",
+		Path:            "private",
+		VisibilityLevel: groupmodels.VisibilityPrivate,
+	})
+	assert.Nil(t, err)
+	inherited, err := manager.GroupMgr.Create(ctx, &groupmodels.Group{
+		Name:            "inherited",
+		Path:            "inherited",
+		VisibilityLevel: groupmodels.VisibilityPublic,
+		ParentID:        private.ID,
+	})
+	assert.Nil(t, err)
+	public, err := manager.GroupMgr.Create(ctx, &groupmodels.Group{
+		Name:            "public",
+		Path:            "public",
+		VisibilityLevel: groupmodels.VisibilityPublic,
+	})
+	assert.Nil(t, err)
+
+	privateApp := &appmodels.Application{Name: "private-app", GroupID: inherited.ID}
+	assert.Nil(t, db.Create(privateApp).Error)
+	publicApp := &appmodels.Application{Name: "public-app", GroupID: public.ID}
+	assert.Nil(t, db.Create(publicApp).Error)
+	// the application is private by itself, its group is public
+	markedApp := &appmodels.Application{Name: "marked-app", GroupID: public.ID,
+		VisibilityLevel: groupmodels.VisibilityPrivate}
+	assert.Nil(t, db.Create(markedApp).Error)
+	cluster := &clustermodels.Cluster{Name: "private-cluster", ApplicationID: privateApp.ID}
+	assert.Nil(t, db.Create(cluster).Error)
+	pipelinerun := &prmodels.Pipelinerun{ClusterID: cluster.ID}
+	assert.Nil(t, db.Create(pipelinerun).Error)
+
+	cases := []struct {
+		resourceType string
+		resourceID   uint
+		private      bool
+	}{
+		{common.ResourceGroup, 0, false},
+		{common.ResourceGroup, private.ID, true},
+		{common.ResourceGroup, inherited.ID, true},
+		{common.ResourceGroup, public.ID, false},
+		{common.ResourceApplication, privateApp.ID, true},
+		{common.ResourceApplication, publicApp.ID, false},
+		{common.ResourceApplication, markedApp.ID, true},
+		{common.ResourceCluster, cluster.ID, true},
+		{common.ResourcePipelinerun, pipelinerun.ID, true},
+		{common.ResourceTemplate, 1, false},
+		{common.ResourceCluster, 0, false},
+		{common.ResourceApplication, 1000, false},
+	}
+	for _, c := range cases {
+		isPrivate, err := checker.IsPrivate(ctx, c.resourceType, c.resourceID)
+		assert.Nil(t, err)
+		assert.Equal(t, c.private, isPrivate, "%s/%d", c.resourceType, c.resourceID)
+	}
+
+	// the creator of the groups sees all the groups and applications under them
+	hidden, err := checker.HiddenResources(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, hidden.GroupIDs)
+	assert.Empty(t, hidden.ApplicationIDs)
+
+	hidden, err = checker.HiddenResources(ctx2)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{private.ID, inherited.ID}, hidden.GroupIDs)
+	assert.Equal(t, []uint{privateApp.ID, markedApp.ID}, hidden.ApplicationIDs)
+
+	// members of the application see it even though they are not members of the group
+	_, err = manager.MemberMgr.Create(ctx, &membermodels.Member{
+		ResourceType: membermodels.TypeApplication,
+		ResourceID:   privateApp.ID,
+		Role:         "guest",
+		MemberType:   membermodels.MemberUser,
+		MemberNameID: 2,
+	})
+	assert.Nil(t, err)
+	hidden, err = checker.HiddenResources(ctx2)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{private.ID, inherited.ID}, hidden.GroupIDs)
+	assert.Equal(t, []uint{markedApp.ID}, hidden.ApplicationIDs)
+
+	// so do the members of teams bound to the application
+	assert.Nil(t, db.Create(&teammodels.TeamMember{TeamID: 1, UserID: 2}).Error)
+	_, err = manager.MemberMgr.Create(ctx, &membermodels.Member{
+		ResourceType: membermodels.TypeApplication,
+		ResourceID:   markedApp.ID,
+		Role:         "guest",
+		MemberType:   membermodels.MemberGroup,
+		MemberNameID: 1,
+	})
+	assert.Nil(t, err)
+	hidden, err = checker.HiddenResources(ctx2)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{private.ID, inherited.ID}, hidden.GroupIDs)
+	assert.Empty(t, hidden.ApplicationIDs)
+
+	// members of a group see the subgroups under it, but not the groups above it
+	_, err = manager.MemberMgr.Create(ctx, &membermodels.Member{
+		ResourceType: membermodels.TypeGroup,
+		ResourceID:   inherited.ID,
+		Role:         "guest",
+		MemberType:   membermodels.MemberGroup,
+		MemberNameID: 1,
+	})
+	assert.Nil(t, err)
+	hidden, err = checker.HiddenResources(ctx2)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{private.ID}, hidden.GroupIDs)
+	assert.Empty(t, hidden.ApplicationIDs)
+}
@@ -317,7 +317,7 @@ func (d *dao) DeleteWebhookLogs(ctx context.Context, ids ...uint) (int64, error)
 
 func (d *dao) GetMaxEventIDOfLog(ctx context.Context) (uint, error) {
 	var maxID uint
-	if result := d.db.WithContext(ctx).Model(&models.WebhookLog{}).Select("ifnull(max(event_id),0)").
+	if result := d.db.WithContext(ctx).Model(&models.WebhookLog{}).Select("coalesce(max(event_id), 0)").
 		Scan(&maxID); result.Error != nil {
 		return maxID, herrors.NewErrGetFailed(herrors.WebhookLogInDB, result.Error.Error())
 	}
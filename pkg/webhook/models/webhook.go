@@ -14,7 +14,11 @@
 
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/lib/encrypt"
+)
 
 const (
 	StatusWaiting = "waiting"
@@ -28,7 +32,7 @@ type Webhook struct {
 	URL              string
 	SSLVerifyEnabled bool
 	Description      string
-	Secret           string
+	Secret           encrypt.String
 	Triggers         string
 	ResourceType     string
 	ResourceID       uint
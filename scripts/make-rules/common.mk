@@ -119,7 +119,7 @@ endif
 # Linux command settings-CODE DIRS Copyright
 # TODO: if ! ./db/* AND ./openapi/*
 # $$file != '_output' && $$file != 'docs' && $$file != 'vendor' && $$file != 'logger' && $$file != 'applications' 
-CODE_DIRS := $(ROOT_DIR)/pkg $(ROOT_DIR)/core $(ROOT_DIR)/integrationtest $(ROOT_DIR)/lib $(ROOT_DIR)/mock $(ROOT_DIR)/db $(ROOT_DIR)/openapi
+CODE_DIRS := $(ROOT_DIR)/pkg $(ROOT_DIR)/core $(ROOT_DIR)/integrationtest $(ROOT_DIR)/lib $(ROOT_DIR)/horizonctl $(ROOT_DIR)/mock $(ROOT_DIR)/db $(ROOT_DIR)/openapi
 FINDS := find $(CODE_DIRS)
 
 # Makefile settings: Select different behaviors by determining whether V option is set
@@ -129,6 +129,8 @@ endif
 
 BUILDFILE = "./core/main.go"
 BUILDAPP = "$(OUTPUT_DIR)/app"
+BUILDCTLFILE = "./horizonctl/main.go"
+BUILDCTL = "$(OUTPUT_DIR)/horizonctl"
 
 # COMMA: Concatenate multiple strings to form a list of strings
 COMMA := ,
@@ -39,6 +39,12 @@ go.build:
 	@echo "===========> Building binary $(BUILDAPP) *[Git Info]: $(VERSION)-$(GIT_TAG)-$(GIT_COMMIT)"
 	@export CGO_ENABLED=0 && go build -o $(BUILDAPP) -ldflags '-s -w' $(BUILDFILE)
 
+## go.build.horizonctl: Build the horizonctl binary
+.PHONY: go.build.horizonctl
+go.build.horizonctl:
+	@echo "===========> Building binary $(BUILDCTL) *[Git Info]: $(VERSION)-$(GIT_TAG)-$(GIT_COMMIT)"
+	@export CGO_ENABLED=0 && go build -o $(BUILDCTL) -ldflags '-s -w' $(BUILDCTLFILE)
+
 ## swagger-run: Run a swagger server locally
 .PHONY: go.swagger-run
 go.swagger-run: image.swagger